@@ -0,0 +1,267 @@
+package alerting
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// recordingSender запоминает отправленные сообщения вместо отправки в Telegram
+type recordingSender struct {
+	mutex    sync.Mutex
+	messages []sentMessage
+}
+
+type sentMessage struct {
+	chatID string
+	text   string
+}
+
+func (s *recordingSender) SendMessageTo(chatID, text string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.messages = append(s.messages, sentMessage{chatID: chatID, text: text})
+	return nil
+}
+
+func (s *recordingSender) sent() []sentMessage {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]sentMessage(nil), s.messages...)
+}
+
+func newTestBridge(t *testing.T, set *RuleSet, options *Options) (*Bridge, *recordingSender, *time.Time) {
+	t.Helper()
+
+	sender := &recordingSender{}
+	bridge := NewBridge(sender, nil, options)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	bridge.now = func() time.Time { return now }
+
+	if set != nil {
+		if err := bridge.SetRules(set); err != nil {
+			t.Fatalf("SetRules() error = %v", err)
+		}
+	}
+	return bridge, sender, &now
+}
+
+func deliver(t *testing.T, bridge *Bridge, routingKey, payload string) {
+	t.Helper()
+
+	if err := bridge.Handle(context.Background(), amqp.Delivery{RoutingKey: routingKey}, []byte(payload)); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+}
+
+func TestBridgeMatchesConditionAndRoutingKey(t *testing.T) {
+	bridge, sender, _ := newTestBridge(t, &RuleSet{
+		Rules: []Rule{{
+			Name:       "large-payment",
+			RoutingKey: "payment.*",
+			Condition:  `$.amount > 50000 && $.currency == "RUB"`,
+			Template:   "payment",
+			ChatID:     "-100",
+		}},
+		Templates: map[string]string{
+			"payment": `Платеж {{.Payload.id}} на {{.Payload.amount}} ({{.RoutingKey}})`,
+		},
+	}, nil)
+
+	deliver(t, bridge, "payment.created", `{"id": "p1", "amount": 70000, "currency": "RUB"}`)
+	deliver(t, bridge, "payment.created", `{"id": "p2", "amount": 100, "currency": "RUB"}`)
+	deliver(t, bridge, "payment.created", `{"id": "p3", "amount": 70000, "currency": "USD"}`)
+	deliver(t, bridge, "order.created", `{"id": "p4", "amount": 70000, "currency": "RUB"}`)
+
+	sent := sender.sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1: %v", len(sent), sent)
+	}
+	if sent[0].chatID != "-100" || sent[0].text != "Платеж p1 на 70000 (payment.created)" {
+		t.Errorf("sent %+v", sent[0])
+	}
+}
+
+func TestBridgeEscapesPayloadInTemplate(t *testing.T) {
+	bridge, sender, _ := newTestBridge(t, &RuleSet{
+		Rules: []Rule{{Name: "all", RoutingKey: "#"}},
+	}, nil)
+
+	deliver(t, bridge, "user.created", `{"name": "<b>x</b>"}`)
+
+	sent := sender.sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sent))
+	}
+	if strings.Contains(sent[0].text, "<b>x</b>") || !strings.Contains(sent[0].text, "&lt;b&gt;x&lt;/b&gt;") {
+		t.Errorf("payload is not escaped: %s", sent[0].text)
+	}
+}
+
+func TestBridgeRateLimit(t *testing.T) {
+	bridge, sender, now := newTestBridge(t, &RuleSet{
+		Rules: []Rule{{
+			Name:         "errors",
+			RoutingKey:   "service.error",
+			RateLimit:    2,
+			RateInterval: Duration(time.Minute),
+		}},
+	}, nil)
+
+	for i := 0; i < 5; i++ {
+		deliver(t, bridge, "service.error", `{}`)
+	}
+	if got := len(sender.sent()); got != 2 {
+		t.Fatalf("sent %d messages within interval, want 2", got)
+	}
+
+	*now = now.Add(time.Minute)
+	deliver(t, bridge, "service.error", `{}`)
+	if got := len(sender.sent()); got != 3 {
+		t.Errorf("sent %d messages after interval, want 3", got)
+	}
+}
+
+func TestBridgeDedup(t *testing.T) {
+	bridge, sender, now := newTestBridge(t, &RuleSet{
+		Rules: []Rule{{
+			Name:        "orders",
+			RoutingKey:  "order.failed",
+			DedupKey:    "$.order.id",
+			DedupWindow: Duration(10 * time.Minute),
+		}},
+	}, nil)
+
+	deliver(t, bridge, "order.failed", `{"order": {"id": 1}}`)
+	deliver(t, bridge, "order.failed", `{"order": {"id": 1}}`)
+	deliver(t, bridge, "order.failed", `{"order": {"id": 2}}`)
+	if got := len(sender.sent()); got != 2 {
+		t.Fatalf("sent %d messages, want 2", got)
+	}
+
+	*now = now.Add(10 * time.Minute)
+	deliver(t, bridge, "order.failed", `{"order": {"id": 1}}`)
+	if got := len(sender.sent()); got != 3 {
+		t.Errorf("sent %d messages after dedup window, want 3", got)
+	}
+}
+
+func TestBridgeDryRun(t *testing.T) {
+	bridge, sender, _ := newTestBridge(t, &RuleSet{
+		Rules: []Rule{{Name: "all", RoutingKey: "#"}},
+	}, &Options{DryRun: true})
+
+	deliver(t, bridge, "user.created", `{"id": 1}`)
+
+	if got := len(sender.sent()); got != 0 {
+		t.Errorf("dry run sent %d messages", got)
+	}
+}
+
+func TestBridgeRejectsInvalidPayload(t *testing.T) {
+	bridge, _, _ := newTestBridge(t, &RuleSet{Rules: []Rule{{Name: "all", RoutingKey: "#"}}}, nil)
+
+	if err := bridge.Handle(context.Background(), amqp.Delivery{RoutingKey: "x"}, []byte("not json")); err == nil {
+		t.Error("Handle() must reject invalid payload")
+	}
+}
+
+func TestCompileRuleSetErrors(t *testing.T) {
+	cases := map[string]*RuleSet{
+		"no name":          {Rules: []Rule{{RoutingKey: "a"}}},
+		"duplicate":        {Rules: []Rule{{Name: "a", RoutingKey: "a"}, {Name: "a", RoutingKey: "b"}}},
+		"no routing key":   {Rules: []Rule{{Name: "a"}}},
+		"no operator":      {Rules: []Rule{{Name: "a", RoutingKey: "a", Condition: "$.amount"}}},
+		"bad path":         {Rules: []Rule{{Name: "a", RoutingKey: "a", Condition: "amount > 1"}}},
+		"bad literal":      {Rules: []Rule{{Name: "a", RoutingKey: "a", Condition: "$.status == paid"}}},
+		"unknown template": {Rules: []Rule{{Name: "a", RoutingKey: "a", Template: "missing"}}},
+		"bad dedup key":    {Rules: []Rule{{Name: "a", RoutingKey: "a", DedupKey: "id"}}},
+	}
+
+	for name, set := range cases {
+		if _, err := compileRuleSet(set); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestConditionOperators(t *testing.T) {
+	payload := map[string]interface{}{
+		"status": "paid",
+		"items":  []interface{}{map[string]interface{}{"price": 10.0}},
+	}
+
+	cases := map[string]bool{
+		`$.status == "paid"`:                     true,
+		`$.status != "paid"`:                     false,
+		`$.items[0].price >= 10`:                 true,
+		`$.items[0].price < 10`:                  false,
+		`$.items[1].price < 10`:                  false,
+		`$.missing != null`:                      true,
+		`$.status == "a && b" && $.status != ""`: false,
+	}
+
+	for expr, want := range cases {
+		conditions, err := parseCondition(expr)
+		if err != nil {
+			t.Fatalf("parseCondition(%q) error = %v", expr, err)
+		}
+		if got := matches(conditions, payload); got != want {
+			t.Errorf("%s = %v, want %v", expr, got, want)
+		}
+	}
+}
+
+func TestWatchReloadsRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	write := func(routingKey string) {
+		data := `{"rules": [{"name": "r", "routing_key": "` + routingKey + `"}]}`
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("order.created")
+	bridge, sender, _ := newTestBridge(t, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := bridge.Watch(ctx, FileSource{Path: path}, 10*time.Millisecond); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	deliver(t, bridge, "order.created", `{}`)
+	if got := len(sender.sent()); got != 1 {
+		t.Fatalf("sent %d messages, want 1", got)
+	}
+
+	write("order.paid")
+	deadline := time.Now().Add(time.Second)
+	for {
+		deliver(t, bridge, "order.paid", `{}`)
+		if len(sender.sent()) == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("rules were not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := os.WriteFile(path, []byte("{broken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	deliver(t, bridge, "order.paid", `{}`)
+	if got := len(sender.sent()); got != 3 {
+		t.Errorf("previous rules must stay after invalid reload, sent %d", got)
+	}
+}
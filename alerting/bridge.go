@@ -0,0 +1,251 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/streadway/amqp"
+	"github.com/vladzorgan/common/logging"
+	events "github.com/vladzorgan/common/messaging/rabbitmq"
+)
+
+// Причины подавления уведомлений в метрике alerts_suppressed_total
+const (
+	suppressedRateLimit = "rate_limit"
+	suppressedDuplicate = "duplicate"
+	suppressedDryRun    = "dry_run"
+)
+
+var (
+	// alertsMatchedTotal считает события, удовлетворившие правилу
+	alertsMatchedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "alerts_matched_total",
+			Help: "Количество событий, удовлетворивших правилу уведомлений",
+		},
+		[]string{"rule"},
+	)
+
+	// alertsSentTotal считает отправленные уведомления по результату
+	alertsSentTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "alerts_sent_total",
+			Help: "Количество отправленных в Telegram уведомлений",
+		},
+		[]string{"rule", "status"},
+	)
+
+	// alertsSuppressedTotal считает подавленные уведомления по причине
+	alertsSuppressedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "alerts_suppressed_total",
+			Help: "Количество подавленных уведомлений",
+		},
+		[]string{"rule", "reason"},
+	)
+)
+
+// Sender отправляет текст в чат Telegram; реализуется telegram.TelegramClient
+type Sender interface {
+	SendMessageTo(chatID, text string) error
+}
+
+// Options содержит настройки моста уведомлений
+type Options struct {
+	// DryRun только логирует уведомления, не отправляя их
+	DryRun bool
+}
+
+// Bridge проверяет события по правилам и отправляет уведомления в Telegram.
+// Ограничение частоты и подавление повторов действуют в пределах экземпляра сервиса.
+type Bridge struct {
+	sender  Sender
+	logger  logging.Logger
+	options Options
+	now     func() time.Time
+
+	mutex    sync.RWMutex
+	rules    []*compiledRule
+	consumer *events.Consumer
+	bound    map[string]bool
+
+	limiterMutex sync.Mutex
+	sentAt       map[string][]time.Time
+	dedup        map[string]time.Time
+}
+
+// NewBridge создает мост уведомлений без правил; правила задаются SetRules или Watch
+func NewBridge(sender Sender, logger logging.Logger, options *Options) *Bridge {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+	if options == nil {
+		options = &Options{}
+	}
+
+	return &Bridge{
+		sender:  sender,
+		logger:  logger,
+		options: *options,
+		now:     time.Now,
+		bound:   make(map[string]bool),
+		sentAt:  make(map[string][]time.Time),
+		dedup:   make(map[string]time.Time),
+	}
+}
+
+// SetRules заменяет набор правил. При ошибке в любом правиле действующий набор не меняется.
+// Если мост подключен к потребителю, новые шаблоны ключей маршрутизации подписываются сразу.
+func (b *Bridge) SetRules(set *RuleSet) error {
+	rules, err := compileRuleSet(set)
+	if err != nil {
+		return err
+	}
+
+	b.mutex.Lock()
+	b.rules = rules
+	consumer := b.consumer
+	b.mutex.Unlock()
+
+	if consumer != nil {
+		return b.subscribe(consumer, rules)
+	}
+	return nil
+}
+
+// Attach подписывает мост на ключи маршрутизации правил в потребителе
+func (b *Bridge) Attach(consumer *events.Consumer) error {
+	b.mutex.Lock()
+	b.consumer = consumer
+	rules := b.rules
+	b.mutex.Unlock()
+
+	return b.subscribe(consumer, rules)
+}
+
+// subscribe подписывает потребителя на еще не подписанные шаблоны ключей
+func (b *Bridge) subscribe(consumer *events.Consumer, rules []*compiledRule) error {
+	for _, rule := range rules {
+		b.mutex.Lock()
+		bound := b.bound[rule.RoutingKey]
+		b.bound[rule.RoutingKey] = true
+		b.mutex.Unlock()
+
+		if bound {
+			continue
+		}
+		if err := consumer.Subscribe(rule.RoutingKey, b.Handle); err != nil {
+			return fmt.Errorf("failed to subscribe alert rules to %s: %v", rule.RoutingKey, err)
+		}
+	}
+	return nil
+}
+
+// Handle реализует rabbitmq.HandlerFunc: проверяет событие всеми подходящими правилами.
+// Ошибки отправки логируются и учитываются в метриках, сообщение не возвращается в очередь.
+func (b *Bridge) Handle(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+	b.mutex.RLock()
+	rules := b.rules
+	b.mutex.RUnlock()
+
+	var payload interface{}
+	dec := json.NewDecoder(bytes.NewReader(message))
+	dec.UseNumber()
+	if err := dec.Decode(&payload); err != nil {
+		return fmt.Errorf("%w: invalid alert payload: %v", events.ErrRejectMessage, err)
+	}
+
+	for _, rule := range rules {
+		if !events.MatchRoutingKey(rule.RoutingKey, delivery.RoutingKey) || !matches(rule.condition, payload) {
+			continue
+		}
+		alertsMatchedTotal.WithLabelValues(rule.Name).Inc()
+
+		b.dispatch(ctx, rule, delivery.RoutingKey, payload, message)
+	}
+
+	return nil
+}
+
+// dispatch применяет ограничения правила и отправляет уведомление
+func (b *Bridge) dispatch(ctx context.Context, rule *compiledRule, routingKey string, payload interface{}, raw []byte) {
+	logger := b.logger.WithContext(ctx).WithField("rule", rule.Name).WithField("routing_key", routingKey)
+	now := b.now()
+
+	if reason := b.admit(rule, payload, now); reason != "" {
+		alertsSuppressedTotal.WithLabelValues(rule.Name, reason).Inc()
+		logger.Debug("Alert suppressed: %s", reason)
+		return
+	}
+
+	text, err := rule.render(routingKey, payload, raw, now)
+	if err != nil {
+		alertsSentTotal.WithLabelValues(rule.Name, "error").Inc()
+		logger.Error("%v", err)
+		return
+	}
+
+	if b.options.DryRun {
+		alertsSuppressedTotal.WithLabelValues(rule.Name, suppressedDryRun).Inc()
+		logger.Info("Dry run alert to chat %s:\n%s", rule.ChatID, text)
+		return
+	}
+
+	if err := b.sender.SendMessageTo(rule.ChatID, text); err != nil {
+		alertsSentTotal.WithLabelValues(rule.Name, "error").Inc()
+		logger.Error("Failed to send alert: %v", err)
+		return
+	}
+	alertsSentTotal.WithLabelValues(rule.Name, "success").Inc()
+}
+
+// admit проверяет подавление повторов и ограничение частоты правила.
+// Возвращает причину подавления или пустую строку, если уведомление можно отправить.
+func (b *Bridge) admit(rule *compiledRule, payload interface{}, now time.Time) string {
+	b.limiterMutex.Lock()
+	defer b.limiterMutex.Unlock()
+
+	var dedupKey string
+	if rule.DedupKey != "" && rule.DedupWindow > 0 {
+		path, _ := parsePath(rule.DedupKey)
+		value, _ := lookup(payload, path)
+		dedupKey = fmt.Sprintf("%s\x00%v", rule.Name, value)
+
+		if sentAt, ok := b.dedup[dedupKey]; ok && now.Sub(sentAt) < time.Duration(rule.DedupWindow) {
+			return suppressedDuplicate
+		}
+	}
+
+	if rule.RateLimit > 0 {
+		window := now.Add(-time.Duration(rule.RateInterval))
+		recent := b.sentAt[rule.Name][:0]
+		for _, sentAt := range b.sentAt[rule.Name] {
+			if sentAt.After(window) {
+				recent = append(recent, sentAt)
+			}
+		}
+		b.sentAt[rule.Name] = recent
+
+		if len(recent) >= rule.RateLimit {
+			return suppressedRateLimit
+		}
+		b.sentAt[rule.Name] = append(recent, now)
+	}
+
+	if dedupKey != "" {
+		b.dedup[dedupKey] = now
+		for key, sentAt := range b.dedup {
+			if now.Sub(sentAt) >= time.Duration(rule.DedupWindow) && key != dedupKey {
+				delete(b.dedup, key)
+			}
+		}
+	}
+
+	return ""
+}
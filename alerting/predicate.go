@@ -0,0 +1,230 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// operators поддерживаемые операторы сравнения; двухсимвольные проверяются первыми
+var operators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+// pathSegment элемент пути: имя поля объекта или индекс массива
+type pathSegment struct {
+	field string
+	index int
+	isIdx bool
+}
+
+// condition сравнение значения по пути с константой
+type condition struct {
+	path     []pathSegment
+	operator string
+	value    interface{}
+}
+
+// parseCondition разбирает условие вида `$.a.b[0] > 10 && $.status == "paid"`
+func parseCondition(expr string) ([]condition, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+
+	var conditions []condition
+	for _, part := range splitOutsideQuotes(expr, "&&") {
+		cond, err := parseComparison(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, cond)
+	}
+	return conditions, nil
+}
+
+// parseComparison разбирает одно сравнение
+func parseComparison(expr string) (condition, error) {
+	for i := 0; i < len(expr); i++ {
+		if expr[i] == '"' {
+			break
+		}
+		for _, op := range operators {
+			if strings.HasPrefix(expr[i:], op) {
+				path, err := parsePath(strings.TrimSpace(expr[:i]))
+				if err != nil {
+					return condition{}, err
+				}
+
+				literal := strings.TrimSpace(expr[i+len(op):])
+				var value interface{}
+				if err := json.Unmarshal([]byte(literal), &value); err != nil {
+					return condition{}, fmt.Errorf("invalid literal %s in condition %q", literal, expr)
+				}
+
+				return condition{path: path, operator: op, value: value}, nil
+			}
+		}
+	}
+	return condition{}, fmt.Errorf("condition %q has no comparison operator", expr)
+}
+
+// parsePath разбирает путь вида $.order.items[0].price
+func parsePath(path string) ([]pathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path %q must start with $", path)
+	}
+
+	var segments []pathSegment
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			field := rest[1 : end+1]
+			if field == "" {
+				return nil, fmt.Errorf("empty field name in path %q", path)
+			}
+			segments = append(segments, pathSegment{field: field})
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in path %q", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index in path %q", path)
+			}
+			segments = append(segments, pathSegment{index: index, isIdx: true})
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in path %q", rest[0], path)
+		}
+	}
+	return segments, nil
+}
+
+// lookup возвращает значение по пути или false, если путь отсутствует
+func lookup(payload interface{}, path []pathSegment) (interface{}, bool) {
+	current := payload
+	for _, segment := range path {
+		if segment.isIdx {
+			items, ok := current.([]interface{})
+			if !ok || segment.index >= len(items) {
+				return nil, false
+			}
+			current = items[segment.index]
+			continue
+		}
+
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[segment.field]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// matches проверяет все условия; отсутствующее поле не удовлетворяет ни одному сравнению, кроме !=
+func matches(conditions []condition, payload interface{}) bool {
+	for _, cond := range conditions {
+		actual, ok := lookup(payload, cond.path)
+		if !ok {
+			if cond.operator == "!=" {
+				continue
+			}
+			return false
+		}
+		if !compare(actual, cond.operator, cond.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// compare сравнивает значение события с константой условия
+func compare(actual interface{}, operator string, expected interface{}) bool {
+	if a, ok := toFloat(actual); ok {
+		if e, ok := toFloat(expected); ok {
+			switch operator {
+			case "==":
+				return a == e
+			case "!=":
+				return a != e
+			case ">":
+				return a > e
+			case ">=":
+				return a >= e
+			case "<":
+				return a < e
+			case "<=":
+				return a <= e
+			}
+		}
+	}
+
+	if a, ok := actual.(string); ok {
+		if e, ok := expected.(string); ok {
+			switch operator {
+			case "==":
+				return a == e
+			case "!=":
+				return a != e
+			case ">":
+				return a > e
+			case ">=":
+				return a >= e
+			case "<":
+				return a < e
+			case "<=":
+				return a <= e
+			}
+		}
+	}
+
+	switch operator {
+	case "==":
+		return reflect.DeepEqual(actual, expected)
+	case "!=":
+		return !reflect.DeepEqual(actual, expected)
+	}
+	return false
+}
+
+// toFloat приводит число события (json.Number) или условия (float64) к float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// splitOutsideQuotes разбивает строку по разделителю, не учитывая его внутри строковых литералов
+func splitOutsideQuotes(s, sep string) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && inQuotes:
+			i++
+		case s[i] == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(parts, s[start:])
+}
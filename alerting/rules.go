@@ -0,0 +1,168 @@
+// Package alerting пересылает события RabbitMQ в Telegram по правилам маршрутизации:
+// шаблон ключа маршрутизации, условие над содержимым события, шаблон текста, чат и ограничение частоты.
+package alerting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// defaultTemplate используется правилами без шаблона
+const defaultTemplate = `<b>{{.Rule}}</b>
+{{.RoutingKey}}
+<pre>{{.PayloadJSON}}</pre>`
+
+// defaultRateInterval интервал ограничения частоты, если задан только RateLimit
+const defaultRateInterval = time.Minute
+
+// Duration длительность, задаваемая в конфигурации строкой вида "30s" или "5m"
+type Duration time.Duration
+
+// UnmarshalJSON разбирает длительность из строки
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string like \"5m\": %v", err)
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON записывает длительность строкой
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Rule правило пересылки события в Telegram
+type Rule struct {
+	// Name имя правила для логов и метрик
+	Name string `json:"name"`
+	// RoutingKey шаблон ключа маршрутизации ("order.created", "service.*", "billing.#")
+	RoutingKey string `json:"routing_key"`
+	// Condition условие над содержимым события, например `$.amount > 50000 && $.currency == "RUB"`.
+	// Пустое условие выполняется для любого события.
+	Condition string `json:"condition,omitempty"`
+	// Template имя шаблона из RuleSet.Templates; по умолчанию выводится содержимое события
+	Template string `json:"template,omitempty"`
+	// ChatID чат Telegram; по умолчанию чат клиента
+	ChatID string `json:"chat_id,omitempty"`
+	// RateLimit максимальное количество уведомлений за RateInterval (0 - без ограничения)
+	RateLimit    int      `json:"rate_limit,omitempty"`
+	RateInterval Duration `json:"rate_interval,omitempty"`
+	// DedupKey путь к полю события, по которому повторные уведомления подавляются в течение DedupWindow
+	DedupKey    string   `json:"dedup_key,omitempty"`
+	DedupWindow Duration `json:"dedup_window,omitempty"`
+}
+
+// RuleSet набор правил и шаблонов текста уведомлений (html/template, parse mode HTML)
+type RuleSet struct {
+	Rules     []Rule            `json:"rules"`
+	Templates map[string]string `json:"templates,omitempty"`
+}
+
+// compiledRule правило с разобранными условием и шаблоном
+type compiledRule struct {
+	Rule
+	condition []condition
+	template  *template.Template
+}
+
+// compileRuleSet проверяет и разбирает набор правил
+func compileRuleSet(set *RuleSet) ([]*compiledRule, error) {
+	templates := make(map[string]*template.Template, len(set.Templates)+1)
+	defaultTmpl, err := template.New("default").Parse(defaultTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, text := range set.Templates {
+		tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %v", name, err)
+		}
+		templates[name] = tmpl
+	}
+
+	rules := make([]*compiledRule, 0, len(set.Rules))
+	names := make(map[string]bool, len(set.Rules))
+	for i, rule := range set.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule #%d: name is required", i+1)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("rule %s: duplicate name", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if rule.RoutingKey == "" {
+			return nil, fmt.Errorf("rule %s: routing_key is required", rule.Name)
+		}
+
+		compiled := &compiledRule{Rule: rule, template: defaultTmpl}
+
+		if compiled.condition, err = parseCondition(rule.Condition); err != nil {
+			return nil, fmt.Errorf("rule %s: %v", rule.Name, err)
+		}
+
+		if rule.Template != "" {
+			tmpl, ok := templates[rule.Template]
+			if !ok {
+				return nil, fmt.Errorf("rule %s: unknown template %s", rule.Name, rule.Template)
+			}
+			compiled.template = tmpl
+		}
+
+		if rule.DedupKey != "" {
+			if _, err := parsePath(rule.DedupKey); err != nil {
+				return nil, fmt.Errorf("rule %s: dedup_key: %v", rule.Name, err)
+			}
+		}
+		if rule.RateLimit > 0 && rule.RateInterval <= 0 {
+			compiled.RateInterval = Duration(defaultRateInterval)
+		}
+
+		rules = append(rules, compiled)
+	}
+
+	return rules, nil
+}
+
+// templateData данные, доступные в шаблоне уведомления
+type templateData struct {
+	Rule        string
+	RoutingKey  string
+	Payload     interface{}
+	PayloadJSON string
+	Time        time.Time
+}
+
+// render формирует текст уведомления
+func (r *compiledRule) render(routingKey string, payload interface{}, raw []byte, now time.Time) (string, error) {
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, raw, "", "  "); err != nil {
+		pretty.Reset()
+		pretty.Write(raw)
+	}
+
+	var text strings.Builder
+	err := r.template.Execute(&text, templateData{
+		Rule:        r.Name,
+		RoutingKey:  routingKey,
+		Payload:     payload,
+		PayloadJSON: pretty.String(),
+		Time:        now,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render alert %s: %v", r.Name, err)
+	}
+	return text.String(), nil
+}
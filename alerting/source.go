@@ -0,0 +1,129 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/vladzorgan/common/redis"
+)
+
+// defaultWatchInterval интервал проверки источника правил по умолчанию
+const defaultWatchInterval = 30 * time.Second
+
+// Source источник набора правил в формате JSON
+type Source interface {
+	// Load возвращает содержимое набора правил
+	Load(ctx context.Context) ([]byte, error)
+}
+
+// FileSource читает правила из файла
+type FileSource struct {
+	Path string
+}
+
+// Load читает файл с правилами
+func (s FileSource) Load(ctx context.Context) ([]byte, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert rules: %v", err)
+	}
+	return data, nil
+}
+
+// RedisSource читает правила из ключа Redis, что позволяет менять их без перезапуска сервисов
+type RedisSource struct {
+	Client *redis.Client
+	Key    string
+}
+
+// Load читает значение ключа с правилами
+func (s RedisSource) Load(ctx context.Context) ([]byte, error) {
+	value, err := s.Client.Get(ctx, s.Key)
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return nil, fmt.Errorf("alert rules key %s not found", s.Key)
+	}
+	return []byte(value), nil
+}
+
+// ParseRuleSet разбирает набор правил из JSON, отклоняя неизвестные поля
+func ParseRuleSet(data []byte) (*RuleSet, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var set RuleSet
+	if err := dec.Decode(&set); err != nil {
+		return nil, fmt.Errorf("invalid alert rules: %v", err)
+	}
+	return &set, nil
+}
+
+// Load загружает и применяет правила из источника
+func (b *Bridge) Load(ctx context.Context, source Source) error {
+	_, err := b.reload(ctx, source, nil)
+	return err
+}
+
+// Watch применяет правила из источника и перечитывает их каждые interval до отмены ctx.
+// Правила применяются только при изменении содержимого; при ошибке остаются прежние правила.
+func (b *Bridge) Watch(ctx context.Context, source Source, interval time.Duration) error {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	current, err := b.reload(ctx, source, nil)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				data, err := b.reload(ctx, source, current)
+				if err != nil {
+					b.logger.Error("Failed to reload alert rules, keeping previous rules: %v", err)
+					continue
+				}
+				current = data
+			}
+		}
+	}()
+
+	return nil
+}
+
+// reload читает источник и применяет правила, если содержимое отличается от previous
+func (b *Bridge) reload(ctx context.Context, source Source, previous []byte) ([]byte, error) {
+	data, err := source.Load(ctx)
+	if err != nil {
+		return previous, err
+	}
+	if previous != nil && bytes.Equal(data, previous) {
+		return previous, nil
+	}
+
+	set, err := ParseRuleSet(data)
+	if err != nil {
+		return previous, err
+	}
+	if err := b.SetRules(set); err != nil {
+		return previous, err
+	}
+
+	if previous != nil {
+		b.logger.Info("Alert rules reloaded: %d rules", len(set.Rules))
+	}
+	return data, nil
+}
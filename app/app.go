@@ -0,0 +1,253 @@
+// Package app предоставляет запуск и корректную остановку компонентов сервиса:
+// HTTP и gRPC серверов, потребителей сообщений и соединений с хранилищами.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/vladzorgan/common/config"
+	commongrpc "github.com/vladzorgan/common/grpc"
+	"github.com/vladzorgan/common/health"
+	commonhttp "github.com/vladzorgan/common/http"
+	"github.com/vladzorgan/common/logging"
+	events "github.com/vladzorgan/common/messaging/rabbitmq"
+	"github.com/vladzorgan/common/profiling"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// defaultShutdownTimeout срок остановки, если он не задан в конфигурации
+const defaultShutdownTimeout = 30 * time.Second
+
+// component представляет запускаемый и останавливаемый компонент приложения
+type component struct {
+	name string
+	// Блокирующий запуск; nil, если компонент уже запущен при создании
+	start func() error
+	// Остановка в пределах срока ctx; nil, если остановка не требуется
+	stop func(ctx context.Context) error
+}
+
+// App запускает компоненты сервиса и останавливает их в обратном порядке
+// при получении SIGINT/SIGTERM, отмене контекста или ошибке запуска.
+type App struct {
+	logger     logging.Logger
+	components []component
+
+	shutdownTimeout time.Duration
+	drainDelay      time.Duration
+
+	// Снятие готовности перед остановкой
+	draining    atomic.Bool
+	readiness   *readinessComponent
+	checkers    []*health.Checker
+	grpcServers []*commongrpc.Server
+}
+
+// New создает приложение. Сроки остановки берутся из cfg (ShutdownTimeout, ShutdownDrainDelay).
+func New(cfg *config.BaseConfig, logger logging.Logger) *App {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	a := &App{
+		logger:          logger,
+		shutdownTimeout: defaultShutdownTimeout,
+	}
+	a.readiness = &readinessComponent{draining: &a.draining}
+
+	if cfg != nil {
+		if cfg.ShutdownTimeout > 0 {
+			a.shutdownTimeout = cfg.ShutdownTimeout
+		}
+		a.drainDelay = cfg.ShutdownDrainDelay
+
+		// Метки pprof нужны и сервисам без HTTP/gRPC сервера, обрабатывающим только сообщения
+		profiling.Enable(cfg.ProfilingLabels)
+	}
+
+	return a
+}
+
+// WithShutdownTimeout устанавливает общий срок остановки всех компонентов
+func (a *App) WithShutdownTimeout(timeout time.Duration) *App {
+	a.shutdownTimeout = timeout
+	return a
+}
+
+// WithDrainDelay устанавливает паузу между снятием готовности и остановкой компонентов
+func (a *App) WithDrainDelay(delay time.Duration) *App {
+	a.drainDelay = delay
+	return a
+}
+
+// WithHTTP добавляет HTTP сервер. Проверка готовности сервера начинает возвращать
+// 503 с причиной "shutting_down", как только приложение начинает остановку.
+func (a *App) WithHTTP(server *commonhttp.Server) *App {
+	if checker := server.HealthChecker(); checker != nil {
+		checker.RegisterComponent(a.readiness)
+		a.checkers = append(a.checkers, checker)
+	}
+
+	return a.WithComponent("http", server.Start, server.Shutdown)
+}
+
+// WithGRPC добавляет gRPC сервер. При остановке сервер сначала сообщает NOT_SERVING
+// через стандартную проверку здоровья gRPC, а по истечении срока останавливается принудительно.
+func (a *App) WithGRPC(server *commongrpc.Server) *App {
+	a.grpcServers = append(a.grpcServers, server)
+
+	return a.WithComponent("grpc", server.Start, func(ctx context.Context) error {
+		server.StopWithContext(ctx)
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("gRPC server stopped forcefully: %w", err)
+		}
+		return nil
+	})
+}
+
+// WithConsumer добавляет потребителя сообщений. Потребитель начинает получать
+// сообщения при создании, поэтому приложение отвечает только за его остановку.
+func (a *App) WithConsumer(consumer *events.Consumer) *App {
+	return a.WithComponent("rabbitmq consumer", nil, consumer.Shutdown)
+}
+
+// WithCloser добавляет ресурс, закрываемый при остановке (например, *database.Database или *redis.Client)
+func (a *App) WithCloser(closer io.Closer) *App {
+	return a.WithComponent(fmt.Sprintf("%T", closer), nil, func(ctx context.Context) error {
+		return closer.Close()
+	})
+}
+
+// WithComponent добавляет произвольный компонент. start блокируется до остановки компонента
+// и может быть nil; stop вызывается при остановке приложения и тоже может быть nil.
+func (a *App) WithComponent(name string, start func() error, stop func(ctx context.Context) error) *App {
+	a.components = append(a.components, component{name: name, start: start, stop: stop})
+	return a
+}
+
+// Run запускает компоненты в порядке добавления и ждет SIGINT/SIGTERM, отмены ctx
+// или ошибки запуска любого компонента. Затем снимает готовность, выжидает паузу
+// и останавливает компоненты в обратном порядке в пределах общего срока.
+// Возвращает ошибки запуска и остановки, объединенные errors.Join.
+func (a *App) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	startErrs := make(chan error, len(a.components))
+	for _, c := range a.components {
+		if c.start == nil {
+			continue
+		}
+
+		go func(c component) {
+			if err := c.start(); err != nil {
+				startErrs <- fmt.Errorf("%s: %w", c.name, err)
+			}
+		}(c)
+	}
+
+	a.logger.Info("Application started with %d components", len(a.components))
+
+	var errs []error
+	select {
+	case <-ctx.Done():
+		a.logger.Info("Shutdown requested")
+	case err := <-startErrs:
+		a.logger.Error("Component failed, shutting down: %v", err)
+		errs = append(errs, err)
+	}
+
+	errs = append(errs, a.shutdown())
+	return errors.Join(errs...)
+}
+
+// shutdown снимает готовность и останавливает компоненты в обратном порядке
+func (a *App) shutdown() error {
+	a.draining.Store(true)
+	for _, checker := range a.checkers {
+		checker.SetReady(false)
+	}
+	for _, server := range a.grpcServers {
+		server.SetServiceStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+
+	if a.drainDelay > 0 {
+		a.logger.Info("Readiness is down, waiting %s before stopping components", a.drainDelay)
+		time.Sleep(a.drainDelay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(a.components) - 1; i >= 0; i-- {
+		c := a.components[i]
+		if c.stop == nil {
+			continue
+		}
+
+		if err := a.stopComponent(ctx, c); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		a.logger.Info("Application stopped")
+	}
+	return errors.Join(errs...)
+}
+
+// stopComponent останавливает компонент, не дожидаясь его дольше срока ctx
+func (a *App) stopComponent(ctx context.Context, c component) error {
+	started := time.Now()
+	done := make(chan error, 1)
+
+	go func() {
+		done <- c.stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			a.logger.Error("Failed to stop %s: %v", c.name, err)
+			return fmt.Errorf("%s: %w", c.name, err)
+		}
+		a.logger.Info("Stopped %s in %s", c.name, time.Since(started).Round(time.Millisecond))
+		return nil
+	case <-ctx.Done():
+		a.logger.Error("Timed out stopping %s after %s", c.name, time.Since(started).Round(time.Millisecond))
+		return fmt.Errorf("%s: shutdown timed out: %w", c.name, ctx.Err())
+	}
+}
+
+// readinessComponent сообщает о неготовности сервиса во время остановки
+type readinessComponent struct {
+	draining *atomic.Bool
+}
+
+// Name возвращает имя компонента
+func (r *readinessComponent) Name() string {
+	return "shutdown"
+}
+
+// Check возвращает StatusDown, если приложение останавливается
+func (r *readinessComponent) Check(ctx context.Context) (health.Status, error) {
+	if r.draining.Load() {
+		return health.StatusDown, errors.New("service is shutting down")
+	}
+	return health.StatusUp, nil
+}
+
+// IsCritical возвращает true: во время остановки сервис не должен получать запросы
+func (r *readinessComponent) IsCritical() bool {
+	return true
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunStopsComponentsInReverseOrder(t *testing.T) {
+	var stopped []string
+	stopFunc := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			stopped = append(stopped, name)
+			return nil
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a := New(nil, nil).
+		WithComponent("db", nil, stopFunc("db")).
+		WithComponent("consumer", nil, stopFunc("consumer")).
+		WithComponent("http", func() error {
+			cancel()
+			return nil
+		}, stopFunc("http"))
+
+	if err := a.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if got := strings.Join(stopped, ","); got != "http,consumer,db" {
+		t.Errorf("stop order = %s, want http,consumer,db", got)
+	}
+}
+
+func TestRunReportsStartFailureAndTimeouts(t *testing.T) {
+	startErr := errors.New("address already in use")
+
+	a := New(nil, nil).
+		WithShutdownTimeout(50*time.Millisecond).
+		WithComponent("stuck", nil, func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}).
+		WithComponent("http", func() error {
+			return startErr
+		}, nil)
+
+	err := a.Run(context.Background())
+	if !errors.Is(err, startErr) {
+		t.Errorf("Run() error = %v, want start failure", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run() error = %v, want shutdown timeout", err)
+	}
+}
+
+func TestReadinessGoesDownWhenDraining(t *testing.T) {
+	a := New(nil, nil)
+
+	if status, _ := a.readiness.Check(context.Background()); status != "up" {
+		t.Fatalf("status before shutdown = %s, want up", status)
+	}
+
+	if err := a.shutdown(); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+
+	if status, _ := a.readiness.Check(context.Background()); status != "down" {
+		t.Errorf("status after shutdown = %s, want down", status)
+	}
+}
@@ -83,6 +83,12 @@ func (cm *ContextManager) ExtractUserFromMetadata(ctx context.Context) (*User, e
 		user.Role = UserRole(roleValues[0])
 	}
 
+	// Арендатор пользователя передается шлюзом или вызывающим сервисом отдельно от запрошенного
+	// x-tenant-id (см. interceptors.OutgoingContext)
+	if tenantValues := md.Get(UserTenantMetadataKey); len(tenantValues) > 0 {
+		user.TenantID = parseUserTenant(tenantValues[0])
+	}
+
 	return user, nil
 }
 
@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Ошибки авторизации HTTP запросов
+var (
+	errInvalidAuthorizationHeader = errors.New("неверный формат заголовка авторизации")
+	errInvalidUserIDHeader        = errors.New("неверный формат ID пользователя")
+	errUserNotFound               = errors.New("пользователь не найден")
+)
+
+// GinOptions содержит опции middleware авторизации для HTTP сервисов
+type GinOptions struct {
+	// Заголовок с ID пользователя, выставляемый шлюзом
+	UserIDHeader string
+	// Заголовок с ролью пользователя, выставляемый шлюзом
+	UserRoleHeader string
+	// Валидатор Bearer токенов (nil - токены не проверяются)
+	Validator TokenValidator
+	// Отклонять запросы без пользователя с кодом 401
+	Required bool
+	// Заголовок с ID арендатора (пустой - TenantHeader)
+	TenantHeader string
+	// Заголовок с арендатором пользователя, выставляемый шлюзом (пустой - UserTenantHeader)
+	UserTenantHeader string
+}
+
+// DefaultGinOptions возвращает опции по умолчанию
+func DefaultGinOptions() *GinOptions {
+	return &GinOptions{
+		UserIDHeader:     "X-User-ID",
+		UserRoleHeader:   "X-User-Role",
+		TenantHeader:     TenantHeader,
+		UserTenantHeader: UserTenantHeader,
+	}
+}
+
+// GinMiddleware возвращает middleware, заполняющее авторизационный контекст из заголовков шлюза
+// или Bearer токена. Пользователь сохраняется в gin.Context и в контексте запроса через WithUser,
+// поэтому фильтр владения BaseRepository работает без изменений. Арендатор берется из заголовка
+// X-Tenant-ID или из данных пользователя (провайдер, токен или заголовок шлюза X-User-Tenant-ID);
+// чужого арендатора может выбрать только администратор.
+func GinMiddleware(userProvider UserProvider, options *GinOptions) gin.HandlerFunc {
+	if options == nil {
+		options = DefaultGinOptions()
+	}
+
+	return func(c *gin.Context) {
+		user, err := extractGinUser(c, userProvider, options)
+		if err != nil {
+			abortUnauthorized(c, err.Error())
+			return
+		}
+
+		if user == nil && options.Required {
+			abortUnauthorized(c, "Authorization is required")
+			return
+		}
+
+		tenantHeader := options.TenantHeader
+		if tenantHeader == "" {
+			tenantHeader = TenantHeader
+		}
+		tenantID, err := resolveTenant(c.GetHeader(tenantHeader), user)
+		if err != nil {
+			abortWithAuthError(c, err)
+			return
+		}
+
+		ctx := c.Request.Context()
+		if user != nil {
+			// Сохраняем пользователя в gin.Context и в контексте запроса
+			c.Set(string(UserContextKey), user)
+			ctx = WithUser(ctx, user)
+		}
+		if tenantID != 0 {
+			c.Set(string(TenantContextKey), tenantID)
+			ctx = WithTenant(ctx, tenantID)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// extractGinUser определяет пользователя по Bearer токену или заголовкам шлюза.
+// Возвращает nil без ошибки, если данные о пользователе отсутствуют.
+func extractGinUser(c *gin.Context, userProvider UserProvider, options *GinOptions) (*User, error) {
+	var user *User
+
+	authHeader := c.GetHeader("Authorization")
+	if options.Validator != nil && authHeader != "" {
+		token := strings.TrimPrefix(authHeader, "Bearer ")
+		if token == authHeader || token == "" {
+			return nil, errInvalidAuthorizationHeader
+		}
+
+		claims, err := options.Validator.ValidateToken(c.Request.Context(), token)
+		if err != nil {
+			return nil, err
+		}
+
+		user, err = claims.User()
+		if err != nil {
+			return nil, err
+		}
+	} else if userIDHeader := c.GetHeader(options.UserIDHeader); userIDHeader != "" {
+		userID, err := strconv.ParseUint(userIDHeader, 10, 32)
+		if err != nil {
+			return nil, errInvalidUserIDHeader
+		}
+
+		role, err := ParseUserRole(c.GetHeader(options.UserRoleHeader))
+		if err != nil {
+			return nil, err
+		}
+
+		userTenantHeader := options.UserTenantHeader
+		if userTenantHeader == "" {
+			userTenantHeader = UserTenantHeader
+		}
+
+		// Заголовки выставляет доверенный шлюз, поэтому пользователь считается активным
+		user = &User{
+			ID:       uint(userID),
+			IsActive: true,
+			Role:     role,
+			TenantID: parseUserTenant(c.GetHeader(userTenantHeader)),
+		}
+	} else {
+		return nil, nil
+	}
+
+	// Загружаем пользователя через провайдер
+	if userProvider != nil {
+		loaded, err := userProvider.GetUserByID(c.Request.Context(), user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if loaded == nil {
+			return nil, errUserNotFound
+		}
+		user = loaded
+	}
+
+	return user, nil
+}
+
+// RequireAuthGin возвращает middleware, требующее авторизованного пользователя
+func RequireAuthGin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := RequireAuth(c.Request.Context()); err != nil {
+			abortWithAuthError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAdminGin возвращает middleware, требующее права администратора
+func RequireAdminGin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := RequireAdmin(c.Request.Context()); err != nil {
+			abortWithAuthError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRoleGin возвращает middleware, требующее определенную роль
+func RequireRoleGin(role UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := RequireRole(c.Request.Context(), role); err != nil {
+			abortWithAuthError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// abortWithAuthError прерывает запрос с кодом 401, 400 или 403 в зависимости от ошибки
+func abortWithAuthError(c *gin.Context, err error) {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.Unauthenticated:
+		abortUnauthorized(c, st.Message())
+		return
+	case codes.InvalidArgument:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": st.Message(),
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error":   "Forbidden",
+		"message": st.Message(),
+	})
+}
+
+// abortUnauthorized прерывает запрос с кодом 401
+func abortUnauthorized(c *gin.Context, message string) {
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"error":   "Unauthorized",
+		"message": message,
+	})
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mapUserProvider провайдер пользователей из памяти
+type mapUserProvider map[uint]*User
+
+func (p mapUserProvider) GetUserByID(ctx context.Context, userID uint) (*User, error) {
+	return p[userID], nil
+}
+
+// ginResult результат тестового запроса
+type ginResult struct {
+	status int
+	body   map[string]string
+	user   *User
+}
+
+// serveGin выполняет GET / через middleware и возвращает статус, JSON ответа и пользователя
+// из контекста запроса (его читает фильтр владения BaseRepository)
+func serveGin(t *testing.T, headers map[string]string, middleware ...gin.HandlerFunc) ginResult {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var result ginResult
+	router := gin.New()
+	router.Use(middleware...)
+	router.GET("/", func(c *gin.Context) {
+		result.user, _ = GetUserFromContext(c.Request.Context())
+		if value, _ := c.Get(string(UserContextKey)); result.user != nil && value != result.user {
+			t.Errorf("gin.Context user = %v, want the request context user", value)
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	result.status = rec.Code
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &result.body); err != nil {
+			t.Fatalf("response body %q: %v", rec.Body.String(), err)
+		}
+	}
+	return result
+}
+
+func TestGinMiddlewareUserFromHeaders(t *testing.T) {
+	got := serveGin(t, map[string]string{"X-User-ID": "5", "X-User-Role": "service_owner"}, GinMiddleware(nil, nil))
+	if got.status != http.StatusOK || got.user == nil {
+		t.Fatalf("status = %d, user = %v; want 200 with user", got.status, got.user)
+	}
+	if got.user.ID != 5 || got.user.Role != UserRole_ServiceOwner || !got.user.IsActive {
+		t.Errorf("user = %+v, want active service_owner 5", got.user)
+	}
+
+	// Без заголовков запрос проходит анонимно, с Required - отклоняется
+	if got := serveGin(t, nil, GinMiddleware(nil, nil)); got.status != http.StatusOK || got.user != nil {
+		t.Errorf("anonymous: status = %d, user = %v; want 200 without user", got.status, got.user)
+	}
+	options := DefaultGinOptions()
+	options.Required = true
+	if got := serveGin(t, nil, GinMiddleware(nil, options)); got.status != http.StatusUnauthorized || got.body["error"] != "Unauthorized" {
+		t.Errorf("required: status = %d, body = %v; want 401 Unauthorized", got.status, got.body)
+	}
+}
+
+func TestGinMiddlewareBearerTokenOverHeaders(t *testing.T) {
+	options := DefaultGinOptions()
+	options.Validator = NewJWTValidator("secret")
+
+	got := serveGin(t, map[string]string{
+		"Authorization": "Bearer " + signHS256(t, "secret", validClaims()),
+		"X-User-ID":     "5",
+	}, GinMiddleware(nil, options))
+	if got.status != http.StatusOK || got.user == nil || got.user.ID != 42 || got.user.Role != UserRole_Admin {
+		t.Errorf("status = %d, user = %+v; want 200 with admin 42 from token", got.status, got.user)
+	}
+
+	tests := map[string]string{
+		"invalid signature": "Bearer " + signHS256(t, "other", validClaims()),
+		"not bearer":        "Basic abc",
+	}
+	for name, header := range tests {
+		got := serveGin(t, map[string]string{"Authorization": header, "X-User-ID": "5"}, GinMiddleware(nil, options))
+		if got.status != http.StatusUnauthorized || got.body["error"] != "Unauthorized" || got.body["message"] == "" {
+			t.Errorf("%s: status = %d, body = %v; want 401 with message", name, got.status, got.body)
+		}
+	}
+}
+
+func TestGinMiddlewareUserProvider(t *testing.T) {
+	provider := mapUserProvider{5: {ID: 5, Username: "loaded", Role: UserRole_Admin, IsActive: true}}
+
+	got := serveGin(t, map[string]string{"X-User-ID": "5"}, GinMiddleware(provider, nil))
+	if got.status != http.StatusOK || got.user == nil || got.user.Username != "loaded" || got.user.Role != UserRole_Admin {
+		t.Errorf("status = %d, user = %+v; want user loaded by provider", got.status, got.user)
+	}
+
+	got = serveGin(t, map[string]string{"X-User-ID": "6"}, GinMiddleware(provider, nil))
+	if got.status != http.StatusUnauthorized || got.body["message"] != errUserNotFound.Error() {
+		t.Errorf("not found: status = %d, body = %v; want 401 %q", got.status, got.body, errUserNotFound)
+	}
+}
+
+func TestGinMiddlewareCustomHeaders(t *testing.T) {
+	options := &GinOptions{UserIDHeader: "X-Auth-User", UserRoleHeader: "X-Auth-Role"}
+
+	got := serveGin(t, map[string]string{"X-Auth-User": "7", "X-Auth-Role": "admin"}, GinMiddleware(nil, options))
+	if got.status != http.StatusOK || got.user == nil || got.user.ID != 7 || got.user.Role != UserRole_Admin {
+		t.Errorf("status = %d, user = %+v; want admin 7", got.status, got.user)
+	}
+
+	// Стандартные заголовки не используются
+	if got := serveGin(t, map[string]string{"X-User-ID": "7"}, GinMiddleware(nil, options)); got.user != nil {
+		t.Errorf("user = %+v, want none from default header", got.user)
+	}
+
+	got = serveGin(t, map[string]string{"X-Auth-User": "abc"}, GinMiddleware(nil, options))
+	if got.status != http.StatusUnauthorized || got.body["message"] != errInvalidUserIDHeader.Error() {
+		t.Errorf("invalid id: status = %d, body = %v; want 401 %q", got.status, got.body, errInvalidUserIDHeader)
+	}
+}
+
+func TestRequireAdminAndRoleGin(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		require gin.HandlerFunc
+		status  int
+		error   string
+	}{
+		{"admin anonymous", nil, RequireAdminGin(), http.StatusUnauthorized, "Unauthorized"},
+		{"admin as user", map[string]string{"X-User-ID": "1"}, RequireAdminGin(), http.StatusForbidden, "Forbidden"},
+		{"admin as admin", map[string]string{"X-User-ID": "1", "X-User-Role": "admin"}, RequireAdminGin(), http.StatusOK, ""},
+		{"role anonymous", nil, RequireRoleGin(UserRole_ServiceOwner), http.StatusUnauthorized, "Unauthorized"},
+		{"role mismatch", map[string]string{"X-User-ID": "1"}, RequireRoleGin(UserRole_ServiceOwner), http.StatusForbidden, "Forbidden"},
+		{"role match", map[string]string{"X-User-ID": "1", "X-User-Role": "service_owner"}, RequireRoleGin(UserRole_ServiceOwner), http.StatusOK, ""},
+		{"role as admin", map[string]string{"X-User-ID": "1", "X-User-Role": "admin"}, RequireRoleGin(UserRole_ServiceOwner), http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		got := serveGin(t, tt.headers, GinMiddleware(nil, nil), tt.require)
+		if got.status != tt.status || got.body["error"] != tt.error {
+			t.Errorf("%s: status = %d, body = %v; want %d %q", tt.name, got.status, got.body, tt.status, tt.error)
+		}
+		if tt.error != "" && got.body["message"] == "" {
+			t.Errorf("%s: empty error message", tt.name)
+		}
+	}
+}
@@ -39,6 +39,10 @@ func (ai *AuthInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
 	) (interface{}, error) {
 		// Проверяем, нужна ли авторизация для этого метода
 		if ai.skipMethods[info.FullMethod] {
+			ctx, err := withTenantFromMetadata(ctx, nil)
+			if err != nil {
+				return nil, err
+			}
 			return handler(ctx, req)
 		}
 
@@ -48,8 +52,11 @@ func (ai *AuthInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
 			return nil, status.Errorf(codes.Unauthenticated, "Ошибка авторизации: %v", err)
 		}
 
-		// Добавляем пользователя в контекст
-		ctx = WithUser(ctx, user)
+		// Добавляем пользователя и арендатора в контекст
+		ctx, err = withTenantFromMetadata(WithUser(ctx, user), user)
+		if err != nil {
+			return nil, err
+		}
 
 		// Вызываем обработчик с обновленным контекстом
 		return handler(ctx, req)
@@ -66,7 +73,11 @@ func (ai *AuthInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
 	) error {
 		// Проверяем, нужна ли авторизация для этого метода
 		if ai.skipMethods[info.FullMethod] {
-			return handler(srv, ss)
+			ctx, err := withTenantFromMetadata(ss.Context(), nil)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &wrappedServerStream{ServerStream: ss, ctx: ctx})
 		}
 
 		// Извлекаем пользователя из метаданных
@@ -75,10 +86,16 @@ func (ai *AuthInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
 			return status.Errorf(codes.Unauthenticated, "Ошибка авторизации: %v", err)
 		}
 
+		// Добавляем пользователя и арендатора в контекст stream
+		ctx, err := withTenantFromMetadata(WithUser(ss.Context(), user), user)
+		if err != nil {
+			return err
+		}
+
 		// Создаем обертку для stream с обновленным контекстом
 		wrappedStream := &wrappedServerStream{
 			ServerStream: ss,
-			ctx:          WithUser(ss.Context(), user),
+			ctx:          ctx,
 		}
 
 		// Вызываем обработчик с обновленным stream
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrTokenUnknownKey возвращается, если ключ токена отсутствует в JWKS
+var ErrTokenUnknownKey = errors.New("ключ подписи токена не найден")
+
+// jwksMinRefreshInterval ограничивает частоту внеплановых обновлений JWKS при неизвестном kid
+const jwksMinRefreshInterval = time.Minute
+
+// jwk представляет ключ RSA из JWKS
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// jwksCache хранит ключи JWKS с ограниченным временем жизни
+type jwksCache struct {
+	url        string
+	cacheTTL   time.Duration
+	httpClient *http.Client
+	keys       map[string]*rsa.PublicKey
+	fetchedAt  time.Time
+	mutex      sync.RWMutex
+}
+
+// NewJWKSValidator создает валидатор токенов, получающий открытые ключи RSA из JWKS
+func NewJWKSValidator(jwksURL string, cacheTTL time.Duration) *JWTValidator {
+	cache := &jwksCache{
+		url:        jwksURL,
+		cacheTTL:   cacheTTL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+
+	return &JWTValidator{
+		keyFunc: func(ctx context.Context, alg, kid string) (interface{}, error) {
+			if !strings.HasPrefix(alg, "RS") {
+				return nil, ErrTokenUnsupportedAlg
+			}
+			return cache.getKey(ctx, kid)
+		},
+		options: DefaultValidatorOptions(),
+	}
+}
+
+// getKey возвращает ключ по kid, обновляя кеш при истечении TTL или неизвестном kid
+func (c *jwksCache) getKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	c.mutex.RLock()
+	key, ok := c.keys[kid]
+	expired := time.Since(c.fetchedAt) > c.cacheTTL
+	canRefresh := time.Since(c.fetchedAt) > jwksMinRefreshInterval
+	c.mutex.RUnlock()
+
+	if ok && !expired {
+		return key, nil
+	}
+
+	// Обновляем ключи при истечении TTL или ротации ключей
+	if expired || canRefresh {
+		if err := c.refresh(ctx); err != nil {
+			// Если ключ есть в устаревшем кеше, используем его
+			if ok {
+				return key, nil
+			}
+			return nil, err
+		}
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	key, ok = c.keys[kid]
+	if !ok {
+		return nil, ErrTokenUnknownKey
+	}
+	return key, nil
+}
+
+// refresh загружает ключи из JWKS
+func (c *jwksCache) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create JWKS request: %v", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %v", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(body.Keys))
+	for _, k := range body.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+
+		publicKey, err := k.rsaPublicKey()
+		if err != nil {
+			return fmt.Errorf("invalid JWKS key %s: %v", k.Kid, err)
+		}
+		keys[k.Kid] = publicKey
+	}
+
+	c.mutex.Lock()
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	c.mutex.Unlock()
+
+	return nil
+}
+
+// rsaPublicKey преобразует JWK в открытый ключ RSA
+func (k *jwk) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// jwksServer тестовый JWKS endpoint с подменяемым набором ключей
+type jwksServer struct {
+	mutex    sync.Mutex
+	keys     map[string]*rsa.PublicKey
+	fail     bool
+	requests int
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.requests++
+	if s.fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var body struct {
+		Keys []jwk `json:"keys"`
+	}
+	for kid, key := range s.keys {
+		body.Keys = append(body.Keys, jwk{
+			Kty: "RSA",
+			Kid: kid,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	json.NewEncoder(w).Encode(body)
+}
+
+func (s *jwksServer) set(keys map[string]*rsa.PublicKey, fail bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.keys, s.fail = keys, fail
+}
+
+func (s *jwksServer) count() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests
+}
+
+func TestJWKSValidatorLooksUpKeyByKid(t *testing.T) {
+	key := rsaTestKey(t)
+	server := &jwksServer{keys: map[string]*rsa.PublicKey{"key-1": &key.PublicKey}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	validator := NewJWKSValidator(ts.URL, time.Hour)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := validator.ValidateToken(ctx, signRS256(t, key, "key-1", validClaims())); err != nil {
+			t.Fatalf("ValidateToken() error = %v", err)
+		}
+	}
+	if n := server.count(); n != 1 {
+		t.Errorf("JWKS requests = %d, want 1 (keys cached)", n)
+	}
+
+	// Неизвестный kid сразу после загрузки не вызывает повторный запрос
+	if _, err := validator.ValidateToken(ctx, signRS256(t, key, "key-2", validClaims())); !errors.Is(err, ErrTokenUnknownKey) {
+		t.Errorf("ValidateToken() unknown kid error = %v, want ErrTokenUnknownKey", err)
+	}
+	if n := server.count(); n != 1 {
+		t.Errorf("JWKS requests = %d, want 1 (refresh rate-limited)", n)
+	}
+
+	// HMAC токен не принимается валидатором JWKS
+	if _, err := validator.ValidateToken(ctx, signHS256(t, "secret", validClaims())); !errors.Is(err, ErrTokenUnsupportedAlg) {
+		t.Errorf("ValidateToken() HS256 error = %v, want ErrTokenUnsupportedAlg", err)
+	}
+}
+
+func TestJWKSCacheRefresh(t *testing.T) {
+	key := rsaTestKey(t)
+	server := &jwksServer{keys: map[string]*rsa.PublicKey{"key-1": &key.PublicKey}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	cache := &jwksCache{url: ts.URL, cacheTTL: time.Hour, httpClient: ts.Client()}
+	ctx := context.Background()
+	if _, err := cache.getKey(ctx, "key-1"); err != nil {
+		t.Fatalf("getKey() error = %v", err)
+	}
+
+	// Ротация ключей: неизвестный kid после минимального интервала загружает новый набор
+	server.set(map[string]*rsa.PublicKey{"key-2": &key.PublicKey}, false)
+	cache.fetchedAt = time.Now().Add(-2 * jwksMinRefreshInterval)
+	if _, err := cache.getKey(ctx, "key-2"); err != nil {
+		t.Fatalf("getKey() after rotation error = %v", err)
+	}
+	if n := server.count(); n != 2 {
+		t.Errorf("JWKS requests = %d, want 2", n)
+	}
+
+	// По истечении TTL ключи перезагружаются; при ошибке используется устаревший ключ
+	server.set(nil, true)
+	cache.fetchedAt = time.Now().Add(-2 * time.Hour)
+	if _, err := cache.getKey(ctx, "key-2"); err != nil {
+		t.Errorf("getKey() with stale cache error = %v", err)
+	}
+	if n := server.count(); n != 3 {
+		t.Errorf("JWKS requests = %d, want 3", n)
+	}
+
+	cache.fetchedAt = time.Now().Add(-2 * time.Hour)
+	if _, err := cache.getKey(ctx, "key-3"); err == nil || errors.Is(err, ErrTokenUnknownKey) {
+		t.Errorf("getKey() with failing JWKS error = %v, want fetch error", err)
+	}
+}
@@ -0,0 +1,310 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ошибки валидации токенов
+var (
+	ErrTokenMalformed        = errors.New("токен имеет неверный формат")
+	ErrTokenUnsupportedAlg   = errors.New("алгоритм подписи токена не поддерживается")
+	ErrTokenSignatureInvalid = errors.New("неверная подпись токена")
+	ErrTokenExpired          = errors.New("срок действия токена истек")
+	ErrTokenMissingExpiry    = errors.New("в токене не указан срок действия")
+	ErrTokenNotYetValid      = errors.New("токен еще не действителен")
+	ErrTokenInvalidIssuer    = errors.New("неверный издатель токена")
+	ErrTokenInvalidAudience  = errors.New("неверная аудитория токена")
+	ErrTokenInvalidClaims    = errors.New("неверные данные пользователя в токене")
+)
+
+// TokenValidator определяет интерфейс для проверки токенов
+type TokenValidator interface {
+	ValidateToken(ctx context.Context, token string) (*Claims, error)
+}
+
+// Audience представляет claim aud, который может быть строкой или массивом строк
+type Audience []string
+
+// UnmarshalJSON разбирает aud из строки или массива строк
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+// Contains проверяет наличие аудитории
+func (a Audience) Contains(audience string) bool {
+	for _, value := range a {
+		if value == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// Claims представляет данные JWT токена
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  Audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	IssuedAt  int64    `json:"iat"`
+	UserID    uint     `json:"user_id"`
+	Username  string   `json:"username"`
+	FullName  string   `json:"full_name"`
+	Role      string   `json:"role"`
+	TenantID  uint     `json:"tenant_id"`
+}
+
+// User создает пользователя на основе данных токена.
+// ID берется из user_id, а если он не задан - из sub.
+func (c *Claims) User() (*User, error) {
+	userID := c.UserID
+	if userID == 0 {
+		parsed, err := strconv.ParseUint(c.Subject, 10, 32)
+		if err != nil || parsed == 0 {
+			return nil, fmt.Errorf("%w: отсутствует ID пользователя", ErrTokenInvalidClaims)
+		}
+		userID = uint(parsed)
+	}
+
+	role, err := ParseUserRole(c.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &User{
+		ID:       userID,
+		Username: c.Username,
+		FullName: c.FullName,
+		IsActive: true,
+		Role:     role,
+		TenantID: c.TenantID,
+	}, nil
+}
+
+// ParseUserRole преобразует строку в роль пользователя. Пустая строка соответствует обычному пользователю.
+func ParseUserRole(role string) (UserRole, error) {
+	switch UserRole(role) {
+	case "":
+		return UserRole_User, nil
+	case UserRole_User, UserRole_ServiceOwner, UserRole_ServiceEmployer,
+		UserRole_Admin, UserRole_SuperAdmin, UserRole_Microservice:
+		return UserRole(role), nil
+	default:
+		return "", fmt.Errorf("%w: неизвестная роль %q", ErrTokenInvalidClaims, role)
+	}
+}
+
+// ValidatorOptions содержит опции проверки claims токена
+type ValidatorOptions struct {
+	// Ожидаемый издатель (пустая строка - не проверять)
+	Issuer string
+	// Ожидаемая аудитория (пустая строка - не проверять)
+	Audience string
+	// Допустимое расхождение часов
+	Leeway time.Duration
+	// Принимать токены без claim exp (бессрочные). По умолчанию такие токены отклоняются.
+	AllowMissingExpiry bool
+}
+
+// DefaultValidatorOptions возвращает опции по умолчанию
+func DefaultValidatorOptions() *ValidatorOptions {
+	return &ValidatorOptions{
+		Leeway: 30 * time.Second,
+	}
+}
+
+// keyFunc возвращает ключ проверки подписи для алгоритма и идентификатора ключа
+type keyFunc func(ctx context.Context, alg, kid string) (interface{}, error)
+
+// JWTValidator проверяет JWT токены, подписанные HMAC (HS*) или RSA (RS*)
+type JWTValidator struct {
+	keyFunc keyFunc
+	options *ValidatorOptions
+}
+
+// NewJWTValidator создает валидатор токенов с общим секретом HMAC
+func NewJWTValidator(secret string) *JWTValidator {
+	key := []byte(secret)
+	return &JWTValidator{
+		keyFunc: func(ctx context.Context, alg, kid string) (interface{}, error) {
+			if !strings.HasPrefix(alg, "HS") {
+				return nil, ErrTokenUnsupportedAlg
+			}
+			return key, nil
+		},
+		options: DefaultValidatorOptions(),
+	}
+}
+
+// NewRSAValidator создает валидатор токенов с открытым ключом RSA
+func NewRSAValidator(publicKey *rsa.PublicKey) *JWTValidator {
+	return &JWTValidator{
+		keyFunc: func(ctx context.Context, alg, kid string) (interface{}, error) {
+			if !strings.HasPrefix(alg, "RS") {
+				return nil, ErrTokenUnsupportedAlg
+			}
+			return publicKey, nil
+		},
+		options: DefaultValidatorOptions(),
+	}
+}
+
+// WithOptions устанавливает опции проверки claims
+func (v *JWTValidator) WithOptions(options *ValidatorOptions) *JWTValidator {
+	if options != nil {
+		v.options = options
+	}
+	return v
+}
+
+// jwtHeader представляет заголовок JWT
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// ValidateToken проверяет подпись токена и claims exp/nbf/iss/aud
+func (v *JWTValidator) ValidateToken(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	key, err := v.keyFunc(ctx, header.Alg, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	if err := v.validateClaims(&claims); err != nil {
+		return nil, err
+	}
+
+	return &claims, nil
+}
+
+// validateClaims проверяет временные ограничения, издателя и аудиторию.
+// Токен без exp отклоняется, если не задан AllowMissingExpiry.
+func (v *JWTValidator) validateClaims(claims *Claims) error {
+	now := time.Now()
+
+	if claims.ExpiresAt == 0 {
+		if !v.options.AllowMissingExpiry {
+			return ErrTokenMissingExpiry
+		}
+	} else if now.After(time.Unix(claims.ExpiresAt, 0).Add(v.options.Leeway)) {
+		return ErrTokenExpired
+	}
+
+	if claims.NotBefore != 0 && now.Add(v.options.Leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return ErrTokenNotYetValid
+	}
+
+	if v.options.Issuer != "" && claims.Issuer != v.options.Issuer {
+		return ErrTokenInvalidIssuer
+	}
+
+	if v.options.Audience != "" && !claims.Audience.Contains(v.options.Audience) {
+		return ErrTokenInvalidAudience
+	}
+
+	return nil
+}
+
+// decodeSegment декодирует base64url сегмент JWT в структуру
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// verifySignature проверяет подпись токена для указанного алгоритма
+func verifySignature(alg string, key interface{}, signingInput string, signature []byte) error {
+	var (
+		newHash    func() hash.Hash
+		cryptoHash crypto.Hash
+	)
+
+	switch alg {
+	case "HS256", "RS256":
+		newHash, cryptoHash = sha256.New, crypto.SHA256
+	case "HS384", "RS384":
+		newHash, cryptoHash = sha512.New384, crypto.SHA384
+	case "HS512", "RS512":
+		newHash, cryptoHash = sha512.New, crypto.SHA512
+	default:
+		return ErrTokenUnsupportedAlg
+	}
+
+	switch k := key.(type) {
+	case []byte:
+		if !strings.HasPrefix(alg, "HS") {
+			return ErrTokenUnsupportedAlg
+		}
+		mac := hmac.New(newHash, k)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return ErrTokenSignatureInvalid
+		}
+		return nil
+
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return ErrTokenUnsupportedAlg
+		}
+		digest := newHash()
+		digest.Write([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(k, cryptoHash, digest.Sum(nil), signature); err != nil {
+			return ErrTokenSignatureInvalid
+		}
+		return nil
+
+	default:
+		return ErrTokenUnsupportedAlg
+	}
+}
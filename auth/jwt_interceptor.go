@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// JWTAuthInterceptor представляет интерцептор авторизации по JWT.
+// Bearer токен имеет приоритет над метаданными user-id; без токена используется ContextManager.
+type JWTAuthInterceptor struct {
+	validator      TokenValidator
+	contextManager *ContextManager
+	skipMethods    map[string]bool // Методы, которые не требуют авторизации
+}
+
+// NewJWTAuthInterceptor создает новый интерцептор авторизации по JWT
+func NewJWTAuthInterceptor(validator TokenValidator, userProvider UserProvider, skipMethods []string) *JWTAuthInterceptor {
+	skipMap := make(map[string]bool)
+	for _, method := range skipMethods {
+		skipMap[method] = true
+	}
+
+	return &JWTAuthInterceptor{
+		validator:      validator,
+		contextManager: NewContextManager(userProvider),
+		skipMethods:    skipMap,
+	}
+}
+
+// UnaryInterceptor возвращает unary интерцептор для авторизации по JWT
+func (ji *JWTAuthInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		// Проверяем, нужна ли авторизация для этого метода
+		if ji.skipMethods[info.FullMethod] {
+			ctx, err := withTenantFromMetadata(ctx, nil)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}
+
+		user, err := ji.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, err = withTenantFromMetadata(WithUser(ctx, user), user)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor возвращает stream интерцептор для авторизации по JWT
+func (ji *JWTAuthInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		// Проверяем, нужна ли авторизация для этого метода
+		if ji.skipMethods[info.FullMethod] {
+			ctx, err := withTenantFromMetadata(ss.Context(), nil)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &wrappedServerStream{ServerStream: ss, ctx: ctx})
+		}
+
+		user, err := ji.authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+
+		ctx, err := withTenantFromMetadata(WithUser(ss.Context(), user), user)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &wrappedServerStream{
+			ServerStream: ss,
+			ctx:          ctx,
+		})
+	}
+}
+
+// authenticate определяет пользователя по Bearer токену или метаданным user-id
+func (ji *JWTAuthInterceptor) authenticate(ctx context.Context) (*User, error) {
+	if !hasAuthorizationHeader(ctx) {
+		user, err := ji.contextManager.ExtractUserFromMetadata(ctx)
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "Ошибка авторизации: %v", err)
+		}
+		return user, nil
+	}
+
+	token, err := ExtractBearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := ji.validator.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "Ошибка авторизации: %v", err)
+	}
+
+	user, err := claims.User()
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "Ошибка авторизации: %v", err)
+	}
+
+	return user, nil
+}
+
+// hasAuthorizationHeader проверяет наличие заголовка authorization в метаданных
+func hasAuthorizationHeader(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	return ok && len(md.Get("authorization")) > 0
+}
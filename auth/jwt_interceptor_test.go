@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestJWTAuthInterceptor(t *testing.T) {
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	withoutExpiry := validClaims()
+	delete(withoutExpiry, "exp")
+
+	tests := []struct {
+		name    string
+		method  string
+		pairs   []string
+		wantID  uint
+		code    codes.Code
+		message string
+	}{
+		{"bearer token", "/test.Service/Get", []string{"authorization", "Bearer " + signHS256(t, "secret", validClaims())}, 42, codes.OK, ""},
+		{"token over user-id", "/test.Service/Get", []string{"authorization", "Bearer " + signHS256(t, "secret", validClaims()), "user-id", "1"}, 42, codes.OK, ""},
+		{"user-id without token", "/test.Service/Get", []string{"user-id", "1"}, 1, codes.OK, ""},
+		{"expired", "/test.Service/Get", []string{"authorization", "Bearer " + signHS256(t, "secret", expired)}, 0, codes.Unauthenticated, ErrTokenExpired.Error()},
+		{"missing exp", "/test.Service/Get", []string{"authorization", "Bearer " + signHS256(t, "secret", withoutExpiry)}, 0, codes.Unauthenticated, ErrTokenMissingExpiry.Error()},
+		{"invalid signature", "/test.Service/Get", []string{"authorization", "Bearer " + signHS256(t, "other", validClaims())}, 0, codes.Unauthenticated, ErrTokenSignatureInvalid.Error()},
+		{"malformed", "/test.Service/Get", []string{"authorization", "Bearer abc"}, 0, codes.Unauthenticated, ErrTokenMalformed.Error()},
+		{"not bearer", "/test.Service/Get", []string{"authorization", "Basic abc"}, 0, codes.Unauthenticated, "неверный формат заголовка авторизации"},
+		{"anonymous", "/test.Service/Get", nil, 0, codes.Unauthenticated, "отсутствует user-id"},
+		{"skipped method", "/test.Service/Public", nil, 0, codes.OK, ""},
+	}
+
+	interceptor := NewJWTAuthInterceptor(NewJWTValidator("secret"), nil, []string{"/test.Service/Public"}).UnaryInterceptor()
+	for _, tt := range tests {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(tt.pairs...))
+
+		var gotID uint
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			gotID, _ = GetUserIDFromContext(ctx)
+			return nil, nil
+		})
+
+		st := status.Convert(err)
+		if st.Code() != tt.code || gotID != tt.wantID || !strings.Contains(st.Message(), tt.message) {
+			t.Errorf("%s: user = %d, status = %s %q; want %d, %s containing %q", tt.name, gotID, st.Code(), st.Message(), tt.wantID, tt.code, tt.message)
+		}
+	}
+}
+
+// contextStream серверный поток с заданным контекстом
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
+
+func TestJWTAuthStreamInterceptor(t *testing.T) {
+	interceptor := NewJWTAuthInterceptor(NewJWTValidator("secret"), nil, nil).StreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Watch"}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+signHS256(t, "secret", validClaims())))
+	var role UserRole
+	err := interceptor(nil, &contextStream{ctx: ctx}, info, func(srv interface{}, stream grpc.ServerStream) error {
+		role, _ = GetUserRoleFromContext(stream.Context())
+		return nil
+	})
+	if err != nil || role != UserRole_Admin {
+		t.Errorf("role = %q, error = %v; want admin", role, err)
+	}
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+signHS256(t, "other", validClaims())))
+	err = interceptor(nil, &contextStream{ctx: ctx}, info, func(srv interface{}, stream grpc.ServerStream) error {
+		t.Error("handler called with invalid token")
+		return nil
+	})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("error = %v, want Unauthenticated", err)
+	}
+}
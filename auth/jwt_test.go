@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	testRSAKeyOnce sync.Once
+	testRSAKey     *rsa.PrivateKey
+)
+
+// rsaTestKey возвращает общий для тестов ключ RSA
+func rsaTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	testRSAKeyOnce.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			panic(err)
+		}
+		testRSAKey = key
+	})
+	return testRSAKey
+}
+
+// signingInput кодирует заголовок и claims токена
+func signingInput(t *testing.T, header map[string]string, claims map[string]interface{}) string {
+	t.Helper()
+
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	return encode(header) + "." + encode(claims)
+}
+
+// signHS256 подписывает токен общим секретом
+func signHS256(t *testing.T, secret string, claims map[string]interface{}) string {
+	t.Helper()
+
+	input := signingInput(t, map[string]string{"alg": "HS256", "typ": "JWT"}, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signRS256 подписывает токен ключом RSA с идентификатором kid
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+
+	input := signingInput(t, map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid}, claims)
+	digest := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("SignPKCS1v15() error = %v", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// validClaims возвращает claims действующего токена пользователя 42
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":  "42",
+		"role": "admin",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestJWTValidatorSignatures(t *testing.T) {
+	key := rsaTestKey(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	hmacValidator := NewJWTValidator("secret")
+	rsaValidator := NewRSAValidator(&key.PublicKey)
+
+	// Токен HS256, подписанный открытым ключом RSA как секретом (подмена алгоритма)
+	publicKeyAsSecret := signHS256(t, string(key.PublicKey.N.Bytes()), validClaims())
+
+	tests := []struct {
+		name      string
+		validator *JWTValidator
+		token     string
+		wantErr   error
+	}{
+		{"hs256", hmacValidator, signHS256(t, "secret", validClaims()), nil},
+		{"hs256 wrong secret", hmacValidator, signHS256(t, "other", validClaims()), ErrTokenSignatureInvalid},
+		{"rs256", rsaValidator, signRS256(t, key, "", validClaims()), nil},
+		{"rs256 wrong key", rsaValidator, signRS256(t, otherKey, "", validClaims()), ErrTokenSignatureInvalid},
+		{"rs256 token for hmac validator", hmacValidator, signRS256(t, key, "", validClaims()), ErrTokenUnsupportedAlg},
+		{"hs256 token for rsa validator", rsaValidator, publicKeyAsSecret, ErrTokenUnsupportedAlg},
+		{"alg none", hmacValidator, signingInput(t, map[string]string{"alg": "none"}, validClaims()) + ".", ErrTokenUnsupportedAlg},
+		{"two segments", hmacValidator, "a.b", ErrTokenMalformed},
+		{"invalid header", hmacValidator, "!!!.e30.sig", ErrTokenMalformed},
+	}
+
+	for _, tt := range tests {
+		claims, err := tt.validator.ValidateToken(context.Background(), tt.token)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: ValidateToken() error = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr == nil && claims.Subject != "42" {
+			t.Errorf("%s: subject = %q, want 42", tt.name, claims.Subject)
+		}
+	}
+}
+
+func TestJWTValidatorClaims(t *testing.T) {
+	now := time.Now()
+	options := &ValidatorOptions{Issuer: "auth-service", Audience: "orders", Leeway: 30 * time.Second}
+
+	tests := []struct {
+		name    string
+		claims  map[string]interface{}
+		options *ValidatorOptions
+		wantErr error
+	}{
+		{"valid", map[string]interface{}{"exp": now.Add(time.Minute).Unix()}, nil, nil},
+		{"expired", map[string]interface{}{"exp": now.Add(-time.Minute).Unix()}, nil, ErrTokenExpired},
+		{"expired within leeway", map[string]interface{}{"exp": now.Add(-10 * time.Second).Unix()}, nil, nil},
+		{"missing exp", map[string]interface{}{}, nil, ErrTokenMissingExpiry},
+		{"missing exp allowed", map[string]interface{}{}, &ValidatorOptions{AllowMissingExpiry: true}, nil},
+		{"not yet valid", map[string]interface{}{"exp": now.Add(time.Hour).Unix(), "nbf": now.Add(time.Minute).Unix()}, nil, ErrTokenNotYetValid},
+		{"nbf within leeway", map[string]interface{}{"exp": now.Add(time.Hour).Unix(), "nbf": now.Add(10 * time.Second).Unix()}, nil, nil},
+		{"issuer and audience string", map[string]interface{}{"exp": now.Add(time.Hour).Unix(), "iss": "auth-service", "aud": "orders"}, options, nil},
+		{"audience array", map[string]interface{}{"exp": now.Add(time.Hour).Unix(), "iss": "auth-service", "aud": []string{"billing", "orders"}}, options, nil},
+		{"wrong issuer", map[string]interface{}{"exp": now.Add(time.Hour).Unix(), "iss": "other", "aud": "orders"}, options, ErrTokenInvalidIssuer},
+		{"wrong audience", map[string]interface{}{"exp": now.Add(time.Hour).Unix(), "iss": "auth-service", "aud": []string{"billing"}}, options, ErrTokenInvalidAudience},
+	}
+
+	for _, tt := range tests {
+		validator := NewJWTValidator("secret").WithOptions(tt.options)
+		_, err := validator.ValidateToken(context.Background(), signHS256(t, "secret", tt.claims))
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: ValidateToken() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestClaimsUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		claims  Claims
+		want    User
+		wantErr bool
+	}{
+		{"user_id", Claims{UserID: 7, Role: "service_owner", TenantID: 3}, User{ID: 7, Role: UserRole_ServiceOwner, TenantID: 3, IsActive: true}, false},
+		{"subject", Claims{Subject: "8"}, User{ID: 8, Role: UserRole_User, IsActive: true}, false},
+		{"no id", Claims{Subject: "user"}, User{}, true},
+		{"unknown role", Claims{UserID: 7, Role: "root"}, User{}, true},
+	}
+
+	for _, tt := range tests {
+		user, err := tt.claims.User()
+		if tt.wantErr {
+			if !errors.Is(err, ErrTokenInvalidClaims) {
+				t.Errorf("%s: User() error = %v, want ErrTokenInvalidClaims", tt.name, err)
+			}
+			continue
+		}
+		if err != nil || *user != tt.want {
+			t.Errorf("%s: User() = %+v, %v; want %+v", tt.name, user, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PermissionAny в MapPolicy разрешает любую операцию над ресурсом
+const PermissionAny Permission = "*"
+
+// PermissionPolicy определяет, какие операции над ресурсами доступны пользователю.
+// Используется User.CanAccess, AuthContext.CanPerform и RequirePermission.
+type PermissionPolicy interface {
+	// Allows возвращает true, если пользователю разрешена операция permission над ресурсом resource
+	Allows(user *User, resource ResourceType, permission Permission) bool
+}
+
+// policyHolder оборачивает политику для хранения в atomic.Pointer
+type policyHolder struct {
+	policy PermissionPolicy
+}
+
+// currentPolicy текущая политика разрешений; заменяется через SetPolicy без перезапуска сервиса
+var currentPolicy atomic.Pointer[policyHolder]
+
+// SetPolicy заменяет политику разрешений. Безопасна для вызова во время обработки запросов;
+// nil восстанавливает DefaultPolicy.
+func SetPolicy(p PermissionPolicy) {
+	if p == nil {
+		currentPolicy.Store(nil)
+		return
+	}
+	currentPolicy.Store(&policyHolder{policy: p})
+}
+
+// GetPolicy возвращает текущую политику разрешений
+func GetPolicy() PermissionPolicy {
+	if holder := currentPolicy.Load(); holder != nil {
+		return holder.policy
+	}
+	return DefaultPolicy{}
+}
+
+// DefaultPolicy встроенная политика разрешений, используемая, пока не задана другая (см. SetPolicy)
+type DefaultPolicy struct{}
+
+// Allows проверяет доступ по встроенной матрице ролей и ресурсов
+func (DefaultPolicy) Allows(u *User, resource ResourceType, permission Permission) bool {
+	// Супер-админ имеет доступ ко всему
+	if u.Role == UserRole_SuperAdmin {
+		return true
+	}
+
+	// Администраторы имеют полный доступ, кроме некоторых супер-админских операций
+	if u.Role == UserRole_Admin {
+		return permission != Permission("super_admin")
+	}
+
+	// Проверяем доступ по ролям и ресурсам
+	switch resource {
+	case ResourceTypeUser:
+		// Только админы могут управлять пользователями
+		return u.IsAdmin()
+
+	case ResourceTypeServiceCenter:
+		// Владельцы сервисных центров и админы
+		return u.IsServiceOwner() && (permission == PermissionRead || permission == PermissionWrite)
+
+	case ResourceTypeOrder:
+		// Все авторизованные пользователи могут читать заказы
+		// Запись/удаление зависит от владения заказом
+		return permission == PermissionRead || permission == PermissionOwn
+
+	case ResourceTypeDevice, ResourceTypeReview:
+		// Чтение доступно всем, запись - владельцам и админам
+		return permission == PermissionRead || u.IsServiceEmployee()
+
+	default:
+		// По умолчанию только чтение для обычных пользователей
+		return permission == PermissionRead
+	}
+}
+
+// MapPolicy политика разрешений в виде матрицы роль → ресурс → разрешения.
+// Ресурс ResourceTypeAny ("*") задает разрешения для ресурсов, не описанных явно,
+// разрешение PermissionAny ("*") разрешает любую операцию. Роли без записи не имеют доступа.
+//
+//	admin:
+//	  "*": ["*"]
+//	user:
+//	  order: [read, own]
+//	  "*": [read]
+type MapPolicy map[UserRole]map[ResourceType][]Permission
+
+// Allows проверяет доступ по матрице
+func (p MapPolicy) Allows(u *User, resource ResourceType, permission Permission) bool {
+	resources, ok := p[u.Role]
+	if !ok {
+		return false
+	}
+
+	permissions, ok := resources[resource]
+	if !ok {
+		permissions = resources[ResourceTypeAny]
+	}
+
+	for _, allowed := range permissions {
+		if allowed == permission || allowed == PermissionAny {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseMapPolicy разбирает матрицу разрешений из YAML или JSON, отклоняя неизвестные роли
+func ParseMapPolicy(data []byte) (MapPolicy, error) {
+	var policy MapPolicy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("invalid permission policy: %v", err)
+	}
+
+	for role := range policy {
+		if _, err := ParseUserRole(string(role)); err != nil || role == "" {
+			return nil, fmt.Errorf("invalid permission policy: unknown role %q", role)
+		}
+	}
+	return policy, nil
+}
+
+// LoadMapPolicyFile читает матрицу разрешений из файла YAML или JSON
+func LoadMapPolicyFile(path string) (MapPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read permission policy: %v", err)
+	}
+	return ParseMapPolicy(data)
+}
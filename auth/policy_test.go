@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	allRoles = []UserRole{
+		UserRole_User, UserRole_ServiceOwner, UserRole_ServiceEmployer,
+		UserRole_Admin, UserRole_SuperAdmin, UserRole_Microservice,
+	}
+	allResources = []ResourceType{
+		ResourceTypeUser, ResourceTypeOrder, ResourceTypeServiceCenter,
+		ResourceTypeDevice, ResourceTypeReview, ResourceType("invoice"),
+	}
+	allPermissions = []Permission{
+		PermissionRead, PermissionWrite, PermissionDelete, PermissionAdmin, PermissionOwn,
+	}
+)
+
+// defaultMatrix описывает текущую встроенную матрицу разрешений; отсутствующий ресурс - нет доступа
+var defaultMatrix = map[UserRole]map[ResourceType][]Permission{
+	UserRole_SuperAdmin: everything(),
+	UserRole_Admin:      everything(),
+	UserRole_User: {
+		ResourceTypeOrder:       {PermissionRead, PermissionOwn},
+		ResourceTypeDevice:      {PermissionRead},
+		ResourceTypeReview:      {PermissionRead},
+		ResourceType("invoice"): {PermissionRead},
+	},
+	UserRole_Microservice: {
+		ResourceTypeOrder:       {PermissionRead, PermissionOwn},
+		ResourceTypeDevice:      {PermissionRead},
+		ResourceTypeReview:      {PermissionRead},
+		ResourceType("invoice"): {PermissionRead},
+	},
+	UserRole_ServiceEmployer: {
+		ResourceTypeOrder:       {PermissionRead, PermissionOwn},
+		ResourceTypeDevice:      allPermissions,
+		ResourceTypeReview:      allPermissions,
+		ResourceType("invoice"): {PermissionRead},
+	},
+	UserRole_ServiceOwner: {
+		ResourceTypeServiceCenter: {PermissionRead, PermissionWrite},
+		ResourceTypeOrder:         {PermissionRead, PermissionOwn},
+		ResourceTypeDevice:        allPermissions,
+		ResourceTypeReview:        allPermissions,
+		ResourceType("invoice"):   {PermissionRead},
+	},
+}
+
+// defaultMatrixYAML повторяет встроенную матрицу в формате MapPolicy
+const defaultMatrixYAML = `
+super_admin:
+  "*": ["*"]
+admin:
+  "*": ["*"]
+user:
+  user: []
+  service_center: []
+  order: [read, own]
+  "*": [read]
+microservice:
+  user: []
+  service_center: []
+  order: [read, own]
+  "*": [read]
+service_employer:
+  user: []
+  service_center: []
+  order: [read, own]
+  device: ["*"]
+  review: ["*"]
+  "*": [read]
+service_owner:
+  user: []
+  service_center: [read, write]
+  order: [read, own]
+  device: ["*"]
+  review: ["*"]
+  "*": [read]
+`
+
+func everything() map[ResourceType][]Permission {
+	matrix := make(map[ResourceType][]Permission, len(allResources))
+	for _, resource := range allResources {
+		matrix[resource] = allPermissions
+	}
+	return matrix
+}
+
+func expectedAccess(role UserRole, resource ResourceType, permission Permission) bool {
+	for _, allowed := range defaultMatrix[role][resource] {
+		if allowed == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func assertMatrix(t *testing.T, policy PermissionPolicy) {
+	t.Helper()
+
+	for _, role := range allRoles {
+		for _, resource := range allResources {
+			for _, permission := range allPermissions {
+				user := &User{ID: 1, Role: role}
+				want := expectedAccess(role, resource, permission)
+				if got := policy.Allows(user, resource, permission); got != want {
+					t.Errorf("%s %s %s = %v, want %v", role, resource, permission, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestDefaultPolicyMatrix(t *testing.T) {
+	assertMatrix(t, DefaultPolicy{})
+}
+
+func TestMapPolicyReplicatesDefault(t *testing.T) {
+	policy, err := ParseMapPolicy([]byte(defaultMatrixYAML))
+	if err != nil {
+		t.Fatalf("ParseMapPolicy() error = %v", err)
+	}
+	assertMatrix(t, policy)
+}
+
+func TestParseMapPolicyJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(`{"user": {"review": ["read", "write"]}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := LoadMapPolicyFile(path)
+	if err != nil {
+		t.Fatalf("LoadMapPolicyFile() error = %v", err)
+	}
+
+	user := &User{Role: UserRole_User}
+	if !policy.Allows(user, ResourceTypeReview, PermissionWrite) {
+		t.Error("user must be allowed to write reviews")
+	}
+	if policy.Allows(user, ResourceTypeOrder, PermissionRead) {
+		t.Error("resources without entry and without \"*\" must be denied")
+	}
+	if policy.Allows(&User{Role: UserRole_Admin}, ResourceTypeReview, PermissionRead) {
+		t.Error("roles without entry must be denied")
+	}
+}
+
+func TestParseMapPolicyRejectsUnknownRole(t *testing.T) {
+	if _, err := ParseMapPolicy([]byte("moderator:\n  review: [write]\n")); err == nil {
+		t.Error("ParseMapPolicy() must reject unknown roles")
+	}
+	if _, err := ParseMapPolicy([]byte("user: [read]")); err == nil {
+		t.Error("ParseMapPolicy() must reject malformed policies")
+	}
+}
+
+func TestSetPolicyAppliesToPermissionChecks(t *testing.T) {
+	t.Cleanup(func() { SetPolicy(nil) })
+
+	user := &User{ID: 7, Role: UserRole_User, IsActive: true}
+	authCtx := NewAuthContext(user)
+	check := PermissionCheck{Resource: ResourceTypeReview, Permission: PermissionWrite}
+
+	if authCtx.CanPerform(check) {
+		t.Fatal("default policy must deny review writes for users")
+	}
+
+	SetPolicy(MapPolicy{UserRole_User: {ResourceTypeReview: {PermissionRead, PermissionWrite}}})
+	if !authCtx.CanPerform(check) {
+		t.Error("CanPerform() must use the policy set at runtime")
+	}
+	if _, err := RequirePermission(WithUser(context.Background(), user), check); err != nil {
+		t.Errorf("RequirePermission() error = %v", err)
+	}
+
+	SetPolicy(nil)
+	if authCtx.CanPerform(check) {
+		t.Error("SetPolicy(nil) must restore the default policy")
+	}
+}
@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/vladzorgan/common/logging"
+	events "github.com/vladzorgan/common/messaging/rabbitmq"
+	"github.com/vladzorgan/common/redis"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// События, при которых кеш пользователя сбрасывается
+const (
+	UserUpdatedEvent = "user.updated"
+	UserDeletedEvent = "user.deleted"
+)
+
+// GormDBProvider предоставляет соединение GORM (например, *database.Database)
+type GormDBProvider interface {
+	GetDB() *gorm.DB
+}
+
+// userRow представляет строку таблицы пользователей
+type userRow struct {
+	ID         uint
+	Username   string
+	FullName   string
+	IsActive   bool
+	Role       string
+	TelegramID *int64
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// GormUserProvider получает пользователей из таблицы базы данных.
+// Ожидаются колонки id, username, full_name, is_active, role, telegram_id, created_at, updated_at;
+// отсутствующие колонки остаются нулевыми.
+type GormUserProvider struct {
+	db        GormDBProvider
+	tableName string
+}
+
+// NewGormUserProvider создает провайдер пользователей на основе таблицы tableName
+func NewGormUserProvider(db GormDBProvider, tableName string) *GormUserProvider {
+	if tableName == "" {
+		tableName = "users"
+	}
+
+	return &GormUserProvider{
+		db:        db,
+		tableName: tableName,
+	}
+}
+
+// GetUserByID получает пользователя по ID; возвращает nil, если пользователь не найден
+func (p *GormUserProvider) GetUserByID(ctx context.Context, userID uint) (*User, error) {
+	var row userRow
+	result := p.db.GetDB().WithContext(ctx).
+		Table(p.tableName).
+		Where("id = ?", userID).
+		Limit(1).
+		Find(&row)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get user %d: %v", userID, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	role, err := ParseUserRole(row.Role)
+	if err != nil {
+		return nil, fmt.Errorf("invalid role of user %d: %v", userID, err)
+	}
+
+	return &User{
+		ID:         row.ID,
+		Username:   row.Username,
+		FullName:   row.FullName,
+		IsActive:   row.IsActive,
+		Role:       role,
+		TelegramID: row.TelegramID,
+		CreatedAt:  row.CreatedAt,
+		UpdatedAt:  row.UpdatedAt,
+	}, nil
+}
+
+// UserClient определяет метод клиента auth-service, используемый GRPCUserProvider
+type UserClient interface {
+	GetUser(ctx context.Context, userID uint) (*User, error)
+}
+
+// GRPCUserProvider получает пользователей из auth-service
+type GRPCUserProvider struct {
+	client UserClient
+}
+
+// NewGRPCUserProvider создает провайдер пользователей поверх клиента auth-service
+func NewGRPCUserProvider(authClient UserClient) *GRPCUserProvider {
+	return &GRPCUserProvider{client: authClient}
+}
+
+// GetUserByID получает пользователя по ID; ответ NotFound преобразуется в nil без ошибки
+func (p *GRPCUserProvider) GetUserByID(ctx context.Context, userID uint) (*User, error) {
+	user, err := p.client.GetUser(ctx, userID)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user %d from auth service: %v", userID, err)
+	}
+
+	return user, nil
+}
+
+// CachingUserProvider кеширует пользователей в Redis
+type CachingUserProvider struct {
+	inner     UserProvider
+	client    *redis.Client
+	ttl       time.Duration
+	keyPrefix string
+	logger    logging.Logger
+}
+
+// NewCachingUserProvider создает провайдер, кеширующий результаты inner в Redis на ttl.
+// Ненайденные пользователи не кешируются. При недоступности Redis запрос идет напрямую в inner.
+func NewCachingUserProvider(inner UserProvider, redisClient *redis.Client, ttl time.Duration) *CachingUserProvider {
+	return &CachingUserProvider{
+		inner:     inner,
+		client:    redisClient,
+		ttl:       ttl,
+		keyPrefix: "auth:user",
+		logger:    logging.NewLogger(),
+	}
+}
+
+// WithLogger устанавливает логгер
+func (p *CachingUserProvider) WithLogger(logger logging.Logger) *CachingUserProvider {
+	p.logger = logger
+	return p
+}
+
+// GetUserByID получает пользователя из кеша или из inner
+func (p *CachingUserProvider) GetUserByID(ctx context.Context, userID uint) (*User, error) {
+	key := p.key(userID)
+
+	var cached User
+	if err := p.client.GetJSON(ctx, key, &cached); err != nil {
+		p.logger.WithContext(ctx).Warn("Failed to read cached user %d: %v", userID, err)
+	} else if cached.ID != 0 {
+		return &cached, nil
+	}
+
+	user, err := p.inner.GetUserByID(ctx, userID)
+	if err != nil || user == nil {
+		return user, err
+	}
+
+	if err := p.client.SetJSON(ctx, key, user, p.ttl); err != nil {
+		p.logger.WithContext(ctx).Warn("Failed to cache user %d: %v", userID, err)
+	}
+
+	return user, nil
+}
+
+// Invalidate удаляет пользователя из кеша
+func (p *CachingUserProvider) Invalidate(ctx context.Context, userID uint) error {
+	return p.client.Del(ctx, p.key(userID))
+}
+
+// InvalidationHandler возвращает обработчик событий user.updated/user.deleted,
+// сбрасывающий кеш пользователя. Payload должен содержать поле id или user_id.
+func (p *CachingUserProvider) InvalidationHandler() events.HandlerFunc {
+	return func(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+		var payload struct {
+			ID     json.Number `json:"id"`
+			UserID json.Number `json:"user_id"`
+		}
+		if err := json.Unmarshal(message, &payload); err != nil {
+			p.logger.WithContext(ctx).Warn("Invalid %s payload: %v", delivery.RoutingKey, err)
+			return nil // Повторная доставка не поможет
+		}
+
+		raw := payload.UserID
+		if raw == "" {
+			raw = payload.ID
+		}
+
+		userID, err := strconv.ParseUint(raw.String(), 10, 32)
+		if err != nil {
+			p.logger.WithContext(ctx).Warn("Invalid user id %q in %s event", raw, delivery.RoutingKey)
+			return nil
+		}
+
+		return p.Invalidate(ctx, uint(userID))
+	}
+}
+
+// SubscribeInvalidation подписывает обработчик сброса кеша на события пользователя.
+// Чтобы кеш сбрасывался на всех экземплярах сервиса, у каждого экземпляра должна быть своя очередь.
+func (p *CachingUserProvider) SubscribeInvalidation(consumer *events.Consumer) error {
+	for _, routingKey := range []string{UserUpdatedEvent, UserDeletedEvent} {
+		if err := consumer.Subscribe(routingKey, p.InvalidationHandler()); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %v", routingKey, err)
+		}
+	}
+	return nil
+}
+
+// key формирует ключ кеша пользователя
+func (p *CachingUserProvider) key(userID uint) string {
+	return fmt.Sprintf("%s:%d", p.keyPrefix, userID)
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// TenantContextKey ключ ID арендатора в контексте
+	TenantContextKey contextKey = "tenant_id"
+	// TenantMetadataKey ключ gRPC метаданных с ID арендатора
+	TenantMetadataKey = "x-tenant-id"
+	// TenantHeader HTTP заголовок с ID арендатора
+	TenantHeader = "X-Tenant-ID"
+	// UserTenantMetadataKey ключ gRPC метаданных с арендатором пользователя. В отличие от x-tenant-id
+	// (запрошенный арендатор), значение выставляет шлюз или вызывающий сервис по данным пользователя.
+	UserTenantMetadataKey = "user-tenant-id"
+	// UserTenantHeader HTTP заголовок с арендатором пользователя, выставляемый шлюзом
+	UserTenantHeader = "X-User-Tenant-ID"
+)
+
+// Ошибки определения арендатора
+var (
+	errInvalidTenantID = errors.New("неверный формат ID арендатора")
+)
+
+// WithTenant добавляет ID арендатора в контекст
+func WithTenant(ctx context.Context, tenantID uint) context.Context {
+	return context.WithValue(ctx, TenantContextKey, tenantID)
+}
+
+// GetTenantFromContext получает ID арендатора из контекста
+func GetTenantFromContext(ctx context.Context) (uint, error) {
+	tenantID, ok := ctx.Value(TenantContextKey).(uint)
+	if !ok || tenantID == 0 {
+		return 0, errors.New("арендатор не найден в контексте")
+	}
+	return tenantID, nil
+}
+
+// RequireTenant проверяет, что в контексте задан арендатор
+func RequireTenant(ctx context.Context) (uint, error) {
+	tenantID, err := GetTenantFromContext(ctx)
+	if err != nil {
+		return 0, status.Errorf(codes.PermissionDenied, "Требуется арендатор: %v", err)
+	}
+	return tenantID, nil
+}
+
+// resolveTenant определяет арендатора запроса по значению заголовка x-tenant-id и арендатору пользователя.
+// Арендатор пользователя берется из доверенного источника (провайдер, JWT, user-tenant-id), а не из
+// запрошенного значения. Обычный пользователь работает только со своим арендатором; выбрать другого
+// может только администратор.
+// Анонимный запрос и пользователь без арендатора не могут выбрать арендатора (PermissionDenied).
+// Возвращает 0, если арендатор не задан.
+func resolveTenant(requested string, user *User) (uint, error) {
+	if requested == "" {
+		if user == nil {
+			return 0, nil
+		}
+		return user.TenantID, nil
+	}
+
+	parsed, err := strconv.ParseUint(requested, 10, 32)
+	if err != nil || parsed == 0 {
+		return 0, status.Error(codes.InvalidArgument, errInvalidTenantID.Error())
+	}
+	tenantID := uint(parsed)
+
+	if user == nil || (tenantID != user.TenantID && !user.IsAdmin()) {
+		return 0, status.Errorf(codes.PermissionDenied, "Доступ к арендатору %d запрещен", tenantID)
+	}
+
+	return tenantID, nil
+}
+
+// parseUserTenant разбирает ID арендатора пользователя; неверное значение считается отсутствующим
+func parseUserTenant(value string) uint {
+	tenantID, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint(tenantID)
+}
+
+// withTenantFromMetadata добавляет в контекст арендатора из метаданных x-tenant-id и данных пользователя
+func withTenantFromMetadata(ctx context.Context, user *User) (context.Context, error) {
+	var requested string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(TenantMetadataKey); len(values) > 0 {
+			requested = values[0]
+		}
+	}
+
+	tenantID, err := resolveTenant(requested, user)
+	if err != nil || tenantID == 0 {
+		return ctx, err
+	}
+
+	return WithTenant(ctx, tenantID), nil
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestResolveTenant(t *testing.T) {
+	member := &User{ID: 1, Role: UserRole_User, TenantID: 7}
+	withoutTenant := &User{ID: 2, Role: UserRole_User}
+	admin := &User{ID: 3, Role: UserRole_Admin}
+
+	tests := []struct {
+		name      string
+		requested string
+		user      *User
+		want      uint
+		code      codes.Code
+	}{
+		{"anonymous without header", "", nil, 0, codes.OK},
+		{"anonymous with header", "7", nil, 0, codes.PermissionDenied},
+		{"user tenant by default", "", member, 7, codes.OK},
+		{"own tenant", "7", member, 7, codes.OK},
+		{"foreign tenant", "8", member, 0, codes.PermissionDenied},
+		{"user without tenant", "7", withoutTenant, 0, codes.PermissionDenied},
+		{"admin selects tenant", "8", admin, 8, codes.OK},
+		{"invalid header", "abc", member, 0, codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveTenant(tt.requested, tt.user)
+		if got != tt.want || status.Code(err) != tt.code {
+			t.Errorf("%s: resolveTenant() = %d, %v; want %d, %s", tt.name, got, err, tt.want, tt.code)
+		}
+	}
+}
+
+func TestAuthInterceptorTenantFromMetadata(t *testing.T) {
+	tests := []struct {
+		name  string
+		pairs []string
+		want  uint
+		code  codes.Code
+	}{
+		{"own tenant", []string{"user-id", "1", "user-role", "user", UserTenantMetadataKey, "7", TenantMetadataKey, "7"}, 7, codes.OK},
+		{"user tenant by default", []string{"user-id", "1", "user-role", "user", UserTenantMetadataKey, "7"}, 7, codes.OK},
+		{"foreign tenant", []string{"user-id", "1", "user-role", "user", UserTenantMetadataKey, "7", TenantMetadataKey, "8"}, 0, codes.PermissionDenied},
+		// Запрошенный арендатор не считается арендатором пользователя
+		{"requested tenant only", []string{"user-id", "1", "user-role", "user", TenantMetadataKey, "8"}, 0, codes.PermissionDenied},
+		{"admin selects tenant", []string{"user-id", "1", "user-role", "admin", TenantMetadataKey, "8"}, 8, codes.OK},
+	}
+
+	interceptor := NewAuthInterceptor(nil, nil).UnaryInterceptor()
+	for _, tt := range tests {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(tt.pairs...))
+
+		var got uint
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			got, _ = GetTenantFromContext(ctx)
+			return nil, nil
+		})
+		if got != tt.want || status.Code(err) != tt.code {
+			t.Errorf("%s: tenant = %d, %v; want %d, %s", tt.name, got, err, tt.want, tt.code)
+		}
+	}
+}
+
+func TestGinMiddlewareTenantFromHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		status  int
+		want    uint
+	}{
+		{"own tenant", map[string]string{"X-User-ID": "1", UserTenantHeader: "7", TenantHeader: "7"}, http.StatusOK, 7},
+		{"user tenant by default", map[string]string{"X-User-ID": "1", UserTenantHeader: "7"}, http.StatusOK, 7},
+		{"foreign tenant", map[string]string{"X-User-ID": "1", UserTenantHeader: "7", TenantHeader: "8"}, http.StatusForbidden, 0},
+		{"requested tenant only", map[string]string{"X-User-ID": "1", TenantHeader: "8"}, http.StatusForbidden, 0},
+		{"admin selects tenant", map[string]string{"X-User-ID": "1", "X-User-Role": "admin", TenantHeader: "8"}, http.StatusOK, 8},
+	}
+
+	for _, tt := range tests {
+		var got uint
+		router := gin.New()
+		router.Use(GinMiddleware(nil, nil))
+		router.GET("/", func(c *gin.Context) {
+			got, _ = GetTenantFromContext(c.Request.Context())
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for key, value := range tt.headers {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status || got != tt.want {
+			t.Errorf("%s: status = %d, tenant = %d; want %d, %d", tt.name, rec.Code, got, tt.status, tt.want)
+		}
+	}
+}
@@ -24,6 +24,8 @@ type User struct {
 	IsActive   bool     `json:"is_active"`
 	Role       UserRole `json:"role"`
 	TelegramID *int64   `json:"telegram_id,omitempty"`
+	// TenantID арендатор пользователя (0 - не привязан к арендатору)
+	TenantID  uint      `json:"tenant_id,omitempty"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
@@ -85,41 +87,9 @@ func (u *User) IsServiceEmployee() bool {
 	return u.Role == UserRole_ServiceEmployer || u.IsServiceOwner()
 }
 
-// CanAccess проверяет, может ли пользователь получить доступ к ресурсу
+// CanAccess проверяет, может ли пользователь получить доступ к ресурсу по текущей политике разрешений (см. SetPolicy)
 func (u *User) CanAccess(resource ResourceType, permission Permission) bool {
-	// Супер-админ имеет доступ ко всему
-	if u.Role == UserRole_SuperAdmin {
-		return true
-	}
-	
-	// Администраторы имеют полный доступ, кроме некоторых супер-админских операций
-	if u.Role == UserRole_Admin {
-		return permission != Permission("super_admin")
-	}
-	
-	// Проверяем доступ по ролям и ресурсам
-	switch resource {
-	case ResourceTypeUser:
-		// Только админы могут управлять пользователями
-		return u.IsAdmin()
-		
-	case ResourceTypeServiceCenter:
-		// Владельцы сервисных центров и админы
-		return u.IsServiceOwner() && (permission == PermissionRead || permission == PermissionWrite)
-		
-	case ResourceTypeOrder:
-		// Все авторизованные пользователи могут читать заказы
-		// Запись/удаление зависит от владения заказом
-		return permission == PermissionRead || permission == PermissionOwn
-		
-	case ResourceTypeDevice, ResourceTypeReview:
-		// Чтение доступно всем, запись - владельцам и админам
-		return permission == PermissionRead || u.IsServiceEmployee()
-		
-	default:
-		// По умолчанию только чтение для обычных пользователей
-		return permission == PermissionRead
-	}
+	return GetPolicy().Allows(u, resource, permission)
 }
 
 // NewAuthContext создает новый контекст авторизации
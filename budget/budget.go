@@ -0,0 +1,156 @@
+// Package budget предоставляет бюджет исходящих запросов, общий для всего дерева вызовов.
+// Бюджет ограничивает число подзапросов и повторных попыток, предотвращая умножение
+// повторов между слоями сервисов во время аварий.
+package budget
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vladzorgan/common/killswitch"
+	"github.com/vladzorgan/common/logging"
+)
+
+// Имена заголовка и метаданных для передачи оставшегося бюджета
+const (
+	Header      = "X-Request-Budget"
+	MetadataKey = "x-request-budget"
+)
+
+// Ключ для хранения бюджета в контексте
+type contextKey string
+
+const budgetContextKey contextKey = "request_budget"
+
+// exhaustedTotal считает операции, пропущенные из-за исчерпания бюджета
+var exhaustedTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "request_budget_exhausted_total",
+		Help: "Количество подзапросов и повторов, пропущенных из-за исчерпания бюджета запроса",
+	},
+	[]string{"kind"},
+)
+
+// Budget представляет бюджет исходящих запросов
+type Budget struct {
+	requests atomic.Int64
+	retries  atomic.Int64
+}
+
+// New создает бюджет с указанным количеством подзапросов и повторных попыток
+func New(maxRequests, maxRetries int) *Budget {
+	b := &Budget{}
+	b.requests.Store(int64(maxRequests))
+	b.retries.Store(int64(maxRetries))
+	return b
+}
+
+// Parse разбирает бюджет из значения заголовка вида "requests=10;retries=3"
+func Parse(value string) (*Budget, error) {
+	var requests, retries int64
+	var hasRequests, hasRetries bool
+
+	for _, part := range strings.Split(value, ";") {
+		key, raw, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid request budget %q", value)
+		}
+
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid request budget %q", value)
+		}
+
+		switch key {
+		case "requests":
+			requests, hasRequests = n, true
+		case "retries":
+			retries, hasRetries = n, true
+		}
+	}
+
+	if !hasRequests || !hasRetries {
+		return nil, fmt.Errorf("invalid request budget %q", value)
+	}
+
+	b := &Budget{}
+	b.requests.Store(requests)
+	b.retries.Store(retries)
+	return b, nil
+}
+
+// String возвращает оставшийся бюджет в формате заголовка
+func (b *Budget) String() string {
+	return fmt.Sprintf("requests=%d;retries=%d", max(b.requests.Load(), 0), max(b.retries.Load(), 0))
+}
+
+// RemainingRequests возвращает оставшееся количество подзапросов
+func (b *Budget) RemainingRequests() int64 {
+	return max(b.requests.Load(), 0)
+}
+
+// RemainingRetries возвращает оставшееся количество повторных попыток
+func (b *Budget) RemainingRetries() int64 {
+	return max(b.retries.Load(), 0)
+}
+
+// WithBudget добавляет бюджет в контекст
+func WithBudget(ctx context.Context, b *Budget) context.Context {
+	return context.WithValue(ctx, budgetContextKey, b)
+}
+
+// FromContext возвращает бюджет из контекста или nil
+func FromContext(ctx context.Context) *Budget {
+	b, _ := ctx.Value(budgetContextKey).(*Budget)
+	return b
+}
+
+// ConsumeRequest учитывает исходящий подзапрос. Первые попытки разрешены всегда,
+// поэтому исчерпание бюджета подзапросов только фиксируется в метрике и логе.
+// Отключается выключателем killswitch.FeatureRequestBudget.
+func ConsumeRequest(ctx context.Context, logger logging.Logger, target string) {
+	b := FromContext(ctx)
+	if b == nil || killswitch.IsDisabled(killswitch.FeatureRequestBudget) {
+		return
+	}
+
+	if b.requests.Add(-1) < 0 {
+		exhaustedTotal.WithLabelValues("request").Inc()
+		if logger != nil {
+			logger.WithContext(ctx).Warn("Request budget exhausted, sub-request to %s exceeds budget", target)
+		}
+	}
+}
+
+// AllowRetry проверяет и учитывает повторную попытку. Если бюджет исчерпан, возвращает false.
+// Без бюджета в контексте повторы не ограничиваются.
+// Отключается выключателем killswitch.FeatureRequestBudget.
+func AllowRetry(ctx context.Context, logger logging.Logger, target string) bool {
+	b := FromContext(ctx)
+	if b == nil || killswitch.IsDisabled(killswitch.FeatureRequestBudget) {
+		return true
+	}
+
+	if b.retries.Add(-1) < 0 {
+		exhaustedTotal.WithLabelValues("retry").Inc()
+		if logger != nil {
+			logger.WithContext(ctx).Warn("Request budget exhausted, skipping retry of %s", target)
+		}
+		return false
+	}
+
+	return true
+}
+
+// InjectHeader добавляет оставшийся бюджет в заголовки исходящего HTTP запроса
+func InjectHeader(ctx context.Context, req *http.Request) {
+	if b := FromContext(ctx); b != nil {
+		req.Header.Set(Header, b.String())
+	}
+}
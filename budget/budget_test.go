@@ -0,0 +1,123 @@
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/vladzorgan/common/killswitch"
+	"github.com/vladzorgan/common/redis"
+)
+
+// disableFeature отключает функцию через множество выключателей в Redis до конца теста
+func disableFeature(t *testing.T, feature string) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client, err := redis.NewClient(server.Addr(), "", 0, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	server.SAdd("killswitch", feature)
+	ctx, cancel := context.WithCancel(context.Background())
+	killswitch.WatchRedis(ctx, client, "killswitch", time.Hour, nil)
+	cancel()
+
+	t.Cleanup(func() {
+		server.Del("killswitch")
+		ctx, cancel := context.WithCancel(context.Background())
+		killswitch.WatchRedis(ctx, client, "killswitch", time.Hour, nil)
+		cancel()
+		client.Close()
+	})
+	if !killswitch.IsDisabled(feature) {
+		t.Fatalf("feature %s is not disabled", feature)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value    string
+		requests int64
+		retries  int64
+		wantErr  bool
+	}{
+		{value: "requests=10;retries=3", requests: 10, retries: 3},
+		{value: " retries=0 ; requests=5 ", requests: 5, retries: 0},
+		{value: "requests=10;retries=3;extra=1", requests: 10, retries: 3},
+		{value: "", wantErr: true},
+		{value: "requests=10", wantErr: true},
+		{value: "retries=3", wantErr: true},
+		{value: "requests=-1;retries=3", wantErr: true},
+		{value: "requests=ten;retries=3", wantErr: true},
+		{value: "requests;retries=3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		b, err := Parse(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) = %v, want error", tt.value, b)
+			}
+			continue
+		}
+		if err != nil || b.RemainingRequests() != tt.requests || b.RemainingRetries() != tt.retries {
+			t.Errorf("Parse(%q) = %v, %v; want requests=%d;retries=%d", tt.value, b, err, tt.requests, tt.retries)
+		}
+	}
+
+	if b, _ := Parse("requests=10;retries=3"); b.String() != "requests=10;retries=3" {
+		t.Errorf("String() = %q", b.String())
+	}
+}
+
+func TestBudgetCountdown(t *testing.T) {
+	b := New(2, 1)
+	ctx := WithBudget(context.Background(), b)
+	retriesBefore := testutil.ToFloat64(exhaustedTotal.WithLabelValues("retry"))
+	requestsBefore := testutil.ToFloat64(exhaustedTotal.WithLabelValues("request"))
+
+	if !AllowRetry(ctx, nil, "users") {
+		t.Error("first retry must be allowed")
+	}
+	if AllowRetry(ctx, nil, "users") {
+		t.Error("retry beyond the budget must be rejected")
+	}
+
+	// Подзапросы сверх бюджета не запрещаются, а только учитываются
+	for i := 0; i < 3; i++ {
+		ConsumeRequest(ctx, nil, "users")
+	}
+	if b.RemainingRequests() != 0 || b.RemainingRetries() != 0 || b.String() != "requests=0;retries=0" {
+		t.Errorf("budget = %s, want requests=0;retries=0", b)
+	}
+
+	if got := testutil.ToFloat64(exhaustedTotal.WithLabelValues("retry")) - retriesBefore; got != 1 {
+		t.Errorf("exhausted retries = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(exhaustedTotal.WithLabelValues("request")) - requestsBefore; got != 1 {
+		t.Errorf("exhausted requests = %v, want 1", got)
+	}
+
+	// Без бюджета в контексте повторы не ограничиваются
+	if !AllowRetry(context.Background(), nil, "users") {
+		t.Error("retry without budget must be allowed")
+	}
+}
+
+func TestBudgetKillswitch(t *testing.T) {
+	disableFeature(t, killswitch.FeatureRequestBudget)
+
+	b := New(0, 0)
+	ctx := WithBudget(context.Background(), b)
+	if !AllowRetry(ctx, nil, "users") {
+		t.Error("retry must be allowed when the budget is disabled")
+	}
+	ConsumeRequest(ctx, nil, "users")
+	if b.String() != "requests=0;retries=0" || b.requests.Load() != 0 || b.retries.Load() != 0 {
+		t.Errorf("budget changed while disabled: requests=%d, retries=%d", b.requests.Load(), b.retries.Load())
+	}
+}
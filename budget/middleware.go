@@ -0,0 +1,75 @@
+package budget
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/killswitch"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Config содержит лимиты бюджета для входящих запросов без бюджета от вызывающей стороны
+type Config struct {
+	// Максимальное количество подзапросов
+	MaxRequests int
+	// Максимальное количество повторных попыток
+	MaxRetries int
+}
+
+// newBudget создает бюджет из значения вызывающей стороны или из конфигурации
+func (c *Config) newBudget(value string) *Budget {
+	if value != "" {
+		if b, err := Parse(value); err == nil {
+			return b
+		}
+	}
+	return New(c.MaxRequests, c.MaxRetries)
+}
+
+// GinMiddleware возвращает middleware, инициализирующее бюджет запроса.
+// Бюджет из заголовка X-Request-Budget имеет приоритет над конфигурацией.
+func GinMiddleware(cfg *Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if killswitch.IsDisabled(killswitch.FeatureRequestBudget) {
+			c.Next()
+			return
+		}
+
+		b := cfg.newBudget(c.GetHeader(Header))
+		c.Request = c.Request.WithContext(WithBudget(c.Request.Context(), b))
+
+		c.Next()
+	}
+}
+
+// UnaryServerInterceptor возвращает интерцептор, инициализирующий бюджет запроса.
+// Бюджет из метаданных x-request-budget имеет приоритет над конфигурацией.
+func UnaryServerInterceptor(cfg *Config) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if killswitch.IsDisabled(killswitch.FeatureRequestBudget) {
+			return handler(ctx, req)
+		}
+
+		value := ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(MetadataKey); len(values) > 0 {
+				value = values[0]
+			}
+		}
+
+		return handler(WithBudget(ctx, cfg.newBudget(value)), req)
+	}
+}
+
+// UnaryClientInterceptor возвращает клиентский интерцептор, учитывающий подзапрос
+// и передающий оставшийся бюджет в исходящие метаданные
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if b := FromContext(ctx); b != nil {
+			ConsumeRequest(ctx, nil, method)
+			ctx = metadata.AppendToOutgoingContext(ctx, MetadataKey, b.String())
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,103 @@
+package budget
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/killswitch"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ginBudget выполняет запрос через GinMiddleware и возвращает бюджет обработчика
+func ginBudget(t *testing.T, header string) *Budget {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var got *Budget
+	router := gin.New()
+	router.Use(GinMiddleware(&Config{MaxRequests: 5, MaxRetries: 2}))
+	router.GET("/", func(c *gin.Context) {
+		got = FromContext(c.Request.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		req.Header.Set(Header, header)
+	}
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	return got
+}
+
+func TestGinMiddleware(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "requests=3;retries=1", want: "requests=3;retries=1"},
+		{header: "", want: "requests=5;retries=2"},
+		{header: "requests=-3", want: "requests=5;retries=2"},
+	}
+
+	for _, tt := range tests {
+		if got := ginBudget(t, tt.header); got == nil || got.String() != tt.want {
+			t.Errorf("header %q: budget = %v, want %s", tt.header, got, tt.want)
+		}
+	}
+
+	// Исходящий HTTP запрос получает оставшийся бюджет
+	b := New(4, 1)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	InjectHeader(WithBudget(context.Background(), b), req)
+	if got := req.Header.Get(Header); got != "requests=4;retries=1" {
+		t.Errorf("InjectHeader() = %q", got)
+	}
+}
+
+func TestGinMiddlewareKillswitch(t *testing.T) {
+	disableFeature(t, killswitch.FeatureRequestBudget)
+
+	if got := ginBudget(t, "requests=3;retries=1"); got != nil {
+		t.Errorf("budget = %v, want none while disabled", got)
+	}
+}
+
+func TestUnaryInterceptorsPropagateBudget(t *testing.T) {
+	server := UnaryServerInterceptor(&Config{MaxRequests: 5, MaxRetries: 2})
+	client := UnaryClientInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}
+
+	// Без метаданных используется конфигурация
+	var got *Budget
+	server(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		got = FromContext(ctx)
+		return nil, nil
+	})
+	if got == nil || got.String() != "requests=5;retries=2" {
+		t.Errorf("budget without metadata = %v, want requests=5;retries=2", got)
+	}
+
+	// Бюджет из метаданных уменьшается исходящим вызовом и передается дальше
+	var outgoing []string
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		outgoing = md.Get(MetadataKey)
+		return nil
+	}
+	incoming := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "requests=3;retries=1"))
+	server(incoming, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, client(ctx, "/users.Service/Get", nil, nil, nil, invoker)
+	})
+	if len(outgoing) != 1 || outgoing[0] != "requests=2;retries=1" {
+		t.Errorf("outgoing budget = %v, want [requests=2;retries=1]", outgoing)
+	}
+
+	// Без бюджета в контексте метаданные не добавляются
+	outgoing = nil
+	if err := client(context.Background(), "/users.Service/Get", nil, nil, nil, invoker); err != nil || outgoing != nil {
+		t.Errorf("outgoing budget = %v, %v; want none", outgoing, err)
+	}
+}
@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vladzorgan/common/logging"
+)
+
+// InvalidationRoutingKey ключ маршрутизации (и канал Redis) сообщений об инвалидации
+const InvalidationRoutingKey = "cache.invalidate"
+
+var (
+	// invalidationsSentTotal считает отправленные сообщения об инвалидации
+	invalidationsSentTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_invalidations_sent_total",
+			Help: "Количество отправленных сообщений об инвалидации кеша",
+		},
+		[]string{"entity", "status"},
+	)
+
+	// invalidationsReceivedTotal считает полученные от других экземпляров сообщения об инвалидации
+	invalidationsReceivedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_invalidations_received_total",
+			Help: "Количество полученных сообщений об инвалидации кеша",
+		},
+		[]string{"entity"},
+	)
+)
+
+// Invalidation описывает записи кеша, ставшие неактуальными после изменения данных.
+// Если не заданы ни IDs, ни Prefix, инвалидируются все записи сущности.
+type Invalidation struct {
+	Entity string `json:"entity"`
+	IDs    []uint `json:"ids,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+	// Экземпляр-отправитель; свои сообщения повторно не применяются
+	Source string    `json:"source"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+// Keys возвращает ключи кеша, соответствующие IDs
+func (i Invalidation) Keys() []string {
+	keys := make([]string, 0, len(i.IDs))
+	for _, id := range i.IDs {
+		keys = append(keys, strconv.FormatUint(uint64(id), 10))
+	}
+	return keys
+}
+
+// Transport доставляет сообщения об инвалидации другим экземплярам сервиса
+type Transport interface {
+	Publish(ctx context.Context, invalidation Invalidation) error
+}
+
+// Subscriber получает сообщения об инвалидации от других экземпляров.
+// Subscribe блокируется до отмены ctx.
+type Subscriber interface {
+	Subscribe(ctx context.Context, handle func(ctx context.Context, invalidation Invalidation)) error
+}
+
+// Bus рассылает сообщения об инвалидации между экземплярами сервиса и удаляет
+// соответствующие записи из зарегистрированных кешей в памяти процесса.
+// Доставка не гарантируется: TTL записей остается последним рубежом от устаревших данных.
+type Bus struct {
+	transport  Transport
+	instanceID string
+	logger     logging.Logger
+
+	mutex    sync.RWMutex
+	evictors map[string][]Evictor
+}
+
+// NewBus создает шину инвалидации поверх transport.
+// Если transport равен nil, инвалидация применяется только к локальным кешам.
+func NewBus(transport Transport, logger logging.Logger) *Bus {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	return &Bus{
+		transport:  transport,
+		instanceID: uuid.New().String(),
+		logger:     logger,
+		evictors:   make(map[string][]Evictor),
+	}
+}
+
+// InstanceID возвращает идентификатор экземпляра, которым помечаются отправляемые сообщения
+func (b *Bus) InstanceID() string {
+	return b.instanceID
+}
+
+// Register подписывает кеш на инвалидацию записей сущности entity
+func (b *Bus) Register(entity string, evictor Evictor) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.evictors[entity] = append(b.evictors[entity], evictor)
+}
+
+// Invalidate удаляет записи из локальных кешей и рассылает сообщение остальным экземплярам.
+// Ошибка доставки логируется и возвращается, но локальные кеши уже очищены.
+func (b *Bus) Invalidate(ctx context.Context, invalidation Invalidation) error {
+	invalidation.Source = b.instanceID
+	invalidation.SentAt = time.Now()
+
+	b.apply(invalidation)
+
+	if b.transport == nil {
+		return nil
+	}
+
+	if err := b.transport.Publish(ctx, invalidation); err != nil {
+		invalidationsSentTotal.WithLabelValues(invalidation.Entity, "error").Inc()
+		b.logger.WithContext(ctx).Warn("Failed to publish cache invalidation for %s: %v", invalidation.Entity, err)
+		return err
+	}
+
+	invalidationsSentTotal.WithLabelValues(invalidation.Entity, "success").Inc()
+	return nil
+}
+
+// InvalidateIDs инвалидирует записи сущности с указанными ID
+func (b *Bus) InvalidateIDs(ctx context.Context, entity string, ids ...uint) error {
+	return b.Invalidate(ctx, Invalidation{Entity: entity, IDs: ids})
+}
+
+// InvalidatePrefix инвалидирует записи сущности с ключами, начинающимися с prefix
+func (b *Bus) InvalidatePrefix(ctx context.Context, entity, prefix string) error {
+	return b.Invalidate(ctx, Invalidation{Entity: entity, Prefix: prefix})
+}
+
+// Handle применяет сообщение, полученное от другого экземпляра
+func (b *Bus) Handle(ctx context.Context, invalidation Invalidation) {
+	if invalidation.Source == b.instanceID {
+		return
+	}
+
+	invalidationsReceivedTotal.WithLabelValues(invalidation.Entity).Inc()
+	b.logger.WithContext(ctx).Debug("Received cache invalidation for %s from %s", invalidation.Entity, invalidation.Source)
+
+	b.apply(invalidation)
+}
+
+// Run получает сообщения через subscriber до отмены ctx.
+// Обычно запускается в отдельной горутине.
+func (b *Bus) Run(ctx context.Context, subscriber Subscriber) error {
+	return subscriber.Subscribe(ctx, b.Handle)
+}
+
+// apply удаляет записи из кешей, зарегистрированных для сущности
+func (b *Bus) apply(invalidation Invalidation) {
+	b.mutex.RLock()
+	evictors := b.evictors[invalidation.Entity]
+	b.mutex.RUnlock()
+
+	keys := invalidation.Keys()
+	for _, evictor := range evictors {
+		if len(keys) > 0 {
+			evictor.Evict(keys...)
+		}
+		if invalidation.Prefix != "" || len(keys) == 0 {
+			evictor.EvictPrefix(invalidation.Prefix)
+		}
+	}
+}
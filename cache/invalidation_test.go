@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/vladzorgan/common/redis"
+)
+
+// replica представляет экземпляр сервиса с локальным кешем и своей шиной инвалидации
+type replica struct {
+	cache *LocalCache[string]
+	bus   *Bus
+}
+
+func newReplica(t *testing.T, ctx context.Context, addr string) *replica {
+	t.Helper()
+
+	client, err := redis.NewClient(addr, "", 0, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to connect to miniredis: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	transport := NewRedisTransport(client, "")
+	r := &replica{
+		cache: NewLocalCache[string](time.Minute),
+		bus:   NewBus(transport, nil),
+	}
+	r.bus.Register("city", r.cache)
+
+	go r.bus.Run(ctx, transport)
+
+	return r
+}
+
+func TestBusInvalidatesOtherReplicas(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := newReplica(t, ctx, server.Addr())
+	second := newReplica(t, ctx, server.Addr())
+
+	// Ждем, пока обе подписки зарегистрируются в Redis
+	waitFor(t, func() bool { return server.PubSubNumSub(InvalidationRoutingKey)[InvalidationRoutingKey] == 2 })
+
+	for _, r := range []*replica{first, second} {
+		r.cache.Set("1", "Moscow")
+		r.cache.Set("2", "Kazan")
+	}
+
+	if err := first.bus.InvalidateIDs(ctx, "city", 1); err != nil {
+		t.Fatalf("InvalidateIDs() error = %v", err)
+	}
+
+	// Локальный кеш очищается сразу
+	if _, ok := first.cache.Get("1"); ok {
+		t.Error("first replica still caches invalidated entry")
+	}
+
+	waitFor(t, func() bool {
+		_, ok := second.cache.Get("1")
+		return !ok
+	})
+
+	for _, r := range []*replica{first, second} {
+		if _, ok := r.cache.Get("2"); !ok {
+			t.Error("unrelated entry was evicted")
+		}
+	}
+}
+
+func TestBusPrefixAndEntityInvalidation(t *testing.T) {
+	bus := NewBus(nil, nil)
+
+	cities := NewLocalCache[string](0)
+	brands := NewLocalCache[string](0)
+	bus.Register("city", cities)
+	bus.Register("brand", brands)
+
+	cities.Set("list:1", "page")
+	cities.Set("1", "Moscow")
+	brands.Set("1", "Apple")
+
+	bus.Handle(context.Background(), Invalidation{Entity: "city", Prefix: "list:", Source: "other"})
+	if _, ok := cities.Get("list:1"); ok {
+		t.Error("prefix entry was not evicted")
+	}
+	if _, ok := cities.Get("1"); !ok {
+		t.Error("entry outside prefix was evicted")
+	}
+
+	bus.Handle(context.Background(), Invalidation{Entity: "city", Source: "other"})
+	if cities.Len() != 0 {
+		t.Errorf("entity invalidation left %d entries", cities.Len())
+	}
+	if _, ok := brands.Get("1"); !ok {
+		t.Error("other entity cache was evicted")
+	}
+}
+
+func TestLocalCacheTTL(t *testing.T) {
+	c := NewLocalCache[int](time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get() = %v, %v", v, ok)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Error("expired entry returned")
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,101 @@
+// Package cache предоставляет кеши в памяти процесса и согласованную инвалидацию
+// между экземплярами сервиса.
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Evictor удаляет записи из кеша в памяти процесса по команде шины инвалидации
+type Evictor interface {
+	// Evict удаляет записи с указанными ключами
+	Evict(keys ...string)
+	// EvictPrefix удаляет записи, ключ которых начинается с prefix; пустой prefix очищает кеш
+	EvictPrefix(prefix string)
+}
+
+// localEntry представляет запись кеша со временем истечения
+type localEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// LocalCache представляет потокобезопасный кеш в памяти процесса с TTL записей.
+// TTL ограничивает устаревание данных, если сообщение об инвалидации потеряно.
+type LocalCache[V any] struct {
+	mutex   sync.RWMutex
+	entries map[string]localEntry[V]
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// NewLocalCache создает кеш в памяти процесса с временем жизни записей ttl (0 - без истечения)
+func NewLocalCache[V any](ttl time.Duration) *LocalCache[V] {
+	return &LocalCache[V]{
+		entries: make(map[string]localEntry[V]),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// Get возвращает значение по ключу, если оно есть и не истекло
+func (c *LocalCache[V]) Get(key string) (V, bool) {
+	c.mutex.RLock()
+	entry, ok := c.entries[key]
+	c.mutex.RUnlock()
+
+	if !ok || (!entry.expiresAt.IsZero() && c.now().After(entry.expiresAt)) {
+		var zero V
+		return zero, false
+	}
+
+	return entry.value, true
+}
+
+// Set сохраняет значение по ключу
+func (c *LocalCache[V]) Set(key string, value V) {
+	entry := localEntry[V]{value: value}
+	if c.ttl > 0 {
+		entry.expiresAt = c.now().Add(c.ttl)
+	}
+
+	c.mutex.Lock()
+	c.entries[key] = entry
+	c.mutex.Unlock()
+}
+
+// Len возвращает количество записей в кеше, включая истекшие, но еще не удаленные
+func (c *LocalCache[V]) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.entries)
+}
+
+// Evict удаляет записи с указанными ключами
+func (c *LocalCache[V]) Evict(keys ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+}
+
+// EvictPrefix удаляет записи, ключ которых начинается с prefix
+func (c *LocalCache[V]) EvictPrefix(prefix string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if prefix == "" {
+		c.entries = make(map[string]localEntry[V])
+		return
+	}
+
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/streadway/amqp"
+	events "github.com/vladzorgan/common/messaging/rabbitmq"
+	"github.com/vladzorgan/common/redis"
+)
+
+// RedisTransport доставляет сообщения об инвалидации через Redis pub/sub
+type RedisTransport struct {
+	client  *redis.Client
+	channel string
+}
+
+// NewRedisTransport создает транспорт Redis pub/sub.
+// Пустой channel заменяется на InvalidationRoutingKey; сервисы с общим Redis должны задавать свой канал.
+func NewRedisTransport(client *redis.Client, channel string) *RedisTransport {
+	if channel == "" {
+		channel = InvalidationRoutingKey
+	}
+
+	return &RedisTransport{
+		client:  client,
+		channel: channel,
+	}
+}
+
+// Publish публикует сообщение в канал
+func (t *RedisTransport) Publish(ctx context.Context, invalidation Invalidation) error {
+	payload, err := json.Marshal(invalidation)
+	if err != nil {
+		return fmt.Errorf("failed to serialize cache invalidation: %v", err)
+	}
+
+	return t.client.Publish(ctx, t.channel, payload)
+}
+
+// Subscribe получает сообщения из канала до отмены ctx.
+// После потери соединения go-redis восстанавливает подписку самостоятельно.
+func (t *RedisTransport) Subscribe(ctx context.Context, handle func(ctx context.Context, invalidation Invalidation)) error {
+	pubsub := t.client.Subscribe(ctx, t.channel)
+	defer pubsub.Close()
+
+	// Дожидаемся подтверждения подписки
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %v", t.channel, err)
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+
+			var invalidation Invalidation
+			if err := json.Unmarshal([]byte(message.Payload), &invalidation); err != nil {
+				continue // Чужие или поврежденные сообщения пропускаем
+			}
+			handle(ctx, invalidation)
+		}
+	}
+}
+
+// RabbitMQTransport доставляет сообщения об инвалидации через RabbitMQ.
+// Для получения сообщений каждый экземпляр должен подписать свою очередь
+// на InvalidationRoutingKey с обработчиком Bus.ConsumerHandler.
+type RabbitMQTransport struct {
+	publisher events.EventPublisher
+}
+
+// NewRabbitMQTransport создает транспорт RabbitMQ поверх издателя событий
+func NewRabbitMQTransport(publisher events.EventPublisher) *RabbitMQTransport {
+	return &RabbitMQTransport{publisher: publisher}
+}
+
+// Publish публикует сообщение с ключом маршрутизации InvalidationRoutingKey
+func (t *RabbitMQTransport) Publish(ctx context.Context, invalidation Invalidation) error {
+	return t.publisher.PublishEvent(ctx, InvalidationRoutingKey, invalidation)
+}
+
+// ConsumerHandler возвращает обработчик сообщений об инвалидации для rabbitmq.Consumer
+func (b *Bus) ConsumerHandler() events.HandlerFunc {
+	return func(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+		var invalidation Invalidation
+		if err := json.Unmarshal(message, &invalidation); err != nil {
+			// Повторная доставка не исправит формат сообщения
+			b.logger.WithContext(ctx).Warn("Failed to decode cache invalidation: %v", err)
+			return nil
+		}
+
+		b.Handle(ctx, invalidation)
+		return nil
+	}
+}
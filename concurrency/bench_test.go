@@ -0,0 +1,78 @@
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// downstreamCall имитирует вызов другого сервиса
+func downstreamCall(ctx context.Context) error {
+	select {
+	case <-time.After(100 * time.Microsecond):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func benchmarkTasks(n int) []Task {
+	tasks := make([]Task, n)
+	for i := range tasks {
+		tasks[i] = downstreamCall
+	}
+	return tasks
+}
+
+func BenchmarkParallel(b *testing.B) {
+	for _, limit := range []int{4, 16, 0} {
+		b.Run(limitName(limit), func(b *testing.B) {
+			tasks := benchmarkTasks(64)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := Parallel(context.Background(), limit, tasks...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNaiveGoroutines(b *testing.B) {
+	tasks := benchmarkTasks(64)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		errs := make([]error, len(tasks))
+		for j, task := range tasks {
+			wg.Add(1)
+			go func(j int, task Task) {
+				defer wg.Done()
+				errs[j] = task(context.Background())
+			}(j, task)
+		}
+		wg.Wait()
+	}
+}
+
+func BenchmarkMap(b *testing.B) {
+	items := make([]int, 64)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := Map(context.Background(), 16, items, func(ctx context.Context, item int) (int, error) {
+			return item, downstreamCall(ctx)
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func limitName(limit int) string {
+	if limit <= 0 {
+		return "unbounded"
+	}
+	return fmt.Sprintf("limit=%d", limit)
+}
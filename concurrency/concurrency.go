@@ -0,0 +1,190 @@
+// Package concurrency предоставляет ограниченное параллельное выполнение задач
+// для агрегирующих обработчиков, обращающихся к нескольким сервисам.
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"github.com/vladzorgan/common/budget"
+	"github.com/vladzorgan/common/killswitch"
+)
+
+// Task представляет задачу, выполняемую параллельно с другими
+type Task func(ctx context.Context) error
+
+// TaskError содержит ошибку задачи и ее позицию во входном списке
+type TaskError struct {
+	Index int
+	Err   error
+}
+
+// Error возвращает текст ошибки
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("task %d: %v", e.Index, e.Err)
+}
+
+// Unwrap возвращает исходную ошибку
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError представляет панику в задаче, преобразованную в ошибку
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// Error возвращает текст ошибки
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Parallel выполняет задачи, одновременно не более limit (limit <= 0 - без ограничения).
+// Первая ошибка отменяет контекст остальных задач, не начатые задачи пропускаются;
+// возвращается первая ошибка в виде *TaskError.
+func Parallel(ctx context.Context, limit int, tasks ...Task) error {
+	return run(ctx, limit, len(tasks), true, func(ctx context.Context, i int) error {
+		return tasks[i](ctx)
+	})
+}
+
+// ParallelAll выполняет все задачи, одновременно не более limit, и возвращает
+// ошибки всех завершившихся неудачно задач, объединенные errors.Join в порядке задач.
+func ParallelAll(ctx context.Context, limit int, tasks ...Task) error {
+	return run(ctx, limit, len(tasks), false, func(ctx context.Context, i int) error {
+		return tasks[i](ctx)
+	})
+}
+
+// Map применяет fn к элементам items, одновременно не более limit, и возвращает
+// результаты в порядке элементов. Первая ошибка отменяет обработку остальных элементов.
+func Map[T, R any](ctx context.Context, limit int, items []T, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	err := run(ctx, limit, len(items), true, func(ctx context.Context, i int) error {
+		result, err := fn(ctx, items[i])
+		if err != nil {
+			return err
+		}
+		results[i] = result
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// MapAll применяет fn ко всем элементам items, одновременно не более limit.
+// Для элементов с ошибкой результат остается нулевым значением; ошибки объединяются errors.Join.
+// Подходит для агрегации, в которой частичный ответ лучше отсутствия ответа.
+func MapAll[T, R any](ctx context.Context, limit int, items []T, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	err := run(ctx, limit, len(items), false, func(ctx context.Context, i int) error {
+		result, err := fn(ctx, items[i])
+		if err != nil {
+			return err
+		}
+		results[i] = result
+		return nil
+	})
+	return results, err
+}
+
+// run выполняет n задач с ограничением параллелизма
+func run(ctx context.Context, limit, n int, failFast bool, fn func(ctx context.Context, i int) error) error {
+	if n == 0 {
+		return nil
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		firstErr error
+		errs     = make([]error, n)
+		slots    = make(chan struct{}, effectiveLimit(ctx, limit, n))
+	)
+
+launch:
+	for i := 0; i < n; i++ {
+		select {
+		case slots <- struct{}{}:
+		case <-runCtx.Done():
+			break launch
+		}
+
+		// Контекст мог быть отменен одновременно с освобождением слота
+		if runCtx.Err() != nil {
+			<-slots
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			err := call(runCtx, i, fn)
+			if err == nil {
+				return
+			}
+
+			taskErr := &TaskError{Index: i, Err: err}
+			mutex.Lock()
+			errs[i] = taskErr
+			if firstErr == nil {
+				firstErr = taskErr
+			}
+			mutex.Unlock()
+
+			if failFast {
+				cancel()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if failFast && firstErr != nil {
+		return firstErr
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	// Отмена вызывающим кодом прерывает запуск оставшихся задач
+	return ctx.Err()
+}
+
+// call выполняет задачу, преобразуя панику в ошибку
+func call(ctx context.Context, i int, fn func(ctx context.Context, i int) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx, i)
+}
+
+// effectiveLimit ограничивает параллелизм количеством задач и оставшимся бюджетом запроса:
+// запускать одновременно больше подзапросов, чем разрешает бюджет, бессмысленно.
+func effectiveLimit(ctx context.Context, limit, n int) int {
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+
+	if b := budget.FromContext(ctx); b != nil && !killswitch.IsDisabled(killswitch.FeatureRequestBudget) {
+		if remaining := int(b.RemainingRequests()); remaining < limit {
+			// Первые попытки бюджет не запрещает, поэтому выполняем задачи хотя бы по одной
+			limit = max(remaining, 1)
+		}
+	}
+
+	return limit
+}
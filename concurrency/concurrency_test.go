@@ -0,0 +1,164 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vladzorgan/common/budget"
+)
+
+func TestParallelRespectsLimit(t *testing.T) {
+	var running, peak atomic.Int32
+
+	tasks := make([]Task, 20)
+	for i := range tasks {
+		tasks[i] = func(ctx context.Context) error {
+			current := running.Add(1)
+			for {
+				old := peak.Load()
+				if current <= old || peak.CompareAndSwap(old, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			return nil
+		}
+	}
+
+	if err := Parallel(context.Background(), 3, tasks...); err != nil {
+		t.Fatalf("Parallel() error = %v", err)
+	}
+	if peak.Load() > 3 {
+		t.Errorf("peak concurrency = %d, want <= 3", peak.Load())
+	}
+}
+
+func TestParallelFailFast(t *testing.T) {
+	var started atomic.Int32
+	failure := errors.New("downstream unavailable")
+
+	tasks := make([]Task, 10)
+	tasks[0] = func(ctx context.Context) error {
+		started.Add(1)
+		return failure
+	}
+	for i := 1; i < len(tasks); i++ {
+		tasks[i] = func(ctx context.Context) error {
+			started.Add(1)
+			<-ctx.Done()
+			return ctx.Err()
+		}
+	}
+
+	err := Parallel(context.Background(), 2, tasks...)
+
+	var taskErr *TaskError
+	if !errors.As(err, &taskErr) || taskErr.Index != 0 || !errors.Is(err, failure) {
+		t.Fatalf("Parallel() error = %v, want task 0 failure", err)
+	}
+	if started.Load() > 2 {
+		t.Errorf("started %d tasks after failure, want at most 2", started.Load())
+	}
+}
+
+func TestParallelAllCollectsErrors(t *testing.T) {
+	err := ParallelAll(context.Background(), 2,
+		func(ctx context.Context) error { return errors.New("first") },
+		func(ctx context.Context) error { return nil },
+		func(ctx context.Context) error { panic("boom") },
+	)
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Fatalf("expected panic converted to error, got %v", err)
+	}
+	if got := err.Error(); got != "task 0: first\ntask 2: panic: boom" {
+		t.Errorf("error = %q", got)
+	}
+}
+
+func TestMapPreservesOrder(t *testing.T) {
+	items := []int{5, 1, 4, 2, 3}
+
+	results, err := Map(context.Background(), 2, items, func(ctx context.Context, item int) (int, error) {
+		time.Sleep(time.Duration(item) * time.Millisecond)
+		return item * 10, nil
+	})
+	if err != nil {
+		t.Fatalf("Map() error = %v", err)
+	}
+
+	for i, item := range items {
+		if results[i] != item*10 {
+			t.Errorf("results[%d] = %d, want %d", i, results[i], item*10)
+		}
+	}
+}
+
+func TestMapAllReturnsPartialResults(t *testing.T) {
+	results, err := MapAll(context.Background(), 0, []int{1, 2, 3}, func(ctx context.Context, item int) (string, error) {
+		if item == 2 {
+			return "", errors.New("not found")
+		}
+		return "ok", nil
+	})
+
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if results[0] != "ok" || results[1] != "" || results[2] != "ok" {
+		t.Errorf("results = %q", results)
+	}
+}
+
+func TestParallelStopsOnCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var started atomic.Int32
+	tasks := make([]Task, 5)
+	for i := range tasks {
+		tasks[i] = func(ctx context.Context) error {
+			started.Add(1)
+			cancel()
+			return nil
+		}
+	}
+
+	if err := ParallelAll(ctx, 1, tasks...); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ParallelAll() error = %v, want context.Canceled", err)
+	}
+	if started.Load() != 1 {
+		t.Errorf("started %d tasks after cancellation, want 1", started.Load())
+	}
+}
+
+func TestEffectiveLimitUsesBudget(t *testing.T) {
+	ctx := budget.WithBudget(context.Background(), budget.New(2, 0))
+	if got := effectiveLimit(ctx, 10, 8); got != 2 {
+		t.Errorf("effectiveLimit() = %d, want 2", got)
+	}
+
+	exhausted := budget.WithBudget(context.Background(), budget.New(0, 0))
+	if got := effectiveLimit(exhausted, 10, 8); got != 1 {
+		t.Errorf("effectiveLimit() with exhausted budget = %d, want 1", got)
+	}
+
+	if got := effectiveLimit(context.Background(), 0, 8); got != 8 {
+		t.Errorf("effectiveLimit() without limit = %d, want 8", got)
+	}
+
+	// Подзапросы, учтенные в бюджете, уменьшают параллелизм следующих задач
+	b := budget.New(5, 0)
+	consumed := budget.WithBudget(context.Background(), b)
+	for i := 0; i < 2; i++ {
+		budget.ConsumeRequest(consumed, nil, "users")
+	}
+	if got := effectiveLimit(consumed, 10, 8); got != 3 {
+		t.Errorf("effectiveLimit() after 2 sub-requests = %d, want 3", got)
+	}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadInto заполняет пользовательскую структуру конфигурации из переменных окружения
+// по тегам полей:
+//
+//	type Config struct {
+//		PaymentURL string        `env:"PAYMENT_URL" required:"true"`
+//		Workers    int           `env:"WORKERS" default:"4"`
+//		Timeout    time.Duration `env:"PAYMENT_TIMEOUT" default:"5s"`
+//		Currencies []string      `env:"CURRENCIES" default:"RUB,USD" sep:","`
+//	}
+//
+// Поддерживаются строки, bool, целые и дробные числа, time.Duration и слайсы строк.
+// Вложенные структуры без тега env обрабатываются рекурсивно.
+// Все ошибки возвращаются вместе в виде *ValidationError.
+func LoadInto(cfg interface{}, opts ...Option) error {
+	value := reflect.ValueOf(cfg)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.New("config must be a non-nil pointer to a struct")
+	}
+
+	env := newEnvLoader(opts)
+	if err := loadStruct(env, value.Elem()); err != nil {
+		return err
+	}
+
+	return env.err()
+}
+
+// loadStruct заполняет поля структуры по тегам
+func loadStruct(env *envLoader, value reflect.Value) error {
+	valueType := value.Type()
+
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		key, ok := field.Tag.Lookup("env")
+		if !ok {
+			if fieldValue.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Duration(0)) {
+				if err := loadStruct(env, fieldValue); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if required, _ := strconv.ParseBool(field.Tag.Get("required")); required {
+			env.require(key)
+		}
+
+		raw := env.get(key, "")
+		if raw == "" {
+			raw = field.Tag.Get("default")
+		}
+		if raw == "" {
+			continue
+		}
+
+		separator := field.Tag.Get("sep")
+		if separator == "" {
+			separator = ","
+		}
+
+		reason, err := setField(fieldValue, raw, separator)
+		if err != nil {
+			return fmt.Errorf("field %s: %v", field.Name, err)
+		}
+		if reason != "" {
+			env.addError(key, raw, reason)
+		}
+	}
+
+	return nil
+}
+
+// setField разбирает значение и записывает его в поле.
+// Возвращает причину, если значение некорректно, и ошибку, если тип поля не поддерживается.
+func setField(field reflect.Value, raw, separator string) (string, error) {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return "must be a duration (e.g. 30s)", nil
+		}
+		field.SetInt(int64(duration))
+		return "", nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "must be a boolean", nil
+		}
+		field.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return "must be an integer", nil
+		}
+		field.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return "must be a non-negative integer", nil
+		}
+		field.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return "must be a number", nil
+		}
+		field.SetFloat(value)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return "", fmt.Errorf("unsupported slice type %s", field.Type())
+		}
+		parts := strings.Split(raw, separator)
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		field.Set(reflect.ValueOf(parts).Convert(field.Type()))
+	default:
+		return "", fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return "", nil
+}
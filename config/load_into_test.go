@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// paymentConfig конфигурация сервиса для тестов LoadInto
+type paymentConfig struct {
+	PaymentURL string        `env:"PAYMENT_URL" required:"true"`
+	Workers    int           `env:"WORKERS" default:"4"`
+	Timeout    time.Duration `env:"PAYMENT_TIMEOUT" default:"5s"`
+	Currencies []string      `env:"CURRENCIES" default:"RUB, USD"`
+	Ratio      float64       `env:"PAYMENT_RATIO"`
+	Limits     struct {
+		Daily uint `env:"DAILY_LIMIT" default:"100"`
+	}
+}
+
+// fieldErrors возвращает ошибки *ValidationError по имени переменной
+func fieldErrors(t *testing.T, err error) map[string]*FieldError {
+	t.Helper()
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("error = %v, want *ValidationError", err)
+	}
+
+	result := make(map[string]*FieldError, len(validationErr.Errors))
+	for _, fieldErr := range validationErr.Errors {
+		result[fieldErr.Key] = fieldErr
+	}
+	return result
+}
+
+func TestLoadIntoAppliesDefaults(t *testing.T) {
+	var cfg paymentConfig
+	if err := LoadInto(&cfg, WithValues(map[string]string{"PAYMENT_URL": "http://payments", "WORKERS": "8"})); err != nil {
+		t.Fatalf("LoadInto() error = %v", err)
+	}
+
+	if cfg.PaymentURL != "http://payments" || cfg.Workers != 8 || cfg.Timeout != 5*time.Second || cfg.Limits.Daily != 100 {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Currencies, []string{"RUB", "USD"}) {
+		t.Errorf("Currencies = %q, want [RUB USD]", cfg.Currencies)
+	}
+}
+
+func TestLoadIntoReportsAllErrors(t *testing.T) {
+	var cfg paymentConfig
+	err := LoadInto(&cfg, WithValues(map[string]string{
+		"WORKERS":         "many",
+		"PAYMENT_TIMEOUT": "5",
+		"PAYMENT_RATIO":   "half",
+		"DAILY_LIMIT":     "-1",
+	}))
+
+	errs := fieldErrors(t, err)
+	want := map[string]string{
+		"PAYMENT_URL":     "must be set",
+		"WORKERS":         "must be an integer",
+		"PAYMENT_TIMEOUT": "must be a duration (e.g. 30s)",
+		"PAYMENT_RATIO":   "must be a number",
+		"DAILY_LIMIT":     "must be a non-negative integer",
+	}
+	if len(errs) != len(want) {
+		t.Errorf("errors = %v, want %d", err, len(want))
+	}
+	for key, reason := range want {
+		if errs[key] == nil || errs[key].Reason != reason {
+			t.Errorf("%s: error = %v, want %q", key, errs[key], reason)
+		}
+	}
+}
+
+func TestLoadIntoRequired(t *testing.T) {
+	t.Setenv("WORKERS", "")
+
+	// Required делает переменную обязательной даже при наличии значения по умолчанию
+	var cfg paymentConfig
+	err := LoadInto(&cfg, Required("WORKERS"), WithValues(map[string]string{"PAYMENT_URL": "http://payments"}))
+	if errs := fieldErrors(t, err); len(errs) != 1 || errs["WORKERS"] == nil {
+		t.Errorf("error = %v, want only WORKERS must be set", err)
+	}
+}
+
+func TestLoadIntoRejectsUnsupportedTargets(t *testing.T) {
+	var cfg paymentConfig
+	if err := LoadInto(cfg); err == nil {
+		t.Error("LoadInto() with a struct value must fail")
+	}
+
+	var unsupported struct {
+		Ports []int `env:"PORTS" default:"80"`
+	}
+	if err := LoadInto(&unsupported); err == nil {
+		t.Error("LoadInto() with []int must fail")
+	}
+}
+
+func TestLoadBaseConfigReportsAllErrors(t *testing.T) {
+	t.Setenv("SERVICE_NAME", "")
+
+	_, err := LoadBaseConfig(Required("SERVICE_NAME"), WithValues(map[string]string{
+		"TIMEOUT_SECONDS":        "-5",
+		"ENABLE_REFLECTION":      "maybe",
+		"GRPC_MAX_RECV_MSG_SIZE": "big",
+	}))
+
+	errs := fieldErrors(t, err)
+	for _, key := range []string{"SERVICE_NAME", "TIMEOUT_SECONDS", "ENABLE_REFLECTION", "GRPC_MAX_RECV_MSG_SIZE"} {
+		if errs[key] == nil {
+			t.Errorf("missing error for %s in %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FieldError описывает ошибку значения переменной окружения
+type FieldError struct {
+	// Имя переменной окружения
+	Key string
+	// Исходное значение (пустое, если переменная не задана)
+	Value string
+	// Причина ошибки
+	Reason string
+}
+
+// Error возвращает текст ошибки
+func (e *FieldError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("%s: %s", e.Key, e.Reason)
+	}
+	return fmt.Sprintf("%s=%q: %s", e.Key, e.Value, e.Reason)
+}
+
+// ValidationError содержит все ошибки, найденные при загрузке конфигурации
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+// Error возвращает текст ошибки со списком всех некорректных переменных
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+// Option настраивает загрузку конфигурации
+type Option func(*loadOptions)
+
+// loadOptions содержит настройки загрузки конфигурации
+type loadOptions struct {
+	required []string
+	values   map[string]string
+}
+
+// Required объявляет переменные окружения обязательными: загрузка завершится ошибкой,
+// если они не заданы, даже при наличии значения по умолчанию
+func Required(keys ...string) Option {
+	return func(o *loadOptions) {
+		o.required = append(o.required, keys...)
+	}
+}
+
+// WithValues задает значения переменных, которые имеют приоритет над окружением процесса
+// (например, прочитанные из env-файла, см. ReadEnvFile)
+func WithValues(values map[string]string) Option {
+	return func(o *loadOptions) {
+		o.values = values
+	}
+}
+
+// envLoader читает переменные окружения, накапливая ошибки разбора
+type envLoader struct {
+	errs   []*FieldError
+	values map[string]string
+}
+
+// newEnvLoader создает загрузчик и проверяет обязательные переменные из опций
+func newEnvLoader(opts []Option) *envLoader {
+	options := &loadOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	l := &envLoader{values: options.values}
+	for _, key := range options.required {
+		l.require(key)
+	}
+	return l
+}
+
+// addError добавляет ошибку значения переменной
+func (l *envLoader) addError(key, value, reason string) {
+	l.errs = append(l.errs, &FieldError{Key: key, Value: value, Reason: reason})
+}
+
+// require проверяет, что переменная задана
+func (l *envLoader) require(key string) {
+	for _, err := range l.errs {
+		if err.Key == key && err.Value == "" {
+			return // Уже отмечена как отсутствующая
+		}
+	}
+
+	if l.get(key, "") == "" {
+		l.addError(key, "", "must be set")
+	}
+}
+
+// err возвращает накопленные ошибки или nil
+func (l *envLoader) err() error {
+	if len(l.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: l.errs}
+}
+
+// get получает значение из WithValues, переменной окружения или значение по умолчанию
+func (l *envLoader) get(key, defaultValue string) string {
+	if value := l.values[key]; value != "" {
+		return value
+	}
+	return getEnv(key, defaultValue)
+}
+
+// string получает значение переменной окружения или значение по умолчанию
+func (l *envLoader) string(key, defaultValue string) string {
+	return l.get(key, defaultValue)
+}
+
+// int получает значение переменной окружения как int
+func (l *envLoader) int(key string, defaultValue int) int {
+	valueStr := l.get(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		l.addError(key, valueStr, "must be an integer")
+		return defaultValue
+	}
+
+	return value
+}
+
+// bool получает значение переменной окружения как bool
+func (l *envLoader) bool(key string, defaultValue bool) bool {
+	valueStr := l.get(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		l.addError(key, valueStr, "must be a boolean")
+		return defaultValue
+	}
+
+	return value
+}
@@ -0,0 +1,264 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/vladzorgan/common/logging"
+)
+
+// restartRequiredFields поля BaseConfig, которые применяются только при запуске сервиса.
+// Их изменения Watcher не применяет и не передает обработчикам, а только логирует.
+var restartRequiredFields = map[string]bool{
+	"ServiceName":              true,
+	"ServicePrefix":            true,
+	"URLPrefix":                true,
+	"Env":                      true,
+	"Port":                     true,
+	"DatabaseURL":              true,
+	"RabbitMQURL":              true,
+	"RedisURL":                 true,
+	"RedisPassword":            true,
+	"RedisDB":                  true,
+	"GRPCPort":                 true,
+	"GRPCMaxRecvMsgSize":       true,
+	"GRPCMaxSendMsgSize":       true,
+	"GRPCKeepAliveTime":        true,
+	"GRPCKeepAliveTimeout":     true,
+	"EnableReflection":         true,
+	"GRPCTLSCertFile":          true,
+	"GRPCTLSKeyFile":           true,
+	"GRPCTLSClientCAFile":      true,
+	"GRPCTLSRequireClientCert": true,
+	"GRPCAllowInsecure":        true,
+}
+
+// ChangeFunc вызывается для каждого изменившегося поля BaseConfig (имя поля структуры, например "LogLevel")
+type ChangeFunc func(field string, old, new interface{})
+
+// WatcherOptions содержит настройки Watcher
+type WatcherOptions struct {
+	// Интервал перечитывания файла; 0 - перечитывать по событиям файловой системы (fsnotify)
+	Interval time.Duration
+	// Логгер
+	Logger logging.Logger
+}
+
+// Watcher перечитывает конфигурацию из env-файла при его изменении и сообщает
+// обработчикам OnChange об изменившихся полях. Значения из файла имеют приоритет над окружением процесса.
+//
+// Поля, которые применяются только при запуске (Port, DatabaseURL и т.п.), не изменяются:
+// их новые значения логируются и вступят в силу после перезапуска.
+type Watcher struct {
+	path    string
+	options *WatcherOptions
+	logger  logging.Logger
+
+	mu        sync.RWMutex
+	current   BaseConfig
+	callbacks []ChangeFunc
+	// Последние проигнорированные значения полей restartRequiredFields, чтобы не повторять предупреждение
+	ignored map[string]interface{}
+
+	started  bool
+	stopChan chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewWatcher создает наблюдатель файла path с исходной конфигурацией current
+func NewWatcher(current *BaseConfig, path string, options *WatcherOptions) *Watcher {
+	if options == nil {
+		options = &WatcherOptions{}
+	}
+
+	logger := options.Logger
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	return &Watcher{
+		path:     path,
+		options:  options,
+		logger:   logger,
+		current:  *current,
+		ignored:  make(map[string]interface{}),
+		stopChan: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// OnChange регистрирует обработчик изменений. Обработчики вызываются последовательно
+// из горутины наблюдателя после применения всех изменений, поэтому Current уже возвращает новые значения.
+func (w *Watcher) OnChange(fn ChangeFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callbacks = append(w.callbacks, fn)
+}
+
+// Current возвращает копию текущей конфигурации
+func (w *Watcher) Current() BaseConfig {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// Start запускает наблюдение в фоне; останавливается через Stop или отмену ctx
+func (w *Watcher) Start(ctx context.Context) error {
+	if w.options.Interval > 0 {
+		w.markStarted()
+		go w.pollLoop(ctx)
+		return nil
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config file watcher: %w", err)
+	}
+
+	// Наблюдаем за каталогом: ConfigMap в Kubernetes обновляется заменой символической ссылки ..data
+	if err := fsWatcher.Add(filepath.Dir(w.path)); err != nil {
+		fsWatcher.Close()
+		return fmt.Errorf("failed to watch config file %s: %w", w.path, err)
+	}
+
+	w.markStarted()
+	go w.notifyLoop(ctx, fsWatcher)
+	return nil
+}
+
+// markStarted отмечает запуск фоновой горутины, завершения которой ждет Stop
+func (w *Watcher) markStarted() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.started = true
+}
+
+// Stop останавливает наблюдение и ждет завершения фоновой горутины
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopChan)
+	})
+
+	w.mu.RLock()
+	started := w.started
+	w.mu.RUnlock()
+	if started {
+		<-w.done
+	}
+}
+
+// pollLoop перечитывает файл с интервалом WatcherOptions.Interval
+func (w *Watcher) pollLoop(ctx context.Context) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.reloadAndLog()
+		case <-ctx.Done():
+			return
+		case <-w.stopChan:
+			return
+		}
+	}
+}
+
+// notifyLoop перечитывает файл по событиям файловой системы
+func (w *Watcher) notifyLoop(ctx context.Context, fsWatcher *fsnotify.Watcher) {
+	defer close(w.done)
+	defer fsWatcher.Close()
+
+	name := filepath.Base(w.path)
+	for {
+		select {
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return
+			}
+			base := filepath.Base(event.Name)
+			if base == name || strings.HasPrefix(base, "..") {
+				w.reloadAndLog()
+			}
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Warn("Config file watcher error: %v", err)
+		case <-ctx.Done():
+			return
+		case <-w.stopChan:
+			return
+		}
+	}
+}
+
+// reloadAndLog перечитывает конфигурацию, логируя ошибку
+func (w *Watcher) reloadAndLog() {
+	if err := w.Reload(); err != nil {
+		w.logger.Error("Failed to reload configuration from %s: %v", w.path, err)
+	}
+}
+
+// Reload перечитывает файл, применяет изменившиеся поля и вызывает обработчики OnChange.
+// При ошибке чтения или проверки конфигурации текущие значения не изменяются.
+func (w *Watcher) Reload() error {
+	values, err := ReadEnvFile(w.path)
+	if err != nil {
+		return err
+	}
+
+	loaded, err := LoadBaseConfig(WithValues(values))
+	if err != nil {
+		return err
+	}
+
+	type change struct {
+		field    string
+		old, new interface{}
+	}
+
+	w.mu.Lock()
+	var changes []change
+	current := reflect.ValueOf(&w.current).Elem()
+	next := reflect.ValueOf(loaded).Elem()
+	for i := 0; i < current.NumField(); i++ {
+		field := current.Type().Field(i).Name
+		oldValue, newValue := current.Field(i).Interface(), next.Field(i).Interface()
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+
+		if restartRequiredFields[field] {
+			if previous, ok := w.ignored[field]; !ok || !reflect.DeepEqual(previous, newValue) {
+				w.logger.Warn("Configuration field %s changed but requires a restart, ignoring", field)
+				w.ignored[field] = newValue
+			}
+			continue
+		}
+
+		current.Field(i).Set(next.Field(i))
+		changes = append(changes, change{field: field, old: oldValue, new: newValue})
+	}
+	callbacks := append([]ChangeFunc(nil), w.callbacks...)
+	w.mu.Unlock()
+
+	for _, c := range changes {
+		// Значения не логируются: среди полей есть секреты (InternalAPIKey)
+		w.logger.Info("Configuration field %s changed", c.field)
+		for _, callback := range callbacks {
+			callback(c.field, c.old, c.new)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordedChange struct {
+	field    string
+	old, new interface{}
+}
+
+func writeEnvFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func newTestWatcher(t *testing.T, interval time.Duration) (*Watcher, string, func() []recordedChange) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "service.env")
+	writeEnvFile(t, path, "LOG_LEVEL=info\nPORT=8080\n")
+
+	current, err := LoadBaseConfig(WithValues(map[string]string{"LOG_LEVEL": "info", "PORT": "8080"}))
+	if err != nil {
+		t.Fatalf("LoadBaseConfig() error = %v", err)
+	}
+
+	watcher := NewWatcher(current, path, &WatcherOptions{Interval: interval})
+
+	var mu sync.Mutex
+	var changes []recordedChange
+	watcher.OnChange(func(field string, old, new interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, recordedChange{field, old, new})
+	})
+
+	return watcher, path, func() []recordedChange {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recordedChange(nil), changes...)
+	}
+}
+
+func TestWatcherReloadAppliesHotFieldsOnly(t *testing.T) {
+	watcher, path, changes := newTestWatcher(t, 0)
+
+	writeEnvFile(t, path, "# hot reload\nexport LOG_LEVEL=\"debug\"\nPORT=9090\nRATE_LIMIT_REQUESTS=5\n")
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	got := changes()
+	if len(got) != 2 {
+		t.Fatalf("changes = %+v, want LogLevel and RateLimitRequests", got)
+	}
+	if got[0] != (recordedChange{"LogLevel", "info", "debug"}) {
+		t.Errorf("change = %+v", got[0])
+	}
+	if got[1] != (recordedChange{"RateLimitRequests", 100, 5}) {
+		t.Errorf("change = %+v", got[1])
+	}
+
+	current := watcher.Current()
+	if current.LogLevel != "debug" || current.Port != "8080" {
+		t.Errorf("current LogLevel = %q, Port = %q; Port must not be hot-applied", current.LogLevel, current.Port)
+	}
+
+	// Повторное чтение без изменений не вызывает обработчики
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(changes()) != 2 {
+		t.Errorf("changes = %+v, want no new changes", changes())
+	}
+}
+
+func TestWatcherInvalidFileKeepsCurrent(t *testing.T) {
+	watcher, path, changes := newTestWatcher(t, 0)
+
+	writeEnvFile(t, path, "LOG_LEVEL=debug\nTIMEOUT_SECONDS=abc\n")
+	if err := watcher.Reload(); err == nil {
+		t.Fatal("Reload() with invalid value must fail")
+	}
+	if watcher.Current().LogLevel != "info" || len(changes()) != 0 {
+		t.Errorf("configuration must not change on invalid file, changes = %+v", changes())
+	}
+}
+
+func TestWatcherDetectsFileChanges(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		interval time.Duration
+	}{
+		{"fsnotify", 0},
+		{"interval", 10 * time.Millisecond},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			watcher, path, changes := newTestWatcher(t, tt.interval)
+			if err := watcher.Start(context.Background()); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			defer watcher.Stop()
+
+			writeEnvFile(t, path, "LOG_LEVEL=warning\nPORT=8080\n")
+
+			deadline := time.Now().Add(5 * time.Second)
+			for time.Now().Before(deadline) && len(changes()) == 0 {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if got := changes(); len(got) == 0 || got[0].field != "LogLevel" || got[0].new != "warning" {
+				t.Errorf("changes = %+v, want LogLevel -> warning", got)
+			}
+		})
+	}
+}
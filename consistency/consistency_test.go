@@ -0,0 +1,201 @@
+package consistency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/auth"
+	"github.com/vladzorgan/common/database"
+	"github.com/vladzorgan/common/redis"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// laggingStore имитирует primary и реплику, на которую запись еще не доехала
+type laggingStore struct {
+	primary map[string]string
+	replica map[string]string
+}
+
+func newLaggingStore() *laggingStore {
+	return &laggingStore{primary: make(map[string]string), replica: make(map[string]string)}
+}
+
+func (s *laggingStore) write(key, value string) { s.primary[key] = value }
+
+// read читает с реплики, если контекст не требует primary (как database.Database.Reader)
+func (s *laggingStore) read(ctx context.Context, key string) (string, bool) {
+	source := s.replica
+	if database.HasWriteIntent(ctx) {
+		source = s.primary
+	}
+	value, ok := source[key]
+	return value, ok
+}
+
+func newTestTracker(t *testing.T, window time.Duration) (*Tracker, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client, err := redis.NewClient(server.Addr(), "", 0, nil, nil)
+	if err != nil {
+		t.Fatalf("redis.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return NewTracker(client, nil, &TrackerOptions{Window: window, KeyPrefix: "consistency"}), server
+}
+
+func userContext(id uint) context.Context {
+	return auth.WithUser(context.Background(), &auth.User{ID: id, Role: auth.UserRole_User, IsActive: true})
+}
+
+func TestGinMiddlewareReadYourWrites(t *testing.T) {
+	tracker, server := newTestTracker(t, time.Minute)
+	store := newLaggingStore()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(userContext(7))
+	}, GinMiddleware(tracker))
+	router.POST("/orders", func(c *gin.Context) {
+		store.write("order:1", "new")
+		c.Status(http.StatusCreated)
+	})
+	router.GET("/orders/1", func(c *gin.Context) {
+		if _, ok := store.read(c.Request.Context(), "order:1"); !ok {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", nil))
+	if rec.Code != http.StatusCreated || rec.Header().Get(TokenHeader) == "" {
+		t.Fatalf("POST = %d, token %q", rec.Code, rec.Header().Get(TokenHeader))
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET after write = %d, want 200 from primary", rec.Code)
+	}
+
+	// После окна отставания чтения снова идут на реплику
+	server.FastForward(2 * time.Minute)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET after window = %d, want 404 from the lagging replica", rec.Code)
+	}
+}
+
+// headerStream сохраняет заголовки ответа, выставленные через grpc.SetHeader
+type headerStream struct {
+	method string
+	header metadata.MD
+}
+
+func (s *headerStream) Method() string { return s.method }
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+func (s *headerStream) SendHeader(md metadata.MD) error { return s.SetHeader(md) }
+func (s *headerStream) SetTrailer(metadata.MD) error    { return nil }
+
+func TestUnaryServerInterceptorRecordsWrites(t *testing.T) {
+	tracker, _ := newTestTracker(t, time.Minute)
+	store := newLaggingStore()
+	interceptor := UnaryServerInterceptor(tracker)
+
+	call := func(ctx context.Context, method string, handler grpc.UnaryHandler) (interface{}, *headerStream, error) {
+		stream := &headerStream{method: method}
+		ctx = grpc.NewContextWithServerTransportStream(ctx, stream)
+		resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return resp, stream, err
+	}
+	create := func(ctx context.Context, req interface{}) (interface{}, error) {
+		store.write("city:1", "Тверь")
+		return "created", nil
+	}
+	get := func(ctx context.Context, req interface{}) (interface{}, error) {
+		value, ok := store.read(ctx, "city:1")
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return value, nil
+	}
+
+	// Чтение другого пользователя идет на отстающую реплику
+	if _, _, err := call(userContext(8), "/location.LocationService/GetCity", get); err == nil {
+		t.Fatal("read before any write must hit the replica")
+	}
+
+	_, stream, err := call(userContext(7), "/location.LocationService/CreateCity", create)
+	if err != nil {
+		t.Fatalf("CreateCity error = %v", err)
+	}
+	if len(stream.header.Get(TokenMetadataKey)) != 1 {
+		t.Errorf("response header = %v, want consistency token", stream.header)
+	}
+
+	if resp, _, err := call(userContext(7), "/location.LocationService/GetCity", get); err != nil || resp != "Тверь" {
+		t.Errorf("GetCity after write = %v, %v; want the value from primary", resp, err)
+	}
+	if _, _, err := call(userContext(8), "/location.LocationService/GetCity", get); err == nil {
+		t.Error("other users must keep reading from the replica")
+	}
+
+	// Токен в метаданных направляет чтение на primary и без записи в трекере
+	ctx := metadata.NewIncomingContext(userContext(9), metadata.Pairs(TokenMetadataKey, NewToken(time.Now())))
+	if _, _, err := call(ctx, "/location.LocationService/GetCity", get); err != nil {
+		t.Errorf("GetCity with token error = %v", err)
+	}
+}
+
+func TestUnaryServerInterceptorSkipsFailedWrites(t *testing.T) {
+	tracker, _ := newTestTracker(t, time.Minute)
+	interceptor := UnaryServerInterceptor(tracker)
+
+	failing := func(context.Context, interface{}) (interface{}, error) { return nil, errors.New("invalid") }
+	info := &grpc.UnaryServerInfo{FullMethod: "/location.LocationService/UpdateCity"}
+	if _, err := interceptor(userContext(7), nil, info, failing); err == nil {
+		t.Fatal("interceptor swallowed the handler error")
+	}
+	if tracker.RequiresPrimary(context.Background(), "7", "") {
+		t.Error("failed write was recorded")
+	}
+}
+
+func TestRequiresPrimaryWhenRedisDown(t *testing.T) {
+	tracker, server := newTestTracker(t, time.Minute)
+	server.Close()
+
+	if !tracker.RequiresPrimary(context.Background(), "7", "") {
+		t.Error("RequiresPrimary() = false with Redis down, want true")
+	}
+	if !database.HasWriteIntent(tracker.Context(userContext(7), "orders")) {
+		t.Error("Context() must route to primary with Redis down")
+	}
+}
+
+func TestIsMutatingMethod(t *testing.T) {
+	for method, want := range map[string]bool{
+		"/location.LocationService/CreateCity":   true,
+		"/location.LocationService/DeleteRegion": true,
+		"/location.LocationService/GetCities":    false,
+		"/location.LocationService/ListRegions":  false,
+	} {
+		if got := IsMutatingMethod(method); got != want {
+			t.Errorf("IsMutatingMethod(%s) = %v, want %v", method, got, want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package consistency
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/database"
+	"github.com/vladzorgan/common/killswitch"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// GinMiddleware возвращает middleware, которое переносит токен согласованности между запросами.
+// Мутирующие запросы получают новый токен в ответе и фиксируются в трекере,
+// а чтения со свежим токеном или недавней записью пользователя направляются на primary.
+// Отключается выключателем killswitch.FeatureConsistency.
+func GinMiddleware(tracker *Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if killswitch.IsDisabled(killswitch.FeatureConsistency) {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		subject := SubjectFromContext(ctx)
+
+		if tracker.TokenRequiresPrimary(c.GetHeader(TokenHeader)) ||
+			tracker.RequiresPrimary(ctx, subject, "") {
+			c.Request = c.Request.WithContext(database.WithWriteIntent(ctx))
+		}
+
+		mutating := isMutatingMethod(c.Request.Method)
+		if mutating {
+			// Заголовок выставляется до обработчика, так как после записи тела он уже отправлен
+			c.Header(TokenHeader, NewToken(time.Now()))
+		}
+
+		c.Next()
+
+		if mutating && c.Writer.Status() < http.StatusBadRequest {
+			if err := tracker.RecordWrite(c.Request.Context(), subject, c.FullPath()); err != nil {
+				tracker.logger.Warn("Failed to record write: %v", err)
+			}
+		}
+	}
+}
+
+// UnaryServerInterceptor возвращает интерцептор, направляющий чтения на primary
+// при наличии свежего токена в метаданных или недавней записи пользователя.
+// Мутирующие методы (см. IsMutatingMethod) получают новый токен в заголовках ответа
+// и после успешного выполнения фиксируются в трекере, как в GinMiddleware.
+// Отключается выключателем killswitch.FeatureConsistency.
+func UnaryServerInterceptor(tracker *Tracker) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if killswitch.IsDisabled(killswitch.FeatureConsistency) {
+			return handler(ctx, req)
+		}
+
+		token := ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(TokenMetadataKey); len(values) > 0 {
+				token = values[0]
+			}
+		}
+
+		subject := SubjectFromContext(ctx)
+		if tracker.TokenRequiresPrimary(token) ||
+			tracker.RequiresPrimary(ctx, subject, "") {
+			ctx = database.WithWriteIntent(ctx)
+		}
+
+		mutating := IsMutatingMethod(info.FullMethod)
+		if mutating {
+			// Заголовки отправляются вместе с ответом, поэтому токен выставляется до обработчика
+			if err := SetTokenHeader(ctx); err != nil {
+				tracker.logger.Warn("Failed to set consistency token header: %v", err)
+			}
+		}
+
+		resp, err := handler(ctx, req)
+
+		if mutating && err == nil {
+			if recordErr := tracker.RecordWrite(ctx, subject, info.FullMethod); recordErr != nil {
+				tracker.logger.Warn("Failed to record write: %v", recordErr)
+			}
+		}
+
+		return resp, err
+	}
+}
+
+// mutatingMethodPrefixes префиксы имен gRPC-методов, изменяющих данные
+var mutatingMethodPrefixes = []string{"Create", "Update", "Delete", "Patch", "Upsert", "Remove", "Add", "Set"}
+
+// IsMutatingMethod проверяет по имени gRPC-метода (например, "/location.LocationService/CreateCity"),
+// изменяет ли он данные
+func IsMutatingMethod(fullMethod string) bool {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	for _, prefix := range mutatingMethodPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTokenHeader отправляет клиенту токен согласованности в заголовках gRPC-ответа.
+// Вызывается обработчиками мутаций.
+func SetTokenHeader(ctx context.Context) error {
+	return grpc.SetHeader(ctx, metadata.Pairs(TokenMetadataKey, NewToken(time.Now())))
+}
+
+// WithOutgoingToken добавляет токен согласованности в исходящие метаданные
+func WithOutgoingToken(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, TokenMetadataKey, token)
+}
+
+// isMutatingMethod проверяет, изменяет ли HTTP-метод данные
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,42 @@
+package consistency
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Имена заголовка и метаданных для передачи токена согласованности
+const (
+	TokenHeader      = "X-Consistency-Token"
+	TokenMetadataKey = "x-consistency-token"
+)
+
+// NewToken создает токен согласованности для момента записи.
+// Токен не подписывается: его подделка лишь направляет чтения на primary.
+func NewToken(writtenAt time.Time) string {
+	return strconv.FormatInt(writtenAt.UnixMilli(), 10)
+}
+
+// ParseToken разбирает токен согласованности
+func ParseToken(token string) (time.Time, error) {
+	writtenAt, err := strconv.ParseInt(token, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid consistency token: %v", err)
+	}
+	return time.UnixMilli(writtenAt), nil
+}
+
+// TokenRequiresPrimary проверяет, требует ли токен чтения с primary
+func (t *Tracker) TokenRequiresPrimary(token string) bool {
+	if token == "" {
+		return false
+	}
+
+	writtenAt, err := ParseToken(token)
+	if err != nil {
+		return false
+	}
+
+	return t.isFresh(writtenAt)
+}
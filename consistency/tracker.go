@@ -0,0 +1,150 @@
+// Package consistency предоставляет помощники read-your-writes: после мутации чтения
+// того же пользователя направляются на primary в течение окна отставания реплик
+package consistency
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/vladzorgan/common/auth"
+	"github.com/vladzorgan/common/database"
+	"github.com/vladzorgan/common/killswitch"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/redis"
+)
+
+// TrackerOptions содержит опции трекера записей
+type TrackerOptions struct {
+	// Окно отставания реплик, в течение которого чтения идут на primary
+	Window time.Duration
+	// Префикс ключей в Redis
+	KeyPrefix string
+}
+
+// DefaultTrackerOptions возвращает опции по умолчанию
+func DefaultTrackerOptions() *TrackerOptions {
+	return &TrackerOptions{
+		Window:    5 * time.Second,
+		KeyPrefix: "consistency",
+	}
+}
+
+// Tracker хранит время последних записей пользователя по сущностям в Redis
+type Tracker struct {
+	client  *redis.Client
+	options *TrackerOptions
+	logger  logging.Logger
+}
+
+// NewTracker создает новый трекер записей
+func NewTracker(client *redis.Client, logger logging.Logger, options *TrackerOptions) *Tracker {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	if options == nil {
+		options = DefaultTrackerOptions()
+	}
+
+	return &Tracker{
+		client:  client,
+		options: options,
+		logger:  logger,
+	}
+}
+
+// Window возвращает окно отставания реплик
+func (t *Tracker) Window() time.Duration {
+	return t.options.Window
+}
+
+// SubjectFromContext возвращает идентификатор пользователя из авторизационного контекста
+func SubjectFromContext(ctx context.Context) string {
+	userID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(userID), 10)
+}
+
+// RecordWrite фиксирует запись сущности пользователем
+func (t *Tracker) RecordWrite(ctx context.Context, subject, entity string) error {
+	if subject == "" {
+		return nil
+	}
+
+	key := t.key(subject)
+	pipe := t.client.Client().TxPipeline()
+	pipe.HSet(ctx, key, entity, time.Now().UnixMilli())
+	pipe.PExpire(ctx, key, t.options.Window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record write in Redis: %v", err)
+	}
+
+	return nil
+}
+
+// RequiresPrimary проверяет, была ли запись сущности в пределах окна.
+// Пустая сущность означает любую сущность. При недоступности Redis возвращает true.
+func (t *Tracker) RequiresPrimary(ctx context.Context, subject, entity string) bool {
+	if subject == "" {
+		return false
+	}
+
+	var timestamps []string
+	if entity == "" {
+		values, err := t.client.Client().HVals(ctx, t.key(subject)).Result()
+		if err != nil {
+			t.logger.Warn("Consistency tracker unavailable, routing to primary: %v", err)
+			return true
+		}
+		timestamps = values
+	} else {
+		value, err := t.client.Client().HGet(ctx, t.key(subject), entity).Result()
+		if err == goredis.Nil {
+			return false
+		}
+		if err != nil {
+			t.logger.Warn("Consistency tracker unavailable, routing to primary: %v", err)
+			return true
+		}
+		timestamps = []string{value}
+	}
+
+	for _, value := range timestamps {
+		writtenAt, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		if t.isFresh(time.UnixMilli(writtenAt)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Context возвращает контекст с намерением записи, если чтение должно идти на primary.
+// Отключается выключателем killswitch.FeatureConsistency.
+func (t *Tracker) Context(ctx context.Context, entity string) context.Context {
+	if killswitch.IsDisabled(killswitch.FeatureConsistency) {
+		return ctx
+	}
+	if t.RequiresPrimary(ctx, SubjectFromContext(ctx), entity) {
+		return database.WithWriteIntent(ctx)
+	}
+	return ctx
+}
+
+// isFresh проверяет, попадает ли время записи в окно отставания
+func (t *Tracker) isFresh(writtenAt time.Time) bool {
+	return time.Since(writtenAt) < t.options.Window
+}
+
+// key формирует ключ Redis для пользователя
+func (t *Tracker) key(subject string) string {
+	return t.options.KeyPrefix + ":" + subject
+}
@@ -0,0 +1,28 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm/clause"
+)
+
+// ArchiveTableName возвращает имя архивной таблицы для указанной таблицы
+func ArchiveTableName(table string) string {
+	return table + "_archive"
+}
+
+// EnsureArchiveTable создает архивную таблицу с той же схемой, что и исходная, если она не существует
+func (d *Database) EnsureArchiveTable(ctx context.Context, table string) (string, error) {
+	archiveTable := ArchiveTableName(table)
+
+	if err := d.db.WithContext(ctx).Exec(
+		"CREATE TABLE IF NOT EXISTS ? (LIKE ? INCLUDING ALL)",
+		clause.Table{Name: archiveTable},
+		clause.Table{Name: table},
+	).Error; err != nil {
+		return "", fmt.Errorf("failed to create archive table %s: %v", archiveTable, err)
+	}
+
+	return archiveTable, nil
+}
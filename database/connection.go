@@ -2,10 +2,14 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/tracing"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	goormlogger "gorm.io/gorm/logger"
@@ -14,8 +18,12 @@ import (
 
 // Database представляет соединение с базой данных
 type Database struct {
-	db     *gorm.DB
-	logger logging.Logger
+	db              *gorm.DB
+	logger          logging.Logger
+	sessionSettings SessionSettingsFunc
+	// Реплики для чтения (см. NewDatabaseWithReplicas и Reader)
+	replicas    []*gorm.DB
+	nextReplica *atomic.Uint64
 }
 
 // DatabaseOptions содержит опции для создания соединения с базой данных
@@ -30,6 +38,10 @@ type DatabaseOptions struct {
 	MaxOpenConns int
 	// Максимальное время жизни соединения
 	ConnMaxLifetime time.Duration
+	// Настройки сессии, применяемые через SET LOCAL в начале транзакций
+	SessionSettings SessionSettingsFunc
+	// Применять настройки сессии в неявных транзакциях GORM (create/update/delete)
+	SessionSettingsCallback bool
 }
 
 // DefaultDatabaseOptions возвращает опции по умолчанию
@@ -53,12 +65,66 @@ func NewDatabase(databaseURL string, logger logging.Logger, options *DatabaseOpt
 		options = DefaultDatabaseOptions()
 	}
 
+	db, err := open(databaseURL, options)
+	if err != nil {
+		return nil, err
+	}
+
+	database := &Database{
+		db:              db,
+		logger:          logger,
+		sessionSettings: options.SessionSettings,
+		nextReplica:     new(atomic.Uint64),
+	}
+
+	// Регистрируем callback для неявных транзакций
+	if options.SessionSettingsCallback {
+		if err := database.registerSessionSettingsCallback(); err != nil {
+			return nil, fmt.Errorf("failed to register session settings callback: %v", err)
+		}
+	}
+
+	logger.Info("Successfully connected to database")
+
+	return database, nil
+}
+
+// NewDatabaseWithReplicas создает соединение с primary и репликами для чтения.
+// Репозитории направляют чтения на реплики (см. Reader), а записи и чтения
+// в контексте ForcePrimary - на primary.
+func NewDatabaseWithReplicas(primaryURL string, replicaURLs []string, logger logging.Logger, options *DatabaseOptions) (*Database, error) {
+	if options == nil {
+		options = DefaultDatabaseOptions()
+	}
+
+	database, err := NewDatabase(primaryURL, logger, options)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, replicaURL := range replicaURLs {
+		replica, err := open(replicaURL, options)
+		if err != nil {
+			database.Close()
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		database.replicas = append(database.replicas, replica)
+	}
+
+	database.logger.Info("Successfully connected to %d database replicas", len(database.replicas))
+	return database, nil
+}
+
+// open подключается к базе данных и настраивает пул соединений и трассировку
+func open(databaseURL string, options *DatabaseOptions) (*gorm.DB, error) {
 	// Настраиваем конфигурацию GORM
 	config := &gorm.Config{
 		Logger: goormlogger.Default.LogMode(options.LogLevel),
 		NamingStrategy: schema.NamingStrategy{
 			SingularTable: options.SingularTable,
 		},
+		// Преобразуем ошибки драйвера в gorm.ErrDuplicatedKey и т.п.
+		TranslateError: true,
 	}
 
 	// Подключаемся к базе данных
@@ -78,12 +144,14 @@ func NewDatabase(databaseURL string, logger logging.Logger, options *DatabaseOpt
 	sqlDB.SetMaxOpenConns(options.MaxOpenConns)
 	sqlDB.SetConnMaxLifetime(options.ConnMaxLifetime)
 
-	logger.Info("Successfully connected to database")
+	// Подключаем трассировку запросов
+	if tracing.Enabled() {
+		if err := db.Use(tracing.NewGormPlugin()); err != nil {
+			return nil, fmt.Errorf("failed to register tracing plugin: %v", err)
+		}
+	}
 
-	return &Database{
-		db:     db,
-		logger: logger,
-	}, nil
+	return db, nil
 }
 
 // GetDB возвращает экземпляр GORM DB
@@ -91,8 +159,14 @@ func (db *Database) GetDB() *gorm.DB {
 	return db.db
 }
 
-// Close закрывает соединение с базой данных
+// Close закрывает соединения с базой данных и репликами
 func (d *Database) Close() error {
+	for _, replica := range d.replicas {
+		if sqlDB, err := replica.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+
 	sqlDB, err := d.db.DB()
 	if err != nil {
 		return fmt.Errorf("failed to get database connection: %v", err)
@@ -120,6 +194,15 @@ func (d *Database) Ping() error {
 	return nil
 }
 
+// Stats возвращает статистику пула соединений (для metrics.AttachDatabase)
+func (d *Database) Stats() sql.DBStats {
+	sqlDB, err := d.db.DB()
+	if err != nil {
+		return sql.DBStats{}
+	}
+	return sqlDB.Stats()
+}
+
 // AutoMigrate выполняет автоматическую миграцию моделей
 func (d *Database) AutoMigrate(models ...interface{}) error {
 	if err := d.db.AutoMigrate(models...); err != nil {
@@ -132,12 +215,25 @@ func (d *Database) AutoMigrate(models ...interface{}) error {
 // WithLogger возвращает новый экземпляр Database с указанным логгером
 func (d *Database) WithLogger(logger logging.Logger) *Database {
 	return &Database{
-		db:     d.db.Session(&gorm.Session{}),
-		logger: logger,
+		db:              d.db.Session(&gorm.Session{}),
+		logger:          logger,
+		sessionSettings: d.sessionSettings,
+		replicas:        d.replicas,
+		nextReplica:     d.nextReplica,
 	}
 }
 
 // Transaction выполняет функцию в транзакции
 func (d *Database) Transaction(txFunc func(tx *gorm.DB) error) error {
-	return d.db.Transaction(txFunc)
+	return d.TransactionContext(context.Background(), txFunc)
+}
+
+// TransactionContext выполняет функцию в транзакции с указанным контекстом
+func (d *Database) TransactionContext(ctx context.Context, txFunc func(tx *gorm.DB) error) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := d.applySessionSettings(ctx, tx); err != nil {
+			return err
+		}
+		return txFunc(tx)
+	})
 }
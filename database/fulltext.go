@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DefaultFullTextConfig конфигурация текстового поиска Postgres по умолчанию
+const DefaultFullTextConfig = "simple"
+
+// FullTextVector возвращает выражение to_tsvector для колонки. Одно и то же выражение
+// используется в индексе и в запросах, иначе Postgres не применит GIN индекс.
+func FullTextVector(config, column string) string {
+	return fmt.Sprintf("to_tsvector(%s, %s)", quoteLiteral(config), quoteIdentifier(column))
+}
+
+// FullTextQuery возвращает выражение plainto_tsquery с плейсхолдером для ключевого слова
+func FullTextQuery(config string) string {
+	return fmt.Sprintf("plainto_tsquery(%s, ?)", quoteLiteral(config))
+}
+
+// FullTextIndexName возвращает имя GIN индекса полнотекстового поиска
+func FullTextIndexName(table, column string) string {
+	return strings.ReplaceAll(fmt.Sprintf("idx_%s_%s_fts", table, column), ".", "_")
+}
+
+// EnsureFullTextIndex создает GIN индекс по to_tsvector(config, column), если он не существует.
+// Индекс строится с CONCURRENTLY, поэтому функцию нельзя вызывать внутри транзакции.
+func (d *Database) EnsureFullTextIndex(ctx context.Context, table, column, config string) (string, error) {
+	if config == "" {
+		config = DefaultFullTextConfig
+	}
+	index := FullTextIndexName(table, column)
+
+	if err := d.db.WithContext(ctx).Exec(fmt.Sprintf(
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON %s USING GIN (%s)",
+		quoteIdentifier(index),
+		quoteIdentifier(table),
+		FullTextVector(config, column),
+	)).Error; err != nil {
+		return "", fmt.Errorf("failed to create full-text index %s: %v", index, err)
+	}
+
+	return index, nil
+}
+
+// quoteIdentifier экранирует идентификатор, в том числе составной вида schema.table
+func quoteIdentifier(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteLiteral экранирует строковую константу
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
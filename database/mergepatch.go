@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// mergePatchFunctionSQL создает функцию jsonb_merge_patch(target, patch), реализующую
+// JSON Merge Patch (RFC 7396): объекты объединяются рекурсивно, null удаляет ключ,
+// остальные значения (в том числе массивы) заменяют текущее
+const mergePatchFunctionSQL = `
+CREATE OR REPLACE FUNCTION jsonb_merge_patch(target jsonb, patch jsonb) RETURNS jsonb AS $$
+DECLARE
+	patch_key text;
+	patch_value jsonb;
+	result jsonb;
+BEGIN
+	IF patch IS NULL OR jsonb_typeof(patch) <> 'object' THEN
+		RETURN patch;
+	END IF;
+
+	IF target IS NULL OR jsonb_typeof(target) <> 'object' THEN
+		result := '{}'::jsonb;
+	ELSE
+		result := target;
+	END IF;
+
+	FOR patch_key, patch_value IN SELECT * FROM jsonb_each(patch) LOOP
+		IF jsonb_typeof(patch_value) = 'null' THEN
+			result := result - patch_key;
+		ELSE
+			result := jsonb_set(result, ARRAY[patch_key], jsonb_merge_patch(result -> patch_key, patch_value));
+		END IF;
+	END LOOP;
+
+	RETURN result;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE`
+
+// EnsureMergePatchFunction создает или обновляет функцию jsonb_merge_patch,
+// которую использует merge patch обновление JSON колонок (service.PatchField.JSON)
+func (d *Database) EnsureMergePatchFunction(ctx context.Context) error {
+	if err := d.db.WithContext(ctx).Exec(mergePatchFunctionSQL).Error; err != nil {
+		return fmt.Errorf("failed to create jsonb_merge_patch function: %v", err)
+	}
+	return nil
+}
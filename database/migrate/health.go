@@ -0,0 +1,48 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vladzorgan/common/health"
+)
+
+// HealthComponent сообщает о непримененных миграциях: статус Degraded, пока они есть.
+// Позволяет заметить забытые миграции до того, как на экземпляр придет трафик.
+type HealthComponent struct {
+	name     string
+	migrator *Migrator
+}
+
+var _ health.Component = (*HealthComponent)(nil)
+
+// NewHealthComponent создает компонент проверки миграций
+func NewHealthComponent(name string, migrator *Migrator) *HealthComponent {
+	return &HealthComponent{
+		name:     name,
+		migrator: migrator,
+	}
+}
+
+// Name возвращает имя компонента
+func (c *HealthComponent) Name() string {
+	return c.name
+}
+
+// Check проверяет наличие непримененных миграций
+func (c *HealthComponent) Check(ctx context.Context) (health.Status, error) {
+	pending, err := c.migrator.Pending(ctx)
+	if err != nil {
+		return health.StatusDown, err
+	}
+
+	if len(pending) > 0 {
+		return health.StatusDegraded, fmt.Errorf("%d pending migrations, first %d_%s", len(pending), pending[0].Version, pending[0].Name)
+	}
+	return health.StatusUp, nil
+}
+
+// IsCritical возвращает false: непримененные миграции не делают сервис недоступным
+func (c *HealthComponent) IsCritical() bool {
+	return false
+}
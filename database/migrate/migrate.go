@@ -0,0 +1,399 @@
+// Package migrate применяет версионированные SQL миграции из файловой системы (например, embed.FS).
+//
+// Миграции задаются парами файлов NNNN_name.up.sql и NNNN_name.down.sql, где NNNN - номер версии.
+// Примененные версии хранятся в таблице schema_migrations. Каждая миграция выполняется в отдельной
+// транзакции, а одновременный запуск на нескольких репликах сервиса исключается advisory-блокировкой
+// PostgreSQL. Команды, которые нельзя выполнить в транзакции (CREATE INDEX CONCURRENTLY),
+// в миграциях не поддерживаются.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/vladzorgan/common/logging"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultTable таблица примененных миграций по умолчанию
+const DefaultTable = "schema_migrations"
+
+// ErrNoDownMigration возвращается при откате миграции без файла .down.sql
+var ErrNoDownMigration = errors.New("down migration not found")
+
+// filePattern соответствует именам файлов миграций NNNN_name.up.sql и NNNN_name.down.sql
+var filePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration описывает миграцию из файлов
+type Migration struct {
+	Version uint64
+	Name    string
+	// Пути файлов миграции в файловой системе; DownFile пуст, если откат не предусмотрен
+	UpFile   string
+	DownFile string
+}
+
+// MigrationStatus описывает состояние миграции
+type MigrationStatus struct {
+	Version   uint64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	// Версия применена, но ее файлов больше нет
+	Missing bool
+}
+
+// Options содержит настройки Migrator
+type Options struct {
+	// Таблица примененных миграций (по умолчанию DefaultTable)
+	Table string
+	// Ключ advisory-блокировки (0 - вычисляется из имени таблицы)
+	LockKey int64
+	// Логгер
+	Logger logging.Logger
+}
+
+// appliedMigration строка таблицы примененных миграций
+type appliedMigration struct {
+	Version   uint64
+	Name      string
+	AppliedAt time.Time
+}
+
+// Migrator применяет и откатывает миграции
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	fsys       fs.FS
+	table      string
+	lockKey    int64
+	logger     logging.Logger
+}
+
+// New создает Migrator для миграций из корня fsys (для подкаталога используйте fs.Sub).
+// Возвращает ошибку для некорректных имен файлов, повторяющихся версий и миграций без .up.sql.
+func New(db *gorm.DB, fsys fs.FS, options *Options) (*Migrator, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	table := options.Table
+	if table == "" {
+		table = DefaultTable
+	}
+
+	lockKey := options.LockKey
+	if lockKey == 0 {
+		hash := fnv.New64a()
+		hash.Write([]byte("migrate:" + table))
+		lockKey = int64(hash.Sum64())
+	}
+
+	logger := options.Logger
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+		fsys:       fsys,
+		table:      table,
+		lockKey:    lockKey,
+		logger:     logger,
+	}, nil
+}
+
+// Run применяет все непримененные миграции из fsys с настройками по умолчанию
+func Run(ctx context.Context, db *gorm.DB, fsys fs.FS) error {
+	migrator, err := New(db, fsys, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = migrator.Up(ctx)
+	return err
+}
+
+// Load читает список миграций из корня fsys, упорядоченный по версии
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %v", err)
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		match := filePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s, expected NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %v", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, migration.Name, match[2])
+		}
+
+		file := &migration.UpFile
+		if match[3] == "down" {
+			file = &migration.DownFile
+		}
+		if *file != "" {
+			return nil, fmt.Errorf("duplicate migration file %s", entry.Name())
+		}
+		*file = entry.Name()
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.UpFile == "" {
+			return nil, fmt.Errorf("migration %d_%s has no .up.sql file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Up применяет все непримененные миграции по возрастанию версии и возвращает их количество.
+// Миграции с версией меньше последней примененной (например, после слияния веток) тоже применяются.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		done, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		pending := pendingMigrations(m.migrations, done)
+		for _, migration := range pending {
+			if len(done) > 0 && migration.Version < done[len(done)-1].Version {
+				m.logger.Warn("Applying migration %d_%s out of order", migration.Version, migration.Name)
+			}
+
+			if err := m.apply(conn, migration, true); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down откатывает n последних примененных миграций
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	return m.withLock(ctx, func(conn *gorm.DB) error {
+		done, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		byVersion := make(map[uint64]Migration, len(m.migrations))
+		for _, migration := range m.migrations {
+			byVersion[migration.Version] = migration
+		}
+
+		// Проверяем наличие всех файлов отката до изменения схемы
+		rollback := make([]Migration, 0, n)
+		for i := len(done) - 1; i >= 0 && len(rollback) < n; i-- {
+			migration, ok := byVersion[done[i].Version]
+			if !ok || migration.DownFile == "" {
+				return fmt.Errorf("migration %d_%s: %w", done[i].Version, done[i].Name, ErrNoDownMigration)
+			}
+			rollback = append(rollback, migration)
+		}
+
+		for _, migration := range rollback {
+			if err := m.apply(conn, migration, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Status возвращает состояние всех миграций по возрастанию версии
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	done, err := m.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return migrationStatus(m.migrations, done), nil
+}
+
+// Pending возвращает непримененные миграции
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	done, err := m.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pendingMigrations(m.migrations, done), nil
+}
+
+// withLock выполняет fn на выделенном соединении под advisory-блокировкой.
+// Блокировка сессионная, поэтому захват, миграции и освобождение идут через одно соединение.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", m.lockKey).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %v", err)
+		}
+		defer func() {
+			// Контекст мог быть отменен, а блокировку нужно снять до возврата соединения в пул
+			if err := conn.WithContext(context.Background()).Exec("SELECT pg_advisory_unlock(?)", m.lockKey).Error; err != nil {
+				m.logger.Error("Failed to release migration lock: %v", err)
+			}
+		}()
+
+		if err := m.ensureTable(conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+// ensureTable создает таблицу примененных миграций
+func (m *Migrator) ensureTable(conn *gorm.DB) error {
+	err := conn.Exec(
+		"CREATE TABLE IF NOT EXISTS ? (version BIGINT PRIMARY KEY, name TEXT NOT NULL, applied_at TIMESTAMPTZ NOT NULL DEFAULT now())",
+		clause.Table{Name: m.table},
+	).Error
+	if err != nil {
+		return fmt.Errorf("failed to create table %s: %v", m.table, err)
+	}
+	return nil
+}
+
+// current возвращает примененные миграции без блокировки и изменения схемы:
+// если таблицы миграций еще нет, примененных миграций нет
+func (m *Migrator) current(ctx context.Context) ([]appliedMigration, error) {
+	conn := m.db.WithContext(ctx)
+
+	var exists bool
+	if err := conn.Raw("SELECT to_regclass(?) IS NOT NULL", m.table).Scan(&exists).Error; err != nil {
+		return nil, fmt.Errorf("failed to check table %s: %v", m.table, err)
+	}
+	if !exists {
+		return nil, nil
+	}
+	return m.applied(conn)
+}
+
+// applied возвращает примененные миграции по возрастанию версии
+func (m *Migrator) applied(conn *gorm.DB) ([]appliedMigration, error) {
+	var done []appliedMigration
+	if err := conn.Table(m.table).Order("version").Find(&done).Error; err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", m.table, err)
+	}
+	return done, nil
+}
+
+// apply выполняет миграцию (up) или ее откат (down) вместе с изменением таблицы миграций в одной транзакции
+func (m *Migrator) apply(conn *gorm.DB, migration Migration, up bool) error {
+	file, direction := migration.UpFile, "up"
+	if !up {
+		file, direction = migration.DownFile, "down"
+	}
+
+	script, err := fs.ReadFile(m.fsys, file)
+	if err != nil {
+		return fmt.Errorf("failed to read migration %s: %v", file, err)
+	}
+
+	start := time.Now()
+	err = conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(string(script)).Error; err != nil {
+			return err
+		}
+
+		if up {
+			return tx.Exec("INSERT INTO ? (version, name) VALUES (?, ?)", clause.Table{Name: m.table}, migration.Version, migration.Name).Error
+		}
+		return tx.Exec("DELETE FROM ? WHERE version = ?", clause.Table{Name: m.table}, migration.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d_%s %s failed: %v", migration.Version, migration.Name, direction, err)
+	}
+
+	m.logger.Info("Migration %d_%s %s applied in %v", migration.Version, migration.Name, direction, time.Since(start))
+	return nil
+}
+
+// pendingMigrations возвращает миграции, версий которых нет среди примененных
+func pendingMigrations(migrations []Migration, done []appliedMigration) []Migration {
+	applied := make(map[uint64]bool, len(done))
+	for _, migration := range done {
+		applied[migration.Version] = true
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if !applied[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending
+}
+
+// migrationStatus объединяет миграции из файлов и примененные миграции
+func migrationStatus(migrations []Migration, done []appliedMigration) []MigrationStatus {
+	statuses := make(map[uint64]*MigrationStatus, len(migrations)+len(done))
+	for _, migration := range migrations {
+		statuses[migration.Version] = &MigrationStatus{Version: migration.Version, Name: migration.Name}
+	}
+	for _, migration := range done {
+		status, ok := statuses[migration.Version]
+		if !ok {
+			status = &MigrationStatus{Version: migration.Version, Name: migration.Name, Missing: true}
+			statuses[migration.Version] = status
+		}
+		appliedAt := migration.AppliedAt
+		status.Applied = true
+		status.AppliedAt = &appliedAt
+	}
+
+	result := make([]MigrationStatus, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, *status)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result
+}
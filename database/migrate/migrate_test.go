@@ -0,0 +1,92 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func migrationsFS(names ...string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for _, name := range names {
+		fsys[name] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+	}
+	return fsys
+}
+
+func TestLoadOrdersMigrations(t *testing.T) {
+	fsys := migrationsFS(
+		"0010_add_slug.up.sql",
+		"0002_create_cities.up.sql",
+		"0002_create_cities.down.sql",
+		"0001_init.up.sql",
+		"README.md",
+	)
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	var got []string
+	for _, migration := range migrations {
+		got = append(got, migration.UpFile+"|"+migration.DownFile)
+	}
+	want := "0001_init.up.sql||0002_create_cities.up.sql|0002_create_cities.down.sql|0010_add_slug.up.sql|"
+	if strings.Join(got, "|") != want {
+		t.Errorf("Load() = %v", got)
+	}
+}
+
+func TestLoadRejectsInvalidMigrations(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"invalid name":      migrationsFS("init.sql"),
+		"duplicate version": migrationsFS("0001_init.up.sql", "0001_other.up.sql"),
+		"missing up":        migrationsFS("0001_init.down.sql"),
+	}
+
+	for name, fsys := range tests {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: Load() must fail", name)
+		}
+	}
+}
+
+func TestPendingAndStatus(t *testing.T) {
+	migrations, err := Load(migrationsFS("0001_init.up.sql", "0002_cities.up.sql", "0003_slug.up.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appliedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	done := []appliedMigration{
+		{Version: 1, Name: "init", AppliedAt: appliedAt},
+		{Version: 3, Name: "slug", AppliedAt: appliedAt},
+		{Version: 7, Name: "removed", AppliedAt: appliedAt},
+	}
+
+	pending := pendingMigrations(migrations, done)
+	if len(pending) != 1 || pending[0].Version != 2 {
+		t.Errorf("pendingMigrations() = %+v, want version 2", pending)
+	}
+
+	statuses := migrationStatus(migrations, done)
+	if len(statuses) != 4 {
+		t.Fatalf("migrationStatus() = %+v", statuses)
+	}
+	for i, want := range []MigrationStatus{
+		{Version: 1, Name: "init", Applied: true},
+		{Version: 2, Name: "cities"},
+		{Version: 3, Name: "slug", Applied: true},
+		{Version: 7, Name: "removed", Applied: true, Missing: true},
+	} {
+		got := statuses[i]
+		if got.Version != want.Version || got.Name != want.Name || got.Applied != want.Applied || got.Missing != want.Missing {
+			t.Errorf("status[%d] = %+v, want %+v", i, got, want)
+		}
+		if got.Applied != (got.AppliedAt != nil) {
+			t.Errorf("status[%d] AppliedAt = %v", i, got.AppliedAt)
+		}
+	}
+}
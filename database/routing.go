@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Ключ для хранения признака намерения записи в контексте
+type routingContextKey string
+
+const writeIntentContextKey routingContextKey = "write_intent"
+
+// WithWriteIntent помечает контекст как требующий чтения с primary.
+// Используется для read-your-writes после мутаций, пока реплики могут отставать.
+func WithWriteIntent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, writeIntentContextKey, true)
+}
+
+// ForcePrimary направляет чтения в контексте на primary (то же, что WithWriteIntent).
+// Используется, когда нужно прочитать только что записанные данные.
+func ForcePrimary(ctx context.Context) context.Context {
+	return WithWriteIntent(ctx)
+}
+
+// HasWriteIntent проверяет, требует ли контекст чтения с primary
+func HasWriteIntent(ctx context.Context) bool {
+	writeIntent, ok := ctx.Value(writeIntentContextKey).(bool)
+	return ok && writeIntent
+}
+
+// Reader возвращает соединение для чтения: реплику (по кругу), если реплики настроены,
+// или primary, если реплик нет, контекст помечен ForcePrimary или в нем открыта транзакция
+func (d *Database) Reader(ctx context.Context) *gorm.DB {
+	if len(d.replicas) == 0 || HasWriteIntent(ctx) {
+		return d.db
+	}
+	if _, ok := TransactionFromContext(ctx); ok {
+		return d.db
+	}
+
+	next := d.nextReplica.Add(1) - 1
+	return d.replicas[next%uint64(len(d.replicas))]
+}
+
+// Replicas возвращает соединения с репликами (например, для проверки здоровья)
+func (d *Database) Replicas() []*gorm.DB {
+	return d.replicas
+}
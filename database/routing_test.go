@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return db
+}
+
+func TestReaderRoutesToReplicas(t *testing.T) {
+	primary, first, second := newDryRunDB(t), newDryRunDB(t), newDryRunDB(t)
+	db := &Database{db: primary, replicas: []*gorm.DB{first, second}, nextReplica: new(atomic.Uint64)}
+	ctx := context.Background()
+
+	if got := db.Reader(ctx); got != first {
+		t.Error("first read must go to the first replica")
+	}
+	if got := db.Reader(ctx); got != second {
+		t.Error("reads must be distributed across replicas")
+	}
+	if got := db.Reader(ctx); got != first {
+		t.Error("replica selection must wrap around")
+	}
+
+	if got := db.Reader(ForcePrimary(ctx)); got != primary {
+		t.Error("ForcePrimary must route reads to primary")
+	}
+	if got := db.Reader(WithTransaction(ctx, primary)); got != primary {
+		t.Error("reads inside a transaction must go to primary")
+	}
+
+	// Копия с другим логгером использует те же реплики
+	if got := db.WithLogger(nil).Reader(ctx); got != second {
+		t.Error("WithLogger must keep replicas")
+	}
+}
+
+func TestReaderWithoutReplicas(t *testing.T) {
+	primary := newDryRunDB(t)
+	db := &Database{db: primary, nextReplica: new(atomic.Uint64)}
+
+	if got := db.Reader(context.Background()); got != primary {
+		t.Error("without replicas reads must go to primary")
+	}
+}
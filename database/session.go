@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/vladzorgan/common/auth"
+	"gorm.io/gorm"
+)
+
+// SessionSettingsFunc возвращает настройки сессии Postgres для текущего контекста.
+// Настройки применяются как SET LOCAL в начале каждой транзакции и сбрасываются после нее,
+// что позволяет использовать их в политиках row-level security через current_setting().
+type SessionSettingsFunc func(ctx context.Context) map[string]string
+
+// AuthSessionSettings возвращает ID и роль пользователя из авторизационного контекста
+// в виде настроек app.user_id и app.user_role
+func AuthSessionSettings(ctx context.Context) map[string]string {
+	settings := make(map[string]string)
+
+	if userID, err := auth.GetUserIDFromContext(ctx); err == nil {
+		settings["app.user_id"] = strconv.FormatUint(uint64(userID), 10)
+	}
+
+	if userRole, err := auth.GetUserRoleFromContext(ctx); err == nil {
+		settings["app.user_role"] = string(userRole)
+	}
+
+	return settings
+}
+
+// SetSessionSettings устанавливает функцию получения настроек сессии
+func (d *Database) SetSessionSettings(fn SessionSettingsFunc) {
+	d.sessionSettings = fn
+}
+
+// applySessionSettings применяет настройки сессии внутри транзакции
+func (d *Database) applySessionSettings(ctx context.Context, tx *gorm.DB) error {
+	if d.sessionSettings == nil {
+		return nil
+	}
+
+	for key, value := range d.sessionSettings(ctx) {
+		// set_config(..., true) эквивалентен SET LOCAL, но поддерживает параметры запроса
+		if err := tx.Exec("SELECT set_config(?, ?, true)", key, value).Error; err != nil {
+			return fmt.Errorf("failed to apply session setting %s: %v", key, err)
+		}
+	}
+
+	return nil
+}
+
+// registerSessionSettingsCallback регистрирует callback GORM, применяющий настройки сессии
+// в неявных транзакциях, которые GORM открывает для операций create/update/delete
+func (d *Database) registerSessionSettingsCallback() error {
+	callback := func(db *gorm.DB) {
+		if d.sessionSettings == nil || db.Error != nil {
+			return
+		}
+
+		// Применяем настройки только если транзакцию открыл сам GORM
+		if _, ok := db.InstanceGet("gorm:started_transaction"); !ok {
+			return
+		}
+
+		ctx := db.Statement.Context
+		for key, value := range d.sessionSettings(ctx) {
+			if _, err := db.Statement.ConnPool.ExecContext(ctx, "SELECT set_config($1, $2, true)", key, value); err != nil {
+				db.AddError(fmt.Errorf("failed to apply session setting %s: %v", key, err))
+				return
+			}
+		}
+	}
+
+	const name = "common:session_settings"
+
+	// Без ограничения Before GORM ставит callback в конец цепочки, то есть после самого запроса
+	callbacks := d.db.Callback()
+	if err := callbacks.Create().After("gorm:begin_transaction").Before("gorm:before_create").Register(name, callback); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:begin_transaction").Before("gorm:before_update").Register(name, callback); err != nil {
+		return err
+	}
+	return callbacks.Delete().After("gorm:begin_transaction").Before("gorm:before_delete").Register(name, callback)
+}
@@ -0,0 +1,186 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vladzorgan/common/auth"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// settingsServer имитирует область видимости set_config(..., true) в Postgres:
+// локальные настройки видны только внутри транзакции и сбрасываются при ее завершении
+type settingsServer struct {
+	mutex sync.Mutex
+	// Значения current_setting, прочитанные тестом, в порядке запросов
+	observed []string
+	inserted []string
+}
+
+func (s *settingsServer) Connect(context.Context) (driver.Conn, error) {
+	return &settingsConn{server: s}, nil
+}
+func (s *settingsServer) Driver() driver.Driver { return nil }
+
+type settingsConn struct {
+	server *settingsServer
+	local  map[string]string
+}
+
+func (c *settingsConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *settingsConn) Close() error                        { return nil }
+func (c *settingsConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *settingsConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.local = make(map[string]string)
+	return c, nil
+}
+
+func (c *settingsConn) Commit() error   { c.local = nil; return nil }
+func (c *settingsConn) Rollback() error { c.local = nil; return nil }
+
+func (c *settingsConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.setConfig(query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *settingsConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	switch {
+	case strings.Contains(query, "set_config"):
+		return &settingsRows{}, c.setConfig(query, args)
+	case strings.Contains(query, "current_setting"):
+		key, _ := args[0].Value.(string)
+		value := c.local[key]
+
+		c.server.mutex.Lock()
+		c.server.observed = append(c.server.observed, value)
+		c.server.mutex.Unlock()
+		return &settingsRows{column: "current_setting", values: []driver.Value{value}}, nil
+	case strings.HasPrefix(query, "INSERT"):
+		c.server.mutex.Lock()
+		c.server.inserted = append(c.server.inserted, c.local["app.user_id"])
+		c.server.mutex.Unlock()
+		return &settingsRows{column: "id", values: []driver.Value{int64(1)}}, nil
+	default:
+		return nil, errors.New("unexpected query: " + query)
+	}
+}
+
+// setConfig применяет set_config; вне транзакции локальная настройка не сохраняется
+func (c *settingsConn) setConfig(query string, args []driver.NamedValue) error {
+	if !strings.Contains(query, "set_config") || !strings.Contains(query, "true)") {
+		return errors.New("unexpected statement: " + query)
+	}
+	if c.local != nil {
+		key, _ := args[0].Value.(string)
+		value, _ := args[1].Value.(string)
+		c.local[key] = value
+	}
+	return nil
+}
+
+type settingsRows struct {
+	column string
+	values []driver.Value
+}
+
+func (r *settingsRows) Columns() []string {
+	if r.column == "" {
+		return []string{"set_config"}
+	}
+	return []string{r.column}
+}
+
+func (r *settingsRows) Close() error { return nil }
+
+func (r *settingsRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+type sessionEntity struct {
+	ID   uint
+	Name string
+}
+
+func newSessionDatabase(t *testing.T, server *settingsServer, skipDefaultTransaction bool) *Database {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(server)}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: skipDefaultTransaction,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return &Database{db: db, logger: nil, sessionSettings: AuthSessionSettings}
+}
+
+// currentSetting читает настройку так же, как политика row-level security
+func currentSetting(db *gorm.DB, key string) string {
+	var value string
+	db.Raw("SELECT current_setting(?, true)", key).Scan(&value)
+	return value
+}
+
+func TestSessionSettingsVisibleOnlyInsideTransaction(t *testing.T) {
+	server := &settingsServer{}
+	db := newSessionDatabase(t, server, true)
+	ctx := auth.WithUser(context.Background(), &auth.User{ID: 42, Role: auth.UserRole_Admin, IsActive: true})
+
+	var userID, role string
+	err := db.RunInTransaction(ctx, func(ctx context.Context) error {
+		tx, _ := TransactionFromContext(ctx)
+		userID, role = currentSetting(tx, "app.user_id"), currentSetting(tx, "app.user_role")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunInTransaction() error = %v", err)
+	}
+	if userID != "42" || role != string(auth.UserRole_Admin) {
+		t.Errorf("inside transaction app.user_id = %q, app.user_role = %q", userID, role)
+	}
+
+	// После фиксации настройки не видны
+	if got := currentSetting(db.GetDB().WithContext(ctx), "app.user_id"); got != "" {
+		t.Errorf("outside transaction app.user_id = %q, want empty", got)
+	}
+}
+
+func TestSessionSettingsCallbackAppliesToImplicitTransactions(t *testing.T) {
+	server := &settingsServer{}
+	db := newSessionDatabase(t, server, false)
+	if err := db.registerSessionSettingsCallback(); err != nil {
+		t.Fatalf("registerSessionSettingsCallback() error = %v", err)
+	}
+
+	ctx := auth.WithUser(context.Background(), &auth.User{ID: 7, Role: auth.UserRole_User, IsActive: true})
+	if err := db.GetDB().WithContext(ctx).Create(&sessionEntity{Name: "row"}).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if len(server.inserted) != 1 || server.inserted[0] != "7" {
+		t.Errorf("app.user_id during INSERT = %v, want [7]", server.inserted)
+	}
+}
+
+func TestAuthSessionSettingsWithoutUser(t *testing.T) {
+	if settings := AuthSessionSettings(context.Background()); len(settings) != 0 {
+		t.Errorf("AuthSessionSettings() = %v, want empty", settings)
+	}
+}
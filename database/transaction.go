@@ -34,9 +34,29 @@ func (p *GormTxProvider) GetTx(ctx context.Context) *gorm.DB {
 	return p.db.GetDB().WithContext(ctx)
 }
 
+// TxRunner выполняет функцию в транзакции, передавая ее через контекст
+type TxRunner interface {
+	RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// RunInTransaction выполняет функцию в транзакции.
+// Если в контексте уже есть транзакция, функция выполняется в ней.
+func (d *Database) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := TransactionFromContext(ctx); ok {
+		return fn(ctx)
+	}
+	return RunInTransaction(ctx, d, fn)
+}
+
+// TransactionFromContext возвращает транзакцию из контекста
+func TransactionFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(TransactionKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
 // RunInTransaction выполняет функцию в транзакции
 func RunInTransaction(ctx context.Context, db *Database, fn func(ctx context.Context) error) error {
-	return db.GetDB().Transaction(func(tx *gorm.DB) error {
+	return db.TransactionContext(ctx, func(tx *gorm.DB) error {
 		// Создаем новый контекст с транзакцией
 		txCtx := context.WithValue(ctx, TransactionKey{}, tx)
 		return fn(txCtx)
@@ -73,7 +93,7 @@ func (m *TransactionMiddleware) Handler(next func(ctx context.Context) error) fu
 
 		// Начинаем новую транзакцию
 		var err error
-		err = m.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		err = m.db.TransactionContext(ctx, func(tx *gorm.DB) error {
 			// Создаем новый контекст с транзакцией
 			txCtx := context.WithValue(ctx, TransactionKey{}, tx)
 
@@ -0,0 +1,254 @@
+// Package di предоставляет минимальный контейнер зависимостей на дженериках
+// для связывания репозиториев, сервисов и инфраструктуры в main
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrNotProvided возвращается, если для типа не зарегистрирован провайдер
+var ErrNotProvided = errors.New("dependency not provided")
+
+// ErrCycle возвращается при циклической зависимости
+var ErrCycle = errors.New("dependency cycle detected")
+
+// key идентифицирует зависимость по типу и имени
+type key struct {
+	typ  reflect.Type
+	name string
+}
+
+// String возвращает читаемое имя зависимости
+func (k key) String() string {
+	if k.name == "" {
+		return k.typ.String()
+	}
+	return fmt.Sprintf("%s[%s]", k.typ, k.name)
+}
+
+// entry содержит провайдер и созданный экземпляр зависимости
+type entry struct {
+	provider func(ctx context.Context) (interface{}, error)
+	value    interface{}
+	resolved bool
+	owned    bool // Экземпляр создан контейнером и должен быть остановлен им
+}
+
+// Container хранит провайдеры и созданные экземпляры зависимостей.
+// Все экземпляры создаются один раз (singleton).
+type Container struct {
+	entries map[key]*entry
+	order   []key // Порядок создания экземпляров для остановки в обратном порядке
+	mutex   sync.Mutex
+}
+
+// Ключ состояния разрешения зависимостей в контексте
+type contextKey string
+
+const resolutionContextKey contextKey = "di_resolution"
+
+// resolution хранит цепочку разрешаемых зависимостей текущего вызова Resolve
+type resolution struct {
+	container *Container
+	chain     []key
+}
+
+// NewContainer создает новый контейнер
+func NewContainer() *Container {
+	return &Container{
+		entries: make(map[key]*entry),
+	}
+}
+
+// Provide регистрирует провайдер зависимости типа T
+func Provide[T any](c *Container, provider func(ctx context.Context, c *Container) (T, error)) {
+	ProvideNamed(c, "", provider)
+}
+
+// ProvideNamed регистрирует именованный провайдер для нескольких экземпляров одного типа
+func ProvideNamed[T any](c *Container, name string, provider func(ctx context.Context, c *Container) (T, error)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[keyOf[T](name)] = &entry{
+		provider: func(ctx context.Context) (interface{}, error) {
+			return provider(ctx, c)
+		},
+		owned: true,
+	}
+}
+
+// Supply регистрирует готовый экземпляр. Контейнер не останавливает такие экземпляры.
+func Supply[T any](c *Container, value T) {
+	SupplyNamed(c, "", value)
+}
+
+// SupplyNamed регистрирует готовый именованный экземпляр
+func SupplyNamed[T any](c *Container, name string, value T) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[keyOf[T](name)] = &entry{
+		value:    value,
+		resolved: true,
+	}
+}
+
+// Resolve возвращает экземпляр типа T, создавая его и его зависимости при первом обращении
+func Resolve[T any](ctx context.Context, c *Container) (T, error) {
+	return ResolveNamed[T](ctx, c, "")
+}
+
+// ResolveNamed возвращает именованный экземпляр типа T
+func ResolveNamed[T any](ctx context.Context, c *Container, name string) (T, error) {
+	var zero T
+
+	value, err := c.resolve(ctx, keyOf[T](name))
+	if err != nil {
+		return zero, err
+	}
+
+	typed, ok := value.(T)
+	if !ok && value != nil {
+		return zero, fmt.Errorf("dependency %s has unexpected type %T", keyOf[T](name), value)
+	}
+	return typed, nil
+}
+
+// MustResolve аналогичен Resolve, но паникует при ошибке
+func MustResolve[T any](ctx context.Context, c *Container) T {
+	value, err := Resolve[T](ctx, c)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// resolve разрешает зависимость по ключу.
+// Контейнер блокируется на весь внешний вызов Resolve; вложенные вызовы из провайдеров
+// определяются по контексту и выполняются без повторной блокировки.
+func (c *Container) resolve(ctx context.Context, k key) (interface{}, error) {
+	state, nested := ctx.Value(resolutionContextKey).(*resolution)
+	if !nested || state.container != c {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		state = &resolution{container: c}
+		ctx = context.WithValue(ctx, resolutionContextKey, state)
+	}
+
+	e, ok := c.entries[k]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s%s", ErrNotProvided, k, state.describeChain())
+	}
+
+	if e.resolved {
+		return e.value, nil
+	}
+
+	// Проверяем цикл по текущей цепочке разрешения
+	for i, chained := range state.chain {
+		if chained == k {
+			names := make([]string, 0, len(state.chain)-i+1)
+			for _, ck := range state.chain[i:] {
+				names = append(names, ck.String())
+			}
+			names = append(names, k.String())
+			return nil, fmt.Errorf("%w: %s", ErrCycle, strings.Join(names, " -> "))
+		}
+	}
+
+	state.chain = append(state.chain, k)
+	value, err := e.provider(ctx)
+	state.chain = state.chain[:len(state.chain)-1]
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to provide %s: %w", k, err)
+	}
+
+	e.value = value
+	e.resolved = true
+	c.order = append(c.order, k)
+
+	return value, nil
+}
+
+// describeChain возвращает описание цепочки разрешения для сообщений об ошибках
+func (r *resolution) describeChain() string {
+	if len(r.chain) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(r.chain))
+	for _, k := range r.chain {
+		names = append(names, k.String())
+	}
+	return " (required by " + strings.Join(names, " -> ") + ")"
+}
+
+// Close останавливает созданные контейнером экземпляры в порядке, обратном порядку создания.
+// Поддерживаются методы Close() error, Close(), Stop() error, Stop() и Shutdown(ctx) error.
+func (c *Container) Close(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var errs []error
+	stopped := make(map[interface{}]bool)
+	for i := len(c.order) - 1; i >= 0; i-- {
+		k := c.order[i]
+		e := c.entries[k]
+		if !e.owned {
+			continue
+		}
+
+		// Один экземпляр может быть зарегистрирован под несколькими типами (например, интерфейсом)
+		if e.value != nil && reflect.TypeOf(e.value).Comparable() {
+			if stopped[e.value] {
+				e.value = nil
+				e.resolved = false
+				continue
+			}
+			stopped[e.value] = true
+		}
+
+		if err := stop(ctx, e.value); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", k, err))
+		}
+
+		e.value = nil
+		e.resolved = false
+	}
+	c.order = nil
+
+	return errors.Join(errs...)
+}
+
+// stop останавливает экземпляр, если он поддерживает один из методов остановки
+func stop(ctx context.Context, value interface{}) error {
+	switch v := value.(type) {
+	case interface{ Shutdown(context.Context) error }:
+		return v.Shutdown(ctx)
+	case interface{ Close() error }:
+		return v.Close()
+	case interface{ Close() }:
+		v.Close()
+	case interface{ Stop() error }:
+		return v.Stop()
+	case interface{ Stop() }:
+		v.Stop()
+	}
+	return nil
+}
+
+// keyOf возвращает ключ зависимости для типа T
+func keyOf[T any](name string) key {
+	return key{
+		typ:  reflect.TypeOf((*T)(nil)).Elem(),
+		name: name,
+	}
+}
@@ -0,0 +1,309 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/vladzorgan/common/config"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/messaging/rabbitmq"
+)
+
+type (
+	serviceA struct{}
+	serviceB struct{}
+	serviceC struct{}
+)
+
+// closer записывает порядок остановки в общий журнал
+type closer struct {
+	name string
+	log  *[]string
+	err  error
+}
+
+func (c *closer) Close() error {
+	*c.log = append(*c.log, c.name)
+	return c.err
+}
+
+// stopper останавливается методом Stop без результата
+type stopper struct {
+	name string
+	log  *[]string
+}
+
+func (s *stopper) Stop() { *s.log = append(*s.log, s.name) }
+
+// shutdowner останавливается методом Shutdown с контекстом
+type shutdowner struct {
+	name string
+	log  *[]string
+}
+
+func (s *shutdowner) Shutdown(context.Context) error {
+	*s.log = append(*s.log, s.name)
+	return nil
+}
+
+type named interface{ Name() string }
+
+func (c *closer) Name() string { return c.name }
+
+func TestResolveMemoizesSingletons(t *testing.T) {
+	c := NewContainer()
+
+	var calls atomic.Int32
+	Provide(c, func(ctx context.Context, c *Container) (*serviceA, error) {
+		calls.Add(1)
+		return &serviceA{}, nil
+	})
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	results := make([]*serviceA, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = MustResolve[*serviceA](ctx, c)
+		}(i)
+	}
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("provider calls = %d, want 1", got)
+	}
+	for _, result := range results {
+		if result != results[0] {
+			t.Fatal("Resolve() returned different instances")
+		}
+	}
+}
+
+func TestResolveDetectsCycles(t *testing.T) {
+	c := NewContainer()
+	Provide(c, func(ctx context.Context, c *Container) (*serviceA, error) {
+		_, err := Resolve[*serviceB](ctx, c)
+		return &serviceA{}, err
+	})
+	Provide(c, func(ctx context.Context, c *Container) (*serviceB, error) {
+		_, err := Resolve[*serviceC](ctx, c)
+		return &serviceB{}, err
+	})
+	Provide(c, func(ctx context.Context, c *Container) (*serviceC, error) {
+		_, err := Resolve[*serviceA](ctx, c)
+		return &serviceC{}, err
+	})
+
+	_, err := Resolve[*serviceA](context.Background(), c)
+	if !errors.Is(err, ErrCycle) {
+		t.Fatalf("Resolve() error = %v, want ErrCycle", err)
+	}
+	chain := "*di.serviceA -> *di.serviceB -> *di.serviceC -> *di.serviceA"
+	if !strings.Contains(err.Error(), chain) {
+		t.Errorf("Resolve() error = %v, want chain %s", err, chain)
+	}
+
+	// Ни один экземпляр цикла не создан, повторный вызов возвращает ту же ошибку
+	if _, err := Resolve[*serviceB](context.Background(), c); !errors.Is(err, ErrCycle) {
+		t.Errorf("second Resolve() error = %v, want ErrCycle", err)
+	}
+	if len(c.order) != 0 {
+		t.Errorf("resolved = %v, want none", c.order)
+	}
+}
+
+func TestResolveDetectsSelfCycle(t *testing.T) {
+	c := NewContainer()
+	ProvideNamed(c, "self", func(ctx context.Context, c *Container) (*serviceA, error) {
+		return ResolveNamed[*serviceA](ctx, c, "self")
+	})
+
+	_, err := ResolveNamed[*serviceA](context.Background(), c, "self")
+	if !errors.Is(err, ErrCycle) || !strings.Contains(err.Error(), "*di.serviceA[self] -> *di.serviceA[self]") {
+		t.Errorf("Resolve() error = %v, want self cycle", err)
+	}
+}
+
+func TestResolveNotProvidedListsChain(t *testing.T) {
+	c := NewContainer()
+	Provide(c, func(ctx context.Context, c *Container) (*serviceA, error) {
+		_, err := Resolve[*serviceB](ctx, c)
+		return &serviceA{}, err
+	})
+	Provide(c, func(ctx context.Context, c *Container) (*serviceB, error) {
+		_, err := Resolve[*serviceC](ctx, c)
+		return &serviceB{}, err
+	})
+
+	_, err := Resolve[*serviceA](context.Background(), c)
+	if !errors.Is(err, ErrNotProvided) {
+		t.Fatalf("Resolve() error = %v, want ErrNotProvided", err)
+	}
+	if !strings.Contains(err.Error(), "*di.serviceC (required by *di.serviceA -> *di.serviceB)") {
+		t.Errorf("Resolve() error = %v, want required-by chain", err)
+	}
+}
+
+func TestResolveNamed(t *testing.T) {
+	c := NewContainer()
+	var log []string
+	ProvideNamed(c, "primary", func(ctx context.Context, c *Container) (*closer, error) {
+		return &closer{name: "primary", log: &log}, nil
+	})
+	ProvideNamed(c, "replica", func(ctx context.Context, c *Container) (*closer, error) {
+		return &closer{name: "replica", log: &log}, nil
+	})
+	SupplyNamed[named](c, "static", &closer{name: "static", log: &log})
+
+	ctx := context.Background()
+	for _, name := range []string{"primary", "replica"} {
+		value, err := ResolveNamed[*closer](ctx, c, name)
+		if err != nil || value.name != name {
+			t.Errorf("ResolveNamed(%s) = %v, %v", name, value, err)
+		}
+	}
+	if value, err := ResolveNamed[named](ctx, c, "static"); err != nil || value.Name() != "static" {
+		t.Errorf("ResolveNamed(static) = %v, %v", value, err)
+	}
+
+	// Безымянный экземпляр не совпадает с именованными
+	if _, err := Resolve[*closer](ctx, c); !errors.Is(err, ErrNotProvided) {
+		t.Errorf("Resolve() error = %v, want ErrNotProvided", err)
+	}
+	if _, err := ResolveNamed[*closer](ctx, c, "missing"); err == nil || !strings.Contains(err.Error(), "*di.closer[missing]") {
+		t.Errorf("ResolveNamed(missing) error = %v", err)
+	}
+}
+
+func TestCloseStopsInReverseResolutionOrder(t *testing.T) {
+	c := NewContainer()
+	var log []string
+
+	// database <- repository <- service; consumer зависит от service
+	Provide(c, func(ctx context.Context, c *Container) (*closer, error) {
+		return &closer{name: "database", log: &log}, nil
+	})
+	Provide(c, func(ctx context.Context, c *Container) (*stopper, error) {
+		if _, err := Resolve[*closer](ctx, c); err != nil {
+			return nil, err
+		}
+		return &stopper{name: "repository", log: &log}, nil
+	})
+	Provide(c, func(ctx context.Context, c *Container) (*shutdowner, error) {
+		if _, err := Resolve[*stopper](ctx, c); err != nil {
+			return nil, err
+		}
+		return &shutdowner{name: "service", log: &log}, nil
+	})
+	ProvideNamed(c, "consumer", func(ctx context.Context, c *Container) (*closer, error) {
+		if _, err := Resolve[*shutdowner](ctx, c); err != nil {
+			return nil, err
+		}
+		return &closer{name: "consumer", log: &log}, nil
+	})
+	// Готовые экземпляры остаются под управлением вызывающего кода
+	SupplyNamed(c, "external", &closer{name: "external", log: &log})
+
+	ctx := context.Background()
+	if _, err := ResolveNamed[*closer](ctx, c, "consumer"); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if _, err := ResolveNamed[*closer](ctx, c, "external"); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	if err := c.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := strings.Join(log, ","); got != "consumer,service,repository,database" {
+		t.Errorf("shutdown order = %s, want consumer,service,repository,database", got)
+	}
+
+	// После Close зависимости создаются заново
+	log = nil
+	if _, err := Resolve[*stopper](ctx, c); err != nil {
+		t.Fatalf("Resolve() after Close error = %v", err)
+	}
+	if err := c.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := strings.Join(log, ","); got != "repository,database" {
+		t.Errorf("shutdown order = %s, want repository,database", got)
+	}
+}
+
+func TestCloseStopsSharedInstanceOnceAndJoinsErrors(t *testing.T) {
+	c := NewContainer()
+	var log []string
+
+	Provide(c, func(ctx context.Context, c *Container) (*closer, error) {
+		return &closer{name: "broken", log: &log, err: errors.New("close failed")}, nil
+	})
+	// Интерфейс указывает на тот же экземпляр
+	Provide(c, func(ctx context.Context, c *Container) (named, error) {
+		return Resolve[*closer](ctx, c)
+	})
+	Provide(c, func(ctx context.Context, c *Container) (*stopper, error) {
+		return &stopper{name: "healthy", log: &log}, nil
+	})
+
+	ctx := context.Background()
+	if _, err := Resolve[named](ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Resolve[*stopper](ctx, c); err != nil {
+		t.Fatal(err)
+	}
+
+	err := c.Close(ctx)
+	if err == nil || !strings.Contains(err.Error(), "close failed") {
+		t.Errorf("Close() error = %v", err)
+	}
+	// Ошибка одного экземпляра не прерывает остановку остальных
+	if got := strings.Join(log, ","); got != "healthy,broken" {
+		t.Errorf("shutdown order = %s, want healthy,broken", got)
+	}
+}
+
+func TestProviderErrorIsWrapped(t *testing.T) {
+	c := NewContainer()
+	errDial := errors.New("dial failed")
+	Provide(c, func(ctx context.Context, c *Container) (*serviceA, error) {
+		return nil, errDial
+	})
+
+	_, err := Resolve[*serviceA](context.Background(), c)
+	if !errors.Is(err, errDial) || !strings.Contains(err.Error(), "failed to provide *di.serviceA") {
+		t.Errorf("Resolve() error = %v", err)
+	}
+}
+
+func TestProvideDefaults(t *testing.T) {
+	c := NewContainer()
+	cfg := &config.BaseConfig{ServiceName: "orders"}
+	logger := logging.NewLogger()
+	ProvideDefaults(c, cfg, logger, nil)
+
+	ctx := context.Background()
+	if got := MustResolve[*config.BaseConfig](ctx, c); got != cfg {
+		t.Error("config is not the supplied instance")
+	}
+	if got := MustResolve[logging.Logger](ctx, c); got != logger {
+		t.Error("logger is not the supplied instance")
+	}
+
+	// Без RABBITMQ_URL издатель создается без соединения
+	publisher := MustResolve[*rabbitmq.Publisher](ctx, c)
+	if eventPublisher := MustResolve[rabbitmq.EventPublisher](ctx, c); eventPublisher != publisher {
+		t.Error("EventPublisher is not the shared *Publisher")
+	}
+	if err := c.Close(ctx); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package di
+
+import (
+	"context"
+
+	"github.com/vladzorgan/common/config"
+	"github.com/vladzorgan/common/database"
+	"github.com/vladzorgan/common/killswitch"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/messaging/rabbitmq"
+	"github.com/vladzorgan/common/redis"
+)
+
+// DefaultsOptions содержит опции встроенных зависимостей
+type DefaultsOptions struct {
+	// Имя обменника для издателя событий
+	ExchangeName string
+	// Опции соединения с базой данных
+	DatabaseOptions *database.DatabaseOptions
+	// Опции клиента Redis
+	RedisOptions *redis.ClientOptions
+}
+
+// DefaultDefaultsOptions возвращает опции по умолчанию
+func DefaultDefaultsOptions() *DefaultsOptions {
+	return &DefaultsOptions{
+		ExchangeName: "events",
+	}
+}
+
+// ProvideDefaults регистрирует встроенные зависимости: конфигурацию, логгер, базу данных,
+// клиент Redis и издателя событий. Инфраструктурные клиенты создаются лениво при первом Resolve.
+func ProvideDefaults(c *Container, cfg *config.BaseConfig, logger logging.Logger, options *DefaultsOptions) {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	if options == nil {
+		options = DefaultDefaultsOptions()
+	}
+
+	Supply(c, cfg)
+	Supply(c, logger)
+
+	Provide(c, func(ctx context.Context, c *Container) (*database.Database, error) {
+		return database.NewDatabase(cfg.DatabaseURL, logger, options.DatabaseOptions)
+	})
+
+	Provide(c, func(ctx context.Context, c *Container) (*redis.Client, error) {
+		redisOptions := redis.DefaultClientOptions()
+		if options.RedisOptions != nil {
+			copied := *options.RedisOptions
+			redisOptions = &copied
+		}
+		if redisOptions.ClientName == "" {
+			redisOptions.ClientName = cfg.ServicePrefix
+		}
+		if redisOptions.ReadRetryDisabled == nil {
+			redisOptions.ReadRetryDisabled = func() bool {
+				return killswitch.IsDisabled(killswitch.FeatureRedisReadRetry)
+			}
+		}
+		return redis.NewUniversalClient(cfg.RedisURL, cfg.RedisPassword, cfg.RedisDB, logger, redisOptions)
+	})
+
+	Provide(c, func(ctx context.Context, c *Container) (*rabbitmq.Publisher, error) {
+		return rabbitmq.NewPublisher(cfg.RabbitMQURL, options.ExchangeName, cfg.ServiceName, logger)
+	})
+
+	Provide(c, func(ctx context.Context, c *Container) (rabbitmq.EventPublisher, error) {
+		return Resolve[*rabbitmq.Publisher](ctx, c)
+	})
+}
@@ -0,0 +1,92 @@
+package errors
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ToGRPCStatus преобразует ошибку в gRPC статус
+func ToGRPCStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	// Типизированные ошибки сопоставляем по виду
+	var typedErr *Error
+	if stderrors.As(err, &typedErr) {
+		return status.New(kindToGRPCCode(typedErr.Kind), err.Error())
+	}
+
+	// Ошибка уже является gRPC статусом
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	return status.New(kindToGRPCCode(err), err.Error())
+}
+
+// ToGRPCError преобразует ошибку в gRPC ошибку с соответствующим кодом
+func ToGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return ToGRPCStatus(err).Err()
+}
+
+// ToHTTPStatus возвращает HTTP код, соответствующий ошибке
+func ToHTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	return grpcCodeToHTTP(ToGRPCStatus(err).Code())
+}
+
+// kindToGRPCCode сопоставляет вид ошибки с gRPC кодом
+func kindToGRPCCode(err error) codes.Code {
+	switch {
+	case stderrors.Is(err, ErrNotFound):
+		return codes.NotFound
+	case stderrors.Is(err, ErrValidation):
+		return codes.InvalidArgument
+	case stderrors.Is(err, ErrConflict):
+		return codes.AlreadyExists
+	case stderrors.Is(err, ErrPermissionDenied):
+		return codes.PermissionDenied
+	default:
+		return codes.Internal
+	}
+}
+
+// grpcCodeToHTTP сопоставляет gRPC код с HTTP кодом
+func grpcCodeToHTTP(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Canceled:
+		return 499 // Клиент закрыл соединение
+	default:
+		return http.StatusInternalServerError
+	}
+}
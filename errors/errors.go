@@ -0,0 +1,158 @@
+// Package errors предоставляет стандартизированные типизированные ошибки сервисов
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Базовые виды ошибок, используемые с errors.Is
+var (
+	// ErrNotFound сущность не найдена
+	ErrNotFound = stderrors.New("not found")
+	// ErrValidation входные данные не прошли валидацию
+	ErrValidation = stderrors.New("validation failed")
+	// ErrConflict операция конфликтует с текущим состоянием (например, дубликат)
+	ErrConflict = stderrors.New("conflict")
+	// ErrPermissionDenied недостаточно прав для операции
+	ErrPermissionDenied = stderrors.New("permission denied")
+	// ErrInternal внутренняя ошибка (база данных, внешние сервисы и т.д.)
+	ErrInternal = stderrors.New("internal error")
+)
+
+// Error представляет типизированную ошибку с информацией о сущности
+type Error struct {
+	Kind    error       // Вид ошибки (ErrNotFound, ErrValidation и т.д.)
+	Entity  string      // Имя сущности
+	ID      interface{} // ID сущности (если применимо)
+	Message string      // Текст ошибки
+	Err     error       // Исходная ошибка
+
+	// Blockers содержит причины, препятствующие операции, по ID сущностей (например, "has 12 cities")
+	Blockers map[uint][]string
+
+	// Fields содержит сообщения ошибок валидации по полям (например, "name": "обязательное поле")
+	Fields map[string]string
+}
+
+// Error возвращает текст ошибки
+func (e *Error) Error() string {
+	message := e.Message
+	if message == "" {
+		message = e.Kind.Error()
+	}
+
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", message, e.Err)
+	}
+
+	return message
+}
+
+// Unwrap возвращает вид ошибки и исходную ошибку для errors.Is/As
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// New создает новую типизированную ошибку
+func New(kind error, entity string, id interface{}, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Entity:  entity,
+		ID:      id,
+		Message: message,
+	}
+}
+
+// Wrap создает типизированную ошибку, оборачивающую исходную
+func Wrap(kind error, entity string, id interface{}, err error, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Entity:  entity,
+		ID:      id,
+		Message: message,
+		Err:     err,
+	}
+}
+
+// NotFound создает ошибку "сущность не найдена"
+func NotFound(entity string, id interface{}) *Error {
+	return New(ErrNotFound, entity, id, fmt.Sprintf("%s с ID %v не найден", EntityDisplayName(entity), id))
+}
+
+// Validation создает ошибку валидации
+func Validation(entity string, err error) *Error {
+	return Wrap(ErrValidation, entity, nil, err, "ошибка валидации")
+}
+
+// Conflict создает ошибку конфликта
+func Conflict(entity string, id interface{}, err error) *Error {
+	return Wrap(ErrConflict, entity, id, err, fmt.Sprintf("конфликт при изменении %s", EntityDisplayName(entity)))
+}
+
+// Blocked создает ошибку конфликта, перечисляющую причины, по которым сущности нельзя удалить
+func Blocked(entity string, blockers map[uint][]string) *Error {
+	ids := make([]uint, 0, len(blockers))
+	for id := range blockers {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, fmt.Sprintf("ID %d: %s", id, strings.Join(blockers[id], ", ")))
+	}
+
+	var id interface{}
+	if len(ids) == 1 {
+		id = ids[0]
+	}
+
+	return &Error{
+		Kind:     ErrConflict,
+		Entity:   entity,
+		ID:       id,
+		Message:  fmt.Sprintf("нельзя удалить %s: %s", EntityDisplayName(entity), strings.Join(parts, "; ")),
+		Blockers: blockers,
+	}
+}
+
+// PermissionDenied создает ошибку недостатка прав
+func PermissionDenied(entity string, err error) *Error {
+	return Wrap(ErrPermissionDenied, entity, nil, err, fmt.Sprintf("недостаточно прав для операции с %s", EntityDisplayName(entity)))
+}
+
+// Internal создает внутреннюю ошибку
+func Internal(entity string, err error, message string) *Error {
+	return Wrap(ErrInternal, entity, nil, err, message)
+}
+
+// IsNotFound проверяет, является ли ошибка ошибкой "не найдено"
+func IsNotFound(err error) bool {
+	return stderrors.Is(err, ErrNotFound)
+}
+
+// IsValidation проверяет, является ли ошибка ошибкой валидации
+func IsValidation(err error) bool {
+	return stderrors.Is(err, ErrValidation)
+}
+
+// IsConflict проверяет, является ли ошибка ошибкой конфликта
+func IsConflict(err error) bool {
+	return stderrors.Is(err, ErrConflict)
+}
+
+// IsPermissionDenied проверяет, является ли ошибка ошибкой недостатка прав
+func IsPermissionDenied(err error) bool {
+	return stderrors.Is(err, ErrPermissionDenied)
+}
+
+// IsInternal проверяет, является ли ошибка внутренней
+func IsInternal(err error) bool {
+	return stderrors.Is(err, ErrInternal)
+}
@@ -0,0 +1,23 @@
+package errors
+
+import "sync"
+
+// entityNames хранит отображаемые имена сущностей для текстов ошибок
+var entityNames sync.Map
+
+// RegisterEntityName задает отображаемое имя сущности, используемое в текстах ошибок
+// (например, "device_brand" -> "бренд устройства")
+func RegisterEntityName(entity, displayName string) {
+	if entity == "" || displayName == "" {
+		return
+	}
+	entityNames.Store(entity, displayName)
+}
+
+// EntityDisplayName возвращает отображаемое имя сущности или само имя, если оно не зарегистрировано
+func EntityDisplayName(entity string) string {
+	if displayName, ok := entityNames.Load(entity); ok {
+		return displayName.(string)
+	}
+	return entity
+}
@@ -0,0 +1,152 @@
+// Package eventcatalog предоставляет каталог событий сервиса с JSON-схемами полезной нагрузки.
+// Каталог служит контрактом для потребителей событий и вебхуков и позволяет проверять
+// исходящие сообщения перед отправкой.
+package eventcatalog
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownEvent возвращается при обращении к незарегистрированному событию
+var ErrUnknownEvent = errors.New("event is not registered in catalog")
+
+// EventDescriptor описывает событие при регистрации в каталоге
+type EventDescriptor struct {
+	// Ключ маршрутизации события (например, "device.created")
+	RoutingKey string
+	// Описание события для потребителей
+	Description string
+	// Значение типа полезной нагрузки; схема строится по его Go-типу
+	Payload interface{}
+	// Пример полезной нагрузки (необязательно); проверяется на соответствие схеме
+	Example interface{}
+}
+
+// Event представляет зарегистрированное событие каталога
+type Event struct {
+	RoutingKey  string      `json:"routing_key"`
+	Description string      `json:"description,omitempty"`
+	Schema      *Schema     `json:"schema"`
+	Example     interface{} `json:"example,omitempty"`
+}
+
+// Document представляет машиночитаемый каталог событий сервиса
+type Document struct {
+	Service string  `json:"service"`
+	Events  []Event `json:"events"`
+}
+
+// Catalog хранит описания событий сервиса
+type Catalog struct {
+	serviceName string
+	events      map[string]Event
+	mutex       sync.RWMutex
+}
+
+// NewCatalog создает пустой каталог событий сервиса
+func NewCatalog(serviceName string) *Catalog {
+	return &Catalog{
+		serviceName: serviceName,
+		events:      make(map[string]Event),
+	}
+}
+
+// Register регистрирует одно или несколько событий.
+// Повторная регистрация ключа маршрутизации и несоответствие примера схеме возвращают ошибку.
+func (c *Catalog) Register(descriptors ...EventDescriptor) error {
+	events := make([]Event, 0, len(descriptors))
+	for _, descriptor := range descriptors {
+		if descriptor.RoutingKey == "" {
+			return errors.New("event routing key is empty")
+		}
+		if descriptor.Payload == nil {
+			return fmt.Errorf("event %s: payload type is not set", descriptor.RoutingKey)
+		}
+
+		schema := SchemaFor(descriptor.Payload)
+		if descriptor.Example != nil {
+			if err := ValidatePayload(schema, descriptor.Example); err != nil {
+				return fmt.Errorf("event %s: example does not match schema: %w", descriptor.RoutingKey, err)
+			}
+		}
+
+		events = append(events, Event{
+			RoutingKey:  descriptor.RoutingKey,
+			Description: descriptor.Description,
+			Schema:      schema,
+			Example:     descriptor.Example,
+		})
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, event := range events {
+		if _, exists := c.events[event.RoutingKey]; exists {
+			return fmt.Errorf("event %s is already registered", event.RoutingKey)
+		}
+	}
+	for _, event := range events {
+		c.events[event.RoutingKey] = event
+	}
+
+	return nil
+}
+
+// MustRegister регистрирует события и паникует при ошибке
+func (c *Catalog) MustRegister(descriptors ...EventDescriptor) {
+	if err := c.Register(descriptors...); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup возвращает описание события по ключу маршрутизации
+func (c *Catalog) Lookup(routingKey string) (Event, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	event, ok := c.events[routingKey]
+	return event, ok
+}
+
+// Events возвращает все события каталога, упорядоченные по ключу маршрутизации
+func (c *Catalog) Events() []Event {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	events := make([]Event, 0, len(c.events))
+	for _, event := range c.events {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].RoutingKey < events[j].RoutingKey
+	})
+
+	return events
+}
+
+// Document возвращает каталог в машиночитаемом виде
+func (c *Catalog) Document() Document {
+	return Document{
+		Service: c.serviceName,
+		Events:  c.Events(),
+	}
+}
+
+// Validate проверяет полезную нагрузку исходящего события по схеме каталога.
+// Для незарегистрированного события возвращается ErrUnknownEvent.
+func (c *Catalog) Validate(routingKey string, payload interface{}) error {
+	event, ok := c.Lookup(routingKey)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownEvent, routingKey)
+	}
+
+	if err := ValidatePayload(event.Schema, payload); err != nil {
+		return fmt.Errorf("event %s: %w", routingKey, err)
+	}
+
+	return nil
+}
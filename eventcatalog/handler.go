@@ -0,0 +1,25 @@
+package eventcatalog
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler возвращает обработчик, отдающий каталог событий сервиса
+// @Summary Каталог событий
+// @Description Возвращает ключи маршрутизации событий и JSON-схемы их полезной нагрузки
+// @Tags internal
+// @Produce json
+// @Success 200 {object} Document
+// @Router /internal/events [get]
+func (c *Catalog) Handler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, c.Document())
+	}
+}
+
+// RegisterHandlers регистрирует обработчик каталога в маршрутизаторе
+func (c *Catalog) RegisterHandlers(router gin.IRouter) {
+	router.GET("/internal/events", c.Handler())
+}
@@ -0,0 +1,157 @@
+package eventcatalog
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema представляет JSON-схему полезной нагрузки события
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Example              string             `json:"example,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// SchemaFor строит JSON-схему для значения v по его Go-типу.
+// Имена полей берутся из тегов json, описание - из тега doc, пример - из тега example.
+// Поля без omitempty и не являющиеся указателями считаются обязательными.
+func SchemaFor(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return schemaForType(reflect.TypeOf(v), make(map[reflect.Type]bool))
+}
+
+// schemaForType строит схему для типа; visiting защищает от бесконечной рекурсии на циклических типах
+func schemaForType(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	if t.Kind() == reflect.Ptr {
+		schema := schemaForType(t.Elem(), visiting)
+		schema.Nullable = true
+		return schema
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	// Типы с собственной сериализацией описать по структуре нельзя
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		// []byte сериализуется в base64-строку; nil-срезы сериализуются в null
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte", Nullable: true}
+		}
+		return &Schema{Type: "array", Items: schemaForType(t.Elem(), visiting), Nullable: true}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: schemaForType(t.Elem(), visiting)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaForType(t.Elem(), visiting), Nullable: true}
+	case reflect.Struct:
+		return structSchema(t, visiting)
+	default:
+		// interface{} и прочие типы допускают любое значение
+		return &Schema{}
+	}
+}
+
+// structSchema строит схему объекта по полям структуры
+func structSchema(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	// Циклическая ссылка описывается как объект с произвольными свойствами
+	if visiting[t] {
+		return &Schema{Type: "object"}
+	}
+
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	addStructFields(schema, t, visiting)
+	return schema
+}
+
+// addStructFields добавляет в схему свойства для полей структуры, включая встроенные
+func addStructFields(schema *Schema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, omitEmpty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		// Встроенные структуры без имени в json разворачиваются в родительский объект
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				addStructFields(schema, embedded, visiting)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := schemaForType(field.Type, visiting)
+		if doc := field.Tag.Get("doc"); doc != "" {
+			property.Description = doc
+		}
+		if example := field.Tag.Get("example"); example != "" {
+			property.Example = example
+		}
+
+		schema.Properties[name] = property
+		if !omitEmpty && field.Type.Kind() != reflect.Ptr {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// jsonFieldName разбирает тег json поля
+func jsonFieldName(field reflect.StructField) (name string, omitEmpty, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return parts[0], omitEmpty, false
+}
@@ -0,0 +1,182 @@
+package eventcatalog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type address struct {
+	City   string `json:"city" doc:"Город"`
+	Street string `json:"street,omitempty"`
+}
+
+type orderItem struct {
+	SKU      string  `json:"sku"`
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
+type orderPayload struct {
+	ID         uint              `json:"id" doc:"ID заказа" example:"42"`
+	Address    address           `json:"address"`
+	Items      []orderItem       `json:"items"`
+	Tags       []string          `json:"tags,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	ShippedAt  *time.Time        `json:"shipped_at"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	internal   string
+	Ignored    string `json:"-"`
+}
+
+type treeNode struct {
+	Name     string      `json:"name"`
+	Children []*treeNode `json:"children"`
+}
+
+func TestSchemaForNestedStruct(t *testing.T) {
+	schema := SchemaFor(orderPayload{})
+
+	if schema.Type != "object" {
+		t.Fatalf("type = %q, want object", schema.Type)
+	}
+
+	wantRequired := []string{"id", "address", "items", "created_at"}
+	if !reflect.DeepEqual(schema.Required, wantRequired) {
+		t.Errorf("required = %v, want %v", schema.Required, wantRequired)
+	}
+
+	for _, name := range []string{"internal", "Ignored"} {
+		if _, ok := schema.Properties[name]; ok {
+			t.Errorf("property %q must not be in schema", name)
+		}
+	}
+
+	id := schema.Properties["id"]
+	if id.Type != "integer" || id.Description != "ID заказа" || id.Example != "42" {
+		t.Errorf("id = %+v, want integer with doc and example", id)
+	}
+
+	addr := schema.Properties["address"]
+	if addr.Type != "object" || addr.Properties["city"].Type != "string" {
+		t.Fatalf("address = %+v, want nested object", addr)
+	}
+	if addr.Properties["city"].Description != "Город" {
+		t.Errorf("address.city description = %q", addr.Properties["city"].Description)
+	}
+	if !reflect.DeepEqual(addr.Required, []string{"city"}) {
+		t.Errorf("address required = %v, want [city]", addr.Required)
+	}
+}
+
+func TestSchemaForSlices(t *testing.T) {
+	schema := SchemaFor(orderPayload{})
+
+	items := schema.Properties["items"]
+	if items.Type != "array" || items.Items == nil {
+		t.Fatalf("items = %+v, want array", items)
+	}
+	if items.Items.Type != "object" || items.Items.Properties["price"].Type != "number" {
+		t.Errorf("items element = %+v, want object with number price", items.Items)
+	}
+
+	tags := schema.Properties["tags"]
+	if tags.Type != "array" || tags.Items.Type != "string" {
+		t.Errorf("tags = %+v, want array of strings", tags)
+	}
+
+	if bytes := SchemaFor([]byte("x")); bytes.Type != "string" || bytes.Format != "byte" {
+		t.Errorf("[]byte = %+v, want string/byte", bytes)
+	}
+
+	attributes := schema.Properties["attributes"]
+	if attributes.Type != "object" || attributes.AdditionalProperties.Type != "string" {
+		t.Errorf("attributes = %+v, want map of strings", attributes)
+	}
+}
+
+func TestSchemaForTimeFields(t *testing.T) {
+	schema := SchemaFor(orderPayload{})
+
+	created := schema.Properties["created_at"]
+	if created.Type != "string" || created.Format != "date-time" || created.Nullable {
+		t.Errorf("created_at = %+v, want non-nullable date-time string", created)
+	}
+
+	shipped := schema.Properties["shipped_at"]
+	if shipped.Type != "string" || shipped.Format != "date-time" || !shipped.Nullable {
+		t.Errorf("shipped_at = %+v, want nullable date-time string", shipped)
+	}
+}
+
+func TestSchemaForRecursiveType(t *testing.T) {
+	schema := SchemaFor(treeNode{})
+
+	children := schema.Properties["children"]
+	if children.Type != "array" || children.Items.Type != "object" {
+		t.Fatalf("children = %+v, want array of objects", children)
+	}
+	if children.Items.Properties != nil {
+		t.Errorf("recursive element must not expand properties, got %v", children.Items.Properties)
+	}
+}
+
+func TestValidatePayload(t *testing.T) {
+	schema := SchemaFor(orderPayload{})
+	now := time.Now()
+
+	valid := orderPayload{
+		ID:        1,
+		Address:   address{City: "Moscow"},
+		Items:     []orderItem{{SKU: "a", Quantity: 1, Price: 9.5}},
+		CreatedAt: now,
+		ShippedAt: &now,
+	}
+	if err := ValidatePayload(schema, valid); err != nil {
+		t.Fatalf("valid payload: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		payload interface{}
+		wantErr string
+	}{
+		{
+			name:    "missing required",
+			payload: map[string]interface{}{"id": 1, "address": map[string]interface{}{"city": "x"}, "items": nil},
+			wantErr: "payload.created_at: required property is missing",
+		},
+		{
+			name: "wrong nested type",
+			payload: map[string]interface{}{
+				"id": 1, "address": map[string]interface{}{"city": "x"}, "created_at": now,
+				"items": []interface{}{map[string]interface{}{"sku": "a", "quantity": 1.5, "price": 1}},
+			},
+			wantErr: "payload.items[0].quantity: expected integer",
+		},
+		{
+			name: "invalid time",
+			payload: map[string]interface{}{
+				"id": 1, "address": map[string]interface{}{"city": "x"}, "items": nil, "created_at": "yesterday",
+			},
+			wantErr: "payload.created_at: invalid date-time",
+		},
+		{
+			name: "unknown property",
+			payload: map[string]interface{}{
+				"id": 1, "address": map[string]interface{}{"city": "x"}, "items": nil, "created_at": now, "extra": true,
+			},
+			wantErr: "payload.extra: unknown property",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePayload(schema, tt.payload)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package eventcatalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ValidatePayload проверяет, что JSON-представление payload соответствует схеме
+func ValidatePayload(schema *Schema, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	return validateValue(schema, value, "payload")
+}
+
+// validateValue рекурсивно проверяет значение по схеме; path указывает место ошибки
+func validateValue(schema *Schema, value interface{}, path string) error {
+	if schema == nil || schema.Type == "" {
+		return nil
+	}
+
+	if value == nil {
+		if schema.Nullable {
+			return nil
+		}
+		return fmt.Errorf("%s: must not be null", path)
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return typeError(path, schema.Type, value)
+		}
+		return validateObject(schema, object, path)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return typeError(path, schema.Type, value)
+		}
+		for i, item := range items {
+			if err := validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return typeError(path, schema.Type, value)
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				return fmt.Errorf("%s: invalid date-time %q", path, str)
+			}
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return typeError(path, schema.Type, value)
+		}
+		if strings.ContainsAny(number.String(), ".eE") {
+			return fmt.Errorf("%s: expected integer, got %s", path, number)
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return typeError(path, schema.Type, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return typeError(path, schema.Type, value)
+		}
+	}
+
+	return nil
+}
+
+// validateObject проверяет обязательные, известные и дополнительные свойства объекта
+func validateObject(schema *Schema, object map[string]interface{}, path string) error {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s.%s: required property is missing", path, name)
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		property, known := schema.Properties[key]
+		switch {
+		case known:
+			if err := validateValue(property, object[key], path+"."+key); err != nil {
+				return err
+			}
+		case schema.AdditionalProperties != nil:
+			if err := validateValue(schema.AdditionalProperties, object[key], path+"."+key); err != nil {
+				return err
+			}
+		case schema.Properties != nil:
+			// Свойства, не описанные в контракте, считаются ошибкой
+			return fmt.Errorf("%s.%s: unknown property", path, key)
+		}
+	}
+
+	return nil
+}
+
+// typeError формирует ошибку несоответствия типа
+func typeError(path, expected string, value interface{}) error {
+	return fmt.Errorf("%s: expected %s, got %s", path, expected, jsonTypeName(value))
+}
+
+// jsonTypeName возвращает имя JSON-типа значения
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,32 @@
+// Пример сервиса на библиотеке common. Запуск инфраструктуры и сервиса описан в README.md.
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/vladzorgan/common/config"
+	"github.com/vladzorgan/common/examples/todo-service/todo"
+	"github.com/vladzorgan/common/logging"
+)
+
+func main() {
+	logger := logging.NewLogger()
+
+	cfg, err := config.LoadBaseConfig()
+	if err != nil {
+		logger.Error("Invalid configuration: %v", err)
+		os.Exit(1)
+	}
+
+	svc, err := todo.New(cfg, logger, nil)
+	if err != nil {
+		logger.Error("Failed to start todo-service: %v", err)
+		os.Exit(1)
+	}
+
+	if err := svc.Run(context.Background()); err != nil {
+		logger.Error("todo-service stopped with error: %v", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,29 @@
+package todo
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/streadway/amqp"
+	events "github.com/vladzorgan/common/messaging/rabbitmq"
+	"github.com/vladzorgan/common/service"
+)
+
+// handleTodoCreated учитывает созданную задачу в статистике и рассылает
+// инвалидацию кеша статистики всем экземплярам
+func (s *Service) handleTodoCreated(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+	var event service.EntityEvent
+	if err := json.Unmarshal(message, &event); err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to decode todo.created: %v", err)
+		return events.ErrRejectMessage
+	}
+
+	if _, err := s.redis.Incr(ctx, s.statsKey()); err != nil {
+		return err
+	}
+
+	// Ошибка рассылки не повод обрабатывать событие повторно: локальный кеш уже очищен,
+	// остальные экземпляры увидят новое значение по истечении TTL
+	s.bus.InvalidatePrefix(ctx, StatsEntity, "")
+	return nil
+}
@@ -0,0 +1,83 @@
+package todo
+
+import (
+	"context"
+
+	apperrors "github.com/vladzorgan/common/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// TodoServiceName полное имя gRPC сервиса задач
+const TodoServiceName = "todo.v1.TodoService"
+
+// TodoServiceServer gRPC сервис задач. Пример обходится без .proto файла: запросы и ответы
+// используют стандартные типы protobuf (wrapperspb, structpb).
+type TodoServiceServer interface {
+	GetTodo(ctx context.Context, id *wrapperspb.UInt64Value) (*structpb.Struct, error)
+}
+
+// TodoServiceDesc описание gRPC сервиса задач для grpc.Server.RegisterService
+var TodoServiceDesc = grpc.ServiceDesc{
+	ServiceName: TodoServiceName,
+	HandlerType: (*TodoServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTodo",
+			Handler:    getTodoHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "todo/v1/todo.proto",
+}
+
+func getTodoHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(wrapperspb.UInt64Value)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).GetTodo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + TodoServiceName + "/GetTodo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).GetTodo(ctx, req.(*wrapperspb.UInt64Value))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// grpcServer реализует TodoServiceServer поверх кешированного чтения сервиса
+type grpcServer struct {
+	service *Service
+}
+
+// GetTodo возвращает задачу по идентификатору
+func (g *grpcServer) GetTodo(ctx context.Context, id *wrapperspb.UInt64Value) (*structpb.Struct, error) {
+	todo, err := g.service.Get(ctx, uint(id.GetValue()))
+	if err != nil {
+		return nil, apperrors.ToGRPCError(err)
+	}
+
+	fields := map[string]interface{}{
+		"id":    float64(todo.ID),
+		"title": todo.Title,
+		"done":  todo.Done,
+	}
+	if todo.Note != nil {
+		fields["note"] = *todo.Note
+	}
+	return structpb.NewStruct(fields)
+}
+
+// GetTodo вызывает TodoService.GetTodo через соединение conn
+func GetTodo(ctx context.Context, conn grpc.ClientConnInterface, id uint, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	out := new(structpb.Struct)
+	if err := conn.Invoke(ctx, "/"+TodoServiceName+"/GetTodo", wrapperspb.UInt64(uint64(id)), out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,51 @@
+package todo
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/http/crud"
+	"github.com/vladzorgan/common/http/response"
+	"github.com/vladzorgan/common/service"
+)
+
+// registerRoutes регистрирует маршруты задач: стандартные CRUD через crud.Register,
+// статистику и чтение по ID через кеш экземпляра
+func (s *Service) registerRoutes(group *gin.RouterGroup) {
+	group.GET("/stats", s.stats)
+	group.GET("/:id", s.get)
+
+	crud.Register(group, s.todos, crud.Options[Todo]{
+		NewCreateInput: func() service.CreateInput[Todo] { return &CreateInput{} },
+		PatchSchema:    PatchSchema,
+		DefaultLimit:   s.cfg.DefaultPaginationLimit,
+		Disabled:       []crud.Route{crud.RouteGet},
+	})
+}
+
+func (s *Service) get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.ErrorWithStatus(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	todo, err := s.Get(c.Request.Context(), uint(id))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+func (s *Service) stats(c *gin.Context) {
+	stats, err := s.Stats(c.Request.Context())
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,99 @@
+package todo
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/vladzorgan/common/service"
+)
+
+// Todo задача списка дел
+type Todo struct {
+	ID        uint    `gorm:"primaryKey"`
+	Title     string  `gorm:"size:255;not null"`
+	Done      bool    `gorm:"not null;default:false"`
+	Note      *string `gorm:"type:text"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// GetID возвращает идентификатор задачи
+func (t Todo) GetID() uint { return t.ID }
+
+// GetName возвращает название задачи для логов и событий
+func (t Todo) GetName() string { return t.Title }
+
+// GetTableName возвращает имя таблицы
+func (Todo) GetTableName() string { return "todos" }
+
+// TableName возвращает имя таблицы для gorm
+func (Todo) TableName() string { return "todos" }
+
+// Response представление задачи в ответах HTTP и gRPC
+type Response struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Done      bool      `json:"done"`
+	Note      *string   `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Stats статистика задач, которую ведет потребитель событий todo.created
+type Stats struct {
+	Created int64 `json:"created"`
+}
+
+// CreateInput входные данные для создания задачи
+type CreateInput struct {
+	Title string  `json:"title"`
+	Note  *string `json:"note"`
+}
+
+// Validate проверяет входные данные
+func (i *CreateInput) Validate() error {
+	if strings.TrimSpace(i.Title) == "" {
+		return errors.New("title is required")
+	}
+	return nil
+}
+
+// ToEntity преобразует входные данные в сущность
+func (i *CreateInput) ToEntity() *Todo {
+	return &Todo{
+		Title: strings.TrimSpace(i.Title),
+		Note:  i.Note,
+	}
+}
+
+// PatchSchema поля задачи, изменяемые через PATCH (JSON Merge Patch)
+var PatchSchema = service.PatchSchema{
+	"title": {},
+	"done":  {},
+	"note":  {Nullable: true},
+}
+
+// Transformer преобразует задачи в ответы
+type Transformer struct{}
+
+// Transform преобразует задачу в ответ
+func (Transformer) Transform(entity *Todo) *Response {
+	return &Response{
+		ID:        entity.ID,
+		Title:     entity.Title,
+		Done:      entity.Done,
+		Note:      entity.Note,
+		CreatedAt: entity.CreatedAt,
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
+
+// TransformSlice преобразует список задач в ответы
+func (t Transformer) TransformSlice(entities []Todo) []Response {
+	responses := make([]Response, 0, len(entities))
+	for i := range entities {
+		responses = append(responses, *t.Transform(&entities[i]))
+	}
+	return responses
+}
@@ -0,0 +1,259 @@
+// Package todo реализует пример сервиса на библиотеке common: конфигурация, база данных,
+// репозиторий и сервис, HTTP и gRPC серверы, публикация и потребление событий,
+// согласованная инвалидация кешей между экземплярами и проверки здоровья.
+package todo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/app"
+	"github.com/vladzorgan/common/cache"
+	"github.com/vladzorgan/common/config"
+	"github.com/vladzorgan/common/database"
+	commongrpc "github.com/vladzorgan/common/grpc"
+	"github.com/vladzorgan/common/health"
+	commonhttp "github.com/vladzorgan/common/http"
+	"github.com/vladzorgan/common/logging"
+	events "github.com/vladzorgan/common/messaging/rabbitmq"
+	"github.com/vladzorgan/common/redis"
+	"github.com/vladzorgan/common/repository"
+	"github.com/vladzorgan/common/service"
+)
+
+const (
+	// ExchangeName обменник событий сервиса
+	ExchangeName = "todo"
+	// StatsEntity имя сущности кеша статистики в шине инвалидации
+	StatsEntity = "todo_stats"
+)
+
+// Options содержит опции экземпляра сервиса
+type Options struct {
+	// Идентификатор экземпляра; определяет имя очереди инвалидации кешей (по умолчанию имя хоста)
+	InstanceID string
+	// Время жизни записей кешей в памяти процесса
+	CacheTTL time.Duration
+	// Реестр метрик gRPC сервера (по умолчанию prometheus.DefaultRegisterer)
+	MetricsRegisterer prometheus.Registerer
+}
+
+// DefaultOptions возвращает опции по умолчанию
+func DefaultOptions() *Options {
+	instanceID, err := os.Hostname()
+	if err != nil {
+		instanceID = strconv.Itoa(os.Getpid())
+	}
+
+	return &Options{
+		InstanceID: instanceID,
+		CacheTTL:   10 * time.Minute,
+	}
+}
+
+// Service экземпляр todo-service со всеми компонентами
+type Service struct {
+	cfg    *config.BaseConfig
+	logger logging.Logger
+
+	db        *database.Database
+	redis     *redis.Client
+	publisher *events.Publisher
+	// Общая очередь событий сервиса: каждое событие обрабатывает один экземпляр
+	events *events.Consumer
+	// Очередь экземпляра: сообщения об инвалидации получают все экземпляры
+	invalidations *events.Consumer
+
+	todos      *service.BaseService[Todo, Response]
+	bus        *cache.Bus
+	todoCache  *cache.LocalCache[Response]
+	statsCache *cache.LocalCache[Stats]
+
+	http *commonhttp.Server
+	grpc *commongrpc.Server
+}
+
+// New подключается к хранилищам и брокеру и собирает экземпляр сервиса.
+// Готовность (/readiness) открывается после подписки потребителей.
+func New(cfg *config.BaseConfig, logger logging.Logger, options *Options) (*Service, error) {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	if options == nil {
+		options = DefaultOptions()
+	}
+
+	s := &Service{
+		cfg:        cfg,
+		logger:     logger,
+		todoCache:  cache.NewLocalCache[Response](options.CacheTTL),
+		statsCache: cache.NewLocalCache[Stats](options.CacheTTL),
+	}
+
+	var err error
+	s.db, err = database.NewDatabase(cfg.DatabaseURL, logger, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.AutoMigrate(&Todo{}); err != nil {
+		s.db.Close()
+		return nil, fmt.Errorf("failed to migrate todos: %w", err)
+	}
+
+	s.redis, err = redis.NewUniversalClient(cfg.RedisURL, cfg.RedisPassword, cfg.RedisDB, logger, nil)
+	if err != nil {
+		s.db.Close()
+		return nil, err
+	}
+
+	s.publisher, err = events.NewPublisher(cfg.RabbitMQURL, ExchangeName, cfg.ServiceName, logger)
+	if err != nil {
+		s.redis.Close()
+		s.db.Close()
+		return nil, err
+	}
+
+	// Записи кешей экземпляра очищаются по сообщениям остальных экземпляров
+	s.bus = cache.NewBus(cache.NewRabbitMQTransport(s.publisher), logger)
+	s.bus.Register("todo", s.todoCache)
+	s.bus.Register(StatsEntity, s.statsCache)
+
+	repo := repository.NewBaseRepository[Todo](s.db)
+	entity := service.EntityDescriptor{Singular: "todo", DisplayNameRu: "задача"}
+	s.todos = service.NewBaseServiceWithTx[Todo, Response](repo, Transformer{}, s.publisher, entity, s.db).
+		WithInvalidationBus(s.bus)
+
+	if err := s.subscribe(options.InstanceID); err != nil {
+		s.close()
+		return nil, err
+	}
+
+	s.http = commonhttp.NewServer(cfg, logger, &commonhttp.ServerOptions{
+		EnableMetrics: true,
+		EnableHealth:  true,
+		StartNotReady: true,
+		SkipLogPaths:  []string{"/metrics", "/health", "/readiness", "/liveness"},
+	})
+	s.http.RegisterHealthComponent(health.NewDatabaseComponent("database", s.db.GetDB(), true))
+	s.http.RegisterHealthComponent(health.NewRedisComponent("redis", s.redis.Client(), true))
+	s.http.RegisterHealthComponent(health.NewRabbitMQComponent("rabbitmq", cfg.RabbitMQURL, true))
+	s.registerRoutes(s.http.Group("/" + cfg.URLPrefix + "/v1/todos"))
+
+	grpcOptions := commongrpc.DefaultServerOptions(cfg)
+	grpcOptions.MetricsRegisterer = options.MetricsRegisterer
+	// Статусы gRPC health следуют за проверками компонентов и переключателем готовности
+	grpcOptions.HealthChecker = s.http.HealthChecker()
+	s.grpc = commongrpc.NewServer(cfg, logger, grpcOptions)
+	s.grpc.RegisterService(&TodoServiceDesc, &grpcServer{service: s})
+
+	s.http.HealthChecker().SetReady(true)
+	return s, nil
+}
+
+// subscribe создает очередь событий сервиса и очередь инвалидации экземпляра
+func (s *Service) subscribe(instanceID string) error {
+	var err error
+	s.events, err = events.NewConsumer(s.cfg.RabbitMQURL, ExchangeName, s.cfg.ServicePrefix+".events", s.cfg.ServiceName, s.logger, nil)
+	if err != nil {
+		return err
+	}
+	if err := s.events.Subscribe("todo.created", s.handleTodoCreated); err != nil {
+		return fmt.Errorf("failed to subscribe to todo.created: %w", err)
+	}
+
+	// Очередь экземпляра удаляется вместе с ним
+	spec := events.NewQueueSpec().Durable(false).AutoDelete(true)
+	queueName := fmt.Sprintf("%s.cache.%s", s.cfg.ServicePrefix, instanceID)
+	s.invalidations, err = events.NewConsumerWithSpec(s.cfg.RabbitMQURL, ExchangeName, queueName, s.cfg.ServiceName, s.logger, spec, nil)
+	if err != nil {
+		return err
+	}
+	if err := s.invalidations.Subscribe(cache.InvalidationRoutingKey, s.bus.ConsumerHandler()); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", cache.InvalidationRoutingKey, err)
+	}
+
+	return nil
+}
+
+// Todos возвращает сервис задач
+func (s *Service) Todos() *service.BaseService[Todo, Response] {
+	return s.todos
+}
+
+// Get возвращает задачу из кеша экземпляра или из базы данных
+func (s *Service) Get(ctx context.Context, id uint) (*Response, error) {
+	key := strconv.FormatUint(uint64(id), 10)
+	if cached, ok := s.todoCache.Get(key); ok {
+		return &cached, nil
+	}
+
+	response, err := s.todos.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.todoCache.Set(key, *response)
+	return response, nil
+}
+
+// Stats возвращает статистику из кеша экземпляра или из Redis
+func (s *Service) Stats(ctx context.Context) (*Stats, error) {
+	if cached, ok := s.statsCache.Get(""); ok {
+		return &cached, nil
+	}
+
+	created, err := s.redis.Get(ctx, s.statsKey())
+	if err != nil {
+		return nil, err
+	}
+
+	stats := Stats{}
+	if created != "" {
+		if stats.Created, err = strconv.ParseInt(created, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid stats value %q: %w", created, err)
+		}
+	}
+
+	s.statsCache.Set("", stats)
+	return &stats, nil
+}
+
+// statsKey ключ Redis счетчика созданных задач
+func (s *Service) statsKey() string {
+	return s.cfg.ServicePrefix + ":stats:created"
+}
+
+// Run запускает серверы и блокируется до отмены ctx или SIGINT/SIGTERM,
+// после чего останавливает компоненты и закрывает соединения
+func (s *Service) Run(ctx context.Context) error {
+	return app.New(s.cfg, s.logger).
+		WithCloser(s.db).
+		WithCloser(s.redis).
+		WithComponent("rabbitmq publisher", nil, func(ctx context.Context) error {
+			s.publisher.Close()
+			return nil
+		}).
+		WithConsumer(s.events).
+		WithConsumer(s.invalidations).
+		WithGRPC(s.grpc).
+		WithHTTP(s.http).
+		Run(ctx)
+}
+
+// close закрывает соединения собранного не до конца экземпляра
+func (s *Service) close() {
+	if s.events != nil {
+		s.events.Close()
+	}
+	if s.invalidations != nil {
+		s.invalidations.Close()
+	}
+	s.publisher.Close()
+	s.redis.Close()
+	s.db.Close()
+}
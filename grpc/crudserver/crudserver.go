@@ -0,0 +1,334 @@
+// Package crudserver содержит обобщенные обработчики gRPC методов Get/List/Create/Update/Delete/Search
+// поверх service.Service. Сгенерированный сервер делегирует им вызовы, а сам задает только
+// преобразования proto сообщений во входные данные сервиса и ответов сервиса в proto сообщения.
+package crudserver
+
+import (
+	"context"
+	stderrors "errors"
+	"sort"
+
+	"github.com/vladzorgan/common/config"
+	apperrors "github.com/vladzorgan/common/errors"
+	"github.com/vladzorgan/common/repository"
+	"github.com/vladzorgan/common/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// IDRequest запрос с идентификатором сущности (GetRegionRequest, DeleteRegionRequest и т.п.)
+type IDRequest interface {
+	GetId() uint32
+}
+
+// ListRequest запрос списка с пагинацией (GetRegionsRequest и т.п.)
+type ListRequest interface {
+	GetSkip() int32
+	GetLimit() int32
+}
+
+// SearchRequest запрос поиска по ключевому слову (SearchRegionsRequest и т.п.)
+type SearchRequest interface {
+	ListRequest
+	GetKeyword() string
+}
+
+// SortMessage сообщение параметров сортировки (общее сообщение SortOptions).
+// Сгенерированные методы доступа безопасны для nil, поэтому можно передавать req.GetSort() без проверки.
+type SortMessage interface {
+	GetField() string
+	GetOrder() string
+}
+
+// Options содержит преобразования сообщений и настройки пагинации.
+// T - сущность, R - ответ сервиса, P - proto ответ, C - proto запрос создания, U - proto запрос обновления.
+type Options[T service.BaseEntity, R any, P any, C any, U IDRequest] struct {
+	// Преобразование ответа сервиса в proto сообщение (обязательно)
+	ToProto func(response *R) P
+	// Преобразование запроса создания во входные данные сервиса; без него Create возвращает Unimplemented
+	CreateInput func(req C) service.CreateInput[T]
+	// Преобразование запроса обновления во входные данные сервиса; без него Update возвращает Unimplemented
+	UpdateInput func(req U) service.UpdateInput[T]
+
+	// Лимит страницы, если в запросе limit не задан, обычно config.BaseConfig.DefaultPaginationLimit
+	// (0 - значение DEFAULT_PAGINATION_LIMIT, см. config.PaginationLimit)
+	DefaultLimit int
+	// Максимальный лимит страницы (0 - без ограничения)
+	MaxLimit int
+}
+
+// Page представляет страницу списка proto сообщений
+type Page[P any] struct {
+	Items      []P
+	Pagination service.Pagination
+}
+
+// Handler реализует стандартные CRUD обработчики gRPC сервиса
+type Handler[T service.BaseEntity, R any, P any, C any, U IDRequest] struct {
+	svc  service.Service[T, R]
+	opts Options[T, R, P, C, U]
+}
+
+// New создает обработчики для сервиса svc. Пример для регионов:
+//
+//	regions := crudserver.New(regionService, crudserver.Options[models.Region, dto.Region, *pb.RegionResponse, *pb.CreateRegionRequest, *pb.UpdateRegionRequest]{
+//		ToProto:     toRegionProto,
+//		CreateInput: toCreateRegionInput,
+//		UpdateInput: toUpdateRegionInput,
+//	})
+//
+//	func (s *server) GetRegion(ctx context.Context, req *pb.GetRegionRequest) (*pb.RegionResponse, error) {
+//		return s.regions.Get(ctx, req)
+//	}
+func New[T service.BaseEntity, R any, P any, C any, U IDRequest](svc service.Service[T, R], opts Options[T, R, P, C, U]) *Handler[T, R, P, C, U] {
+	return &Handler[T, R, P, C, U]{svc: svc, opts: opts}
+}
+
+// Get возвращает сущность по ID
+func (h *Handler[T, R, P, C, U]) Get(ctx context.Context, req IDRequest) (P, error) {
+	var zero P
+	id, err := requestID(req)
+	if err != nil {
+		return zero, err
+	}
+
+	response, err := h.svc.GetByID(ctx, id)
+	if err != nil {
+		return zero, Error(err)
+	}
+	return h.opts.ToProto(response), nil
+}
+
+// Create создает сущность из запроса
+func (h *Handler[T, R, P, C, U]) Create(ctx context.Context, req C) (P, error) {
+	var zero P
+	if h.opts.CreateInput == nil {
+		return zero, status.Error(codes.Unimplemented, "create is not supported")
+	}
+
+	response, err := h.svc.Create(ctx, h.opts.CreateInput(req))
+	if err != nil {
+		return zero, Error(err)
+	}
+	return h.opts.ToProto(response), nil
+}
+
+// Update обновляет сущность с ID из запроса
+func (h *Handler[T, R, P, C, U]) Update(ctx context.Context, req U) (P, error) {
+	var zero P
+	if h.opts.UpdateInput == nil {
+		return zero, status.Error(codes.Unimplemented, "update is not supported")
+	}
+
+	id, err := requestID(req)
+	if err != nil {
+		return zero, err
+	}
+
+	response, err := h.svc.Update(ctx, id, h.opts.UpdateInput(req))
+	if err != nil {
+		return zero, Error(err)
+	}
+	return h.opts.ToProto(response), nil
+}
+
+// Delete удаляет сущность по ID и возвращает ее последнее состояние
+func (h *Handler[T, R, P, C, U]) Delete(ctx context.Context, req IDRequest) (P, error) {
+	var zero P
+	id, err := requestID(req)
+	if err != nil {
+		return zero, err
+	}
+
+	response, err := h.svc.Delete(ctx, id)
+	if err != nil {
+		return zero, Error(err)
+	}
+	return h.opts.ToProto(response), nil
+}
+
+// List возвращает страницу сущностей. Имена ключей filters подставляются в SQL репозиторием,
+// поэтому их нужно формировать из полей запроса, а не из произвольных данных клиента.
+func (h *Handler[T, R, P, C, U]) List(ctx context.Context, req ListRequest, sortMsg SortMessage, filters map[string]interface{}) (*Page[P], error) {
+	skip, limit, sortOpts, err := h.listParams(req, sortMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := h.svc.GetAll(ctx, skip, limit, filters, sortOpts)
+	if err != nil {
+		return nil, Error(err)
+	}
+	return h.page(response), nil
+}
+
+// Search возвращает страницу сущностей, найденных по ключевому слову запроса
+func (h *Handler[T, R, P, C, U]) Search(ctx context.Context, req SearchRequest, sortMsg SortMessage, filters map[string]interface{}) (*Page[P], error) {
+	skip, limit, sortOpts, err := h.listParams(req, sortMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := h.svc.Search(ctx, req.GetKeyword(), skip, limit, filters, sortOpts)
+	if err != nil {
+		return nil, Error(err)
+	}
+	return h.page(response), nil
+}
+
+// listParams проверяет пагинацию и сортировку запроса
+func (h *Handler[T, R, P, C, U]) listParams(req ListRequest, sortMsg SortMessage) (int, int, *repository.SortOptions, error) {
+	if req.GetSkip() < 0 {
+		return 0, 0, nil, invalidArgument("skip", "must not be negative")
+	}
+	if req.GetLimit() < 0 {
+		return 0, 0, nil, invalidArgument("limit", "must not be negative")
+	}
+
+	// В proto3 нулевое значение означает, что лимит не задан
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = h.opts.DefaultLimit
+		if limit <= 0 {
+			limit = config.PaginationLimit()
+		}
+	}
+	if h.opts.MaxLimit > 0 && limit > h.opts.MaxLimit {
+		limit = h.opts.MaxLimit
+	}
+
+	sortOpts, err := SortFromProto(sortMsg)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	return int(req.GetSkip()), limit, sortOpts, nil
+}
+
+// page преобразует ответ сервиса в страницу proto сообщений
+func (h *Handler[T, R, P, C, U]) page(response *service.PaginationResponse[R]) *Page[P] {
+	items := make([]P, 0, len(response.Items))
+	for i := range response.Items {
+		items = append(items, h.opts.ToProto(&response.Items[i]))
+	}
+	return &Page[P]{Items: items, Pagination: response.Pagination}
+}
+
+// SortFromProto преобразует сообщение сортировки в параметры репозитория.
+// Пустое поле означает сортировку по умолчанию; поле проверяется репозиторием по списку разрешенных полей.
+func SortFromProto(msg SortMessage) (*repository.SortOptions, error) {
+	if msg == nil || msg.GetField() == "" {
+		return nil, nil
+	}
+
+	order := msg.GetOrder()
+	switch order {
+	case "":
+		order = "asc"
+	case "asc", "desc":
+	default:
+		return nil, invalidArgument("sort.order", "must be asc or desc")
+	}
+
+	return &repository.SortOptions{Field: msg.GetField(), Order: order}, nil
+}
+
+// FillPagination заполняет поля total, page, size и pages сообщения пагинации (общее сообщение
+// PaginationResponse) и возвращает его:
+//
+//	Pagination: crudserver.FillPagination(&pb.PaginationResponse{}, page.Pagination)
+//
+// Поля сопоставляются по имени; отсутствующие в сообщении поля пропускаются. Если общее количество
+// записей не запрашивалось (repository.WithoutTotal), total и pages остаются незаполненными.
+func FillPagination[M proto.Message](msg M, pagination service.Pagination) M {
+	message := msg.ProtoReflect()
+	fields := message.Descriptor().Fields()
+
+	values := map[protoreflect.Name]int{
+		"page": pagination.Page,
+		"size": pagination.Size,
+	}
+	if pagination.HasTotal() {
+		values["total"] = pagination.Total
+		values["pages"] = pagination.Pages
+	}
+
+	for name, value := range values {
+		field := fields.ByName(name)
+		if field == nil {
+			continue
+		}
+
+		switch field.Kind() {
+		case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+			message.Set(field, protoreflect.ValueOfInt32(int32(value)))
+		case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+			message.Set(field, protoreflect.ValueOfInt64(int64(value)))
+		case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+			message.Set(field, protoreflect.ValueOfUint32(uint32(value)))
+		case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+			message.Set(field, protoreflect.ValueOfUint64(uint64(value)))
+		}
+	}
+
+	return msg
+}
+
+// Error преобразует ошибку сервиса в gRPC статус (см. apperrors.ToGRPCStatus).
+// Ошибки валидации по полям (apperrors.Error.Fields) передаются в деталях google.rpc.BadRequest.
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st := apperrors.ToGRPCStatus(err)
+
+	var typedErr *apperrors.Error
+	if !stderrors.As(err, &typedErr) || len(typedErr.Fields) == 0 {
+		return st.Err()
+	}
+
+	fields := make([]string, 0, len(typedErr.Fields))
+	for field := range typedErr.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: typedErr.Fields[field],
+		})
+	}
+
+	return withDetails(st, badRequest)
+}
+
+// requestID возвращает ID из запроса; нулевой ID считается ошибкой запроса
+func requestID(req IDRequest) (uint, error) {
+	if req.GetId() == 0 {
+		return 0, invalidArgument("id", "is required")
+	}
+	return uint(req.GetId()), nil
+}
+
+// invalidArgument возвращает ошибку InvalidArgument с деталями google.rpc.BadRequest для поля
+func invalidArgument(field, description string) error {
+	st := status.New(codes.InvalidArgument, "invalid "+field+": "+description)
+	return withDetails(st, &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: description}},
+	})
+}
+
+// withDetails добавляет детали к статусу; если сериализовать их не удалось, возвращает статус без деталей
+func withDetails(st *status.Status, details *errdetails.BadRequest) error {
+	detailed, err := st.WithDetails(details)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,255 @@
+package crudserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vladzorgan/common/config"
+	apperrors "github.com/vladzorgan/common/errors"
+	locationpb "github.com/vladzorgan/common/proto/location"
+	"github.com/vladzorgan/common/repository"
+	"github.com/vladzorgan/common/service"
+	"github.com/vladzorgan/common/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type region struct {
+	ID   uint
+	Name string
+	Code string
+}
+
+func (r region) GetID() uint          { return r.ID }
+func (r region) GetTableName() string { return "regions" }
+func (r region) GetName() string      { return r.Name }
+
+type regionInput struct {
+	name, code string
+}
+
+func (i *regionInput) ToEntity() *region { return &region{Name: i.name, Code: i.code} }
+func (i *regionInput) ToUpdateMap() map[string]interface{} {
+	return map[string]interface{}{"name": i.name}
+}
+func (i *regionInput) Validate() error {
+	if i.name == "" {
+		return validation.ValidationErrors{"name": "обязательное поле"}
+	}
+	return nil
+}
+
+// fakeService запоминает параметры вызовов
+type fakeService struct {
+	service.Service[region, region]
+
+	skip, limit int
+	filters     map[string]interface{}
+	sort        *repository.SortOptions
+	keyword     string
+}
+
+func (s *fakeService) GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) (*service.PaginationResponse[region], error) {
+	s.skip, s.limit, s.filters, s.sort = skip, limit, filters, sort
+	return &service.PaginationResponse[region]{
+		Items:      []region{{ID: 1, Name: "Москва"}, {ID: 2, Name: "Тверь"}},
+		Pagination: service.Pagination{Total: 12, Page: 2, Size: limit, Pages: 3},
+	}, nil
+}
+
+func (s *fakeService) Search(ctx context.Context, keyword string, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) (*service.PaginationResponse[region], error) {
+	s.keyword = keyword
+	return s.GetAll(ctx, skip, limit, filters, sort)
+}
+
+func (s *fakeService) GetByID(ctx context.Context, id uint, opts ...repository.QueryOption) (*region, error) {
+	if id != 1 {
+		return nil, apperrors.NotFound("region", id)
+	}
+	return &region{ID: 1, Name: "Москва"}, nil
+}
+
+func (s *fakeService) Create(ctx context.Context, input service.CreateInput[region]) (*region, error) {
+	if err := input.Validate(); err != nil {
+		typedErr := apperrors.Validation("region", err)
+		typedErr.Fields = map[string]string{"name": "обязательное поле"}
+		return nil, typedErr
+	}
+	entity := input.ToEntity()
+	entity.ID = 3
+	return entity, nil
+}
+
+func (s *fakeService) Update(ctx context.Context, id uint, input service.UpdateInput[region]) (*region, error) {
+	return &region{ID: id, Name: input.ToUpdateMap()["name"].(string)}, nil
+}
+
+func newHandler(svc *fakeService) *Handler[region, region, *locationpb.RegionResponse, *locationpb.CreateRegionRequest, *locationpb.UpdateRegionRequest] {
+	return New(svc, Options[region, region, *locationpb.RegionResponse, *locationpb.CreateRegionRequest, *locationpb.UpdateRegionRequest]{
+		ToProto: func(r *region) *locationpb.RegionResponse {
+			return &locationpb.RegionResponse{Id: uint32(r.ID), Name: r.Name, Code: r.Code}
+		},
+		CreateInput: func(req *locationpb.CreateRegionRequest) service.CreateInput[region] {
+			return &regionInput{name: req.GetName(), code: req.GetCode()}
+		},
+		UpdateInput: func(req *locationpb.UpdateRegionRequest) service.UpdateInput[region] {
+			return &regionInput{name: req.GetName()}
+		},
+		DefaultLimit: 20,
+		MaxLimit:     50,
+	})
+}
+
+func TestListMapsPaginationAndSort(t *testing.T) {
+	svc := &fakeService{}
+	h := newHandler(svc)
+
+	req := &locationpb.GetRegionsRequest{Skip: 10, Sort: &locationpb.SortOptions{Field: "name", Order: "desc"}}
+	page, err := h.List(context.Background(), req, req.GetSort(), map[string]interface{}{"country": "RU"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if svc.skip != 10 || svc.limit != 20 || svc.filters["country"] != "RU" {
+		t.Errorf("GetAll() skip = %d, limit = %d, filters = %v", svc.skip, svc.limit, svc.filters)
+	}
+	if svc.sort == nil || svc.sort.Field != "name" || svc.sort.Order != "desc" {
+		t.Errorf("GetAll() sort = %+v", svc.sort)
+	}
+	if len(page.Items) != 2 || page.Items[1].GetName() != "Тверь" {
+		t.Errorf("items = %v", page.Items)
+	}
+
+	pagination := FillPagination(&locationpb.PaginationResponse{}, page.Pagination)
+	if pagination.GetTotal() != 12 || pagination.GetPage() != 2 || pagination.GetSize() != 20 || pagination.GetPages() != 3 {
+		t.Errorf("pagination = %v", pagination)
+	}
+
+	// Без сортировки и с лимитом больше максимального
+	req = &locationpb.GetRegionsRequest{Limit: 500}
+	if _, err := h.List(context.Background(), req, req.GetSort(), nil); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if svc.sort != nil || svc.limit != 50 {
+		t.Errorf("GetAll() sort = %+v, limit = %d", svc.sort, svc.limit)
+	}
+}
+
+func TestListDefaultLimitFromConfig(t *testing.T) {
+	svc := &fakeService{}
+	h := New(svc, Options[region, region, *locationpb.RegionResponse, *locationpb.CreateRegionRequest, *locationpb.UpdateRegionRequest]{
+		ToProto: func(r *region) *locationpb.RegionResponse { return &locationpb.RegionResponse{Id: uint32(r.ID)} },
+	})
+	req := &locationpb.GetRegionsRequest{}
+
+	t.Setenv("DEFAULT_PAGINATION_LIMIT", "25")
+	if _, err := h.List(context.Background(), req, req.GetSort(), nil); err != nil || svc.limit != 25 {
+		t.Errorf("List() limit = %d, error = %v; want DEFAULT_PAGINATION_LIMIT 25", svc.limit, err)
+	}
+
+	t.Setenv("DEFAULT_PAGINATION_LIMIT", "")
+	if _, err := h.List(context.Background(), req, req.GetSort(), nil); err != nil || svc.limit != config.DefaultPaginationLimit {
+		t.Errorf("List() limit = %d, error = %v; want %d", svc.limit, err, config.DefaultPaginationLimit)
+	}
+}
+
+func TestFillPaginationWithoutTotal(t *testing.T) {
+	pagination := FillPagination(&locationpb.PaginationResponse{}, service.Pagination{Total: -1, Page: 3, Size: 20})
+	if pagination.GetTotal() != 0 || pagination.GetPages() != 0 || pagination.GetPage() != 3 || pagination.GetSize() != 20 {
+		t.Errorf("pagination = %v", pagination)
+	}
+}
+
+func TestSearchPassesKeyword(t *testing.T) {
+	svc := &fakeService{}
+	h := newHandler(svc)
+
+	req := &locationpb.SearchRegionsRequest{Keyword: "моск", Limit: 5}
+	if _, err := h.Search(context.Background(), req, req.GetSort(), nil); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if svc.keyword != "моск" || svc.limit != 5 {
+		t.Errorf("Search() keyword = %q, limit = %d", svc.keyword, svc.limit)
+	}
+}
+
+func TestInvalidListRequest(t *testing.T) {
+	h := newHandler(&fakeService{})
+
+	req := &locationpb.GetRegionsRequest{Sort: &locationpb.SortOptions{Field: "name", Order: "sideways"}}
+	_, err := h.List(context.Background(), req, req.GetSort(), nil)
+	if violations := fieldViolations(t, err, codes.InvalidArgument); violations["sort.order"] == "" {
+		t.Errorf("violations = %v", violations)
+	}
+
+	req = &locationpb.GetRegionsRequest{Skip: -1}
+	if _, err := h.List(context.Background(), req, req.GetSort(), nil); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("List() with negative skip code = %v", status.Code(err))
+	}
+}
+
+func TestGetAndUpdate(t *testing.T) {
+	h := newHandler(&fakeService{})
+
+	response, err := h.Get(context.Background(), &locationpb.GetRegionRequest{Id: 1})
+	if err != nil || response.GetName() != "Москва" {
+		t.Fatalf("Get() = %v, %v", response, err)
+	}
+
+	if _, err := h.Get(context.Background(), &locationpb.GetRegionRequest{Id: 7}); status.Code(err) != codes.NotFound {
+		t.Errorf("Get() missing code = %v, want NotFound", status.Code(err))
+	}
+	if _, err := h.Get(context.Background(), &locationpb.GetRegionRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Get() without id code = %v, want InvalidArgument", status.Code(err))
+	}
+
+	updated, err := h.Update(context.Background(), &locationpb.UpdateRegionRequest{Id: 4, Name: "Тула"})
+	if err != nil || updated.GetId() != 4 || updated.GetName() != "Тула" {
+		t.Errorf("Update() = %v, %v", updated, err)
+	}
+}
+
+func TestCreateValidationDetails(t *testing.T) {
+	h := newHandler(&fakeService{})
+
+	created, err := h.Create(context.Background(), &locationpb.CreateRegionRequest{Name: "Тула", Code: "71"})
+	if err != nil || created.GetId() != 3 || created.GetCode() != "71" {
+		t.Fatalf("Create() = %v, %v", created, err)
+	}
+
+	_, err = h.Create(context.Background(), &locationpb.CreateRegionRequest{})
+	if violations := fieldViolations(t, err, codes.InvalidArgument); violations["name"] != "обязательное поле" {
+		t.Errorf("violations = %v", violations)
+	}
+}
+
+func TestUnsupportedCreate(t *testing.T) {
+	h := New(&fakeService{}, Options[region, region, *locationpb.RegionResponse, *locationpb.CreateRegionRequest, *locationpb.UpdateRegionRequest]{
+		ToProto: func(r *region) *locationpb.RegionResponse { return &locationpb.RegionResponse{} },
+	})
+
+	if _, err := h.Create(context.Background(), &locationpb.CreateRegionRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Create() without converter code = %v, want Unimplemented", status.Code(err))
+	}
+}
+
+// fieldViolations проверяет код ошибки и возвращает нарушения из деталей google.rpc.BadRequest
+func fieldViolations(t *testing.T, err error, code codes.Code) map[string]string {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != code {
+		t.Fatalf("error = %v, want code %v", err, code)
+	}
+
+	violations := make(map[string]string)
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				violations[violation.GetField()] = violation.GetDescription()
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,185 @@
+// Package grpctest предоставляет тестовый gRPC сервер с той же цепочкой интерцепторов,
+// что и grpc.Server в продакшене, работающий поверх bufconn без реальных TCP-портов
+package grpctest
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/auth"
+	"github.com/vladzorgan/common/config"
+	commongrpc "github.com/vladzorgan/common/grpc"
+	"github.com/vladzorgan/common/grpc/interceptors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// bufSize размер буфера in-memory соединения
+const bufSize = 1024 * 1024
+
+// Options содержит опции тестового сервера
+type Options struct {
+	// Префикс метрик (по умолчанию "test")
+	ServicePrefix string
+	// Регистрация тестируемых сервисов
+	Register func(s *commongrpc.Server)
+	// Включить проверку API ключа (interceptors.AuthUnaryInterceptor)
+	APIKey string
+	// Методы, не требующие API ключа
+	APIKeyExcludedMethods []string
+	// Включить авторизацию пользователя (auth.AuthInterceptor) с фиктивным провайдером
+	EnableUserAuth bool
+	// Пользователи фиктивного провайдера; если не заданы, пользователь строится из метаданных
+	Users map[uint]*auth.User
+	// Методы, не требующие авторизации пользователя
+	UserAuthSkipMethods []string
+	// Обработчик паник, перехваченных интерцепторами восстановления
+	OnPanic interceptors.PanicHandler
+	// Дополнительные унарные интерцепторы, выполняемые после стандартных
+	UnaryInterceptors []grpc.UnaryServerInterceptor
+	// Базовая конфигурация (по умолчанию формируется автоматически)
+	Config *config.BaseConfig
+}
+
+// TestServer представляет запущенный тестовый сервер и подключенного к нему клиента
+type TestServer struct {
+	// Сервер с продакшен-цепочкой интерцепторов
+	Server *commongrpc.Server
+	// Клиентское соединение с сервером
+	Conn *grpc.ClientConn
+	// Приватный реестр метрик сервера
+	Registry *prometheus.Registry
+	// Префикс метрик сервера
+	ServicePrefix string
+}
+
+// NewTestServer создает и запускает тестовый сервер поверх bufconn.
+// Сервер и соединение закрываются через t.Cleanup.
+func NewTestServer(t testing.TB, opts *Options) *TestServer {
+	t.Helper()
+
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	cfg := opts.Config
+	if cfg == nil {
+		cfg = defaultConfig()
+	}
+	if opts.ServicePrefix != "" {
+		cfg.ServicePrefix = opts.ServicePrefix
+	}
+	if cfg.ServicePrefix == "" {
+		cfg.ServicePrefix = "test"
+	}
+
+	registry := prometheus.NewRegistry()
+
+	serverOptions := commongrpc.DefaultServerOptions(cfg)
+	serverOptions.EnableReflection = false
+	serverOptions.MetricsRegisterer = registry
+	serverOptions.AdditionalOptions = nil
+	serverOptions.AllowInsecure = true
+	serverOptions.OnPanic = opts.OnPanic
+
+	// Дополнительные интерцепторы выполняются после основной цепочки
+	var extra []grpc.UnaryServerInterceptor
+	if opts.APIKey != "" {
+		extra = append(extra, interceptors.AuthUnaryInterceptor(opts.APIKey, opts.APIKeyExcludedMethods))
+	}
+	if opts.EnableUserAuth {
+		var provider auth.UserProvider
+		if opts.Users != nil {
+			provider = &FakeUserProvider{Users: opts.Users}
+		}
+		extra = append(extra, auth.NewAuthInterceptor(provider, opts.UserAuthSkipMethods).UnaryInterceptor())
+	}
+	extra = append(extra, opts.UnaryInterceptors...)
+	if len(extra) > 0 {
+		serverOptions.AdditionalOptions = append(serverOptions.AdditionalOptions, grpc.ChainUnaryInterceptor(extra...))
+	}
+
+	server := commongrpc.NewServer(cfg, NewLogger(t), serverOptions)
+	if opts.Register != nil {
+		opts.Register(server)
+	}
+
+	listener := bufconn.Listen(bufSize)
+	go func() {
+		// Ошибка Serve после остановки сервера ожидаема
+		_ = server.Server().Serve(listener)
+	}()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpctest: failed to dial bufconn: %v", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Server().Stop()
+		listener.Close()
+	})
+
+	return &TestServer{
+		Server:        server,
+		Conn:          conn,
+		Registry:      registry,
+		ServicePrefix: cfg.ServicePrefix,
+	}
+}
+
+// defaultConfig возвращает минимальную конфигурацию для тестового сервера
+func defaultConfig() *config.BaseConfig {
+	return &config.BaseConfig{
+		ServiceName:          "test",
+		ServicePrefix:        "test",
+		Env:                  "test",
+		GRPCMaxRecvMsgSize:   4 * 1024 * 1024,
+		GRPCMaxSendMsgSize:   4 * 1024 * 1024,
+		GRPCKeepAliveTime:    30 * time.Second,
+		GRPCKeepAliveTimeout: 10 * time.Second,
+	}
+}
+
+// FakeUserProvider возвращает пользователей из заранее заданной карты
+type FakeUserProvider struct {
+	Users map[uint]*auth.User
+}
+
+// GetUserByID возвращает пользователя по ID или nil, если он не задан
+func (p *FakeUserProvider) GetUserByID(ctx context.Context, userID uint) (*auth.User, error) {
+	return p.Users[userID], nil
+}
+
+// WithUserID добавляет в исходящие метаданные ID пользователя
+func WithUserID(ctx context.Context, userID uint) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "user-id", strconv.FormatUint(uint64(userID), 10))
+}
+
+// WithUserRole добавляет в исходящие метаданные роль пользователя
+func WithUserRole(ctx context.Context, role auth.UserRole) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "user-role", string(role))
+}
+
+// WithRequestID добавляет в исходящие метаданные ID запроса
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "x-request-id", requestID)
+}
+
+// WithAPIKey добавляет в исходящие метаданные API ключ
+func WithAPIKey(ctx context.Context, apiKey string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "x-api-key", apiKey)
+}
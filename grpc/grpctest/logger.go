@@ -0,0 +1,93 @@
+package grpctest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/vladzorgan/common/logging"
+)
+
+// testLogger реализует logging.Logger, направляя вывод в лог теста
+type testLogger struct {
+	t      testing.TB
+	fields map[string]interface{}
+}
+
+// NewLogger создает логгер, пишущий сообщения через t.Logf
+func NewLogger(t testing.TB) logging.Logger {
+	return &testLogger{t: t, fields: make(map[string]interface{})}
+}
+
+func (l *testLogger) log(level, format string, v ...interface{}) {
+	l.t.Helper()
+
+	message := fmt.Sprintf(format, v...)
+	if len(l.fields) > 0 {
+		keys := make([]string, 0, len(l.fields))
+		for k := range l.fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, fmt.Sprintf("%s: %v", k, l.fields[k]))
+		}
+		message += " {" + strings.Join(parts, ", ") + "}"
+	}
+
+	l.t.Logf("[%s] %s", level, message)
+}
+
+// Debug логирует сообщение на уровне DEBUG
+func (l *testLogger) Debug(format string, v ...interface{}) { l.log("DEBUG", format, v...) }
+
+// Info логирует сообщение на уровне INFO
+func (l *testLogger) Info(format string, v ...interface{}) { l.log("INFO", format, v...) }
+
+// Warn логирует сообщение на уровне WARNING
+func (l *testLogger) Warn(format string, v ...interface{}) { l.log("WARN", format, v...) }
+
+// Error логирует сообщение на уровне ERROR
+func (l *testLogger) Error(format string, v ...interface{}) { l.log("ERROR", format, v...) }
+
+// Fatal логирует сообщение на уровне FATAL; тест при этом не прерывается
+func (l *testLogger) Fatal(format string, v ...interface{}) { l.log("FATAL", format, v...) }
+
+// WithField добавляет поле в логгер
+func (l *testLogger) WithField(key string, value interface{}) logging.Logger {
+	return l.WithFields(map[string]interface{}{key: value})
+}
+
+// WithFields добавляет несколько полей в логгер
+func (l *testLogger) WithFields(fields map[string]interface{}) logging.Logger {
+	newLogger := &testLogger{t: l.t, fields: make(map[string]interface{}, len(l.fields)+len(fields))}
+	for k, v := range l.fields {
+		newLogger.fields[k] = v
+	}
+	for k, v := range fields {
+		newLogger.fields[k] = v
+	}
+	return newLogger
+}
+
+// WithError добавляет ошибку в логгер
+func (l *testLogger) WithError(err error) logging.Logger {
+	return l.WithField("error", err.Error())
+}
+
+// WithContext добавляет ID запроса из контекста в логгер
+func (l *testLogger) WithContext(ctx context.Context) logging.Logger {
+	if requestID := logging.ExtractRequestID(ctx); requestID != "" {
+		return l.WithRequestID(requestID)
+	}
+	return l
+}
+
+// WithRequestID добавляет ID запроса в логгер
+func (l *testLogger) WithRequestID(requestID string) logging.Logger {
+	return l.WithField("request_id", requestID)
+}
@@ -0,0 +1,77 @@
+package grpctest
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// CounterValue возвращает значение счетчика name с указанными метками из реестра сервера.
+// Имя указывается без префикса сервиса, например "grpc_requests_total".
+func (s *TestServer) CounterValue(t testing.TB, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	metric := s.findMetric(t, name, labels)
+	if metric == nil || metric.GetCounter() == nil {
+		return 0
+	}
+	return metric.GetCounter().GetValue()
+}
+
+// HistogramCount возвращает количество наблюдений гистограммы name с указанными метками
+func (s *TestServer) HistogramCount(t testing.TB, name string, labels map[string]string) uint64 {
+	t.Helper()
+
+	metric := s.findMetric(t, name, labels)
+	if metric == nil || metric.GetHistogram() == nil {
+		return 0
+	}
+	return metric.GetHistogram().GetSampleCount()
+}
+
+// AssertCounter проверяет значение счетчика name с указанными метками
+func (s *TestServer) AssertCounter(t testing.TB, name string, labels map[string]string, want float64) {
+	t.Helper()
+
+	if got := s.CounterValue(t, name, labels); got != want {
+		t.Errorf("metric %s_%s%v = %v, want %v", s.ServicePrefix, name, labels, got, want)
+	}
+}
+
+// findMetric ищет метрику с совпадающими метками; метки, не указанные в labels, не учитываются
+func (s *TestServer) findMetric(t testing.TB, name string, labels map[string]string) *dto.Metric {
+	t.Helper()
+
+	families, err := s.Registry.Gather()
+	if err != nil {
+		t.Fatalf("grpctest: failed to gather metrics: %v", err)
+	}
+
+	fullName := s.ServicePrefix + "_" + name
+	for _, family := range families {
+		if family.GetName() != fullName {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if labelsMatch(metric, labels) {
+				return metric
+			}
+		}
+	}
+
+	return nil
+}
+
+// labelsMatch проверяет, что метрика содержит все указанные метки
+func labelsMatch(metric *dto.Metric, labels map[string]string) bool {
+	matched := 0
+	for _, pair := range metric.GetLabel() {
+		if want, ok := labels[pair.GetName()]; ok {
+			if pair.GetValue() != want {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched == len(labels)
+}
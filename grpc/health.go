@@ -0,0 +1,124 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	commonhealth "github.com/vladzorgan/common/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// defaultHealthCheckInterval интервал обновления статусов gRPC health по умолчанию
+const defaultHealthCheckInterval = 10 * time.Second
+
+// startHealthWatch запускает фоновое обновление статусов gRPC health по HealthChecker
+func (s *Server) startHealthWatch() {
+	if s.healthSrv == nil || s.healthChecker == nil {
+		return
+	}
+
+	s.healthStart.Do(func() {
+		interval := s.healthInterval
+		if interval <= 0 {
+			interval = defaultHealthCheckInterval
+		}
+
+		// Первое обновление выполняется сразу, до приема запросов
+		s.updateHealth(interval)
+		go s.watchHealth(interval)
+	})
+}
+
+// watchHealth периодически обновляет статусы до остановки сервера
+func (s *Server) watchHealth(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.updateHealth(interval)
+		case <-s.healthStop:
+			return
+		}
+	}
+}
+
+// updateHealth проверяет здоровье сервиса и устанавливает статусы зарегистрированных сервисов
+func (s *Server) updateHealth(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	check, err := s.healthChecker.Check(ctx)
+	if err != nil {
+		s.logger.Error("gRPC health check failed: %v", err)
+		return
+	}
+	ready, _ := s.healthChecker.Ready()
+
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+
+	select {
+	case <-s.healthStop:
+		// Сервер останавливается: статусы уже NOT_SERVING
+		return
+	default:
+	}
+
+	// Пустое имя - общий статус сервера
+	s.setServingStatus("", servingStatus(check, ready, nil))
+	for name := range s.serviceMap {
+		s.setServingStatus(name, servingStatus(check, ready, s.healthComponents[name]))
+	}
+}
+
+// setServingStatus устанавливает статус сервиса, если он изменился; вызывается под healthMu
+func (s *Server) setServingStatus(service string, status healthpb.HealthCheckResponse_ServingStatus) {
+	previous, known := s.healthStatuses[service]
+	if known && previous == status {
+		return
+	}
+	if known || status != healthpb.HealthCheckResponse_SERVING {
+		s.logger.Warn("gRPC health status of %q changed to %s", service, status)
+	}
+
+	s.healthStatuses[service] = status
+	s.healthSrv.SetServingStatus(service, status)
+}
+
+// shutdownHealth останавливает обновление статусов и переводит все сервисы в NOT_SERVING
+func (s *Server) shutdownHealth() {
+	s.healthStopOnce.Do(func() {
+		s.healthMu.Lock()
+		defer s.healthMu.Unlock()
+
+		close(s.healthStop)
+		if s.healthSrv != nil {
+			s.healthSrv.Shutdown()
+		}
+	})
+}
+
+// servingStatus определяет статус gRPC health по результату проверки. Сервис без списка компонентов
+// следует общему статусу, сервис с компонентами - состоянию этих компонентов.
+func servingStatus(check *commonhealth.HealthCheck, ready bool, components []string) healthpb.HealthCheckResponse_ServingStatus {
+	if !ready {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	if len(components) == 0 {
+		if check.Status == commonhealth.StatusDown {
+			return healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		return healthpb.HealthCheckResponse_SERVING
+	}
+
+	for _, name := range components {
+		result, ok := check.Components[name].(commonhealth.CheckResult)
+		if ok && result.Status == commonhealth.StatusDown {
+			return healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
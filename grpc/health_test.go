@@ -0,0 +1,121 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/config"
+	commonhealth "github.com/vladzorgan/common/health"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// switchComponent компонент проверки здоровья с переключаемым состоянием
+type switchComponent struct {
+	name     string
+	critical bool
+	down     atomic.Bool
+}
+
+func (c *switchComponent) Name() string { return c.name }
+
+func (c *switchComponent) Check(ctx context.Context) (commonhealth.Status, error) {
+	if c.down.Load() {
+		return commonhealth.StatusDown, errors.New("unavailable")
+	}
+	return commonhealth.StatusUp, nil
+}
+
+func (c *switchComponent) IsCritical() bool { return c.critical }
+
+// newHealthTestServer создает сервер с двумя сервисами; billing зависит только от компонента payments
+func newHealthTestServer(t *testing.T) (*Server, *commonhealth.Checker) {
+	t.Helper()
+
+	checker := commonhealth.NewChecker("health-test", "health_test", "test")
+	options := DefaultServerOptions(&config.BaseConfig{ServicePrefix: "health_test"})
+	options.MetricsRegisterer = prometheus.NewRegistry()
+	options.AllowInsecure = true
+	options.HealthChecker = checker
+	options.HealthComponents = map[string][]string{"test.Billing": {"payments"}}
+
+	server := NewServer(&config.BaseConfig{ServicePrefix: "health_test"}, nil, options)
+	server.RegisterService(&grpc.ServiceDesc{ServiceName: "test.Orders", HandlerType: (*interface{})(nil)}, struct{}{})
+	server.RegisterService(&grpc.ServiceDesc{ServiceName: "test.Billing", HandlerType: (*interface{})(nil)}, struct{}{})
+	return server, checker
+}
+
+func servingStatusOf(t *testing.T, server *Server, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+
+	resp, err := server.healthSrv.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("Check(%q) error = %v", service, err)
+	}
+	return resp.Status
+}
+
+func TestHealthStatusFollowsChecker(t *testing.T) {
+	server, checker := newHealthTestServer(t)
+
+	database := &switchComponent{name: "database", critical: true}
+	payments := &switchComponent{name: "payments"}
+	checker.RegisterComponent(database)
+	checker.RegisterComponent(payments)
+
+	want := func(overall, orders, billing healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		server.updateHealth(time.Second)
+		for service, status := range map[string]healthpb.HealthCheckResponse_ServingStatus{
+			"": overall, "test.Orders": orders, "test.Billing": billing,
+		} {
+			if got := servingStatusOf(t, server, service); got != status {
+				t.Errorf("status of %q = %s, want %s", service, got, status)
+			}
+		}
+	}
+
+	serving, notServing := healthpb.HealthCheckResponse_SERVING, healthpb.HealthCheckResponse_NOT_SERVING
+
+	want(serving, serving, serving)
+
+	// Критичный компонент недоступен: сервисы без своих компонентов не обслуживают запросы
+	database.down.Store(true)
+	want(notServing, notServing, serving)
+
+	// Восстановление
+	database.down.Store(false)
+	want(serving, serving, serving)
+
+	// Некритичный компонент влияет только на сервисы, которые от него зависят
+	payments.down.Store(true)
+	want(serving, serving, notServing)
+	payments.down.Store(false)
+
+	// Закрытый переключатель готовности переводит все сервисы в NOT_SERVING
+	checker.SetReady(false)
+	want(notServing, notServing, notServing)
+}
+
+func TestStopSetsNotServingFirst(t *testing.T) {
+	server, _ := newHealthTestServer(t)
+
+	server.Stop()
+
+	for _, service := range []string{"", "test.Orders", "test.Billing"} {
+		if got := servingStatusOf(t, server, service); got != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("status of %q after Stop = %s, want NOT_SERVING", service, got)
+		}
+	}
+
+	// Обновления после остановки игнорируются
+	server.updateHealth(time.Second)
+	if got := servingStatusOf(t, server, "test.Orders"); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("status after update = %s, want NOT_SERVING", got)
+	}
+}
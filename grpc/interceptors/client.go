@@ -0,0 +1,260 @@
+package interceptors
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/auth"
+	"github.com/vladzorgan/common/budget"
+	"github.com/vladzorgan/common/killswitch"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/metrics"
+	"github.com/vladzorgan/common/retry"
+	"github.com/vladzorgan/common/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Клиентские интерцепторы
+
+// RetryOptions содержит настройки повторных попыток клиентских вызовов
+type RetryOptions struct {
+	// Максимальное количество повторных попыток (0 - без повторов)
+	MaxRetries int
+	// Начальная задержка между попытками
+	Backoff time.Duration
+	// Максимальная задержка между попытками
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryOptions возвращает опции повторных попыток по умолчанию
+func DefaultRetryOptions() *RetryOptions {
+	return &RetryOptions{
+		MaxRetries: 3,
+		Backoff:    100 * time.Millisecond,
+		MaxBackoff: 2 * time.Second,
+	}
+}
+
+// withoutRetryCallOption отключает повторы интерцептора для одного вызова
+type withoutRetryCallOption struct {
+	grpc.EmptyCallOption
+}
+
+// WithoutRetry отключает повторы RetryUnaryClientInterceptor для вызова.
+// Используется, когда повторы выполняет вызывающий код (см. grpc_clients/clientcall).
+func WithoutRetry() grpc.CallOption {
+	return withoutRetryCallOption{}
+}
+
+// IsRetryable определяет, стоит ли повторять вызов при данной ошибке
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return true // Неизвестная ошибка - повторяем
+	}
+
+	switch st.Code() {
+	case codes.DeadlineExceeded,
+		codes.Unavailable,
+		codes.ResourceExhausted,
+		codes.Aborted,
+		codes.Internal:
+		return true
+	default:
+		return false
+	}
+}
+
+// DefaultUnaryClientInterceptors возвращает стандартный набор клиентских интерцепторов
+func DefaultUnaryClientInterceptors(logger logging.Logger, retryOptions *RetryOptions) []grpc.UnaryClientInterceptor {
+	return []grpc.UnaryClientInterceptor{
+		MetadataUnaryClientInterceptor(),
+		tracing.UnaryClientInterceptor(),
+		LoggingUnaryClientInterceptor(logger),
+		MetricsUnaryClientInterceptor(""),
+		RetryUnaryClientInterceptorWithLogger(retryOptions, logger),
+		budget.UnaryClientInterceptor(),
+	}
+}
+
+// RequestIDUnaryClientInterceptor создает интерцептор, передающий request ID в исходящие метаданные
+func RequestIDUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withOutgoingRequestID(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// withOutgoingRequestID добавляет request ID из контекста в исходящие метаданные, если его там нет
+func withOutgoingRequestID(ctx context.Context) context.Context {
+	requestID := logging.ExtractRequestID(ctx)
+	if requestID == "" {
+		return ctx
+	}
+
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get("x-request-id")) > 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, "x-request-id", requestID)
+}
+
+// MetadataUnaryClientInterceptor создает интерцептор, передающий request ID, user-id, user-role,
+// user-tenant-id и x-tenant-id из контекста в исходящие метаданные
+func MetadataUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(OutgoingContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// OutgoingContext добавляет request ID, user-id, user-role, user-tenant-id и x-tenant-id из контекста
+// в исходящие метаданные.
+// Уже заданные в исходящих метаданных значения не перезаписываются.
+func OutgoingContext(ctx context.Context) context.Context {
+	ctx = withOutgoingRequestID(ctx)
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+
+	if userID, err := auth.GetUserIDFromContext(ctx); err == nil && len(md.Get("user-id")) == 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, "user-id", strconv.FormatUint(uint64(userID), 10))
+	}
+
+	if userRole, err := auth.GetUserRoleFromContext(ctx); err == nil && userRole != "" && len(md.Get("user-role")) == 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, "user-role", string(userRole))
+	}
+
+	if user, err := auth.GetUserFromContext(ctx); err == nil && user.TenantID != 0 && len(md.Get(auth.UserTenantMetadataKey)) == 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, auth.UserTenantMetadataKey, strconv.FormatUint(uint64(user.TenantID), 10))
+	}
+
+	if tenantID, err := auth.GetTenantFromContext(ctx); err == nil && len(md.Get(auth.TenantMetadataKey)) == 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, auth.TenantMetadataKey, strconv.FormatUint(uint64(tenantID), 10))
+	}
+
+	return ctx
+}
+
+// LoggingUnaryClientInterceptor создает интерцептор для логирования исходящих вызовов
+func LoggingUnaryClientInterceptor(logger logging.Logger) grpc.UnaryClientInterceptor {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		startTime := time.Now()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		reqLogger := logger.WithContext(ctx).WithFields(map[string]interface{}{
+			"method":      method,
+			"target":      cc.Target(),
+			"duration_ms": time.Since(startTime).Milliseconds(),
+			"status":      status.Code(err).String(),
+		})
+
+		if err != nil {
+			reqLogger.Warn("gRPC client call failed: %v", err)
+		} else {
+			reqLogger.Debug("gRPC client call completed")
+		}
+
+		return err
+	}
+}
+
+// MetricsUnaryClientInterceptor создает интерцептор для сбора метрик исходящих вызовов
+func MetricsUnaryClientInterceptor(servicePrefix string) grpc.UnaryClientInterceptor {
+	return MetricsUnaryClientInterceptorWithRegisterer(servicePrefix, prometheus.DefaultRegisterer)
+}
+
+// MetricsUnaryClientInterceptorWithRegisterer создает интерцептор для сбора метрик исходящих вызовов,
+// регистрируя метрики в указанном реестре (nil - prometheus.DefaultRegisterer)
+func MetricsUnaryClientInterceptorWithRegisterer(servicePrefix string, registerer prometheus.Registerer) grpc.UnaryClientInterceptor {
+	requestDuration := metrics.Register(registerer, prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    metricName(servicePrefix, "grpc_client_request_duration_ms"),
+			Help:    "gRPC client request duration in milliseconds",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 15), // От 1мс до ~16с
+		},
+		[]string{"method", "status"},
+	))
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		startTime := time.Now()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		metrics.ObserveWithExemplar(ctx, requestDuration.WithLabelValues(method, status.Code(err).String()),
+			float64(time.Since(startTime).Milliseconds()))
+
+		return err
+	}
+}
+
+// RetryUnaryClientInterceptor создает интерцептор, повторяющий вызовы при временных ошибках.
+// Отключается выключателем killswitch.FeatureGRPCClientRetry.
+func RetryUnaryClientInterceptor(options *RetryOptions) grpc.UnaryClientInterceptor {
+	return RetryUnaryClientInterceptorWithLogger(options, nil)
+}
+
+// RetryUnaryClientInterceptorWithLogger аналогичен RetryUnaryClientInterceptor, но логирует
+// повторы, пропущенные из-за исчерпания бюджета запроса (см. пакет budget)
+func RetryUnaryClientInterceptorWithLogger(options *RetryOptions, logger logging.Logger) grpc.UnaryClientInterceptor {
+	if options == nil {
+		options = DefaultRetryOptions()
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if killswitch.IsDisabled(killswitch.FeatureGRPCClientRetry) || retryDisabled(opts) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		backoff := retry.NewBackoff(options.Backoff, options.MaxBackoff)
+
+		var err error
+		for attempt := 0; attempt <= options.MaxRetries; attempt++ {
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || !IsRetryable(err) || attempt == options.MaxRetries {
+				return err
+			}
+
+			// Не повторяем вызов, если исчерпан общий бюджет запроса
+			if !budget.AllowRetry(ctx, logger, method) {
+				return err
+			}
+
+			// Ждем перед следующей попыткой
+			if backoff.Wait(ctx) != nil {
+				return err
+			}
+		}
+
+		return err
+	}
+}
+
+// retryDisabled проверяет, передана ли опция WithoutRetry
+func retryDisabled(opts []grpc.CallOption) bool {
+	for _, opt := range opts {
+		if _, ok := opt.(withoutRetryCallOption); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// metricName формирует имя метрики с учетом префикса сервиса
+func metricName(servicePrefix, name string) string {
+	if servicePrefix == "" {
+		return name
+	}
+	return servicePrefix + "_" + name
+}
@@ -3,11 +3,11 @@ package interceptors
 
 import (
 	"context"
-	"runtime/debug"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/metrics"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -89,54 +89,31 @@ func LoggingUnaryInterceptor(logger logging.Logger) grpc.UnaryServerInterceptor
 	}
 }
 
-// RecoveryUnaryInterceptor создает интерцептор для восстановления после паники в унарных запросах
-func RecoveryUnaryInterceptor(logger logging.Logger) grpc.UnaryServerInterceptor {
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		defer func() {
-			if r := recover(); r != nil {
-				stackTrace := string(debug.Stack())
-
-				// Получаем request ID из контекста
-				requestID := logging.ExtractRequestID(ctx)
-
-				// Логируем панику
-				logger.WithRequestID(requestID).
-					WithField("method", info.FullMethod).
-					WithField("stack", stackTrace).
-					Error("Panic recovered in gRPC handler: %v", r)
-
-				// Возвращаем Internal Server Error
-				err := status.Errorf(codes.Internal, "Internal server error")
-				panic(err) // Перепаникуем с правильной gRPC ошибкой
-			}
-		}()
-
-		return handler(ctx, req)
-	}
-}
-
 // MetricsUnaryInterceptor создает интерцептор для сбора метрик унарных запросов
 func MetricsUnaryInterceptor(servicePrefix string) grpc.UnaryServerInterceptor {
-	// Создаем счетчики и гистограммы для метрик
-	requestsCounter := prometheus.NewCounterVec(
+	return MetricsUnaryInterceptorWithRegisterer(servicePrefix, prometheus.DefaultRegisterer)
+}
+
+// MetricsUnaryInterceptorWithRegisterer создает интерцептор для сбора метрик унарных запросов,
+// регистрируя метрики в указанном реестре (nil - prometheus.DefaultRegisterer)
+func MetricsUnaryInterceptorWithRegisterer(servicePrefix string, registerer prometheus.Registerer) grpc.UnaryServerInterceptor {
+	// Создаем счетчики и гистограммы для метрик; уже зарегистрированные переиспользуются
+	requestsCounter := metrics.Register(registerer, prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: servicePrefix + "_grpc_requests_total",
 			Help: "Total number of gRPC requests",
 		},
 		[]string{"method", "status"},
-	)
+	))
 
-	requestDuration := prometheus.NewHistogramVec(
+	requestDuration := metrics.Register(registerer, prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    servicePrefix + "_grpc_request_duration_ms",
 			Help:    "gRPC request duration in milliseconds",
 			Buckets: prometheus.ExponentialBuckets(1, 2, 15), // От 1мс до ~16с
 		},
 		[]string{"method", "status"},
-	)
-
-	// Регистрируем метрики
-	prometheus.MustRegister(requestsCounter, requestDuration)
+	))
 
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		startTime := time.Now()
@@ -149,7 +126,7 @@ func MetricsUnaryInterceptor(servicePrefix string) grpc.UnaryServerInterceptor {
 		statusCode := status.Code(err)
 
 		requestsCounter.WithLabelValues(info.FullMethod, statusCode.String()).Inc()
-		requestDuration.WithLabelValues(info.FullMethod, statusCode.String()).Observe(float64(duration.Milliseconds()))
+		metrics.ObserveWithExemplar(ctx, requestDuration.WithLabelValues(info.FullMethod, statusCode.String()), float64(duration.Milliseconds()))
 
 		return resp, err
 	}
@@ -263,55 +240,31 @@ func LoggingStreamInterceptor(logger logging.Logger) grpc.StreamServerIntercepto
 	}
 }
 
-// RecoveryStreamInterceptor создает интерцептор для восстановления после паники в потоковых запросах
-func RecoveryStreamInterceptor(logger logging.Logger) grpc.StreamServerInterceptor {
-	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		defer func() {
-			if r := recover(); r != nil {
-				stackTrace := string(debug.Stack())
-
-				// Получаем request ID из контекста
-				ctx := ss.Context()
-				requestID := logging.ExtractRequestID(ctx)
-
-				// Логируем панику
-				logger.WithRequestID(requestID).
-					WithField("method", info.FullMethod).
-					WithField("stack", stackTrace).
-					Error("Panic recovered in gRPC stream handler: %v", r)
-
-				// Возвращаем Internal Server Error
-				err := status.Errorf(codes.Internal, "Internal server error")
-				panic(err) // Перепаникуем с правильной gRPC ошибкой
-			}
-		}()
-
-		return handler(srv, ss)
-	}
-}
-
 // MetricsStreamInterceptor создает интерцептор для сбора метрик потоковых запросов
 func MetricsStreamInterceptor(servicePrefix string) grpc.StreamServerInterceptor {
-	// Создаем счетчики и гистограммы для метрик
-	streamsCounter := prometheus.NewCounterVec(
+	return MetricsStreamInterceptorWithRegisterer(servicePrefix, prometheus.DefaultRegisterer)
+}
+
+// MetricsStreamInterceptorWithRegisterer создает интерцептор для сбора метрик потоковых запросов,
+// регистрируя метрики в указанном реестре (nil - prometheus.DefaultRegisterer)
+func MetricsStreamInterceptorWithRegisterer(servicePrefix string, registerer prometheus.Registerer) grpc.StreamServerInterceptor {
+	// Создаем счетчики и гистограммы для метрик; уже зарегистрированные переиспользуются
+	streamsCounter := metrics.Register(registerer, prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: servicePrefix + "_grpc_streams_total",
 			Help: "Total number of gRPC streams",
 		},
 		[]string{"method", "stream_type", "status"},
-	)
+	))
 
-	streamDuration := prometheus.NewHistogramVec(
+	streamDuration := metrics.Register(registerer, prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    servicePrefix + "_grpc_stream_duration_ms",
 			Help:    "gRPC stream duration in milliseconds",
 			Buckets: prometheus.ExponentialBuckets(1, 2, 15), // От 1мс до ~16с
 		},
 		[]string{"method", "stream_type", "status"},
-	)
-
-	// Регистрируем метрики
-	prometheus.MustRegister(streamsCounter, streamDuration)
+	))
 
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		startTime := time.Now()
@@ -325,7 +278,7 @@ func MetricsStreamInterceptor(servicePrefix string) grpc.StreamServerInterceptor
 		statusCode := status.Code(err)
 
 		streamsCounter.WithLabelValues(info.FullMethod, streamType, statusCode.String()).Inc()
-		streamDuration.WithLabelValues(info.FullMethod, streamType, statusCode.String()).Observe(float64(duration.Milliseconds()))
+		metrics.ObserveWithExemplar(ss.Context(), streamDuration.WithLabelValues(info.FullMethod, streamType, statusCode.String()), float64(duration.Milliseconds()))
 
 		return err
 	}
@@ -0,0 +1,241 @@
+package interceptors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vladzorgan/common/auth"
+	commongrpc "github.com/vladzorgan/common/grpc"
+	"github.com/vladzorgan/common/grpc/grpctest"
+	"github.com/vladzorgan/common/grpc/interceptors"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const echoMethod = "/grpctest.Echo/Echo"
+
+// echoHandler обрабатывает тестовый запрос; возвращаемая строка уходит клиенту
+type echoHandler func(ctx context.Context, in string) (string, error)
+
+// registerEcho регистрирует тестовый сервис с единственным унарным методом
+func registerEcho(handler echoHandler) func(s *commongrpc.Server) {
+	desc := &grpc.ServiceDesc{
+		ServiceName: "grpctest.Echo",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Echo",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(wrapperspb.StringValue)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				call := func(ctx context.Context, req interface{}) (interface{}, error) {
+					out, err := handler(ctx, req.(*wrapperspb.StringValue).GetValue())
+					if err != nil {
+						return nil, err
+					}
+					return wrapperspb.String(out), nil
+				}
+				if interceptor == nil {
+					return call(ctx, in)
+				}
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: echoMethod}, call)
+			},
+		}},
+	}
+
+	return func(s *commongrpc.Server) {
+		s.RegisterService(desc, struct{}{})
+	}
+}
+
+// callEcho вызывает тестовый метод
+func callEcho(ctx context.Context, ts *grpctest.TestServer, in string) (string, error) {
+	out := new(wrapperspb.StringValue)
+	if err := ts.Conn.Invoke(ctx, echoMethod, wrapperspb.String(in), out); err != nil {
+		return "", err
+	}
+	return out.GetValue(), nil
+}
+
+func TestLoggingInterceptorPropagatesRequestID(t *testing.T) {
+	ts := grpctest.NewTestServer(t, &grpctest.Options{
+		Register: registerEcho(func(ctx context.Context, _ string) (string, error) {
+			return logging.ExtractRequestID(ctx), nil
+		}),
+	})
+
+	got, err := callEcho(grpctest.WithRequestID(context.Background(), "req-1"), ts, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "req-1" {
+		t.Errorf("request id = %q, want %q", got, "req-1")
+	}
+
+	got, err = callEcho(context.Background(), ts, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == "" {
+		t.Error("request id was not generated")
+	}
+}
+
+func TestMetricsInterceptorCountsByStatus(t *testing.T) {
+	ts := grpctest.NewTestServer(t, &grpctest.Options{
+		Register: registerEcho(func(_ context.Context, in string) (string, error) {
+			if in == "missing" {
+				return "", status.Error(codes.NotFound, "not found")
+			}
+			return in, nil
+		}),
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := callEcho(ctx, ts, "ok"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := callEcho(ctx, ts, "missing"); status.Code(err) != codes.NotFound {
+		t.Fatalf("code = %v, want %v", status.Code(err), codes.NotFound)
+	}
+
+	ts.AssertCounter(t, "grpc_requests_total", map[string]string{"method": echoMethod, "status": "OK"}, 2)
+	ts.AssertCounter(t, "grpc_requests_total", map[string]string{"method": echoMethod, "status": "NotFound"}, 1)
+
+	if got := ts.HistogramCount(t, "grpc_request_duration_ms", map[string]string{"method": echoMethod, "status": "OK"}); got != 2 {
+		t.Errorf("duration observations = %d, want 2", got)
+	}
+}
+
+func TestAuthInterceptorRequiresAPIKey(t *testing.T) {
+	ts := grpctest.NewTestServer(t, &grpctest.Options{
+		APIKey: "secret",
+		Register: registerEcho(func(_ context.Context, in string) (string, error) {
+			return in, nil
+		}),
+	})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{"missing key", context.Background(), codes.Unauthenticated},
+		{"invalid key", grpctest.WithAPIKey(context.Background(), "wrong"), codes.Unauthenticated},
+		{"valid key", grpctest.WithAPIKey(context.Background(), "secret"), codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := callEcho(tt.ctx, ts, "ping")
+			if status.Code(err) != tt.want {
+				t.Errorf("code = %v, want %v", status.Code(err), tt.want)
+			}
+		})
+	}
+
+	ts.AssertCounter(t, "grpc_requests_total", map[string]string{"method": echoMethod, "status": "Unauthenticated"}, 2)
+}
+
+func TestUserAuthInterceptorInjectsUser(t *testing.T) {
+	ts := grpctest.NewTestServer(t, &grpctest.Options{
+		EnableUserAuth: true,
+		Users: map[uint]*auth.User{
+			42: {ID: 42, Username: "alice", Role: auth.UserRole_Admin, IsActive: true},
+		},
+		Register: registerEcho(func(ctx context.Context, _ string) (string, error) {
+			user, err := auth.GetUserFromContext(ctx)
+			if err != nil {
+				return "", status.Error(codes.Internal, err.Error())
+			}
+			return user.Username, nil
+		}),
+	})
+
+	got, err := callEcho(grpctest.WithUserID(context.Background(), 42), ts, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "alice" {
+		t.Errorf("user = %q, want %q", got, "alice")
+	}
+
+	if _, err := callEcho(grpctest.WithUserID(context.Background(), 7), ts, ""); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("unknown user: code = %v, want %v", status.Code(err), codes.Unauthenticated)
+	}
+
+	if _, err := callEcho(context.Background(), ts, ""); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("anonymous: code = %v, want %v", status.Code(err), codes.Unauthenticated)
+	}
+}
+
+func TestMetricsInterceptorsReuseRegisteredCollectors(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	// Повторное создание интерцепторов с тем же реестром не должно вызывать панику
+	for i := 0; i < 2; i++ {
+		interceptors.MetricsUnaryInterceptorWithRegisterer("reuse", registry)
+		interceptors.MetricsStreamInterceptorWithRegisterer("reuse", registry)
+		interceptors.MetricsUnaryClientInterceptorWithRegisterer("reuse", registry)
+	}
+
+	unary := interceptors.MetricsUnaryInterceptorWithRegisterer("reuse", registry)
+	info := &grpc.UnaryServerInfo{FullMethod: echoMethod}
+	unary(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+
+	if got := testutil.CollectAndCount(registry, "reuse_grpc_requests_total"); got != 1 {
+		t.Errorf("reuse_grpc_requests_total series = %d, want 1", got)
+	}
+}
+
+func TestMetricsInterceptorExemplars(t *testing.T) {
+	t.Cleanup(func() { metrics.EnableExemplars(false) })
+
+	info := &grpc.UnaryServerInfo{FullMethod: echoMethod}
+	ctx := logging.ContextWithRequestID(context.Background(), "req-9")
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	for _, enabled := range []bool{false, true} {
+		metrics.EnableExemplars(enabled)
+		registry := prometheus.NewRegistry()
+		unary := interceptors.MetricsUnaryInterceptorWithRegisterer("exemplar", registry)
+		if _, err := unary(ctx, nil, info, handler); err != nil {
+			t.Fatal(err)
+		}
+
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var requestIDs []string
+		for _, family := range families {
+			if family.GetName() != "exemplar_grpc_request_duration_ms" {
+				continue
+			}
+			for _, bucket := range family.GetMetric()[0].GetHistogram().GetBucket() {
+				for _, label := range bucket.GetExemplar().GetLabel() {
+					if label.GetName() == metrics.ExemplarRequestIDLabel {
+						requestIDs = append(requestIDs, label.GetValue())
+					}
+				}
+			}
+		}
+
+		switch {
+		case !enabled && len(requestIDs) != 0:
+			t.Errorf("disabled: exemplars = %v, want none", requestIDs)
+		case enabled && (len(requestIDs) != 1 || requestIDs[0] != "req-9"):
+			t.Errorf("enabled: exemplars = %v, want [req-9]", requestIDs)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/vladzorgan/common/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// PayloadLoggingOptions содержит настройки логирования сообщений gRPC
+type PayloadLoggingOptions struct {
+	// Скрытие полей и ограничение размера. Пути полей задаются по именам из .proto
+	// (например, "user.password", "card.number").
+	logging.PayloadConfig
+	// Методы, сообщения которых логируются: полное имя ("/location.LocationService/GetCity")
+	// или сервис ("/location.LocationService/"). Пустой список - все методы.
+	Methods []string
+	// Методы, сообщения которых не логируются (формат как у Methods)
+	SkipMethods []string
+}
+
+// PayloadLoggingUnaryInterceptor создает интерцептор, логирующий запросы и ответы в JSON на уровне DEBUG.
+// Предназначен для отладки: включается явно, поля с персональными данными скрываются по RedactFields.
+func PayloadLoggingUnaryInterceptor(logger logging.Logger, options *PayloadLoggingOptions) grpc.UnaryServerInterceptor {
+	if options == nil {
+		options = &PayloadLoggingOptions{}
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !options.logs(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		resp, err := handler(ctx, req)
+
+		fields := map[string]interface{}{
+			"method":  info.FullMethod,
+			"status":  status.Code(err).String(),
+			"request": options.format(req),
+		}
+		if err == nil {
+			fields["response"] = options.format(resp)
+		}
+
+		logger.WithContext(ctx).WithFields(fields).Debug("gRPC payload")
+		return resp, err
+	}
+}
+
+// logs проверяет, нужно ли логировать сообщения метода
+func (o *PayloadLoggingOptions) logs(fullMethod string) bool {
+	if matchMethod(fullMethod, o.SkipMethods) {
+		return false
+	}
+	return len(o.Methods) == 0 || matchMethod(fullMethod, o.Methods)
+}
+
+// format сериализует сообщение в JSON и применяет PayloadConfig
+func (o *PayloadLoggingOptions) format(message interface{}) string {
+	if message == nil {
+		return ""
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if msg, ok := message.(proto.Message); ok {
+		data, err = protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	} else {
+		data, err = json.Marshal(message)
+	}
+	if err != nil {
+		return "<unserializable payload>"
+	}
+
+	return o.PayloadConfig.Format(data)
+}
+
+// matchMethod проверяет, соответствует ли метод одному из имен: полному имени метода или сервису с "/" на конце
+func matchMethod(fullMethod string, methods []string) bool {
+	for _, method := range methods {
+		if method == fullMethod || (strings.HasSuffix(method, "/") && strings.HasPrefix(fullMethod, method)) {
+			return true
+		}
+	}
+	return false
+}
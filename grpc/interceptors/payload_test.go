@@ -0,0 +1,125 @@
+package interceptors_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vladzorgan/common/grpc/interceptors"
+	"github.com/vladzorgan/common/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// fieldsLogger запоминает поля и сообщения DEBUG
+type fieldsLogger struct {
+	mu      *sync.Mutex
+	fields  map[string]interface{}
+	entries *[]map[string]interface{}
+}
+
+func newFieldsLogger() *fieldsLogger {
+	return &fieldsLogger{mu: &sync.Mutex{}, entries: &[]map[string]interface{}{}}
+}
+
+func (l *fieldsLogger) Debug(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	*l.entries = append(*l.entries, l.fields)
+}
+func (l *fieldsLogger) Info(string, ...interface{})  {}
+func (l *fieldsLogger) Warn(string, ...interface{})  {}
+func (l *fieldsLogger) Error(string, ...interface{}) {}
+func (l *fieldsLogger) Fatal(string, ...interface{}) {}
+
+func (l *fieldsLogger) WithField(key string, value interface{}) logging.Logger {
+	return l.WithFields(map[string]interface{}{key: value})
+}
+
+func (l *fieldsLogger) WithFields(fields map[string]interface{}) logging.Logger {
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &fieldsLogger{mu: l.mu, fields: merged, entries: l.entries}
+}
+
+func (l *fieldsLogger) WithError(error) logging.Logger             { return l }
+func (l *fieldsLogger) WithContext(context.Context) logging.Logger { return l }
+func (l *fieldsLogger) WithRequestID(string) logging.Logger        { return l }
+
+func (l *fieldsLogger) logged() []map[string]interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]map[string]interface{}(nil), *l.entries...)
+}
+
+func TestPayloadLoggingRedactsNestedFields(t *testing.T) {
+	logger := newFieldsLogger()
+	interceptor := interceptors.PayloadLoggingUnaryInterceptor(logger, &interceptors.PayloadLoggingOptions{
+		PayloadConfig: logging.PayloadConfig{RedactFields: []string{"user.password", "card.number"}},
+	})
+
+	req, _ := structpb.NewStruct(map[string]interface{}{
+		"user": map[string]interface{}{"login": "ivan", "password": "secret"},
+		"card": map[string]interface{}{"number": "4111111111111111"},
+	})
+	resp, _ := structpb.NewStruct(map[string]interface{}{"card": map[string]interface{}{"number": "4111", "last4": "1111"}})
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/billing.Billing/Pay"}
+	_, err := interceptor(context.Background(), req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return resp, nil
+	})
+	if err != nil {
+		t.Fatalf("interceptor error = %v", err)
+	}
+
+	entries := logger.logged()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(entries))
+	}
+
+	request, _ := entries[0]["request"].(string)
+	response, _ := entries[0]["response"].(string)
+	for _, secret := range []string{"secret", "4111"} {
+		if strings.Contains(request+response, secret) {
+			t.Errorf("payload leaks %q: request=%s response=%s", secret, request, response)
+		}
+	}
+	if !strings.Contains(request, `"login":"ivan"`) || !strings.Contains(response, `"last4":"1111"`) {
+		t.Errorf("non-redacted fields missing: request=%s response=%s", request, response)
+	}
+	if entries[0]["status"] != "OK" {
+		t.Errorf("status = %v, want OK", entries[0]["status"])
+	}
+}
+
+func TestPayloadLoggingMethodSelection(t *testing.T) {
+	logger := newFieldsLogger()
+	interceptor := interceptors.PayloadLoggingUnaryInterceptor(logger, &interceptors.PayloadLoggingOptions{
+		Methods:     []string{"/billing.Billing/"},
+		SkipMethods: []string{"/billing.Billing/Refund"},
+	})
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "missing")
+	}
+	for _, method := range []string{"/billing.Billing/Pay", "/billing.Billing/Refund", "/orders.Orders/Get"} {
+		interceptor(context.Background(), structpb.NewStringValue("x"), &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	}
+
+	entries := logger.logged()
+	if len(entries) != 1 || entries[0]["method"] != "/billing.Billing/Pay" {
+		t.Fatalf("logged entries = %v, want only /billing.Billing/Pay", entries)
+	}
+	if _, ok := entries[0]["response"]; ok || entries[0]["status"] != "NotFound" {
+		t.Errorf("failed call entry = %v, want status without response", entries[0])
+	}
+}
@@ -0,0 +1,118 @@
+package interceptors
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PanicHandler вызывается после восстановления после паники в обработчике gRPC метода method
+// (например, для отправки оповещения). recovered - значение, переданное в panic.
+type PanicHandler func(ctx context.Context, method string, recovered interface{}, stack []byte)
+
+// RecoveryOptions содержит настройки интерцепторов восстановления после паники
+type RecoveryOptions struct {
+	// Обработчик перехваченных паник
+	OnPanic PanicHandler
+	// Префикс метрики panics_recovered_total
+	ServicePrefix string
+	// Реестр метрики (nil - prometheus.DefaultRegisterer)
+	Registerer prometheus.Registerer
+}
+
+// recovery перехватывает панику обработчика и преобразует ее в ошибку codes.Internal
+type recovery struct {
+	logger  logging.Logger
+	onPanic PanicHandler
+	panics  *prometheus.CounterVec
+}
+
+// newRecovery создает обработчик паник с метрикой из options
+func newRecovery(logger logging.Logger, options *RecoveryOptions) *recovery {
+	if options == nil {
+		options = &RecoveryOptions{}
+	}
+
+	return &recovery{
+		logger:  logger,
+		onPanic: options.OnPanic,
+		panics:  metrics.PanicsRecovered(options.Registerer, options.ServicePrefix),
+	}
+}
+
+// handle логирует панику, увеличивает счетчик, вызывает OnPanic и возвращает ошибку для клиента
+func (r *recovery) handle(ctx context.Context, method string, recovered interface{}, message string) error {
+	stack := debug.Stack()
+
+	r.logger.WithRequestID(logging.ExtractRequestID(ctx)).
+		WithField("method", method).
+		WithField("stack", string(stack)).
+		Error("%s: %v", message, recovered)
+
+	r.panics.WithLabelValues(method).Inc()
+
+	if r.onPanic != nil {
+		r.callHook(ctx, method, recovered, stack)
+	}
+
+	return status.Errorf(codes.Internal, "Internal server error")
+}
+
+// callHook вызывает OnPanic; паника в самом обработчике не должна остановить сервер
+func (r *recovery) callHook(ctx context.Context, method string, recovered interface{}, stack []byte) {
+	defer func() {
+		if hookPanic := recover(); hookPanic != nil {
+			r.logger.WithField("method", method).Error("Panic in OnPanic handler: %v", hookPanic)
+		}
+	}()
+
+	r.onPanic(ctx, method, recovered, stack)
+}
+
+// RecoveryUnaryInterceptor создает интерцептор для восстановления после паники в унарных запросах
+func RecoveryUnaryInterceptor(logger logging.Logger) grpc.UnaryServerInterceptor {
+	return RecoveryUnaryInterceptorWithOptions(logger, nil)
+}
+
+// RecoveryUnaryInterceptorWithOptions создает интерцептор, который при панике в обработчике
+// возвращает клиенту codes.Internal вместо завершения процесса
+func RecoveryUnaryInterceptorWithOptions(logger logging.Logger, options *RecoveryOptions) grpc.UnaryServerInterceptor {
+	recovery := newRecovery(logger, options)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				resp, err = nil, recovery.handle(ctx, info.FullMethod, r, "Panic recovered in gRPC handler")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStreamInterceptor создает интерцептор для восстановления после паники в потоковых запросах
+func RecoveryStreamInterceptor(logger logging.Logger) grpc.StreamServerInterceptor {
+	return RecoveryStreamInterceptorWithOptions(logger, nil)
+}
+
+// RecoveryStreamInterceptorWithOptions создает интерцептор, который при панике в потоковом
+// обработчике завершает поток с codes.Internal вместо завершения процесса
+func RecoveryStreamInterceptorWithOptions(logger logging.Logger, options *RecoveryOptions) grpc.StreamServerInterceptor {
+	recovery := newRecovery(logger, options)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovery.handle(ss.Context(), info.FullMethod, r, "Panic recovered in gRPC stream handler")
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
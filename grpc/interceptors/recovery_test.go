@@ -0,0 +1,127 @@
+package interceptors_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/vladzorgan/common/grpc/grpctest"
+	"github.com/vladzorgan/common/grpc/interceptors"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recordedPanic паника, переданная в OnPanic
+type recordedPanic struct {
+	method    string
+	recovered interface{}
+	stack     []byte
+}
+
+// panicRecorder записывает вызовы OnPanic
+type panicRecorder struct {
+	mu     sync.Mutex
+	panics []recordedPanic
+}
+
+func (r *panicRecorder) OnPanic(ctx context.Context, method string, recovered interface{}, stack []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.panics = append(r.panics, recordedPanic{method, recovered, stack})
+}
+
+func (r *panicRecorder) recorded() []recordedPanic {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]recordedPanic(nil), r.panics...)
+}
+
+func TestRecoveryInterceptorReturnsInternal(t *testing.T) {
+	recorder := &panicRecorder{}
+	ts := grpctest.NewTestServer(t, &grpctest.Options{
+		OnPanic: recorder.OnPanic,
+		Register: registerEcho(func(_ context.Context, in string) (string, error) {
+			if in == "panic" {
+				panic("boom")
+			}
+			return in, nil
+		}),
+	})
+
+	ctx := context.Background()
+	if _, err := callEcho(ctx, ts, "panic"); status.Code(err) != codes.Internal {
+		t.Fatalf("code = %v, want %v", status.Code(err), codes.Internal)
+	}
+
+	// Сервер продолжает обслуживать запросы после паники
+	if out, err := callEcho(ctx, ts, "ok"); err != nil || out != "ok" {
+		t.Fatalf("callEcho() after panic = %q, %v", out, err)
+	}
+
+	ts.AssertCounter(t, "panics_recovered_total", map[string]string{"method": echoMethod}, 1)
+
+	panics := recorder.recorded()
+	if len(panics) != 1 {
+		t.Fatalf("OnPanic calls = %d, want 1", len(panics))
+	}
+	if panics[0].method != echoMethod || panics[0].recovered != "boom" || len(panics[0].stack) == 0 {
+		t.Errorf("OnPanic(%s, %v, stack %d bytes)", panics[0].method, panics[0].recovered, len(panics[0].stack))
+	}
+}
+
+// contextStream потоковое соединение, возвращающее только контекст
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
+
+func TestRecoveryStreamInterceptorReturnsInternal(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := &panicRecorder{}
+	interceptor := interceptors.RecoveryStreamInterceptorWithOptions(logging.NewLogger(), &interceptors.RecoveryOptions{
+		OnPanic:       recorder.OnPanic,
+		ServicePrefix: "stream",
+		Registerer:    registry,
+	})
+
+	info := &grpc.StreamServerInfo{FullMethod: "/grpctest.Echo/Stream"}
+	err := interceptor(nil, &contextStream{ctx: context.Background()}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		panic("stream boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("code = %v, want %v", status.Code(err), codes.Internal)
+	}
+
+	// Повторная регистрация возвращает счетчик интерцептора
+	panicsCounter := metrics.PanicsRecovered(registry, "stream")
+	if got := testutil.ToFloat64(panicsCounter.WithLabelValues(info.FullMethod)); got != 1 {
+		t.Errorf("stream_panics_recovered_total = %v, want 1", got)
+	}
+	if panics := recorder.recorded(); len(panics) != 1 || panics[0].recovered != "stream boom" {
+		t.Errorf("OnPanic calls = %+v", panics)
+	}
+}
+
+func TestRecoveryInterceptorSurvivesPanickingHook(t *testing.T) {
+	interceptor := interceptors.RecoveryUnaryInterceptorWithOptions(logging.NewLogger(), &interceptors.RecoveryOptions{
+		OnPanic: func(context.Context, string, interface{}, []byte) {
+			panic("hook boom")
+		},
+		Registerer: prometheus.NewRegistry(),
+	})
+
+	info := &grpc.UnaryServerInfo{FullMethod: echoMethod}
+	resp, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	if resp != nil || status.Code(err) != codes.Internal {
+		t.Errorf("interceptor() = %v, %v; want nil, Internal", resp, err)
+	}
+}
@@ -5,14 +5,20 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/budget"
 	"github.com/vladzorgan/common/config"
 	"github.com/vladzorgan/common/grpc/interceptors"
+	commonhealth "github.com/vladzorgan/common/health"
 	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/metrics"
+	"github.com/vladzorgan/common/profiling"
+	"github.com/vladzorgan/common/tracing"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
@@ -26,6 +32,18 @@ type Server struct {
 	logger     logging.Logger
 	healthSrv  *health.Server
 	serviceMap map[string]struct{}
+	// Ошибка настройки транспорта; сервер с ней не запускается
+	credsErr error
+
+	// Синхронизация статусов проверки здоровья с health.Checker (см. ServerOptions.HealthChecker)
+	healthChecker    *commonhealth.Checker
+	healthInterval   time.Duration
+	healthComponents map[string][]string
+	healthStatuses   map[string]healthpb.HealthCheckResponse_ServingStatus
+	healthMu         sync.Mutex
+	healthStart      sync.Once
+	healthStop       chan struct{}
+	healthStopOnce   sync.Once
 }
 
 // ServerOptions содержит опции для создания gRPC сервера
@@ -34,6 +52,14 @@ type ServerOptions struct {
 	EnableReflection bool
 	// Включить проверку здоровья
 	EnableHealth bool
+	// Проверка здоровья сервиса: если задана, статусы gRPC health обновляются по ее результатам
+	// (NOT_SERVING, когда сервис Down или переключатель готовности закрыт)
+	HealthChecker *commonhealth.Checker
+	// Интервал обновления статусов gRPC health по HealthChecker
+	HealthCheckInterval time.Duration
+	// Компоненты HealthChecker, от которых зависит статус отдельных сервисов (имя gRPC сервиса → имена компонентов).
+	// Такой сервис получает NOT_SERVING, если недоступен любой из его компонентов, независимо от общего статуса.
+	HealthComponents map[string][]string
 	// Максимальный размер сообщений для отправки
 	MaxSendMsgSize int
 	// Максимальный размер сообщений для приема
@@ -42,6 +68,23 @@ type ServerOptions struct {
 	KeepaliveParams keepalive.ServerParameters
 	// Политика keepalive
 	KeepalivePolicy keepalive.EnforcementPolicy
+	// Реестр метрик интерцепторов (по умолчанию prometheus.DefaultRegisterer)
+	MetricsRegisterer prometheus.Registerer
+	// Вызывается после восстановления после паники в обработчике (например, для оповещения);
+	// клиент в любом случае получает codes.Internal
+	OnPanic interceptors.PanicHandler
+
+	// Сертификат и ключ сервера в формате PEM; если заданы, сервер принимает только TLS соединения
+	CertFile string
+	KeyFile  string
+	// Удостоверяющий центр клиентских сертификатов (mTLS). Без RequireClientCert
+	// клиенты без сертификата допускаются, предъявленный сертификат проверяется.
+	ClientCAFile string
+	// Отклонять клиентов без сертификата, подписанного ClientCAFile
+	RequireClientCert bool
+	// Разрешить незащищенные соединения, если TLS не настроен. Иначе Start возвращает ErrInsecureNotAllowed.
+	AllowInsecure bool
+
 	// Дополнительные опции сервера
 	AdditionalOptions []grpc.ServerOption
 }
@@ -49,10 +92,11 @@ type ServerOptions struct {
 // DefaultServerOptions возвращает опции по умолчанию
 func DefaultServerOptions(cfg *config.BaseConfig) *ServerOptions {
 	return &ServerOptions{
-		EnableReflection: cfg.EnableReflection,
-		EnableHealth:     true,
-		MaxSendMsgSize:   cfg.GRPCMaxSendMsgSize,
-		MaxRecvMsgSize:   cfg.GRPCMaxRecvMsgSize,
+		EnableReflection:    cfg.EnableReflection,
+		EnableHealth:        true,
+		HealthCheckInterval: defaultHealthCheckInterval,
+		MaxSendMsgSize:      cfg.GRPCMaxSendMsgSize,
+		MaxRecvMsgSize:      cfg.GRPCMaxRecvMsgSize,
 		KeepaliveParams: keepalive.ServerParameters{
 			MaxConnectionIdle:     cfg.GRPCKeepAliveTime,
 			MaxConnectionAge:      cfg.GRPCKeepAliveTime * 2,
@@ -64,9 +108,11 @@ func DefaultServerOptions(cfg *config.BaseConfig) *ServerOptions {
 			MinTime:             5 * time.Second,
 			PermitWithoutStream: true,
 		},
-		AdditionalOptions: []grpc.ServerOption{
-			grpc.Creds(insecure.NewCredentials()), // Для разработки
-		},
+		CertFile:          cfg.GRPCTLSCertFile,
+		KeyFile:           cfg.GRPCTLSKeyFile,
+		ClientCAFile:      cfg.GRPCTLSClientCAFile,
+		RequireClientCert: cfg.GRPCTLSRequireClientCert,
+		AllowInsecure:     cfg.GRPCAllowInsecure,
 	}
 }
 
@@ -80,6 +126,12 @@ func NewServer(cfg *config.BaseConfig, logger logging.Logger, options *ServerOpt
 		options = DefaultServerOptions(cfg)
 	}
 
+	// Транспорт: TLS, если задан сертификат, иначе незащищенный, если он разрешен
+	creds, credsErr := serverCredentials(options)
+	if credsErr != nil {
+		logger.Error("gRPC server transport is not configured: %v", credsErr)
+	}
+
 	// Базовые опции сервера
 	serverOptions := []grpc.ServerOption{
 		grpc.MaxRecvMsgSize(options.MaxRecvMsgSize),
@@ -88,24 +140,59 @@ func NewServer(cfg *config.BaseConfig, logger logging.Logger, options *ServerOpt
 		grpc.KeepaliveEnforcementPolicy(options.KeepalivePolicy),
 	}
 
+	registerer := options.MetricsRegisterer
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	// Exemplar на гистограммах длительности (экспортируются обработчиком metrics.Handler)
+	metrics.EnableExemplars(cfg.MetricsExemplars)
+
+	// Метки pprof для профилей CPU
+	profiling.Enable(cfg.ProfilingLabels)
+
+	recoveryOptions := &interceptors.RecoveryOptions{
+		OnPanic:       options.OnPanic,
+		ServicePrefix: cfg.ServicePrefix,
+		Registerer:    registerer,
+	}
+
 	// Добавляем интерцепторы для унарных запросов
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		interceptors.LoggingUnaryInterceptor(logger),
+		tracing.UnaryServerInterceptor(),
+		profiling.UnaryServerInterceptor(),
+		interceptors.RecoveryUnaryInterceptorWithOptions(logger, recoveryOptions),
+		interceptors.MetricsUnaryInterceptorWithRegisterer(cfg.ServicePrefix, registerer),
+	}
+
+	// Инициализируем бюджет исходящих запросов
+	if cfg.RequestBudgetMaxRequests > 0 {
+		unaryInterceptors = append(unaryInterceptors, budget.UnaryServerInterceptor(&budget.Config{
+			MaxRequests: cfg.RequestBudgetMaxRequests,
+			MaxRetries:  cfg.RequestBudgetMaxRetries,
+		}))
+	}
+
 	serverOptions = append(serverOptions, grpc.UnaryInterceptor(
-		interceptors.ChainUnaryInterceptors(
-			interceptors.LoggingUnaryInterceptor(logger),
-			interceptors.RecoveryUnaryInterceptor(logger),
-			interceptors.MetricsUnaryInterceptor(cfg.ServicePrefix),
-		),
+		interceptors.ChainUnaryInterceptors(unaryInterceptors...),
 	))
 
 	// Добавляем интерцепторы для потоковых запросов
 	serverOptions = append(serverOptions, grpc.StreamInterceptor(
 		interceptors.ChainStreamInterceptors(
 			interceptors.LoggingStreamInterceptor(logger),
-			interceptors.RecoveryStreamInterceptor(logger),
-			interceptors.MetricsStreamInterceptor(cfg.ServicePrefix),
+			tracing.StreamServerInterceptor(),
+			profiling.StreamServerInterceptor(),
+			interceptors.RecoveryStreamInterceptorWithOptions(logger, recoveryOptions),
+			interceptors.MetricsStreamInterceptorWithRegisterer(cfg.ServicePrefix, registerer),
 		),
 	))
 
+	if creds != nil {
+		serverOptions = append(serverOptions, grpc.Creds(creds))
+	}
+
 	// Добавляем дополнительные опции
 	serverOptions = append(serverOptions, options.AdditionalOptions...)
 
@@ -114,10 +201,16 @@ func NewServer(cfg *config.BaseConfig, logger logging.Logger, options *ServerOpt
 
 	// Создаем сервер
 	server := &Server{
-		server:     grpcServer,
-		config:     cfg,
-		logger:     logger,
-		serviceMap: make(map[string]struct{}),
+		server:           grpcServer,
+		config:           cfg,
+		logger:           logger,
+		serviceMap:       make(map[string]struct{}),
+		credsErr:         credsErr,
+		healthChecker:    options.HealthChecker,
+		healthInterval:   options.HealthCheckInterval,
+		healthComponents: options.HealthComponents,
+		healthStatuses:   make(map[string]healthpb.HealthCheckResponse_ServingStatus),
+		healthStop:       make(chan struct{}),
 	}
 
 	// Включаем отражение для gRPC, если нужно
@@ -140,13 +233,20 @@ func (s *Server) RegisterService(desc *grpc.ServiceDesc, impl interface{}) {
 
 	// Регистрируем сервис для проверки здоровья
 	if s.healthSrv != nil {
+		s.healthMu.Lock()
 		s.healthSrv.SetServingStatus(desc.ServiceName, healthpb.HealthCheckResponse_SERVING)
 		s.serviceMap[desc.ServiceName] = struct{}{}
+		s.healthMu.Unlock()
 	}
 }
 
-// Start запускает gRPC сервер
+// Start запускает gRPC сервер. Если транспорт не настроен (ошибка загрузки сертификатов
+// или TLS не задан при AllowInsecure = false), сервер не запускается.
 func (s *Server) Start() error {
+	if s.credsErr != nil {
+		return fmt.Errorf("failed to configure gRPC transport: %w", s.credsErr)
+	}
+
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", s.config.GRPCPort))
 	if err != nil {
 		return fmt.Errorf("failed to listen on port %s: %v", s.config.GRPCPort, err)
@@ -154,6 +254,9 @@ func (s *Server) Start() error {
 
 	s.logger.Info("gRPC server is starting on port %s", s.config.GRPCPort)
 
+	// Статусы проверки здоровья следуют за health.Checker
+	s.startHealthWatch()
+
 	// Логируем зарегистрированные сервисы
 	for serviceName := range s.serviceMap {
 		s.logger.Info("Registered gRPC service: %s", serviceName)
@@ -175,16 +278,19 @@ func (s *Server) StartAsync() {
 	}()
 }
 
-// Stop останавливает gRPC сервер
+// Stop останавливает gRPC сервер. Сначала все сервисы получают статус NOT_SERVING,
+// чтобы балансировщики клиентов перестали направлять на экземпляр новые запросы.
 func (s *Server) Stop() {
 	s.logger.Info("Stopping gRPC server...")
+	s.shutdownHealth()
 	s.server.GracefulStop()
 	s.logger.Info("gRPC server stopped")
 }
 
-// StopWithContext останавливает gRPC сервер с контекстом
+// StopWithContext останавливает gRPC сервер с контекстом, предварительно переводя сервисы в NOT_SERVING
 func (s *Server) StopWithContext(ctx context.Context) {
 	s.logger.Info("Stopping gRPC server with context...")
+	s.shutdownHealth()
 
 	// Создаем канал для сигнализации о завершении GracefulStop
 	stopped := make(chan struct{})
@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ErrInsecureNotAllowed возвращается Start, если TLS не настроен, а незащищенные соединения запрещены
+var ErrInsecureNotAllowed = errors.New("gRPC TLS is not configured and insecure connections are not allowed")
+
+// TLSEnabled возвращает true, если задан сертификат сервера
+func (o *ServerOptions) TLSEnabled() bool {
+	return o.CertFile != "" || o.KeyFile != ""
+}
+
+// serverCredentials возвращает транспортные учетные данные сервера:
+// TLS (mTLS при заданном ClientCAFile), если задан сертификат, иначе незащищенные, если они разрешены
+func serverCredentials(options *ServerOptions) (credentials.TransportCredentials, error) {
+	if !options.TLSEnabled() {
+		if !options.AllowInsecure {
+			return nil, ErrInsecureNotAllowed
+		}
+		return insecure.NewCredentials(), nil
+	}
+
+	if options.CertFile == "" || options.KeyFile == "" {
+		return nil, errors.New("gRPC TLS requires both CertFile and KeyFile")
+	}
+
+	certificate, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gRPC server certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if options.ClientCAFile != "" {
+		pool, err := loadCertPool(options.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		// Без RequireClientCert клиенты без сертификата допускаются, что позволяет
+		// включать mTLS на клиентах постепенно
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	if options.RequireClientCert {
+		if tlsConfig.ClientCAs == nil {
+			return nil, errors.New("gRPC RequireClientCert requires ClientCAFile")
+		}
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// loadCertPool читает PEM сертификаты удостоверяющего центра
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	return pool, nil
+}
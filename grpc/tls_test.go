@@ -0,0 +1,246 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/config"
+	"github.com/vladzorgan/common/grpc_clients"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// testPKI файлы удостоверяющего центра, сертификата сервера и клиента
+type testPKI struct {
+	caFile, serverCert, serverKey, clientCert, clientKey string
+}
+
+// newTestPKI выпускает во временном каталоге CA и подписанные им сертификаты сервера (localhost) и клиента
+func newTestPKI(t *testing.T) testPKI {
+	t.Helper()
+	dir := t.TempDir()
+
+	write := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	issue := func(name string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     []string{name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatalf("failed to issue %s certificate: %v", name, err)
+		}
+		keyDER, _ := x509.MarshalECPrivateKey(key)
+		return write(name+".crt", "CERTIFICATE", der), write(name+".key", "EC PRIVATE KEY", keyDER)
+	}
+
+	pki := testPKI{caFile: write("ca.crt", "CERTIFICATE", caDER)}
+	pki.serverCert, pki.serverKey = issue("localhost", 2, x509.ExtKeyUsageServerAuth)
+	pki.clientCert, pki.clientKey = issue("client", 3, x509.ExtKeyUsageClientAuth)
+	return pki
+}
+
+// startTLSServer запускает сервер с проверкой здоровья на свободном порту
+func startTLSServer(t *testing.T, configure func(options *ServerOptions)) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	cfg := &config.BaseConfig{
+		ServicePrefix:        "tls_test",
+		GRPCPort:             strconv.Itoa(port),
+		GRPCMaxRecvMsgSize:   4 * 1024 * 1024,
+		GRPCMaxSendMsgSize:   4 * 1024 * 1024,
+		GRPCKeepAliveTime:    30 * time.Second,
+		GRPCKeepAliveTimeout: 10 * time.Second,
+	}
+	options := DefaultServerOptions(cfg)
+	options.MetricsRegisterer = prometheus.NewRegistry()
+	configure(options)
+
+	addr := "localhost:" + cfg.GRPCPort
+	server := NewServer(cfg, nil, options)
+	errs := make(chan error, 1)
+	go func() { errs <- server.Start() }()
+	t.Cleanup(server.Stop)
+
+	// Дожидаемся начала приема соединений
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		select {
+		case err := <-errs:
+			t.Fatalf("Start() error = %v", err)
+		default:
+		}
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			return addr
+		}
+	}
+	t.Fatal("server did not start")
+	return ""
+}
+
+// checkHealth вызывает проверку здоровья через соединение с заданными опциями клиента
+func checkHealth(t *testing.T, addr string, opts ...grpc_clients.OptionFunc) error {
+	t.Helper()
+
+	host, port, _ := net.SplitHostPort(addr)
+	opts = append([]grpc_clients.OptionFunc{
+		grpc_clients.WithLogging(false),
+		grpc_clients.WithRetryOptions(nil),
+		grpc_clients.WithConnectTimeout(time.Second),
+	}, opts...)
+
+	client, err := grpc_clients.NewBaseClientWithOptions(nil, host, "tls-test", port, opts...)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(client.Conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestServerTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := startTLSServer(t, func(options *ServerOptions) {
+		options.CertFile = pki.serverCert
+		options.KeyFile = pki.serverKey
+	})
+
+	if err := checkHealth(t, addr, grpc_clients.WithTLS(pki.caFile, "localhost")); err != nil {
+		t.Fatalf("TLS client error = %v", err)
+	}
+	if err := checkHealth(t, addr); err == nil {
+		t.Error("insecure client must be rejected by TLS server")
+	}
+	if err := checkHealth(t, addr, grpc_clients.WithTLS(pki.caFile, "other-host")); err == nil {
+		t.Error("server name mismatch must be rejected")
+	}
+}
+
+func TestServerMutualTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := startTLSServer(t, func(options *ServerOptions) {
+		options.CertFile = pki.serverCert
+		options.KeyFile = pki.serverKey
+		options.ClientCAFile = pki.caFile
+		options.RequireClientCert = true
+	})
+
+	if err := checkHealth(t, addr, grpc_clients.WithTLS(pki.caFile, "localhost")); err == nil {
+		t.Error("client without certificate must be rejected")
+	}
+	err := checkHealth(t, addr,
+		grpc_clients.WithTLS(pki.caFile, "localhost"),
+		grpc_clients.WithClientCertificate(pki.clientCert, pki.clientKey),
+	)
+	if err != nil {
+		t.Fatalf("mTLS client error = %v", err)
+	}
+}
+
+func TestServerFromDefaultConfigRequiresTLS(t *testing.T) {
+	for _, key := range []string{"GRPC_ALLOW_INSECURE", "GRPC_TLS_CERT_FILE", "GRPC_TLS_KEY_FILE"} {
+		t.Setenv(key, "")
+	}
+	cfg, err := config.LoadBaseConfig(config.WithValues(map[string]string{
+		"SERVICE_NAME":   "tls_default",
+		"SERVICE_PREFIX": "tls_default",
+		"GRPC_PORT":      "0",
+	}))
+	if err != nil {
+		t.Fatalf("LoadBaseConfig() error = %v", err)
+	}
+
+	// Без TLS и без GRPC_ALLOW_INSECURE сервер не запускается
+	options := DefaultServerOptions(cfg)
+	options.MetricsRegisterer = prometheus.NewRegistry()
+	if err := NewServer(cfg, nil, options).Start(); !errors.Is(err, ErrInsecureNotAllowed) {
+		t.Errorf("Start() error = %v, want ErrInsecureNotAllowed", err)
+	}
+}
+
+func TestServerInsecureRequiresOptIn(t *testing.T) {
+	cfg := &config.BaseConfig{ServicePrefix: "tls_insecure", GRPCPort: "0"}
+
+	options := DefaultServerOptions(cfg)
+	options.MetricsRegisterer = prometheus.NewRegistry()
+	if err := NewServer(cfg, nil, options).Start(); !errors.Is(err, ErrInsecureNotAllowed) {
+		t.Errorf("Start() error = %v, want ErrInsecureNotAllowed", err)
+	}
+
+	options.MetricsRegisterer = prometheus.NewRegistry()
+	options.CertFile, options.KeyFile = "missing.crt", "missing.key"
+	if err := NewServer(cfg, nil, options).Start(); err == nil {
+		t.Error("Start() with unreadable certificate must fail")
+	}
+
+	// Явно разрешенный незащищенный сервер доступен обычным клиентам
+	addr := startTLSServer(t, func(options *ServerOptions) {
+		options.AllowInsecure = true
+	})
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("health check error = %v", err)
+	}
+}
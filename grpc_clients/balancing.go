@@ -0,0 +1,80 @@
+package grpc_clients
+
+import (
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+// Политики балансировки нагрузки между адресами сервиса
+const (
+	// LoadBalancingRoundRobin распределяет вызовы по всем адресам по очереди (по умолчанию)
+	LoadBalancingRoundRobin = "round_robin"
+	// LoadBalancingPickFirst использует первый доступный адрес
+	LoadBalancingPickFirst = "pick_first"
+)
+
+// staticResolverScheme схема резолвера для явного списка адресов
+const staticResolverScheme = "static"
+
+// WithLoadBalancing задает политику балансировки (LoadBalancingRoundRobin, LoadBalancingPickFirst)
+func WithLoadBalancing(policy string) OptionFunc {
+	return func(o *ClientOptions) {
+		o.LoadBalancing = policy
+	}
+}
+
+// WithAddresses задает явный список адресов сервиса (host:port) вместо разрешения имени через DNS
+func WithAddresses(addresses ...string) OptionFunc {
+	return func(o *ClientOptions) {
+		o.Addresses = addresses
+	}
+}
+
+// ServiceBalancing возвращает политику балансировки и явный список адресов сервиса из конфигурации
+func (c *Config) ServiceBalancing(serviceName string) (policy string, addresses []string) {
+	if c != nil {
+		if service, ok := c.Services[serviceName]; ok {
+			return service.LoadBalancing, service.Addresses
+		}
+	}
+	return "", nil
+}
+
+// balancedTarget возвращает цель подключения и опции балансировки.
+// Без явного списка адресов имя разрешается через dns:///, поэтому для headless сервиса
+// Kubernetes соединение устанавливается с каждым подом, а не только с первым адресом.
+// Явный список адресов передается через manual резолвер.
+func balancedTarget(address, policy string, addresses []string) (string, []grpc.DialOption, error) {
+	if policy == "" {
+		policy = LoadBalancingRoundRobin
+	}
+	if policy != LoadBalancingRoundRobin && policy != LoadBalancingPickFirst {
+		return "", nil, fmt.Errorf("unsupported load balancing policy %q", policy)
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig":[{%q:{}}]}`, policy)),
+	}
+
+	if len(addresses) == 0 {
+		return "dns:///" + address, opts, nil
+	}
+
+	state := resolver.State{Addresses: make([]resolver.Address, 0, len(addresses))}
+	for _, addr := range addresses {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return "", nil, fmt.Errorf("invalid address %q: %w", addr, err)
+		}
+		state.Addresses = append(state.Addresses, resolver.Address{Addr: addr})
+	}
+
+	// Резолвер обслуживает одно соединение, поэтому создается для каждого подключения
+	staticResolver := manual.NewBuilderWithScheme(staticResolverScheme)
+	staticResolver.InitialState(state)
+
+	return staticResolverScheme + ":///" + address, append(opts, grpc.WithResolvers(staticResolver)), nil
+}
@@ -0,0 +1,137 @@
+package grpc_clients
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// countingServer gRPC сервер с health сервисом, считающий входящие вызовы
+type countingServer struct {
+	addr   string
+	calls  atomic.Int64
+	server *grpc.Server
+}
+
+// start запускает сервер на addr ("127.0.0.1:0" - на свободном порту)
+func (s *countingServer) start(t *testing.T, addr string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s.addr = listener.Addr().String()
+	s.server = grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		s.calls.Add(1)
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(s.server, grpchealth.NewServer())
+	go s.server.Serve(listener)
+	t.Cleanup(s.server.Stop)
+}
+
+// callHealth выполняет count вызовов health сервиса через conn
+func callHealth(t *testing.T, conn *grpc.ClientConn, count int) {
+	t.Helper()
+
+	client := healthpb.NewHealthClient(conn)
+	for i := 0; i < count; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true))
+		cancel()
+		if err != nil {
+			t.Fatalf("Check: %v", err)
+		}
+	}
+}
+
+// waitForBackends ожидает, пока round_robin подключится ко всем серверам
+func waitForBackends(t *testing.T, conn *grpc.ClientConn, servers ...*countingServer) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, server := range servers {
+			server.calls.Store(0)
+		}
+		callHealth(t, conn, 2*len(servers))
+
+		reached := true
+		for _, server := range servers {
+			if server.calls.Load() == 0 {
+				reached = false
+			}
+		}
+		if reached {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("calls were not distributed across %d servers", len(servers))
+}
+
+func TestRegistryRoundRobinRedistributesAfterRestart(t *testing.T) {
+	first, second := &countingServer{}, &countingServer{}
+	first.start(t, "127.0.0.1:0")
+	second.start(t, "127.0.0.1:0")
+
+	registry := NewClientRegistry()
+	defer registry.CloseAll()
+	registry.RegisterService("orders", &ServiceConfig{Address: "orders", Addresses: []string{first.addr, second.addr}})
+
+	conn, err := registry.GetConnection("orders")
+	if err != nil {
+		t.Fatalf("GetConnection: %v", err)
+	}
+	waitForBackends(t, conn, first, second)
+
+	// Под перезапускается на том же адресе: пока его нет, вызовы идут на оставшийся
+	second.server.Stop()
+	first.calls.Store(0)
+	callHealth(t, conn, 4)
+	if got := first.calls.Load(); got != 4 {
+		t.Errorf("calls to remaining server = %d, want 4", got)
+	}
+
+	restarted := &countingServer{}
+	restarted.start(t, second.addr)
+	waitForBackends(t, conn, first, restarted)
+}
+
+func TestBaseClientUsesLoadBalancingOptions(t *testing.T) {
+	first, second := &countingServer{}, &countingServer{}
+	first.start(t, "127.0.0.1:0")
+	second.start(t, "127.0.0.1:0")
+
+	client, err := NewBaseClientWithOptions(DefaultConfig(), "orders", "orders", "50054",
+		WithAddresses(first.addr, second.addr),
+		WithLogging(false),
+	)
+	if err != nil {
+		t.Fatalf("NewBaseClientWithOptions: %v", err)
+	}
+	defer client.Close()
+
+	waitForBackends(t, client.Conn, first, second)
+}
+
+func TestBalancedTarget(t *testing.T) {
+	target, _, err := balancedTarget("orders:50054", "", nil)
+	if err != nil || target != "dns:///orders:50054" {
+		t.Errorf("balancedTarget() = %q, %v", target, err)
+	}
+
+	if _, _, err := balancedTarget("orders:50054", "random", nil); err == nil {
+		t.Error("expected error for unsupported policy")
+	}
+	if _, _, err := balancedTarget("orders:50054", LoadBalancingPickFirst, []string{"no-port"}); err == nil {
+		t.Error("expected error for address without port")
+	}
+}
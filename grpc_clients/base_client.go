@@ -6,11 +6,11 @@ import (
 	"log"
 	"time"
 
+	"github.com/vladzorgan/common/grpc/interceptors"
+	"github.com/vladzorgan/common/grpc_clients/clientcall"
+	"github.com/vladzorgan/common/logging"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
-	"google.golang.org/grpc/status"
 )
 
 // Config представляет конфигурацию для клиентов
@@ -25,6 +25,12 @@ type ServiceConfigBase struct {
 	Timeout     time.Duration `yaml:"timeout" json:"timeout"`
 	MaxRetries  int           `yaml:"max_retries" json:"max_retries"`
 	HealthCheck bool          `yaml:"health_check" json:"health_check"`
+	// TLS соединения с сервисом; переопределяется опцией WithTLS
+	TLS *TLSConfig `yaml:"tls,omitempty" json:"tls,omitempty"`
+	// Политика балансировки (round_robin по умолчанию, pick_first); переопределяется опцией WithLoadBalancing
+	LoadBalancing string `yaml:"load_balancing,omitempty" json:"load_balancing,omitempty"`
+	// Явный список адресов host:port вместо DNS; переопределяется опцией WithAddresses
+	Addresses []string `yaml:"addresses,omitempty" json:"addresses,omitempty"`
 }
 
 // BaseClient базовый gRPC клиент
@@ -32,6 +38,10 @@ type BaseClient struct {
 	Conn        *grpc.ClientConn
 	Config      *Config
 	ServiceName string
+	// Timeout таймаут вызовов без дедлайна в контексте (см. WithTimeout)
+	Timeout time.Duration
+
+	callConfig *clientcall.Config
 }
 
 // ClientOptions опции для создания клиента
@@ -44,6 +54,14 @@ type ClientOptions struct {
 	OnConnected     func(*grpc.ClientConn)
 	OnError         func(error) error
 	EnableLogging   bool
+	Logger          logging.Logger
+	RetryOptions    *interceptors.RetryOptions
+	// TLS соединения (см. WithTLS, WithClientCertificate); nil - незащищенное соединение
+	TLS *TLSConfig
+	// Политика балансировки (см. WithLoadBalancing); пустая - из конфигурации или round_robin
+	LoadBalancing string
+	// Явный список адресов (см. WithAddresses); пустой - из конфигурации или DNS
+	Addresses []string
 }
 
 // OptionFunc функциональная опция
@@ -70,6 +88,19 @@ func WithLogging(enable bool) OptionFunc {
 	}
 }
 
+// WithOutgoingMetadata добавляет request ID, user-id и user-role из контекста в исходящие метаданные.
+// Используется для вызовов через соединения, созданные без стандартных интерцепторов.
+func WithOutgoingMetadata(ctx context.Context) context.Context {
+	return interceptors.OutgoingContext(ctx)
+}
+
+// WithRetryOptions устанавливает настройки повторных попыток вызовов
+func WithRetryOptions(retryOptions *interceptors.RetryOptions) OptionFunc {
+	return func(o *ClientOptions) {
+		o.RetryOptions = retryOptions
+	}
+}
+
 // DefaultOptions возвращает опции по умолчанию
 func DefaultOptions(serviceName, serviceURLKey, defaultPort string) ClientOptions {
 	return ClientOptions{
@@ -78,6 +109,7 @@ func DefaultOptions(serviceName, serviceURLKey, defaultPort string) ClientOption
 		DefaultPort:    defaultPort,
 		ConnectTimeout: 5 * time.Second,
 		EnableLogging:  true,
+		RetryOptions:   interceptors.DefaultRetryOptions(),
 	}
 }
 
@@ -97,12 +129,44 @@ func NewBaseClient(cfg *Config, options ClientOptions) (*BaseClient, error) {
 		PermitWithoutStream: true,
 	}
 
+	// Таймаут вызовов сервиса из конфигурации
+	callTimeout := cfg.CallTimeout(options.ServiceURLKey)
+
+	// TLS из опций клиента или из конфигурации сервиса
+	tlsConfig := options.TLS
+	if tlsConfig == nil {
+		tlsConfig = cfg.ServiceTLS(options.ServiceURLKey)
+	}
+	creds, err := tlsConfig.transportCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка настройки TLS для %s: %w", options.ServiceName, err)
+	}
+
+	// Балансировка из опций клиента или из конфигурации сервиса
+	policy, addresses := cfg.ServiceBalancing(options.ServiceURLKey)
+	if options.LoadBalancing != "" {
+		policy = options.LoadBalancing
+	}
+	if len(options.Addresses) > 0 {
+		addresses = options.Addresses
+	}
+	target, balancingOptions, err := balancedTarget(serviceURL, policy, addresses)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка настройки балансировки для %s: %w", options.ServiceName, err)
+	}
+
 	dialOptions := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
 		grpc.WithKeepaliveParams(kacp),
 		grpc.WithBlock(),
+		// Таймаут ограничивает вызов целиком, включая повторы
+		grpc.WithChainUnaryInterceptor(TimeoutInterceptor(callTimeout)),
+		// Request ID, логирование, метрики и повторы для всех исходящих вызовов
+		grpc.WithChainUnaryInterceptor(interceptors.DefaultUnaryClientInterceptors(options.Logger, options.RetryOptions)...),
 	}
 
+	dialOptions = append(dialOptions, balancingOptions...)
+
 	// Добавляем дополнительные опции
 	if len(options.ExtraDialOption) > 0 {
 		dialOptions = append(dialOptions, options.ExtraDialOption...)
@@ -113,7 +177,7 @@ func NewBaseClient(cfg *Config, options ClientOptions) (*BaseClient, error) {
 	}
 
 	// Инициализируем соединение
-	conn, err := grpc.DialContext(ctx, serviceURL, dialOptions...)
+	conn, err := grpc.DialContext(ctx, target, dialOptions...)
 	if err != nil {
 		if options.OnError != nil {
 			return nil, options.OnError(err)
@@ -130,13 +194,29 @@ func NewBaseClient(cfg *Config, options ClientOptions) (*BaseClient, error) {
 		options.OnConnected(conn)
 	}
 
+	callConfig := cfg.CallConfig(options.ServiceURLKey)
+	callConfig.Service = options.ServiceName
+	callConfig.Logger = options.Logger
+
 	return &BaseClient{
 		Conn:        conn,
 		Config:      cfg,
 		ServiceName: options.ServiceName,
+		Timeout:     callTimeout,
+		callConfig:  callConfig,
 	}, nil
 }
 
+// CallConfig возвращает настройки вызовов сервиса (таймаут попытки и повторы из конфигурации)
+// для Call и типизированных клиентов: location.NewWithConfig(client.Conn, client.CallConfig())
+func (c *BaseClient) CallConfig() *clientcall.Config {
+	if c.callConfig == nil {
+		return c.Config.CallConfig(c.ServiceName)
+	}
+	callConfig := *c.callConfig
+	return &callConfig
+}
+
 // NewBaseClientWithOptions создает базовый клиент с функциональными опциями
 func NewBaseClientWithOptions(cfg *Config, serviceName, serviceURLKey, defaultPort string, opts ...OptionFunc) (*BaseClient, error) {
 	options := DefaultOptions(serviceName, serviceURLKey, defaultPort)
@@ -157,7 +237,10 @@ func (c *BaseClient) Close() error {
 	return nil
 }
 
-// MeasureCall выполняет gRPC запрос с измерением времени и retry логикой
+// MeasureCall выполняет gRPC запрос с настройками вызовов по умолчанию (clientcall.DefaultConfig)
+// и оборачивает ошибку именем сервиса.
+//
+// Deprecated: используйте Call с настройками сервиса из Config.CallConfig или BaseClient.CallConfig.
 func MeasureCall[Req any, Resp any](
 	ctx context.Context,
 	serviceName, methodName string,
@@ -165,44 +248,5 @@ func MeasureCall[Req any, Resp any](
 	call func(context.Context, Req, ...grpc.CallOption) (Resp, error),
 	opts ...grpc.CallOption,
 ) (Resp, error) {
-	var emptyResp Resp
-
-	start := time.Now()
-	
-	// Логируем запрос
-	log.Printf("gRPC call: %s.%s", serviceName, methodName)
-
-	resp, err := call(ctx, request, opts...)
-	duration := time.Since(start)
-
-	if err != nil {
-		log.Printf("gRPC error in %s.%s: %v (duration: %v)", serviceName, methodName, err, duration)
-		return emptyResp, fmt.Errorf("сервис %s недоступен: %w", serviceName, err)
-	}
-
-	log.Printf("gRPC success: %s.%s (duration: %v)", serviceName, methodName, duration)
-	return resp, nil
+	return clientcall.Call(ctx, clientcall.DefaultConfig(serviceName), methodName, request, call, opts...)
 }
-
-// shouldRetryCall определяет, стоит ли повторять запрос
-func shouldRetryCall(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	st, ok := status.FromError(err)
-	if !ok {
-		return true
-	}
-
-	switch st.Code() {
-	case codes.DeadlineExceeded,
-		codes.Unavailable,
-		codes.ResourceExhausted,
-		codes.Aborted,
-		codes.Internal:
-		return true
-	default:
-		return false
-	}
-}
\ No newline at end of file
@@ -0,0 +1,79 @@
+package grpc_clients
+
+import (
+	"context"
+	"time"
+
+	"github.com/vladzorgan/common/grpc_clients/clientcall"
+	"google.golang.org/grpc"
+)
+
+// DefaultMaxRetries количество повторов вызовов для сервисов без настроенного MaxRetries
+const DefaultMaxRetries = 3
+
+// Call выполняет вызов метода типизированного клиента с таймаутом попытки, повторами и метриками
+// (см. clientcall.Call). Настройки обычно берутся из конфигурации сервиса:
+//
+//	resp, err := grpc_clients.Call(ctx, cfg.CallConfig(location.URLKey), "GetRegion", req, pbClient.GetRegion)
+func Call[Req any, Resp any](
+	ctx context.Context,
+	cfg *clientcall.Config,
+	method string,
+	request Req,
+	invoke func(context.Context, Req, ...grpc.CallOption) (Resp, error),
+	opts ...grpc.CallOption,
+) (Resp, error) {
+	return clientcall.Call(ctx, cfg, method, request, invoke, opts...)
+}
+
+// CallConfig возвращает настройки вызовов сервиса из конфигурации: таймаут попытки (см. CallTimeout)
+// и MaxRetries (0 - DefaultMaxRetries, отрицательное значение - без повторов)
+func (c *Config) CallConfig(serviceName string) *clientcall.Config {
+	var maxRetries int
+	if c != nil {
+		maxRetries = c.Services[serviceName].MaxRetries
+	}
+	return callConfig(serviceName, c.CallTimeout(serviceName), maxRetries)
+}
+
+// CallConfig возвращает настройки вызовов зарегистрированного сервиса (Timeout и MaxRetries)
+func (r *ClientRegistry) CallConfig(serviceName string) *clientcall.Config {
+	r.mu.RLock()
+	config, ok := r.configs[serviceName]
+	r.mu.RUnlock()
+
+	if !ok {
+		return callConfig(serviceName, DefaultCallTimeout, 0)
+	}
+	return callConfig(serviceName, config.Timeout, config.MaxRetries)
+}
+
+// TypedWithConfig аналогичен Typed, но передает конструктору клиента настройки вызовов сервиса из реестра:
+//
+//	client, err := grpc_clients.TypedWithConfig(registry, location.ServiceName, location.NewWithConfig)
+func TypedWithConfig[T any](r *ClientRegistry, serviceName string, newClient func(grpc.ClientConnInterface, *clientcall.Config) T) (T, error) {
+	conn, err := r.GetConnection(serviceName)
+	if err != nil {
+		var empty T
+		return empty, err
+	}
+
+	return newClient(conn, r.CallConfig(serviceName)), nil
+}
+
+// callConfig формирует настройки вызовов с задержками повторов по умолчанию
+func callConfig(serviceName string, timeout time.Duration, maxRetries int) *clientcall.Config {
+	cfg := clientcall.DefaultConfig(serviceName)
+	cfg.Timeout = timeout
+
+	switch {
+	case maxRetries > 0:
+		cfg.MaxRetries = maxRetries
+	case maxRetries < 0:
+		cfg.MaxRetries = 0
+	default:
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+
+	return cfg
+}
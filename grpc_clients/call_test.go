@@ -0,0 +1,29 @@
+package grpc_clients
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCallConfigFromServiceConfig(t *testing.T) {
+	cfg := &Config{Services: map[string]ServiceConfigBase{
+		"configured": {Timeout: 2 * time.Second, MaxRetries: 5},
+		"no-retries": {MaxRetries: -1},
+	}}
+
+	if got := cfg.CallConfig("configured"); got.Timeout != 2*time.Second || got.MaxRetries != 5 || got.Service != "configured" {
+		t.Errorf("configured call config = %+v", got)
+	}
+	if got := cfg.CallConfig("no-retries"); got.MaxRetries != 0 || got.Timeout != DefaultCallTimeout {
+		t.Errorf("no-retries call config = %+v", got)
+	}
+	if got := cfg.CallConfig("unknown"); got.MaxRetries != DefaultMaxRetries {
+		t.Errorf("unknown service retries = %d, want %d", got.MaxRetries, DefaultMaxRetries)
+	}
+
+	registry := NewClientRegistry()
+	registry.RegisterService("orders", &ServiceConfig{Address: "orders", Port: "50054", Timeout: time.Second, MaxRetries: 1})
+	if got := registry.CallConfig("orders"); got.Timeout != time.Second || got.MaxRetries != 1 {
+		t.Errorf("registry call config = %+v", got)
+	}
+}
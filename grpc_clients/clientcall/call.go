@@ -0,0 +1,215 @@
+// Package clientcall выполняет вызовы типизированных gRPC клиентов с таймаутом попытки,
+// повторами и метриками. Вынесен из grpc_clients, чтобы клиенты в подпакетах (location и др.)
+// могли использовать его без циклического импорта.
+package clientcall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/budget"
+	"github.com/vladzorgan/common/grpc/interceptors"
+	"github.com/vladzorgan/common/killswitch"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/metrics"
+	"github.com/vladzorgan/common/retry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Config содержит настройки вызовов сервиса
+type Config struct {
+	// Имя сервиса для ошибок и метрик
+	Service string
+	// Таймаут одной попытки; 0 - таймаут задает контекст или интерцептор соединения
+	Timeout time.Duration
+	// Максимальное количество повторных попыток при временных ошибках (0 - без повторов)
+	MaxRetries int
+	// Начальная задержка между попытками; удваивается после каждой попытки
+	Backoff time.Duration
+	// Максимальная задержка между попытками (0 - без ограничения)
+	MaxBackoff time.Duration
+	// Проверка идемпотентности метода; nil - IsReadMethod
+	Idempotent func(method string) bool
+	// Логгер повторов, пропущенных из-за исчерпания бюджета запроса; nil - без логирования
+	Logger logging.Logger
+	// Реестр метрик (nil - prometheus.DefaultRegisterer)
+	Registerer prometheus.Registerer
+}
+
+// DefaultConfig возвращает настройки по умолчанию: 3 повтора с задержкой от 100мс до 2с
+// и без таймаута попытки
+func DefaultConfig(service string) *Config {
+	return &Config{
+		Service:    service,
+		MaxRetries: 3,
+		Backoff:    100 * time.Millisecond,
+		MaxBackoff: 2 * time.Second,
+	}
+}
+
+// Call выполняет вызов invoke с настройками cfg:
+//   - каждая попытка ограничена Config.Timeout (более короткий дедлайн ctx сохраняется);
+//   - при временных ошибках (interceptors.IsRetryable) вызов повторяется до Config.MaxRetries раз
+//     с экспоненциальной задержкой со случайным разбросом; неидемпотентные методы (см. Config.Idempotent)
+//     повторяются только при Unavailable, когда запрос не был обработан сервером;
+//   - между попытками учитывается отмена ctx и бюджет запроса (см. пакет budget);
+//   - количество попыток и итоговый статус записываются в метрики
+//     grpc_client_call_attempts и grpc_client_calls_total.
+//
+// Повторы интерцептора соединения для вызова отключаются (interceptors.WithoutRetry), чтобы
+// попытки не умножались. При nil cfg выполняется одна попытка, повторы остаются за интерцептором.
+// Ошибка оборачивается именем сервиса, код gRPC статуса сохраняется для status.Code.
+func Call[Req any, Resp any](
+	ctx context.Context,
+	cfg *Config,
+	method string,
+	request Req,
+	invoke func(context.Context, Req, ...grpc.CallOption) (Resp, error),
+	opts ...grpc.CallOption,
+) (Resp, error) {
+	var empty Resp
+
+	if cfg == nil {
+		// Одна попытка без собственного таймаута: повторы выполняет интерцептор соединения
+		cfg = &Config{}
+	} else {
+		opts = append(opts, interceptors.WithoutRetry())
+	}
+
+	maxRetries := cfg.MaxRetries
+	if killswitch.IsDisabled(killswitch.FeatureGRPCClientRetry) {
+		maxRetries = 0
+	}
+
+	backoff := retry.NewBackoff(cfg.Backoff, cfg.MaxBackoff)
+	attempts := 0
+
+	for {
+		attempts++
+		resp, err := attempt(ctx, cfg.Timeout, request, invoke, opts)
+		if err == nil {
+			observe(cfg, method, attempts, nil)
+			return resp, nil
+		}
+
+		retryable := attempts <= maxRetries && cfg.canRetry(method, err) && ctx.Err() == nil &&
+			// Не повторяем вызов, если исчерпан общий бюджет запроса
+			budget.AllowRetry(ctx, cfg.Logger, method)
+		if !retryable || backoff.Wait(ctx) != nil {
+			observe(cfg, method, attempts, err)
+			return empty, wrapError(cfg.Service, err)
+		}
+	}
+}
+
+// readMethodPrefixes - префиксы методов чтения
+var readMethodPrefixes = []string{"Get", "List", "Search", "Find", "Count", "Check", "Exists"}
+
+// IsReadMethod проверяет по имени, что метод только читает данные и его безопасно повторять
+func IsReadMethod(method string) bool {
+	for _, prefix := range readMethodPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// canRetry проверяет, можно ли повторить вызов метода после ошибки err.
+// После DeadlineExceeded, Internal и других ошибок запись могла быть выполнена,
+// поэтому неидемпотентные методы повторяются только при Unavailable.
+func (c *Config) canRetry(method string, err error) bool {
+	idempotent := c.Idempotent
+	if idempotent == nil {
+		idempotent = IsReadMethod
+	}
+
+	if idempotent(method) {
+		return interceptors.IsRetryable(err)
+	}
+	return status.Code(err) == codes.Unavailable
+}
+
+// attempt выполняет одну попытку с таймаутом timeout (0 - без собственного таймаута)
+func attempt[Req any, Resp any](
+	ctx context.Context,
+	timeout time.Duration,
+	request Req,
+	invoke func(context.Context, Req, ...grpc.CallOption) (Resp, error),
+	opts []grpc.CallOption,
+) (Resp, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return invoke(ctx, request, opts...)
+}
+
+// wrapError оборачивает ошибку именем сервиса
+func wrapError(service string, err error) error {
+	if service == "" {
+		return fmt.Errorf("вызов сервиса завершился ошибкой: %w", err)
+	}
+	return fmt.Errorf("сервис %s недоступен: %w", service, err)
+}
+
+// callMetrics метрики вызовов одного реестра
+type callMetrics struct {
+	attempts *prometheus.HistogramVec
+	calls    *prometheus.CounterVec
+}
+
+var (
+	metricsMu sync.Mutex
+	// Метрики по реестрам: регистрация выполняется один раз для каждого реестра
+	metricsByRegisterer = make(map[prometheus.Registerer]*callMetrics)
+)
+
+// metricsFor возвращает метрики, зарегистрированные в registerer
+func metricsFor(registerer prometheus.Registerer) *callMetrics {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	if m, ok := metricsByRegisterer[registerer]; ok {
+		return m
+	}
+
+	m := &callMetrics{
+		attempts: metrics.Register(registerer, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "grpc_client_call_attempts",
+				Help:    "Number of attempts per gRPC client call",
+				Buckets: []float64{1, 2, 3, 4, 5, 8},
+			},
+			[]string{"service", "method"},
+		)),
+		calls: metrics.Register(registerer, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_client_calls_total",
+				Help: "Total number of gRPC client calls by final status",
+			},
+			[]string{"service", "method", "status"},
+		)),
+	}
+	metricsByRegisterer[registerer] = m
+	return m
+}
+
+// observe записывает количество попыток и итоговый статус вызова
+func observe(cfg *Config, method string, attempts int, err error) {
+	m := metricsFor(cfg.Registerer)
+	m.attempts.WithLabelValues(cfg.Service, method).Observe(float64(attempts))
+	m.calls.WithLabelValues(cfg.Service, method, status.Code(err).String()).Inc()
+}
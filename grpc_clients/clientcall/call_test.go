@@ -0,0 +1,164 @@
+package clientcall
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/vladzorgan/common/grpc/interceptors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// scriptedInvoker возвращает ошибки из списка по очереди, затем успешный ответ
+type scriptedInvoker struct {
+	errs      []error
+	calls     int
+	deadlines []time.Duration
+	noRetry   bool
+}
+
+func (s *scriptedInvoker) invoke(ctx context.Context, req string, opts ...grpc.CallOption) (string, error) {
+	s.calls++
+	if deadline, ok := ctx.Deadline(); ok {
+		s.deadlines = append(s.deadlines, time.Until(deadline))
+	}
+	for _, opt := range opts {
+		if opt == interceptors.WithoutRetry() {
+			s.noRetry = true
+		}
+	}
+
+	if s.calls <= len(s.errs) {
+		return "", s.errs[s.calls-1]
+	}
+	return "ok:" + req, nil
+}
+
+func testConfig(registry *prometheus.Registry) *Config {
+	return &Config{
+		Service:    "location-service",
+		Timeout:    time.Second,
+		MaxRetries: 2,
+		Backoff:    time.Millisecond,
+		MaxBackoff: 5 * time.Millisecond,
+		Registerer: registry,
+	}
+}
+
+func TestCallRetriesRetryableErrors(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	invoker := &scriptedInvoker{errs: []error{
+		status.Error(codes.Unavailable, "pod restarting"),
+		status.Error(codes.Unavailable, "pod restarting"),
+	}}
+
+	resp, err := Call(context.Background(), testConfig(registry), "GetRegion", "1", invoker.invoke)
+	if err != nil || resp != "ok:1" {
+		t.Fatalf("Call() = %q, %v", resp, err)
+	}
+	if invoker.calls != 3 {
+		t.Errorf("attempts = %d, want 3", invoker.calls)
+	}
+	if !invoker.noRetry {
+		t.Error("interceptor retries must be disabled for Call attempts")
+	}
+	for _, remaining := range invoker.deadlines {
+		if remaining <= 0 || remaining > time.Second {
+			t.Errorf("attempt deadline in %v, want per-attempt 1s", remaining)
+		}
+	}
+
+	m := metricsFor(registry)
+	if got := testutil.ToFloat64(m.calls.WithLabelValues("location-service", "GetRegion", "OK")); got != 1 {
+		t.Errorf("calls_total{OK} = %v, want 1", got)
+	}
+	if count := testutil.CollectAndCount(m.attempts); count != 1 {
+		t.Errorf("attempts series = %d, want 1", count)
+	}
+}
+
+func TestCallStopsAfterMaxRetries(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	unavailable := status.Error(codes.Unavailable, "down")
+	invoker := &scriptedInvoker{errs: []error{unavailable, unavailable, unavailable, unavailable}}
+
+	_, err := Call(context.Background(), testConfig(registry), "GetRegion", "1", invoker.invoke)
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("Call() error = %v, want wrapped Unavailable", err)
+	}
+	if invoker.calls != 3 {
+		t.Errorf("attempts = %d, want 1 + MaxRetries", invoker.calls)
+	}
+
+	m := metricsFor(registry)
+	if got := testutil.ToFloat64(m.calls.WithLabelValues("location-service", "GetRegion", "Unavailable")); got != 1 {
+		t.Errorf("calls_total{Unavailable} = %v, want 1", got)
+	}
+}
+
+func TestCallDoesNotRetryPermanentErrors(t *testing.T) {
+	invoker := &scriptedInvoker{errs: []error{status.Error(codes.NotFound, "no region")}}
+
+	_, err := Call(context.Background(), testConfig(prometheus.NewRegistry()), "GetRegion", "1", invoker.invoke)
+	if status.Code(err) != codes.NotFound || invoker.calls != 1 {
+		t.Errorf("Call() error = %v after %d attempts, want NotFound after 1", err, invoker.calls)
+	}
+}
+
+func TestCallRetriesWritesOnlyWhenUnavailable(t *testing.T) {
+	invoker := &scriptedInvoker{errs: []error{status.Error(codes.DeadlineExceeded, "slow")}}
+	_, err := Call(context.Background(), testConfig(prometheus.NewRegistry()), "CreateRegion", "1", invoker.invoke)
+	if status.Code(err) != codes.DeadlineExceeded || invoker.calls != 1 {
+		t.Errorf("Call() error = %v after %d attempts, want DeadlineExceeded after 1", err, invoker.calls)
+	}
+
+	invoker = &scriptedInvoker{errs: []error{status.Error(codes.Unavailable, "pod restarting")}}
+	if _, err := Call(context.Background(), testConfig(prometheus.NewRegistry()), "CreateRegion", "1", invoker.invoke); err != nil || invoker.calls != 2 {
+		t.Errorf("Call() error = %v after %d attempts, want success after 2", err, invoker.calls)
+	}
+
+	// Метод записи, объявленный идемпотентным, повторяется при любой временной ошибке
+	cfg := testConfig(prometheus.NewRegistry())
+	cfg.Idempotent = func(method string) bool { return method == "UpdateRegion" || IsReadMethod(method) }
+	invoker = &scriptedInvoker{errs: []error{status.Error(codes.DeadlineExceeded, "slow")}}
+	if _, err := Call(context.Background(), cfg, "UpdateRegion", "1", invoker.invoke); err != nil || invoker.calls != 2 {
+		t.Errorf("Call() error = %v after %d attempts, want success after 2", err, invoker.calls)
+	}
+}
+
+func TestCallRespectsCancellationBetweenAttempts(t *testing.T) {
+	cfg := testConfig(prometheus.NewRegistry())
+	cfg.Backoff = time.Hour
+	cfg.MaxBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	invoker := &scriptedInvoker{errs: []error{status.Error(codes.Unavailable, "down")}}
+
+	start := time.Now()
+	if _, err := Call(ctx, cfg, "GetRegion", "1", invoker.invoke); err == nil {
+		t.Fatal("Call() must fail when the context is done")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Call() took %v, cancellation was ignored", elapsed)
+	}
+	if invoker.calls != 1 {
+		t.Errorf("attempts = %d, want 1", invoker.calls)
+	}
+}
+
+func TestCallWithoutConfigMakesSingleAttempt(t *testing.T) {
+	invoker := &scriptedInvoker{errs: []error{status.Error(codes.Unavailable, "down")}}
+
+	if _, err := Call(context.Background(), nil, "GetRegion", "1", invoker.invoke); status.Code(err) != codes.Unavailable {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if invoker.calls != 1 || invoker.noRetry || len(invoker.deadlines) != 0 {
+		t.Errorf("nil config: attempts = %d, noRetry = %v, deadlines = %v", invoker.calls, invoker.noRetry, invoker.deadlines)
+	}
+}
@@ -2,12 +2,10 @@ package grpc_clients
 
 import (
 	"context"
-	"fmt"
 	"time"
 
+	"github.com/vladzorgan/common/grpc_clients/clientcall"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 // CallOptions опции для вызова gRPC методов
@@ -17,16 +15,19 @@ type CallOptions struct {
 	RetryDelay time.Duration
 }
 
-// DefaultCallOptions возвращает опции по умолчанию
+// DefaultCallOptions возвращает опции без повторов с таймаутом попытки 30с
 func DefaultCallOptions() *CallOptions {
 	return &CallOptions{
 		Timeout:    30 * time.Second,
-		Retries:    3,
+		Retries:    0,
 		RetryDelay: 1 * time.Second,
 	}
 }
 
-// GrpcCallWrapper обертка для выполнения gRPC вызовов с retry логикой
+// GrpcCallWrapper обертка для выполнения gRPC вызовов с retry логикой.
+// Без opts используются Timeout и MaxRetries сервиса из реестра.
+//
+// Deprecated: используйте Call с ClientRegistry.CallConfig.
 func GrpcCallWrapper[Req, Resp any](
 	ctx context.Context,
 	client *BaseServiceClient,
@@ -35,68 +36,15 @@ func GrpcCallWrapper[Req, Resp any](
 	callFunc func(context.Context, Req, ...grpc.CallOption) (Resp, error),
 	opts *CallOptions,
 ) (Resp, error) {
-	var response Resp
-	var lastErr error
-
-	if opts == nil {
-		opts = DefaultCallOptions()
-	}
-
-	for attempt := 0; attempt <= opts.Retries; attempt++ {
-		// Создаем контекст с таймаутом для каждой попытки
-		callCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
-		
-		// Выполняем вызов
-		resp, err := callFunc(callCtx, request)
-		cancel()
-
-		if err == nil {
-			return resp, nil
-		}
-
-		lastErr = err
-
-		// Проверяем, стоит ли повторять запрос
-		if !shouldRetry(err) || attempt == opts.Retries {
-			break
-		}
-
-		// Ждем перед следующей попыткой
-		select {
-		case <-ctx.Done():
-			return response, ctx.Err()
-		case <-time.After(opts.RetryDelay * time.Duration(attempt+1)):
-			// Exponential backoff
-		}
-	}
-
-	return response, fmt.Errorf("все попытки вызова %s.%s исчерпаны: %w", 
-		client.GetServiceName(), methodName, lastErr)
-}
-
-// shouldRetry определяет, стоит ли повторять запрос при данной ошибке
-func shouldRetry(err error) bool {
-	if err == nil {
-		return false
+	cfg := client.registry.CallConfig(client.GetServiceName())
+	if opts != nil {
+		cfg.Timeout = opts.Timeout
+		cfg.MaxRetries = opts.Retries
+		cfg.Backoff = opts.RetryDelay
+		cfg.MaxBackoff = 0
 	}
 
-	// Извлекаем gRPC статус
-	st, ok := status.FromError(err)
-	if !ok {
-		return true // Неизвестная ошибка - повторяем
-	}
-
-	// Определяем коды ошибок, при которых имеет смысл повторить запрос
-	switch st.Code() {
-	case codes.DeadlineExceeded,
-		codes.Unavailable,
-		codes.ResourceExhausted,
-		codes.Aborted,
-		codes.Internal:
-		return true
-	default:
-		return false
-	}
+	return clientcall.Call(ctx, cfg, methodName, request, callFunc)
 }
 
 // ClientBuilder паттерн Builder для создания клиентов различных сервисов
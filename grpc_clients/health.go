@@ -0,0 +1,58 @@
+package grpc_clients
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vladzorgan/common/health"
+)
+
+// RegistryComponent представляет компонент проверки здоровья для соединений ClientRegistry
+type RegistryComponent struct {
+	name     string
+	registry *ClientRegistry
+	critical bool
+}
+
+// NewRegistryComponent создает компонент health.Component по состоянию сервисов реестра
+func NewRegistryComponent(name string, registry *ClientRegistry, critical bool) *RegistryComponent {
+	return &RegistryComponent{
+		name:     name,
+		registry: registry,
+		critical: critical,
+	}
+}
+
+// Name возвращает имя компонента
+func (c *RegistryComponent) Name() string {
+	return c.name
+}
+
+// Check возвращает StatusDown, если недоступны все подключенные сервисы,
+// и StatusDegraded, если недоступна часть из них
+func (c *RegistryComponent) Check(ctx context.Context) (health.Status, error) {
+	statuses := c.registry.RegistryStatus()
+	unhealthy := unhealthyServices(statuses)
+	if len(unhealthy) == 0 {
+		return health.StatusUp, nil
+	}
+
+	connected := 0
+	for _, status := range statuses {
+		if status.State != StateNotConnected {
+			connected++
+		}
+	}
+
+	err := fmt.Errorf("unavailable services: %s", strings.Join(unhealthy, ", "))
+	if len(unhealthy) == connected {
+		return health.StatusDown, err
+	}
+	return health.StatusDegraded, err
+}
+
+// IsCritical возвращает true, если компонент критичен для работы сервиса
+func (c *RegistryComponent) IsCritical() bool {
+	return c.critical
+}
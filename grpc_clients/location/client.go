@@ -0,0 +1,152 @@
+// Package location содержит типизированный клиент location-service.
+// Вынесен из grpc_clients, чтобы сервисы, которым нужен только реестр, не тянули proto-типы.
+package location
+
+import (
+	"context"
+
+	"github.com/vladzorgan/common/grpc_clients/clientcall"
+	locationpb "github.com/vladzorgan/common/proto/location"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	ServiceName = "location-service"
+	URLKey      = "location-service"
+	DefaultPort = "50053"
+)
+
+// Client представляет gRPC клиент для сервиса местоположений.
+// Создается поверх соединения реестра или BaseClient:
+//
+//	client, err := grpc_clients.TypedWithConfig(registry, location.ServiceName, location.NewWithConfig)
+//	client := location.NewWithConfig(baseClient.Conn, baseClient.CallConfig())
+type Client struct {
+	client locationpb.LocationServiceClient
+	call   *clientcall.Config
+}
+
+// New создает клиент сервиса местоположений поверх соединения с настройками вызовов по умолчанию
+// (clientcall.DefaultConfig)
+func New(conn grpc.ClientConnInterface) *Client {
+	return NewWithConfig(conn, nil)
+}
+
+// NewWithConfig создает клиент сервиса местоположений с настройками таймаута и повторов вызовов;
+// nil - clientcall.DefaultConfig
+func NewWithConfig(conn grpc.ClientConnInterface, cfg *clientcall.Config) *Client {
+	if cfg == nil {
+		cfg = clientcall.DefaultConfig(ServiceName)
+	}
+	return &Client{client: locationpb.NewLocationServiceClient(conn), call: cfg}
+}
+
+// Методы для работы с регионами
+
+// GetRegion получает регион по ID
+func (c *Client) GetRegion(ctx context.Context, id uint32, opts ...grpc.CallOption) (*locationpb.RegionResponse, error) {
+	request := &locationpb.GetRegionRequest{Id: id}
+	return clientcall.Call(ctx, c.call, "GetRegion", request, c.client.GetRegion, opts...)
+}
+
+// GetRegions получает список регионов с пагинацией
+func (c *Client) GetRegions(ctx context.Context, skip, limit int32, sort *locationpb.SortOptions, opts ...grpc.CallOption) (*locationpb.GetRegionsResponse, error) {
+	request := &locationpb.GetRegionsRequest{
+		Skip:  skip,
+		Limit: limit,
+		Sort:  sort,
+	}
+	return clientcall.Call(ctx, c.call, "GetRegions", request, c.client.GetRegions, opts...)
+}
+
+// CreateRegion создает новый регион
+func (c *Client) CreateRegion(ctx context.Context, name, code, country string, opts ...grpc.CallOption) (*locationpb.RegionResponse, error) {
+	request := &locationpb.CreateRegionRequest{
+		Name:    name,
+		Code:    code,
+		Country: country,
+	}
+	return clientcall.Call(ctx, c.call, "CreateRegion", request, c.client.CreateRegion, opts...)
+}
+
+// UpdateRegion обновляет регион
+func (c *Client) UpdateRegion(ctx context.Context, id uint32, name, code, country string, opts ...grpc.CallOption) (*locationpb.RegionResponse, error) {
+	request := &locationpb.UpdateRegionRequest{
+		Id:      id,
+		Name:    name,
+		Code:    code,
+		Country: country,
+	}
+	return clientcall.Call(ctx, c.call, "UpdateRegion", request, c.client.UpdateRegion, opts...)
+}
+
+// DeleteRegion удаляет регион
+func (c *Client) DeleteRegion(ctx context.Context, id uint32, opts ...grpc.CallOption) (*locationpb.RegionResponse, error) {
+	request := &locationpb.DeleteRegionRequest{Id: id}
+	return clientcall.Call(ctx, c.call, "DeleteRegion", request, c.client.DeleteRegion, opts...)
+}
+
+// Методы для работы с городами
+
+// GetCity получает город по ID
+func (c *Client) GetCity(ctx context.Context, id uint32, opts ...grpc.CallOption) (*locationpb.CityResponse, error) {
+	request := &locationpb.GetCityRequest{Id: id}
+	return clientcall.Call(ctx, c.call, "GetCity", request, c.client.GetCity, opts...)
+}
+
+// GetCityBySlug получает город по slug
+func (c *Client) GetCityBySlug(ctx context.Context, slug string, opts ...grpc.CallOption) (*locationpb.CityResponse, error) {
+	request := &locationpb.GetCityBySlugRequest{Slug: slug}
+	return clientcall.Call(ctx, c.call, "GetCityBySlug", request, c.client.GetCityBySlug, opts...)
+}
+
+// GetCities получает список городов с фильтрацией и пагинацией
+func (c *Client) GetCities(ctx context.Context, skip, limit int32, filter *locationpb.CityFilter, sort *locationpb.SortOptions, opts ...grpc.CallOption) (*locationpb.GetCitiesResponse, error) {
+	request := &locationpb.GetCitiesRequest{
+		Skip:   skip,
+		Limit:  limit,
+		Filter: filter,
+		Sort:   sort,
+	}
+	return clientcall.Call(ctx, c.call, "GetCities", request, c.client.GetCities, opts...)
+}
+
+// GetLargestCities получает самые крупные города
+func (c *Client) GetLargestCities(ctx context.Context, limit int32, sort *locationpb.SortOptions, opts ...grpc.CallOption) (*locationpb.GetCitiesResponse, error) {
+	request := &locationpb.GetLargestCitiesRequest{
+		Limit: limit,
+		Sort:  sort,
+	}
+	return clientcall.Call(ctx, c.call, "GetLargestCities", request, c.client.GetLargestCities, opts...)
+}
+
+// CreateCity создает новый город
+func (c *Client) CreateCity(ctx context.Context, req *locationpb.CreateCityRequest, opts ...grpc.CallOption) (*locationpb.CityResponse, error) {
+	return clientcall.Call(ctx, c.call, "CreateCity", req, c.client.CreateCity, opts...)
+}
+
+// UpdateCity обновляет город
+func (c *Client) UpdateCity(ctx context.Context, req *locationpb.UpdateCityRequest, opts ...grpc.CallOption) (*locationpb.CityResponse, error) {
+	return clientcall.Call(ctx, c.call, "UpdateCity", req, c.client.UpdateCity, opts...)
+}
+
+// DeleteCity удаляет город
+func (c *Client) DeleteCity(ctx context.Context, id uint32, opts ...grpc.CallOption) (*locationpb.CityResponse, error) {
+	request := &locationpb.DeleteCityRequest{Id: id}
+	return clientcall.Call(ctx, c.call, "DeleteCity", request, c.client.DeleteCity, opts...)
+}
+
+// Методы для аналитики
+
+// GetSearchStats получает статистику поиска
+func (c *Client) GetSearchStats(ctx context.Context, opts ...grpc.CallOption) (*locationpb.SearchStatsResponse, error) {
+	request := &emptypb.Empty{}
+	return clientcall.Call(ctx, c.call, "GetSearchStats", request, c.client.GetSearchStats, opts...)
+}
+
+// GetMostSearchedQueries получает самые популярные поисковые запросы
+func (c *Client) GetMostSearchedQueries(ctx context.Context, limit int32, opts ...grpc.CallOption) (*locationpb.MostSearchedQueriesResponse, error) {
+	request := &locationpb.GetMostSearchedQueriesRequest{Limit: limit}
+	return clientcall.Call(ctx, c.call, "GetMostSearchedQueries", request, c.client.GetMostSearchedQueries, opts...)
+}
@@ -7,8 +7,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/vladzorgan/common/grpc/interceptors"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/keepalive"
 )
 
@@ -19,12 +20,38 @@ type ServiceConfig struct {
 	Timeout     time.Duration `json:"timeout" yaml:"timeout"`
 	MaxRetries  int           `json:"max_retries" yaml:"max_retries"`
 	HealthCheck bool          `json:"health_check" yaml:"health_check"`
+	// TLS соединения; nil - незащищенное соединение
+	TLS *TLSConfig `json:"tls,omitempty" yaml:"tls,omitempty"`
+	// Политика балансировки: round_robin (по умолчанию) или pick_first
+	LoadBalancing string `json:"load_balancing,omitempty" yaml:"load_balancing,omitempty"`
+	// Явный список адресов host:port; если пуст, Address разрешается через DNS
+	Addresses []string `json:"addresses,omitempty" yaml:"addresses,omitempty"`
+}
+
+// RegistryOptions содержит настройки ClientRegistry
+type RegistryOptions struct {
+	// Максимальное время ожидания готовности нового соединения в GetConnection.
+	// Соединение создается без блокировки и возвращается по истечении времени, даже если сервис недоступен.
+	ConnectWait time.Duration
+	// Таймаут запроса к gRPC health сервису (для сервисов с HealthCheck)
+	HealthTimeout time.Duration
+}
+
+// DefaultRegistryOptions возвращает настройки по умолчанию
+func DefaultRegistryOptions() *RegistryOptions {
+	return &RegistryOptions{
+		ConnectWait:   time.Second,
+		HealthTimeout: 2 * time.Second,
+	}
 }
 
 // ClientRegistry централизованно управляет всеми gRPC клиентами
 type ClientRegistry struct {
 	connections map[string]*grpc.ClientConn
 	configs     map[string]*ServiceConfig
+	statuses    map[string]*ServiceStatus
+	options     *RegistryOptions
+	stopHealth  chan struct{}
 	mu          sync.RWMutex
 }
 
@@ -42,11 +69,22 @@ type BaseServiceClient struct {
 	registry    *ClientRegistry
 }
 
-// NewClientRegistry создает новый реестр клиентов
+// NewClientRegistry создает новый реестр клиентов с настройками по умолчанию
 func NewClientRegistry() *ClientRegistry {
+	return NewClientRegistryWithOptions(DefaultRegistryOptions())
+}
+
+// NewClientRegistryWithOptions создает новый реестр клиентов с указанными настройками
+func NewClientRegistryWithOptions(options *RegistryOptions) *ClientRegistry {
+	if options == nil {
+		options = DefaultRegistryOptions()
+	}
+
 	return &ClientRegistry{
 		connections: make(map[string]*grpc.ClientConn),
 		configs:     make(map[string]*ServiceConfig),
+		statuses:    make(map[string]*ServiceStatus),
+		options:     options,
 	}
 }
 
@@ -67,29 +105,53 @@ func (r *ClientRegistry) RegisterService(serviceName string, config *ServiceConf
 	log.Printf("Зарегистрирован сервис %s с адресом %s:%s", serviceName, config.Address, config.Port)
 }
 
-// GetConnection возвращает gRPC соединение для сервиса (создает при необходимости)
+// GetConnection возвращает gRPC соединение для сервиса (создает при необходимости).
+// Соединение создается без блокировки: недоступный при старте сервис не мешает запуску,
+// а новое соединение ожидается не дольше RegistryOptions.ConnectWait.
 func (r *ClientRegistry) GetConnection(serviceName string) (*grpc.ClientConn, error) {
 	r.mu.RLock()
-	if conn, exists := r.connections[serviceName]; exists {
+	if conn, exists := r.connections[serviceName]; exists && conn.GetState() != connectivity.Shutdown {
 		r.mu.RUnlock()
 		return conn, nil
 	}
 	r.mu.RUnlock()
 
 	// Создаем новое соединение
-	return r.createConnection(serviceName)
+	conn, created, err := r.createConnection(serviceName)
+	if err != nil || !created {
+		return conn, err
+	}
+
+	if r.options.ConnectWait > 0 && !waitForReady(conn, r.options.ConnectWait) {
+		log.Printf("Сервис %s пока недоступен, соединение будет установлено в фоне", serviceName)
+	}
+
+	return conn, nil
 }
 
-// createConnection создает новое gRPC соединение
-func (r *ClientRegistry) createConnection(serviceName string) (*grpc.ClientConn, error) {
+// createConnection создает новое gRPC соединение без ожидания подключения.
+// created равен false, если соединение уже создано другим вызовом.
+func (r *ClientRegistry) createConnection(serviceName string) (conn *grpc.ClientConn, created bool, err error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	// Проверяем еще раз под блокировкой
-	if conn, exists := r.connections[serviceName]; exists {
-		return conn, nil
+	if conn, exists := r.connections[serviceName]; exists && conn.GetState() != connectivity.Shutdown {
+		return conn, false, nil
 	}
 
+	conn, err = r.dial(serviceName)
+	if err != nil {
+		return nil, false, err
+	}
+
+	r.connections[serviceName] = conn
+	return conn, true, nil
+}
+
+// dial создает соединение с сервисом; вызывается под блокировкой реестра
+func (r *ClientRegistry) dial(serviceName string) (*grpc.ClientConn, error) {
+
 	config, exists := r.configs[serviceName]
 	if !exists {
 		return nil, fmt.Errorf("конфигурация для сервиса %s не найдена", serviceName)
@@ -104,30 +166,60 @@ func (r *ClientRegistry) createConnection(serviceName string) (*grpc.ClientConn,
 		PermitWithoutStream: true,
 	}
 
-	// Создаем контекст с таймаутом
-	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
-	defer cancel()
+	creds, err := config.TLS.transportCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка настройки TLS для сервиса %s: %w", serviceName, err)
+	}
+
+	target, balancingOptions, err := balancedTarget(target, config.LoadBalancing, config.Addresses)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка настройки балансировки для сервиса %s: %w", serviceName, err)
+	}
 
 	// Опции подключения
 	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
 		grpc.WithKeepaliveParams(kacp),
-		grpc.WithBlock(), // Ждем подключения
+		// Таймаут ограничивает вызов целиком, включая повторы
+		grpc.WithChainUnaryInterceptor(TimeoutInterceptor(config.Timeout)),
+		// Request ID, логирование, метрики и повторы для всех исходящих вызовов
+		grpc.WithChainUnaryInterceptor(interceptors.DefaultUnaryClientInterceptors(nil, &interceptors.RetryOptions{
+			MaxRetries: config.MaxRetries,
+			Backoff:    100 * time.Millisecond,
+			MaxBackoff: 2 * time.Second,
+		})...),
 	}
+	opts = append(opts, balancingOptions...)
 
 	log.Printf("Подключение к сервису %s по адресу %s", serviceName, target)
 
-	conn, err := grpc.DialContext(ctx, target, opts...)
+	conn, err := grpc.DialContext(context.Background(), target, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка подключения к сервису %s: %w", serviceName, err)
 	}
 
-	r.connections[serviceName] = conn
-	log.Printf("Успешно подключен к сервису %s", serviceName)
+	// Начинаем подключение сразу, не дожидаясь первого вызова
+	conn.Connect()
 
 	return conn, nil
 }
 
+// waitForReady ожидает готовности соединения не дольше timeout
+func waitForReady(conn *grpc.ClientConn, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return true
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return false
+		}
+	}
+}
+
 // CreateClient создает клиент для указанного сервиса
 func (r *ClientRegistry) CreateClient(serviceName string) (*BaseServiceClient, error) {
 	conn, err := r.GetConnection(serviceName)
@@ -142,6 +234,20 @@ func (r *ClientRegistry) CreateClient(serviceName string) (*BaseServiceClient, e
 	}, nil
 }
 
+// Typed возвращает типизированный клиент сервиса поверх соединения реестра.
+// Конструкторы клиентов находятся в подпакетах, чтобы реестр не зависел от proto-типов:
+//
+//	client, err := grpc_clients.Typed(registry, location.ServiceName, location.New)
+func Typed[T any](r *ClientRegistry, serviceName string, newClient func(grpc.ClientConnInterface) T) (T, error) {
+	conn, err := r.GetConnection(serviceName)
+	if err != nil {
+		var empty T
+		return empty, err
+	}
+
+	return newClient(conn), nil
+}
+
 // Close закрывает соединение для сервиса
 func (r *ClientRegistry) Close(serviceName string) error {
 	r.mu.Lock()
@@ -149,16 +255,22 @@ func (r *ClientRegistry) Close(serviceName string) error {
 
 	if conn, exists := r.connections[serviceName]; exists {
 		delete(r.connections, serviceName)
+		delete(r.statuses, serviceName)
 		return conn.Close()
 	}
 	return nil
 }
 
-// CloseAll закрывает все соединения
+// CloseAll закрывает все соединения и останавливает фоновую проверку
 func (r *ClientRegistry) CloseAll() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if r.stopHealth != nil {
+		close(r.stopHealth)
+		r.stopHealth = nil
+	}
+
 	for serviceName, conn := range r.connections {
 		if err := conn.Close(); err != nil {
 			log.Printf("Ошибка при закрытии соединения с сервисом %s: %v", serviceName, err)
@@ -167,6 +279,7 @@ func (r *ClientRegistry) CloseAll() {
 		}
 	}
 	r.connections = make(map[string]*grpc.ClientConn)
+	r.statuses = make(map[string]*ServiceStatus)
 }
 
 // GetAllServices возвращает список всех зарегистрированных сервисов
@@ -181,6 +294,20 @@ func (r *ClientRegistry) GetAllServices() []string {
 	return services
 }
 
+// ConnectionCount возвращает количество открытых соединений (для метрики grpc_client_connections)
+func (r *ClientRegistry) ConnectionCount() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, conn := range r.connections {
+		if conn.GetState() != connectivity.Shutdown {
+			count++
+		}
+	}
+	return count
+}
+
 // Реализация BaseServiceClient
 
 // Close закрывает соединение клиента
@@ -0,0 +1,210 @@
+package grpc_clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// StateNotConnected состояние сервиса, к которому еще не было обращений
+const StateNotConnected = "NOT_CONNECTED"
+
+// ServiceStatus содержит состояние соединения с сервисом
+type ServiceStatus struct {
+	Service string `json:"service"`
+	Target  string `json:"target"`
+	// Состояние соединения gRPC (READY, CONNECTING, TRANSIENT_FAILURE и т.д.) или NOT_CONNECTED
+	State   string `json:"state"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+	// Время последней фоновой проверки (нулевое, если проверка не выполнялась)
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+}
+
+// StartHealthLoop запускает фоновую проверку соединений с интервалом interval.
+// Закрытые соединения пересоздаются, для соединений в TRANSIENT_FAILURE сбрасывается задержка
+// переподключения, а для сервисов с HealthCheck дополнительно вызывается gRPC health сервис.
+// Проверка останавливается в CloseAll.
+func (r *ClientRegistry) StartHealthLoop(interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopHealth != nil || interval <= 0 {
+		return
+	}
+
+	stop := make(chan struct{})
+	r.stopHealth = stop
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				r.CheckConnections(context.Background())
+			}
+		}
+	}()
+}
+
+// CheckConnections однократно проверяет все созданные соединения (см. StartHealthLoop)
+func (r *ClientRegistry) CheckConnections(ctx context.Context) {
+	r.mu.Lock()
+	if err := r.reconnectClosed(); err != nil {
+		log.Printf("Ошибка переподключения: %v", err)
+	}
+	connections := make(map[string]*grpc.ClientConn, len(r.connections))
+	for serviceName, conn := range r.connections {
+		connections[serviceName] = conn
+	}
+	r.mu.Unlock()
+
+	for serviceName, conn := range connections {
+		status := r.checkConnection(ctx, serviceName, conn)
+
+		r.mu.Lock()
+		previous := r.statuses[serviceName]
+		r.statuses[serviceName] = status
+		r.mu.Unlock()
+
+		if previous != nil && previous.Healthy != status.Healthy {
+			if status.Healthy {
+				log.Printf("Соединение с сервисом %s восстановлено", serviceName)
+			} else {
+				log.Printf("Сервис %s недоступен: %s", serviceName, status.Error)
+			}
+		}
+	}
+}
+
+// reconnectClosed пересоздает закрытые соединения; вызывается под блокировкой реестра
+func (r *ClientRegistry) reconnectClosed() error {
+	var errs []error
+	for serviceName, conn := range r.connections {
+		if conn.GetState() != connectivity.Shutdown {
+			continue
+		}
+
+		newConn, err := r.dial(serviceName)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.connections[serviceName] = newConn
+		log.Printf("Соединение с сервисом %s пересоздано", serviceName)
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkConnection определяет состояние соединения и при необходимости вызывает gRPC health сервис
+func (r *ClientRegistry) checkConnection(ctx context.Context, serviceName string, conn *grpc.ClientConn) *ServiceStatus {
+	state := conn.GetState()
+	status := &ServiceStatus{
+		Service:   serviceName,
+		Target:    conn.Target(),
+		State:     state.String(),
+		Healthy:   stateHealthy(state),
+		CheckedAt: time.Now(),
+	}
+
+	switch state {
+	case connectivity.TransientFailure:
+		// Пробуем переподключиться сразу, не дожидаясь окончания задержки gRPC
+		conn.ResetConnectBackoff()
+		status.Error = "connection is in transient failure"
+		return status
+	case connectivity.Idle:
+		conn.Connect()
+	}
+
+	r.mu.RLock()
+	config := r.configs[serviceName]
+	r.mu.RUnlock()
+
+	if status.Healthy && config != nil && config.HealthCheck {
+		checkCtx, cancel := context.WithTimeout(ctx, r.options.HealthTimeout)
+		defer cancel()
+
+		resp, err := healthpb.NewHealthClient(conn).Check(checkCtx, &healthpb.HealthCheckRequest{})
+		switch {
+		case err != nil:
+			status.Healthy = false
+			status.Error = fmt.Sprintf("health check failed: %v", err)
+		case resp.GetStatus() != healthpb.HealthCheckResponse_SERVING:
+			status.Healthy = false
+			status.Error = fmt.Sprintf("health check status: %s", resp.GetStatus())
+		}
+	}
+
+	return status
+}
+
+// RegistryStatus возвращает состояние всех зарегистрированных сервисов для /health.
+// Для сервисов с фоновой проверкой возвращается ее результат, для остальных - текущее состояние соединения.
+// Сервисы, к которым еще не было обращений, считаются исправными.
+func (r *ClientRegistry) RegistryStatus() map[string]ServiceStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]ServiceStatus, len(r.configs))
+	for serviceName, config := range r.configs {
+		conn, connected := r.connections[serviceName]
+		if !connected {
+			result[serviceName] = ServiceStatus{
+				Service: serviceName,
+				Target:  fmt.Sprintf("%s:%s", config.Address, config.Port),
+				State:   StateNotConnected,
+				Healthy: true,
+			}
+			continue
+		}
+
+		state := conn.GetState()
+		if checked, ok := r.statuses[serviceName]; ok && checked.State == state.String() {
+			result[serviceName] = *checked
+			continue
+		}
+
+		status := ServiceStatus{
+			Service: serviceName,
+			Target:  conn.Target(),
+			State:   state.String(),
+			Healthy: stateHealthy(state),
+		}
+		if !status.Healthy {
+			status.Error = fmt.Sprintf("connection state is %s", state)
+		}
+		result[serviceName] = status
+	}
+
+	return result
+}
+
+// stateHealthy проверяет, что соединение готово к вызовам или подключится при первом вызове
+func stateHealthy(state connectivity.State) bool {
+	return state == connectivity.Ready || state == connectivity.Idle
+}
+
+// unhealthyServices возвращает отсортированные имена неисправных сервисов
+func unhealthyServices(statuses map[string]ServiceStatus) []string {
+	var names []string
+	for serviceName, status := range statuses {
+		if !status.Healthy {
+			names = append(names, serviceName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,117 @@
+package grpc_clients
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/vladzorgan/common/health"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// startHealthServer запускает gRPC сервер с health сервисом и возвращает его порт
+func startHealthServer(t *testing.T) (*grpchealth.Server, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	healthServer := grpchealth.NewServer()
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	return healthServer, port
+}
+
+// unusedPort возвращает порт, на котором никто не слушает
+func unusedPort(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+	return port
+}
+
+func TestGetConnectionDoesNotBlockOnUnavailableService(t *testing.T) {
+	registry := NewClientRegistryWithOptions(&RegistryOptions{ConnectWait: 50 * time.Millisecond, HealthTimeout: time.Second})
+	defer registry.CloseAll()
+	registry.RegisterService("down-service", &ServiceConfig{Address: "127.0.0.1", Port: unusedPort(t)})
+	registry.RegisterService("idle-service", &ServiceConfig{Address: "127.0.0.1", Port: unusedPort(t)})
+
+	start := time.Now()
+	if _, err := registry.GetConnection("down-service"); err != nil {
+		t.Fatalf("GetConnection: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetConnection blocked for %s", elapsed)
+	}
+
+	registry.CheckConnections(context.Background())
+	statuses := registry.RegistryStatus()
+	if statuses["down-service"].Healthy {
+		t.Errorf("down-service status = %+v", statuses["down-service"])
+	}
+	if status := statuses["idle-service"]; !status.Healthy || status.State != StateNotConnected {
+		t.Errorf("idle-service status = %+v", status)
+	}
+
+	component := NewRegistryComponent("grpc_clients", registry, false)
+	if status, err := component.Check(context.Background()); status != health.StatusDown || err == nil {
+		t.Errorf("component status = %s, %v", status, err)
+	}
+}
+
+func TestCheckConnectionsUsesHealthService(t *testing.T) {
+	healthServer, port := startHealthServer(t)
+
+	registry := NewClientRegistry()
+	defer registry.CloseAll()
+	registry.RegisterService("orders", &ServiceConfig{Address: "127.0.0.1", Port: port, HealthCheck: true})
+
+	if _, err := registry.GetConnection("orders"); err != nil {
+		t.Fatalf("GetConnection: %v", err)
+	}
+
+	registry.CheckConnections(context.Background())
+	if status := registry.RegistryStatus()["orders"]; !status.Healthy || status.State != "READY" {
+		t.Errorf("status = %+v", status)
+	}
+
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	registry.CheckConnections(context.Background())
+	if status := registry.RegistryStatus()["orders"]; status.Healthy || status.Error == "" {
+		t.Errorf("not serving status = %+v", status)
+	}
+}
+
+func TestCheckConnectionsRecreatesClosedConnection(t *testing.T) {
+	_, port := startHealthServer(t)
+
+	registry := NewClientRegistry()
+	defer registry.CloseAll()
+	registry.RegisterService("orders", &ServiceConfig{Address: "127.0.0.1", Port: port})
+
+	conn, err := registry.GetConnection("orders")
+	if err != nil {
+		t.Fatalf("GetConnection: %v", err)
+	}
+	conn.Close()
+
+	registry.CheckConnections(context.Background())
+	newConn, err := registry.GetConnection("orders")
+	if err != nil || newConn == conn {
+		t.Fatalf("connection was not recreated: %v", err)
+	}
+}
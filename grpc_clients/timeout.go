@@ -0,0 +1,65 @@
+package grpc_clients
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// DefaultCallTimeout таймаут вызова для сервисов без настроенного Timeout
+const DefaultCallTimeout = 10 * time.Second
+
+// timeoutCallOption переопределяет таймаут одного вызова
+type timeoutCallOption struct {
+	grpc.EmptyCallOption
+	timeout time.Duration
+}
+
+// WithTimeout задает таймаут одного вызова вместо таймаута сервиса из конфигурации:
+//
+//	client.GetRegion(ctx, req, grpc_clients.WithTimeout(2*time.Second))
+//
+// Более короткий дедлайн вызывающего контекста сохраняется.
+func WithTimeout(timeout time.Duration) grpc.CallOption {
+	return timeoutCallOption{timeout: timeout}
+}
+
+// TimeoutInterceptor ограничивает время вызова, включая повторные попытки интерцептора.
+// Для вызовов через Call каждая попытка проходит интерцептор отдельно, поэтому таймаут действует на попытку.
+// Таймаут из WithTimeout применяется всегда, таймаут сервиса - только если у контекста нет дедлайна.
+func TimeoutInterceptor(defaultTimeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		timeout := time.Duration(0)
+		for _, opt := range opts {
+			if o, ok := opt.(timeoutCallOption); ok {
+				timeout = o.timeout
+			}
+		}
+
+		if timeout <= 0 {
+			if _, ok := ctx.Deadline(); ok {
+				return invoker(ctx, method, req, reply, cc, opts...)
+			}
+			timeout = defaultTimeout
+		}
+
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// CallTimeout возвращает таймаут вызовов сервиса из конфигурации или DefaultCallTimeout
+func (c *Config) CallTimeout(serviceName string) time.Duration {
+	if c != nil {
+		if service, ok := c.Services[serviceName]; ok && service.Timeout > 0 {
+			return service.Timeout
+		}
+	}
+	return DefaultCallTimeout
+}
@@ -0,0 +1,156 @@
+package grpc_clients
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/vladzorgan/common/grpc_clients/location"
+	locationpb "github.com/vladzorgan/common/proto/location"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// slowLocationServer отвечает на GetRegion с задержкой и сообщает оставшееся до дедлайна время
+type slowLocationServer struct {
+	locationpb.UnimplementedLocationServiceServer
+	delay     time.Duration
+	deadlines chan time.Duration
+}
+
+func (s *slowLocationServer) GetRegion(ctx context.Context, req *locationpb.GetRegionRequest) (*locationpb.RegionResponse, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		s.deadlines <- time.Until(deadline)
+	} else {
+		s.deadlines <- 0
+	}
+
+	select {
+	case <-time.After(s.delay):
+		return &locationpb.RegionResponse{Id: req.Id}, nil
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// testLocationClient клиент location-service поверх BaseClient, как его собирают сервисы
+type testLocationClient struct {
+	*BaseClient
+	*location.Client
+}
+
+func newTestLocationClient(t *testing.T, timeout, delay time.Duration) (*testLocationClient, *slowLocationServer) {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	impl := &slowLocationServer{delay: delay, deadlines: make(chan time.Duration, 16)}
+	locationpb.RegisterLocationServiceServer(server, impl)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	cfg := DefaultConfig()
+	cfg.Services[location.URLKey] = ServiceConfigBase{Timeout: timeout}
+
+	base, err := NewBaseClientWithOptions(cfg, location.ServiceName, location.URLKey, location.DefaultPort,
+		WithLogging(false),
+		WithRetryOptions(nil),
+		// Явный адрес обходит разрешение имени через DNS: соединение идет через bufconn
+		WithAddresses(location.ServiceName+":"+location.DefaultPort),
+		WithDialOptions(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		})),
+	)
+	if err != nil {
+		t.Fatalf("NewBaseClientWithOptions() error = %v", err)
+	}
+	t.Cleanup(func() { base.Close() })
+
+	return &testLocationClient{BaseClient: base, Client: location.New(base.Conn)}, impl
+}
+
+func TestServiceTimeoutApplied(t *testing.T) {
+	client, server := newTestLocationClient(t, 50*time.Millisecond, time.Second)
+
+	if client.Timeout != 50*time.Millisecond {
+		t.Fatalf("client timeout = %v, want 50ms", client.Timeout)
+	}
+
+	_, err := client.GetRegion(context.Background(), 1)
+	if status.Code(unwrapStatus(err)) != codes.DeadlineExceeded {
+		t.Fatalf("GetRegion() error = %v, want DeadlineExceeded", err)
+	}
+	if remaining := <-server.deadlines; remaining <= 0 || remaining > 50*time.Millisecond {
+		t.Errorf("server deadline in %v, want within 50ms", remaining)
+	}
+}
+
+func TestCallerDeadlineRespected(t *testing.T) {
+	client, server := newTestLocationClient(t, time.Minute, time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetRegion(ctx, 1)
+	if status.Code(unwrapStatus(err)) != codes.DeadlineExceeded {
+		t.Fatalf("GetRegion() error = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("call took %v, caller deadline was ignored", elapsed)
+	}
+	if remaining := <-server.deadlines; remaining > 30*time.Millisecond {
+		t.Errorf("server deadline in %v, want caller's 30ms", remaining)
+	}
+}
+
+func TestWithTimeoutOverridesServiceTimeout(t *testing.T) {
+	client, server := newTestLocationClient(t, 20*time.Millisecond, 50*time.Millisecond)
+
+	resp, err := client.GetRegion(context.Background(), 7, WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("GetRegion() error = %v", err)
+	}
+	if resp.Id != 7 {
+		t.Errorf("GetRegion() id = %d, want 7", resp.Id)
+	}
+	if remaining := <-server.deadlines; remaining <= 20*time.Millisecond {
+		t.Errorf("server deadline in %v, want per-call 1s", remaining)
+	}
+
+	// Переопределение не продлевает более короткий дедлайн вызывающего
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetRegion(ctx, 7, WithTimeout(time.Second)); status.Code(unwrapStatus(err)) != codes.DeadlineExceeded {
+		t.Errorf("GetRegion() error = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestConfigCallTimeout(t *testing.T) {
+	var cfg *Config
+	if got := cfg.CallTimeout("any"); got != DefaultCallTimeout {
+		t.Errorf("nil config timeout = %v", got)
+	}
+	if got := DefaultLocationConfig().CallTimeout(location.ServiceName); got != 10*time.Second {
+		t.Errorf("location timeout = %v", got)
+	}
+}
+
+// unwrapStatus извлекает gRPC статус из ошибки, обернутой клиентом сервиса
+func unwrapStatus(err error) error {
+	type grpcStatus interface{ GRPCStatus() *status.Status }
+	for e := err; e != nil; {
+		if _, ok := e.(grpcStatus); ok {
+			return e
+		}
+		unwrapper, ok := e.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		e = unwrapper.Unwrap()
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package grpc_clients
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// TLSConfig настройки TLS соединения с сервисом. Nil означает незащищенное соединение.
+type TLSConfig struct {
+	// Удостоверяющий центр сертификата сервера (по умолчанию системные корневые сертификаты)
+	CAFile string `yaml:"ca_file" json:"ca_file"`
+	// Имя сервера для проверки сертификата (по умолчанию хост из адреса)
+	ServerName string `yaml:"server_name" json:"server_name"`
+	// Сертификат и ключ клиента для mTLS
+	CertFile string `yaml:"cert_file" json:"cert_file"`
+	KeyFile  string `yaml:"key_file" json:"key_file"`
+}
+
+// WithTLS включает TLS соединение с сервисом; сертификат сервера проверяется по caFile и serverName
+func WithTLS(caFile, serverName string) OptionFunc {
+	return func(o *ClientOptions) {
+		if o.TLS == nil {
+			o.TLS = &TLSConfig{}
+		}
+		o.TLS.CAFile = caFile
+		o.TLS.ServerName = serverName
+	}
+}
+
+// WithClientCertificate задает сертификат клиента для mTLS; включает TLS, если он еще не включен
+func WithClientCertificate(certFile, keyFile string) OptionFunc {
+	return func(o *ClientOptions) {
+		if o.TLS == nil {
+			o.TLS = &TLSConfig{}
+		}
+		o.TLS.CertFile = certFile
+		o.TLS.KeyFile = keyFile
+	}
+}
+
+// ServiceTLS возвращает настройки TLS сервиса из конфигурации или nil
+func (c *Config) ServiceTLS(serviceName string) *TLSConfig {
+	if c != nil {
+		if service, ok := c.Services[serviceName]; ok {
+			return service.TLS
+		}
+	}
+	return nil
+}
+
+// transportCredentials возвращает учетные данные соединения: TLS, если конфигурация задана, иначе незащищенные
+func (c *TLSConfig) transportCredentials() (credentials.TransportCredentials, error) {
+	if c == nil {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: c.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if c.CAFile != "" {
+		data, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no PEM certificates found in %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, errors.New("client certificate requires both CertFile and KeyFile")
+		}
+		certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
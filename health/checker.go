@@ -3,8 +3,11 @@ package health
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
+
+	"github.com/vladzorgan/common/killswitch"
 )
 
 // Status представляет статус компонента или сервиса
@@ -50,6 +53,29 @@ type HealthCheck struct {
 	Components    map[string]interface{} `json:"components"`
 }
 
+// CheckerOptions содержит опции сервиса проверки здоровья
+type CheckerOptions struct {
+	// Интервал фоновой проверки компонентов (0 - без фоновой проверки). Пока фоновая проверка
+	// обновляет результат, Check возвращает его без проверки компонентов.
+	CheckInterval time.Duration
+	// Время жизни кешированного результата (0 - без кеширования, а при фоновой проверке -
+	// два интервала CheckInterval)
+	CacheTTL time.Duration
+	// Таймаут проверки одного компонента
+	ComponentTimeout time.Duration
+	// Закрыть переключатель готовности до вызова SetReady(true)
+	StartNotReady bool
+}
+
+// DefaultCheckerOptions возвращает опции по умолчанию
+func DefaultCheckerOptions() *CheckerOptions {
+	return &CheckerOptions{
+		CheckInterval:    0,
+		CacheTTL:         0,
+		ComponentTimeout: 5 * time.Second,
+	}
+}
+
 // Checker представляет сервис для проверки здоровья
 type Checker struct {
 	startTime     time.Time
@@ -58,17 +84,77 @@ type Checker struct {
 	version       string
 	components    []Component
 	mutex         sync.RWMutex
+	options       *CheckerOptions
+	cached        *HealthCheck
+	cachedAt      time.Time
+	cacheMutex    sync.RWMutex
+	stopChan      chan struct{}
+	stopOnce      sync.Once
+	gate          *readinessGate
+	observers     []Observer
 }
 
+// Observer получает каждый результат проверки здоровья (фоновой или по запросу).
+// Вызывается синхронно, поэтому не должен блокироваться.
+type Observer func(check *HealthCheck)
+
 // NewChecker создает новый сервис проверки здоровья
 func NewChecker(serviceName, servicePrefix, version string) *Checker {
-	return &Checker{
+	return NewCheckerWithOptions(serviceName, servicePrefix, version, nil)
+}
+
+// NewCheckerWithOptions создает новый сервис проверки здоровья с опциями.
+// Если задан CheckInterval, компоненты проверяются в фоне, а Check возвращает кешированный результат.
+func NewCheckerWithOptions(serviceName, servicePrefix, version string, options *CheckerOptions) *Checker {
+	if options == nil {
+		options = DefaultCheckerOptions()
+	}
+
+	if options.ComponentTimeout <= 0 {
+		options.ComponentTimeout = DefaultCheckerOptions().ComponentTimeout
+	}
+
+	checker := &Checker{
 		startTime:     time.Now(),
 		serviceName:   serviceName,
 		servicePrefix: servicePrefix,
 		version:       version,
 		components:    make([]Component, 0),
+		options:       options,
+		stopChan:      make(chan struct{}),
+		gate:          newReadinessGate(!options.StartNotReady),
+	}
+
+	// Запускаем фоновую проверку компонентов
+	if options.CheckInterval > 0 {
+		go checker.refreshLoop()
 	}
+
+	return checker
+}
+
+// refreshLoop периодически обновляет кешированный результат проверки, начиная с немедленной
+// проверки, чтобы первые пробы уже получили результат из кеша
+func (c *Checker) refreshLoop() {
+	ticker := time.NewTicker(c.options.CheckInterval)
+	defer ticker.Stop()
+
+	c.CheckForce(context.Background())
+	for {
+		select {
+		case <-ticker.C:
+			c.CheckForce(context.Background())
+		case <-c.stopChan:
+			return
+		}
+	}
+}
+
+// Stop останавливает фоновую проверку компонентов
+func (c *Checker) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopChan)
+	})
 }
 
 // RegisterComponent регистрирует компонент для проверки
@@ -78,48 +164,80 @@ func (c *Checker) RegisterComponent(component Component) {
 	c.components = append(c.components, component)
 }
 
-// Check проверяет здоровье всех зарегистрированных компонентов
+// AddObserver регистрирует наблюдателя результатов проверки. Для регулярных результатов
+// без внешних запросов нужна фоновая проверка (CheckerOptions.CheckInterval).
+func (c *Checker) AddObserver(observer Observer) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.observers = append(c.observers, observer)
+}
+
+// Check возвращает результат проверки здоровья, используя кеш, если он включен и актуален.
+// Кеширование отключается выключателем killswitch.FeatureHealthCache.
 func (c *Checker) Check(ctx context.Context) (*HealthCheck, error) {
+	if ttl := c.cacheTTL(); ttl > 0 && !killswitch.IsDisabled(killswitch.FeatureHealthCache) {
+		c.cacheMutex.RLock()
+		cached, cachedAt := c.cached, c.cachedAt
+		c.cacheMutex.RUnlock()
+
+		if cached != nil && time.Since(cachedAt) < ttl {
+			return cached, nil
+		}
+	}
+
+	return c.CheckForce(ctx)
+}
+
+// cacheTTL возвращает время жизни кешированного результата. При фоновой проверке без CacheTTL
+// результат живет два интервала: обновление не опаздывает к пробам, а зависшая фоновая
+// проверка не приводит к бесконечной выдаче устаревшего результата.
+func (c *Checker) cacheTTL() time.Duration {
+	if c.options.CacheTTL > 0 {
+		return c.options.CacheTTL
+	}
+	return 2 * c.options.CheckInterval
+}
+
+// CheckForce проверяет здоровье всех зарегистрированных компонентов в обход кеша.
+// Компоненты проверяются параллельно, каждый с ограничением ComponentTimeout.
+func (c *Checker) CheckForce(ctx context.Context) (*HealthCheck, error) {
 	startTime := time.Now()
 
 	// Копируем список компонентов для потокобезопасности
 	c.mutex.RLock()
 	components := make([]Component, len(c.components))
 	copy(components, c.components)
+	observers := c.observers
 	c.mutex.RUnlock()
 
+	// Проверяем компоненты параллельно
+	checkResults := make([]CheckResult, len(components))
+	var wg sync.WaitGroup
+	for i, component := range components {
+		wg.Add(1)
+		go func(i int, component Component) {
+			defer wg.Done()
+			checkResults[i] = c.checkComponent(ctx, component)
+		}(i, component)
+	}
+	wg.Wait()
+
 	results := make(map[string]interface{})
 	overallStatus := StatusUp
 
-	// Проверяем каждый компонент
-	for _, component := range components {
-		checkStartTime := time.Now()
-		status, err := component.Check(ctx)
-		duration := time.Since(checkStartTime).Milliseconds()
-
-		var errStr *string
-		if err != nil {
-			errMsg := err.Error()
-			errStr = &errMsg
-		}
-
-		results[component.Name()] = CheckResult{
-			Component: component.Name(),
-			Status:    status,
-			Error:     errStr,
-			Time:      checkStartTime,
-			Duration:  duration,
-		}
+	for i, component := range components {
+		result := checkResults[i]
+		results[component.Name()] = result
 
 		// Определение общего статуса
-		if status == StatusDown && component.IsCritical() {
+		if result.Status == StatusDown && component.IsCritical() {
 			overallStatus = StatusDown
-		} else if status == StatusDegraded && overallStatus != StatusDown {
+		} else if result.Status == StatusDegraded && overallStatus != StatusDown {
 			overallStatus = StatusDegraded
 		}
 	}
 
-	return &HealthCheck{
+	healthCheck := &HealthCheck{
 		Status:        overallStatus,
 		ServiceName:   c.serviceName,
 		ServicePrefix: c.servicePrefix,
@@ -128,5 +246,61 @@ func (c *Checker) Check(ctx context.Context) (*HealthCheck, error) {
 		Timestamp:     float64(time.Now().Unix()),
 		CheckDuration: float64(time.Since(startTime).Milliseconds()),
 		Components:    results,
-	}, nil
+	}
+
+	// Сохраняем результат в кеш
+	c.cacheMutex.Lock()
+	c.cached = healthCheck
+	c.cachedAt = time.Now()
+	c.cacheMutex.Unlock()
+
+	for _, observer := range observers {
+		observer(healthCheck)
+	}
+
+	return healthCheck, nil
+}
+
+// checkComponent проверяет компонент с ограничением по времени
+func (c *Checker) checkComponent(ctx context.Context, component Component) CheckResult {
+	checkStartTime := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, c.options.ComponentTimeout)
+	defer cancel()
+
+	type checkOutcome struct {
+		status Status
+		err    error
+	}
+
+	// Буферизованный канал позволяет зависшей проверке завершиться без утечки блокировки
+	outcomeChan := make(chan checkOutcome, 1)
+	go func() {
+		status, err := component.Check(ctx)
+		outcomeChan <- checkOutcome{status: status, err: err}
+	}()
+
+	var outcome checkOutcome
+	select {
+	case outcome = <-outcomeChan:
+	case <-ctx.Done():
+		outcome = checkOutcome{
+			status: StatusDown,
+			err:    fmt.Errorf("check timed out after %v", c.options.ComponentTimeout),
+		}
+	}
+
+	var errStr *string
+	if outcome.err != nil {
+		errMsg := outcome.err.Error()
+		errStr = &errMsg
+	}
+
+	return CheckResult{
+		Component: component.Name(),
+		Status:    outcome.status,
+		Error:     errStr,
+		Time:      checkStartTime,
+		Duration:  time.Since(checkStartTime).Milliseconds(),
+	}
 }
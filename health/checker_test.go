@@ -0,0 +1,137 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// countingComponent считает проверки и выполняет каждую за delay
+type countingComponent struct {
+	name     string
+	delay    time.Duration
+	critical bool
+	checks   atomic.Int32
+}
+
+func (c *countingComponent) Name() string     { return c.name }
+func (c *countingComponent) IsCritical() bool { return c.critical }
+
+func (c *countingComponent) Check(ctx context.Context) (Status, error) {
+	c.checks.Add(1)
+	select {
+	case <-time.After(c.delay):
+		return StatusUp, nil
+	case <-ctx.Done():
+		return StatusDown, ctx.Err()
+	}
+}
+
+func TestCheckServesBackgroundResults(t *testing.T) {
+	component := &countingComponent{name: "rabbitmq"}
+	checker := NewCheckerWithOptions("test", "test", "1.0.0", &CheckerOptions{CheckInterval: time.Hour})
+	checker.RegisterComponent(component)
+	defer checker.Stop()
+
+	// Первая фоновая проверка выполняется сразу после запуска
+	deadline := time.Now().Add(time.Second)
+	for component.checks.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	// Компонент зарегистрирован после запуска, поэтому ждем результат с ним
+	checker.CheckForce(context.Background())
+	before := component.checks.Load()
+
+	for i := 0; i < 10; i++ {
+		result, err := checker.Check(context.Background())
+		if err != nil || result.Status != StatusUp {
+			t.Fatalf("Check() = %+v, %v", result, err)
+		}
+	}
+	if got := component.checks.Load(); got != before {
+		t.Errorf("component checked %d times by probes, want results from the background check", got-before)
+	}
+}
+
+func TestCheckCacheTTL(t *testing.T) {
+	component := &countingComponent{name: "database"}
+	checker := NewCheckerWithOptions("test", "test", "1.0.0", &CheckerOptions{CacheTTL: 50 * time.Millisecond})
+	checker.RegisterComponent(component)
+
+	checker.Check(context.Background())
+	checker.Check(context.Background())
+	if got := component.checks.Load(); got != 1 {
+		t.Fatalf("checks within TTL = %d, want 1", got)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	checker.Check(context.Background())
+	if got := component.checks.Load(); got != 2 {
+		t.Errorf("checks after TTL = %d, want 2", got)
+	}
+}
+
+func TestCheckWithoutCacheRunsEveryTime(t *testing.T) {
+	component := &countingComponent{name: "redis"}
+	checker := NewChecker("test", "test", "1.0.0")
+	checker.RegisterComponent(component)
+
+	checker.Check(context.Background())
+	checker.Check(context.Background())
+	if got := component.checks.Load(); got != 2 {
+		t.Errorf("checks = %d, want 2", got)
+	}
+}
+
+func TestCheckComponentsConcurrentlyWithTimeout(t *testing.T) {
+	checker := NewCheckerWithOptions("test", "test", "1.0.0", &CheckerOptions{ComponentTimeout: 100 * time.Millisecond})
+	checker.RegisterComponent(&countingComponent{name: "first", delay: 50 * time.Millisecond, critical: true})
+	checker.RegisterComponent(&countingComponent{name: "second", delay: 50 * time.Millisecond, critical: true})
+	checker.RegisterComponent(&countingComponent{name: "hanging", delay: time.Hour, critical: true})
+
+	start := time.Now()
+	result, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 180*time.Millisecond {
+		t.Errorf("Check() took %v, want components checked concurrently within the timeout", elapsed)
+	}
+
+	if result.Status != StatusDown {
+		t.Errorf("status = %s, want down because of the hanging critical component", result.Status)
+	}
+	hanging := result.Components["hanging"].(CheckResult)
+	if hanging.Status != StatusDown || hanging.Error == nil {
+		t.Errorf("hanging component = %+v, want down with timeout error", hanging)
+	}
+	if first := result.Components["first"].(CheckResult); first.Status != StatusUp {
+		t.Errorf("first component = %+v, want up", first)
+	}
+}
+
+func TestHealthHandlerForceBypassesCache(t *testing.T) {
+	component := &countingComponent{name: "database"}
+	checker := NewCheckerWithOptions("test", "test", "1.0.0", &CheckerOptions{CacheTTL: time.Hour})
+	checker.RegisterComponent(component)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHTTPHandler(checker).RegisterHandlers(router)
+
+	for _, target := range []string{"/health", "/health", "/readiness", "/health?force=true"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d", target, rec.Code)
+		}
+	}
+	if got := component.checks.Load(); got != 2 {
+		t.Errorf("checks = %d, want 2 (cached probes and one forced check)", got)
+	}
+}
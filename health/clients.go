@@ -3,6 +3,7 @@ package health
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -55,6 +56,52 @@ func (c *DatabaseComponent) IsCritical() bool {
 	return c.critical
 }
 
+// DatabaseReplicasComponent представляет компонент проверки реплик базы данных
+type DatabaseReplicasComponent struct {
+	name     string
+	replicas []*gorm.DB
+	critical bool
+}
+
+// NewDatabaseReplicasComponent создает компонент проверки реплик (например, database.Database.Replicas())
+func NewDatabaseReplicasComponent(name string, replicas []*gorm.DB, critical bool) *DatabaseReplicasComponent {
+	return &DatabaseReplicasComponent{
+		name:     name,
+		replicas: replicas,
+		critical: critical,
+	}
+}
+
+// Name возвращает имя компонента
+func (c *DatabaseReplicasComponent) Name() string {
+	return c.name
+}
+
+// Check возвращает StatusDown, если недоступны все реплики, и StatusDegraded, если недоступна часть из них
+func (c *DatabaseReplicasComponent) Check(ctx context.Context) (Status, error) {
+	var failed []error
+	for i, replica := range c.replicas {
+		status, err := NewDatabaseComponent(c.name, replica, c.critical).Check(ctx)
+		if status != StatusUp {
+			failed = append(failed, fmt.Errorf("replica %d: %v", i, err))
+		}
+	}
+
+	switch {
+	case len(failed) == 0:
+		return StatusUp, nil
+	case len(failed) == len(c.replicas):
+		return StatusDown, errors.Join(failed...)
+	default:
+		return StatusDegraded, errors.Join(failed...)
+	}
+}
+
+// IsCritical возвращает true, если компонент критичен для работы сервиса
+func (c *DatabaseReplicasComponent) IsCritical() bool {
+	return c.critical
+}
+
 // SQLDatabaseComponent представляет компонент проверки SQL базы данных
 type SQLDatabaseComponent struct {
 	name     string
@@ -96,12 +143,13 @@ func (c *SQLDatabaseComponent) IsCritical() bool {
 // RedisComponent представляет компонент проверки Redis
 type RedisComponent struct {
 	name     string
-	client   *redis.Client
+	client   redis.UniversalClient
 	critical bool
 }
 
-// NewRedisComponent создает новый компонент для проверки Redis
-func NewRedisComponent(name string, client *redis.Client, critical bool) *RedisComponent {
+// NewRedisComponent создает новый компонент для проверки Redis.
+// Принимает одиночный клиент, клиент Sentinel или Cluster (например, redis.Client.Client() из common/redis).
+func NewRedisComponent(name string, client redis.UniversalClient, critical bool) *RedisComponent {
 	return &RedisComponent{
 		name:     name,
 		client:   client,
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"context"
+	"sync"
+)
+
+// Причины, по которым ручной переключатель готовности закрыт
+const (
+	// ReasonStarting сервис еще не завершил запуск (миграции, подписки потребителей)
+	ReasonStarting = "starting"
+	// ReasonShuttingDown сервис останавливается и не должен получать новые запросы
+	ReasonShuttingDown = "shutting_down"
+)
+
+// readinessGate ручной переключатель готовности, который объединяется по И с проверками компонентов
+type readinessGate struct {
+	mutex    sync.Mutex
+	ready    bool
+	wasReady bool
+	// readyChan закрывается, когда переключатель открыт
+	readyChan chan struct{}
+}
+
+func newReadinessGate(ready bool) *readinessGate {
+	gate := &readinessGate{readyChan: make(chan struct{})}
+	if ready {
+		gate.set(true)
+	}
+	return gate
+}
+
+func (g *readinessGate) set(ready bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.ready == ready {
+		return
+	}
+
+	g.ready = ready
+	if ready {
+		g.wasReady = true
+		close(g.readyChan)
+	} else {
+		g.readyChan = make(chan struct{})
+	}
+}
+
+func (g *readinessGate) state() (bool, string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	switch {
+	case g.ready:
+		return true, ""
+	case g.wasReady:
+		return false, ReasonShuttingDown
+	default:
+		return false, ReasonStarting
+	}
+}
+
+func (g *readinessGate) wait() <-chan struct{} {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.readyChan
+}
+
+// SetReady открывает или закрывает переключатель готовности. Пока он закрыт, /readiness
+// возвращает 503 независимо от проверок компонентов: с причиной "starting", если сервис
+// еще не был готов, и "shutting_down" после этого.
+func (c *Checker) SetReady(ready bool) {
+	c.gate.set(ready)
+}
+
+// Ready возвращает состояние переключателя готовности и причину, если он закрыт
+func (c *Checker) Ready() (bool, string) {
+	return c.gate.state()
+}
+
+// WaitUntilReady ждет открытия переключателя готовности или отмены ctx
+func (c *Checker) WaitUntilReady(ctx context.Context) error {
+	select {
+	case <-c.gate.wait():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
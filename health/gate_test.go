@@ -0,0 +1,74 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func readiness(t *testing.T, checker *Checker) (int, map[string]interface{}) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHTTPHandler(checker).RegisterHandlers(router)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid readiness body %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, body
+}
+
+func TestReadinessGate(t *testing.T) {
+	checker := NewCheckerWithOptions("test", "test", "1.0.0", &CheckerOptions{StartNotReady: true})
+
+	if code, body := readiness(t, checker); code != http.StatusServiceUnavailable || body["reason"] != ReasonStarting {
+		t.Errorf("before start: %d %v, want 503 starting", code, body)
+	}
+
+	checker.SetReady(true)
+	if code, body := readiness(t, checker); code != http.StatusOK || body["reason"] != nil {
+		t.Errorf("ready: %d %v, want 200", code, body)
+	}
+
+	checker.SetReady(false)
+	if code, body := readiness(t, checker); code != http.StatusServiceUnavailable || body["reason"] != ReasonShuttingDown {
+		t.Errorf("shutting down: %d %v, want 503 shutting_down", code, body)
+	}
+}
+
+func TestReadinessGateDefaultsToReady(t *testing.T) {
+	if ready, _ := NewChecker("test", "test", "1.0.0").Ready(); !ready {
+		t.Error("checker without StartNotReady must be ready")
+	}
+}
+
+func TestWaitUntilReady(t *testing.T) {
+	checker := NewCheckerWithOptions("test", "test", "1.0.0", &CheckerOptions{StartNotReady: true})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := checker.WaitUntilReady(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("WaitUntilReady() error = %v, want deadline exceeded", err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		checker.SetReady(true)
+	}()
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := checker.WaitUntilReady(ctx); err != nil {
+		t.Errorf("WaitUntilReady() error = %v", err)
+	}
+}
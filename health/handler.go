@@ -25,12 +25,13 @@ func NewHTTPHandler(checker *Checker) *HTTPHandler {
 // @Tags health
 // @Accept json
 // @Produce json
+// @Param force query bool false "Выполнить проверку в обход кеша"
 // @Success 200 {object} HealthCheck
 // @Failure 503 {object} HealthCheck
 // @Router /health [get]
 func (h *HTTPHandler) HealthCheck(c *gin.Context) {
 	// Проверяем здоровье сервиса
-	health, err := h.checker.Check(c.Request.Context())
+	health, err := h.check(c)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
@@ -73,8 +74,18 @@ func (h *HTTPHandler) LivenessCheck(c *gin.Context) {
 // @Failure 503 {object} map[string]interface{}
 // @Router /readiness [get]
 func (h *HTTPHandler) ReadinessCheck(c *gin.Context) {
+	// Закрытый переключатель готовности имеет приоритет над проверками компонентов
+	if ready, reason := h.checker.Ready(); !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":    string(StatusDown),
+			"reason":    reason,
+			"timestamp": time.Now().Unix(),
+		})
+		return
+	}
+
 	// Проверяем здоровье сервиса
-	health, err := h.checker.Check(c.Request.Context())
+	health, err := h.check(c)
 	if err != nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"status":    "down",
@@ -96,6 +107,14 @@ func (h *HTTPHandler) ReadinessCheck(c *gin.Context) {
 	})
 }
 
+// check выполняет проверку здоровья; параметр ?force=true позволяет обойти кеш
+func (h *HTTPHandler) check(c *gin.Context) (*HealthCheck, error) {
+	if c.Query("force") == "true" {
+		return h.checker.CheckForce(c.Request.Context())
+	}
+	return h.checker.Check(c.Request.Context())
+}
+
 // RegisterHandlers регистрирует обработчики проверки здоровья в Gin роутере
 func (h *HTTPHandler) RegisterHandlers(router *gin.Engine) {
 	router.GET("/health", h.HealthCheck)
@@ -0,0 +1,266 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vladzorgan/common/logging"
+)
+
+// Notification уведомление о смене состояния компонента
+type Notification struct {
+	ServiceName string
+	Component   string
+	// Новое состояние: StatusDown при отказе, StatusUp при восстановлении
+	Status Status
+	// Ошибка последней проверки (только при отказе)
+	Error string
+	// Время, когда компонент перестал работать
+	DownSince time.Time
+	// Длительность отказа (только при восстановлении)
+	Downtime time.Duration
+	Time     time.Time
+}
+
+// NotificationSink доставляет уведомления о смене состояния компонентов
+type NotificationSink interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// NotifierOptions содержит настройки уведомлений о состоянии компонентов
+type NotifierOptions struct {
+	// Количество подряд идущих проверок с новым состоянием, после которого оно считается устойчивым
+	ConfirmChecks int
+	// Минимальный интервал между уведомлениями об одном компоненте.
+	// Смены состояния внутри интервала не отправляются, после него отправляется итоговое состояние.
+	Cooldown time.Duration
+	// Компоненты, о которых нужно уведомлять (пусто - все)
+	Components []string
+	// Таймаут отправки одного уведомления
+	SendTimeout time.Duration
+	// Размер очереди уведомлений; при переполнении уведомления отбрасываются
+	QueueSize int
+	// Логгер
+	Logger logging.Logger
+}
+
+// DefaultNotifierOptions возвращает опции по умолчанию
+func DefaultNotifierOptions() *NotifierOptions {
+	return &NotifierOptions{
+		ConfirmChecks: 2,
+		Cooldown:      5 * time.Minute,
+		SendTimeout:   10 * time.Second,
+		QueueSize:     100,
+	}
+}
+
+// componentState состояние компонента, отслеживаемое уведомителем
+type componentState struct {
+	// Последнее наблюдаемое состояние и количество проверок подряд с ним
+	observed bool
+	streak   int
+	// Первая проверка текущей серии отказов
+	downSince time.Time
+	// Состояние, о котором было отправлено последнее уведомление
+	reported     bool
+	reportedDown bool
+	notifiedAt   time.Time
+}
+
+// Notifier отправляет уведомления при отказе и восстановлении компонентов.
+// Результаты получает от Checker, поэтому для регулярных уведомлений нужна фоновая
+// проверка (CheckerOptions.CheckInterval). Уведомления отправляются асинхронно.
+type Notifier struct {
+	sinks      []NotificationSink
+	options    *NotifierOptions
+	components map[string]bool
+
+	mutex  sync.Mutex
+	states map[string]*componentState
+
+	queue    chan Notification
+	stopChan chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewNotifier создает уведомитель и подписывает его на результаты checker
+func NewNotifier(checker *Checker, options *NotifierOptions, sinks ...NotificationSink) *Notifier {
+	if options == nil {
+		options = DefaultNotifierOptions()
+	}
+	if options.ConfirmChecks < 1 {
+		options.ConfirmChecks = 1
+	}
+	if options.SendTimeout <= 0 {
+		options.SendTimeout = 10 * time.Second
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = 100
+	}
+	if options.Logger == nil {
+		options.Logger = logging.NewLogger()
+	}
+
+	n := &Notifier{
+		sinks:    sinks,
+		options:  options,
+		states:   make(map[string]*componentState),
+		queue:    make(chan Notification, options.QueueSize),
+		stopChan: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if len(options.Components) > 0 {
+		n.components = make(map[string]bool, len(options.Components))
+		for _, name := range options.Components {
+			n.components[name] = true
+		}
+	}
+
+	go n.sendLoop()
+	checker.AddObserver(n.Observe)
+
+	return n
+}
+
+// Observe обрабатывает результат проверки здоровья
+func (n *Notifier) Observe(check *HealthCheck) {
+	now := time.Now()
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	for name, value := range check.Components {
+		result, ok := value.(CheckResult)
+		if !ok || (n.components != nil && !n.components[name]) {
+			continue
+		}
+
+		if notification, ok := n.transition(name, result, now); ok {
+			notification.ServiceName = check.ServiceName
+			n.enqueue(notification)
+		}
+	}
+}
+
+// transition обновляет состояние компонента и возвращает уведомление, если его нужно отправить
+func (n *Notifier) transition(name string, result CheckResult, now time.Time) (Notification, bool) {
+	state, ok := n.states[name]
+	if !ok {
+		state = &componentState{}
+		n.states[name] = state
+	}
+
+	down := result.Status == StatusDown
+	if state.streak == 0 || state.observed != down {
+		state.observed = down
+		state.streak = 0
+		if down && (!state.reported || !state.reportedDown) {
+			state.downSince = result.Time
+		}
+	}
+	state.streak++
+
+	if state.streak < n.options.ConfirmChecks {
+		return Notification{}, false
+	}
+
+	// Первое устойчивое состояние работающего компонента не сообщается
+	if !state.reported && !down {
+		state.reported = true
+		return Notification{}, false
+	}
+	if state.reported && state.reportedDown == down {
+		return Notification{}, false
+	}
+	if !state.notifiedAt.IsZero() && now.Sub(state.notifiedAt) < n.options.Cooldown {
+		return Notification{}, false
+	}
+
+	state.reported = true
+	state.reportedDown = down
+	state.notifiedAt = now
+
+	notification := Notification{
+		Component: name,
+		Status:    StatusUp,
+		DownSince: state.downSince,
+		Time:      now,
+	}
+	if down {
+		notification.Status = StatusDown
+		if result.Error != nil {
+			notification.Error = *result.Error
+		}
+	} else {
+		notification.Downtime = now.Sub(state.downSince)
+	}
+
+	return notification, true
+}
+
+// enqueue ставит уведомление в очередь отправки
+func (n *Notifier) enqueue(notification Notification) {
+	select {
+	case n.queue <- notification:
+	default:
+		n.options.Logger.Warn("Health notification queue is full, dropping notification for %s", notification.Component)
+	}
+}
+
+// sendLoop отправляет уведомления из очереди
+func (n *Notifier) sendLoop() {
+	defer close(n.done)
+
+	for {
+		select {
+		case notification := <-n.queue:
+			n.send(notification)
+		case <-n.stopChan:
+			return
+		}
+	}
+}
+
+func (n *Notifier) send(notification Notification) {
+	for _, sink := range n.sinks {
+		ctx, cancel := context.WithTimeout(context.Background(), n.options.SendTimeout)
+		if err := sink.Notify(ctx, notification); err != nil {
+			n.options.Logger.Error("Failed to send health notification for %s: %v", notification.Component, err)
+		}
+		cancel()
+	}
+}
+
+// Stop останавливает отправку уведомлений и ждет завершения текущей
+func (n *Notifier) Stop() {
+	n.stopOnce.Do(func() {
+		close(n.stopChan)
+	})
+	<-n.done
+}
+
+// LogSink пишет уведомления о состоянии компонентов в лог
+type LogSink struct {
+	logger logging.Logger
+}
+
+// NewLogSink создает уведомления в лог
+func NewLogSink(logger logging.Logger) *LogSink {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+	return &LogSink{logger: logger}
+}
+
+// Notify пишет уведомление в лог
+func (s *LogSink) Notify(ctx context.Context, notification Notification) error {
+	if notification.Status == StatusDown {
+		s.logger.Error("Component %s of %s is down: %s", notification.Component, notification.ServiceName, notification.Error)
+		return nil
+	}
+
+	s.logger.Info("Component %s of %s recovered after %s", notification.Component, notification.ServiceName, notification.Downtime.Round(time.Second))
+	return nil
+}
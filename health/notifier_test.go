@@ -0,0 +1,166 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// switchComponent компонент с переключаемым состоянием
+type switchComponent struct {
+	name string
+	mu   sync.Mutex
+	err  error
+}
+
+func (c *switchComponent) Name() string     { return c.name }
+func (c *switchComponent) IsCritical() bool { return true }
+
+func (c *switchComponent) Check(ctx context.Context) (Status, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return StatusDown, c.err
+	}
+	return StatusUp, nil
+}
+
+func (c *switchComponent) set(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+// recordingSink запоминает уведомления
+type recordingSink struct {
+	notifications chan Notification
+}
+
+func (s *recordingSink) Notify(ctx context.Context, notification Notification) error {
+	s.notifications <- notification
+	return nil
+}
+
+func (s *recordingSink) expect(t *testing.T, status Status) Notification {
+	t.Helper()
+	select {
+	case notification := <-s.notifications:
+		if notification.Status != status {
+			t.Fatalf("notification status = %s, want %s", notification.Status, status)
+		}
+		return notification
+	case <-time.After(time.Second):
+		t.Fatalf("no %s notification", status)
+	}
+	return Notification{}
+}
+
+func (s *recordingSink) expectNone(t *testing.T) {
+	t.Helper()
+	select {
+	case notification := <-s.notifications:
+		t.Fatalf("unexpected notification %+v", notification)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func newTestNotifier(options *NotifierOptions, components ...Component) (*Checker, *recordingSink, *Notifier) {
+	checker := NewChecker("orders", "orders", "1.0.0")
+	for _, component := range components {
+		checker.RegisterComponent(component)
+	}
+
+	sink := &recordingSink{notifications: make(chan Notification, 10)}
+	return checker, sink, NewNotifier(checker, options, sink)
+}
+
+func TestNotifierDebouncesTransitions(t *testing.T) {
+	db := &switchComponent{name: "db"}
+	checker, sink, notifier := newTestNotifier(&NotifierOptions{ConfirmChecks: 2}, db)
+	defer notifier.Stop()
+	ctx := context.Background()
+
+	checker.CheckForce(ctx)
+	checker.CheckForce(ctx)
+	sink.expectNone(t)
+
+	// Единичный сбой не сообщается
+	db.set(errors.New("connection refused"))
+	checker.CheckForce(ctx)
+	db.set(nil)
+	checker.CheckForce(ctx)
+	sink.expectNone(t)
+
+	db.set(errors.New("connection refused"))
+	checker.CheckForce(ctx)
+	checker.CheckForce(ctx)
+	down := sink.expect(t, StatusDown)
+	if down.Component != "db" || down.ServiceName != "orders" || down.Error != "connection refused" || down.DownSince.IsZero() {
+		t.Errorf("down notification = %+v", down)
+	}
+
+	checker.CheckForce(ctx)
+	sink.expectNone(t)
+
+	db.set(nil)
+	checker.CheckForce(ctx)
+	checker.CheckForce(ctx)
+	up := sink.expect(t, StatusUp)
+	if up.Downtime <= 0 || !up.DownSince.Equal(down.DownSince) {
+		t.Errorf("up notification = %+v", up)
+	}
+}
+
+func TestNotifierCooldown(t *testing.T) {
+	db := &switchComponent{name: "db"}
+	checker, sink, notifier := newTestNotifier(&NotifierOptions{ConfirmChecks: 1, Cooldown: 50 * time.Millisecond}, db)
+	defer notifier.Stop()
+	ctx := context.Background()
+
+	checker.CheckForce(ctx)
+	db.set(errors.New("timeout"))
+	checker.CheckForce(ctx)
+	sink.expect(t, StatusDown)
+
+	// Восстановление внутри интервала откладывается
+	db.set(nil)
+	checker.CheckForce(ctx)
+	sink.expectNone(t)
+
+	time.Sleep(60 * time.Millisecond)
+	checker.CheckForce(ctx)
+	sink.expect(t, StatusUp)
+}
+
+func TestNotifierComponentsFilter(t *testing.T) {
+	db := &switchComponent{name: "db", err: errors.New("down")}
+	cache := &switchComponent{name: "cache", err: errors.New("down")}
+	checker, sink, notifier := newTestNotifier(&NotifierOptions{ConfirmChecks: 1, Components: []string{"db"}}, db, cache)
+	defer notifier.Stop()
+
+	checker.CheckForce(context.Background())
+	if notification := sink.expect(t, StatusDown); notification.Component != "db" {
+		t.Errorf("notification component = %s, want db", notification.Component)
+	}
+	sink.expectNone(t)
+}
+
+func TestFormatTelegramNotification(t *testing.T) {
+	text := formatTelegramNotification(Notification{
+		ServiceName: "orders",
+		Component:   "db",
+		Status:      StatusDown,
+		Error:       "dial <tcp>",
+	})
+	if !strings.Contains(text, "<b>db</b>") || !strings.Contains(text, "dial &lt;tcp&gt;") {
+		t.Errorf("down text = %q", text)
+	}
+
+	text = formatTelegramNotification(Notification{ServiceName: "orders", Component: "db", Status: StatusUp, Downtime: 90 * time.Second})
+	if !strings.Contains(text, "восстановлен") || !strings.Contains(text, "1m30s") {
+		t.Errorf("up text = %q", text)
+	}
+}
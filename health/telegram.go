@@ -0,0 +1,44 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/vladzorgan/common/telegram"
+)
+
+// TelegramSink отправляет уведомления о состоянии компонентов в Telegram
+type TelegramSink struct {
+	client *telegram.TelegramClient
+	chatID string
+}
+
+// NewTelegramSink создает уведомления в Telegram; пустой chatID - чат клиента
+func NewTelegramSink(client *telegram.TelegramClient, chatID string) *TelegramSink {
+	return &TelegramSink{client: client, chatID: chatID}
+}
+
+// Notify отправляет уведомление в Telegram
+func (s *TelegramSink) Notify(ctx context.Context, notification Notification) error {
+	return s.client.SendMessageToCtx(ctx, s.chatID, formatTelegramNotification(notification))
+}
+
+// formatTelegramNotification формирует HTML текст уведомления
+func formatTelegramNotification(notification Notification) string {
+	service := html.EscapeString(notification.ServiceName)
+	component := html.EscapeString(notification.Component)
+
+	if notification.Status == StatusDown {
+		text := fmt.Sprintf("🔴 <b>%s</b>: компонент <b>%s</b> недоступен с %s",
+			service, component, notification.DownSince.Format("15:04:05 02.01.2006"))
+		if notification.Error != "" {
+			text += "\n\n<code>" + html.EscapeString(notification.Error) + "</code>"
+		}
+		return text
+	}
+
+	return fmt.Sprintf("🟢 <b>%s</b>: компонент <b>%s</b> восстановлен, простой %s",
+		service, component, notification.Downtime.Round(time.Second))
+}
@@ -0,0 +1,268 @@
+// Package crud регистрирует стандартные CRUD маршруты Gin для сервисов на основе service.Service
+package crud
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/config"
+	commonhttp "github.com/vladzorgan/common/http"
+	"github.com/vladzorgan/common/http/response"
+	"github.com/vladzorgan/common/repository"
+	"github.com/vladzorgan/common/service"
+)
+
+// Route определяет CRUD маршрут
+type Route string
+
+const (
+	// RouteList GET "" - список с пагинацией, фильтрами и сортировкой
+	RouteList Route = "list"
+	// RouteGet GET /:id - получение по ID
+	RouteGet Route = "get"
+	// RouteCreate POST "" - создание
+	RouteCreate Route = "create"
+	// RouteUpdate PATCH /:id - обновление
+	RouteUpdate Route = "update"
+	// RouteDelete DELETE /:id - удаление
+	RouteDelete Route = "delete"
+	// RouteSearch GET /search - поиск по ключевому слову (параметр q)
+	RouteSearch Route = "search"
+)
+
+// Options содержит настройки регистрации маршрутов
+type Options[T service.BaseEntity] struct {
+	// Фабрика входных данных для POST; должна возвращать указатель, в который разбирается тело запроса.
+	// Без фабрики маршрут создания не регистрируется.
+	NewCreateInput func() service.CreateInput[T]
+	// Фабрика входных данных для PATCH; без фабрики и PatchSchema маршрут обновления не регистрируется
+	NewUpdateInput func() service.UpdateInput[T]
+	// Схема JSON Merge Patch; если задана, PATCH обрабатывается http.PatchHandler вместо NewUpdateInput
+	PatchSchema service.PatchSchema
+
+	// Параметры запроса, которые передаются в фильтры списка и поиска (остальные игнорируются)
+	Filters []string
+	// Лимит страницы по умолчанию, обычно config.BaseConfig.DefaultPaginationLimit
+	// (0 - значение DEFAULT_PAGINATION_LIMIT, см. config.PaginationLimit)
+	DefaultLimit int
+	// Максимальный лимит страницы (0 - без ограничения)
+	MaxLimit int
+
+	// Отключенные маршруты
+	Disabled []Route
+	// Дополнительные middleware маршрутов, например, auth.RequireAdminGin() для RouteDelete
+	Middleware map[Route][]gin.HandlerFunc
+}
+
+// Register регистрирует CRUD маршруты сервиса в группе router:
+//
+//	GET    ""        - список (skip, limit, sort, order и фильтры из Options.Filters)
+//	GET    /search   - поиск (q и те же параметры)
+//	GET    /:id      - получение по ID
+//	POST   ""        - создание
+//	PATCH  /:id      - обновление
+//	DELETE /:id      - удаление
+func Register[T service.BaseEntity, R any](router *gin.RouterGroup, svc service.Service[T, R], opts Options[T]) {
+	h := &handlers[T, R]{svc: svc, opts: opts}
+
+	disabled := make(map[Route]bool, len(opts.Disabled))
+	for _, route := range opts.Disabled {
+		disabled[route] = true
+	}
+
+	register := func(route Route, method, path string, handler gin.HandlerFunc) {
+		if disabled[route] {
+			return
+		}
+		chain := append(append([]gin.HandlerFunc(nil), opts.Middleware[route]...), handler)
+		router.Handle(method, path, chain...)
+	}
+
+	register(RouteList, http.MethodGet, "", h.list)
+	register(RouteSearch, http.MethodGet, "/search", h.search)
+	register(RouteGet, http.MethodGet, "/:id", h.get)
+	if opts.NewCreateInput != nil {
+		register(RouteCreate, http.MethodPost, "", h.create)
+	}
+	switch {
+	case opts.PatchSchema != nil:
+		register(RouteUpdate, http.MethodPatch, "/:id", commonhttp.PatchHandler(svc, opts.PatchSchema))
+	case opts.NewUpdateInput != nil:
+		register(RouteUpdate, http.MethodPatch, "/:id", h.update)
+	}
+	register(RouteDelete, http.MethodDelete, "/:id", h.delete)
+}
+
+// handlers содержит обработчики CRUD маршрутов
+type handlers[T service.BaseEntity, R any] struct {
+	svc  service.Service[T, R]
+	opts Options[T]
+}
+
+func (h *handlers[T, R]) list(c *gin.Context) {
+	query, ok := h.parseListQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.svc.GetAll(c.Request.Context(), query.skip, query.limit, query.filters, query.sort)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *handlers[T, R]) search(c *gin.Context) {
+	query, ok := h.parseListQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.svc.Search(c.Request.Context(), c.Query("q"), query.skip, query.limit, query.filters, query.sort)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *handlers[T, R]) get(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.svc.GetByID(c.Request.Context(), id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *handlers[T, R]) create(c *gin.Context) {
+	input := h.opts.NewCreateInput()
+	if err := c.ShouldBindJSON(input); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	result, err := h.svc.Create(c.Request.Context(), input)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *handlers[T, R]) update(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	input := h.opts.NewUpdateInput()
+	if err := c.ShouldBindJSON(input); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	result, err := h.svc.Update(c.Request.Context(), id, input)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *handlers[T, R]) delete(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.svc.Delete(c.Request.Context(), id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// listQuery содержит разобранные параметры списка
+type listQuery struct {
+	skip    int
+	limit   int
+	filters map[string]interface{}
+	sort    *repository.SortOptions
+}
+
+// parseListQuery разбирает пагинацию, сортировку и фильтры; при ошибке прерывает запрос с кодом 400
+func (h *handlers[T, R]) parseListQuery(c *gin.Context) (listQuery, bool) {
+	defaultLimit := h.opts.DefaultLimit
+	if defaultLimit <= 0 {
+		defaultLimit = config.PaginationLimit()
+	}
+
+	skip, err := strconv.Atoi(c.DefaultQuery("skip", "0"))
+	if err != nil || skip < 0 {
+		badRequest(c, "invalid skip")
+		return listQuery{}, false
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 {
+		badRequest(c, "invalid limit")
+		return listQuery{}, false
+	}
+	if h.opts.MaxLimit > 0 && limit > h.opts.MaxLimit {
+		limit = h.opts.MaxLimit
+	}
+
+	query := listQuery{skip: skip, limit: limit}
+
+	// Поле сортировки проверяется репозиторием по списку разрешенных полей
+	if field := c.Query("sort"); field != "" {
+		order := c.DefaultQuery("order", "asc")
+		if order != "asc" && order != "desc" {
+			badRequest(c, "invalid order")
+			return listQuery{}, false
+		}
+		query.sort = &repository.SortOptions{Field: field, Order: order}
+	}
+
+	// Имена фильтров подставляются в SQL репозиторием, поэтому принимаются только разрешенные
+	for _, name := range h.opts.Filters {
+		if value, ok := c.GetQuery(name); ok && value != "" {
+			if query.filters == nil {
+				query.filters = make(map[string]interface{})
+			}
+			query.filters[name] = value
+		}
+	}
+
+	return query, true
+}
+
+// parseID разбирает идентификатор из пути; при ошибке прерывает запрос с кодом 400
+func parseID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequest(c, "invalid id")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// badRequest прерывает запрос с кодом 400 в конверте ошибки response
+func badRequest(c *gin.Context, message string) {
+	response.ErrorWithStatus(c, http.StatusBadRequest, message)
+}
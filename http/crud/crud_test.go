@@ -0,0 +1,191 @@
+package crud
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/config"
+	apperrors "github.com/vladzorgan/common/errors"
+	"github.com/vladzorgan/common/http/response"
+	"github.com/vladzorgan/common/repository"
+	"github.com/vladzorgan/common/service"
+)
+
+type city struct {
+	ID   uint
+	Name string
+}
+
+func (c city) GetID() uint          { return c.ID }
+func (c city) GetTableName() string { return "cities" }
+func (c city) GetName() string      { return c.Name }
+
+type cityInput struct {
+	Name string `json:"name"`
+}
+
+func (i *cityInput) ToEntity() *city { return &city{Name: i.Name} }
+func (i *cityInput) Validate() error { return nil }
+func (i *cityInput) ToUpdateMap() map[string]interface{} {
+	return map[string]interface{}{"name": i.Name}
+}
+
+// fakeService запоминает параметры вызовов
+type fakeService struct {
+	service.Service[city, city]
+
+	skip, limit int
+	filters     map[string]interface{}
+	sort        *repository.SortOptions
+	keyword     string
+	created     string
+}
+
+func (s *fakeService) GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) (*service.PaginationResponse[city], error) {
+	s.skip, s.limit, s.filters, s.sort = skip, limit, filters, sort
+	return &service.PaginationResponse[city]{Items: []city{}}, nil
+}
+
+func (s *fakeService) Search(ctx context.Context, keyword string, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) (*service.PaginationResponse[city], error) {
+	s.keyword = keyword
+	return s.GetAll(ctx, skip, limit, filters, sort)
+}
+
+func (s *fakeService) GetByID(ctx context.Context, id uint, opts ...repository.QueryOption) (*city, error) {
+	if id != 1 {
+		return nil, apperrors.NotFound("city", id)
+	}
+	return &city{ID: 1, Name: "Москва"}, nil
+}
+
+func (s *fakeService) Create(ctx context.Context, input service.CreateInput[city]) (*city, error) {
+	entity := input.ToEntity()
+	s.created = entity.Name
+	return entity, nil
+}
+
+func (s *fakeService) Delete(ctx context.Context, id uint) (*city, error) {
+	return &city{ID: id}, nil
+}
+
+func newRouter(svc *fakeService, opts Options[city]) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	Register[city, city](router.Group("/cities"), svc, opts)
+	return router
+}
+
+func do(router *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRegisterListParsesQuery(t *testing.T) {
+	svc := &fakeService{}
+	router := newRouter(svc, Options[city]{Filters: []string{"region_id"}, DefaultLimit: 20, MaxLimit: 50})
+
+	if rec := do(router, http.MethodGet, "/cities?skip=10&sort=name&order=desc&region_id=3&owner_id=1", ""); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if svc.skip != 10 || svc.limit != 20 {
+		t.Errorf("skip, limit = %d, %d", svc.skip, svc.limit)
+	}
+	if len(svc.filters) != 1 || svc.filters["region_id"] != "3" {
+		t.Errorf("filters = %v", svc.filters)
+	}
+	if svc.sort == nil || svc.sort.Field != "name" || svc.sort.Order != "desc" {
+		t.Errorf("sort = %+v", svc.sort)
+	}
+
+	do(router, http.MethodGet, "/cities/search?q=мос&limit=500", "")
+	if svc.keyword != "мос" || svc.limit != 50 {
+		t.Errorf("keyword, limit = %q, %d", svc.keyword, svc.limit)
+	}
+
+	if rec := do(router, http.MethodGet, "/cities?limit=abc", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid limit status = %d", rec.Code)
+	}
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	svc := &fakeService{}
+	denied := func(c *gin.Context) { c.AbortWithStatus(http.StatusForbidden) }
+	router := newRouter(svc, Options[city]{
+		NewCreateInput: func() service.CreateInput[city] { return &cityInput{} },
+		Disabled:       []Route{RouteSearch},
+		Middleware:     map[Route][]gin.HandlerFunc{RouteDelete: {denied}},
+	})
+
+	tests := []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodGet, "/cities/1", "", http.StatusOK},
+		{http.MethodGet, "/cities/2", "", http.StatusNotFound},
+		{http.MethodGet, "/cities/abc", "", http.StatusBadRequest},
+		{http.MethodPost, "/cities", `{"name": "Казань"}`, http.StatusCreated},
+		{http.MethodPost, "/cities", `{"name":`, http.StatusBadRequest},
+		{http.MethodDelete, "/cities/1", "", http.StatusForbidden},
+		// Маршрут обновления не зарегистрирован без фабрики и схемы
+		{http.MethodPatch, "/cities/1", `{"name": "Казань"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		if rec := do(router, tt.method, tt.target, tt.body); rec.Code != tt.status {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.target, rec.Code, tt.status)
+		}
+	}
+	if svc.created != "Казань" {
+		t.Errorf("created = %q", svc.created)
+	}
+}
+
+func TestRegisterErrorsUseEnvelope(t *testing.T) {
+	router := newRouter(&fakeService{}, Options[city]{})
+
+	tests := []struct {
+		target string
+		status int
+		code   string
+	}{
+		{"/cities/2", http.StatusNotFound, response.CodeNotFound},
+		{"/cities/abc", http.StatusBadRequest, response.CodeBadRequest},
+		{"/cities?skip=-1", http.StatusBadRequest, response.CodeBadRequest},
+	}
+
+	for _, tt := range tests {
+		rec := do(router, http.MethodGet, tt.target, "")
+		var envelope response.ErrorEnvelope
+		if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+			t.Fatalf("%s: invalid body %s: %v", tt.target, rec.Body.String(), err)
+		}
+		if rec.Code != tt.status || envelope.Error.Code != tt.code || envelope.Error.Message == "" {
+			t.Errorf("%s: status = %d, error = %+v; want %d %s", tt.target, rec.Code, envelope.Error, tt.status, tt.code)
+		}
+	}
+}
+
+func TestRegisterDefaultLimitFromConfig(t *testing.T) {
+	svc := &fakeService{}
+	router := newRouter(svc, Options[city]{})
+
+	t.Setenv("DEFAULT_PAGINATION_LIMIT", "25")
+	do(router, http.MethodGet, "/cities", "")
+	if svc.limit != 25 {
+		t.Errorf("limit = %d, want DEFAULT_PAGINATION_LIMIT 25", svc.limit)
+	}
+
+	t.Setenv("DEFAULT_PAGINATION_LIMIT", "")
+	do(router, http.MethodGet, "/cities", "")
+	if svc.limit != config.DefaultPaginationLimit {
+		t.Errorf("limit = %d, want %d", svc.limit, config.DefaultPaginationLimit)
+	}
+}
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/vladzorgan/common/errors"
+)
+
+// RespondError прерывает запрос с HTTP кодом, соответствующим ошибке сервиса.
+// Причины блокировки операции (см. apperrors.Blocked) передаются в поле blockers.
+func RespondError(c *gin.Context, err error) {
+	status, body := errorBody(err)
+	c.AbortWithStatusJSON(status, body)
+}
+
+// errorBody формирует HTTP код и конверт ошибки сервиса
+func errorBody(err error) (int, gin.H) {
+	status := apperrors.ToHTTPStatus(err)
+
+	body := gin.H{
+		"error":   http.StatusText(status),
+		"message": err.Error(),
+	}
+
+	var typedErr *apperrors.Error
+	if errors.As(err, &typedErr) {
+		if len(typedErr.Blockers) > 0 {
+			body["blockers"] = typedErr.Blockers
+		}
+		if len(typedErr.Fields) > 0 {
+			body["fields"] = typedErr.Fields
+		}
+	}
+
+	return status, body
+}
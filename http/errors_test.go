@@ -0,0 +1,49 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/vladzorgan/common/errors"
+)
+
+func TestRespondErrorRendersBlockers(t *testing.T) {
+	router := gin.New()
+	router.DELETE("/regions/bulk", func(c *gin.Context) {
+		RespondError(c, apperrors.Blocked("region", map[uint][]string{1: {"has 12 cities"}, 2: {"has 1 cities"}}))
+	})
+	router.GET("/regions/:id", func(c *gin.Context) {
+		RespondError(c, apperrors.NotFound("region", 1))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/regions/bulk", nil))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", w.Code)
+	}
+
+	var body struct {
+		Message  string              `json:"message"`
+		Blockers map[string][]string `json:"blockers"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if len(body.Blockers) != 2 || body.Blockers["1"][0] != "has 12 cities" || body.Blockers["2"][0] != "has 1 cities" {
+		t.Errorf("blockers = %v", body.Blockers)
+	}
+
+	// Ошибки без причин блокировки отдаются без поля blockers
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/regions/1", nil))
+	var notFound map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &notFound); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if _, ok := notFound["blockers"]; ok || w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, body = %v; want 404 without blockers", w.Code, notFound)
+	}
+}
@@ -0,0 +1,31 @@
+// Package httpctx предоставляет доступ к данным запроса, которые middleware сохраняют в gin.Context
+// и в стандартном контексте запроса
+package httpctx
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/logging"
+)
+
+// RequestIDKey ключ gin.Context, под которым хранится идентификатор запроса
+const RequestIDKey = "RequestID"
+
+// RequestID возвращает идентификатор запроса. Сначала проверяется стандартный контекст
+// (c.Request.Context()), затем gin.Context для middleware, которые сохраняют ID только в нем.
+func RequestID(c *gin.Context) string {
+	if c.Request != nil {
+		if requestID := logging.ExtractRequestID(c.Request.Context()); requestID != "" {
+			return requestID
+		}
+	}
+	return c.GetString(RequestIDKey)
+}
+
+// SetRequestID сохраняет идентификатор запроса в gin.Context и в контексте запроса,
+// чтобы он был доступен репозиториям, gRPC клиентам и logging.Logger.WithContext
+func SetRequestID(c *gin.Context, requestID string) {
+	c.Set(RequestIDKey, requestID)
+	if c.Request != nil {
+		c.Request = c.Request.WithContext(logging.ContextWithRequestID(c.Request.Context(), requestID))
+	}
+}
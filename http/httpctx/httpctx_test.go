@@ -0,0 +1,51 @@
+package httpctx_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/http/httpctx"
+	"github.com/vladzorgan/common/http/middleware"
+	"github.com/vladzorgan/common/logging"
+)
+
+func TestRequestIDPropagatesToRequestContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var fromContext, fromHelper string
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.GET("/", func(c *gin.Context) {
+		fromContext = logging.ExtractRequestID(c.Request.Context())
+		fromHelper = httpctx.RequestID(c)
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if fromContext != "req-1" || fromHelper != "req-1" {
+		t.Errorf("request ID: context = %q, helper = %q, want req-1", fromContext, fromHelper)
+	}
+	if got := w.Header().Get(middleware.RequestIDHeader); got != "req-1" {
+		t.Errorf("response header = %q, want req-1", got)
+	}
+}
+
+func TestRequestIDFallsBackToGinContext(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	if got := httpctx.RequestID(c); got != "" {
+		t.Errorf("RequestID() without ID = %q", got)
+	}
+
+	c.Set(httpctx.RequestIDKey, "req-2")
+	if got := httpctx.RequestID(c); got != "req-2" {
+		t.Errorf("RequestID() = %q, want req-2", got)
+	}
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/service"
+)
+
+// MergePatchContentType - тип содержимого JSON Merge Patch (RFC 7396)
+const MergePatchContentType = "application/merge-patch+json"
+
+// BindMergePatch разбирает тело PATCH запроса в документ merge patch.
+// Принимает application/merge-patch+json и application/json; при ошибке прерывает запрос
+// с кодом 415 или 400 и возвращает false.
+func BindMergePatch(c *gin.Context) (*service.MergePatch, bool) {
+	if contentType := c.GetHeader("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != MergePatchContentType && mediaType != gin.MIMEJSON) {
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{
+				"error":   "Unsupported Media Type",
+				"message": "expected " + MergePatchContentType,
+			})
+			return nil, false
+		}
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": "failed to read request body",
+		})
+		return nil, false
+	}
+
+	patch, err := service.DecodeMergePatch(body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": err.Error(),
+		})
+		return nil, false
+	}
+
+	return patch, true
+}
+
+// PatchHandler возвращает обработчик PATCH /:id с семантикой JSON Merge Patch:
+// изменяются только переданные поля, null очищает значение. Поля вне schema отклоняются с кодом 400.
+// PUT маршруты продолжают использовать полное обновление через UpdateInput.
+func PatchHandler[T service.BaseEntity, R any](svc service.Service[T, R], schema service.PatchSchema) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":   "Bad Request",
+				"message": "invalid id",
+			})
+			return
+		}
+
+		patch, ok := BindMergePatch(c)
+		if !ok {
+			return
+		}
+
+		response, err := svc.Update(c.Request.Context(), uint(id), service.NewPatchInput[T](patch, schema))
+		if err != nil {
+			RespondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBindMergePatch(t *testing.T) {
+	router := gin.New()
+	router.PATCH("/customers/:id", func(c *gin.Context) {
+		patch, ok := BindMergePatch(c)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"keys": patch.Keys(), "phone_null": patch.IsNull("phone")})
+	})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"merge patch", MergePatchContentType, `{"name": "Ivan", "phone": null}`, http.StatusOK},
+		{"plain json", "application/json; charset=utf-8", `{"name": "Ivan"}`, http.StatusOK},
+		{"unsupported media type", "text/plain", `{"name": "Ivan"}`, http.StatusUnsupportedMediaType},
+		{"not an object", MergePatchContentType, `["name"]`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/customers/1", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
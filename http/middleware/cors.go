@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrCORSWildcardCredentials возвращается для CORS с AllowOrigins "*" и AllowCredentials:
+// браузеры не принимают такой ответ, а разрешение учетных данных для любого источника небезопасно
+var ErrCORSWildcardCredentials = errors.New(`CORS origin "*" cannot be combined with AllowCredentials`)
+
+// CORSOptions содержит настройки CORS
+type CORSOptions struct {
+	// Разрешенные источники; "*" разрешает любой источник (только без AllowCredentials)
+	AllowOrigins []string
+	// Проверка источников, не найденных в AllowOrigins (например, источники арендаторов из базы данных).
+	// Вызывается для каждого CORS запроса, поэтому результат стоит кешировать.
+	AllowOriginFunc func(origin string) bool
+
+	AllowMethods     []string
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	// Время кеширования ответа на preflight запрос
+	MaxAge time.Duration
+}
+
+// DefaultCORSOptions возвращает настройки CORS по умолчанию для источников origins.
+// Учетные данные разрешаются, только если среди источников нет "*".
+func DefaultCORSOptions(origins []string) *CORSOptions {
+	return &CORSOptions{
+		AllowOrigins:     origins,
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "X-Internal-API-Key"},
+		ExposeHeaders:    []string{"Content-Length"},
+		AllowCredentials: !containsWildcardOrigin(origins),
+		MaxAge:           12 * time.Hour,
+	}
+}
+
+// Validate проверяет согласованность настроек CORS
+func (o *CORSOptions) Validate() error {
+	if o.AllowCredentials && containsWildcardOrigin(o.AllowOrigins) {
+		return ErrCORSWildcardCredentials
+	}
+	return o.config().Validate()
+}
+
+// config преобразует настройки в конфигурацию gin-contrib/cors
+func (o *CORSOptions) config() cors.Config {
+	config := cors.Config{
+		AllowOriginFunc:  o.AllowOriginFunc,
+		AllowMethods:     o.AllowMethods,
+		AllowHeaders:     o.AllowHeaders,
+		ExposeHeaders:    o.ExposeHeaders,
+		AllowCredentials: o.AllowCredentials,
+		MaxAge:           o.MaxAge,
+	}
+
+	// "*" разрешает любой источник, остальные источники и AllowOriginFunc при этом не нужны
+	if containsWildcardOrigin(o.AllowOrigins) {
+		config.AllowAllOrigins = true
+		config.AllowOriginFunc = nil
+		return config
+	}
+
+	for _, origin := range o.AllowOrigins {
+		if origin != "" {
+			config.AllowOrigins = append(config.AllowOrigins, origin)
+		}
+	}
+	return config
+}
+
+// containsWildcardOrigin проверяет, разрешены ли все источники
+func containsWildcardOrigin(origins []string) bool {
+	for _, origin := range origins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS обрабатывает CORS запросы с настройками, которые можно заменить во время работы
+// (например, при изменении CorsOrigins в конфигурации)
+type CORS struct {
+	handler atomic.Pointer[gin.HandlerFunc]
+}
+
+// NewCORS создает CORS middleware; возвращает ошибку для несогласованных настроек
+func NewCORS(options *CORSOptions) (*CORS, error) {
+	c := &CORS{}
+	if err := c.Update(options); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Update заменяет настройки CORS; действует на следующие запросы.
+// При ошибке продолжают действовать прежние настройки.
+func (c *CORS) Update(options *CORSOptions) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	handler := cors.New(options.config())
+	c.handler.Store(&handler)
+	return nil
+}
+
+// Handler возвращает middleware с текущими настройками CORS
+func (c *CORS) Handler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		(*c.handler.Load())(ctx)
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newCORSRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handler)
+	router.GET("/api/cities", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func corsRequest(router *gin.Engine, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/cities", nil)
+	req.Header.Set("Origin", origin)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSRejectsWildcardWithCredentials(t *testing.T) {
+	options := DefaultCORSOptions([]string{"*"})
+	if options.AllowCredentials {
+		t.Fatal("default options must not allow credentials for wildcard origin")
+	}
+
+	options.AllowCredentials = true
+	if _, err := NewCORS(options); !errors.Is(err, ErrCORSWildcardCredentials) {
+		t.Errorf("NewCORS() error = %v, want ErrCORSWildcardCredentials", err)
+	}
+}
+
+func TestCORSAllowOriginFunc(t *testing.T) {
+	options := DefaultCORSOptions([]string{"https://admin.example.com"})
+	options.AllowOriginFunc = func(origin string) bool { return origin == "https://tenant.example.com" }
+
+	handler, err := NewCORS(options)
+	if err != nil {
+		t.Fatalf("NewCORS() error = %v", err)
+	}
+	router := newCORSRouter(handler.Handler())
+
+	for origin, want := range map[string]int{
+		"https://admin.example.com":  http.StatusOK,
+		"https://tenant.example.com": http.StatusOK,
+		"https://evil.example.com":   http.StatusForbidden,
+	} {
+		w := corsRequest(router, origin)
+		if w.Code != want {
+			t.Errorf("origin %s: status = %d, want %d", origin, w.Code, want)
+		}
+		if want == http.StatusOK && w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+			t.Errorf("origin %s: credentials not allowed", origin)
+		}
+	}
+}
+
+func TestCORSUpdate(t *testing.T) {
+	handler, err := NewCORS(DefaultCORSOptions([]string{"https://old.example.com"}))
+	if err != nil {
+		t.Fatalf("NewCORS() error = %v", err)
+	}
+	router := newCORSRouter(handler.Handler())
+
+	if err := handler.Update(DefaultCORSOptions([]string{"https://new.example.com"})); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if w := corsRequest(router, "https://new.example.com"); w.Code != http.StatusOK {
+		t.Errorf("new origin status = %d, want 200", w.Code)
+	}
+	if w := corsRequest(router, "https://old.example.com"); w.Code != http.StatusForbidden {
+		t.Errorf("old origin status = %d, want 403", w.Code)
+	}
+
+	// Некорректные настройки не заменяют действующие
+	invalid := DefaultCORSOptions([]string{"*"})
+	invalid.AllowCredentials = true
+	if err := handler.Update(invalid); err == nil {
+		t.Fatal("Update() must reject invalid options")
+	}
+	if w := corsRequest(router, "https://new.example.com"); w.Code != http.StatusOK {
+		t.Errorf("origin after failed update status = %d, want 200", w.Code)
+	}
+}
@@ -0,0 +1,253 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimit ограничивает размер тела запроса maxBytes байтами.
+// Запросы с заведомо большим Content-Length отклоняются сразу, для остальных тело
+// оборачивается в http.MaxBytesReader. Если обработчик превысил лимит при чтении и
+// еще не записал ответ, возвращается 413.
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			abortBodyTooLarge(c, maxBytes)
+			return
+		}
+
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		body := &limitedBody{ReadCloser: http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)}
+		c.Request.Body = body
+
+		c.Next()
+
+		if body.exceeded && !c.Writer.Written() {
+			abortBodyTooLarge(c, maxBytes)
+		}
+	}
+}
+
+// limitedBody запоминает, что чтение тела прервано из-за превышения лимита
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+// Read читает тело запроса
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// abortBodyTooLarge прерывает запрос с кодом 413
+func abortBodyTooLarge(c *gin.Context, maxBytes int64) {
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error":   "Request Entity Too Large",
+		"message": fmt.Sprintf("Request body exceeds %d bytes", maxBytes),
+	})
+}
+
+// Timeout ограничивает время обработки запроса. Контекст запроса отменяется по истечении d,
+// и клиенту сразу возвращается 504; ответ обработчика после этого отбрасывается.
+// Ответ буферизуется до завершения обработчика, поэтому middleware не подходит для потоковых ответов.
+// Middleware дожидается завершения обработчика перед возвратом, поэтому обработчики
+// должны прекращать работу при отмене контекста.
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		original := c.Writer
+		writer := newTimeoutWriter(original)
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = writer
+
+		deadline, _ := ctx.Deadline()
+		done := make(chan struct{})
+		panicChan := make(chan interface{}, 1)
+		var finishedAt time.Time
+
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicChan <- p
+				}
+				finishedAt = time.Now()
+				close(done)
+			}()
+			c.Next()
+		}()
+
+		timedOut := false
+		select {
+		case <-done:
+			timedOut = finishedAt.After(deadline)
+		case <-ctx.Done():
+			// Отмена родительского контекста (клиент отключился) не считается таймаутом
+			timedOut = errors.Is(ctx.Err(), context.DeadlineExceeded)
+		}
+
+		if timedOut && writer.timeout() {
+			original.Header().Set("Content-Type", "application/json; charset=utf-8")
+			original.WriteHeader(http.StatusGatewayTimeout)
+			_, _ = original.Write([]byte(`{"error":"Gateway Timeout","message":"Request processing timed out"}`))
+			original.Flush()
+		}
+
+		// Дожидаемся обработчика, чтобы gin не переиспользовал контекст, пока он выполняется
+		<-done
+
+		c.Writer = original
+
+		select {
+		case p := <-panicChan:
+			panic(p)
+		default:
+		}
+
+		writer.flush()
+	}
+}
+
+// timeoutWriter буферизует ответ обработчика до его завершения
+type timeoutWriter struct {
+	gin.ResponseWriter
+
+	mutex    sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	written  bool
+	timedOut bool
+}
+
+// newTimeoutWriter создает буферизующий writer
+func newTimeoutWriter(w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{
+		ResponseWriter: w,
+		header:         w.Header().Clone(),
+		status:         http.StatusOK,
+	}
+}
+
+// Header возвращает заголовки буферизованного ответа
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader запоминает код ответа
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.timedOut || w.written {
+		return
+	}
+	w.status = code
+}
+
+// WriteHeaderNow фиксирует код ответа
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.written = true
+}
+
+// Write записывает тело в буфер; после таймаута возвращает http.ErrHandlerTimeout
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.written = true
+	return w.body.Write(data)
+}
+
+// WriteString записывает строку в буфер
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Status возвращает код буферизованного ответа
+func (w *timeoutWriter) Status() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.status
+}
+
+// Size возвращает размер буферизованного тела
+func (w *timeoutWriter) Size() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+// Written проверяет, был ли начат ответ
+func (w *timeoutWriter) Written() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.written
+}
+
+// Flush ничего не делает: ответ отправляется после завершения обработчика
+func (w *timeoutWriter) Flush() {}
+
+// timeout помечает ответ как прерванный; возвращает false, если обработчик уже завершился
+func (w *timeoutWriter) timeout() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.timedOut {
+		return false
+	}
+	w.timedOut = true
+	return true
+}
+
+// flush отправляет буферизованный ответ, если таймаут не наступил
+func (w *timeoutWriter) flush() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.timedOut {
+		return
+	}
+
+	destination := w.ResponseWriter.Header()
+	for key, values := range w.header {
+		destination[key] = values
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	} else if w.written {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
@@ -2,27 +2,51 @@
 package middleware
 
 import (
+	"math/rand/v2"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/vladzorgan/common/http/httpctx"
 	"github.com/vladzorgan/common/logging"
 )
 
 // RequestIDHeader определяет заголовок для идентификатора запроса
 const RequestIDHeader = "X-Request-ID"
 
+// LoggerOptions содержит настройки логирования запросов
+type LoggerOptions struct {
+	// Префиксы путей, запросы к которым не логируются (например, "/metrics", "/api/health")
+	SkipPaths []string
+	// Писать строку "Request started" перед обработкой запроса; по умолчанию пишется только строка завершения
+	LogStart bool
+	// Доля логируемых успешных запросов (статус ниже 400) от 0 до 1; 0 - логировать все.
+	// Ответы 4xx и 5xx и медленные запросы логируются всегда.
+	SuccessSampleRate float64
+	// Запросы дольше порога логируются всегда с уровнем WARN (0 - без порога)
+	SlowThreshold time.Duration
+}
+
 // Logger возвращает middleware для логирования запросов
 func Logger(logger logging.Logger) gin.HandlerFunc {
-	return LoggerWithSkipPaths(logger, []string{})
+	return LoggerWithOptions(logger, nil)
 }
 
-// LoggerWithSkipPaths возвращает middleware для логирования запросов с возможностью пропуска определенных путей
+// LoggerWithSkipPaths возвращает middleware для логирования запросов с возможностью пропуска путей по префиксу
 func LoggerWithSkipPaths(logger logging.Logger, skipPaths []string) gin.HandlerFunc {
+	return LoggerWithOptions(logger, &LoggerOptions{SkipPaths: skipPaths})
+}
+
+// LoggerWithOptions возвращает middleware для логирования запросов с указанными настройками
+func LoggerWithOptions(logger logging.Logger, options *LoggerOptions) gin.HandlerFunc {
+	if options == nil {
+		options = &LoggerOptions{}
+	}
+
 	return func(c *gin.Context) {
 		// Проверяем, нужно ли пропустить логирование для данного пути
-		if shouldSkipLogging(c.Request.URL.Path, skipPaths) {
+		if shouldSkipLogging(c.Request.URL.Path, options.SkipPaths) {
 			c.Next()
 			return
 		}
@@ -30,32 +54,39 @@ func LoggerWithSkipPaths(logger logging.Logger, skipPaths []string) gin.HandlerF
 		// Время начала запроса
 		startTime := time.Now()
 
-		// Получаем идентификатор запроса
-		requestID := c.GetString("RequestID")
-		if requestID == "" {
-			requestID = c.GetHeader(RequestIDHeader)
+		// Получаем идентификатор запроса из контекста (его сохраняет middleware RequestID)
+		if httpctx.RequestID(c) == "" {
+			requestID := c.GetHeader(RequestIDHeader)
 			if requestID == "" {
 				requestID = uuid.New().String()
 			}
-			c.Set("RequestID", requestID)
+			httpctx.SetRequestID(c, requestID)
 		}
 
 		// Создаем логгер с данными запроса
-		reqLogger := logger.WithRequestID(requestID).
+		reqLogger := logger.WithContext(c.Request.Context()).
 			WithField("method", c.Request.Method).
 			WithField("path", c.Request.URL.Path).
 			WithField("client_ip", c.ClientIP())
 
-		reqLogger.Info("Request started")
+		if options.LogStart {
+			reqLogger.Info("Request started")
+		}
 
 		// Обрабатываем запрос
 		c.Next()
 
 		// Вычисляем время выполнения запроса
 		latency := time.Since(startTime)
+		statusCode := c.Writer.Status()
+		slow := options.SlowThreshold > 0 && latency >= options.SlowThreshold
+
+		// Успешные запросы логируются выборочно
+		if statusCode < 400 && !slow && !sampled(options.SuccessSampleRate) {
+			return
+		}
 
 		// Формируем сообщение лога в зависимости от статуса
-		statusCode := c.Writer.Status()
 		fields := map[string]interface{}{
 			"status":     statusCode,
 			"latency_ms": latency.Milliseconds(),
@@ -76,13 +107,23 @@ func LoggerWithSkipPaths(logger logging.Logger, skipPaths []string) gin.HandlerF
 			reqLogger.Error("Server error")
 		} else if statusCode >= 400 {
 			reqLogger.Warn("Client error")
+		} else if slow {
+			reqLogger.Warn("Slow request")
 		} else {
 			reqLogger.Info("Request completed")
 		}
 	}
 }
 
-// shouldSkipLogging проверяет, нужно ли пропустить логирование для данного пути
+// sampled решает, логировать ли успешный запрос при доле rate
+func sampled(rate float64) bool {
+	if rate <= 0 || rate >= 1 {
+		return true
+	}
+	return rand.Float64() < rate
+}
+
+// shouldSkipLogging проверяет, начинается ли путь с одного из префиксов skipPaths
 func shouldSkipLogging(path string, skipPaths []string) bool {
 	for _, skipPath := range skipPaths {
 		if strings.HasPrefix(path, skipPath) {
@@ -102,38 +143,10 @@ func RequestID() gin.HandlerFunc {
 			requestID = uuid.New().String()
 		}
 
-		// Устанавливаем идентификатор в контекст и заголовок ответа
-		c.Set("RequestID", requestID)
+		// Устанавливаем идентификатор в gin.Context, контекст запроса и заголовок ответа
+		httpctx.SetRequestID(c, requestID)
 		c.Writer.Header().Set(RequestIDHeader, requestID)
 
 		c.Next()
 	}
 }
-
-// Recovery возвращает middleware для восстановления после паники
-func Recovery(logger logging.Logger) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		defer func() {
-			if err := recover(); err != nil {
-				// Получаем идентификатор запроса
-				requestID := c.GetString("RequestID")
-				if requestID == "" {
-					requestID = uuid.New().String()
-					c.Set("RequestID", requestID)
-				}
-
-				// Логируем ошибку
-				logger.WithRequestID(requestID).
-					WithField("method", c.Request.Method).
-					WithField("path", c.Request.URL.Path).
-					WithField("client_ip", c.ClientIP()).
-					Error("Panic recovered: %v", err)
-
-				// Возвращаем 500 Internal Server Error
-				c.AbortWithStatus(500)
-			}
-		}()
-
-		c.Next()
-	}
-}
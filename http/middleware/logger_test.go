@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/logging"
+)
+
+// recordingLogger запоминает сообщения в формате "LEVEL message"
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{}
+}
+
+func (l *recordingLogger) record(level, format string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, level+" "+format)
+}
+
+func (l *recordingLogger) Debug(format string, v ...interface{}) { l.record("DEBUG", format) }
+func (l *recordingLogger) Info(format string, v ...interface{})  { l.record("INFO", format) }
+func (l *recordingLogger) Warn(format string, v ...interface{})  { l.record("WARN", format) }
+func (l *recordingLogger) Error(format string, v ...interface{}) { l.record("ERROR", format) }
+func (l *recordingLogger) Fatal(format string, v ...interface{}) { l.record("FATAL", format) }
+
+func (l *recordingLogger) WithField(string, interface{}) logging.Logger     { return l }
+func (l *recordingLogger) WithFields(map[string]interface{}) logging.Logger { return l }
+func (l *recordingLogger) WithError(error) logging.Logger                   { return l }
+func (l *recordingLogger) WithContext(context.Context) logging.Logger       { return l }
+func (l *recordingLogger) WithRequestID(string) logging.Logger              { return l }
+
+func (l *recordingLogger) lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.messages...)
+}
+
+func newLoggedRouter(logger logging.Logger, options *LoggerOptions) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(LoggerWithOptions(logger, options))
+	router.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/missing", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+	router.GET("/slow", func(c *gin.Context) {
+		time.Sleep(20 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+	router.GET("/api/health/live", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func serve(router *gin.Engine, path string) {
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+}
+
+func TestLoggerWritesOnlyCompletionByDefault(t *testing.T) {
+	logger := newRecordingLogger()
+	router := newLoggedRouter(logger, nil)
+
+	serve(router, "/ok")
+
+	lines := logger.lines()
+	if len(lines) != 1 || lines[0] != "INFO Request completed" {
+		t.Errorf("lines = %v, want only completion", lines)
+	}
+}
+
+func TestLoggerSamplesSuccessfulRequests(t *testing.T) {
+	logger := newRecordingLogger()
+	router := newLoggedRouter(logger, &LoggerOptions{
+		SkipPaths:         []string{"/api/health"},
+		SuccessSampleRate: 0.0001,
+		SlowThreshold:     10 * time.Millisecond,
+	})
+
+	for i := 0; i < 100; i++ {
+		serve(router, "/ok")
+	}
+	serve(router, "/api/health/live")
+	serve(router, "/missing")
+	serve(router, "/slow")
+
+	lines := logger.lines()
+	want := map[string]bool{"WARN Client error": false, "WARN Slow request": false}
+	for _, line := range lines {
+		if _, ok := want[line]; ok {
+			want[line] = true
+		}
+	}
+	for line, found := range want {
+		if !found {
+			t.Errorf("missing %q in %v", line, lines)
+		}
+	}
+	// 2 обязательные строки и, с пренебрежимо малой вероятностью, выборочные успешные запросы
+	if len(lines) > 3 {
+		t.Errorf("lines = %d, want successful requests to be sampled: %v", len(lines), lines)
+	}
+}
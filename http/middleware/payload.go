@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/logging"
+)
+
+// defaultMaxCaptureSize максимальный размер тела, сохраняемого для лога, по умолчанию
+const defaultMaxCaptureSize = 64 * 1024
+
+// PayloadLoggerOptions содержит настройки логирования тел запросов и ответов
+type PayloadLoggerOptions struct {
+	// Скрытие полей и ограничение размера тела в логе (те же настройки, что у gRPC интерцептора)
+	logging.PayloadConfig
+	// Префиксы путей, тела которых логируются. Пустой список - все пути.
+	Paths []string
+	// Префиксы путей, тела которых не логируются
+	SkipPaths []string
+	// Максимальный размер тела, сохраняемого для скрытия полей (0 - 64 КБ).
+	// Тела большего размера не логируются: скрыть поля в неполном JSON нельзя.
+	MaxCaptureSize int
+}
+
+// PayloadLogger возвращает middleware, логирующее тела запроса и ответа в JSON на уровне DEBUG.
+// Предназначено для отладки: включается явно, поля с персональными данными скрываются по RedactFields.
+func PayloadLogger(logger logging.Logger, options *PayloadLoggerOptions) gin.HandlerFunc {
+	if options == nil {
+		options = &PayloadLoggerOptions{}
+	}
+
+	maxCapture := options.MaxCaptureSize
+	if maxCapture <= 0 {
+		maxCapture = defaultMaxCaptureSize
+	}
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if shouldSkipLogging(path, options.SkipPaths) ||
+			(len(options.Paths) > 0 && !shouldSkipLogging(path, options.Paths)) {
+			c.Next()
+			return
+		}
+
+		// Читаем начало тела запроса и возвращаем его обработчику целиком
+		var request []byte
+		if c.Request.Body != nil {
+			request, _ = io.ReadAll(io.LimitReader(c.Request.Body, int64(maxCapture)+1))
+			c.Request.Body = readCloser{
+				Reader: io.MultiReader(bytes.NewReader(request), c.Request.Body),
+				Closer: c.Request.Body,
+			}
+		}
+
+		writer := &capturingWriter{ResponseWriter: c.Writer, limit: maxCapture}
+		c.Writer = writer
+
+		c.Next()
+
+		logger.WithContext(c.Request.Context()).WithFields(map[string]interface{}{
+			"method":   c.Request.Method,
+			"path":     path,
+			"status":   writer.Status(),
+			"request":  formatCaptured(&options.PayloadConfig, request, len(request), maxCapture),
+			"response": formatCaptured(&options.PayloadConfig, writer.body.Bytes(), writer.size, maxCapture),
+		}).Debug("HTTP payload")
+	}
+}
+
+// formatCaptured форматирует сохраненное тело полного размера size; тела больше limit не логируются
+func formatCaptured(config *logging.PayloadConfig, data []byte, size, limit int) string {
+	if size > limit {
+		return fmt.Sprintf("<payload larger than %d bytes, not logged>", limit)
+	}
+	return config.Format(data)
+}
+
+// readCloser объединяет прочитанную часть тела с оставшейся
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// capturingWriter сохраняет начало тела ответа (не больше limit байт)
+type capturingWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+	size  int
+}
+
+func (w *capturingWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *capturingWriter) capture(data []byte) {
+	w.size += len(data)
+	if remaining := w.limit - w.body.Len(); remaining > 0 {
+		if len(data) > remaining {
+			data = data[:remaining]
+		}
+		w.body.Write(data)
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/logging"
+)
+
+// payloadLogger запоминает поля последней записи DEBUG
+type payloadLogger struct {
+	recordingLogger
+	fields map[string]interface{}
+	last   *map[string]interface{}
+}
+
+func newPayloadLogger() *payloadLogger {
+	return &payloadLogger{last: new(map[string]interface{})}
+}
+
+func (l *payloadLogger) Debug(format string, v ...interface{}) { *l.last = l.fields }
+
+func (l *payloadLogger) WithFields(fields map[string]interface{}) logging.Logger {
+	return &payloadLogger{fields: fields, last: l.last}
+}
+
+func (l *payloadLogger) WithContext(context.Context) logging.Logger { return l }
+
+func newPayloadRouter(logger logging.Logger, options *PayloadLoggerOptions) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(PayloadLogger(logger, options))
+	router.POST("/api/users", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusCreated, "application/json", body)
+	})
+	return router
+}
+
+func TestPayloadLoggerRedactsBodies(t *testing.T) {
+	logger := newPayloadLogger()
+	router := newPayloadRouter(logger, &PayloadLoggerOptions{
+		PayloadConfig: logging.PayloadConfig{RedactFields: []string{"user.password"}},
+	})
+
+	body := `{"user":{"login":"ivan","password":"secret"}}`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(body)))
+
+	// Обработчик получает тело целиком, клиент - ответ без изменений
+	if w.Body.String() != body {
+		t.Fatalf("response body = %s, want %s", w.Body.String(), body)
+	}
+
+	fields := *logger.last
+	want := `{"user":{"login":"ivan","password":"***"}}`
+	if fields["request"] != want || fields["response"] != want {
+		t.Errorf("logged request = %v, response = %v, want %s", fields["request"], fields["response"], want)
+	}
+	if fields["status"] != http.StatusCreated {
+		t.Errorf("logged status = %v, want 201", fields["status"])
+	}
+}
+
+func TestPayloadLoggerSkipsLargeBodies(t *testing.T) {
+	logger := newPayloadLogger()
+	router := newPayloadRouter(logger, &PayloadLoggerOptions{MaxCaptureSize: 16})
+
+	body := `{"password":"` + strings.Repeat("s", 32) + `"}`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(body)))
+
+	if w.Body.String() != body {
+		t.Fatalf("handler must receive the whole body, got %s", w.Body.String())
+	}
+	fields := *logger.last
+	for _, key := range []string{"request", "response"} {
+		if logged, _ := fields[key].(string); strings.Contains(logged, "sss") {
+			t.Errorf("%s = %s, oversized payload must not be logged", key, logged)
+		}
+	}
+}
+
+func TestPayloadLoggerPathSelection(t *testing.T) {
+	logger := newPayloadLogger()
+	router := newPayloadRouter(logger, &PayloadLoggerOptions{SkipPaths: []string{"/api/users"}})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(`{}`)))
+	if *logger.last != nil {
+		t.Errorf("skipped path logged: %v", *logger.last)
+	}
+}
@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/http/httpctx"
+	"github.com/vladzorgan/common/killswitch"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/metrics"
+	"github.com/vladzorgan/common/redis"
+)
+
+// slidingWindowScript реализует ограничение частоты по скользящему окну.
+// Возвращает {1, 0}, если запрос разрешен, или {0, retry_after_ms}, если лимит исчерпан.
+var slidingWindowScript = goredis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], 0, now - window)
+
+if redis.call("ZCARD", KEYS[1]) < limit then
+	redis.call("ZADD", KEYS[1], now, ARGV[4])
+	redis.call("PEXPIRE", KEYS[1], window)
+	return {1, 0}
+end
+
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+return {0, tonumber(oldest[2]) + window - now}
+`)
+
+// RateLimitConfig содержит настройки ограничения частоты запросов
+type RateLimitConfig struct {
+	// Максимальное количество запросов в окне
+	Requests int
+	// Размер скользящего окна
+	Interval time.Duration
+	// Заголовок для идентификации клиента (например, X-User-ID). Значение должно выставляться или
+	// проверяться шлюзом: клиент, сам задающий заголовок, обходит лимит, меняя его значение.
+	// В ключ Redis записывается хеш значения. Если не задан или пуст в запросе, используется IP клиента
+	KeyHeader string
+	// Префикс ключей в Redis
+	KeyPrefix string
+	// Префикс метрик Prometheus
+	ServicePrefix string
+	// Пути, для которых ограничение не применяется
+	SkipPaths []string
+	// Логгер
+	Logger logging.Logger
+	// Реестр метрики (nil - prometheus.DefaultRegisterer)
+	Registerer prometheus.Registerer
+	// Текущие лимиты, изменяемые без перезапуска (см. RateLimitLimits.Set).
+	// Если не заданы, используются Requests и Interval.
+	Limits *RateLimitLimits
+}
+
+// rateLimitValues лимит запросов в окне
+type rateLimitValues struct {
+	requests int
+	interval time.Duration
+}
+
+// RateLimitLimits хранит лимиты RateLimit, которые можно атомарно заменить во время работы
+type RateLimitLimits struct {
+	values atomic.Pointer[rateLimitValues]
+}
+
+// NewRateLimitLimits создает лимиты с начальными значениями
+func NewRateLimitLimits(requests int, interval time.Duration) *RateLimitLimits {
+	limits := &RateLimitLimits{}
+	limits.Set(requests, interval)
+	return limits
+}
+
+// Set заменяет лимиты; действует на следующие запросы
+func (l *RateLimitLimits) Set(requests int, interval time.Duration) {
+	l.values.Store(&rateLimitValues{requests: requests, interval: interval})
+}
+
+// Get возвращает текущие лимиты
+func (l *RateLimitLimits) Get() (requests int, interval time.Duration) {
+	values := l.values.Load()
+	return values.requests, values.interval
+}
+
+// RateLimit возвращает middleware для ограничения частоты запросов на основе Redis.
+// При недоступности Redis запросы пропускаются (fail open).
+// Отключается выключателем killswitch.FeatureRateLimit.
+func RateLimit(redisClient *redis.Client, cfg RateLimitConfig) gin.HandlerFunc {
+	if cfg.Logger == nil {
+		cfg.Logger = logging.NewLogger()
+	}
+
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = "ratelimit"
+	}
+
+	rejectedCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: cfg.ServicePrefix + "_rate_limit_rejected_total",
+			Help: "Количество запросов, отклоненных ограничением частоты",
+		},
+		[]string{"method"},
+	)
+
+	// Регистрируем метрики; несколько middleware сервиса используют общий счетчик
+	rejectedCounter = metrics.Register(cfg.Registerer, rejectedCounter)
+
+	limits := cfg.Limits
+	if limits == nil {
+		limits = NewRateLimitLimits(cfg.Requests, cfg.Interval)
+	}
+
+	return func(c *gin.Context) {
+		requests, interval := limits.Get()
+		windowMs := interval.Milliseconds()
+		if requests <= 0 || windowMs <= 0 || shouldSkipLogging(c.Request.URL.Path, cfg.SkipPaths) ||
+			killswitch.IsDisabled(killswitch.FeatureRateLimit) {
+			c.Next()
+			return
+		}
+
+		key := cfg.KeyPrefix + ":" + rateLimitClientKey(c, cfg.KeyHeader)
+
+		now := time.Now().UnixMilli()
+		result, err := slidingWindowScript.Run(
+			c.Request.Context(),
+			redisClient.Client(),
+			[]string{key},
+			now, windowMs, requests, uuid.New().String(),
+		).Int64Slice()
+		if err != nil || len(result) != 2 {
+			cfg.Logger.WithRequestID(httpctx.RequestID(c)).
+				Warn("Rate limiter unavailable, allowing request: %v", err)
+			c.Next()
+			return
+		}
+
+		if result[0] == 1 {
+			c.Next()
+			return
+		}
+
+		rejectedCounter.WithLabelValues(c.Request.Method).Inc()
+
+		retryAfter := int(math.Ceil(float64(result[1]) / 1000))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"error":   "Too Many Requests",
+			"message": "Rate limit exceeded",
+		})
+	}
+}
+
+// rateLimitClientKey возвращает ключ клиента: хеш значения keyHeader или IP клиента.
+// Значение заголовка хешируется, чтобы не записывать его (например, API ключ) в имена ключей Redis.
+func rateLimitClientKey(c *gin.Context, keyHeader string) string {
+	if keyHeader != "" {
+		if value := c.GetHeader(keyHeader); value != "" {
+			sum := sha256.Sum256([]byte(value))
+			return "h:" + hex.EncodeToString(sum[:16])
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/redis"
+)
+
+// newRateLimitRouter создает маршрутизатор с RateLimit поверх miniredis
+func newRateLimitRouter(t *testing.T, cfg RateLimitConfig) (*gin.Engine, *miniredis.Miniredis, *prometheus.Registry) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	server := miniredis.RunT(t)
+	client, err := redis.NewClient(server.Addr(), "", 0, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	registry := prometheus.NewRegistry()
+	cfg.ServicePrefix = "ratelimit_test"
+	cfg.Registerer = registry
+
+	router := gin.New()
+	router.Use(RateLimit(client, cfg))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router, server, registry
+}
+
+// rateLimitRequest выполняет GET / с адресом клиента и заголовками
+func rateLimitRequest(router *gin.Engine, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRateLimitRejectsWithRetryAfter(t *testing.T) {
+	router, _, registry := newRateLimitRouter(t, RateLimitConfig{Requests: 2, Interval: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		if recorder := rateLimitRequest(router, "10.0.0.1:1000", nil); recorder.Code != http.StatusOK {
+			t.Fatalf("request %d status = %d, want 200", i+1, recorder.Code)
+		}
+	}
+
+	recorder := rateLimitRequest(router, "10.0.0.1:1000", nil)
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", recorder.Code)
+	}
+	retryAfter, err := strconv.Atoi(recorder.Header().Get("Retry-After"))
+	if err != nil || retryAfter < 1 || retryAfter > 60 {
+		t.Errorf("Retry-After = %q, want 1..60 seconds", recorder.Header().Get("Retry-After"))
+	}
+	if body := errorBody(t, recorder); body["error"] != "Too Many Requests" {
+		t.Errorf("body = %v", body)
+	}
+
+	// Другой клиент ограничивается отдельно
+	if recorder := rateLimitRequest(router, "10.0.0.2:1000", nil); recorder.Code != http.StatusOK {
+		t.Errorf("other client status = %d, want 200", recorder.Code)
+	}
+
+	if got := rejectedTotal(t, registry); got != 1 {
+		t.Errorf("rejected = %v, want 1", got)
+	}
+}
+
+func TestRateLimitWindowExpires(t *testing.T) {
+	router, _, _ := newRateLimitRouter(t, RateLimitConfig{Requests: 1, Interval: 100 * time.Millisecond})
+
+	if recorder := rateLimitRequest(router, "10.0.0.1:1000", nil); recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", recorder.Code)
+	}
+	if recorder := rateLimitRequest(router, "10.0.0.1:1000", nil); recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", recorder.Code)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if recorder := rateLimitRequest(router, "10.0.0.1:1000", nil); recorder.Code != http.StatusOK {
+		t.Errorf("status after window = %d, want 200", recorder.Code)
+	}
+}
+
+func TestRateLimitKeyHeader(t *testing.T) {
+	router, server, _ := newRateLimitRouter(t, RateLimitConfig{Requests: 1, Interval: time.Minute, KeyHeader: "X-User-ID"})
+
+	// Ключ по заголовку: разные клиенты за одним IP ограничиваются отдельно
+	for _, user := range []string{"secret-1", "secret-2"} {
+		if recorder := rateLimitRequest(router, "10.0.0.1:1000", map[string]string{"X-User-ID": user}); recorder.Code != http.StatusOK {
+			t.Errorf("user %s status = %d, want 200", user, recorder.Code)
+		}
+	}
+	if recorder := rateLimitRequest(router, "10.0.0.2:1000", map[string]string{"X-User-ID": "secret-1"}); recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("same header from other IP status = %d, want 429", recorder.Code)
+	}
+
+	// Без заголовка используется IP
+	if recorder := rateLimitRequest(router, "10.0.0.1:1000", nil); recorder.Code != http.StatusOK {
+		t.Errorf("by IP status = %d, want 200", recorder.Code)
+	}
+	if recorder := rateLimitRequest(router, "10.0.0.1:1000", nil); recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("by IP status = %d, want 429", recorder.Code)
+	}
+
+	// Значение заголовка не попадает в имена ключей Redis
+	keys := server.Keys()
+	if len(keys) != 3 {
+		t.Errorf("keys = %v, want 3", keys)
+	}
+	for _, key := range keys {
+		if strings.Contains(key, "secret") {
+			t.Errorf("key %q contains the raw header value", key)
+		}
+	}
+}
+
+func TestRateLimitFailsOpenWhenRedisIsDown(t *testing.T) {
+	router, server, registry := newRateLimitRouter(t, RateLimitConfig{Requests: 1, Interval: time.Minute})
+	server.Close()
+
+	for i := 0; i < 3; i++ {
+		if recorder := rateLimitRequest(router, "10.0.0.1:1000", nil); recorder.Code != http.StatusOK {
+			t.Errorf("request %d status = %d, want 200", i+1, recorder.Code)
+		}
+	}
+	if got := rejectedTotal(t, registry); got != 0 {
+		t.Errorf("rejected = %v, want 0", got)
+	}
+}
+
+// rejectedTotal возвращает сумму счетчика отклоненных запросов по всем методам
+func rejectedTotal(t *testing.T, registry *prometheus.Registry) float64 {
+	t.Helper()
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	var total float64
+	for _, family := range families {
+		if family.GetName() != "ratelimit_test_rate_limit_rejected_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			total += metric.GetCounter().GetValue()
+		}
+	}
+	return total
+}
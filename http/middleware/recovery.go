@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/http/httpctx"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/metrics"
+)
+
+// PanicHandler вызывается после восстановления после паники при обработке маршрута method
+// (например, "GET /api/v1/users/:id"); recovered - значение, переданное в panic
+type PanicHandler func(ctx context.Context, method string, recovered interface{}, stack []byte)
+
+// RecoveryOptions содержит настройки middleware восстановления после паники
+type RecoveryOptions struct {
+	// Обработчик перехваченных паник (например, для отправки оповещения)
+	OnPanic PanicHandler
+	// Префикс метрики panics_recovered_total
+	ServicePrefix string
+	// Реестр метрики (nil - prometheus.DefaultRegisterer)
+	Registerer prometheus.Registerer
+}
+
+// Recovery возвращает middleware для восстановления после паники
+func Recovery(logger logging.Logger) gin.HandlerFunc {
+	return RecoveryWithOptions(logger, nil)
+}
+
+// RecoveryWithOptions возвращает middleware, которое при панике отвечает 500, увеличивает
+// счетчик panics_recovered_total и вызывает OnPanic
+func RecoveryWithOptions(logger logging.Logger, options *RecoveryOptions) gin.HandlerFunc {
+	if options == nil {
+		options = &RecoveryOptions{}
+	}
+	panics := metrics.PanicsRecovered(options.Registerer, options.ServicePrefix)
+
+	return func(c *gin.Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				stack := debug.Stack()
+
+				// Получаем идентификатор запроса
+				requestID := httpctx.RequestID(c)
+				if requestID == "" {
+					requestID = uuid.New().String()
+					httpctx.SetRequestID(c, requestID)
+				}
+
+				// Логируем ошибку
+				logger.WithRequestID(requestID).
+					WithField("method", c.Request.Method).
+					WithField("path", c.Request.URL.Path).
+					WithField("client_ip", c.ClientIP()).
+					WithField("stack", string(stack)).
+					Error("Panic recovered: %v", err)
+
+				// Маршрут вместо пути, чтобы идентификаторы в URL не увеличивали число меток
+				route := c.FullPath()
+				if route == "" {
+					route = metrics.UnmatchedPath
+				}
+				method := c.Request.Method + " " + route
+				panics.WithLabelValues(method).Inc()
+
+				if options.OnPanic != nil {
+					callPanicHandler(c.Request.Context(), logger, options.OnPanic, method, err, stack)
+				}
+
+				// Возвращаем 500 Internal Server Error
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// callPanicHandler вызывает OnPanic; паника в самом обработчике не должна остановить сервер
+func callPanicHandler(ctx context.Context, logger logging.Logger, handler PanicHandler, method string, recovered interface{}, stack []byte) {
+	defer func() {
+		if hookPanic := recover(); hookPanic != nil {
+			logger.WithField("method", method).Error("Panic in OnPanic handler: %v", hookPanic)
+		}
+	}()
+
+	handler(ctx, method, recovered, stack)
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/vladzorgan/common/metrics"
+)
+
+func TestRecoveryWithOptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := prometheus.NewRegistry()
+
+	var method string
+	var recovered interface{}
+	router := gin.New()
+	router.Use(RecoveryWithOptions(newRecordingLogger(), &RecoveryOptions{
+		OnPanic: func(ctx context.Context, m string, r interface{}, stack []byte) {
+			method, recovered = m, r
+			if len(stack) == 0 {
+				t.Error("OnPanic received empty stack")
+			}
+		},
+		ServicePrefix: "http",
+		Registerer:    registry,
+	}))
+	router.GET("/users/:id", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusInternalServerError)
+	}
+	if method != "GET /users/:id" || recovered != "boom" {
+		t.Errorf("OnPanic(%q, %v), want GET /users/:id, boom", method, recovered)
+	}
+
+	panics := metrics.PanicsRecovered(registry, "http")
+	if got := testutil.ToFloat64(panics.WithLabelValues("GET /users/:id")); got != 1 {
+		t.Errorf("http_panics_recovered_total = %v, want 1", got)
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityHeadersOptions содержит настройки заголовков безопасности.
+// Пустое значение заголовка отключает его.
+type SecurityHeadersOptions struct {
+	// X-Frame-Options (например, "DENY" или "SAMEORIGIN")
+	FrameOptions string
+	// Referrer-Policy
+	ReferrerPolicy string
+	// Content-Security-Policy; по умолчанию не задается, так как зависит от страниц сервиса
+	ContentSecurityPolicy string
+	// Время действия Strict-Transport-Security (0 - заголовок не отправляется).
+	// Заголовок отправляется только для запросов по HTTPS.
+	HSTSMaxAge time.Duration
+	// Добавлять includeSubDomains в Strict-Transport-Security
+	HSTSIncludeSubdomains bool
+	// Считать запрос защищенным по заголовку X-Forwarded-Proto (TLS завершается на балансировщике).
+	// Включайте, только если заголовок задает доверенный прокси: клиент может подставить его сам.
+	TrustForwardedProto bool
+}
+
+// DefaultSecurityHeadersOptions возвращает настройки заголовков безопасности по умолчанию
+func DefaultSecurityHeadersOptions() *SecurityHeadersOptions {
+	return &SecurityHeadersOptions{
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+	}
+}
+
+// SecurityHeaders добавляет к ответам заголовки безопасности: X-Content-Type-Options,
+// X-Frame-Options, Referrer-Policy, Content-Security-Policy и, для запросов по HTTPS,
+// Strict-Transport-Security
+func SecurityHeaders(options *SecurityHeadersOptions) gin.HandlerFunc {
+	if options == nil {
+		options = DefaultSecurityHeadersOptions()
+	}
+
+	var hsts string
+	if options.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", int64(options.HSTSMaxAge.Seconds()))
+		if options.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		if options.FrameOptions != "" {
+			header.Set("X-Frame-Options", options.FrameOptions)
+		}
+		if options.ReferrerPolicy != "" {
+			header.Set("Referrer-Policy", options.ReferrerPolicy)
+		}
+		if options.ContentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", options.ContentSecurityPolicy)
+		}
+
+		// По HTTP заголовок HSTS игнорируется браузерами и закрепил бы HTTPS для локальных окружений
+		secure := c.Request.TLS != nil || (options.TrustForwardedProto && c.GetHeader("X-Forwarded-Proto") == "https")
+		if hsts != "" && secure {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(SecurityHeaders(&SecurityHeadersOptions{
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "no-referrer",
+		ContentSecurityPolicy: "default-src 'self'",
+		HSTSMaxAge:            time.Hour,
+	}))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	for name, want := range map[string]string{
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "DENY",
+		"Referrer-Policy":           "no-referrer",
+		"Content-Security-Policy":   "default-src 'self'",
+		"Strict-Transport-Security": "",
+	} {
+		if got := w.Header().Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	// HSTS отправляется только по HTTPS
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.TLS = &tls.ConnectionState{}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if got := w.Header().Get("Strict-Transport-Security"); got != "max-age=3600" {
+		t.Errorf("Strict-Transport-Security = %q, want max-age=3600", got)
+	}
+}
+
+func TestSecurityHeadersForwardedProto(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	request := func(options *SecurityHeadersOptions) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(SecurityHeaders(options))
+		router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Forwarded-Proto", "https")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// По умолчанию X-Forwarded-Proto не доверяется
+	if got := request(nil).Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("Strict-Transport-Security = %q, want none without TrustForwardedProto", got)
+	}
+
+	options := DefaultSecurityHeadersOptions()
+	options.TrustForwardedProto = true
+	w := request(options)
+	if got := w.Header().Get("Strict-Transport-Security"); got != "max-age=31536000; includeSubDomains" {
+		t.Errorf("Strict-Transport-Security = %q", got)
+	}
+	if got := w.Header().Get("Content-Security-Policy"); got != "" {
+		t.Errorf("Content-Security-Policy = %q, want none by default", got)
+	}
+}
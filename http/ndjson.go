@@ -0,0 +1,135 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/repository"
+	"github.com/vladzorgan/common/service"
+)
+
+// NDJSONContentType - тип содержимого потока NDJSON
+const NDJSONContentType = "application/x-ndjson"
+
+// errStreamLimit прерывает поток при достижении лимита строк
+var errStreamLimit = errors.New("stream row limit reached")
+
+// ListOptions содержит параметры потоковой выдачи списка
+type ListOptions struct {
+	// Фильтры выборки
+	Filters map[string]interface{}
+	// Сортировка
+	Sort *repository.SortOptions
+	// Максимальное количество строк (0 - без ограничения)
+	MaxRows int
+	// Максимальная длительность потока (0 - без ограничения)
+	MaxDuration time.Duration
+	// Количество строк между сбросами буфера
+	FlushEvery int
+}
+
+// streamErrorLine представляет завершающую строку потока при ошибке
+type streamErrorLine struct {
+	StreamError *string `json:"stream_error"`
+}
+
+// StreamNDJSON отдает все сущности, соответствующие фильтрам, в формате NDJSON (один JSON объект на строку).
+// Поток прекращается при отключении клиента, достижении MaxRows или MaxDuration.
+// Если ошибка возникла после начала передачи, в конец потока записывается строка {"stream_error": "..."}.
+func StreamNDJSON[T service.BaseEntity, R any](c *gin.Context, svc service.Service[T, R], opts ListOptions) error {
+	ctx := c.Request.Context()
+	if opts.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.MaxDuration)
+		defer cancel()
+	}
+
+	if opts.FlushEvery <= 0 {
+		opts.FlushEvery = 100
+	}
+
+	c.Header("Content-Type", NDJSONContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	rows := 0
+
+	err := svc.Stream(ctx, opts.Filters, opts.Sort, func(response *R) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if opts.MaxRows > 0 && rows >= opts.MaxRows {
+			return errStreamLimit
+		}
+
+		if err := encoder.Encode(response); err != nil {
+			return fmt.Errorf("failed to encode row %d: %w", rows, err)
+		}
+
+		rows++
+		if rows%opts.FlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+
+	switch {
+	case err == nil, errors.Is(err, errStreamLimit):
+		c.Writer.Flush()
+		return nil
+	case c.Request.Context().Err() != nil:
+		// Клиент отключился, писать больше некуда
+		return c.Request.Context().Err()
+	default:
+		message := err.Error()
+		if errors.Is(err, context.DeadlineExceeded) {
+			message = "stream duration limit exceeded"
+		}
+		_ = encoder.Encode(streamErrorLine{StreamError: &message})
+		c.Writer.Flush()
+		return err
+	}
+}
+
+// ReadNDJSON читает поток NDJSON, передавая каждый объект в fn.
+// Возвращает ошибку, если сервер завершил поток строкой stream_error.
+func ReadNDJSON[R any](ctx context.Context, r io.Reader, fn func(item *R) error) error {
+	decoder := json.NewDecoder(r)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to decode NDJSON line: %w", err)
+		}
+
+		var errLine streamErrorLine
+		if err := json.Unmarshal(raw, &errLine); err == nil && errLine.StreamError != nil {
+			return fmt.Errorf("stream failed: %s", *errLine.StreamError)
+		}
+
+		item := new(R)
+		if err := json.Unmarshal(raw, item); err != nil {
+			return fmt.Errorf("failed to decode NDJSON item: %w", err)
+		}
+
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+}
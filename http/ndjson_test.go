@@ -0,0 +1,206 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/repository"
+	"github.com/vladzorgan/common/service"
+)
+
+type streamEntity struct {
+	ID   uint
+	Name string
+}
+
+func (e streamEntity) GetID() uint        { return e.ID }
+func (streamEntity) GetTableName() string { return "stream_entities" }
+func (e streamEntity) GetName() string    { return e.Name }
+
+type streamResponse struct {
+	ID    uint    `json:"id"`
+	Score float64 `json:"score"`
+}
+
+// streamService отдает заранее заданные ответы через Stream; остальные методы сервиса не используются
+type streamService struct {
+	service.Service[streamEntity, streamResponse]
+
+	items []streamResponse
+	// onRow вызывается перед передачей строки с ее номером
+	onRow func(i int)
+}
+
+func (s *streamService) Stream(ctx context.Context, _ map[string]interface{}, _ *repository.SortOptions, fn func(response *streamResponse) error) error {
+	for i := range s.items {
+		if s.onRow != nil {
+			s.onRow(i)
+		}
+		if err := fn(&s.items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func streamItems(n int) []streamResponse {
+	items := make([]streamResponse, n)
+	for i := range items {
+		items[i] = streamResponse{ID: uint(i + 1), Score: float64(i)}
+	}
+	return items
+}
+
+// runStream выполняет StreamNDJSON в тестовом контексте gin с контекстом запроса ctx
+func runStream(ctx context.Context, svc *streamService, opts ListOptions) (*httptest.ResponseRecorder, error) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/export", nil).WithContext(ctx)
+
+	err := StreamNDJSON[streamEntity, streamResponse](c, svc, opts)
+	return w, err
+}
+
+// readStream читает ответ через ReadNDJSON и возвращает полученные ID
+func readStream(t *testing.T, body string) ([]uint, error) {
+	t.Helper()
+
+	var ids []uint
+	err := ReadNDJSON(context.Background(), strings.NewReader(body), func(item *streamResponse) error {
+		ids = append(ids, item.ID)
+		return nil
+	})
+	return ids, err
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	w, err := runStream(context.Background(), &streamService{items: streamItems(5)}, ListOptions{FlushEvery: 2})
+	if err != nil {
+		t.Fatalf("StreamNDJSON() error = %v", err)
+	}
+
+	if got := w.Header().Get("Content-Type"); got != NDJSONContentType {
+		t.Errorf("Content-Type = %s, want %s", got, NDJSONContentType)
+	}
+	if lines := strings.Count(w.Body.String(), "\n"); lines != 5 {
+		t.Errorf("lines = %d, want 5; body = %s", lines, w.Body.String())
+	}
+	if !w.Flushed {
+		t.Error("stream was not flushed")
+	}
+
+	ids, err := readStream(t, w.Body.String())
+	if err != nil || len(ids) != 5 || ids[0] != 1 || ids[4] != 5 {
+		t.Errorf("ReadNDJSON() = %v, %v; want ids 1..5", ids, err)
+	}
+}
+
+func TestStreamNDJSONMaxRows(t *testing.T) {
+	w, err := runStream(context.Background(), &streamService{items: streamItems(10)}, ListOptions{MaxRows: 3})
+	if err != nil {
+		t.Fatalf("StreamNDJSON() error = %v", err)
+	}
+
+	ids, err := readStream(t, w.Body.String())
+	if err != nil || len(ids) != 3 {
+		t.Errorf("ReadNDJSON() = %v, %v; want 3 rows", ids, err)
+	}
+}
+
+func TestStreamNDJSONClientDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Клиент отключается после передачи двух строк
+	svc := &streamService{items: streamItems(10), onRow: func(i int) {
+		if i == 2 {
+			cancel()
+		}
+	}}
+
+	w, err := runStream(ctx, svc, ListOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("StreamNDJSON() error = %v, want context.Canceled", err)
+	}
+
+	// Отключившемуся клиенту строка ошибки не пишется
+	body := w.Body.String()
+	if strings.Contains(body, "stream_error") {
+		t.Errorf("body = %s, want no stream_error line", body)
+	}
+	if lines := strings.Count(body, "\n"); lines != 2 {
+		t.Errorf("lines = %d, want 2", lines)
+	}
+}
+
+func TestStreamNDJSONEncoderError(t *testing.T) {
+	items := streamItems(4)
+	items[2].Score = math.NaN()
+
+	w, err := runStream(context.Background(), &streamService{items: items}, ListOptions{})
+	if err == nil || !strings.Contains(err.Error(), "failed to encode row 2") {
+		t.Fatalf("StreamNDJSON() error = %v, want encode error", err)
+	}
+
+	ids, err := readStream(t, w.Body.String())
+	if len(ids) != 2 || err == nil || !strings.Contains(err.Error(), "stream failed: failed to encode row 2") {
+		t.Errorf("ReadNDJSON() = %v, %v; want 2 rows and stream error", ids, err)
+	}
+}
+
+func TestStreamNDJSONMaxDuration(t *testing.T) {
+	svc := &streamService{items: streamItems(5), onRow: func(i int) {
+		if i == 1 {
+			time.Sleep(20 * time.Millisecond)
+		}
+	}}
+
+	w, err := runStream(context.Background(), svc, ListOptions{MaxDuration: 5 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("StreamNDJSON() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	ids, err := readStream(t, w.Body.String())
+	if len(ids) != 1 || err == nil || !strings.Contains(err.Error(), "stream duration limit exceeded") {
+		t.Errorf("ReadNDJSON() = %v, %v; want 1 row and duration error", ids, err)
+	}
+}
+
+func TestReadNDJSONStopsOnCallbackErrorAndCancellation(t *testing.T) {
+	body := "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"
+	errStop := errors.New("stop")
+
+	calls := 0
+	err := ReadNDJSON(context.Background(), strings.NewReader(body), func(item *streamResponse) error {
+		calls++
+		if item.ID == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) || calls != 2 {
+		t.Errorf("ReadNDJSON() = %v after %d calls, want errStop after 2", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	err = ReadNDJSON(ctx, strings.NewReader(body), func(item *streamResponse) error {
+		calls++
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("ReadNDJSON() = %v after %d calls, want context.Canceled after 1", err, calls)
+	}
+
+	if err := ReadNDJSON(context.Background(), strings.NewReader("{\"id\":"), func(*streamResponse) error { return nil }); err == nil {
+		t.Error("ReadNDJSON() must fail on a truncated line")
+	}
+}
@@ -0,0 +1,150 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	apperrors "github.com/vladzorgan/common/errors"
+	"github.com/vladzorgan/common/http/httpctx"
+	"github.com/vladzorgan/common/logging"
+	"gorm.io/gorm"
+)
+
+// Машиночитаемые коды ошибок в конверте ответа
+const (
+	CodeBadRequest       = "bad_request"
+	CodeValidation       = "validation_failed"
+	CodeUnauthenticated  = "unauthenticated"
+	CodePermissionDenied = "permission_denied"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeTooManyRequests  = "too_many_requests"
+	CodeCanceled         = "canceled"
+	CodeTimeout          = "timeout"
+	CodeUnavailable      = "unavailable"
+	CodeInternal         = "internal"
+)
+
+// internalMessage текст ошибок 5xx без типизированного сообщения: детали не раскрываются клиенту
+const internalMessage = "internal server error"
+
+// ErrorBody содержимое конверта ошибки
+type ErrorBody struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+	// Дополнительные сведения (например, причины блокировки операции по ID сущностей)
+	Details interface{} `json:"details,omitempty"`
+}
+
+// ErrorEnvelope конверт ошибки
+type ErrorEnvelope struct {
+	Error ErrorBody `json:"error"`
+}
+
+// Error прерывает запрос с HTTP кодом и конвертом, соответствующими ошибке.
+// Поддерживает типизированные ошибки пакета errors, gorm.ErrRecordNotFound, ошибки
+// валидации validator и отмену или истечение контекста запроса.
+func Error(c *gin.Context, err error) {
+	status, body := errorBody(err)
+	body.RequestID = requestID(c)
+
+	c.AbortWithStatusJSON(status, ErrorEnvelope{Error: body})
+}
+
+// ErrorWithStatus прерывает запрос с заданным HTTP кодом и сообщением, например, для ошибок разбора запроса
+func ErrorWithStatus(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, ErrorEnvelope{Error: ErrorBody{
+		Code:      statusCode(status),
+		Message:   message,
+		RequestID: requestID(c),
+	}})
+}
+
+// errorBody определяет HTTP код и содержимое конверта ошибки
+func errorBody(err error) (int, ErrorBody) {
+	var validationErrs validator.ValidationErrors
+	var typedErr *apperrors.Error
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound, ErrorBody{Code: CodeNotFound, Message: "record not found"}
+	case errors.As(err, &validationErrs):
+		return http.StatusBadRequest, ErrorBody{Code: CodeValidation, Message: err.Error()}
+	case errors.Is(err, context.Canceled):
+		return 499, ErrorBody{Code: CodeCanceled, Message: "request canceled"}
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, ErrorBody{Code: CodeTimeout, Message: "request timed out"}
+	}
+
+	status := apperrors.ToHTTPStatus(err)
+	body := ErrorBody{
+		Code:    statusCode(status),
+		Message: err.Error(),
+	}
+	if errors.Is(err, apperrors.ErrValidation) {
+		body.Code = CodeValidation
+	}
+
+	if errors.As(err, &typedErr) {
+		details := gin.H{}
+		if len(typedErr.Blockers) > 0 {
+			details["blockers"] = typedErr.Blockers
+		}
+		if len(typedErr.Fields) > 0 {
+			details["fields"] = typedErr.Fields
+		}
+		if len(details) > 0 {
+			body.Details = details
+		}
+		if status >= http.StatusInternalServerError {
+			body.Message = typedErr.Message
+		}
+	} else if status >= http.StatusInternalServerError {
+		body.Message = ""
+	}
+
+	if body.Message == "" {
+		body.Message = internalMessage
+	}
+
+	return status, body
+}
+
+// statusCode возвращает код ошибки для HTTP кода
+func statusCode(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodePermissionDenied
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case 499:
+		return CodeCanceled
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// requestID возвращает ID запроса из middleware.RequestID или контекста запроса
+func requestID(c *gin.Context) string {
+	if id := httpctx.RequestID(c); id != "" {
+		return id
+	}
+	return logging.ExtractRequestID(c.Request.Context())
+}
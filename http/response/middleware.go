@@ -0,0 +1,41 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/logging"
+)
+
+// ErrorHandler возвращает middleware, отвечающее конвертом ошибки, если обработчик
+// добавил ошибку через c.Error (или c.Bind отклонил запрос) и не записал тело ответа.
+// Используется последняя ошибка; ошибки привязки запроса (gin.ErrorTypeBind) отвечают кодом 400.
+// Ошибки 5xx логируются, если задан logger.
+func ErrorHandler(logger logging.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		// c.Bind записывает только заголовок ответа, тело остается пустым
+		if len(c.Errors) == 0 || c.Writer.Size() > 0 {
+			return
+		}
+
+		last := c.Errors.Last()
+		if last.IsType(gin.ErrorTypeBind) {
+			status, body := errorBody(last.Err)
+			if status != http.StatusBadRequest {
+				body = ErrorBody{Code: CodeBadRequest, Message: last.Err.Error()}
+			}
+			body.RequestID = requestID(c)
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrorEnvelope{Error: body})
+			return
+		}
+
+		status, _ := errorBody(last.Err)
+		if logger != nil && status >= http.StatusInternalServerError {
+			logger.WithRequestID(requestID(c)).Error("Request %s %s failed: %v", c.Request.Method, c.FullPath(), last.Err)
+		}
+
+		Error(c, last.Err)
+	}
+}
@@ -0,0 +1,38 @@
+// Package response предоставляет единый формат ответов Gin обработчиков.
+// Успешные ответы содержат данные без обертки, ошибки - конверт
+// {"error": {"code": "...", "message": "...", "request_id": "..."}}.
+package response
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/service"
+)
+
+// OK отвечает кодом 200 с данными
+func OK(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusOK, data)
+}
+
+// Created отвечает кодом 201 с созданной сущностью
+func Created(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusCreated, data)
+}
+
+// NoContent отвечает кодом 204 без тела
+func NoContent(c *gin.Context) {
+	c.Status(http.StatusNoContent)
+}
+
+// Paginated отвечает кодом 200 со страницей элементов в формате service.PaginationResponse
+func Paginated[T any](c *gin.Context, items []T, pagination service.Pagination) {
+	if items == nil {
+		items = []T{}
+	}
+
+	c.JSON(http.StatusOK, service.PaginationResponse[T]{
+		Items:      items,
+		Pagination: pagination,
+	})
+}
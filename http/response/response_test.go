@@ -0,0 +1,232 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/vladzorgan/common/errors"
+	"github.com/vladzorgan/common/service"
+	"gorm.io/gorm"
+)
+
+// serve выполняет запрос к маршруту с обработчиком handler и возвращает ответ
+func serve(t *testing.T, handler gin.HandlerFunc, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("RequestID", "req-1") })
+	router.Use(ErrorHandler(nil))
+	router.POST("/items", handler)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func decodeError(t *testing.T, recorder *httptest.ResponseRecorder) ErrorBody {
+	t.Helper()
+
+	var envelope ErrorEnvelope
+	if err := json.Unmarshal(recorder.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("invalid error envelope %q: %v", recorder.Body.String(), err)
+	}
+	return envelope.Error
+}
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantCode    string
+		wantMessage string
+	}{
+		{
+			name:        "typed not found",
+			err:         apperrors.NotFound("order", 5),
+			wantStatus:  http.StatusNotFound,
+			wantCode:    CodeNotFound,
+			wantMessage: apperrors.NotFound("order", 5).Error(),
+		},
+		{
+			name:        "typed validation",
+			err:         apperrors.Validation("order", errors.New("amount must be positive")),
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    CodeValidation,
+			wantMessage: apperrors.Validation("order", errors.New("amount must be positive")).Error(),
+		},
+		{
+			name:        "gorm record not found",
+			err:         fmt.Errorf("load order: %w", gorm.ErrRecordNotFound),
+			wantStatus:  http.StatusNotFound,
+			wantCode:    CodeNotFound,
+			wantMessage: "record not found",
+		},
+		{
+			name:        "typed internal hides cause",
+			err:         apperrors.Internal("order", errors.New("pq: connection refused"), "не удалось сохранить заказ"),
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    CodeInternal,
+			wantMessage: "не удалось сохранить заказ",
+		},
+		{
+			name:        "untyped error",
+			err:         errors.New("dial tcp 10.0.0.1:5432: timeout"),
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    CodeInternal,
+			wantMessage: internalMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := serve(t, func(c *gin.Context) { Error(c, tt.err) }, "")
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+
+			body := decodeError(t, recorder)
+			if body.Code != tt.wantCode || body.Message != tt.wantMessage || body.RequestID != "req-1" {
+				t.Errorf("error = %+v, want code %q message %q", body, tt.wantCode, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestErrorBlockersInDetails(t *testing.T) {
+	recorder := serve(t, func(c *gin.Context) {
+		Error(c, apperrors.Blocked("region", map[uint][]string{3: {"has 12 cities"}}))
+	}, "")
+
+	var envelope struct {
+		Error struct {
+			Details struct {
+				Blockers map[string][]string `json:"blockers"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if got := envelope.Error.Details.Blockers["3"]; len(got) != 1 || got[0] != "has 12 cities" {
+		t.Errorf("blockers = %v", envelope.Error.Details.Blockers)
+	}
+}
+
+func TestErrorHandlerMiddleware(t *testing.T) {
+	type input struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	// Ошибка, добавленная обработчиком
+	recorder := serve(t, func(c *gin.Context) {
+		c.Error(apperrors.Conflict("order", 1, errors.New("duplicate number")))
+	}, "")
+	if recorder.Code != http.StatusConflict || decodeError(t, recorder).Code != CodeConflict {
+		t.Errorf("c.Error: status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+
+	// c.Bind отклоняет запрос, не записывая тело
+	recorder = serve(t, func(c *gin.Context) {
+		var in input
+		if err := c.Bind(&in); err != nil {
+			return
+		}
+		OK(c, in)
+	}, `{}`)
+	if body := decodeError(t, recorder); recorder.Code != http.StatusBadRequest || body.Code != CodeValidation || body.RequestID != "req-1" {
+		t.Errorf("c.Bind validation: status = %d, body = %+v", recorder.Code, body)
+	}
+
+	recorder = serve(t, func(c *gin.Context) {
+		var in input
+		if err := c.Bind(&in); err != nil {
+			return
+		}
+		OK(c, in)
+	}, `{"name":`)
+	if body := decodeError(t, recorder); recorder.Code != http.StatusBadRequest || body.Code != CodeBadRequest {
+		t.Errorf("c.Bind syntax: status = %d, body = %+v", recorder.Code, body)
+	}
+
+	// Записанный обработчиком ответ не переписывается
+	recorder = serve(t, func(c *gin.Context) {
+		c.Error(errors.New("logged only"))
+		Created(c, gin.H{"id": 1})
+	}, "")
+	if recorder.Code != http.StatusCreated || !strings.Contains(recorder.Body.String(), `"id":1`) {
+		t.Errorf("written response: status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestPaginated(t *testing.T) {
+	recorder := serve(t, func(c *gin.Context) {
+		Paginated[string](c, nil, service.Pagination{Total: 0, Page: 1, Size: 20})
+	}, "")
+
+	var body service.PaginationResponse[string]
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if recorder.Code != http.StatusOK || body.Items == nil || body.Pagination.Size != 20 {
+		t.Errorf("Paginated() = %d %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestErrorFieldsInDetails(t *testing.T) {
+	recorder := serve(t, func(c *gin.Context) {
+		err := apperrors.Validation("city", errors.New("name: обязательное поле"))
+		err.Fields = map[string]string{"name": "обязательное поле"}
+		Error(c, err)
+	}, "")
+
+	var envelope struct {
+		Error struct {
+			Code    string `json:"code"`
+			Details struct {
+				Fields map[string]string `json:"fields"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if recorder.Code != http.StatusBadRequest || envelope.Error.Code != CodeValidation {
+		t.Errorf("status = %d, code = %s", recorder.Code, envelope.Error.Code)
+	}
+	if envelope.Error.Details.Fields["name"] != "обязательное поле" {
+		t.Errorf("fields = %v", envelope.Error.Details.Fields)
+	}
+}
+
+func TestErrorMergesBlockersAndFields(t *testing.T) {
+	recorder := serve(t, func(c *gin.Context) {
+		err := apperrors.Blocked("region", map[uint][]string{3: {"has 12 cities"}})
+		err.Fields = map[string]string{"name": "обязательное поле"}
+		Error(c, err)
+	}, "")
+
+	var envelope struct {
+		Error struct {
+			Details struct {
+				Blockers map[string][]string `json:"blockers"`
+				Fields   map[string]string   `json:"fields"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if len(envelope.Error.Details.Blockers["3"]) != 1 || envelope.Error.Details.Fields["name"] == "" {
+		t.Errorf("details = %+v, want both blockers and fields", envelope.Error.Details)
+	}
+}
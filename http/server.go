@@ -4,18 +4,25 @@ package http
 import (
 	"context"
 	"fmt"
+	"html/template"
+	"io/fs"
 	"net/http"
 	"time"
 
+	"github.com/vladzorgan/common/budget"
 	"github.com/vladzorgan/common/config"
 	"github.com/vladzorgan/common/health"
 	"github.com/vladzorgan/common/http/middleware"
+	"github.com/vladzorgan/common/killswitch"
+	"github.com/vladzorgan/common/lifecycle"
 	"github.com/vladzorgan/common/logging"
+	events "github.com/vladzorgan/common/messaging/rabbitmq"
 	"github.com/vladzorgan/common/metrics"
+	"github.com/vladzorgan/common/profiling"
+	"github.com/vladzorgan/common/redis"
+	"github.com/vladzorgan/common/tracing"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // Server представляет HTTP сервер
@@ -25,16 +32,76 @@ type Server struct {
 	cfg         *config.BaseConfig
 	logger      logging.Logger
 	healthCheck *health.Checker
+
+	// События жизненного цикла экземпляра
+	announcer       *lifecycle.Announcer
+	announcerCancel context.CancelFunc
 }
 
 // ServerOptions содержит опции для создания HTTP сервера
 type ServerOptions struct {
-	EnableCORS     bool
+	EnableCORS bool
+	// Настройки CORS; nil - middleware.DefaultCORSOptions с источниками config.CorsOrigins.
+	// Если настройки заданы, изменения CorsOrigins в ConfigWatcher не применяются.
+	CORS *middleware.CORSOptions
+
+	// Заголовки безопасности (X-Content-Type-Options, X-Frame-Options, HSTS и т.д.), по умолчанию отключены:
+	// X-Frame-Options и HSTS могут сломать встраиваемые страницы и окружения без HTTPS.
+	// nil SecurityHeaders - middleware.DefaultSecurityHeadersOptions
+	EnableSecurityHeaders bool
+	SecurityHeaders       *middleware.SecurityHeadersOptions
+
 	EnableMetrics  bool
 	EnableHealth   bool
 	EnableSwagger  bool
 	TrustedProxies []string
 	SkipLogPaths   []string
+
+	// Настройки логирования запросов (выборка успешных запросов, порог медленных).
+	// Nil - логируются все завершенные запросы; пустой AccessLog.SkipPaths заменяется на SkipLogPaths.
+	AccessLog *middleware.LoggerOptions
+
+	// /readiness возвращает 503 с причиной "starting", пока не вызван HealthChecker().SetReady(true)
+	StartNotReady bool
+
+	// Ограничение частоты запросов (требует RedisClient). RateLimitKeyHeader - заголовок клиента,
+	// выставляемый шлюзом (см. middleware.RateLimitConfig.KeyHeader); пустой - лимит по IP
+	EnableRateLimit    bool
+	RedisClient        *redis.Client
+	RateLimitKeyHeader string
+
+	// Служебные эндпоинты /admin (состояние выключателей функций)
+	EnableAdmin bool
+
+	// Максимальный размер тела запроса в байтах (0 - без ограничения)
+	MaxBodyBytes int64
+	// Максимальное время обработки запроса обработчиком (0 - без ограничения)
+	HandlerTimeout time.Duration
+
+	// Режим Gin (gin.DebugMode, gin.ReleaseMode, gin.TestMode); по умолчанию выводится из окружения
+	GinMode string
+
+	// Статические файлы: префикс URL -> каталог на диске или встроенная файловая система
+	StaticDirs map[string]string
+	StaticFS   map[string]fs.FS
+	// Время кеширования статических файлов браузером (в окружении development не кешируются)
+	StaticMaxAge time.Duration
+
+	// HTML шаблоны: glob на диске (в окружении development перечитываются при каждом рендеринге)
+	// или встроенная файловая система с шаблонами TemplatesPatterns (по умолчанию *.html)
+	TemplatesGlob     string
+	TemplatesFS       fs.FS
+	TemplatesPatterns []string
+	TemplateFuncs     template.FuncMap
+
+	// Наблюдатель конфигурации (см. config.NewWatcher): при изменении LogLevel переключается
+	// уровень логгера (если он поддерживает SetLevel), при изменении RateLimitRequests и
+	// RateLimitInterval - лимиты ограничения частоты запросов. Запускается вызывающим кодом.
+	ConfigWatcher *config.Watcher
+
+	// Издатель событий жизненного цикла (см. lifecycle.NewPublisher).
+	// Используется, если включен config.LifecycleEvents и проверка здоровья.
+	LifecyclePublisher events.EventPublisher
 }
 
 // DefaultServerOptions возвращает опции по умолчанию
@@ -46,6 +113,7 @@ func DefaultServerOptions() *ServerOptions {
 		EnableSwagger:  true,
 		TrustedProxies: []string{"127.0.0.1"},
 		SkipLogPaths:   []string{"/metrics", "/api/health"},
+		StaticMaxAge:   time.Hour,
 	}
 }
 
@@ -59,35 +127,124 @@ func NewServer(cfg *config.BaseConfig, logger logging.Logger, options *ServerOpt
 		options = DefaultServerOptions()
 	}
 
-	// Устанавливаем режим работы Gin
-	if cfg.Env == "production" {
-		gin.SetMode(gin.ReleaseMode)
+	// Логируем отключенные функции библиотеки
+	killswitch.Init(metrics.Register(nil, killswitch.NewGauge(cfg.ServicePrefix)), logger)
+
+	// Переключаем уровень логирования при изменении конфигурации
+	if options.ConfigWatcher != nil {
+		if leveled, ok := logger.(interface{ SetLevel(level string) error }); ok {
+			options.ConfigWatcher.OnChange(func(field string, _, value interface{}) {
+				if field != "LogLevel" {
+					return
+				}
+				if err := leveled.SetLevel(value.(string)); err != nil {
+					logger.Warn("Failed to switch log level: %v", err)
+				}
+			})
+		}
 	}
 
+	// Устанавливаем режим работы Gin
+	configureGinMode(options.GinMode, cfg.Env)
+
 	// Создаем экземпляр роутера
 	router := gin.New()
 
 	// Настраиваем middleware
 	router.Use(gin.Recovery())
-	router.Use(middleware.LoggerWithSkipPaths(logger, options.SkipLogPaths))
+	router.Use(middleware.LoggerWithOptions(logger, accessLogOptions(options)))
 	router.Use(middleware.RequestID())
+	router.Use(tracing.GinMiddleware())
+
+	// Метки pprof для профилей CPU (config.ProfilingLabels)
+	profiling.Enable(cfg.ProfilingLabels)
+	router.Use(profiling.GinMiddleware())
+
+	// Ограничиваем размер тела и время обработки запроса
+	if options.MaxBodyBytes > 0 {
+		router.Use(middleware.BodyLimit(options.MaxBodyBytes))
+	}
+	if options.HandlerTimeout > 0 {
+		router.Use(middleware.Timeout(options.HandlerTimeout))
+	}
 
 	// Добавляем middleware для метрик
 	if options.EnableMetrics {
+		metrics.EnableExemplars(cfg.MetricsExemplars)
 		router.Use(metrics.MetricsMiddleware())
 	}
 
+	// Инициализируем бюджет исходящих запросов
+	if cfg.RequestBudgetMaxRequests > 0 {
+		router.Use(budget.GinMiddleware(&budget.Config{
+			MaxRequests: cfg.RequestBudgetMaxRequests,
+			MaxRetries:  cfg.RequestBudgetMaxRetries,
+		}))
+	}
+
+	// Добавляем ограничение частоты запросов
+	if options.EnableRateLimit {
+		if options.RedisClient == nil {
+			logger.Warn("Rate limiting enabled but Redis client not supplied, rate limiting disabled")
+		} else {
+			rateLimits := middleware.NewRateLimitLimits(cfg.RateLimitRequests, cfg.RateLimitInterval)
+			if options.ConfigWatcher != nil {
+				options.ConfigWatcher.OnChange(func(field string, _, _ interface{}) {
+					if field == "RateLimitRequests" || field == "RateLimitInterval" {
+						current := options.ConfigWatcher.Current()
+						rateLimits.Set(current.RateLimitRequests, current.RateLimitInterval)
+					}
+				})
+			}
+
+			router.Use(middleware.RateLimit(options.RedisClient, middleware.RateLimitConfig{
+				Limits:        rateLimits,
+				KeyHeader:     options.RateLimitKeyHeader,
+				KeyPrefix:     cfg.ServicePrefix + ":ratelimit",
+				ServicePrefix: cfg.ServicePrefix,
+				SkipPaths:     options.SkipLogPaths,
+				Logger:        logger,
+			}))
+		}
+	}
+
+	// Добавляем заголовки безопасности
+	if options.EnableSecurityHeaders {
+		router.Use(middleware.SecurityHeaders(options.SecurityHeaders))
+	}
+
 	// Настраиваем CORS
 	if options.EnableCORS {
-		router.Use(cors.New(cors.Config{
-			AllowOrigins:     cfg.CorsOrigins,
-			AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-			AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "X-Internal-API-Key"},
-			ExposeHeaders:    []string{"Content-Length"},
-			AllowCredentials: true,
-			MaxAge:           12 * time.Hour,
-		}))
+		corsOptions := options.CORS
+		if corsOptions == nil {
+			corsOptions = middleware.DefaultCORSOptions(cfg.CorsOrigins)
+		}
+
+		corsHandler, err := middleware.NewCORS(corsOptions)
+		if err != nil {
+			logger.Error("Invalid CORS configuration, CORS disabled: %v", err)
+		} else {
+			router.Use(corsHandler.Handler())
+
+			// Источники из конфигурации применяются без перезапуска
+			if options.ConfigWatcher != nil && options.CORS == nil {
+				options.ConfigWatcher.OnChange(func(field string, _, value interface{}) {
+					if field != "CorsOrigins" {
+						return
+					}
+					if err := corsHandler.Update(middleware.DefaultCORSOptions(value.([]string))); err != nil {
+						logger.Warn("Failed to update CORS origins: %v", err)
+					}
+				})
+			}
+		}
+	}
+
+	// Загружаем HTML шаблоны и раздачу статических файлов
+	if err := loadTemplates(router, options, cfg.Env); err != nil {
+		logger.Error("Failed to load HTML templates: %v", err)
 	}
+	registerStatic(router, options, cfg.Env)
 
 	// Настраиваем доверенные прокси
 	if len(options.TrustedProxies) > 0 {
@@ -110,19 +267,44 @@ func NewServer(cfg *config.BaseConfig, logger logging.Logger, options *ServerOpt
 
 	// Добавляем эндпоинт метрик
 	if options.EnableMetrics {
-		router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+
+	// Добавляем служебные эндпоинты
+	if options.EnableAdmin {
+		killswitch.RegisterHandlers(router)
 	}
 
 	// Добавляем эндпоинты для проверки здоровья
 	if options.EnableHealth {
-		server.healthCheck = health.NewChecker(cfg.ServiceName, cfg.ServicePrefix, cfg.Version)
+		checkerOptions := health.DefaultCheckerOptions()
+		checkerOptions.StartNotReady = options.StartNotReady
+		server.healthCheck = health.NewCheckerWithOptions(cfg.ServiceName, cfg.ServicePrefix, cfg.Version, checkerOptions)
 		healthHandler := health.NewHTTPHandler(server.healthCheck)
 		healthHandler.RegisterHandlers(router)
 	}
 
+	// События жизненного цикла публикуются после первой успешной проверки здоровья
+	if cfg.LifecycleEvents && options.LifecyclePublisher != nil && server.healthCheck != nil {
+		server.announcer = lifecycle.NewAnnouncer(options.LifecyclePublisher, cfg, logger, nil)
+	}
+
 	return server
 }
 
+// accessLogOptions возвращает настройки логирования запросов с путями SkipLogPaths по умолчанию
+func accessLogOptions(options *ServerOptions) *middleware.LoggerOptions {
+	if options.AccessLog == nil {
+		return &middleware.LoggerOptions{SkipPaths: options.SkipLogPaths}
+	}
+
+	accessLog := *options.AccessLog
+	if len(accessLog.SkipPaths) == 0 {
+		accessLog.SkipPaths = options.SkipLogPaths
+	}
+	return &accessLog
+}
+
 // Router возвращает экземпляр Gin роутера
 func (s *Server) Router() *gin.Engine {
 	return s.router
@@ -144,6 +326,12 @@ func (s *Server) RegisterHealthComponent(component health.Component) {
 func (s *Server) Start() error {
 	s.logger.Info("Starting HTTP server on port %s", s.cfg.Port)
 
+	if s.announcer != nil {
+		var ctx context.Context
+		ctx, s.announcerCancel = context.WithCancel(context.Background())
+		s.announcer.AnnounceWhenReady(ctx, s.healthCheck)
+	}
+
 	// Запускаем сервер
 	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("failed to start HTTP server: %v", err)
@@ -172,6 +360,19 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		defer cancel()
 	}
 
+	// Снимаем готовность, чтобы балансировщик перестал направлять новые запросы
+	if s.healthCheck != nil {
+		s.healthCheck.SetReady(false)
+	}
+
+	// Сообщаем об остановке до того, как перестанем принимать запросы
+	if s.announcer != nil {
+		if s.announcerCancel != nil {
+			s.announcerCancel()
+		}
+		s.announcer.Stopping(ctx)
+	}
+
 	// Останавливаем HTTP сервер
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		return fmt.Errorf("HTTP server shutdown failed: %v", err)
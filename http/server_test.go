@@ -0,0 +1,52 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/config"
+	"github.com/vladzorgan/common/logging"
+)
+
+func TestServerOptionsLimits(t *testing.T) {
+	cfg := &config.BaseConfig{ServicePrefix: "server_limits_test", Port: "0"}
+	server := NewServer(cfg, logging.NewLogger(), &ServerOptions{
+		GinMode:        gin.TestMode,
+		MaxBodyBytes:   4,
+		HandlerTimeout: 20 * time.Millisecond,
+	})
+
+	server.POST("/upload", func(c *gin.Context) {
+		if _, err := io.ReadAll(c.Request.Body); err == nil {
+			c.Status(http.StatusNoContent)
+		}
+	})
+	server.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{name: "small body", req: httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("ok")), want: http.StatusNoContent},
+		{name: "large body", req: httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("too large")), want: http.StatusRequestEntityTooLarge},
+		{name: "slow handler", req: httptest.NewRequest(http.MethodGet, "/slow", nil), want: http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			server.Router().ServeHTTP(recorder, tt.req)
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package http
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// developmentEnv окружение, в котором шаблоны перечитываются при каждом рендеринге,
+// а статические файлы не кешируются браузером
+const developmentEnv = "development"
+
+// configureGinMode устанавливает режим Gin: явно заданный в опциях или выведенный из окружения
+func configureGinMode(mode, env string) {
+	switch {
+	case mode != "":
+		gin.SetMode(mode)
+	case env == "production":
+		gin.SetMode(gin.ReleaseMode)
+	case env == "test":
+		gin.SetMode(gin.TestMode)
+	}
+}
+
+// registerStatic регистрирует раздачу статических файлов из каталогов и fs.FS
+func registerStatic(router gin.IRoutes, options *ServerOptions, env string) {
+	cacheControl := staticCacheControl(options.StaticMaxAge, env)
+
+	for prefix, dir := range options.StaticDirs {
+		serveStatic(router, prefix, http.Dir(dir), cacheControl)
+	}
+	for prefix, fsys := range options.StaticFS {
+		serveStatic(router, prefix, http.FS(fsys), cacheControl)
+	}
+}
+
+// staticCacheControl возвращает значение Cache-Control для статических файлов
+func staticCacheControl(maxAge time.Duration, env string) string {
+	if env == developmentEnv || maxAge <= 0 {
+		return "no-cache"
+	}
+	return fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+}
+
+// serveStatic раздает файлы fileSystem по префиксу URL. Каталоги без index.html не листаются.
+func serveStatic(router gin.IRoutes, prefix string, fileSystem http.FileSystem, cacheControl string) {
+	prefix = "/" + strings.Trim(prefix, "/")
+	fileServer := http.StripPrefix(prefix, http.FileServer(noListingFileSystem{fileSystem}))
+
+	handler := func(c *gin.Context) {
+		fileServer.ServeHTTP(&cacheHeaderWriter{ResponseWriter: c.Writer, cacheControl: cacheControl}, c.Request)
+	}
+
+	pattern := path.Join(prefix, "/*filepath")
+	router.GET(pattern, handler)
+	router.HEAD(pattern, handler)
+}
+
+// noListingFileSystem запрещает листинг каталогов без index.html
+type noListingFileSystem struct {
+	http.FileSystem
+}
+
+func (fs noListingFileSystem) Open(name string) (http.File, error) {
+	file, err := fs.FileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if info.IsDir() {
+		index, err := fs.FileSystem.Open(path.Join(name, "index.html"))
+		if err != nil {
+			file.Close()
+			return nil, os.ErrNotExist
+		}
+		index.Close()
+	}
+
+	return file, nil
+}
+
+// cacheHeaderWriter добавляет Cache-Control к успешным ответам; ошибки не кешируются
+type cacheHeaderWriter struct {
+	http.ResponseWriter
+	cacheControl string
+	wroteHeader  bool
+}
+
+func (w *cacheHeaderWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if code < http.StatusBadRequest {
+			w.Header().Set("Cache-Control", w.cacheControl)
+		} else {
+			w.Header().Set("Cache-Control", "no-store")
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *cacheHeaderWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// loadTemplates загружает HTML шаблоны из каталога (TemplatesGlob) или fs.FS (TemplatesFS).
+// В окружении development шаблоны с диска перечитываются при каждом рендеринге.
+func loadTemplates(router *gin.Engine, options *ServerOptions, env string) error {
+	switch {
+	case options.TemplatesFS != nil:
+		patterns := options.TemplatesPatterns
+		if len(patterns) == 0 {
+			patterns = []string{"*.html"}
+		}
+
+		tmpl, err := template.New("").Funcs(options.TemplateFuncs).ParseFS(options.TemplatesFS, patterns...)
+		if err != nil {
+			return fmt.Errorf("failed to parse embedded templates: %v", err)
+		}
+		router.SetHTMLTemplate(tmpl)
+
+	case options.TemplatesGlob != "":
+		if env == developmentEnv {
+			router.HTMLRender = render.HTMLDebug{
+				Glob:    options.TemplatesGlob,
+				FuncMap: options.TemplateFuncs,
+				Delims:  render.Delims{Left: "{{", Right: "}}"},
+			}
+			return nil
+		}
+
+		tmpl, err := template.New("").Funcs(options.TemplateFuncs).ParseGlob(options.TemplatesGlob)
+		if err != nil {
+			return fmt.Errorf("failed to parse templates %s: %v", options.TemplatesGlob, err)
+		}
+		router.SetHTMLTemplate(tmpl)
+	}
+
+	return nil
+}
+
+// errorPageTemplate страница ошибки для браузерных маршрутов
+var errorPageTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{.Status}} {{.Error}}</title>
+</head>
+<body>
+<h1>{{.Status}} {{.Error}}</h1>
+<p>{{.Message}}</p>
+{{- if .Blockers}}
+<ul>
+{{- range $id, $reasons := .Blockers}}{{range $reasons}}
+<li>{{$id}}: {{.}}</li>
+{{- end}}{{end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
+
+// RespondErrorPage прерывает запрос HTML страницей с теми же полями, что и RespondError
+func RespondErrorPage(c *gin.Context, err error) {
+	status, body := errorBody(err)
+
+	data := struct {
+		Status   int
+		Error    string
+		Message  string
+		Blockers map[uint][]string
+	}{
+		Status:  status,
+		Error:   body["error"].(string),
+		Message: body["message"].(string),
+	}
+	if blockers, ok := body["blockers"].(map[uint][]string); ok {
+		data.Blockers = blockers
+	}
+
+	c.Abort()
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	if err := errorPageTemplate.Execute(c.Writer, data); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// RespondErrorNegotiated отвечает HTML страницей ошибки, если клиент предпочитает text/html
+// (браузер), и JSON конвертом RespondError в остальных случаях
+func RespondErrorNegotiated(c *gin.Context, err error) {
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+		RespondErrorPage(c, err)
+		return
+	}
+	RespondError(c, err)
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/vladzorgan/common/errors"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestRespondErrorNegotiated(t *testing.T) {
+	router := gin.New()
+	router.GET("/callback", func(c *gin.Context) {
+		RespondErrorNegotiated(c, apperrors.NotFound("payment", 7))
+	})
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+	}{
+		{"browser", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "text/html; charset=utf-8"},
+		{"api client", "application/json", "application/json; charset=utf-8"},
+		{"no accept header", "", "application/json; charset=utf-8"},
+		{"any", "*/*", "application/json; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/callback", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("status = %d, want 404", rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+		})
+	}
+}
+
+func TestRespondErrorPageEscapesMessage(t *testing.T) {
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		RespondErrorPage(c, apperrors.Validation("payment", errors.New("<script>alert(1)</script>")))
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if strings.Contains(rec.Body.String(), "<script>") {
+		t.Error("error message is not escaped")
+	}
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", rec.Header().Get("Cache-Control"))
+	}
+}
+
+func TestStaticCacheHeaders(t *testing.T) {
+	files := fstest.MapFS{
+		"app.css":         {Data: []byte("body{}")},
+		"img/logo.svg":    {Data: []byte("<svg/>")},
+		"docs/index.html": {Data: []byte("<h1>docs</h1>")},
+		"docs/guide.html": {Data: []byte("<h1>guide</h1>")},
+	}
+
+	tests := []struct {
+		name         string
+		env          string
+		path         string
+		status       int
+		cacheControl string
+	}{
+		{"file", "production", "/static/app.css", http.StatusOK, "public, max-age=3600"},
+		{"nested file", "production", "/static/img/logo.svg", http.StatusOK, "public, max-age=3600"},
+		{"missing file", "production", "/static/missing.js", http.StatusNotFound, "no-store"},
+		{"directory without index", "production", "/static/img/", http.StatusNotFound, "no-store"},
+		{"directory with index", "production", "/static/docs/", http.StatusOK, "public, max-age=3600"},
+		{"development", "development", "/static/app.css", http.StatusOK, "no-cache"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			registerStatic(router, &ServerOptions{
+				StaticFS:     map[string]fs.FS{"/static": files},
+				StaticMaxAge: time.Hour,
+			}, tt.env)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != tt.cacheControl {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.cacheControl)
+			}
+		})
+	}
+}
+
+func TestStaticDirs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "robots.txt"), []byte("User-agent: *"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	registerStatic(router, &ServerOptions{StaticDirs: map[string]string{"public": dir}, StaticMaxAge: time.Minute}, "production")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/public/robots.txt", nil))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "User-agent: *" {
+		t.Fatalf("response = %d %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "public, max-age=60" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+}
+
+func TestLoadEmbeddedTemplates(t *testing.T) {
+	router := gin.New()
+	err := loadTemplates(router, &ServerOptions{
+		TemplatesFS: fstest.MapFS{"redirect.html": {Data: []byte(`<a href="{{.URL}}">continue</a>`)}},
+	}, "production")
+	if err != nil {
+		t.Fatalf("loadTemplates() error = %v", err)
+	}
+
+	router.GET("/redirect", func(c *gin.Context) {
+		c.HTML(http.StatusOK, "redirect.html", gin.H{"URL": "https://pay.example.com"})
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/redirect", nil))
+
+	if !strings.Contains(rec.Body.String(), `href="https://pay.example.com"`) {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,304 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// strictJSONContextKey - ключ gin.Context, включающий строгий разбор JSON для маршрута
+const strictJSONContextKey = "StrictJSON"
+
+// jsonUnmarshalerType используется для пропуска проверки типов с собственным разбором JSON
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// JSONFieldError описывает ошибку в конкретном поле JSON документа
+type JSONFieldError struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// StrictJSONError содержит ошибки строгого разбора JSON
+type StrictJSONError struct {
+	Errors []JSONFieldError
+}
+
+// Error возвращает описание ошибки
+func (e *StrictJSONError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fieldErr.Path+": "+fieldErr.Reason)
+	}
+	return "invalid JSON payload: " + strings.Join(messages, "; ")
+}
+
+// DecodeStrictJSON разбирает JSON, отклоняя неизвестные поля и повторяющиеся ключи на любом уровне вложенности.
+// Ключи полей структур сравниваются без учета регистра, как это делает encoding/json,
+// поэтому "amount" и "Amount" в одном объекте также считаются дубликатами.
+func DecodeStrictJSON(data []byte, v interface{}) error {
+	var fieldErrs []JSONFieldError
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := scanJSON(dec, "$", reflect.TypeOf(v), &fieldErrs); err != nil {
+		return &StrictJSONError{Errors: []JSONFieldError{{Path: "$", Reason: err.Error()}}}
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return &StrictJSONError{Errors: []JSONFieldError{{Path: "$", Reason: "unexpected data after JSON value"}}}
+	}
+
+	if len(fieldErrs) > 0 {
+		return &StrictJSONError{Errors: fieldErrs}
+	}
+
+	dec = json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return &StrictJSONError{Errors: []JSONFieldError{{Path: "$", Reason: err.Error()}}}
+	}
+
+	return nil
+}
+
+// StrictProtoJSONUnmarshalOptions возвращает опции protojson, отклоняющие неизвестные поля
+func StrictProtoJSONUnmarshalOptions() protojson.UnmarshalOptions {
+	return protojson.UnmarshalOptions{DiscardUnknown: false}
+}
+
+// UnmarshalStrictProtoJSON разбирает JSON в proto сообщение с той же защитой, что и DecodeStrictJSON.
+// Используется на пути gRPC-шлюза.
+func UnmarshalStrictProtoJSON(data []byte, m proto.Message) error {
+	var fieldErrs []JSONFieldError
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := scanJSON(dec, "$", nil, &fieldErrs); err != nil {
+		return &StrictJSONError{Errors: []JSONFieldError{{Path: "$", Reason: err.Error()}}}
+	}
+
+	if len(fieldErrs) > 0 {
+		return &StrictJSONError{Errors: fieldErrs}
+	}
+
+	if err := StrictProtoJSONUnmarshalOptions().Unmarshal(data, m); err != nil {
+		return &StrictJSONError{Errors: []JSONFieldError{{Path: "$", Reason: err.Error()}}}
+	}
+
+	return nil
+}
+
+// StrictJSON возвращает middleware, включающее строгий разбор JSON для маршрута.
+// Повторяющиеся ключи отклоняются сразу, а BindJSON в обработчике дополнительно отклоняет неизвестные поля.
+func StrictJSON() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(strictJSONContextKey, true)
+
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortStrictJSON(c, &StrictJSONError{Errors: []JSONFieldError{{Path: "$", Reason: "failed to read request body"}}})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if len(bytes.TrimSpace(body)) > 0 {
+			var fieldErrs []JSONFieldError
+			dec := json.NewDecoder(bytes.NewReader(body))
+			if err := scanJSON(dec, "$", nil, &fieldErrs); err != nil {
+				fieldErrs = append(fieldErrs, JSONFieldError{Path: "$", Reason: err.Error()})
+			}
+			if len(fieldErrs) > 0 {
+				abortStrictJSON(c, &StrictJSONError{Errors: fieldErrs})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// BindJSON разбирает тело запроса в obj. Для маршрутов со StrictJSON используется DecodeStrictJSON.
+// При ошибке строгого разбора запрос прерывается с кодом 422; возвращает false, если разбор не удался.
+func BindJSON(c *gin.Context, obj interface{}) bool {
+	if !c.GetBool(strictJSONContextKey) {
+		if err := c.ShouldBindJSON(obj); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":   "Bad Request",
+				"message": err.Error(),
+			})
+			return false
+		}
+		return true
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		abortStrictJSON(c, &StrictJSONError{Errors: []JSONFieldError{{Path: "$", Reason: "failed to read request body"}}})
+		return false
+	}
+
+	if err := DecodeStrictJSON(body, obj); err != nil {
+		var strictErr *StrictJSONError
+		if !errors.As(err, &strictErr) {
+			strictErr = &StrictJSONError{Errors: []JSONFieldError{{Path: "$", Reason: err.Error()}}}
+		}
+		abortStrictJSON(c, strictErr)
+		return false
+	}
+
+	return true
+}
+
+// abortStrictJSON прерывает запрос с кодом 422 и списком ошибок
+func abortStrictJSON(c *gin.Context, err *StrictJSONError) {
+	c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+		"error":   "Unprocessable Entity",
+		"message": "Invalid JSON payload",
+		"details": err.Errors,
+	})
+}
+
+// scanJSON обходит JSON значение, собирая повторяющиеся ключи и поля, отсутствующие в типе t.
+// Если t равен nil, проверка неизвестных полей не выполняется.
+func scanJSON(dec *json.Decoder, path string, t reflect.Type, fieldErrs *[]JSONFieldError) error {
+	t = indirectType(t)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil
+	}
+
+	switch delim {
+	case '{':
+		var fields map[string]reflect.Type
+		if t != nil && t.Kind() == reflect.Struct {
+			fields = structJSONFields(t)
+		}
+
+		seen := make(map[string]bool)
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key := keyTok.(string)
+			childPath := path + "." + key
+
+			// Поля структур сопоставляются без учета регистра
+			seenKey := key
+			if fields != nil {
+				seenKey = strings.ToLower(key)
+			}
+			if seen[seenKey] {
+				*fieldErrs = append(*fieldErrs, JSONFieldError{Path: childPath, Reason: "duplicate key"})
+			}
+			seen[seenKey] = true
+
+			var childType reflect.Type
+			switch {
+			case fields != nil:
+				fieldType, ok := fields[strings.ToLower(key)]
+				if !ok {
+					*fieldErrs = append(*fieldErrs, JSONFieldError{Path: childPath, Reason: "unknown field"})
+				}
+				childType = fieldType
+			case t != nil && t.Kind() == reflect.Map:
+				childType = t.Elem()
+			}
+
+			if err := scanJSON(dec, childPath, childType, fieldErrs); err != nil {
+				return err
+			}
+		}
+
+	case '[':
+		var elemType reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elemType = t.Elem()
+		}
+
+		for i := 0; dec.More(); i++ {
+			if err := scanJSON(dec, fmt.Sprintf("%s[%d]", path, i), elemType, fieldErrs); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Закрывающая скобка объекта или массива
+	_, err = dec.Token()
+	return err
+}
+
+// indirectType снимает указатели и возвращает nil для типов с собственным разбором JSON
+func indirectType(t reflect.Type) reflect.Type {
+	for t != nil {
+		if t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+			return nil
+		}
+		if t.Kind() != reflect.Pointer {
+			return t
+		}
+		t = t.Elem()
+	}
+	return nil
+}
+
+// structJSONFields возвращает поля структуры по JSON именам в нижнем регистре, включая встроенные структуры
+func structJSONFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		// Поля встроенных структур без имени в теге поднимаются на уровень выше
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for embeddedName, embeddedType := range structJSONFields(embedded) {
+					if _, exists := fields[embeddedName]; !exists {
+						fields[embeddedName] = embeddedType
+					}
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+
+	return fields
+}
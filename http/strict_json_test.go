@@ -0,0 +1,255 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	location "github.com/vladzorgan/common/proto/location"
+)
+
+type strictOrderItem struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+type strictOrder struct {
+	ID       string            `json:"id"`
+	Amount   float64           `json:"amount"`
+	Items    []strictOrderItem `json:"items"`
+	Customer *struct {
+		Name string `json:"name"`
+	} `json:"customer"`
+	Meta map[string]string `json:"meta"`
+}
+
+// strictErrorPaths возвращает пути ошибок строгого разбора
+func strictErrorPaths(t *testing.T, err error) []string {
+	t.Helper()
+
+	var strictErr *StrictJSONError
+	if !errors.As(err, &strictErr) {
+		t.Fatalf("error = %v, want *StrictJSONError", err)
+	}
+	paths := make([]string, 0, len(strictErr.Errors))
+	for _, fieldErr := range strictErr.Errors {
+		paths = append(paths, fieldErr.Path+" "+fieldErr.Reason)
+	}
+	return paths
+}
+
+func TestDecodeStrictJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "valid", input: `{"id":"1","amount":10,"items":[{"sku":"a","quantity":1}],"meta":{"k":"v"}}`},
+		{name: "top level duplicate", input: `{"amount":1,"amount":1000}`, want: []string{"$.amount duplicate key"}},
+		{name: "case insensitive duplicate", input: `{"amount":1,"Amount":1000}`, want: []string{"$.Amount duplicate key"}},
+		{name: "nested duplicate", input: `{"customer":{"name":"a","name":"b"}}`, want: []string{"$.customer.name duplicate key"}},
+		{
+			name:  "duplicate in array of objects",
+			input: `{"items":[{"sku":"a"},{"sku":"b","quantity":1,"sku":"c"}]}`,
+			want:  []string{"$.items[1].sku duplicate key"},
+		},
+		{name: "map keys are case sensitive", input: `{"meta":{"k":"a","K":"b"}}`},
+		{name: "map duplicate", input: `{"meta":{"k":"a","k":"b"}}`, want: []string{"$.meta.k duplicate key"}},
+		{name: "unknown field", input: `{"items":[{"sku":"a","price":1}]}`, want: []string{"$.items[0].price unknown field"}},
+		{name: "trailing data", input: `{"id":"1"} {"id":"2"}`, want: []string{"$ unexpected data after JSON value"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var order strictOrder
+			err := DecodeStrictJSON([]byte(tt.input), &order)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("DecodeStrictJSON() error = %v", err)
+				}
+				return
+			}
+
+			got := strictErrorPaths(t, err)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("errors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// randomStrictDocument строит случайный вложенный документ strictOrder и возвращает его вместе
+// с путем ключа, продублированного в одном из объектов
+func randomStrictDocument(rng *rand.Rand) (string, string) {
+	items := make([]string, 1+rng.Intn(5))
+	target := rng.Intn(len(items) + 2)
+
+	for i := range items {
+		fields := []string{`"sku":"s` + fmt.Sprint(i) + `"`, `"quantity":` + fmt.Sprint(rng.Intn(100))}
+		if i == target {
+			fields = append(fields, `"sku":"dup"`)
+		}
+		rng.Shuffle(len(fields), func(a, b int) { fields[a], fields[b] = fields[b], fields[a] })
+		items[i] = "{" + strings.Join(fields, ",") + "}"
+	}
+
+	customer := `{"name":"c"}`
+	fields := []string{`"id":"o"`, `"amount":1`, `"items":[` + strings.Join(items, ",") + `]`}
+
+	var path string
+	switch {
+	case target < len(items):
+		path = fmt.Sprintf("$.items[%d].sku", target)
+	case target == len(items):
+		customer = `{"name":"c","NAME":"dup"}`
+		path = "$.customer.NAME"
+	default:
+		fields = append(fields, `"AMOUNT":2`)
+		path = "$.AMOUNT"
+	}
+	fields = append(fields, `"customer":`+customer)
+
+	// Дубликат верхнего уровня должен идти после оригинала, чтобы путь указывал на повтор
+	if target <= len(items) {
+		rng.Shuffle(len(fields), func(a, b int) { fields[a], fields[b] = fields[b], fields[a] })
+	}
+	return "{" + strings.Join(fields, ",") + "}", path
+}
+
+func TestDecodeStrictJSONRandomDuplicates(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 500; i++ {
+		doc, path := randomStrictDocument(rng)
+
+		var order strictOrder
+		err := DecodeStrictJSON([]byte(doc), &order)
+		if err == nil {
+			t.Fatalf("DecodeStrictJSON(%s) error = nil, want duplicate at %s", doc, path)
+		}
+
+		got := strictErrorPaths(t, err)
+		if len(got) != 1 || got[0] != path+" duplicate key" {
+			t.Fatalf("DecodeStrictJSON(%s) errors = %v, want [%s duplicate key]", doc, got, path)
+		}
+	}
+}
+
+func FuzzDecodeStrictJSON(f *testing.F) {
+	f.Add(`{"id":"1","items":[{"sku":"a","quantity":1}]}`)
+	f.Add(`{"items":[{"sku":"a"},{"sku":"b","sku":"c"}]}`)
+	f.Add(`{"customer":{"name":"a","Name":"b"}}`)
+	f.Add(`{"meta":{"k":"a","k":"b"}}`)
+	f.Add(`[{"a":1,"a":2}]`)
+
+	f.Fuzz(func(t *testing.T, input string) {
+		var order strictOrder
+		if err := DecodeStrictJSON([]byte(input), &order); err != nil {
+			var strictErr *StrictJSONError
+			if !errors.As(err, &strictErr) || len(strictErr.Errors) == 0 {
+				t.Fatalf("DecodeStrictJSON(%q) error = %v, want *StrictJSONError with details", input, err)
+			}
+			for _, fieldErr := range strictErr.Errors {
+				if !strings.HasPrefix(fieldErr.Path, "$") {
+					t.Fatalf("DecodeStrictJSON(%q) path = %q, want prefix $", input, fieldErr.Path)
+				}
+			}
+			return
+		}
+
+		// Принятый документ разбирается и стандартным декодером
+		var plain strictOrder
+		if err := json.Unmarshal([]byte(input), &plain); err != nil {
+			t.Fatalf("DecodeStrictJSON(%q) accepted invalid JSON: %v", input, err)
+		}
+	})
+}
+
+func TestStrictJSONMiddleware(t *testing.T) {
+	router := gin.New()
+	handler := func(c *gin.Context) {
+		var order strictOrder
+		if !BindJSON(c, &order) {
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"amount": order.Amount})
+	}
+	router.POST("/strict", StrictJSON(), handler)
+	router.POST("/lenient", handler)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		detail string
+	}{
+		{name: "strict valid", path: "/strict", body: `{"amount":1}`, status: http.StatusOK},
+		{name: "strict duplicate", path: "/strict", body: `{"items":[{"sku":"a","sku":"b"}]}`, status: http.StatusUnprocessableEntity, detail: "$.items[0].sku"},
+		{name: "strict unknown field", path: "/strict", body: `{"amount":1,"discount":5}`, status: http.StatusUnprocessableEntity, detail: "$.discount"},
+		{name: "lenient duplicate", path: "/lenient", body: `{"amount":1,"amount":1000}`, status: http.StatusOK},
+		{name: "lenient malformed", path: "/lenient", body: `{"amount":`, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body = %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.detail == "" {
+				return
+			}
+
+			var body struct {
+				Details []JSONFieldError `json:"details"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if len(body.Details) != 1 || body.Details[0].Path != tt.detail {
+				t.Errorf("details = %+v, want path %s", body.Details, tt.detail)
+			}
+		})
+	}
+}
+
+func TestUnmarshalStrictProtoJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		path  string
+	}{
+		{name: "valid", input: `{"name":"Moscow","code":"77"}`},
+		{name: "duplicate", input: `{"name":"Moscow","name":"Tver"}`, path: "$.name"},
+		{name: "unknown field", input: `{"name":"Moscow","population":1}`, path: "$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req location.CreateRegionRequest
+			err := UnmarshalStrictProtoJSON([]byte(tt.input), &req)
+			if tt.path == "" {
+				if err != nil || req.GetName() != "Moscow" {
+					t.Fatalf("UnmarshalStrictProtoJSON() name = %q, error = %v", req.GetName(), err)
+				}
+				return
+			}
+
+			var strictErr *StrictJSONError
+			if !errors.As(err, &strictErr) || strictErr.Errors[0].Path != tt.path {
+				t.Errorf("UnmarshalStrictProtoJSON() error = %v, want path %s", err, tt.path)
+			}
+		})
+	}
+}
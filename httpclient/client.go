@@ -0,0 +1,369 @@
+// Package httpclient предоставляет HTTP клиент для внешних сервисов с таймаутами,
+// повторными попытками, передачей request ID и метриками Prometheus
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/budget"
+	"github.com/vladzorgan/common/killswitch"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/metrics"
+	"github.com/vladzorgan/common/retry"
+)
+
+// RequestIDHeader заголовок, в котором передается request ID (совпадает с middleware.RequestIDHeader)
+const RequestIDHeader = "X-Request-ID"
+
+// maxErrorBodyBytes ограничивает размер тела ответа, сохраняемого в StatusError
+const maxErrorBodyBytes = 4 << 10
+
+// StatusError возвращается JSON методами, если сервис ответил кодом вне диапазона 2xx
+type StatusError struct {
+	StatusCode int
+	// Начало тела ответа для диагностики
+	Body []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, strings.TrimSpace(string(e.Body)))
+}
+
+// Option настраивает Client
+type Option func(*options)
+
+// options содержит настройки Client
+type options struct {
+	httpClient    *http.Client
+	timeout       time.Duration
+	maxRetries    int
+	backoff       time.Duration
+	maxBackoff    time.Duration
+	retryUnsafe   bool
+	headers       http.Header
+	registerer    prometheus.Registerer
+	servicePrefix string
+	logger        logging.Logger
+}
+
+// WithTimeout задает таймаут одной попытки запроса (по умолчанию 10 секунд)
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithRetry задает количество повторных попыток и экспоненциальную задержку между ними.
+// Повторяются ответы 5xx и ошибки соединения идемпотентных запросов (см. WithRetryNonIdempotent).
+func WithRetry(maxRetries int, backoff, maxBackoff time.Duration) Option {
+	return func(o *options) {
+		o.maxRetries = maxRetries
+		o.backoff = backoff
+		o.maxBackoff = maxBackoff
+	}
+}
+
+// WithRetryNonIdempotent включает повторы запросов POST и PATCH без заголовка Idempotency-Key.
+// Подходит только для сервисов, где повторное выполнение такого запроса безопасно.
+func WithRetryNonIdempotent() Option {
+	return func(o *options) {
+		o.retryUnsafe = true
+	}
+}
+
+// WithHTTPClient задает http.Client (например, с собственным Transport)
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithHeader добавляет заголовок во все запросы клиента
+func WithHeader(key, value string) Option {
+	return func(o *options) {
+		o.headers.Set(key, value)
+	}
+}
+
+// WithRegisterer задает Registerer для метрик (по умолчанию prometheus.DefaultRegisterer)
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(o *options) {
+		o.registerer = registerer
+	}
+}
+
+// WithServicePrefix задает префикс имен метрик
+func WithServicePrefix(prefix string) Option {
+	return func(o *options) {
+		o.servicePrefix = prefix
+	}
+}
+
+// WithLogger задает логгер повторных попыток
+func WithLogger(logger logging.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// Client HTTP клиент внешнего сервиса
+type Client struct {
+	baseURL  string
+	options  *options
+	duration *prometheus.HistogramVec
+	requests *prometheus.CounterVec
+}
+
+// New создает клиент для сервиса с адресом baseURL. Пути запросов дописываются к baseURL;
+// абсолютные URL используются как есть.
+func New(baseURL string, opts ...Option) *Client {
+	o := &options{
+		timeout:    10 * time.Second,
+		maxRetries: 2,
+		backoff:    100 * time.Millisecond,
+		maxBackoff: 2 * time.Second,
+		headers:    make(http.Header),
+		registerer: prometheus.DefaultRegisterer,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.httpClient == nil {
+		o.httpClient = &http.Client{}
+	}
+	if o.logger == nil {
+		o.logger = logging.NewLogger()
+	}
+
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		options: o,
+		duration: metrics.Register(o.registerer, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    metricName(o.servicePrefix, "http_client_request_duration_ms"),
+				Help:    "HTTP client request duration in milliseconds",
+				Buckets: prometheus.ExponentialBuckets(1, 2, 15), // От 1мс до ~16с
+			},
+			[]string{"host", "method"},
+		)),
+		requests: metrics.Register(o.registerer, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: metricName(o.servicePrefix, "http_client_requests_total"),
+				Help: "HTTP client requests by response status",
+			},
+			[]string{"host", "method", "status"},
+		)),
+	}
+}
+
+// NewRequest создает запрос к пути относительно baseURL
+func (c *Client) NewRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, method, c.url(path), body)
+}
+
+// Do выполняет запрос с таймаутом на каждую попытку и повторами при ответах 5xx и ошибках соединения.
+// Повторяются только идемпотентные запросы: GET, HEAD, OPTIONS, TRACE, PUT, DELETE и запросы
+// с заголовком Idempotency-Key; остальные - если задана опция WithRetryNonIdempotent.
+// Запрос с телом повторяется, только если задан req.GetBody (http.NewRequest задает его для
+// bytes.Reader, bytes.Buffer и strings.Reader). Вызывающий код закрывает тело ответа.
+// Повторы отключаются выключателем killswitch.FeatureHTTPClientRetry.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	// Путь может содержать секреты (например, токен бота), поэтому в логи попадает только хост
+	target := req.URL.Host
+	budget.ConsumeRequest(ctx, c.options.logger, target)
+
+	backoff := retry.NewBackoff(c.options.backoff, c.options.maxBackoff)
+	for attempt := 0; ; attempt++ {
+		resp, err := c.attempt(req)
+
+		retryable := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		canRetry := attempt < c.options.maxRetries && ctx.Err() == nil && c.canRetry(req)
+		if !retryable || !canRetry || !budget.AllowRetry(ctx, c.options.logger, target) {
+			return resp, err
+		}
+
+		if err != nil {
+			c.options.logger.WithContext(ctx).Warn("HTTP %s to %s failed, retrying: %v", req.Method, target, redactError(err))
+		} else {
+			c.options.logger.WithContext(ctx).Warn("HTTP %s to %s returned %d, retrying", req.Method, target, resp.StatusCode)
+			// Тело ответа вычитывается, чтобы соединение вернулось в пул
+			io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodyBytes))
+			resp.Body.Close()
+		}
+
+		if err := backoff.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			req.Body = body
+		}
+	}
+}
+
+// canRetry проверяет, можно ли повторить запрос: метод идемпотентен или повторы разрешены явно,
+// а тело можно прочитать заново
+func (c *Client) canRetry(req *http.Request) bool {
+	if killswitch.IsDisabled(killswitch.FeatureHTTPClientRetry) {
+		return false
+	}
+	if !c.options.retryUnsafe && !isIdempotent(req) {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// isIdempotent проверяет, безопасно ли выполнить запрос повторно (по правилам net/http)
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != "" || req.Header.Get("X-Idempotency-Key") != ""
+}
+
+// redactError убирает из ошибки net/http адрес запроса: путь и query могут содержать секреты
+func redactError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// attempt выполняет одну попытку запроса и записывает метрики
+func (c *Client) attempt(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), c.options.timeout)
+	attemptReq := req.Clone(ctx)
+	attemptReq.Body = req.Body
+	c.setHeaders(attemptReq)
+
+	start := time.Now()
+	resp, err := c.options.httpClient.Do(attemptReq)
+	c.duration.WithLabelValues(req.URL.Host, req.Method).Observe(float64(time.Since(start).Milliseconds()))
+
+	if err != nil {
+		cancel()
+		c.requests.WithLabelValues(req.URL.Host, req.Method, "error").Inc()
+		return nil, err
+	}
+
+	c.requests.WithLabelValues(req.URL.Host, req.Method, strconv.Itoa(resp.StatusCode)).Inc()
+	// Таймаут попытки действует до закрытия тела ответа
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// setHeaders добавляет заголовки клиента, request ID и бюджет запроса из контекста
+func (c *Client) setHeaders(req *http.Request) {
+	for key, values := range c.options.headers {
+		if req.Header.Get(key) == "" {
+			req.Header[key] = values
+		}
+	}
+
+	if requestID := logging.ExtractRequestID(req.Context()); requestID != "" && req.Header.Get(RequestIDHeader) == "" {
+		req.Header.Set(RequestIDHeader, requestID)
+	}
+
+	budget.InjectHeader(req.Context(), req)
+}
+
+// GetJSON выполняет GET запрос и декодирует JSON ответ в out (nil - ответ не читается)
+func (c *Client) GetJSON(ctx context.Context, path string, out interface{}) error {
+	return c.DoJSON(ctx, http.MethodGet, path, nil, out)
+}
+
+// PostJSON отправляет in в виде JSON и декодирует JSON ответ в out (nil - ответ не читается)
+func (c *Client) PostJSON(ctx context.Context, path string, in, out interface{}) error {
+	return c.DoJSON(ctx, http.MethodPost, path, in, out)
+}
+
+// DoJSON выполняет запрос с JSON телом in (nil - без тела) и декодирует ответ в out.
+// Ответ вне диапазона 2xx возвращается как *StatusError.
+func (c *Client) DoJSON(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := c.NewRequest(ctx, method, path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return &StatusError{StatusCode: resp.StatusCode, Body: data}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// url возвращает адрес запроса для пути
+func (c *Client) url(path string) string {
+	if parsed, err := url.Parse(path); err == nil && parsed.IsAbs() {
+		return path
+	}
+	if path == "" {
+		return c.baseURL
+	}
+	return c.baseURL + "/" + strings.TrimLeft(path, "/")
+}
+
+// cancelOnClose отменяет контекст попытки при закрытии тела ответа
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// metricName формирует имя метрики с учетом префикса сервиса
+func metricName(servicePrefix, name string) string {
+	if servicePrefix == "" {
+		return name
+	}
+	return servicePrefix + "_" + name
+}
@@ -0,0 +1,187 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/vladzorgan/common/logging"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) (*Client, *prometheus.Registry) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	registry := prometheus.NewRegistry()
+	opts = append([]Option{WithRegisterer(registry), WithRetry(2, time.Millisecond, 5*time.Millisecond)}, opts...)
+	return New(server.URL+"/api/", opts...), registry
+}
+
+func TestPostJSONRetriesServerErrors(t *testing.T) {
+	var calls int32
+	client, registry := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path != "/api/orders" || string(body) != `{"name":"phone"}` {
+			t.Errorf("request = %s %s", r.URL.Path, body)
+		}
+		if r.Header.Get(RequestIDHeader) != "req-1" || r.Header.Get("X-Api-Key") != "secret" {
+			t.Errorf("headers = %v", r.Header)
+		}
+
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]int{"id": 5})
+	}, WithHeader("X-Api-Key", "secret"), WithRetryNonIdempotent())
+
+	ctx := logging.ContextWithRequestID(context.Background(), "req-1")
+	var out struct{ ID int }
+	if err := client.PostJSON(ctx, "orders", map[string]string{"name": "phone"}, &out); err != nil {
+		t.Fatalf("PostJSON() error = %v", err)
+	}
+	if out.ID != 5 || calls != 3 {
+		t.Errorf("out = %+v, calls = %d", out, calls)
+	}
+
+	host, _ := url.Parse(client.baseURL)
+	if got := testutil.ToFloat64(client.requests.WithLabelValues(host.Host, http.MethodPost, "502")); got != 2 {
+		t.Errorf("502 counter = %v, want 2", got)
+	}
+	if count, _ := testutil.GatherAndCount(registry, "http_client_request_duration_ms"); count != 1 {
+		t.Errorf("duration series = %d, want 1", count)
+	}
+}
+
+func TestGetJSONDoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+
+	err := client.GetJSON(context.Background(), "/orders/1", nil)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound || string(statusErr.Body) != "not found\n" {
+		t.Fatalf("GetJSON() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestPerAttemptTimeout(t *testing.T) {
+	var calls int32
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{}`))
+	}, WithTimeout(20*time.Millisecond))
+
+	if err := client.GetJSON(context.Background(), "slow", &struct{}{}); err != nil {
+		t.Fatalf("GetJSON() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestRetriesOnlyIdempotentRequestsByDefault(t *testing.T) {
+	var calls int32
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	tests := []struct {
+		name      string
+		method    string
+		header    string
+		wantCalls int32
+	}{
+		{name: "post", method: http.MethodPost, wantCalls: 1},
+		{name: "patch", method: http.MethodPatch, wantCalls: 1},
+		{name: "post with idempotency key", method: http.MethodPost, header: "Idempotency-Key", wantCalls: 3},
+		{name: "put", method: http.MethodPut, wantCalls: 3},
+		{name: "delete", method: http.MethodDelete, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&calls, 0)
+			req, err := client.NewRequest(context.Background(), tt.method, "orders", strings.NewReader(`{}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.header != "" {
+				req.Header.Set(tt.header, "order-1")
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			resp.Body.Close()
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// warnLogger запоминает отформатированные предупреждения
+type warnLogger struct {
+	logging.Logger
+
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *warnLogger) Warn(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func (l *warnLogger) WithContext(context.Context) logging.Logger { return l }
+
+func TestRetryLogRedactsURL(t *testing.T) {
+	logger := &warnLogger{Logger: logging.NewLogger()}
+	// Сервер закрывает соединение без ответа, поэтому ошибка *url.Error содержит полный адрес
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}, WithLogger(logger), WithRetry(1, time.Millisecond, time.Millisecond))
+
+	err := client.GetJSON(context.Background(), "/bot123:SECRET/getMe?token=SECRET", nil)
+	if err == nil {
+		t.Fatal("GetJSON() error = nil, want connection error")
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.messages) != 1 {
+		t.Fatalf("warnings = %v, want one retry warning", logger.messages)
+	}
+	if message := logger.messages[0]; strings.Contains(message, "SECRET") || !strings.Contains(message, "EOF") {
+		t.Errorf("warning = %q, want the cause without the URL", message)
+	}
+}
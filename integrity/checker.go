@@ -0,0 +1,393 @@
+// Package integrity предоставляет проверку целостности ссылок на сущности других сервисов
+// (например, order.city_id → location-service): поиск «висячих» идентификаторов,
+// оставшихся после удаления связанных сущностей.
+package integrity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/database"
+	"github.com/vladzorgan/common/killswitch"
+	"github.com/vladzorgan/common/logging"
+	events "github.com/vladzorgan/common/messaging/rabbitmq"
+	"github.com/vladzorgan/common/metrics"
+	"gorm.io/gorm/clause"
+)
+
+// BrokenReferenceEventType - тип события о найденных висячих ссылках
+const BrokenReferenceEventType = "reference.broken"
+
+// Reference описывает ссылку локальной сущности на внешнюю
+type Reference struct {
+	// Уникальное имя ссылки, например "order.city_id"
+	Name string
+	// Локальная таблица и колонка со ссылкой
+	Table string
+	Field string
+	// Проверка существования внешних сущностей
+	Resolver Resolver
+}
+
+// CheckerOptions содержит настройки проверки
+type CheckerOptions struct {
+	// Размер пакета при сканировании локальной таблицы и проверке идентификаторов
+	BatchSize int
+	// Пауза между пакетами, чтобы не перегружать внешние сервисы
+	BatchDelay time.Duration
+	// Количество примеров висячих ссылок в отчете
+	SampleSize int
+	// Издатель событий reference.broken (nil - события не публикуются)
+	Publisher events.EventPublisher
+	// Префикс метрик сервиса
+	ServicePrefix string
+}
+
+// DefaultCheckerOptions возвращает опции проверки по умолчанию
+func DefaultCheckerOptions() *CheckerOptions {
+	return &CheckerOptions{
+		BatchSize:  500,
+		BatchDelay: 100 * time.Millisecond,
+		SampleSize: 10,
+	}
+}
+
+// BrokenReference описывает запись с висячей ссылкой
+type BrokenReference struct {
+	ID    uint `json:"id"`
+	RefID uint `json:"ref_id"`
+}
+
+// BrokenReferenceEvent - событие с пакетом висячих ссылок для потребителя-исправителя
+type BrokenReferenceEvent struct {
+	Reference string            `json:"reference"`
+	Table     string            `json:"table"`
+	Field     string            `json:"field"`
+	Rows      []BrokenReference `json:"rows"`
+}
+
+// Report содержит результат проверки одной ссылки
+type Report struct {
+	Reference  string            `json:"reference"`
+	Scanned    int64             `json:"scanned"`
+	Dangling   int64             `json:"dangling"`
+	Samples    []BrokenReference `json:"samples"`
+	StartedAt  time.Time         `json:"started_at"`
+	DurationMs int64             `json:"duration_ms"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// Checker сканирует локальные таблицы и проверяет ссылки на внешние сущности
+type Checker struct {
+	db         *database.Database
+	logger     logging.Logger
+	options    *CheckerOptions
+	references []Reference
+	reports    map[string]*Report
+	mutex      sync.RWMutex
+
+	danglingGauge *prometheus.GaugeVec
+	scannedTotal  *prometheus.CounterVec
+}
+
+// NewChecker создает новую проверку целостности ссылок
+func NewChecker(db *database.Database, logger logging.Logger, options *CheckerOptions) *Checker {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	if options == nil {
+		options = DefaultCheckerOptions()
+	}
+
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultCheckerOptions().BatchSize
+	}
+
+	return &Checker{
+		db:      db,
+		logger:  logger,
+		options: options,
+		reports: make(map[string]*Report),
+		danglingGauge: metrics.Register(nil, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: metricName(options.ServicePrefix, "integrity_dangling_references"),
+				Help: "Number of dangling cross-service references found by the last check",
+			},
+			[]string{"reference"},
+		)),
+		scannedTotal: metrics.Register(nil, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: metricName(options.ServicePrefix, "integrity_scanned_rows_total"),
+				Help: "Total number of rows scanned by the integrity checker",
+			},
+			[]string{"reference"},
+		)),
+	}
+}
+
+// Register регистрирует ссылку для проверки
+func (c *Checker) Register(reference Reference) error {
+	if reference.Name == "" || reference.Table == "" || reference.Field == "" || reference.Resolver == nil {
+		return errors.New("reference name, table, field and resolver are required")
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, existing := range c.references {
+		if existing.Name == reference.Name {
+			return fmt.Errorf("reference %s is already registered", reference.Name)
+		}
+	}
+
+	c.references = append(c.references, reference)
+	return nil
+}
+
+// Run проверяет все зарегистрированные ссылки.
+// Отключается выключателем killswitch.FeatureIntegrityCheck.
+func (c *Checker) Run(ctx context.Context) []Report {
+	if killswitch.IsDisabled(killswitch.FeatureIntegrityCheck) {
+		c.logger.Info("Integrity check disabled by killswitch")
+		return nil
+	}
+
+	c.mutex.RLock()
+	references := append([]Reference(nil), c.references...)
+	c.mutex.RUnlock()
+
+	reports := make([]Report, 0, len(references))
+	for _, reference := range references {
+		if ctx.Err() != nil {
+			break
+		}
+		reports = append(reports, c.check(ctx, reference))
+	}
+
+	return reports
+}
+
+// Reports возвращает отчеты последней проверки, отсортированные по имени ссылки
+func (c *Checker) Reports() []Report {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	reports := make([]Report, 0, len(c.reports))
+	for _, report := range c.reports {
+		reports = append(reports, *report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Reference < reports[j].Reference
+	})
+	return reports
+}
+
+// Schedule периодически запускает проверку с указанным интервалом
+func (c *Checker) Schedule(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.Run(ctx)
+			}
+		}
+	}()
+}
+
+// ScheduleDaily запускает проверку ежедневно в указанное локальное время (например, ночью)
+func (c *Checker) ScheduleDaily(ctx context.Context, hour, minute int) {
+	go func() {
+		for {
+			timer := time.NewTimer(time.Until(nextRun(time.Now(), hour, minute)))
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				c.Run(ctx)
+			}
+		}
+	}()
+}
+
+// check сканирует таблицу ключевыми пакетами и проверяет ссылки одной записи ссылки
+func (c *Checker) check(ctx context.Context, reference Reference) Report {
+	report := Report{
+		Reference: reference.Name,
+		Samples:   []BrokenReference{},
+		StartedAt: time.Now(),
+	}
+
+	logger := c.logger.WithContext(ctx).WithField("reference", reference.Name)
+
+	err := c.scan(ctx, reference, &report)
+
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	if err != nil {
+		report.Error = err.Error()
+		logger.Error("Integrity check failed after %d rows: %v", report.Scanned, err)
+	} else {
+		c.danglingGauge.WithLabelValues(reference.Name).Set(float64(report.Dangling))
+		if report.Dangling > 0 {
+			logger.WithField("samples", report.Samples).
+				Warn("Found %d dangling references in %d rows", report.Dangling, report.Scanned)
+		} else {
+			logger.Info("Integrity check passed, %d rows scanned", report.Scanned)
+		}
+	}
+
+	c.mutex.Lock()
+	c.reports[reference.Name] = &report
+	c.mutex.Unlock()
+
+	return report
+}
+
+// scan обходит таблицу по возрастанию id и проверяет ссылки пакетами
+func (c *Checker) scan(ctx context.Context, reference Reference, report *Report) error {
+	var lastID uint
+
+	for {
+		rows, err := c.loadBatch(ctx, reference, lastID)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		lastID = rows[len(rows)-1].ID
+		report.Scanned += int64(len(rows))
+		c.scannedTotal.WithLabelValues(reference.Name).Add(float64(len(rows)))
+
+		broken, err := c.findBroken(ctx, reference, rows)
+		if err != nil {
+			return err
+		}
+
+		if len(broken) > 0 {
+			report.Dangling += int64(len(broken))
+			for _, row := range broken {
+				if len(report.Samples) >= c.options.SampleSize {
+					break
+				}
+				report.Samples = append(report.Samples, row)
+			}
+			c.publishBroken(ctx, reference, broken)
+		}
+
+		if len(rows) < c.options.BatchSize {
+			return nil
+		}
+
+		// Пауза между пакетами ограничивает нагрузку на внешние сервисы
+		if c.options.BatchDelay > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.options.BatchDelay):
+			}
+		}
+	}
+}
+
+// loadBatch загружает очередной пакет записей со ссылками после lastID
+func (c *Checker) loadBatch(ctx context.Context, reference Reference, lastID uint) ([]BrokenReference, error) {
+	sqlRows, err := c.db.GetDB().WithContext(ctx).
+		Table(reference.Table).
+		Select("id, ?", clause.Column{Name: reference.Field}).
+		Where("id > ?", lastID).
+		Where(clause.Neq{Column: clause.Column{Name: reference.Field}, Value: nil}).
+		Order("id").
+		Limit(c.options.BatchSize).
+		Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %v", reference.Table, err)
+	}
+	defer sqlRows.Close()
+
+	rows := make([]BrokenReference, 0, c.options.BatchSize)
+	for sqlRows.Next() {
+		var row BrokenReference
+		if err := sqlRows.Scan(&row.ID, &row.RefID); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %v", reference.Table, err)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, sqlRows.Err()
+}
+
+// findBroken возвращает записи пакета, ссылающиеся на несуществующие сущности
+func (c *Checker) findBroken(ctx context.Context, reference Reference, rows []BrokenReference) ([]BrokenReference, error) {
+	seen := make(map[uint]bool, len(rows))
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		if !seen[row.RefID] {
+			seen[row.RefID] = true
+			ids = append(ids, row.RefID)
+		}
+	}
+
+	existing, err := reference.Resolver.Existing(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve references: %v", err)
+	}
+
+	var broken []BrokenReference
+	for _, row := range rows {
+		if !existing[row.RefID] {
+			broken = append(broken, row)
+		}
+	}
+
+	return broken, nil
+}
+
+// publishBroken публикует событие reference.broken для пакета висячих ссылок
+func (c *Checker) publishBroken(ctx context.Context, reference Reference, broken []BrokenReference) {
+	if c.options.Publisher == nil {
+		return
+	}
+
+	event := BrokenReferenceEvent{
+		Reference: reference.Name,
+		Table:     reference.Table,
+		Field:     reference.Field,
+		Rows:      broken,
+	}
+
+	if err := c.options.Publisher.PublishEvent(ctx, BrokenReferenceEventType, event); err != nil {
+		c.logger.WithContext(ctx).Warn("Failed to publish %s event for %s: %v", BrokenReferenceEventType, reference.Name, err)
+	}
+}
+
+// nextRun вычисляет ближайший момент запуска в указанное время суток
+func nextRun(now time.Time, hour, minute int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// metricName формирует имя метрики с учетом префикса сервиса
+func metricName(servicePrefix, name string) string {
+	if servicePrefix == "" {
+		return name
+	}
+	return servicePrefix + "_" + name
+}
@@ -0,0 +1,253 @@
+package integrity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/database"
+	"github.com/vladzorgan/common/messaging/rabbitmq"
+	"gorm.io/gorm/logger"
+)
+
+// fakeResolver считает существующими идентификаторы из множества и записывает запрошенные пакеты
+type fakeResolver struct {
+	mutex    sync.Mutex
+	existing map[uint]bool
+	batches  [][]uint
+	err      error
+}
+
+func (r *fakeResolver) Existing(_ context.Context, ids []uint) (map[uint]bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.batches = append(r.batches, append([]uint(nil), ids...))
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	existing := make(map[uint]bool)
+	for _, id := range ids {
+		if r.existing[id] {
+			existing[id] = true
+		}
+	}
+	return existing, nil
+}
+
+// recordingPublisher запоминает опубликованные события
+type recordingPublisher struct {
+	mutex  sync.Mutex
+	events []BrokenReferenceEvent
+}
+
+func (p *recordingPublisher) PublishEvent(_ context.Context, eventType string, payload interface{}) error {
+	return p.PublishEventWithConfig(context.Background(), eventType, payload, nil)
+}
+
+func (p *recordingPublisher) PublishEventWithConfig(_ context.Context, eventType string, payload interface{}, _ *rabbitmq.PublishConfig) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if eventType != BrokenReferenceEventType {
+		return fmt.Errorf("unexpected event type %s", eventType)
+	}
+	p.events = append(p.events, payload.(BrokenReferenceEvent))
+	return nil
+}
+
+func (p *recordingPublisher) Close() {}
+
+func newTestChecker(options *CheckerOptions) *Checker {
+	return NewChecker(nil, nil, options)
+}
+
+func TestFindBrokenDeduplicatesResolverIDs(t *testing.T) {
+	checker := newTestChecker(nil)
+	resolver := &fakeResolver{existing: map[uint]bool{10: true}}
+	reference := Reference{Name: "order.city_id", Table: "orders", Field: "city_id", Resolver: resolver}
+
+	rows := []BrokenReference{{ID: 1, RefID: 10}, {ID: 2, RefID: 20}, {ID: 3, RefID: 10}, {ID: 4, RefID: 20}}
+	broken, err := checker.findBroken(context.Background(), reference, rows)
+	if err != nil {
+		t.Fatalf("findBroken() error = %v", err)
+	}
+
+	if len(broken) != 2 || broken[0].ID != 2 || broken[1].ID != 4 {
+		t.Errorf("broken = %+v, want rows 2 and 4", broken)
+	}
+	// Каждый идентификатор проверяется один раз на пакет
+	if len(resolver.batches) != 1 || len(resolver.batches[0]) != 2 {
+		t.Errorf("resolver batches = %v, want one batch [10 20]", resolver.batches)
+	}
+
+	resolver.err = errors.New("location-service unavailable")
+	if _, err := checker.findBroken(context.Background(), reference, rows); err == nil {
+		t.Error("findBroken() must fail when the resolver fails")
+	}
+}
+
+func TestPublishBrokenEvents(t *testing.T) {
+	publisher := &recordingPublisher{}
+	options := DefaultCheckerOptions()
+	options.Publisher = publisher
+	checker := newTestChecker(options)
+
+	reference := Reference{Name: "order.city_id", Table: "orders", Field: "city_id", Resolver: &fakeResolver{}}
+	checker.publishBroken(context.Background(), reference, []BrokenReference{{ID: 2, RefID: 20}})
+
+	if len(publisher.events) != 1 {
+		t.Fatalf("events = %d, want 1", len(publisher.events))
+	}
+	event := publisher.events[0]
+	if event.Reference != "order.city_id" || event.Table != "orders" || event.Field != "city_id" || len(event.Rows) != 1 {
+		t.Errorf("event = %+v", event)
+	}
+
+	// Без издателя события не публикуются
+	newTestChecker(nil).publishBroken(context.Background(), reference, []BrokenReference{{ID: 2, RefID: 20}})
+}
+
+func TestPerIDResolver(t *testing.T) {
+	calls := 0
+	resolver := PerIDResolver(func(_ context.Context, id uint) (bool, error) {
+		calls++
+		if id == 13 {
+			return false, errors.New("boom")
+		}
+		return id%2 == 0, nil
+	})
+
+	existing, err := resolver.Existing(context.Background(), []uint{1, 2, 4})
+	if err != nil || len(existing) != 2 || !existing[2] || !existing[4] || calls != 3 {
+		t.Errorf("Existing() = %v, %v after %d calls", existing, err, calls)
+	}
+
+	if _, err := resolver.Existing(context.Background(), []uint{2, 13}); err == nil {
+		t.Error("Existing() must fail when a lookup fails")
+	}
+}
+
+func TestRegisterValidatesReferences(t *testing.T) {
+	checker := newTestChecker(nil)
+	reference := Reference{Name: "order.city_id", Table: "orders", Field: "city_id", Resolver: &fakeResolver{}}
+
+	if err := checker.Register(reference); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := checker.Register(reference); err == nil {
+		t.Error("Register() must reject duplicate names")
+	}
+	if err := checker.Register(Reference{Name: "order.user_id", Table: "orders"}); err == nil {
+		t.Error("Register() must require field and resolver")
+	}
+}
+
+func TestHandlerReturnsSortedReports(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	checker := newTestChecker(nil)
+	checker.reports["order.user_id"] = &Report{Reference: "order.user_id"}
+	checker.reports["order.city_id"] = &Report{Reference: "order.city_id", Dangling: 2, Samples: []BrokenReference{{ID: 1, RefID: 5}}}
+
+	router := gin.New()
+	checker.RegisterHandlers(router)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/integrity", nil))
+
+	var body struct {
+		Reports []Report `json:"reports"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if len(body.Reports) != 2 || body.Reports[0].Reference != "order.city_id" || body.Reports[0].Dangling != 2 {
+		t.Errorf("reports = %+v", body.Reports)
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	location := time.FixedZone("MSK", 3*60*60)
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{now: time.Date(2026, 1, 10, 1, 0, 0, 0, location), want: time.Date(2026, 1, 10, 3, 30, 0, 0, location)},
+		{now: time.Date(2026, 1, 10, 3, 30, 0, 0, location), want: time.Date(2026, 1, 11, 3, 30, 0, 0, location)},
+		{now: time.Date(2026, 1, 31, 23, 0, 0, 0, location), want: time.Date(2026, 2, 1, 3, 30, 0, 0, location)},
+	}
+
+	for _, tt := range tests {
+		if got := nextRun(tt.now, 3, 30); !got.Equal(tt.want) {
+			t.Errorf("nextRun(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+}
+
+// TestCheckerScansPostgres сканирует таблицу в Postgres из COMMON_IT_DATABASE_URL
+func TestCheckerScansPostgres(t *testing.T) {
+	url := os.Getenv("COMMON_IT_DATABASE_URL")
+	if url == "" {
+		t.Skip("COMMON_IT_DATABASE_URL is not set")
+	}
+
+	dbOptions := database.DefaultDatabaseOptions()
+	dbOptions.LogLevel = logger.Silent
+	db, err := database.NewDatabase(url, nil, dbOptions)
+	if err != nil {
+		t.Fatalf("NewDatabase() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	gormDB := db.GetDB()
+	// Обычная таблица вместо временной: запросы пула могут выполняться в разных соединениях
+	if err := gormDB.Exec("CREATE TABLE integrity_orders (id bigserial PRIMARY KEY, city_id bigint)").Error; err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	t.Cleanup(func() { gormDB.Exec("DROP TABLE integrity_orders") })
+	// 7 записей: ссылки на города 1..3, город 3 удален, одна запись без города
+	for _, cityID := range []interface{}{1, 2, 3, nil, 3, 1, 3} {
+		if err := gormDB.Exec("INSERT INTO integrity_orders (city_id) VALUES (?)", cityID).Error; err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+
+	publisher := &recordingPublisher{}
+	options := &CheckerOptions{BatchSize: 2, BatchDelay: 10 * time.Millisecond, SampleSize: 2, Publisher: publisher, ServicePrefix: "integrity_it"}
+	checker := NewChecker(db, nil, options)
+	resolver := &fakeResolver{existing: map[uint]bool{1: true, 2: true}}
+	if err := checker.Register(Reference{Name: "order.city_id", Table: "integrity_orders", Field: "city_id", Resolver: resolver}); err != nil {
+		t.Fatal(err)
+	}
+
+	started := time.Now()
+	reports := checker.Run(context.Background())
+	elapsed := time.Since(started)
+
+	if len(reports) != 1 || reports[0].Error != "" {
+		t.Fatalf("reports = %+v", reports)
+	}
+	report := reports[0]
+	if report.Scanned != 6 || report.Dangling != 3 || len(report.Samples) != 2 {
+		t.Errorf("report = %+v, want 6 scanned, 3 dangling, 2 samples", report)
+	}
+	// 6 записей пакетами по 2: три обращения к резолверу и пауза между пакетами
+	if len(resolver.batches) != 3 {
+		t.Errorf("resolver batches = %v, want 3", resolver.batches)
+	}
+	if elapsed < 2*options.BatchDelay {
+		t.Errorf("scan took %v, want at least %v of batch delays", elapsed, 2*options.BatchDelay)
+	}
+	if len(publisher.events) != 2 {
+		t.Errorf("events = %d, want one per batch with dangling references", len(publisher.events))
+	}
+}
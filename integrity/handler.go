@@ -0,0 +1,27 @@
+package integrity
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler возвращает обработчик, отдающий отчеты последней проверки целостности ссылок
+// @Summary Отчет о целостности ссылок
+// @Description Возвращает количество и примеры висячих ссылок на сущности других сервисов
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /admin/integrity [get]
+func (c *Checker) Handler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{
+			"reports": c.Reports(),
+		})
+	}
+}
+
+// RegisterHandlers регистрирует обработчики отчета в маршрутизаторе
+func (c *Checker) RegisterHandlers(router gin.IRouter) {
+	router.GET("/admin/integrity", c.Handler())
+}
@@ -0,0 +1,68 @@
+package integrity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vladzorgan/common/database"
+)
+
+// Resolver проверяет существование сущностей, на которые ссылаются локальные записи
+type Resolver interface {
+	// Existing возвращает множество существующих идентификаторов из переданного пакета
+	Existing(ctx context.Context, ids []uint) (map[uint]bool, error)
+}
+
+// ResolverFunc позволяет использовать функцию в качестве Resolver,
+// например обертку над пакетным методом gRPC клиента
+type ResolverFunc func(ctx context.Context, ids []uint) (map[uint]bool, error)
+
+// Existing вызывает функцию
+func (f ResolverFunc) Existing(ctx context.Context, ids []uint) (map[uint]bool, error) {
+	return f(ctx, ids)
+}
+
+// PerIDResolver создает Resolver для клиентов без пакетного метода: exists вызывается для каждого ID
+func PerIDResolver(exists func(ctx context.Context, id uint) (bool, error)) Resolver {
+	return ResolverFunc(func(ctx context.Context, ids []uint) (map[uint]bool, error) {
+		existing := make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			ok, err := exists(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve id %d: %v", id, err)
+			}
+			if ok {
+				existing[id] = true
+			}
+		}
+		return existing, nil
+	})
+}
+
+// tableResolver проверяет существование записей в локальной таблице
+type tableResolver struct {
+	db    *database.Database
+	table string
+}
+
+// TableResolver создает Resolver, проверяющий существование записей в локальной таблице по колонке id
+func TableResolver(db *database.Database, table string) Resolver {
+	return &tableResolver{db: db, table: table}
+}
+
+// Existing возвращает идентификаторы, найденные в таблице
+func (r *tableResolver) Existing(ctx context.Context, ids []uint) (map[uint]bool, error) {
+	var found []uint
+	if err := r.db.GetDB().WithContext(ctx).
+		Table(r.table).
+		Where("id IN ?", ids).
+		Pluck("id", &found).Error; err != nil {
+		return nil, fmt.Errorf("failed to query %s: %v", r.table, err)
+	}
+
+	existing := make(map[uint]bool, len(found))
+	for _, id := range found {
+		existing[id] = true
+	}
+	return existing, nil
+}
@@ -0,0 +1,42 @@
+package killswitch
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureState представляет состояние выключателя
+type FeatureState struct {
+	Feature  string `json:"feature"`
+	Disabled bool   `json:"disabled"`
+}
+
+// Handler возвращает обработчик, отдающий текущее состояние выключателей
+// @Summary Состояние выключателей функций
+// @Description Возвращает список функций библиотеки и признак их отключения
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /admin/killswitches [get]
+func Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		features := make([]FeatureState, 0, len(Features()))
+		for _, feature := range Features() {
+			features = append(features, FeatureState{
+				Feature:  feature,
+				Disabled: IsDisabled(feature),
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"features": features,
+			"disabled": Disabled(),
+		})
+	}
+}
+
+// RegisterHandlers регистрирует обработчики выключателей в маршрутизаторе
+func RegisterHandlers(router gin.IRouter) {
+	router.GET("/admin/killswitches", Handler())
+}
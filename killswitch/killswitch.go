@@ -0,0 +1,253 @@
+// Package killswitch предоставляет выключатели опциональных функций библиотеки.
+// Функции отключаются без нового релиза через переменную окружения COMMON_DISABLE
+// (список имен через запятую, например COMMON_DISABLE="request_budget,response_cache")
+// и, опционально, через множество в Redis (см. WatchRedis).
+//
+// Имена выключателей:
+//
+//	grpc_client_retry     повторные попытки клиентского интерцептора gRPC
+//	rate_limit            ограничение частоты HTTP запросов
+//	health_cache          кеширование результатов проверки здоровья
+//	consistency           чтение с primary после записи
+//	archive_read_through  чтение из архивной таблицы в GetByID
+//	request_budget        бюджет исходящих запросов и повторов (пакет budget)
+//	consumer_dedup        дедупликация сообщений Consumer
+//	exemplars             exemplar в гистограммах длительности запросов
+//	integrity_check       проверка ссылок на внешние сущности
+//	redis_read_retry      повторы команд чтения Redis
+//	publish_buffer        буферизация событий при недоступности RabbitMQ
+//	login_protection      блокировка входа после неудачных попыток
+//	http_client_retry     повторные попытки httpclient
+//	response_cache        кеширование ответов CachedService
+package killswitch
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/redis"
+)
+
+// EnvVar - переменная окружения со списком отключенных функций
+const EnvVar = "COMMON_DISABLE"
+
+// Имена выключателей функций библиотеки
+const (
+	// FeatureGRPCClientRetry отключает повторные попытки в клиентском интерцепторе gRPC
+	FeatureGRPCClientRetry = "grpc_client_retry"
+	// FeatureRateLimit отключает ограничение частоты HTTP запросов
+	FeatureRateLimit = "rate_limit"
+	// FeatureHealthCache отключает кеширование результатов проверки здоровья
+	FeatureHealthCache = "health_cache"
+	// FeatureConsistency отключает маршрутизацию чтений на primary после записи
+	FeatureConsistency = "consistency"
+	// FeatureArchiveReadThrough отключает чтение из архивной таблицы в GetByID
+	FeatureArchiveReadThrough = "archive_read_through"
+	// FeatureRequestBudget отключает бюджет исходящих запросов
+	FeatureRequestBudget = "request_budget"
+	// FeatureConsumerDedup отключает дедупликацию сообщений в Consumer
+	FeatureConsumerDedup = "consumer_dedup"
+	// FeatureExemplars отключает прикрепление exemplar к гистограммам длительности запросов
+	FeatureExemplars = "exemplars"
+	// FeatureIntegrityCheck отключает проверку целостности ссылок на внешние сущности
+	FeatureIntegrityCheck = "integrity_check"
+	// FeatureRedisReadRetry отключает повторы команд чтения Redis после сетевых ошибок
+	FeatureRedisReadRetry = "redis_read_retry"
+	// FeaturePublishBuffer отключает буферизацию событий издателя при недоступности RabbitMQ
+	FeaturePublishBuffer = "publish_buffer"
+	// FeatureLoginProtection отключает блокировку входа после серии неудачных попыток
+	FeatureLoginProtection = "login_protection"
+	// FeatureHTTPClientRetry отключает повторные попытки исходящих HTTP запросов httpclient
+	FeatureHTTPClientRetry = "http_client_retry"
+	// FeatureResponseCache отключает кеширование ответов сервисов CachedService
+	FeatureResponseCache = "response_cache"
+)
+
+// Features возвращает имена всех выключателей библиотеки
+func Features() []string {
+	return []string{
+		FeatureGRPCClientRetry,
+		FeatureRateLimit,
+		FeatureHealthCache,
+		FeatureConsistency,
+		FeatureArchiveReadThrough,
+		FeatureRequestBudget,
+		FeatureConsumerDedup,
+		FeatureExemplars,
+		FeatureIntegrityCheck,
+		FeatureRedisReadRetry,
+		FeaturePublishBuffer,
+		FeatureLoginProtection,
+		FeatureHTTPClientRetry,
+		FeatureResponseCache,
+	}
+}
+
+// registry хранит состояние выключателей
+type registry struct {
+	env    map[string]bool
+	remote map[string]bool
+	gauge  *prometheus.GaugeVec
+	mutex  sync.RWMutex
+}
+
+var global = &registry{
+	env:    parseList(os.Getenv(EnvVar)),
+	remote: make(map[string]bool),
+}
+
+// IsDisabled проверяет, отключена ли функция
+func IsDisabled(feature string) bool {
+	global.mutex.RLock()
+	defer global.mutex.RUnlock()
+
+	return global.env[feature] || global.remote[feature]
+}
+
+// Disabled возвращает отсортированный список отключенных функций
+func Disabled() []string {
+	global.mutex.RLock()
+	defer global.mutex.RUnlock()
+
+	return global.disabledLocked()
+}
+
+// NewGauge создает метрику состояния выключателей для Init. Регистрируется вызывающим кодом
+// через metrics.Register: пакет metrics сам зависит от killswitch.
+func NewGauge(servicePrefix string) *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: servicePrefix + "_killswitch_disabled",
+			Help: "Состояние выключателей функций библиотеки (1 - функция отключена)",
+		},
+		[]string{"feature"},
+	)
+}
+
+// Init задает зарегистрированную метрику состояния выключателей (nil - без метрики)
+// и логирует отключенные функции. Вызывается при старте сервиса.
+func Init(gauge *prometheus.GaugeVec, logger logging.Logger) {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	global.mutex.Lock()
+	global.gauge = gauge
+	global.updateGaugeLocked()
+	disabled := global.disabledLocked()
+	global.mutex.Unlock()
+
+	if len(disabled) > 0 {
+		logger.Warn("Disabled library features: %s", strings.Join(disabled, ", "))
+	} else {
+		logger.Info("No library features disabled")
+	}
+}
+
+// WatchRedis периодически загружает отключенные функции из множества Redis до отмены контекста
+func WatchRedis(ctx context.Context, client *redis.Client, key string, interval time.Duration, logger logging.Logger) {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	refresh := func() {
+		members, err := client.Client().SMembers(ctx, key).Result()
+		if err != nil {
+			// Сохраняем последнее известное состояние
+			logger.Warn("Failed to load killswitches from Redis: %v", err)
+			return
+		}
+
+		remote := make(map[string]bool, len(members))
+		for _, member := range members {
+			remote[strings.TrimSpace(member)] = true
+		}
+
+		global.mutex.Lock()
+		changed := !sameSet(global.remote, remote)
+		global.remote = remote
+		global.updateGaugeLocked()
+		disabled := global.disabledLocked()
+		global.mutex.Unlock()
+
+		if changed {
+			logger.Warn("Library killswitches changed, disabled features: [%s]", strings.Join(disabled, ", "))
+		}
+	}
+
+	refresh()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				refresh()
+			}
+		}
+	}()
+}
+
+// disabledLocked возвращает список отключенных функций; вызывается под блокировкой
+func (r *registry) disabledLocked() []string {
+	disabled := make([]string, 0, len(r.env)+len(r.remote))
+	for feature := range r.env {
+		disabled = append(disabled, feature)
+	}
+	for feature := range r.remote {
+		if !r.env[feature] {
+			disabled = append(disabled, feature)
+		}
+	}
+	sort.Strings(disabled)
+	return disabled
+}
+
+// updateGaugeLocked обновляет метрику состояния; вызывается под блокировкой
+func (r *registry) updateGaugeLocked() {
+	if r.gauge == nil {
+		return
+	}
+
+	for _, feature := range Features() {
+		value := 0.0
+		if r.env[feature] || r.remote[feature] {
+			value = 1
+		}
+		r.gauge.WithLabelValues(feature).Set(value)
+	}
+}
+
+// parseList разбирает список имен через запятую
+func parseList(value string) map[string]bool {
+	result := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result[item] = true
+		}
+	}
+	return result
+}
+
+// sameSet сравнивает два множества
+func sameSet(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key := range a {
+		if !b[key] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+package killswitch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/redis"
+)
+
+// setState задает состояние выключателей и восстанавливает прежнее после теста
+func setState(t *testing.T, envFeatures string, remoteFeatures ...string) {
+	t.Helper()
+
+	global.mutex.Lock()
+	env, remote, gauge := global.env, global.remote, global.gauge
+	global.env = parseList(envFeatures)
+	global.remote = parseList(strings.Join(remoteFeatures, ","))
+	global.gauge = nil
+	global.mutex.Unlock()
+
+	t.Cleanup(func() {
+		global.mutex.Lock()
+		defer global.mutex.Unlock()
+		global.env, global.remote, global.gauge = env, remote, gauge
+	})
+}
+
+// recordingLogger запоминает предупреждения
+type recordingLogger struct {
+	logging.Logger
+
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) Warn(format string, v ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.messages = append(l.messages, format)
+}
+
+func (l *recordingLogger) count() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return len(l.messages)
+}
+
+func TestParseList(t *testing.T) {
+	got := parseList(" rate_limit ,, response_cache,\trequest_budget ,")
+	want := map[string]bool{"rate_limit": true, "response_cache": true, "request_budget": true}
+	if !sameSet(got, want) {
+		t.Errorf("parseList() = %v, want %v", got, want)
+	}
+	if got := parseList(""); len(got) != 0 {
+		t.Errorf("parseList(\"\") = %v, want empty", got)
+	}
+}
+
+func TestIsDisabledUnionsEnvAndRemote(t *testing.T) {
+	setState(t, "rate_limit,response_cache", FeatureResponseCache, FeatureConsumerDedup)
+
+	for _, feature := range []string{FeatureRateLimit, FeatureResponseCache, FeatureConsumerDedup} {
+		if !IsDisabled(feature) {
+			t.Errorf("IsDisabled(%s) = false, want true", feature)
+		}
+	}
+	if IsDisabled(FeatureRequestBudget) {
+		t.Error("IsDisabled(request_budget) = true, want false")
+	}
+
+	want := []string{FeatureConsumerDedup, FeatureRateLimit, FeatureResponseCache}
+	if got := Disabled(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Disabled() = %v, want %v", got, want)
+	}
+}
+
+func TestInitSetsGauge(t *testing.T) {
+	setState(t, FeatureRateLimit, FeatureExemplars)
+
+	gauge := NewGauge("killswitch_test")
+	Init(gauge, &recordingLogger{Logger: logging.NewLogger()})
+
+	for _, feature := range Features() {
+		want := 0.0
+		if feature == FeatureRateLimit || feature == FeatureExemplars {
+			want = 1
+		}
+		if got := testutil.ToFloat64(gauge.WithLabelValues(feature)); got != want {
+			t.Errorf("gauge{feature=%s} = %v, want %v", feature, got, want)
+		}
+	}
+	if count := testutil.CollectAndCount(gauge); count != len(Features()) {
+		t.Errorf("gauge series = %d, want %d", count, len(Features()))
+	}
+}
+
+func TestWatchRedisKeepsStateOnError(t *testing.T) {
+	setState(t, "")
+
+	server := miniredis.RunT(t)
+	client, err := redis.NewClient(server.Addr(), "", 0, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	gauge := NewGauge("killswitch_watch_test")
+	global.mutex.Lock()
+	global.gauge = gauge
+	global.mutex.Unlock()
+
+	server.SAdd("common:killswitch", FeatureResponseCache)
+	logger := &recordingLogger{Logger: logging.NewLogger()}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	WatchRedis(ctx, client, "common:killswitch", 20*time.Millisecond, logger)
+
+	if !IsDisabled(FeatureResponseCache) {
+		t.Fatal("response_cache must be disabled after the first load")
+	}
+	if got := testutil.ToFloat64(gauge.WithLabelValues(FeatureResponseCache)); got != 1 {
+		t.Errorf("gauge = %v, want 1", got)
+	}
+
+	// При ошибке Redis сохраняется последнее известное состояние
+	server.SetError("LOADING")
+	warnings := logger.count()
+	deadline := time.Now().Add(time.Second)
+	for logger.count() == warnings && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if logger.count() == warnings {
+		t.Fatal("no warning about the failed refresh")
+	}
+	if !IsDisabled(FeatureResponseCache) {
+		t.Error("response_cache re-enabled after a Redis error")
+	}
+
+	// После восстановления Redis изменения применяются
+	server.SetError("")
+	server.Del("common:killswitch")
+	for IsDisabled(FeatureResponseCache) && time.Now().Before(deadline.Add(time.Second)) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if IsDisabled(FeatureResponseCache) {
+		t.Error("response_cache still disabled after removal from Redis")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	setState(t, FeatureRateLimit)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	RegisterHandlers(router)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/killswitches", nil))
+
+	var body struct {
+		Features []FeatureState `json:"features"`
+		Disabled []string       `json:"disabled"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error = %v", recorder.Body.String(), err)
+	}
+
+	if recorder.Code != http.StatusOK || len(body.Features) != len(Features()) {
+		t.Fatalf("status = %d, features = %d; want 200, %d", recorder.Code, len(body.Features), len(Features()))
+	}
+	for _, state := range body.Features {
+		if state.Disabled != (state.Feature == FeatureRateLimit) {
+			t.Errorf("feature %s disabled = %v", state.Feature, state.Disabled)
+		}
+	}
+	if len(body.Disabled) != 1 || body.Disabled[0] != FeatureRateLimit {
+		t.Errorf("disabled = %v, want [rate_limit]", body.Disabled)
+	}
+}
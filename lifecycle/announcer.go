@@ -0,0 +1,192 @@
+// Package lifecycle публикует события о запуске и остановке экземпляров сервиса
+// (service.started / service.stopping), чтобы дашборды показывали волны деплоя.
+package lifecycle
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vladzorgan/common/config"
+	"github.com/vladzorgan/common/health"
+	"github.com/vladzorgan/common/logging"
+	events "github.com/vladzorgan/common/messaging/rabbitmq"
+)
+
+// Exchange - отдельный обменник для событий жизненного цикла
+const Exchange = "service.lifecycle"
+
+// Типы событий жизненного цикла
+const (
+	EventStarted  = "service.started"
+	EventStopping = "service.stopping"
+)
+
+// Event представляет событие жизненного цикла экземпляра сервиса
+type Event struct {
+	Service    string    `json:"service"`
+	Prefix     string    `json:"prefix"`
+	Version    string    `json:"version"`
+	GitCommit  string    `json:"git_commit,omitempty"`
+	Hostname   string    `json:"hostname"`
+	InstanceID string    `json:"instance_id"`
+	ConfigHash string    `json:"config_hash"`
+	StartedAt  time.Time `json:"started_at"`
+}
+
+// AnnouncerOptions содержит опции публикации событий жизненного цикла
+type AnnouncerOptions struct {
+	// Максимальное время публикации: недоступный брокер не должен задерживать запуск и остановку
+	Timeout time.Duration
+	// Интервал проверки готовности перед публикацией service.started
+	ReadinessInterval time.Duration
+	// Хеш коммита сборки
+	GitCommit string
+}
+
+// DefaultAnnouncerOptions возвращает опции по умолчанию. Хеш коммита берется из GIT_COMMIT.
+func DefaultAnnouncerOptions() *AnnouncerOptions {
+	return &AnnouncerOptions{
+		Timeout:           2 * time.Second,
+		ReadinessInterval: time.Second,
+		GitCommit:         os.Getenv("GIT_COMMIT"),
+	}
+}
+
+// Announcer публикует события жизненного цикла экземпляра сервиса
+type Announcer struct {
+	publisher events.EventPublisher
+	logger    logging.Logger
+	options   *AnnouncerOptions
+	event     Event
+	started   sync.Once
+	// stopping устанавливается в начале остановки: service.started после этого не публикуется
+	stopping atomic.Bool
+}
+
+// NewAnnouncer создает публикатор событий жизненного цикла.
+// Издатель должен публиковать в обменник Exchange (см. NewPublisher).
+func NewAnnouncer(publisher events.EventPublisher, cfg *config.BaseConfig, logger logging.Logger, options *AnnouncerOptions) *Announcer {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	if options == nil {
+		options = DefaultAnnouncerOptions()
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return &Announcer{
+		publisher: publisher,
+		logger:    logger,
+		options:   options,
+		event: Event{
+			Service:    cfg.ServiceName,
+			Prefix:     cfg.ServicePrefix,
+			Version:    cfg.Version,
+			GitCommit:  options.GitCommit,
+			Hostname:   hostname,
+			InstanceID: uuid.New().String(),
+			ConfigHash: ConfigHash(cfg),
+			StartedAt:  time.Now(),
+		},
+	}
+}
+
+// NewPublisher создает издателя, публикующего в обменник событий жизненного цикла
+func NewPublisher(cfg *config.BaseConfig, logger logging.Logger) (*events.Publisher, error) {
+	return events.NewPublisher(cfg.RabbitMQURL, Exchange, cfg.ServiceName, logger)
+}
+
+// Started публикует service.started (не более одного раза и только до вызова Stopping)
+func (a *Announcer) Started(ctx context.Context) {
+	a.started.Do(func() {
+		if a.stopping.Load() {
+			return
+		}
+		a.publish(ctx, EventStarted)
+	})
+}
+
+// Stopping публикует service.stopping; вызывается в начале корректной остановки
+func (a *Announcer) Stopping(ctx context.Context) {
+	a.stopping.Store(true)
+	a.publish(ctx, EventStopping)
+}
+
+// AnnounceWhenReady в фоне дожидается готовности экземпляра и публикует service.started.
+// Экземпляр готов, когда открыт переключатель готовности (см. health.Checker.SetReady)
+// и проверка компонентов не возвращает StatusDown. Переключатель проверяется перед каждой
+// попыткой: после начала остановки (причина "shutting_down") событие не публикуется.
+func (a *Announcer) AnnounceWhenReady(ctx context.Context, checker *health.Checker) {
+	go func() {
+		ticker := time.NewTicker(a.options.ReadinessInterval)
+		defer ticker.Stop()
+
+		for ctx.Err() == nil {
+			// Пока сервис запускается (CheckerOptions.StartNotReady), проверки компонентов не выполняются
+			if ready, reason := checker.Ready(); !ready {
+				if reason == health.ReasonShuttingDown || checker.WaitUntilReady(ctx) != nil {
+					return
+				}
+				continue
+			}
+
+			result, err := checker.Check(ctx)
+			if ready, _ := checker.Ready(); ready && err == nil && result.Status != health.StatusDown {
+				a.Started(ctx)
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// publish публикует событие, не дожидаясь брокера дольше Timeout
+func (a *Announcer) publish(ctx context.Context, eventType string) {
+	ctx, cancel := context.WithTimeout(ctx, a.options.Timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.publisher.PublishEvent(ctx, eventType, a.event)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			a.logger.Warn("Failed to publish %s: %v", eventType, err)
+			return
+		}
+		a.logger.Info("Published %s for instance %s", eventType, a.event.InstanceID)
+	case <-ctx.Done():
+		a.logger.Warn("Publishing %s timed out after %v", eventType, a.options.Timeout)
+	}
+}
+
+// ConfigHash возвращает короткий хеш конфигурации, позволяющий сравнить экземпляры без раскрытия секретов
+func ConfigHash(cfg *config.BaseConfig) string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%+v", *cfg))
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
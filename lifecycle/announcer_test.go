@@ -0,0 +1,235 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vladzorgan/common/config"
+	"github.com/vladzorgan/common/health"
+	events "github.com/vladzorgan/common/messaging/rabbitmq"
+)
+
+// recordingPublisher запоминает опубликованные события жизненного цикла
+type recordingPublisher struct {
+	mutex  sync.Mutex
+	types  []string
+	events []Event
+	// block задерживает публикацию до отмены контекста
+	block bool
+}
+
+func (p *recordingPublisher) PublishEvent(ctx context.Context, eventType string, payload interface{}) error {
+	return p.PublishEventWithConfig(ctx, eventType, payload, nil)
+}
+
+func (p *recordingPublisher) PublishEventWithConfig(ctx context.Context, eventType string, payload interface{}, _ *events.PublishConfig) error {
+	if p.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.types = append(p.types, eventType)
+	p.events = append(p.events, payload.(Event))
+	return nil
+}
+
+func (p *recordingPublisher) Close() {}
+
+func (p *recordingPublisher) published() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]string(nil), p.types...)
+}
+
+// switchComponent - компонент проверки здоровья с переключаемым состоянием
+type switchComponent struct {
+	mutex sync.Mutex
+	up    bool
+}
+
+func (c *switchComponent) Name() string     { return "switch" }
+func (c *switchComponent) IsCritical() bool { return true }
+
+func (c *switchComponent) Check(context.Context) (health.Status, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.up {
+		return health.StatusUp, nil
+	}
+	return health.StatusDown, nil
+}
+
+func (c *switchComponent) set(up bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.up = up
+}
+
+func testConfig() *config.BaseConfig {
+	return &config.BaseConfig{ServiceName: "orders", ServicePrefix: "orders", Version: "1.2.3"}
+}
+
+func newTestAnnouncer(publisher events.EventPublisher) *Announcer {
+	return NewAnnouncer(publisher, testConfig(), nil, &AnnouncerOptions{
+		Timeout:           50 * time.Millisecond,
+		ReadinessInterval: 5 * time.Millisecond,
+		GitCommit:         "abc123",
+	})
+}
+
+// waitPublished ждет, пока число опубликованных событий достигнет n
+func waitPublished(t *testing.T, publisher *recordingPublisher, n int) []string {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		published := publisher.published()
+		if len(published) >= n || time.Now().After(deadline) {
+			return published
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAnnouncerPublishesStartedOnceAndStopping(t *testing.T) {
+	publisher := &recordingPublisher{}
+	announcer := newTestAnnouncer(publisher)
+	ctx := context.Background()
+
+	announcer.Started(ctx)
+	announcer.Started(ctx)
+	announcer.Stopping(ctx)
+
+	if got := publisher.published(); len(got) != 2 || got[0] != EventStarted || got[1] != EventStopping {
+		t.Fatalf("published = %v, want [%s %s]", got, EventStarted, EventStopping)
+	}
+
+	event := publisher.events[0]
+	if event.Service != "orders" || event.Version != "1.2.3" || event.GitCommit != "abc123" ||
+		event.Hostname == "" || event.InstanceID == "" || event.ConfigHash != ConfigHash(testConfig()) {
+		t.Errorf("event = %+v", event)
+	}
+	if publisher.events[1].InstanceID != event.InstanceID {
+		t.Error("stopping event has a different instance ID")
+	}
+}
+
+func TestAnnouncerDoesNotBlockOnDeadBroker(t *testing.T) {
+	announcer := newTestAnnouncer(&recordingPublisher{block: true})
+
+	started := time.Now()
+	announcer.Stopping(context.Background())
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Errorf("Stopping() took %v, want at most the publish timeout", elapsed)
+	}
+}
+
+func TestAnnounceWhenReadyWaitsForReadinessGate(t *testing.T) {
+	checker := health.NewCheckerWithOptions("orders", "orders", "1.2.3", &health.CheckerOptions{StartNotReady: true})
+	component := &switchComponent{up: true}
+	checker.RegisterComponent(component)
+
+	publisher := &recordingPublisher{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	newTestAnnouncer(publisher).AnnounceWhenReady(ctx, checker)
+
+	// Компоненты здоровы, но сервис еще запускается
+	time.Sleep(30 * time.Millisecond)
+	if got := publisher.published(); len(got) != 0 {
+		t.Fatalf("published = %v before the readiness gate opened", got)
+	}
+
+	checker.SetReady(true)
+	if got := waitPublished(t, publisher, 1); len(got) != 1 || got[0] != EventStarted {
+		t.Errorf("published = %v, want [%s]", got, EventStarted)
+	}
+}
+
+func TestAnnounceWhenReadyWaitsForComponents(t *testing.T) {
+	checker := health.NewChecker("orders", "orders", "1.2.3")
+	component := &switchComponent{}
+	checker.RegisterComponent(component)
+
+	publisher := &recordingPublisher{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	newTestAnnouncer(publisher).AnnounceWhenReady(ctx, checker)
+
+	time.Sleep(30 * time.Millisecond)
+	if got := publisher.published(); len(got) != 0 {
+		t.Fatalf("published = %v while a critical component is down", got)
+	}
+
+	component.set(true)
+	if got := waitPublished(t, publisher, 1); len(got) != 1 || got[0] != EventStarted {
+		t.Errorf("published = %v, want [%s]", got, EventStarted)
+	}
+}
+
+func TestAnnounceWhenReadyStopsOnCancel(t *testing.T) {
+	checker := health.NewCheckerWithOptions("orders", "orders", "1.2.3", &health.CheckerOptions{StartNotReady: true})
+	publisher := &recordingPublisher{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	newTestAnnouncer(publisher).AnnounceWhenReady(ctx, checker)
+	cancel()
+
+	checker.SetReady(true)
+	time.Sleep(30 * time.Millisecond)
+	if got := publisher.published(); len(got) != 0 {
+		t.Errorf("published = %v after cancellation", got)
+	}
+}
+
+func TestConfigHash(t *testing.T) {
+	first, second := testConfig(), testConfig()
+	if ConfigHash(first) != ConfigHash(second) || len(ConfigHash(first)) != 16 {
+		t.Errorf("ConfigHash() = %s, %s; want equal 16-char hashes", ConfigHash(first), ConfigHash(second))
+	}
+
+	second.Version = "1.2.4"
+	if ConfigHash(first) == ConfigHash(second) {
+		t.Error("ConfigHash() must change with the configuration")
+	}
+}
+
+func TestAnnounceWhenReadySkipsStartedAfterShutdownBegins(t *testing.T) {
+	checker := health.NewChecker("orders", "orders", "1.2.3")
+	component := &switchComponent{}
+	checker.RegisterComponent(component)
+
+	publisher := &recordingPublisher{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	newTestAnnouncer(publisher).AnnounceWhenReady(ctx, checker)
+
+	// Остановка начинается, пока компонент недоступен; затем компонент восстанавливается
+	time.Sleep(20 * time.Millisecond)
+	checker.SetReady(false)
+	component.set(true)
+
+	time.Sleep(30 * time.Millisecond)
+	if got := publisher.published(); len(got) != 0 {
+		t.Errorf("published = %v after the readiness gate closed for shutdown", got)
+	}
+}
+
+func TestStartedNotPublishedAfterStopping(t *testing.T) {
+	publisher := &recordingPublisher{}
+	announcer := newTestAnnouncer(publisher)
+
+	announcer.Stopping(context.Background())
+	announcer.Started(context.Background())
+
+	if got := publisher.published(); len(got) != 1 || got[0] != EventStopping {
+		t.Errorf("published = %v, want only [%s]", got, EventStopping)
+	}
+}
@@ -0,0 +1,105 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+	events "github.com/vladzorgan/common/messaging/rabbitmq"
+)
+
+// Instance представляет известный экземпляр сервиса
+type Instance struct {
+	Event
+	// Последнее событие экземпляра (service.started или service.stopping)
+	LastEvent string    `json:"last_event"`
+	SeenAt    time.Time `json:"seen_at"`
+}
+
+// Inventory собирает список экземпляров сервисов по событиям жизненного цикла
+type Inventory struct {
+	instances map[string]*Instance
+	mutex     sync.RWMutex
+}
+
+// NewInventory создает пустой список экземпляров
+func NewInventory() *Inventory {
+	return &Inventory{
+		instances: make(map[string]*Instance),
+	}
+}
+
+// Subscribe подписывает список на события жизненного цикла.
+// Потребитель должен быть создан для обменника Exchange.
+func (i *Inventory) Subscribe(consumer *events.Consumer) error {
+	for _, routingKey := range []string{EventStarted, EventStopping} {
+		if err := consumer.Subscribe(routingKey, i.Handler()); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %v", routingKey, err)
+		}
+	}
+	return nil
+}
+
+// Handler возвращает обработчик событий жизненного цикла
+func (i *Inventory) Handler() events.HandlerFunc {
+	return func(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+		var event Event
+		if err := json.Unmarshal(message, &event); err != nil {
+			return nil // Повторная доставка не поможет
+		}
+
+		i.Record(delivery.RoutingKey, event)
+		return nil
+	}
+}
+
+// Record сохраняет событие экземпляра
+func (i *Inventory) Record(eventType string, event Event) {
+	if event.InstanceID == "" {
+		return
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.instances[event.InstanceID] = &Instance{
+		Event:     event,
+		LastEvent: eventType,
+		SeenAt:    time.Now(),
+	}
+}
+
+// Instances возвращает известные экземпляры, отсортированные по сервису и времени запуска
+func (i *Inventory) Instances() []Instance {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	instances := make([]Instance, 0, len(i.instances))
+	for _, instance := range i.instances {
+		instances = append(instances, *instance)
+	}
+
+	sort.Slice(instances, func(a, b int) bool {
+		if instances[a].Service != instances[b].Service {
+			return instances[a].Service < instances[b].Service
+		}
+		return instances[a].StartedAt.Before(instances[b].StartedAt)
+	})
+	return instances
+}
+
+// Running возвращает экземпляры, не сообщившие об остановке
+func (i *Inventory) Running() []Instance {
+	instances := i.Instances()
+	running := instances[:0]
+	for _, instance := range instances {
+		if instance.LastEvent != EventStopping {
+			running = append(running, instance)
+		}
+	}
+	return running
+}
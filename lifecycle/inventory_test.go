@@ -0,0 +1,46 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+func TestInventoryTracksRunningInstances(t *testing.T) {
+	inventory := NewInventory()
+	handler := inventory.Handler()
+	now := time.Now()
+
+	deliver := func(eventType string, event Event) {
+		body, _ := json.Marshal(event)
+		if err := handler(context.Background(), amqp.Delivery{RoutingKey: eventType}, body); err != nil {
+			t.Fatalf("handler() error = %v", err)
+		}
+	}
+
+	deliver(EventStarted, Event{Service: "orders", InstanceID: "orders-2", StartedAt: now})
+	deliver(EventStarted, Event{Service: "orders", InstanceID: "orders-1", StartedAt: now.Add(-time.Hour)})
+	deliver(EventStarted, Event{Service: "billing", InstanceID: "billing-1", StartedAt: now})
+	deliver(EventStopping, Event{Service: "orders", InstanceID: "orders-1", StartedAt: now.Add(-time.Hour)})
+	// События без идентификатора экземпляра и некорректные сообщения пропускаются
+	deliver(EventStarted, Event{Service: "ghost"})
+	if err := handler(context.Background(), amqp.Delivery{RoutingKey: EventStarted}, []byte("{")); err != nil {
+		t.Errorf("handler() error = %v for invalid JSON, want nil", err)
+	}
+
+	instances := inventory.Instances()
+	if len(instances) != 3 || instances[0].InstanceID != "billing-1" || instances[1].InstanceID != "orders-1" {
+		t.Fatalf("instances = %+v, want sorted by service and start time", instances)
+	}
+	if instances[1].LastEvent != EventStopping {
+		t.Errorf("orders-1 last event = %s, want %s", instances[1].LastEvent, EventStopping)
+	}
+
+	running := inventory.Running()
+	if len(running) != 2 || running[0].InstanceID != "billing-1" || running[1].InstanceID != "orders-2" {
+		t.Errorf("running = %+v, want billing-1 and orders-2", running)
+	}
+}
@@ -86,37 +86,14 @@ func NewLogger() Logger {
 		levelStr = "info"
 	}
 
-	level := LogLevel(levelStr)
-
-	// Определяем, какие логгеры будут активны
-	var (
-		debugOutput io.Writer = io.Discard
-		infoOutput  io.Writer = io.Discard
-		warnOutput  io.Writer = io.Discard
-		errorOutput io.Writer = os.Stderr
-		fatalOutput io.Writer = os.Stderr
-	)
-
-	switch level {
-	case DEBUG:
-		debugOutput = os.Stdout
-		infoOutput = os.Stdout
-		warnOutput = os.Stdout
-	case INFO:
-		infoOutput = os.Stdout
-		warnOutput = os.Stdout
-	case WARNING:
-		warnOutput = os.Stdout
-	case ERROR, FATAL:
-		// По умолчанию только error и fatal
-	}
+	debugOutput, infoOutput, warnOutput := levelOutputs(LogLevel(levelStr))
 
 	// Создаем логгеры для каждого уровня
 	debugLogger := log.New(debugOutput, "[DEBUG] ", log.Ldate|log.Ltime|log.Lshortfile)
 	infoLogger := log.New(infoOutput, "[INFO] ", log.Ldate|log.Ltime)
 	warnLogger := log.New(warnOutput, "[WARN] ", log.Ldate|log.Ltime|log.Lshortfile)
-	errorLogger := log.New(errorOutput, "[ERROR] ", log.Ldate|log.Ltime|log.Lshortfile)
-	fatalLogger := log.New(fatalOutput, "[FATAL] ", log.Ldate|log.Ltime|log.Lshortfile)
+	errorLogger := log.New(os.Stderr, "[ERROR] ", log.Ldate|log.Ltime|log.Lshortfile)
+	fatalLogger := log.New(os.Stderr, "[FATAL] ", log.Ldate|log.Ltime|log.Lshortfile)
 
 	// Переопределяем стандартный логгер для использования в других пакетах
 	log.SetOutput(infoOutput)
@@ -133,6 +110,44 @@ func NewLogger() Logger {
 	}
 }
 
+// levelOutputs возвращает вывод логгеров DEBUG, INFO и WARNING для уровня level.
+// Сообщения ERROR и FATAL выводятся всегда.
+func levelOutputs(level LogLevel) (debug, info, warn io.Writer) {
+	debug, info, warn = io.Discard, io.Discard, io.Discard
+
+	switch level {
+	case DEBUG:
+		debug, info, warn = os.Stdout, os.Stdout, os.Stdout
+	case INFO:
+		info, warn = os.Stdout, os.Stdout
+	case WARNING:
+		warn = os.Stdout
+	case ERROR, FATAL:
+		// По умолчанию только error и fatal
+	}
+
+	return debug, info, warn
+}
+
+// SetLevel переключает уровень логирования без перезапуска сервиса (например, по config.Watcher).
+// Уровень общий для логгера и всех логгеров, полученных из него через WithField, WithContext и т.п.
+func (l *DefaultLogger) SetLevel(level string) error {
+	logLevel := LogLevel(strings.ToLower(level))
+	switch logLevel {
+	case DEBUG, INFO, WARNING, ERROR, FATAL:
+	default:
+		return fmt.Errorf("unknown log level %q", level)
+	}
+
+	debugOutput, infoOutput, warnOutput := levelOutputs(logLevel)
+	l.debugLogger.SetOutput(debugOutput)
+	l.infoLogger.SetOutput(infoOutput)
+	l.warnLogger.SetOutput(warnOutput)
+	log.SetOutput(infoOutput)
+
+	return nil
+}
+
 // formatMessage форматирует сообщение с учетом полей
 func (l *DefaultLogger) formatMessage(format string, v ...interface{}) string {
 	message := fmt.Sprintf(format, v...)
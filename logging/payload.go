@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// RedactedValue заменяет значения скрываемых полей
+const RedactedValue = "***"
+
+// DefaultMaxPayloadSize максимальный размер тела в логе по умолчанию
+const DefaultMaxPayloadSize = 4096
+
+// PayloadConfig определяет, как тела запросов и ответов попадают в лог: общие настройки
+// для gRPC интерцептора и HTTP middleware логирования тел
+type PayloadConfig struct {
+	// Пути скрываемых полей через точку (например, "user.password", "card.number").
+	// Массивы проходятся прозрачно, "*" соответствует любому полю.
+	RedactFields []string
+	// Максимальный размер тела в логе в байтах после скрытия полей (0 - DefaultMaxPayloadSize)
+	MaxSize int
+}
+
+// Format возвращает тело в JSON для лога: скрывает поля RedactFields и обрезает результат до MaxSize.
+// Тела не в формате JSON не логируются, чтобы не раскрыть данные, которые нельзя скрыть.
+func (c *PayloadConfig) Format(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+
+	var fields []string
+	maxSize := DefaultMaxPayloadSize
+	if c != nil {
+		fields = c.RedactFields
+		if c.MaxSize > 0 {
+			maxSize = c.MaxSize
+		}
+	}
+
+	redacted, err := RedactJSON(data, fields)
+	if err != nil {
+		return fmt.Sprintf("<non-JSON payload, %d bytes>", len(data))
+	}
+
+	if len(redacted) > maxSize {
+		// Не разрываем многобайтовый символ UTF-8
+		cut := maxSize
+		for cut > 0 && !utf8.RuneStart(redacted[cut]) {
+			cut--
+		}
+		return fmt.Sprintf("%s... (truncated, %d bytes)", redacted[:cut], len(redacted))
+	}
+	return string(redacted)
+}
+
+// RedactJSON заменяет значения полей по путям paths (см. PayloadConfig.RedactFields) на RedactedValue
+func RedactJSON(data []byte, paths []string) ([]byte, error) {
+	if len(paths) == 0 {
+		// Проверяем формат, чтобы не логировать произвольные данные
+		if !json.Valid(data) {
+			return nil, fmt.Errorf("invalid JSON payload")
+		}
+		return data, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		value = redactPath(value, strings.Split(path, "."))
+	}
+
+	return json.Marshal(value)
+}
+
+// redactPath скрывает значение по пути segments внутри value
+func redactPath(value interface{}, segments []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if segments[0] != "*" && segments[0] != key {
+				continue
+			}
+			if len(segments) == 1 {
+				v[key] = RedactedValue
+			} else {
+				v[key] = redactPath(child, segments[1:])
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactPath(item, segments)
+		}
+		return v
+	default:
+		return value
+	}
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactJSONNested(t *testing.T) {
+	payload := `{
+		"user": {"name": "ivan", "password": "secret", "profile": {"phone": "+7900"}},
+		"cards": [{"number": "4111", "holder": "IVAN"}, {"number": "5500", "holder": "IVAN"}],
+		"password": "top-level stays",
+		"tokens": {"access": "a", "refresh": "r"}
+	}`
+
+	redacted, err := RedactJSON([]byte(payload), []string{
+		"user.password", "user.profile.phone", "cards.number", "tokens.*", "missing.field",
+	})
+	if err != nil {
+		t.Fatalf("RedactJSON() error = %v", err)
+	}
+
+	want := `{"cards":[{"holder":"IVAN","number":"***"},{"holder":"IVAN","number":"***"}],` +
+		`"password":"top-level stays",` +
+		`"tokens":{"access":"***","refresh":"***"},` +
+		`"user":{"name":"ivan","password":"***","profile":{"phone":"***"}}}`
+	if string(redacted) != want {
+		t.Errorf("RedactJSON() =\n%s\nwant\n%s", redacted, want)
+	}
+}
+
+func TestRedactJSONRedactsWholeSubtree(t *testing.T) {
+	redacted, err := RedactJSON([]byte(`{"card": {"number": "4111", "cvv": "123"}, "id": 1}`), []string{"card"})
+	if err != nil {
+		t.Fatalf("RedactJSON() error = %v", err)
+	}
+	if want := `{"card":"***","id":1}`; string(redacted) != want {
+		t.Errorf("RedactJSON() = %s, want %s", redacted, want)
+	}
+}
+
+func TestPayloadConfigFormat(t *testing.T) {
+	config := &PayloadConfig{RedactFields: []string{"password"}, MaxSize: 20}
+
+	if got := config.Format([]byte(`{"password":"x"}`)); got != `{"password":"***"}` {
+		t.Errorf("Format() = %s", got)
+	}
+
+	long := config.Format([]byte(`{"name":"` + strings.Repeat("я", 20) + `"}`))
+	if !strings.HasPrefix(long, `{"name":"яяяяя`) || !strings.Contains(long, "(truncated, 51 bytes)") {
+		t.Errorf("Format() of long payload = %s", long)
+	}
+	if strings.ContainsRune(long, '�') {
+		t.Errorf("Format() must not split UTF-8 characters: %s", long)
+	}
+
+	if got := config.Format([]byte("password=secret")); strings.Contains(got, "secret") {
+		t.Errorf("Format() of non-JSON payload = %s, must not log raw data", got)
+	}
+
+	var defaults *PayloadConfig
+	if got := defaults.Format([]byte(`{"a":1}`)); got != `{"a":1}` {
+		t.Errorf("nil config Format() = %s", got)
+	}
+}
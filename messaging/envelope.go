@@ -0,0 +1,153 @@
+package messaging
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vladzorgan/common/logging"
+)
+
+// DefaultSchemaVersion версия схемы payload, если издатель ее не указал
+const DefaultSchemaVersion = 1
+
+// EventEnvelope представляет конверт события. Формат JSON общий для всех брокеров,
+// поэтому потребители не зависят от того, через какой брокер опубликовано событие.
+// Поля, добавленные после первой версии формата, необязательны: конверты старых
+// издателей читаются через EventMetadataFromEnvelope со значениями по умолчанию.
+type EventEnvelope struct {
+	EventID     string    `json:"event_id,omitempty"`
+	EventType   string    `json:"event_type"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	ServiceName string    `json:"service_name"`
+	// Request ID исходного запроса; сохраняется для потребителей, не читающих correlation_id
+	RequestID string `json:"request_id,omitempty"`
+	// ID исходного запроса или цепочки событий
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// ID события, при обработке которого опубликовано это событие
+	CausationID   string            `json:"causation_id,omitempty"`
+	SchemaVersion int               `json:"schema_version,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	Payload       interface{}       `json:"payload"`
+}
+
+// SchemaVersioned реализуется payload, версия схемы которого отличается от DefaultSchemaVersion
+type SchemaVersioned interface {
+	SchemaVersion() int
+}
+
+// NewEnvelope создает конверт события, заполняя идентификаторы из контекста:
+// correlation ID берется из обрабатываемого события или request ID, causation ID - ID
+// обрабатываемого события, метаданные - из WithMetadata.
+func NewEnvelope(ctx context.Context, eventType, serviceName string, payload interface{}) EventEnvelope {
+	envelope := EventEnvelope{
+		EventID:       uuid.New().String(),
+		EventType:     eventType,
+		OccurredAt:    time.Now(),
+		ServiceName:   serviceName,
+		RequestID:     logging.ExtractRequestID(ctx),
+		SchemaVersion: DefaultSchemaVersion,
+		Metadata:      metadataFromContext(ctx),
+		Payload:       payload,
+	}
+	envelope.CorrelationID = envelope.RequestID
+
+	// Событие, опубликованное при обработке другого события, продолжает его цепочку
+	if parent, ok := GetEventMetadata(ctx); ok {
+		envelope.CausationID = parent.EventID
+		if parent.CorrelationID != "" {
+			envelope.CorrelationID = parent.CorrelationID
+		}
+	}
+
+	if versioned, ok := payload.(SchemaVersioned); ok {
+		envelope.SchemaVersion = versioned.SchemaVersion()
+	}
+
+	return envelope
+}
+
+// EventMetadata сведения о событии, доступные обработчику через GetEventMetadata
+type EventMetadata struct {
+	EventID       string
+	EventType     string
+	OccurredAt    time.Time
+	ServiceName   string
+	CorrelationID string
+	CausationID   string
+	SchemaVersion int
+	Metadata      map[string]string
+}
+
+// EventMetadataFromEnvelope возвращает сведения о событии. Для конвертов без event_id
+// используется fallbackID (например, ID сообщения брокера), без correlation_id - request_id,
+// без schema_version - DefaultSchemaVersion.
+func EventMetadataFromEnvelope(envelope EventEnvelope, fallbackID string) EventMetadata {
+	metadata := EventMetadata{
+		EventID:       envelope.EventID,
+		EventType:     envelope.EventType,
+		OccurredAt:    envelope.OccurredAt,
+		ServiceName:   envelope.ServiceName,
+		CorrelationID: envelope.CorrelationID,
+		CausationID:   envelope.CausationID,
+		SchemaVersion: envelope.SchemaVersion,
+		Metadata:      envelope.Metadata,
+	}
+
+	if metadata.EventID == "" {
+		metadata.EventID = fallbackID
+	}
+	if metadata.CorrelationID == "" {
+		metadata.CorrelationID = envelope.RequestID
+	}
+	if metadata.SchemaVersion == 0 {
+		metadata.SchemaVersion = DefaultSchemaVersion
+	}
+
+	return metadata
+}
+
+// Ключи контекста пакета
+type (
+	eventMetadataKey struct{}
+	metadataKey      struct{}
+)
+
+// ContextWithEventMetadata добавляет в контекст сведения об обрабатываемом событии
+func ContextWithEventMetadata(ctx context.Context, metadata EventMetadata) context.Context {
+	return context.WithValue(ctx, eventMetadataKey{}, metadata)
+}
+
+// GetEventMetadata возвращает сведения об обрабатываемом событии
+func GetEventMetadata(ctx context.Context) (EventMetadata, bool) {
+	metadata, ok := ctx.Value(eventMetadataKey{}).(EventMetadata)
+	return metadata, ok
+}
+
+// WithMetadata добавляет значения, которые будут записаны в Metadata событий,
+// опубликованных с этим контекстом. Значения дополняют ранее добавленные.
+func WithMetadata(ctx context.Context, values map[string]string) context.Context {
+	merged := metadataFromContext(ctx)
+	if merged == nil {
+		merged = make(map[string]string, len(values))
+	}
+	for key, value := range values {
+		merged[key] = value
+	}
+
+	return context.WithValue(ctx, metadataKey{}, merged)
+}
+
+// metadataFromContext возвращает копию метаданных, добавленных через WithMetadata
+func metadataFromContext(ctx context.Context) map[string]string {
+	values, _ := ctx.Value(metadataKey{}).(map[string]string)
+	if len(values) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		result[key] = value
+	}
+	return result
+}
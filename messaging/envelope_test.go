@@ -0,0 +1,70 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/vladzorgan/common/logging"
+)
+
+type orderCreatedV2 struct {
+	ID uint `json:"id"`
+}
+
+func (orderCreatedV2) SchemaVersion() int { return 2 }
+
+func TestNewEnvelopeFromRequestContext(t *testing.T) {
+	ctx := logging.ContextWithRequestID(context.Background(), "req-1")
+	ctx = WithMetadata(ctx, map[string]string{"tenant": "a"})
+	ctx = WithMetadata(ctx, map[string]string{"source": "api"})
+
+	envelope := NewEnvelope(ctx, "order.created", "orders", orderCreatedV2{ID: 5})
+
+	if envelope.EventID == "" || envelope.CausationID != "" {
+		t.Errorf("EventID = %q, CausationID = %q", envelope.EventID, envelope.CausationID)
+	}
+	if envelope.CorrelationID != "req-1" || envelope.RequestID != "req-1" {
+		t.Errorf("CorrelationID = %q, RequestID = %q, want req-1", envelope.CorrelationID, envelope.RequestID)
+	}
+	if envelope.SchemaVersion != 2 {
+		t.Errorf("SchemaVersion = %d, want 2", envelope.SchemaVersion)
+	}
+	if envelope.Metadata["tenant"] != "a" || envelope.Metadata["source"] != "api" {
+		t.Errorf("Metadata = %v", envelope.Metadata)
+	}
+}
+
+func TestNewEnvelopeContinuesEventChain(t *testing.T) {
+	ctx := ContextWithEventMetadata(context.Background(), EventMetadata{EventID: "evt-1", CorrelationID: "req-1"})
+
+	envelope := NewEnvelope(ctx, "invoice.created", "billing", map[string]int{"id": 1})
+	if envelope.CausationID != "evt-1" || envelope.CorrelationID != "req-1" {
+		t.Errorf("CausationID = %q, CorrelationID = %q", envelope.CausationID, envelope.CorrelationID)
+	}
+	if envelope.SchemaVersion != DefaultSchemaVersion || envelope.Metadata != nil {
+		t.Errorf("SchemaVersion = %d, Metadata = %v", envelope.SchemaVersion, envelope.Metadata)
+	}
+}
+
+func TestEventMetadataFromLegacyEnvelope(t *testing.T) {
+	legacy := `{"event_type":"order.created","occurred_at":"2024-01-02T03:04:05Z","service_name":"orders","request_id":"req-1","payload":{"id":5}}`
+
+	var envelope EventEnvelope
+	if err := json.Unmarshal([]byte(legacy), &envelope); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	metadata := EventMetadataFromEnvelope(envelope, "msg-1")
+	if metadata.EventID != "msg-1" || metadata.CorrelationID != "req-1" || metadata.SchemaVersion != DefaultSchemaVersion {
+		t.Errorf("metadata = %+v", metadata)
+	}
+
+	ctx := ContextWithEventMetadata(context.Background(), metadata)
+	if got, ok := GetEventMetadata(ctx); !ok || got.EventType != "order.created" {
+		t.Errorf("GetEventMetadata() = %+v, %v", got, ok)
+	}
+	if _, ok := GetEventMetadata(context.Background()); ok {
+		t.Error("GetEventMetadata() must report missing metadata")
+	}
+}
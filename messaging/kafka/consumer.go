@@ -0,0 +1,301 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/messaging"
+	"github.com/vladzorgan/common/profiling"
+	"github.com/vladzorgan/common/retry"
+	"github.com/vladzorgan/common/tracing"
+)
+
+// ErrConsumerStopped возвращается при подписке после Shutdown
+var ErrConsumerStopped = errors.New("kafka consumer is stopped")
+
+// Результаты обработки сообщений для метрики kafka_consumer_messages_total
+const (
+	resultProcessed = "processed"
+	resultRejected  = "rejected"
+	resultInvalid   = "invalid"
+	resultFailed    = "failed"
+)
+
+// consumerMessagesTotal считает обработанные сообщения по топику и результату.
+// Результат failed учитывает каждую неудачную попытку обработки.
+var consumerMessagesTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "kafka_consumer_messages_total",
+		Help: "Количество сообщений Kafka, обработанных потребителем, по топику и результату",
+	},
+	[]string{"topic", "result"},
+)
+
+// ConsumerOptions содержит опции потребителя
+type ConsumerOptions struct {
+	// Группа потребителей (по умолчанию - имя сервиса)
+	GroupID string
+	// Префикс имени топика; топик подписки - TopicPrefix + key
+	TopicPrefix string
+	// Максимальное время обработки одного сообщения (0 - 30 секунд)
+	HandlerTimeout time.Duration
+	// Начальная и максимальная задержки повторной обработки и переподключения (0 - 1 и 30 секунд)
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Consumer потребляет события из Kafka. Каждый топик читается отдельным читателем,
+// сообщения топика обрабатываются последовательно в порядке получения.
+type Consumer struct {
+	newReader   ReaderFactory
+	groupID     string
+	topicPrefix string
+	timeout     time.Duration
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	logger      logging.Logger
+
+	mutex   sync.Mutex
+	topics  map[string]bool
+	stopped bool
+	running sync.WaitGroup
+
+	// Контекст чтения отменяется в начале Shutdown, контекст обработчиков - если
+	// Shutdown не дождался их завершения
+	ctx            context.Context
+	cancel         context.CancelFunc
+	handlerCtx     context.Context
+	cancelHandlers context.CancelFunc
+}
+
+var _ messaging.Consumer = (*Consumer)(nil)
+
+// NewConsumer создает потребителя, читатели которого создает newReader
+func NewConsumer(newReader ReaderFactory, serviceName string, logger logging.Logger, options *ConsumerOptions) *Consumer {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+	if options == nil {
+		options = &ConsumerOptions{}
+	}
+
+	consumer := &Consumer{
+		newReader:   newReader,
+		groupID:     options.GroupID,
+		topicPrefix: options.TopicPrefix,
+		timeout:     options.HandlerTimeout,
+		backoff:     options.Backoff,
+		maxBackoff:  options.MaxBackoff,
+		logger:      logger,
+		topics:      make(map[string]bool),
+	}
+	if consumer.groupID == "" {
+		consumer.groupID = serviceName
+	}
+	if consumer.timeout <= 0 {
+		consumer.timeout = defaultHandlerTimeout
+	}
+	if consumer.backoff <= 0 {
+		consumer.backoff = defaultBackoff
+	}
+	if consumer.maxBackoff <= 0 {
+		consumer.maxBackoff = defaultMaxBackoff
+	}
+	consumer.ctx, consumer.cancel = context.WithCancel(context.Background())
+	consumer.handlerCtx, consumer.cancelHandlers = context.WithCancel(context.Background())
+
+	return consumer
+}
+
+// Subscribe подписывает обработчик на топик TopicPrefix + key и начинает чтение топика.
+// На один топик можно подписать только один обработчик.
+func (c *Consumer) Subscribe(key string, handler messaging.HandlerFunc) error {
+	topic := c.topicPrefix + key
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.stopped {
+		return fmt.Errorf("subscribe to %s: %w", topic, ErrConsumerStopped)
+	}
+	if c.topics[topic] {
+		return fmt.Errorf("topic %s already has a handler", topic)
+	}
+	c.topics[topic] = true
+
+	c.running.Add(1)
+	go c.consume(topic, key, handler)
+
+	return nil
+}
+
+// consume читает топик до остановки потребителя, пересоздавая читателя после ошибок
+func (c *Consumer) consume(topic, key string, handler messaging.HandlerFunc) {
+	defer c.running.Done()
+
+	backoff := retry.NewBackoff(c.backoff, c.maxBackoff)
+	for {
+		reader, err := c.newReader(topic, c.groupID)
+		if err == nil {
+			err = c.read(reader, topic, key, handler, backoff)
+			if closeErr := reader.Close(); closeErr != nil {
+				c.logger.Warn("Failed to close Kafka reader for topic %s: %v", topic, closeErr)
+			}
+		}
+		if err == nil || c.ctx.Err() != nil {
+			return
+		}
+
+		c.logger.Error("Kafka consumer for topic %s failed: %v", topic, err)
+		delay := backoff.Next()
+		c.logger.Info("Trying to reconnect to Kafka topic %s in %v...", topic, delay)
+		if retry.Sleep(c.ctx, delay) != nil {
+			return
+		}
+	}
+}
+
+// read обрабатывает сообщения читателя и фиксирует их смещения. Возвращает nil при остановке
+// потребителя и ошибку, после которой читателя нужно пересоздать.
+func (c *Consumer) read(reader Reader, topic, key string, handler messaging.HandlerFunc, backoff *retry.Backoff) error {
+	for {
+		msg, err := reader.FetchMessage(c.ctx)
+		if err != nil {
+			if c.ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to fetch message: %w", err)
+		}
+		// Соединение восстановлено
+		backoff.Reset()
+
+		if !c.process(topic, key, msg, handler) {
+			// Потребитель остановлен до успешной обработки: смещение не фиксируем,
+			// сообщение получит следующий владелец партиции
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), commitTimeout)
+		err = reader.CommitMessages(ctx, msg)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to commit offset %d of partition %d: %w", msg.Offset, msg.Partition, err)
+		}
+	}
+}
+
+// process обрабатывает сообщение, повторяя обработку после ошибок. Возвращает true,
+// если смещение сообщения можно зафиксировать, и false, если потребитель остановлен раньше.
+func (c *Consumer) process(topic, key string, msg Message, handler messaging.HandlerFunc) bool {
+	headers := headerMap(msg.Headers)
+
+	// Распаковываем конверт события; сообщения другого формата повторная обработка не исправит
+	var envelope messaging.EventEnvelope
+	if err := json.Unmarshal(msg.Value, &envelope); err != nil {
+		c.logger.Error("Failed to unmarshal message at offset %d of %s/%d: %v", msg.Offset, topic, msg.Partition, err)
+		consumerMessagesTotal.WithLabelValues(topic, resultInvalid).Inc()
+		return true
+	}
+
+	payload, err := json.Marshal(envelope.Payload)
+	if err != nil {
+		c.logger.Error("Failed to marshal payload: %v", err)
+		consumerMessagesTotal.WithLabelValues(topic, resultInvalid).Inc()
+		return true
+	}
+
+	// Сообщения без ID события идентифицируются положением в топике
+	fallbackID, _ := headers[headerEventID].(string)
+	if fallbackID == "" {
+		fallbackID = fmt.Sprintf("%s/%d/%d", topic, msg.Partition, msg.Offset)
+	}
+	metadata := messaging.EventMetadataFromEnvelope(envelope, fallbackID)
+
+	backoff := retry.NewBackoff(c.backoff, c.maxBackoff)
+	for {
+		err := c.handle(topic, key, headers, metadata, payload, handler)
+		switch {
+		case err == nil:
+			consumerMessagesTotal.WithLabelValues(topic, resultProcessed).Inc()
+			return true
+		case errors.Is(err, messaging.ErrRejectMessage):
+			c.logger.Warn("Message %s rejected by handler: %v", metadata.EventID, err)
+			consumerMessagesTotal.WithLabelValues(topic, resultRejected).Inc()
+			return true
+		}
+
+		consumerMessagesTotal.WithLabelValues(topic, resultFailed).Inc()
+		delay := backoff.Next()
+		c.logger.Error("Failed to process message %s, retrying in %v: %v", metadata.EventID, delay, err)
+		if retry.Sleep(c.ctx, delay) != nil {
+			return false
+		}
+	}
+}
+
+// handle вызывает обработчик с контекстом события, трейсом и метками pprof
+func (c *Consumer) handle(topic, key string, headers map[string]interface{}, metadata messaging.EventMetadata, payload []byte, handler messaging.HandlerFunc) error {
+	ctx, cancel := context.WithTimeout(c.handlerCtx, c.timeout)
+	defer cancel()
+
+	ctx = messaging.ContextWithEventMetadata(ctx, metadata)
+
+	// Сохраняем correlation ID издателя, чтобы корреляция проходила через цепочку publish → consume → RPC
+	requestID := metadata.CorrelationID
+	if requestID == "" {
+		requestID = metadata.EventID
+	}
+	ctx = logging.ContextWithRequestID(ctx, requestID)
+
+	ctx, span := tracing.StartKafkaConsumerSpan(ctx, headers, topic, c.groupID)
+	defer span.End()
+
+	var err error
+	profiling.Do(ctx, profiling.MessageLabels(ctx, key), func(ctx context.Context) {
+		err = handler(ctx, key, payload)
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+	}
+	return err
+}
+
+// Shutdown останавливает потребителя: прекращает чтение, дожидается завершения обработчиков
+// или истечения ctx и закрывает читателей. Смещения необработанных сообщений не фиксируются.
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	c.mutex.Lock()
+	if c.stopped {
+		c.mutex.Unlock()
+		return nil
+	}
+	c.stopped = true
+	c.mutex.Unlock()
+
+	c.logger.Info("Shutting down Kafka consumer...")
+	c.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		c.running.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		c.logger.Warn("Context deadline exceeded, cancelling in-flight message handlers")
+		err = fmt.Errorf("consumer shutdown interrupted: %v", ctx.Err())
+	}
+	c.cancelHandlers()
+
+	c.logger.Info("Kafka consumer stopped")
+	return err
+}
@@ -0,0 +1,356 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/messaging"
+)
+
+// fakeReader отдает сообщения из канала и запоминает зафиксированные смещения
+type fakeReader struct {
+	messages  chan Message
+	fetchErr  error
+	commitErr error
+
+	mu        sync.Mutex
+	committed []int64
+	closed    bool
+}
+
+func newFakeReader(messages ...Message) *fakeReader {
+	r := &fakeReader{messages: make(chan Message, len(messages)+10)}
+	for _, msg := range messages {
+		r.messages <- msg
+	}
+	return r
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (Message, error) {
+	if r.fetchErr != nil {
+		return Message{}, r.fetchErr
+	}
+	select {
+	case msg := <-r.messages:
+		return msg, nil
+	case <-ctx.Done():
+		return Message{}, ctx.Err()
+	}
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...Message) error {
+	if r.commitErr != nil {
+		return r.commitErr
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	return nil
+}
+
+func (r *fakeReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return nil
+}
+
+func (r *fakeReader) state() ([]int64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.committed...), r.closed
+}
+
+// readerFactory выдает читателей по очереди и запоминает запрошенные топик и группу
+type readerFactory struct {
+	mu      sync.Mutex
+	readers []*fakeReader
+	errs    []error
+	calls   []string
+}
+
+func (f *readerFactory) newReader(topic, groupID string) (Reader, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, topic+"@"+groupID)
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return nil, err
+	}
+	if len(f.readers) == 0 {
+		return nil, errors.New("no more readers")
+	}
+	reader := f.readers[0]
+	if len(f.readers) > 1 {
+		f.readers = f.readers[1:]
+	}
+	return reader, nil
+}
+
+func (f *readerFactory) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+func testMessage(t *testing.T, offset int64, envelope messaging.EventEnvelope) Message {
+	t.Helper()
+
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Message{Topic: "orders.order.created", Offset: offset, Value: body}
+}
+
+func newTestConsumer(f *readerFactory) *Consumer {
+	return NewConsumer(f.newReader, "billing", logging.NewLogger(), &ConsumerOptions{
+		TopicPrefix: "orders.",
+		Backoff:     time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	})
+}
+
+func shutdown(t *testing.T, consumer *Consumer) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := consumer.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
+
+func waitFor(t *testing.T, message string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal(message)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestConsumerCommitsAfterHandlerSuccess(t *testing.T) {
+	reader := newFakeReader()
+	reader.messages <- testMessage(t, 7, messaging.EventEnvelope{
+		EventID:       "event-1",
+		EventType:     "order.created",
+		CorrelationID: "corr-1",
+		Payload:       map[string]interface{}{"id": 1},
+	})
+	factory := &readerFactory{readers: []*fakeReader{reader}}
+	consumer := newTestConsumer(factory)
+
+	var calls atomic.Int32
+	commitsBeforeSuccess := make(chan int, 1)
+	err := consumer.Subscribe("order.created", func(ctx context.Context, key string, payload []byte) error {
+		if calls.Add(1) == 1 {
+			committed, _ := reader.state()
+			commitsBeforeSuccess <- len(committed)
+			return errors.New("database unavailable")
+		}
+
+		if key != "order.created" || string(payload) != `{"id":1}` {
+			t.Errorf("handler got key %q payload %s", key, payload)
+		}
+		metadata, ok := messaging.GetEventMetadata(ctx)
+		if !ok || metadata.EventID != "event-1" || metadata.CorrelationID != "corr-1" {
+			t.Errorf("event metadata = %+v, %v", metadata, ok)
+		}
+		if requestID := logging.ExtractRequestID(ctx); requestID != "corr-1" {
+			t.Errorf("request ID = %q, want corr-1", requestID)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	waitFor(t, "offset was not committed", func() bool {
+		committed, _ := reader.state()
+		return len(committed) == 1
+	})
+	shutdown(t, consumer)
+
+	if n := <-commitsBeforeSuccess; n != 0 {
+		t.Errorf("offset committed before the handler succeeded")
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("handler called %d times, want 2", n)
+	}
+	if committed, closed := reader.state(); len(committed) != 1 || committed[0] != 7 || !closed {
+		t.Errorf("committed = %v, closed = %v", committed, closed)
+	}
+	if factory.calls[0] != "orders.order.created@billing" {
+		t.Errorf("reader created for %s, want orders.order.created@billing", factory.calls[0])
+	}
+	if failed := testutil.ToFloat64(consumerMessagesTotal.WithLabelValues("orders.order.created", resultFailed)); failed < 1 {
+		t.Errorf("failed attempts metric = %v", failed)
+	}
+}
+
+func TestConsumerSkipsRejectedAndInvalidMessages(t *testing.T) {
+	invalid := Message{Topic: "orders.order.created", Offset: 1, Value: []byte("not json")}
+	rejected := testMessage(t, 2, messaging.EventEnvelope{EventID: "event-2", Payload: "x"})
+	reader := newFakeReader(invalid, rejected)
+	consumer := newTestConsumer(&readerFactory{readers: []*fakeReader{reader}})
+
+	var calls atomic.Int32
+	err := consumer.Subscribe("order.created", func(ctx context.Context, key string, payload []byte) error {
+		calls.Add(1)
+		return fmt.Errorf("unknown order: %w", messaging.ErrRejectMessage)
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	waitFor(t, "skipped messages were not committed", func() bool {
+		committed, _ := reader.state()
+		return len(committed) == 2
+	})
+	shutdown(t, consumer)
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("handler called %d times, want 1 (only for the valid envelope)", n)
+	}
+}
+
+func TestConsumerReconnects(t *testing.T) {
+	msg := testMessage(t, 3, messaging.EventEnvelope{EventID: "event-3", Payload: 1})
+
+	tests := []struct {
+		name    string
+		factory func() (*readerFactory, *fakeReader)
+	}{
+		{
+			name: "factory error",
+			factory: func() (*readerFactory, *fakeReader) {
+				reader := newFakeReader(msg)
+				return &readerFactory{errs: []error{errors.New("no brokers")}, readers: []*fakeReader{reader}}, reader
+			},
+		},
+		{
+			name: "fetch error",
+			factory: func() (*readerFactory, *fakeReader) {
+				broken := newFakeReader()
+				broken.fetchErr = errors.New("connection reset")
+				reader := newFakeReader(msg)
+				return &readerFactory{readers: []*fakeReader{broken, reader}}, reader
+			},
+		},
+		{
+			name: "commit error",
+			factory: func() (*readerFactory, *fakeReader) {
+				// Смещение не зафиксировано, поэтому новый читатель получает сообщение повторно
+				broken := newFakeReader(msg)
+				broken.commitErr = errors.New("rebalance in progress")
+				reader := newFakeReader(msg)
+				return &readerFactory{readers: []*fakeReader{broken, reader}}, reader
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory, reader := tt.factory()
+			consumer := newTestConsumer(factory)
+
+			if err := consumer.Subscribe("order.created", func(ctx context.Context, key string, payload []byte) error {
+				return nil
+			}); err != nil {
+				t.Fatalf("Subscribe() error = %v", err)
+			}
+
+			waitFor(t, "message was not committed after reconnect", func() bool {
+				committed, _ := reader.state()
+				return len(committed) == 1
+			})
+			shutdown(t, consumer)
+
+			if n := factory.callCount(); n != 2 {
+				t.Errorf("reader created %d times, want 2", n)
+			}
+		})
+	}
+}
+
+func TestConsumerShutdownLeavesFailedMessageUncommitted(t *testing.T) {
+	reader := newFakeReader(testMessage(t, 4, messaging.EventEnvelope{EventID: "event-4", Payload: 1}))
+	consumer := newTestConsumer(&readerFactory{readers: []*fakeReader{reader}})
+
+	var calls atomic.Int32
+	if err := consumer.Subscribe("order.created", func(ctx context.Context, key string, payload []byte) error {
+		calls.Add(1)
+		return errors.New("always fails")
+	}); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	waitFor(t, "handler was not retried", func() bool { return calls.Load() >= 2 })
+	shutdown(t, consumer)
+
+	if committed, closed := reader.state(); len(committed) != 0 || !closed {
+		t.Errorf("committed = %v, closed = %v; want no commits and a closed reader", committed, closed)
+	}
+}
+
+func TestConsumerShutdownCancelsHandlers(t *testing.T) {
+	reader := newFakeReader(testMessage(t, 5, messaging.EventEnvelope{EventID: "event-5", Payload: 1}))
+	consumer := newTestConsumer(&readerFactory{readers: []*fakeReader{reader}})
+
+	started := make(chan struct{})
+	if err := consumer.Subscribe("order.created", func(ctx context.Context, key string, payload []byte) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := consumer.Shutdown(ctx); err == nil {
+		t.Fatal("Shutdown() error = nil, want interrupted shutdown")
+	}
+
+	waitFor(t, "reader was not closed", func() bool {
+		_, closed := reader.state()
+		return closed
+	})
+	if committed, _ := reader.state(); len(committed) != 0 {
+		t.Errorf("committed = %v, want none", committed)
+	}
+}
+
+func TestConsumerSubscribe(t *testing.T) {
+	consumer := newTestConsumer(&readerFactory{readers: []*fakeReader{newFakeReader()}})
+	handler := func(ctx context.Context, key string, payload []byte) error { return nil }
+
+	if err := consumer.Subscribe("order.created", handler); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if err := consumer.Subscribe("order.created", handler); err == nil {
+		t.Error("second Subscribe() to the same topic error = nil")
+	}
+
+	shutdown(t, consumer)
+	if err := consumer.Subscribe("order.paid", handler); !errors.Is(err, ErrConsumerStopped) {
+		t.Errorf("Subscribe() after Shutdown error = %v, want ErrConsumerStopped", err)
+	}
+}
@@ -0,0 +1,99 @@
+// Package kafka реализует messaging.Publisher и messaging.Consumer для Kafka.
+//
+// События публикуются в том же формате messaging.EventEnvelope, что и через RabbitMQ:
+// ключ события определяет топик (TopicPrefix + key), ID события передается в заголовке
+// event-id, контекст трейса - в заголовках сообщения.
+//
+// Потребитель читает каждый топик в группе потребителей (по умолчанию - имя сервиса)
+// и фиксирует смещение только после успешной обработки сообщения. Ошибка обработчика
+// повторяется с экспоненциальной задержкой, не пропуская сообщение; сообщения, отклоненные
+// через messaging.ErrRejectMessage или не являющиеся конвертом события, пропускаются.
+// При ошибке чтения или фиксации смещения читатель пересоздается, поэтому после
+// переподключения сообщение может быть доставлено повторно и обработчики должны быть идемпотентны.
+//
+// Пакет работает с клиентом Kafka через интерфейсы Reader и Writer, повторяющие методы
+// segmentio/kafka-go. Адаптеры kafka-go (NewReaderFactory, NewWriter) собираются с тегом kafka.
+package kafka
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+const (
+	// headerEventID заголовок с ID события
+	headerEventID = "event-id"
+	// headerContentType заголовок с типом содержимого
+	headerContentType = "content-type"
+
+	// Задержки повторов обработки и переподключения по умолчанию
+	defaultBackoff    = 1 * time.Second
+	defaultMaxBackoff = 30 * time.Second
+	// defaultHandlerTimeout ограничивает время обработки сообщения, если HandlerTimeout не задан
+	defaultHandlerTimeout = 30 * time.Second
+	// commitTimeout ограничивает время фиксации смещения
+	commitTimeout = 10 * time.Second
+)
+
+// Header заголовок сообщения
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Message сообщение Kafka; поля соответствуют kafka.Message из segmentio/kafka-go
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   []Header
+	Time      time.Time
+}
+
+// Reader читает сообщения топика в группе потребителей
+type Reader interface {
+	// FetchMessage возвращает следующее сообщение, не фиксируя его смещение
+	FetchMessage(ctx context.Context) (Message, error)
+	// CommitMessages фиксирует смещения сообщений в группе потребителей
+	CommitMessages(ctx context.Context, msgs ...Message) error
+	Close() error
+}
+
+// Writer записывает сообщения в Kafka
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...Message) error
+	Close() error
+}
+
+// ReaderFactory создает читателя топика topic в группе потребителей groupID.
+// Вызывается при подписке и при каждом переподключении.
+type ReaderFactory func(topic, groupID string) (Reader, error)
+
+// headerMap возвращает заголовки сообщения в виде, принимаемом пакетом tracing
+func headerMap(headers []Header) map[string]interface{} {
+	result := make(map[string]interface{}, len(headers))
+	for _, header := range headers {
+		result[header.Key] = string(header.Value)
+	}
+	return result
+}
+
+// messageHeaders возвращает заголовки сообщения из строковых значений headers в порядке ключей
+func messageHeaders(headers map[string]interface{}) []Header {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]Header, 0, len(keys))
+	for _, key := range keys {
+		if value, ok := headers[key].(string); ok {
+			result = append(result, Header{Key: key, Value: []byte(value)})
+		}
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+//go:build kafka
+
+package kafka
+
+import (
+	"context"
+
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// NewReaderFactory создает читателей segmentio/kafka-go для брокеров brokers.
+// Смещения фиксируются синхронно при CommitMessages (CommitInterval 0).
+func NewReaderFactory(brokers []string) ReaderFactory {
+	return func(topic, groupID string) (Reader, error) {
+		return &kafkaGoReader{reader: kafkago.NewReader(kafkago.ReaderConfig{
+			Brokers: brokers,
+			GroupID: groupID,
+			Topic:   topic,
+		})}, nil
+	}
+}
+
+// NewWriter создает writer segmentio/kafka-go для брокеров brokers. Партиция выбирается
+// по ключу сообщения, запись подтверждается всеми синхронными репликами.
+func NewWriter(brokers []string) Writer {
+	return &kafkaGoWriter{writer: &kafkago.Writer{
+		Addr:         kafkago.TCP(brokers...),
+		Balancer:     &kafkago.Hash{},
+		RequiredAcks: kafkago.RequireAll,
+	}}
+}
+
+// kafkaGoReader адаптирует kafkago.Reader к Reader
+type kafkaGoReader struct {
+	reader *kafkago.Reader
+}
+
+func (r *kafkaGoReader) FetchMessage(ctx context.Context) (Message, error) {
+	msg, err := r.reader.FetchMessage(ctx)
+	if err != nil {
+		return Message{}, err
+	}
+	return fromKafkaGo(msg), nil
+}
+
+func (r *kafkaGoReader) CommitMessages(ctx context.Context, msgs ...Message) error {
+	return r.reader.CommitMessages(ctx, toKafkaGo(msgs)...)
+}
+
+func (r *kafkaGoReader) Close() error {
+	return r.reader.Close()
+}
+
+// kafkaGoWriter адаптирует kafkago.Writer к Writer
+type kafkaGoWriter struct {
+	writer *kafkago.Writer
+}
+
+func (w *kafkaGoWriter) WriteMessages(ctx context.Context, msgs ...Message) error {
+	return w.writer.WriteMessages(ctx, toKafkaGo(msgs)...)
+}
+
+func (w *kafkaGoWriter) Close() error {
+	return w.writer.Close()
+}
+
+// fromKafkaGo преобразует сообщение kafka-go в Message
+func fromKafkaGo(msg kafkago.Message) Message {
+	headers := make([]Header, 0, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers = append(headers, Header{Key: header.Key, Value: header.Value})
+	}
+
+	return Message{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   headers,
+		Time:      msg.Time,
+	}
+}
+
+// toKafkaGo преобразует сообщения в сообщения kafka-go
+func toKafkaGo(msgs []Message) []kafkago.Message {
+	result := make([]kafkago.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		headers := make([]kafkago.Header, 0, len(msg.Headers))
+		for _, header := range msg.Headers {
+			headers = append(headers, kafkago.Header{Key: header.Key, Value: header.Value})
+		}
+
+		result = append(result, kafkago.Message{
+			Topic:     msg.Topic,
+			Partition: msg.Partition,
+			Offset:    msg.Offset,
+			Key:       msg.Key,
+			Value:     msg.Value,
+			Headers:   headers,
+			Time:      msg.Time,
+		})
+	}
+	return result
+}
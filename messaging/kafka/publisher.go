@@ -0,0 +1,113 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/messaging"
+	"github.com/vladzorgan/common/tracing"
+)
+
+// ErrPublisherClosed возвращается при публикации после Close
+var ErrPublisherClosed = errors.New("kafka publisher is closed")
+
+// Publisher публикует события в Kafka
+type Publisher struct {
+	writer      Writer
+	serviceName string
+	topicPrefix string
+	logger      logging.Logger
+	closed      chan struct{}
+	closeOnce   sync.Once
+}
+
+var _ messaging.Publisher = (*Publisher)(nil)
+
+// PublisherOptions содержит опции издателя
+type PublisherOptions struct {
+	// Префикс имени топика; топик события - TopicPrefix + key
+	TopicPrefix string
+}
+
+// NewPublisher создает издателя поверх writer. Переподключение к брокерам и повторы записи
+// выполняет writer (kafka-go Writer делает это сам); ошибка записи возвращается вызывающему.
+func NewPublisher(writer Writer, serviceName string, logger logging.Logger, options *PublisherOptions) *Publisher {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+	if options == nil {
+		options = &PublisherOptions{}
+	}
+
+	return &Publisher{
+		writer:      writer,
+		serviceName: serviceName,
+		topicPrefix: options.TopicPrefix,
+		logger:      logger,
+		closed:      make(chan struct{}),
+	}
+}
+
+// partitionKey ключ контекста для ключа партиции
+type partitionKey struct{}
+
+// WithPartitionKey задает ключ партиции событий, опубликованных с этим контекстом.
+// События с одним ключом попадают в одну партицию и обрабатываются по порядку;
+// без ключа партиция выбирается по ID события.
+func WithPartitionKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, partitionKey{}, key)
+}
+
+// PublishEvent публикует событие в топик TopicPrefix + key
+func (p *Publisher) PublishEvent(ctx context.Context, key string, payload interface{}) error {
+	select {
+	case <-p.closed:
+		return fmt.Errorf("event %s not published: %w", key, ErrPublisherClosed)
+	default:
+	}
+
+	envelope := messaging.NewEnvelope(ctx, key, p.serviceName, payload)
+
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to serialize event: %v", err)
+	}
+
+	messageKey, _ := ctx.Value(partitionKey{}).(string)
+	if messageKey == "" {
+		messageKey = envelope.EventID
+	}
+
+	// Передаем ID события и контекст трейса потребителю
+	headers := tracing.InjectHeaders(ctx, map[string]interface{}{
+		headerEventID:     envelope.EventID,
+		headerContentType: "application/json",
+	})
+
+	msg := Message{
+		Topic:   p.topicPrefix + key,
+		Key:     []byte(messageKey),
+		Value:   body,
+		Headers: messageHeaders(headers),
+		Time:    envelope.OccurredAt,
+	}
+	if err := p.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("failed to publish event %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// Close закрывает writer; повторные вызовы ничего не делают
+func (p *Publisher) Close() error {
+	var err error
+	p.closeOnce.Do(func() {
+		close(p.closed)
+		err = p.writer.Close()
+	})
+	return err
+}
@@ -0,0 +1,152 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/messaging"
+)
+
+// fakeWriter запоминает записанные сообщения
+type fakeWriter struct {
+	err error
+
+	mu       sync.Mutex
+	messages []Message
+	closed   int
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func (w *fakeWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed++
+	return nil
+}
+
+func header(msg Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestPublisherWritesEnvelope(t *testing.T) {
+	writer := &fakeWriter{}
+	publisher := NewPublisher(writer, "orders", logging.NewLogger(), &PublisherOptions{TopicPrefix: "orders."})
+
+	ctx := logging.ContextWithRequestID(context.Background(), "req-1")
+	if err := publisher.PublishEvent(ctx, "order.created", map[string]int{"id": 1}); err != nil {
+		t.Fatalf("PublishEvent() error = %v", err)
+	}
+	if err := publisher.PublishEvent(WithPartitionKey(ctx, "order-1"), "order.paid", map[string]int{"id": 1}); err != nil {
+		t.Fatalf("PublishEvent() error = %v", err)
+	}
+
+	if len(writer.messages) != 2 {
+		t.Fatalf("written %d messages, want 2", len(writer.messages))
+	}
+
+	created := writer.messages[0]
+	var envelope messaging.EventEnvelope
+	if err := json.Unmarshal(created.Value, &envelope); err != nil {
+		t.Fatalf("message is not an envelope: %v", err)
+	}
+	if created.Topic != "orders.order.created" {
+		t.Errorf("topic = %q", created.Topic)
+	}
+	if envelope.EventType != "order.created" || envelope.ServiceName != "orders" || envelope.CorrelationID != "req-1" {
+		t.Errorf("envelope = %+v", envelope)
+	}
+	if envelope.EventID == "" || header(created, headerEventID) != envelope.EventID || string(created.Key) != envelope.EventID {
+		t.Errorf("event ID %q, header %q, key %q", envelope.EventID, header(created, headerEventID), created.Key)
+	}
+	if got := header(created, headerContentType); got != "application/json" {
+		t.Errorf("content-type header = %q", got)
+	}
+
+	if key := string(writer.messages[1].Key); key != "order-1" {
+		t.Errorf("partition key = %q, want order-1", key)
+	}
+}
+
+func TestPublisherErrors(t *testing.T) {
+	writeErr := errors.New("leader not available")
+	publisher := NewPublisher(&fakeWriter{err: writeErr}, "orders", nil, nil)
+	if err := publisher.PublishEvent(context.Background(), "order.created", 1); !errors.Is(err, writeErr) {
+		t.Errorf("PublishEvent() error = %v, want %v", err, writeErr)
+	}
+
+	writer := &fakeWriter{}
+	publisher = NewPublisher(writer, "orders", nil, nil)
+	publisher.Close()
+	publisher.Close()
+	if writer.closed != 1 {
+		t.Errorf("writer closed %d times, want 1", writer.closed)
+	}
+	if err := publisher.PublishEvent(context.Background(), "order.created", 1); !errors.Is(err, ErrPublisherClosed) {
+		t.Errorf("PublishEvent() after Close error = %v, want ErrPublisherClosed", err)
+	}
+}
+
+// TestPublishConsumeRoundTrip проверяет, что потребитель читает конверт издателя
+// и продолжает цепочку событий
+func TestPublishConsumeRoundTrip(t *testing.T) {
+	writer := &fakeWriter{}
+	publisher := NewPublisher(writer, "orders", nil, nil)
+
+	ctx := logging.ContextWithRequestID(context.Background(), "req-2")
+	if err := publisher.PublishEvent(ctx, "order.created", map[string]int{"id": 2}); err != nil {
+		t.Fatalf("PublishEvent() error = %v", err)
+	}
+	published := writer.messages[0]
+
+	reader := newFakeReader(published)
+	consumer := NewConsumer((&readerFactory{readers: []*fakeReader{reader}}).newReader, "billing", nil, nil)
+
+	got := make(chan messaging.EventMetadata, 1)
+	if err := consumer.Subscribe("order.created", func(ctx context.Context, key string, payload []byte) error {
+		metadata, _ := messaging.GetEventMetadata(ctx)
+		got <- metadata
+		// Событие, опубликованное обработчиком, продолжает цепочку
+		return publisher.PublishEvent(ctx, "invoice.created", map[string]int{"order": 2})
+	}); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	metadata := <-got
+	waitFor(t, "offset was not committed", func() bool {
+		committed, _ := reader.state()
+		return len(committed) == 1
+	})
+	shutdown(t, consumer)
+
+	if metadata.EventID != header(published, headerEventID) || metadata.CorrelationID != "req-2" {
+		t.Errorf("event metadata = %+v", metadata)
+	}
+
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+	var next messaging.EventEnvelope
+	if err := json.Unmarshal(writer.messages[1].Value, &next); err != nil {
+		t.Fatal(err)
+	}
+	if next.CausationID != metadata.EventID || next.CorrelationID != "req-2" {
+		t.Errorf("chained envelope = %+v", next)
+	}
+}
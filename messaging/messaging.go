@@ -0,0 +1,26 @@
+// Package messaging определяет интерфейсы публикации и потребления событий,
+// не зависящие от брокера. Реализации находятся в пакетах messaging/rabbitmq и messaging/kafka.
+package messaging
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrRejectMessage отклоняет сообщение без повторной доставки.
+// Обработчик возвращает ошибку, обернутую ErrRejectMessage, если повторная доставка не поможет.
+var ErrRejectMessage = errors.New("message rejected")
+
+// Publisher публикует событие с ключом маршрутизации key (для RabbitMQ - routing key, для Kafka - топик TopicPrefix + key)
+type Publisher interface {
+	PublishEvent(ctx context.Context, key string, payload interface{}) error
+}
+
+// HandlerFunc обрабатывает payload события, распакованный из EventEnvelope.
+// Сообщение подтверждается только при успешной обработке.
+type HandlerFunc func(ctx context.Context, key string, payload []byte) error
+
+// Consumer подписывает обработчики на события по ключу маршрутизации
+type Consumer interface {
+	Subscribe(key string, handler HandlerFunc) error
+}
@@ -3,12 +3,43 @@ package rabbitmq
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/streadway/amqp"
+	"github.com/vladzorgan/common/killswitch"
 	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/messaging"
+	"github.com/vladzorgan/common/profiling"
+	"github.com/vladzorgan/common/retry"
+	"github.com/vladzorgan/common/tracing"
+)
+
+// defaultHandlerTimeout ограничивает время обработки сообщения, если HandlerTimeout не задан
+const defaultHandlerTimeout = 30 * time.Second
+
+var (
+	// consumerInFlightMessages показывает количество сообщений, обрабатываемых в данный момент
+	consumerInFlightMessages = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rabbitmq_consumer_in_flight_messages",
+			Help: "Количество сообщений, обрабатываемых потребителем в данный момент",
+		},
+		[]string{"queue"},
+	)
+
+	// consumerMaxInFlight показывает максимальное количество одновременно обрабатываемых сообщений (Concurrency)
+	consumerMaxInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rabbitmq_consumer_max_in_flight",
+			Help: "Максимальное количество сообщений, одновременно обрабатываемых потребителем",
+		},
+		[]string{"queue"},
+	)
 )
 
 // HandlerFunc представляет функцию-обработчик сообщений
@@ -21,17 +52,37 @@ type Consumer struct {
 	exchangeName string
 	queueName    string
 	serviceName  string
+	exchangeType string
 	logger       logging.Logger
-	handlers     map[string]HandlerFunc
+	options      *ConsumerOptions
+	queues       map[string]*consumerQueue
+	middlewares  []HandlerMiddleware
 	mutex        sync.RWMutex
 	connected    bool
 	reconnecting bool
 	stopChan     chan struct{}
 	stopped      bool
+	draining     bool
+	consumerTag  string
+	inFlight     sync.WaitGroup
+	workers      chan struct{}
+	timeout      time.Duration
+	handlerCtx   context.Context
+	cancelCtx    context.CancelFunc
+	dedupStore   Deduplicator
+	dedupTTL     time.Duration
+	dedupClaim   time.Duration
 }
 
 // ConsumerOptions содержит опции для создания потребителя
 type ConsumerOptions struct {
+	// Тип обменника: amqp.ExchangeTopic (по умолчанию), amqp.ExchangeDirect, amqp.ExchangeFanout
+	// или amqp.ExchangeHeaders. Для fanout и headers ключ маршрутизации не участвует в доставке:
+	// обработчик, подписанный с пустым ключом, получает все сообщения очереди.
+	ExchangeType string
+	// Аргументы привязки очередей к обменнику (например, условия x-match для headers)
+	BindArgs map[string]interface{}
+
 	QueueDurable    bool
 	QueueAutoDelete bool
 	QueueExclusive  bool
@@ -40,11 +91,30 @@ type ConsumerOptions struct {
 	PrefetchCount   int
 	PrefetchSize    int
 	PrefetchGlobal  bool
+
+	// Количество сообщений, обрабатываемых одновременно (по умолчанию 1 - последовательная обработка).
+	// При значении больше 1 порядок обработки не гарантируется ни для очереди, ни для отдельного
+	// ключа маршрутизации: сообщения с одним ключом могут обрабатываться параллельно и подтверждаться
+	// в другом порядке. Обработчики должны быть готовы к этому или использовать отдельные очереди.
+	// PrefetchCount должен быть не меньше Concurrency, иначе часть обработчиков будет простаивать.
+	Concurrency int
+	// Максимальное время обработки одного сообщения (0 - 30 секунд)
+	HandlerTimeout time.Duration
+
+	// Хранилище обработанных сообщений (ключ - очередь и MessageId) для подавления повторных доставок
+	// (nil - без дедупликации)
+	DedupStore Deduplicator
+	// Время хранения обработанных MessageId
+	DedupTTL time.Duration
+	// Время захвата сообщения на обработку (0 - HandlerTimeout плюс минута). Если обработчик
+	// завершится аварийно и не снимет захват, повторная доставка будет обработана по его истечении.
+	DedupClaimTTL time.Duration
 }
 
 // DefaultConsumerOptions возвращает опции по умолчанию
 func DefaultConsumerOptions() *ConsumerOptions {
 	return &ConsumerOptions{
+		ExchangeType:    amqp.ExchangeTopic,
 		QueueDurable:    true,
 		QueueAutoDelete: false,
 		QueueExclusive:  false,
@@ -53,6 +123,9 @@ func DefaultConsumerOptions() *ConsumerOptions {
 		PrefetchCount:   1,
 		PrefetchSize:    0,
 		PrefetchGlobal:  false,
+		Concurrency:     1,
+		HandlerTimeout:  defaultHandlerTimeout,
+		DedupTTL:        24 * time.Hour,
 	}
 }
 
@@ -73,14 +146,61 @@ func NewConsumer(
 		options = DefaultConsumerOptions()
 	}
 
+	exchangeType, err := exchangeKind(options.ExchangeType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Аргументы неверного типа брокер все равно отклонит, поэтому не начинаем объявление
+	if err := validateQueueArgTypes(options.QueueArgs); err != nil {
+		return nil, fmt.Errorf("queue %s: %w", queueName, err)
+	}
+
+	// Для совместимости конфликты аргументов очереди только логируем
+	if err := options.Validate(); err != nil {
+		logger.Warn("Queue %s options look invalid: %v", queueName, err)
+	}
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	if options.PrefetchCount > 0 && options.PrefetchCount < concurrency {
+		logger.Warn("Queue %s prefetch count %d is lower than concurrency %d", queueName, options.PrefetchCount, concurrency)
+	}
+
+	timeout := options.HandlerTimeout
+	if timeout <= 0 {
+		timeout = defaultHandlerTimeout
+	}
+
+	dedupClaim := options.DedupClaimTTL
+	if dedupClaim <= 0 {
+		dedupClaim = timeout + time.Minute
+	}
+
+	// Базовый контекст обработчиков отменяется, если Shutdown не успел дождаться их завершения
+	handlerCtx, cancelCtx := context.WithCancel(context.Background())
+
 	consumer := &Consumer{
 		exchangeName: exchangeName,
 		queueName:    queueName,
 		serviceName:  serviceName,
+		exchangeType: exchangeType,
 		logger:       logger,
-		handlers:     make(map[string]HandlerFunc),
+		options:      options,
 		stopChan:     make(chan struct{}),
+		consumerTag:  fmt.Sprintf("%s-%d", serviceName, time.Now().UnixNano()),
+		workers:      make(chan struct{}, concurrency),
+		timeout:      timeout,
+		handlerCtx:   handlerCtx,
+		cancelCtx:    cancelCtx,
+		dedupStore:   options.DedupStore,
+		dedupTTL:     options.DedupTTL,
+		dedupClaim:   dedupClaim,
 	}
+	consumer.queues = map[string]*consumerQueue{queueName: consumer.newQueue(queueName)}
+	consumerMaxInFlight.WithLabelValues(queueName).Set(float64(concurrency))
 
 	if rabbitmqURL == "" {
 		logger.Warn("RABBITMQ_URL not set, events will not be consumed")
@@ -88,6 +208,10 @@ func NewConsumer(
 	}
 
 	if err := consumer.connect(rabbitmqURL, options); err != nil {
+		// Конфликт с существующими обменником или очередью не исправится переподключением
+		if errors.Is(err, ErrDeclarationConflict) {
+			return nil, err
+		}
 		logger.Error("Failed to connect to RabbitMQ: %v", err)
 		go consumer.reconnect(rabbitmqURL, options)
 		return consumer, nil
@@ -96,6 +220,29 @@ func NewConsumer(
 	return consumer, nil
 }
 
+// NewConsumerWithSpec создает нового потребителя с типизированным описанием очереди.
+// Ошибки описания очереди возвращаются до подключения к брокеру.
+func NewConsumerWithSpec(
+	rabbitmqURL string,
+	exchangeName string,
+	queueName string,
+	serviceName string,
+	logger logging.Logger,
+	spec *QueueSpec,
+	options *ConsumerOptions,
+) (*Consumer, error) {
+	if spec == nil {
+		spec = NewQueueSpec()
+	}
+
+	specOptions, err := spec.ConsumerOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConsumer(rabbitmqURL, exchangeName, queueName, serviceName, logger, specOptions)
+}
+
 // connect устанавливает соединение с RabbitMQ
 func (c *Consumer) connect(rabbitmqURL string, options *ConsumerOptions) error {
 	c.mutex.Lock()
@@ -129,35 +276,11 @@ func (c *Consumer) connect(rabbitmqURL string, options *ConsumerOptions) error {
 		return fmt.Errorf("failed to set QoS: %v", err)
 	}
 
-	// Объявляем обменник
-	err = channel.ExchangeDeclare(
-		c.exchangeName, // имя обменника
-		"topic",        // тип обменника
-		true,           // долговечный (durable)
-		false,          // автоудаляемый (auto-delete)
-		false,          // внутренний (internal)
-		false,          // не ждать подтверждения (no-wait)
-		nil,            // аргументы
-	)
-	if err != nil {
-		channel.Close()
-		connection.Close()
-		return fmt.Errorf("failed to declare exchange: %v", err)
-	}
-
-	// Объявляем очередь
-	_, err = channel.QueueDeclare(
-		c.queueName,             // имя очереди
-		options.QueueDurable,    // долговечная (durable)
-		options.QueueAutoDelete, // автоудаляемая (auto-delete)
-		options.QueueExclusive,  // эксклюзивная (exclusive)
-		options.QueueNoWait,     // не ждать подтверждения (no-wait)
-		options.QueueArgs,       // аргументы
-	)
-	if err != nil {
+	// Объявляем обменник и очереди
+	if err := c.declare(channel); err != nil {
 		channel.Close()
 		connection.Close()
-		return fmt.Errorf("failed to declare queue: %v", err)
+		return err
 	}
 
 	// Устанавливаем обработчик закрытия соединения
@@ -192,6 +315,9 @@ func (c *Consumer) connect(rabbitmqURL string, options *ConsumerOptions) error {
 	c.connection = connection
 	c.channel = channel
 	c.connected = true
+	for _, queue := range c.queues {
+		queue.consuming = false
+	}
 
 	c.logger.Info("Successfully connected to RabbitMQ")
 	return nil
@@ -200,7 +326,7 @@ func (c *Consumer) connect(rabbitmqURL string, options *ConsumerOptions) error {
 // reconnect пытается переподключиться к RabbitMQ
 func (c *Consumer) reconnect(rabbitmqURL string, options *ConsumerOptions) {
 	c.mutex.Lock()
-	if c.reconnecting || c.stopped {
+	if c.reconnecting || c.stopped || c.draining {
 		c.mutex.Unlock()
 		return
 	}
@@ -214,12 +340,11 @@ func (c *Consumer) reconnect(rabbitmqURL string, options *ConsumerOptions) {
 	}()
 
 	// Бесконечные попытки подключения
-	backoff := 1 * time.Second
-	maxBackoff := 30 * time.Second
+	backoff := retry.NewBackoff(reconnectBackoff, maxReconnectBackoff)
 
 	for {
 		c.mutex.RLock()
-		stopped := c.stopped
+		stopped := c.stopped || c.draining
 		c.mutex.RUnlock()
 
 		if stopped {
@@ -227,18 +352,13 @@ func (c *Consumer) reconnect(rabbitmqURL string, options *ConsumerOptions) {
 			return
 		}
 
-		c.logger.Info("Trying to reconnect to RabbitMQ in %v...", backoff)
-		time.Sleep(backoff)
+		delay := backoff.Next()
+		c.logger.Info("Trying to reconnect to RabbitMQ in %v...", delay)
+		time.Sleep(delay)
 
 		// Пытаемся подключиться
 		if err := c.connect(rabbitmqURL, options); err != nil {
 			c.logger.Error("Failed to reconnect to RabbitMQ: %v", err)
-
-			// Увеличиваем время ожидания (экспоненциальный backoff)
-			backoff *= 2
-			if backoff > maxBackoff {
-				backoff = maxBackoff
-			}
 			continue
 		}
 
@@ -256,161 +376,255 @@ func (c *Consumer) reconnect(rabbitmqURL string, options *ConsumerOptions) {
 	}
 }
 
-// resubscribe повторно подписывается на все маршруты
+// resubscribe повторно подписывается на все маршруты всех очередей
 func (c *Consumer) resubscribe() error {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
 	if !c.connected || c.channel == nil {
 		return fmt.Errorf("not connected to RabbitMQ")
 	}
 
-	// Копируем маршруты
-	routes := make([]string, 0, len(c.handlers))
-	for route := range c.handlers {
-		routes = append(routes, route)
+	if c.draining {
+		return fmt.Errorf("consumer is shutting down")
 	}
 
-	// Подписываемся на все маршруты
-	for _, route := range routes {
-		if err := c.channel.QueueBind(
-			c.queueName,    // имя очереди
-			route,          // ключ маршрутизации
-			c.exchangeName, // имя обменника
-			false,          // не ждать подтверждения (no-wait)
-			nil,            // аргументы
-		); err != nil {
-			return fmt.Errorf("failed to bind queue to exchange: %v", err)
+	for _, queue := range c.queues {
+		if len(queue.handlers) == 0 {
+			continue
 		}
-	}
 
-	// Начинаем потреблять сообщения
-	deliveries, err := c.channel.Consume(
-		c.queueName, // имя очереди
-		"",          // потребитель
-		false,       // автоматическое подтверждение
-		false,       // эксклюзивный (exclusive)
-		false,       // локальный (no-local)
-		false,       // не ждать подтверждения (no-wait)
-		nil,         // аргументы
-	)
-	if err != nil {
-		return fmt.Errorf("failed to consume from queue: %v", err)
-	}
+		// Подписываемся на все маршруты очереди
+		for route := range queue.handlers {
+			if err := c.bind(queue.name, route); err != nil {
+				return err
+			}
+		}
 
-	// Запускаем обработчик сообщений
-	go c.handleDeliveries(deliveries)
+		if err := c.consume(queue); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-// Subscribe подписывается на указанный маршрут
+// Subscribe подписывается на указанный маршрут основной очереди потребителя
 func (c *Consumer) Subscribe(routingKey string, handler HandlerFunc) error {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	// Сохраняем обработчик
-	c.handlers[routingKey] = handler
+	return c.SubscribeQueue(c.queueName, routingKey, handler)
+}
 
-	// Если не подключены, просто сохраняем обработчик
-	if !c.connected || c.channel == nil {
-		return nil
-	}
+// MessagingConsumer адаптирует Consumer к интерфейсу messaging.Consumer
+type MessagingConsumer struct {
+	consumer *Consumer
+}
 
-	// Связываем очередь с обменником
-	if err := c.channel.QueueBind(
-		c.queueName,    // имя очереди
-		routingKey,     // ключ маршрутизации
-		c.exchangeName, // имя обменника
-		false,          // не ждать подтверждения (no-wait)
-		nil,            // аргументы
-	); err != nil {
-		return fmt.Errorf("failed to bind queue to exchange: %v", err)
-	}
-
-	// Если это первая подписка, начинаем потреблять сообщения
-	if len(c.handlers) == 1 {
-		deliveries, err := c.channel.Consume(
-			c.queueName, // имя очереди
-			"",          // потребитель
-			false,       // автоматическое подтверждение
-			false,       // эксклюзивный (exclusive)
-			false,       // локальный (no-local)
-			false,       // не ждать подтверждения (no-wait)
-			nil,         // аргументы
-		)
-		if err != nil {
-			return fmt.Errorf("failed to consume from queue: %v", err)
-		}
+var _ messaging.Consumer = (*MessagingConsumer)(nil)
 
-		// Запускаем обработчик сообщений
-		go c.handleDeliveries(deliveries)
-	}
+// AsMessagingConsumer возвращает Consumer как messaging.Consumer для кода, не зависящего от брокера
+func (c *Consumer) AsMessagingConsumer() *MessagingConsumer {
+	return &MessagingConsumer{consumer: c}
+}
 
-	return nil
+// Subscribe подписывает обработчик на маршрут; ключом события передается routing key сообщения
+func (m *MessagingConsumer) Subscribe(key string, handler messaging.HandlerFunc) error {
+	return m.consumer.Subscribe(key, func(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+		return handler(ctx, delivery.RoutingKey, message)
+	})
 }
 
-// handleDeliveries обрабатывает поступающие сообщения
-func (c *Consumer) handleDeliveries(deliveries <-chan amqp.Delivery) {
+// handleDeliveries передает поступающие сообщения обработчикам. Одновременно обрабатывается
+// не более Concurrency сообщений: пока все обработчики заняты, новые сообщения не читаются.
+func (c *Consumer) handleDeliveries(queueName string, deliveries <-chan amqp.Delivery) {
 	for delivery := range deliveries {
-		// Создаем контекст с timeout
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		// Ждем свободного обработчика
+		c.workers <- struct{}{}
 
-		// Получаем обработчик для данного маршрута
+		// Регистрируем сообщение как обрабатываемое под блокировкой,
+		// чтобы Shutdown не начал ожидание раньше учета сообщения
 		c.mutex.RLock()
-		handler, ok := c.handlers[delivery.RoutingKey]
+		draining := c.draining
+		if !draining {
+			c.inFlight.Add(1)
+		}
 		c.mutex.RUnlock()
 
-		if !ok {
-			c.logger.Warn("No handler for routing key %s", delivery.RoutingKey)
-			delivery.Nack(false, false) // Не переотправляем
-			cancel()
+		if draining {
+			<-c.workers
+			// Сообщения, полученные после начала остановки, возвращаем в очередь
+			delivery.Nack(false, true)
 			continue
 		}
 
-		// Обрабатываем сообщение
-		c.logger.Debug("Processing message with routing key: %s", delivery.RoutingKey)
+		consumerInFlightMessages.WithLabelValues(queueName).Inc()
+		go c.processDelivery(queueName, delivery)
+	}
 
-		// Распаковываем конверт события
-		var envelope EventEnvelope
-		err := json.Unmarshal(delivery.Body, &envelope)
-		if err != nil {
-			c.logger.Error("Failed to unmarshal message: %v", err)
-			delivery.Nack(false, false) // Не переотправляем при ошибке формата
-			cancel()
-			continue
+	c.logger.Warn("Delivery channel for queue %s closed", queueName)
+}
+
+// processDelivery обрабатывает одно сообщение и подтверждает или отклоняет его
+func (c *Consumer) processDelivery(queueName string, delivery amqp.Delivery) {
+	defer func() {
+		consumerInFlightMessages.WithLabelValues(queueName).Dec()
+		<-c.workers
+		c.inFlight.Done()
+	}()
+
+	// Создаем контекст с timeout
+	ctx, cancel := context.WithTimeout(c.handlerCtx, c.timeout)
+	defer cancel()
+
+	// Получаем обработчик для данного маршрута
+	c.mutex.RLock()
+	handler, ok := c.handlerFor(queueName, delivery.RoutingKey)
+	c.mutex.RUnlock()
+
+	if !ok {
+		c.logger.Warn("No handler for routing key %s", delivery.RoutingKey)
+		delivery.Nack(false, false) // Не переотправляем
+		return
+	}
+
+	// Обрабатываем сообщение
+	c.logger.Debug("Processing message with routing key: %s", delivery.RoutingKey)
+
+	// Распаковываем конверт события
+	var envelope EventEnvelope
+	err := json.Unmarshal(delivery.Body, &envelope)
+	if err != nil {
+		c.logger.Error("Failed to unmarshal message: %v", err)
+		delivery.Nack(false, false) // Не переотправляем при ошибке формата
+		return
+	}
+
+	// Преобразуем payload в JSON
+	payload, err := json.Marshal(envelope.Payload)
+	if err != nil {
+		c.logger.Error("Failed to marshal payload: %v", err)
+		delivery.Nack(false, false)
+		return
+	}
+
+	// Обогащаем контекст данными события (см. messaging.GetEventMetadata)
+	metadata := messaging.EventMetadataFromEnvelope(envelope, delivery.MessageId)
+	ctx = messaging.ContextWithEventMetadata(ctx, metadata)
+
+	// Сохраняем correlation ID издателя, чтобы корреляция проходила через цепочку publish → consume → RPC
+	requestID := metadata.CorrelationID
+	if requestID == "" {
+		requestID = delivery.MessageId
+	}
+	ctx = logging.ContextWithRequestID(ctx, requestID)
+
+	// Продолжаем трейс издателя
+	ctx, span := tracing.StartConsumerSpan(ctx, delivery.Headers, delivery.RoutingKey, queueName)
+	defer span.End()
+
+	// Пропускаем уже обработанные сообщения. Сообщение захватывается только на время обработки
+	// и помечается обработанным после успешного завершения обработчика. Одно событие может прийти
+	// в несколько очередей потребителя, поэтому ключ захвата включает имя очереди.
+	claimed := false
+	dedupKey := queueName + ":" + delivery.MessageId
+	if c.dedupStore != nil && delivery.MessageId != "" && !killswitch.IsDisabled(killswitch.FeatureConsumerDedup) {
+		status, err := c.dedupStore.Claim(ctx, dedupKey, c.dedupClaim)
+		switch {
+		case err != nil:
+			// При недоступности хранилища обрабатываем сообщение
+			c.logger.Warn("Failed to check message %s for duplicates: %v", delivery.MessageId, err)
+		case status == ClaimCompleted:
+			c.logger.Debug("Duplicate message %s acknowledged without processing", delivery.MessageId)
+			duplicateMessagesTotal.WithLabelValues(queueName).Inc()
+			delivery.Ack(false)
+			return
+		case status == ClaimInProgress:
+			// Сообщение еще обрабатывается: подтверждать его нельзя, пока не известен результат
+			c.logger.Debug("Message %s is being processed by another handler, requeueing", delivery.MessageId)
+			delivery.Nack(false, true)
+			return
+		default:
+			claimed = true
 		}
+	}
 
-		// Преобразуем payload в JSON
-		payload, err := json.Marshal(envelope.Payload)
-		if err != nil {
-			c.logger.Error("Failed to marshal payload: %v", err)
-			delivery.Nack(false, false)
-			cancel()
-			continue
+	// Вызываем обработчик с middleware и метками pprof
+	profiling.Do(ctx, profiling.MessageLabels(ctx, delivery.RoutingKey), func(ctx context.Context) {
+		err = c.wrapHandler(handler)(ctx, delivery, payload)
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		c.logger.Error("Failed to process message: %v", err)
+		// Снимаем захват, чтобы повторная доставка была обработана
+		if claimed {
+			if err := c.dedupStore.Release(context.Background(), dedupKey); err != nil {
+				c.logger.Warn("Failed to release message %s: %v", delivery.MessageId, err)
+			}
 		}
+		// При ошибке обработки ставим сообщение обратно в очередь, если его не отклонил обработчик.
+		// Можно также реализовать DLX (Dead Letter Exchange) для обработки ошибок
+		delivery.Nack(false, !errors.Is(err, ErrRejectMessage))
+		return
+	}
 
-		// Обогащаем контекст данными события
-		ctx = context.WithValue(ctx, "event_type", envelope.EventType)
-		ctx = context.WithValue(ctx, "occurred_at", envelope.OccurredAt)
-		ctx = context.WithValue(ctx, "service_name", envelope.ServiceName)
-		ctx = logging.ContextWithRequestID(ctx, delivery.MessageId)
-
-		// Вызываем обработчик
-		err = handler(ctx, delivery, payload)
-		if err != nil {
-			c.logger.Error("Failed to process message: %v", err)
-			// При ошибке обработки ставим сообщение обратно в очередь
-			// Можно также реализовать DLX (Dead Letter Exchange) для обработки ошибок
-			delivery.Nack(false, true)
-		} else {
-			delivery.Ack(false)
+	if claimed {
+		if err := c.dedupStore.Complete(context.Background(), dedupKey, c.dedupTTL); err != nil {
+			c.logger.Warn("Failed to mark message %s as processed: %v", delivery.MessageId, err)
+		}
+	}
+	delivery.Ack(false)
+}
+
+// Shutdown корректно останавливает потребителя: прекращает получение новых сообщений,
+// дожидается завершения обрабатываемых сообщений или истечения ctx и закрывает соединение.
+// Неподтвержденные к моменту закрытия сообщения RabbitMQ вернет в очередь.
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	c.mutex.Lock()
+	if c.stopped || c.draining {
+		c.mutex.Unlock()
+		return nil
+	}
+	c.draining = true
+	channel := c.channel
+	tags := make([]string, 0, len(c.queues))
+	for _, queue := range c.queues {
+		if queue.consuming {
+			tags = append(tags, queue.consumerTag)
+		}
+	}
+	c.mutex.Unlock()
+
+	c.logger.Info("Shutting down consumer...")
+
+	// Прекращаем получение новых сообщений из всех очередей
+	if channel != nil {
+		for _, tag := range tags {
+			if err := channel.Cancel(tag, false); err != nil {
+				c.logger.Warn("Failed to cancel consumer %s: %v", tag, err)
+			}
 		}
+	}
 
-		cancel()
+	// Ждем завершения обрабатываемых сообщений
+	done := make(chan struct{})
+	go func() {
+		c.inFlight.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+		c.logger.Info("All in-flight messages processed")
+	case <-ctx.Done():
+		c.logger.Warn("Context deadline exceeded, cancelling in-flight message handlers")
+		err = fmt.Errorf("consumer shutdown interrupted: %v", ctx.Err())
 	}
 
-	c.logger.Warn("Delivery channel closed")
+	c.cancelCtx()
+	c.Close()
+
+	c.logger.Info("Consumer stopped")
+	return err
 }
 
 // Close закрывает соединение с RabbitMQ
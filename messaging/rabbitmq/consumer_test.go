@@ -0,0 +1,259 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/streadway/amqp"
+	"github.com/vladzorgan/common/logging"
+)
+
+// recordingAcknowledger запоминает подтверждения сообщений
+type recordingAcknowledger struct {
+	mu       sync.Mutex
+	acks     []uint64
+	nacks    []uint64
+	requeued []uint64
+}
+
+func (a *recordingAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.acks = append(a.acks, tag)
+	return nil
+}
+
+func (a *recordingAcknowledger) Nack(tag uint64, multiple bool, requeue bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nacks = append(a.nacks, tag)
+	if requeue {
+		a.requeued = append(a.requeued, tag)
+	}
+	return nil
+}
+
+func (a *recordingAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+func (a *recordingAcknowledger) counts() (int, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.acks), len(a.nacks)
+}
+
+func testDelivery(t *testing.T, ack amqp.Acknowledger, tag uint64) amqp.Delivery {
+	t.Helper()
+
+	body, err := json.Marshal(EventEnvelope{EventType: "order.created", Payload: map[string]interface{}{"id": tag}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return amqp.Delivery{Acknowledger: ack, DeliveryTag: tag, RoutingKey: "order.created", Body: body}
+}
+
+func TestConsumerProcessesConcurrently(t *testing.T) {
+	options := DefaultConsumerOptions()
+	options.PrefetchCount = 3
+	options.Concurrency = 3
+	consumer, err := NewConsumer("", "events", "concurrency-queue", "test", logging.NewLogger(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	consumer.Subscribe("order.created", func(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if current <= old || peak.CompareAndSwap(old, current) {
+				break
+			}
+		}
+		<-release
+		return nil
+	})
+
+	ack := &recordingAcknowledger{}
+	deliveries := make(chan amqp.Delivery, 5)
+	for tag := uint64(1); tag <= 5; tag++ {
+		deliveries <- testDelivery(t, ack, tag)
+	}
+	close(deliveries)
+	go consumer.handleDeliveries(consumer.queueName, deliveries)
+
+	waitFor(t, func() bool { return running.Load() == 3 })
+	// Пока все обработчики заняты, следующие сообщения не берутся в работу
+	time.Sleep(20 * time.Millisecond)
+	if got := running.Load(); got != 3 {
+		t.Fatalf("running handlers = %d, want 3", got)
+	}
+	if got := testutil.ToFloat64(consumerInFlightMessages.WithLabelValues("concurrency-queue")); got != 3 {
+		t.Errorf("in-flight gauge = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(consumerMaxInFlight.WithLabelValues("concurrency-queue")); got != 3 {
+		t.Errorf("max in-flight gauge = %v, want 3", got)
+	}
+
+	close(release)
+	waitFor(t, func() bool { acks, _ := ack.counts(); return acks == 5 })
+
+	if got := peak.Load(); got != 3 {
+		t.Errorf("peak concurrency = %d, want 3", got)
+	}
+	if _, nacks := ack.counts(); nacks != 0 {
+		t.Errorf("nacks = %d, want 0", nacks)
+	}
+}
+
+func TestConsumerShutdownWaitsForWorkers(t *testing.T) {
+	options := DefaultConsumerOptions()
+	options.Concurrency = 2
+	options.HandlerTimeout = time.Minute
+	consumer, err := NewConsumer("", "events", "shutdown-queue", "test", logging.NewLogger(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var finished atomic.Int32
+	started := make(chan struct{}, 2)
+	consumer.Subscribe("order.created", func(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+		if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) < 30*time.Second {
+			t.Errorf("handler deadline = %v, want HandlerTimeout", deadline)
+		}
+		started <- struct{}{}
+		time.Sleep(30 * time.Millisecond)
+		finished.Add(1)
+		return nil
+	})
+
+	ack := &recordingAcknowledger{}
+	deliveries := make(chan amqp.Delivery, 2)
+	deliveries <- testDelivery(t, ack, 1)
+	deliveries <- testDelivery(t, ack, 2)
+	close(deliveries)
+	go consumer.handleDeliveries(consumer.queueName, deliveries)
+
+	<-started
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := consumer.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if got := finished.Load(); got != 2 {
+		t.Errorf("finished handlers after Shutdown = %d, want 2", got)
+	}
+	if acks, _ := ack.counts(); acks != 2 {
+		t.Errorf("acks = %d, want 2", acks)
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestConsumerSubscribeQueueRoutesByQueue(t *testing.T) {
+	options := DefaultConsumerOptions()
+	options.ExchangeType = amqp.ExchangeFanout
+	consumer, err := NewConsumer("", "broadcast", "orders-queue", "test", logging.NewLogger(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	calls := map[string]int{}
+	record := func(name string) HandlerFunc {
+		return func(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+			mu.Lock()
+			defer mu.Unlock()
+			calls[name]++
+			return nil
+		}
+	}
+	if err := consumer.Subscribe("", record("orders")); err != nil {
+		t.Fatal(err)
+	}
+	if err := consumer.SubscribeQueue("cache-queue", "", record("cache")); err != nil {
+		t.Fatal(err)
+	}
+
+	ack := &recordingAcknowledger{}
+	for _, queue := range []string{"orders-queue", "cache-queue", "cache-queue"} {
+		deliveries := make(chan amqp.Delivery, 1)
+		deliveries <- testDelivery(t, ack, 1)
+		close(deliveries)
+		consumer.handleDeliveries(queue, deliveries)
+	}
+	waitFor(t, func() bool { acks, _ := ack.counts(); return acks == 3 })
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls["orders"] != 1 || calls["cache"] != 2 {
+		t.Errorf("calls = %v, want orders:1 cache:2", calls)
+	}
+	if tag := consumer.queues["cache-queue"].consumerTag; tag != consumer.consumerTag+"-cache-queue" {
+		t.Errorf("cache queue consumer tag = %s", tag)
+	}
+}
+
+func TestQueueHandlerForExchangeTypes(t *testing.T) {
+	noop := func(ctx context.Context, delivery amqp.Delivery, message []byte) error { return nil }
+	queue := &consumerQueue{handlers: map[string]HandlerFunc{"order.*": noop}}
+
+	if _, ok := queue.handlerFor("order.created", amqp.ExchangeTopic); !ok {
+		t.Error("topic exchange must match patterns")
+	}
+	if _, ok := queue.handlerFor("order.created", amqp.ExchangeDirect); ok {
+		t.Error("direct exchange must not treat keys as patterns")
+	}
+	if _, ok := queue.handlerFor("order.created", amqp.ExchangeFanout); ok {
+		t.Error("fanout exchange without catch-all handler must not match")
+	}
+
+	queue.handlers[""] = noop
+	if _, ok := queue.handlerFor("anything", amqp.ExchangeFanout); !ok {
+		t.Error("fanout exchange must use catch-all handler")
+	}
+}
+
+func TestNewConsumerRejectsUnknownExchangeType(t *testing.T) {
+	options := DefaultConsumerOptions()
+	options.ExchangeType = "x-delayed"
+	if _, err := NewConsumer("", "events", "queue", "test", logging.NewLogger(), options); err == nil {
+		t.Fatal("NewConsumer() must reject unsupported exchange type")
+	}
+}
+
+func TestDeclarationErrorConflict(t *testing.T) {
+	conflict := &amqp.Error{Code: amqp.PreconditionFailed, Reason: "PRECONDITION_FAILED - inequivalent arg 'type' for exchange 'events'"}
+
+	err := declarationError("exchange", "events", amqp.ExchangeFanout, conflict)
+	if !errors.Is(err, ErrDeclarationConflict) || !strings.Contains(err.Error(), "declared as fanout") {
+		t.Errorf("declarationError() = %v, want ErrDeclarationConflict", err)
+	}
+
+	err = declarationError("queue", "orders", "", &amqp.Error{Code: amqp.ChannelError, Reason: "channel closed"})
+	if errors.Is(err, ErrDeclarationConflict) {
+		t.Errorf("declarationError() = %v, must not be a conflict", err)
+	}
+}
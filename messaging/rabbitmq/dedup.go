@@ -0,0 +1,120 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vladzorgan/common/redis"
+)
+
+// duplicateMessagesTotal считает сообщения, отброшенные как повторные
+var duplicateMessagesTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "duplicate_messages_total",
+		Help: "Количество повторно доставленных сообщений, подтвержденных без обработки",
+	},
+	[]string{"queue"},
+)
+
+// ClaimStatus результат попытки захватить сообщение для обработки
+type ClaimStatus int
+
+const (
+	// ClaimAcquired сообщение захвачено для обработки
+	ClaimAcquired ClaimStatus = iota
+	// ClaimInProgress сообщение уже обрабатывается другим обработчиком
+	ClaimInProgress
+	// ClaimCompleted сообщение уже успешно обработано
+	ClaimCompleted
+)
+
+// Значения ключей дедупликации
+const (
+	dedupInProgress = "processing"
+	dedupCompleted  = "done"
+)
+
+// Deduplicator определяет хранилище идентификаторов обработанных сообщений.
+// Сообщение сначала захватывается на короткое время обработки, после успешной обработки
+// помечается обработанным на долгий срок, а при ошибке освобождается для повторной доставки.
+type Deduplicator interface {
+	// Claim захватывает сообщение на время ttl, если оно еще не захвачено и не обработано
+	Claim(ctx context.Context, messageID string, ttl time.Duration) (ClaimStatus, error)
+	// Complete помечает сообщение обработанным на время ttl
+	Complete(ctx context.Context, messageID string, ttl time.Duration) error
+	// Release снимает захват, чтобы повторная доставка после ошибки была обработана.
+	// Пометка обработанного сообщения не снимается.
+	Release(ctx context.Context, messageID string) error
+}
+
+// claimScript захватывает ключ, если его нет, иначе возвращает состояние существующего ключа
+var claimScript = goredis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 0
+end
+if redis.call("GET", KEYS[1]) == ARGV[3] then
+	return 2
+end
+return 1
+`)
+
+// releaseScript удаляет ключ, только если сообщение еще обрабатывается
+var releaseScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisDeduplicator реализует Deduplicator на основе SET NX в Redis
+type RedisDeduplicator struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedisDeduplicator создает новый RedisDeduplicator
+func NewRedisDeduplicator(client *redis.Client, keyPrefix string) *RedisDeduplicator {
+	if keyPrefix == "" {
+		keyPrefix = "dedup"
+	}
+
+	return &RedisDeduplicator{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+// Claim захватывает сообщение для обработки
+func (d *RedisDeduplicator) Claim(ctx context.Context, messageID string, ttl time.Duration) (ClaimStatus, error) {
+	status, err := claimScript.Run(ctx, d.client.Client(), []string{d.key(messageID)},
+		dedupInProgress, ttl.Milliseconds(), dedupCompleted).Int()
+	if err != nil {
+		return ClaimAcquired, fmt.Errorf("failed to claim message in Redis: %v", err)
+	}
+	return ClaimStatus(status), nil
+}
+
+// Complete помечает сообщение обработанным
+func (d *RedisDeduplicator) Complete(ctx context.Context, messageID string, ttl time.Duration) error {
+	if err := d.client.Client().Set(ctx, d.key(messageID), dedupCompleted, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to complete message in Redis: %v", err)
+	}
+	return nil
+}
+
+// Release снимает захват с сообщения
+func (d *RedisDeduplicator) Release(ctx context.Context, messageID string) error {
+	if err := releaseScript.Run(ctx, d.client.Client(), []string{d.key(messageID)}, dedupInProgress).Err(); err != nil {
+		return fmt.Errorf("failed to release message in Redis: %v", err)
+	}
+	return nil
+}
+
+// key формирует ключ Redis для сообщения
+func (d *RedisDeduplicator) key(messageID string) string {
+	return d.keyPrefix + ":" + messageID
+}
@@ -0,0 +1,196 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/streadway/amqp"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/redis"
+)
+
+func newTestDeduplicator(t *testing.T) (*RedisDeduplicator, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client, err := redis.NewClient(server.Addr(), "", 0, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisDeduplicator(client, "test"), server
+}
+
+func TestRedisDeduplicatorLifecycle(t *testing.T) {
+	dedup, server := newTestDeduplicator(t)
+	ctx := context.Background()
+
+	claim := func(want ClaimStatus) {
+		t.Helper()
+		status, err := dedup.Claim(ctx, "m1", time.Minute)
+		if err != nil || status != want {
+			t.Fatalf("Claim() = %v, %v; want %v", status, err, want)
+		}
+	}
+
+	claim(ClaimAcquired)
+	claim(ClaimInProgress)
+
+	// Захват истекает, если обработчик не завершился
+	server.FastForward(2 * time.Minute)
+	claim(ClaimAcquired)
+
+	// Освобожденное сообщение снова доступно для обработки
+	if err := dedup.Release(ctx, "m1"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	claim(ClaimAcquired)
+
+	if err := dedup.Complete(ctx, "m1", time.Hour); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	claim(ClaimCompleted)
+
+	// Пометка обработанного сообщения не снимается и хранится DedupTTL
+	if err := dedup.Release(ctx, "m1"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	claim(ClaimCompleted)
+	if ttl := server.TTL("test:m1"); ttl != time.Hour {
+		t.Errorf("completed TTL = %v, want 1h", ttl)
+	}
+}
+
+func TestConsumerDedupLifecycle(t *testing.T) {
+	dedup, server := newTestDeduplicator(t)
+
+	options := DefaultConsumerOptions()
+	options.DedupStore = dedup
+	consumer, err := NewConsumer("", "events", "dedup-queue", "test", logging.NewLogger(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	fail := atomic.Bool{}
+	fail.Store(true)
+	consumer.Subscribe("order.created", func(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+		calls.Add(1)
+		if fail.Load() {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+
+	ack := &recordingAcknowledger{}
+	deliver := func(tag uint64) {
+		delivery := testDelivery(t, ack, tag)
+		delivery.MessageId = "m1"
+		consumer.workers <- struct{}{}
+		consumer.inFlight.Add(1)
+		consumer.processDelivery(consumer.queueName, delivery)
+	}
+
+	// Ошибка обработчика снимает захват: повторная доставка обрабатывается
+	deliver(1)
+	if server.Exists("test:dedup-queue:m1") {
+		t.Fatal("claim must be released after handler error")
+	}
+	fail.Store(false)
+	deliver(2)
+
+	// После успешной обработки повтор подтверждается без вызова обработчика
+	deliver(3)
+	if got := calls.Load(); got != 2 {
+		t.Errorf("handler calls = %d, want 2", got)
+	}
+	acks, nacks := ack.counts()
+	if acks != 2 || nacks != 1 {
+		t.Errorf("acks = %d, nacks = %d; want 2, 1", acks, nacks)
+	}
+	if ttl := server.TTL("test:dedup-queue:m1"); ttl != options.DedupTTL {
+		t.Errorf("completed TTL = %v, want %v", ttl, options.DedupTTL)
+	}
+}
+
+func TestConsumerDedupRequeuesInProgress(t *testing.T) {
+	dedup, server := newTestDeduplicator(t)
+
+	options := DefaultConsumerOptions()
+	options.DedupStore = dedup
+	options.HandlerTimeout = time.Second
+	consumer, err := NewConsumer("", "events", "dedup-progress-queue", "test", logging.NewLogger(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	consumer.Subscribe("order.created", func(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+		calls.Add(1)
+		return nil
+	})
+
+	// Сообщение захвачено другим экземпляром, который еще не завершил обработку
+	if status, err := dedup.Claim(context.Background(), "dedup-progress-queue:m1", consumer.dedupClaim); err != nil || status != ClaimAcquired {
+		t.Fatalf("Claim() = %v, %v", status, err)
+	}
+	if ttl := server.TTL("test:dedup-progress-queue:m1"); ttl != time.Second+time.Minute {
+		t.Errorf("claim TTL = %v, want HandlerTimeout plus a minute", ttl)
+	}
+
+	ack := &recordingAcknowledger{}
+	delivery := testDelivery(t, ack, 1)
+	delivery.MessageId = "m1"
+	consumer.workers <- struct{}{}
+	consumer.inFlight.Add(1)
+	consumer.processDelivery(consumer.queueName, delivery)
+
+	if got := calls.Load(); got != 0 {
+		t.Errorf("handler calls = %d, want 0", got)
+	}
+	ack.mu.Lock()
+	defer ack.mu.Unlock()
+	if len(ack.acks) != 0 || len(ack.requeued) != 1 {
+		t.Errorf("acks = %v, requeued = %v; want message requeued", ack.acks, ack.requeued)
+	}
+}
+
+func TestConsumerDedupKeyedByQueue(t *testing.T) {
+	dedup, _ := newTestDeduplicator(t)
+
+	options := DefaultConsumerOptions()
+	options.DedupStore = dedup
+	consumer, err := NewConsumer("", "events", "dedup-main-queue", "test", logging.NewLogger(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	handler := func(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+		calls.Add(1)
+		return nil
+	}
+	consumer.Subscribe("order.created", handler)
+	if err := consumer.SubscribeQueue("dedup-audit-queue", "order.created", handler); err != nil {
+		t.Fatalf("SubscribeQueue() error = %v", err)
+	}
+
+	// Одно событие, доставленное в две очереди, обрабатывается в каждой из них
+	ack := &recordingAcknowledger{}
+	for i, queueName := range []string{"dedup-main-queue", "dedup-audit-queue"} {
+		delivery := testDelivery(t, ack, uint64(i+1))
+		delivery.MessageId = "m1"
+		consumer.workers <- struct{}{}
+		consumer.inFlight.Add(1)
+		consumer.processDelivery(queueName, delivery)
+	}
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("handler calls = %d, want 2", got)
+	}
+}
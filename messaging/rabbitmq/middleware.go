@@ -0,0 +1,143 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/streadway/amqp"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/messaging"
+)
+
+// ErrRejectMessage отклоняет сообщение без возврата в очередь (см. messaging.ErrRejectMessage)
+var ErrRejectMessage = messaging.ErrRejectMessage
+
+// HandlerMiddleware оборачивает обработчик сообщений
+type HandlerMiddleware func(next HandlerFunc) HandlerFunc
+
+var (
+	// messagesProcessedTotal считает обработанные сообщения по результату
+	messagesProcessedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "messages_processed_total",
+			Help: "Количество обработанных сообщений",
+		},
+		[]string{"routing_key", "status"},
+	)
+
+	// messageProcessingDuration измеряет длительность обработки сообщений
+	messageProcessingDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "message_processing_duration_seconds",
+			Help:    "Длительность обработки сообщений в секундах",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"routing_key"},
+	)
+)
+
+// Use добавляет middleware обработчиков. Middleware применяются ко всем обработчикам
+// в порядке добавления: первый добавленный оборачивает остальные.
+func (c *Consumer) Use(middlewares ...HandlerMiddleware) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// wrapHandler применяет middleware к обработчику. Восстановление после паники
+// устанавливается всегда: вокруг обработчика и вокруг всей цепочки, чтобы паника
+// ни в обработчике, ни в middleware не остановила обработку очереди.
+func (c *Consumer) wrapHandler(handler HandlerFunc) HandlerFunc {
+	c.mutex.RLock()
+	middlewares := c.middlewares
+	c.mutex.RUnlock()
+
+	recovery := RecoveryMiddleware(c.logger)
+
+	handler = recovery(handler)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	if len(middlewares) > 0 {
+		handler = recovery(handler)
+	}
+
+	return handler
+}
+
+// RecoveryMiddleware восстанавливается после паники в обработчике: логирует стек
+// и отклоняет сообщение без возврата в очередь (ErrRejectMessage)
+func RecoveryMiddleware(logger logging.Logger) HandlerMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, delivery amqp.Delivery, message []byte) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.WithContext(ctx).
+						WithField("routing_key", delivery.RoutingKey).
+						WithField("message_id", delivery.MessageId).
+						Error("Panic in message handler: %v\n%s", r, debug.Stack())
+					err = fmt.Errorf("%w: panic: %v", ErrRejectMessage, r)
+				}
+			}()
+
+			return next(ctx, delivery, message)
+		}
+	}
+}
+
+// LoggingMiddleware логирует ключ маршрутизации, длительность и результат обработки сообщения
+func LoggingMiddleware(logger logging.Logger) HandlerMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+			start := time.Now()
+			err := next(ctx, delivery, message)
+
+			entry := logger.WithContext(ctx).WithFields(map[string]interface{}{
+				"routing_key": delivery.RoutingKey,
+				"message_id":  delivery.MessageId,
+				"duration_ms": time.Since(start).Milliseconds(),
+				"status":      processingStatus(err),
+			})
+			if err != nil {
+				entry.WithError(err).Warn("Message processing failed")
+			} else {
+				entry.Info("Message processed")
+			}
+
+			return err
+		}
+	}
+}
+
+// MetricsMiddleware собирает метрики messages_processed_total и message_processing_duration_seconds
+func MetricsMiddleware() HandlerMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+			start := time.Now()
+			err := next(ctx, delivery, message)
+
+			messageProcessingDuration.WithLabelValues(delivery.RoutingKey).Observe(time.Since(start).Seconds())
+			messagesProcessedTotal.WithLabelValues(delivery.RoutingKey, processingStatus(err)).Inc()
+
+			return err
+		}
+	}
+}
+
+// processingStatus возвращает результат обработки для логов и метрик
+func processingStatus(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, ErrRejectMessage):
+		return "rejected"
+	default:
+		return "error"
+	}
+}
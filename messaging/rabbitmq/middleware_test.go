@@ -0,0 +1,77 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/vladzorgan/common/logging"
+)
+
+func newTestConsumer() *Consumer {
+	return &Consumer{logger: logging.NewLogger()}
+}
+
+func TestRecoveryInstalledByDefault(t *testing.T) {
+	c := newTestConsumer()
+
+	handler := c.wrapHandler(func(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+		panic("bad payload")
+	})
+
+	err := handler(context.Background(), amqp.Delivery{RoutingKey: "city.created"}, nil)
+	if !errors.Is(err, ErrRejectMessage) {
+		t.Fatalf("handler error = %v, want ErrRejectMessage", err)
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	c := newTestConsumer()
+
+	var calls []string
+	trace := func(name string) HandlerMiddleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+				calls = append(calls, name+":before")
+				err := next(ctx, delivery, message)
+				calls = append(calls, name+":after")
+				return err
+			}
+		}
+	}
+	c.Use(trace("outer"), trace("inner"))
+
+	handler := c.wrapHandler(func(ctx context.Context, delivery amqp.Delivery, message []byte) error {
+		calls = append(calls, "handler")
+		panic("boom")
+	})
+
+	if err := handler(context.Background(), amqp.Delivery{}, nil); !errors.Is(err, ErrRejectMessage) {
+		t.Fatalf("handler error = %v, want ErrRejectMessage", err)
+	}
+
+	// Паника обработчика перехватывается до middleware, поэтому они видят ошибку
+	want := "outer:before,inner:before,handler,inner:after,outer:after"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestProcessingStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, "success"},
+		{errors.New("timeout"), "error"},
+		{ErrRejectMessage, "rejected"},
+	}
+
+	for _, tt := range tests {
+		if got := processingStatus(tt.err); got != tt.want {
+			t.Errorf("processingStatus(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package rabbitmq
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/streadway/amqp"
+	"github.com/vladzorgan/common/killswitch"
+)
+
+// ErrPublishBufferFull возвращается, если RabbitMQ недоступен и буфер неотправленных сообщений заполнен
+var ErrPublishBufferFull = errors.New("publish buffer is full")
+
+// ErrNotConnected возвращается издателем в строгом режиме (PublisherOptions.StrictMode),
+// если соединение с RabbitMQ не установлено и событие не может быть буферизовано
+var ErrNotConnected = errors.New("RabbitMQ not connected")
+
+var (
+	// bufferedMessages показывает количество сообщений, ожидающих переподключения
+	bufferedMessages = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rabbitmq_publisher_buffered_messages",
+			Help: "Number of messages buffered while RabbitMQ is unavailable",
+		},
+		[]string{"exchange"},
+	)
+
+	// droppedMessagesTotal считает сообщения, не помещенные в заполненный буфер
+	droppedMessagesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rabbitmq_publisher_dropped_messages_total",
+			Help: "Total number of messages dropped because the publish buffer was full",
+		},
+		[]string{"exchange"},
+	)
+
+	// eventsDroppedTotal считает события, не отправленные из-за отсутствия соединения в нестрогом режиме
+	eventsDroppedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "events_dropped_total",
+			Help: "Total number of events silently dropped because RabbitMQ was not connected",
+		},
+		[]string{"exchange", "routing_key"},
+	)
+)
+
+// PublisherOptions содержит опции издателя
+type PublisherOptions struct {
+	// Максимальное количество сообщений, буферизуемых в памяти при недоступности RabbitMQ.
+	// 0 - буферизация отключена, сообщения при отсутствии соединения отбрасываются.
+	MaxBufferedMessages int
+	// Строгий режим: при отсутствии соединения (и без места в буфере) PublishEvent возвращает
+	// ErrNotConnected вместо молчаливого отбрасывания события
+	StrictMode bool
+}
+
+// DefaultPublisherOptions возвращает опции издателя по умолчанию
+func DefaultPublisherOptions() *PublisherOptions {
+	return &PublisherOptions{}
+}
+
+// publishChannel - методы *amqp.Channel, используемые издателем
+type publishChannel interface {
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	Close() error
+}
+
+// bufferedMessage представляет сообщение, ожидающее отправки
+type bufferedMessage struct {
+	routingKey string
+	mandatory  bool
+	immediate  bool
+	msg        amqp.Publishing
+}
+
+// bufferEnabled проверяет, буферизуются ли сообщения при отсутствии соединения
+func (p *Publisher) bufferEnabled() bool {
+	return p.maxBuffered > 0 && !killswitch.IsDisabled(killswitch.FeaturePublishBuffer)
+}
+
+// publishOrBuffer публикует сообщение или помещает его в буфер, если соединения нет.
+// Пока буфер не пуст или отправляется, новые сообщения также буферизуются, чтобы сохранить порядок;
+// flushBuffer отправляет и их.
+func (p *Publisher) publishOrBuffer(message bufferedMessage) error {
+	if p.bufferEnabled() {
+		p.bufferMutex.Lock()
+		if len(p.buffer) > 0 || p.flushing {
+			err := p.enqueueLocked(message)
+			p.bufferMutex.Unlock()
+			return err
+		}
+		p.bufferMutex.Unlock()
+	}
+
+	err := p.publish(message)
+	if err == nil {
+		return nil
+	}
+
+	disconnected := errors.Is(err, ErrNotConnected) || errors.Is(err, amqp.ErrClosed)
+	if !disconnected || !p.bufferEnabled() {
+		return err
+	}
+
+	p.bufferMutex.Lock()
+	err = p.enqueueLocked(message)
+	p.bufferMutex.Unlock()
+
+	// Соединение могло восстановиться после неудачной отправки, когда буфер уже был отправлен
+	if err == nil && p.IsConnected() {
+		go p.flushBuffer()
+	}
+	return err
+}
+
+// enqueueLocked добавляет сообщение в буфер; вызывается под bufferMutex
+func (p *Publisher) enqueueLocked(message bufferedMessage) error {
+	if len(p.buffer) >= p.maxBuffered {
+		droppedMessagesTotal.WithLabelValues(p.exchangeName).Inc()
+		return ErrPublishBufferFull
+	}
+
+	p.buffer = append(p.buffer, message)
+	bufferedMessages.WithLabelValues(p.exchangeName).Set(float64(len(p.buffer)))
+	p.logger.Debug("Event %s buffered until RabbitMQ reconnects (%d buffered)", message.routingKey, len(p.buffer))
+	return nil
+}
+
+// flushBuffer отправляет буферизованные сообщения по порядку после переподключения, включая
+// сообщения, буферизованные во время отправки. При ошибке отправка прекращается, оставшиеся
+// сообщения будут отправлены после следующего переподключения.
+func (p *Publisher) flushBuffer() {
+	p.bufferMutex.Lock()
+	if p.flushing || len(p.buffer) == 0 {
+		p.bufferMutex.Unlock()
+		return
+	}
+	p.flushing = true
+
+	sent := 0
+	for len(p.buffer) > 0 {
+		message := p.buffer[0]
+		p.bufferMutex.Unlock()
+
+		err := p.publish(message)
+
+		p.bufferMutex.Lock()
+		if err != nil {
+			p.flushing = false
+			p.bufferMutex.Unlock()
+			p.logger.Error("Failed to flush buffered events after %d sent: %v", sent, err)
+			return
+		}
+
+		p.buffer[0] = bufferedMessage{}
+		p.buffer = p.buffer[1:]
+		bufferedMessages.WithLabelValues(p.exchangeName).Set(float64(len(p.buffer)))
+		sent++
+	}
+
+	// Флаг сбрасывается под той же блокировкой, под которой буфер оказался пуст:
+	// иначе сообщение, буферизованное между проверкой и сбросом, ждало бы следующего переподключения
+	p.flushing = false
+	p.bufferMutex.Unlock()
+
+	p.logger.Info("Flushed %d buffered events", sent)
+}
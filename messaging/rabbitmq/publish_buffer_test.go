@@ -0,0 +1,187 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/vladzorgan/common/logging"
+)
+
+// fakeChannel записывает ключи опубликованных сообщений
+type fakeChannel struct {
+	mutex     sync.Mutex
+	published []string
+	// fail возвращает ошибку публикации для ключа
+	fail func(key string) error
+	// onPublish вызывается перед записью сообщения
+	onPublish func(key string)
+}
+
+func (c *fakeChannel) Publish(_, key string, _, _ bool, _ amqp.Publishing) error {
+	if c.onPublish != nil {
+		c.onPublish(key)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.fail != nil {
+		if err := c.fail(key); err != nil {
+			return err
+		}
+	}
+	c.published = append(c.published, key)
+	return nil
+}
+
+func (c *fakeChannel) Close() error { return nil }
+
+func (c *fakeChannel) keys() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]string(nil), c.published...)
+}
+
+// newBufferedPublisher создает отключенный издатель с буфером и буферизует события keys
+func newBufferedPublisher(t *testing.T, keys ...string) *Publisher {
+	t.Helper()
+
+	publisher := &Publisher{
+		exchangeName: "buffer-test-exchange",
+		logger:       logging.NewLogger(),
+		maxBuffered:  100,
+	}
+	for _, key := range keys {
+		if err := publisher.PublishEvent(context.Background(), key, nil); err != nil {
+			t.Fatalf("PublishEvent(%s) error = %v", key, err)
+		}
+	}
+	if len(publisher.buffer) != len(keys) {
+		t.Fatalf("buffered = %d, want %d", len(publisher.buffer), len(keys))
+	}
+	return publisher
+}
+
+// connect подключает издатель к фиктивному каналу
+func connect(publisher *Publisher, channel *fakeChannel) {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	publisher.channel = channel
+	publisher.connected = true
+}
+
+func TestFlushBufferSendsMessagesBufferedDuringFlush(t *testing.T) {
+	publisher := newBufferedPublisher(t, "city.1", "city.2")
+
+	// Событие публикуется во время отправки буфера и попадает в его конец
+	channel := &fakeChannel{}
+	channel.onPublish = func(key string) {
+		if key == "city.1" {
+			if err := publisher.PublishEvent(context.Background(), "city.3", nil); err != nil {
+				t.Errorf("PublishEvent() during flush error = %v", err)
+			}
+		}
+	}
+	connect(publisher, channel)
+
+	publisher.flushBuffer()
+
+	if got := fmt.Sprint(channel.keys()); got != "[city.1 city.2 city.3]" {
+		t.Errorf("published = %s, want [city.1 city.2 city.3]", got)
+	}
+	if len(publisher.buffer) != 0 || publisher.flushing {
+		t.Errorf("buffered = %d, flushing = %v; want empty buffer after flush", len(publisher.buffer), publisher.flushing)
+	}
+
+	// После отправки буфера события публикуются напрямую
+	if err := publisher.PublishEvent(context.Background(), "city.4", nil); err != nil {
+		t.Fatalf("PublishEvent() error = %v", err)
+	}
+	if keys := channel.keys(); len(keys) != 4 || keys[3] != "city.4" {
+		t.Errorf("published = %v, want city.4 sent directly", keys)
+	}
+}
+
+func TestFlushBufferStopsOnErrorAndResumes(t *testing.T) {
+	publisher := newBufferedPublisher(t, "city.1", "city.2", "city.3")
+
+	channel := &fakeChannel{fail: func(key string) error {
+		if key == "city.2" {
+			return amqp.ErrClosed
+		}
+		return nil
+	}}
+	connect(publisher, channel)
+
+	publisher.flushBuffer()
+	if got := fmt.Sprint(channel.keys()); got != "[city.1]" {
+		t.Errorf("published = %s, want [city.1]", got)
+	}
+	if len(publisher.buffer) != 2 || publisher.flushing {
+		t.Fatalf("buffered = %d, flushing = %v; want 2 kept and flushing reset", len(publisher.buffer), publisher.flushing)
+	}
+
+	channel.fail = nil
+	publisher.flushBuffer()
+	if got := fmt.Sprint(channel.keys()); got != "[city.1 city.2 city.3]" {
+		t.Errorf("published = %s, want all events in order", got)
+	}
+}
+
+func TestFlushBufferConcurrentPublishes(t *testing.T) {
+	publisher := newBufferedPublisher(t, "buffered.0", "buffered.1", "buffered.2")
+	channel := &fakeChannel{}
+	connect(publisher, channel)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		publisher.flushBuffer()
+	}()
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := publisher.PublishEvent(context.Background(), fmt.Sprintf("live.%d", i), nil); err != nil {
+				t.Errorf("PublishEvent() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Ни одно событие не остается в буфере до следующего переподключения
+	publisher.bufferMutex.Lock()
+	buffered, flushing := len(publisher.buffer), publisher.flushing
+	publisher.bufferMutex.Unlock()
+	if buffered != 0 || flushing {
+		t.Errorf("buffered = %d, flushing = %v; want everything sent", buffered, flushing)
+	}
+
+	keys := channel.keys()
+	if len(keys) != 53 {
+		t.Fatalf("published = %d events, want 53", len(keys))
+	}
+	for i := 0; i < 3; i++ {
+		if keys[i] != fmt.Sprintf("buffered.%d", i) {
+			t.Errorf("published[%d] = %s, want buffered events first", i, keys[i])
+		}
+	}
+}
+
+func TestPublishBufferFull(t *testing.T) {
+	publisher := &Publisher{exchangeName: "buffer-full-exchange", logger: logging.NewLogger(), maxBuffered: 1}
+
+	if err := publisher.PublishEvent(context.Background(), "city.1", nil); err != nil {
+		t.Fatalf("PublishEvent() error = %v", err)
+	}
+	if err := publisher.PublishEvent(context.Background(), "city.2", nil); !errors.Is(err, ErrPublishBufferFull) {
+		t.Errorf("PublishEvent() error = %v, want ErrPublishBufferFull", err)
+	}
+}
@@ -4,12 +4,22 @@ package rabbitmq
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/streadway/amqp"
 	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/messaging"
+	"github.com/vladzorgan/common/retry"
+	"github.com/vladzorgan/common/tracing"
+)
+
+// Задержки между попытками переподключения к RabbitMQ
+const (
+	reconnectBackoff    = 1 * time.Second
+	maxReconnectBackoff = 30 * time.Second
 )
 
 // PublishConfig содержит настройки для публикации сообщений
@@ -20,38 +30,50 @@ type PublishConfig struct {
 	Priority  uint8
 }
 
-// EventEnvelope представляет конверт для события
-type EventEnvelope struct {
-	EventType   string      `json:"event_type"`
-	OccurredAt  time.Time   `json:"occurred_at"`
-	ServiceName string      `json:"service_name"`
-	Payload     interface{} `json:"payload"`
-}
+// EventEnvelope представляет конверт для события (см. messaging.EventEnvelope)
+type EventEnvelope = messaging.EventEnvelope
 
 // Publisher представляет сервис для публикации событий в RabbitMQ
 type Publisher struct {
 	connection   *amqp.Connection
-	channel      *amqp.Channel
+	channel      publishChannel
 	exchangeName string
 	serviceName  string
 	logger       logging.Logger
 	mutex        sync.RWMutex
 	connected    bool
 	reconnecting bool
+	strict       bool
+
+	// Буфер сообщений, опубликованных при отсутствии соединения
+	maxBuffered int
+	buffer      []bufferedMessage
+	flushing    bool
+	bufferMutex sync.Mutex
 }
 
 // NewPublisher создает новый экземпляр Publisher
 func NewPublisher(rabbitmqURL, exchangeName, serviceName string, logger logging.Logger) (*Publisher, error) {
+	return NewPublisherWithOptions(rabbitmqURL, exchangeName, serviceName, logger, nil)
+}
+
+// NewPublisherWithOptions создает новый экземпляр Publisher с опциями
+func NewPublisherWithOptions(rabbitmqURL, exchangeName, serviceName string, logger logging.Logger, options *PublisherOptions) (*Publisher, error) {
 	if logger == nil {
 		logger = logging.NewLogger()
 	}
 
+	if options == nil {
+		options = DefaultPublisherOptions()
+	}
+
 	if rabbitmqURL == "" {
 		logger.Warn("RABBITMQ_URL not set, events will not be published")
 		return &Publisher{
 			exchangeName: exchangeName,
 			serviceName:  serviceName,
 			logger:       logger,
+			strict:       options.StrictMode,
 		}, nil
 	}
 
@@ -59,6 +81,8 @@ func NewPublisher(rabbitmqURL, exchangeName, serviceName string, logger logging.
 		exchangeName: exchangeName,
 		serviceName:  serviceName,
 		logger:       logger,
+		maxBuffered:  options.MaxBufferedMessages,
+		strict:       options.StrictMode,
 	}
 
 	if err := publisher.connect(rabbitmqURL); err != nil {
@@ -150,32 +174,35 @@ func (p *Publisher) reconnect(rabbitmqURL string) {
 	}()
 
 	// Бесконечные попытки подключения
-	backoff := 1 * time.Second
-	maxBackoff := 30 * time.Second
+	backoff := retry.NewBackoff(reconnectBackoff, maxReconnectBackoff)
 
 	for {
-		p.logger.Info("Trying to reconnect to RabbitMQ in %v...", backoff)
-		time.Sleep(backoff)
+		delay := backoff.Next()
+		p.logger.Info("Trying to reconnect to RabbitMQ in %v...", delay)
+		time.Sleep(delay)
 
 		// Пытаемся подключиться
 		if err := p.connect(rabbitmqURL); err != nil {
 			p.logger.Error("Failed to reconnect to RabbitMQ: %v", err)
-
-			// Увеличиваем время ожидания (экспоненциальный backoff)
-			backoff *= 2
-			if backoff > maxBackoff {
-				backoff = maxBackoff
-			}
 			continue
 		}
 
 		p.logger.Info("Successfully reconnected to RabbitMQ")
+
+		// Отправляем сообщения, накопленные за время недоступности
+		p.flushBuffer()
 		return
 	}
 }
 
-// Close закрывает соединение с RabbitMQ
+// Close закрывает соединение с RabbitMQ. Неотправленные буферизованные сообщения теряются.
 func (p *Publisher) Close() {
+	p.bufferMutex.Lock()
+	if len(p.buffer) > 0 {
+		p.logger.Warn("Closing publisher with %d buffered events not sent", len(p.buffer))
+	}
+	p.bufferMutex.Unlock()
+
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
@@ -189,29 +216,35 @@ func (p *Publisher) Close() {
 	p.connected = false
 }
 
+// IsConnected возвращает true, если соединение с RabbitMQ установлено.
+// Используется проверками здоровья, чтобы сообщать о деградации при отсутствии соединения.
+func (p *Publisher) IsConnected() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.connected && p.channel != nil
+}
+
 // PublishEvent публикует событие в RabbitMQ
 func (p *Publisher) PublishEvent(ctx context.Context, routingKey string, payload interface{}) error {
 	return p.PublishEventWithConfig(ctx, routingKey, payload, nil)
 }
 
-// PublishEventWithConfig публикует событие в RabbitMQ с дополнительными настройками
+// PublishEventWithConfig публикует событие в RabbitMQ с дополнительными настройками.
+// При отсутствии соединения событие буферизуется (см. PublisherOptions.MaxBufferedMessages);
+// если буфер заполнен, возвращается ErrPublishBufferFull. Без буфера событие отбрасывается
+// (метрика events_dropped_total), а в строгом режиме возвращается ErrNotConnected.
 func (p *Publisher) PublishEventWithConfig(ctx context.Context, routingKey string, payload interface{}, config *PublishConfig) error {
-	// Если соединение не установлено, просто логируем событие
+	// Без буфера и соединения событие не может быть отправлено
 	p.mutex.RLock()
-	if p.channel == nil {
+	if p.channel == nil && !p.bufferEnabled() {
 		p.mutex.RUnlock()
-		p.logger.Debug("Event %s not published (RabbitMQ not connected): %+v", routingKey, payload)
-		return nil
+		return p.notConnected(routingKey, payloadSize(payload))
 	}
 	p.mutex.RUnlock()
 
 	// Создаем конверт для события
-	envelope := EventEnvelope{
-		EventType:   routingKey,
-		OccurredAt:  time.Now(),
-		ServiceName: p.serviceName,
-		Payload:     payload,
-	}
+	envelope := messaging.NewEnvelope(ctx, routingKey, p.serviceName, payload)
 
 	// Сериализуем конверт в JSON
 	body, err := json.Marshal(envelope)
@@ -221,38 +254,92 @@ func (p *Publisher) PublishEventWithConfig(ctx context.Context, routingKey strin
 
 	// Создаем сообщение
 	msg := amqp.Publishing{
-		DeliveryMode: amqp.Persistent,
-		Timestamp:    time.Now(),
-		ContentType:  "application/json",
-		Body:         body,
-		MessageId:    fmt.Sprintf("%d", time.Now().UnixNano()),
+		DeliveryMode:  amqp.Persistent,
+		Timestamp:     envelope.OccurredAt,
+		ContentType:   "application/json",
+		Body:          body,
+		MessageId:     envelope.EventID,
+		CorrelationId: envelope.CorrelationID,
 	}
 
 	// Применяем дополнительные настройки, если указаны
 	if config != nil {
 		if config.Headers != nil {
-			msg.Headers = config.Headers
+			msg.Headers = make(amqp.Table, len(config.Headers))
+			for key, value := range config.Headers {
+				msg.Headers[key] = value
+			}
 		}
 		if config.Priority > 0 {
 			msg.Priority = config.Priority
 		}
 	}
 
+	// Передаем контекст трейса потребителю
+	msg.Headers = tracing.InjectHeaders(ctx, msg.Headers)
+
 	// Публикуем сообщение
-	p.mutex.RLock()
-	err = p.channel.Publish(
-		p.exchangeName,                    // обменник
-		routingKey,                        // ключ маршрутизации
-		config != nil && config.Mandatory, // обязательный (mandatory)
-		config != nil && config.Immediate, // мгновенный (immediate)
-		msg,
-	)
-	p.mutex.RUnlock()
+	err = p.publishOrBuffer(bufferedMessage{
+		routingKey: routingKey,
+		mandatory:  config != nil && config.Mandatory,
+		immediate:  config != nil && config.Immediate,
+		msg:        msg,
+	})
+	if errors.Is(err, ErrPublishBufferFull) {
+		return err
+	}
+	if errors.Is(err, ErrNotConnected) {
+		return p.notConnected(routingKey, len(body))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
 
+	return nil
+}
+
+// payloadSize возвращает размер payload в JSON (0, если его не удалось закодировать).
+// Логируется вместо содержимого, которое может содержать персональные данные.
+func payloadSize(payload interface{}) int {
+	data, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("failed to publish message: %v", err)
+		return 0
+	}
+	return len(data)
+}
+
+// notConnected обрабатывает событие, которое не удалось отправить из-за отсутствия соединения:
+// в строгом режиме возвращает ErrNotConnected, иначе отбрасывает событие с предупреждением.
+// Содержимое события может включать персональные данные, поэтому в журнал попадает только его размер.
+func (p *Publisher) notConnected(routingKey string, size int) error {
+	if p.strict {
+		return fmt.Errorf("event %s not published: %w", routingKey, ErrNotConnected)
+	}
+
+	eventsDroppedTotal.WithLabelValues(p.exchangeName, routingKey).Inc()
+	p.logger.Warn("Event %s dropped (RabbitMQ not connected), payload size %d bytes", routingKey, size)
+	return nil
+}
+
+// publish отправляет сообщение в текущий канал
+func (p *Publisher) publish(message bufferedMessage) error {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if !p.connected || p.channel == nil {
+		return ErrNotConnected
+	}
+
+	if err := p.channel.Publish(
+		p.exchangeName,     // обменник
+		message.routingKey, // ключ маршрутизации
+		message.mandatory,  // обязательный (mandatory)
+		message.immediate,  // мгновенный (immediate)
+		message.msg,
+	); err != nil {
+		return err
 	}
 
-	p.logger.Debug("Published event %s", routingKey)
+	p.logger.Debug("Published event %s", message.routingKey)
 	return nil
 }
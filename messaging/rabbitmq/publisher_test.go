@@ -0,0 +1,116 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/vladzorgan/common/logging"
+)
+
+func TestPublishEventStrictModeFailsWhenNotConnected(t *testing.T) {
+	publisher, err := NewPublisherWithOptions("", "strict-exchange", "test-service", nil, &PublisherOptions{StrictMode: true})
+	if err != nil {
+		t.Fatalf("NewPublisherWithOptions() error = %v", err)
+	}
+	if publisher.IsConnected() {
+		t.Fatal("publisher without URL must not be connected")
+	}
+
+	err = publisher.PublishEvent(context.Background(), "city.created", map[string]int{"id": 1})
+	if !errors.Is(err, ErrNotConnected) {
+		t.Errorf("PublishEvent() error = %v, want ErrNotConnected", err)
+	}
+	if got := testutil.ToFloat64(eventsDroppedTotal.WithLabelValues("strict-exchange", "city.created")); got != 0 {
+		t.Errorf("events_dropped_total = %v, want 0 in strict mode", got)
+	}
+}
+
+func TestPublishEventCountsDroppedEvents(t *testing.T) {
+	publisher, err := NewPublisher("", "lenient-exchange", "test-service", nil)
+	if err != nil {
+		t.Fatalf("NewPublisher() error = %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := publisher.PublishEvent(context.Background(), "city.created", nil); err != nil {
+			t.Fatalf("PublishEvent() error = %v", err)
+		}
+	}
+	if got := testutil.ToFloat64(eventsDroppedTotal.WithLabelValues("lenient-exchange", "city.created")); got != 2 {
+		t.Errorf("events_dropped_total = %v, want 2", got)
+	}
+}
+
+func TestPublishEventStrictModeBuffersWhenPossible(t *testing.T) {
+	publisher := &Publisher{
+		exchangeName: "buffered-exchange",
+		logger:       logging.NewLogger(),
+		maxBuffered:  1,
+		strict:       true,
+	}
+
+	if err := publisher.PublishEvent(context.Background(), "city.created", nil); err != nil {
+		t.Fatalf("PublishEvent() error = %v, want event buffered", err)
+	}
+	if err := publisher.PublishEvent(context.Background(), "city.created", nil); !errors.Is(err, ErrPublishBufferFull) {
+		t.Errorf("PublishEvent() error = %v, want ErrPublishBufferFull", err)
+	}
+}
+
+// warnLogger запоминает отформатированные предупреждения и отладочные сообщения
+type warnLogger struct {
+	logging.Logger
+
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *warnLogger) Warn(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func (l *warnLogger) Debug(format string, v ...interface{}) {
+	l.Warn(format, v...)
+}
+
+func TestDroppedEventLogOmitsPayload(t *testing.T) {
+	logger := &warnLogger{Logger: logging.NewLogger()}
+	publisher, err := NewPublisher("", "redacted-exchange", "test-service", logger)
+	if err != nil {
+		t.Fatalf("NewPublisher() error = %v", err)
+	}
+
+	if err := publisher.PublishEvent(context.Background(), "user.created", map[string]string{"email": "user@example.com"}); err != nil {
+		t.Fatalf("PublishEvent() error = %v", err)
+	}
+
+	if len(logger.messages) == 0 {
+		t.Fatal("no warning about the dropped event")
+	}
+	if message := logger.messages[len(logger.messages)-1]; strings.Contains(message, "user@example.com") || !strings.Contains(message, "user.created") {
+		t.Errorf("warning = %q, want the routing key without the payload", message)
+	}
+}
+
+func TestNoopPublisherLogOmitsPayload(t *testing.T) {
+	logger := &warnLogger{Logger: logging.NewLogger()}
+	publisher := NewNoopPublisher(logger)
+
+	if err := publisher.PublishEvent(context.Background(), "user.created", map[string]string{"email": "user@example.com"}); err != nil {
+		t.Fatalf("PublishEvent() error = %v", err)
+	}
+
+	if publisher.Dropped() != 1 || len(logger.messages) != 1 {
+		t.Fatalf("dropped = %d, messages = %v; want 1 dropped event logged once", publisher.Dropped(), logger.messages)
+	}
+	if message := logger.messages[0]; strings.Contains(message, "user@example.com") || !strings.Contains(message, "user.created") {
+		t.Errorf("message = %q, want the routing key without the payload", message)
+	}
+}
@@ -0,0 +1,117 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/messaging"
+)
+
+// EventPublisher определяет интерфейс публикации событий в RabbitMQ.
+// Код, не зависящий от брокера, принимает messaging.Publisher.
+type EventPublisher interface {
+	messaging.Publisher
+	PublishEventWithConfig(ctx context.Context, routingKey string, payload interface{}, config *PublishConfig) error
+	Close()
+}
+
+// Проверяем, что реализации удовлетворяют интерфейсу
+var (
+	_ EventPublisher = (*Publisher)(nil)
+	_ EventPublisher = (*NoopPublisher)(nil)
+	_ EventPublisher = (*MultiPublisher)(nil)
+)
+
+// NoopPublisher представляет издателя, который отбрасывает события.
+// Используется сервисами, которые осознанно работают без брокера.
+type NoopPublisher struct {
+	logger  logging.Logger
+	dropped atomic.Int64
+}
+
+// NewNoopPublisher создает новый NoopPublisher
+func NewNoopPublisher(logger logging.Logger) *NoopPublisher {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	return &NoopPublisher{
+		logger: logger,
+	}
+}
+
+// PublishEvent отбрасывает событие
+func (p *NoopPublisher) PublishEvent(ctx context.Context, routingKey string, payload interface{}) error {
+	return p.PublishEventWithConfig(ctx, routingKey, payload, nil)
+}
+
+// PublishEventWithConfig отбрасывает событие
+func (p *NoopPublisher) PublishEventWithConfig(ctx context.Context, routingKey string, payload interface{}, config *PublishConfig) error {
+	p.dropped.Add(1)
+	p.logger.Debug("Event %s dropped by noop publisher, payload size %d bytes", routingKey, payloadSize(payload))
+	return nil
+}
+
+// Dropped возвращает количество отброшенных событий
+func (p *NoopPublisher) Dropped() int64 {
+	return p.dropped.Load()
+}
+
+// Close ничего не делает
+func (p *NoopPublisher) Close() {}
+
+// FailurePolicy определяет поведение MultiPublisher при ошибках публикации
+type FailurePolicy int
+
+const (
+	// FailurePolicyAllMustSucceed прекращает публикацию при первой ошибке и возвращает ее
+	FailurePolicyAllMustSucceed FailurePolicy = iota
+	// FailurePolicyBestEffort публикует во все приемники и возвращает объединенную ошибку
+	FailurePolicyBestEffort
+)
+
+// MultiPublisher публикует события в несколько приемников
+type MultiPublisher struct {
+	publishers []EventPublisher
+	policy     FailurePolicy
+}
+
+// NewMultiPublisher создает новый MultiPublisher
+func NewMultiPublisher(policy FailurePolicy, publishers ...EventPublisher) *MultiPublisher {
+	return &MultiPublisher{
+		publishers: publishers,
+		policy:     policy,
+	}
+}
+
+// PublishEvent публикует событие во все приемники
+func (p *MultiPublisher) PublishEvent(ctx context.Context, routingKey string, payload interface{}) error {
+	return p.PublishEventWithConfig(ctx, routingKey, payload, nil)
+}
+
+// PublishEventWithConfig публикует событие во все приемники с дополнительными настройками
+func (p *MultiPublisher) PublishEventWithConfig(ctx context.Context, routingKey string, payload interface{}, config *PublishConfig) error {
+	var errs []error
+
+	for i, publisher := range p.publishers {
+		if err := publisher.PublishEventWithConfig(ctx, routingKey, payload, config); err != nil {
+			err = fmt.Errorf("publisher %d: %w", i, err)
+			if p.policy == FailurePolicyAllMustSucceed {
+				return err
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close закрывает все приемники
+func (p *MultiPublisher) Close() {
+	for _, publisher := range p.publishers {
+		publisher.Close()
+	}
+}
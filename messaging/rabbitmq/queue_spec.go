@@ -0,0 +1,287 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Аргументы очередей RabbitMQ
+const (
+	QueueArgMessageTTL           = "x-message-ttl"
+	QueueArgDeadLetterExchange   = "x-dead-letter-exchange"
+	QueueArgDeadLetterRoutingKey = "x-dead-letter-routing-key"
+	QueueArgMaxPriority          = "x-max-priority"
+	QueueArgQueueType            = "x-queue-type"
+	QueueArgMaxLength            = "x-max-length"
+	QueueArgOverflow             = "x-overflow"
+	QueueArgExpires              = "x-expires"
+	QueueArgMaxLengthBytes       = "x-max-length-bytes"
+)
+
+// ErrInvalidQueueArgs возвращается, если известный брокеру аргумент очереди имеет неверный тип
+// или значение. Брокер отклонил бы такое объявление, закрыв канал (PRECONDITION_FAILED).
+var ErrInvalidQueueArgs = errors.New("invalid rabbitmq queue arguments")
+
+// OverflowBehavior определяет поведение очереди при достижении максимальной длины
+type OverflowBehavior string
+
+const (
+	// OverflowDropHead удаляет самые старые сообщения
+	OverflowDropHead OverflowBehavior = "drop-head"
+	// OverflowRejectPublish отклоняет новые сообщения
+	OverflowRejectPublish OverflowBehavior = "reject-publish"
+	// OverflowRejectPublishDLX отклоняет новые сообщения и отправляет их в DLX
+	OverflowRejectPublishDLX OverflowBehavior = "reject-publish-dlx"
+)
+
+// QueueSpec представляет типизированное описание очереди с проверкой аргументов.
+// Ошибки накапливаются при построении и возвращаются из ConsumerOptions.
+type QueueSpec struct {
+	durable    bool
+	autoDelete bool
+	exclusive  bool
+	args       map[string]interface{}
+	errs       []error
+}
+
+// NewQueueSpec создает описание долговечной очереди без дополнительных аргументов
+func NewQueueSpec() *QueueSpec {
+	return &QueueSpec{
+		durable: true,
+		args:    make(map[string]interface{}),
+	}
+}
+
+// Durable задает долговечность очереди
+func (s *QueueSpec) Durable(durable bool) *QueueSpec {
+	s.durable = durable
+	return s
+}
+
+// AutoDelete задает автоудаление очереди
+func (s *QueueSpec) AutoDelete(autoDelete bool) *QueueSpec {
+	s.autoDelete = autoDelete
+	return s
+}
+
+// Exclusive задает эксклюзивность очереди
+func (s *QueueSpec) Exclusive(exclusive bool) *QueueSpec {
+	s.exclusive = exclusive
+	return s
+}
+
+// WithTTL задает время жизни сообщений в очереди
+func (s *QueueSpec) WithTTL(ttl time.Duration) *QueueSpec {
+	if ttl <= 0 {
+		s.errs = append(s.errs, fmt.Errorf("message TTL must be positive, got %v", ttl))
+		return s
+	}
+	return s.setArg(QueueArgMessageTTL, ttl.Milliseconds())
+}
+
+// WithDLX задает обменник и ключ маршрутизации для отклоненных сообщений
+func (s *QueueSpec) WithDLX(exchange, routingKey string) *QueueSpec {
+	s.setArg(QueueArgDeadLetterExchange, exchange)
+	if routingKey != "" {
+		s.setArg(QueueArgDeadLetterRoutingKey, routingKey)
+	}
+	return s
+}
+
+// WithMaxPriority включает приоритеты сообщений с максимальным значением n (1-255)
+func (s *QueueSpec) WithMaxPriority(n int) *QueueSpec {
+	if n < 1 || n > 255 {
+		s.errs = append(s.errs, fmt.Errorf("max priority must be between 1 and 255, got %d", n))
+		return s
+	}
+	return s.setArg(QueueArgMaxPriority, n)
+}
+
+// WithQuorum делает очередь кворумной
+func (s *QueueSpec) WithQuorum() *QueueSpec {
+	return s.setArg(QueueArgQueueType, "quorum")
+}
+
+// WithMaxLength ограничивает длину очереди
+func (s *QueueSpec) WithMaxLength(n int, overflow OverflowBehavior) *QueueSpec {
+	if n <= 0 {
+		s.errs = append(s.errs, fmt.Errorf("max length must be positive, got %d", n))
+		return s
+	}
+	s.setArg(QueueArgMaxLength, n)
+	if overflow != "" {
+		s.setArg(QueueArgOverflow, string(overflow))
+	}
+	return s
+}
+
+// WithArg задает произвольный аргумент очереди для расширений, не покрытых типизированными методами
+func (s *QueueSpec) WithArg(name string, value interface{}) *QueueSpec {
+	return s.setArg(name, value)
+}
+
+// setArg устанавливает аргумент, запрещая повторное задание
+func (s *QueueSpec) setArg(name string, value interface{}) *QueueSpec {
+	if _, exists := s.args[name]; exists {
+		s.errs = append(s.errs, fmt.Errorf("queue argument %s is set more than once", name))
+		return s
+	}
+	s.args[name] = value
+	return s
+}
+
+// Validate проверяет описание очереди
+func (s *QueueSpec) Validate() error {
+	errs := make([]error, 0, len(s.errs)+1)
+	errs = append(errs, s.errs...)
+	if err := validateQueueArgs(s.durable, s.autoDelete, s.exclusive, s.args); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// ConsumerOptions возвращает опции потребителя с настройками очереди из описания.
+// Настройки prefetch берутся из base (или из значений по умолчанию).
+func (s *QueueSpec) ConsumerOptions(base *ConsumerOptions) (*ConsumerOptions, error) {
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid queue spec: %w", err)
+	}
+
+	options := DefaultConsumerOptions()
+	if base != nil {
+		copied := *base
+		options = &copied
+	}
+
+	options.QueueDurable = s.durable
+	options.QueueAutoDelete = s.autoDelete
+	options.QueueExclusive = s.exclusive
+	options.QueueArgs = make(map[string]interface{}, len(s.args))
+	for name, value := range s.args {
+		options.QueueArgs[name] = value
+	}
+
+	return options, nil
+}
+
+// Validate проверяет согласованность настроек очереди и аргументов
+func (o *ConsumerOptions) Validate() error {
+	return validateQueueArgs(o.QueueDurable, o.QueueAutoDelete, o.QueueExclusive, o.QueueArgs)
+}
+
+// validateQueueArgTypes проверяет типы и значения известных аргументов очереди
+func validateQueueArgTypes(args map[string]interface{}) error {
+	var errs []error
+
+	for name, value := range args {
+		switch name {
+		case QueueArgMessageTTL, QueueArgMaxLength, QueueArgMaxLengthBytes:
+			if n, ok := queueArgInt(value); !ok || n < 0 {
+				errs = append(errs, fmt.Errorf("queue argument %s must be a non-negative integer, got %T(%v)", name, value, value))
+			}
+		case QueueArgExpires:
+			if n, ok := queueArgInt(value); !ok || n <= 0 {
+				errs = append(errs, fmt.Errorf("queue argument %s must be a positive integer, got %T(%v)", name, value, value))
+			}
+		case QueueArgMaxPriority:
+			if n, ok := queueArgInt(value); !ok || n < 1 || n > 255 {
+				errs = append(errs, fmt.Errorf("queue argument %s must be an integer between 1 and 255, got %T(%v)", name, value, value))
+			}
+		case QueueArgDeadLetterExchange, QueueArgDeadLetterRoutingKey, QueueArgOverflow:
+			if _, ok := value.(string); !ok {
+				errs = append(errs, fmt.Errorf("queue argument %s must be a string, got %T", name, value))
+			}
+		case QueueArgQueueType:
+			switch value {
+			case "classic", "quorum", "stream":
+			default:
+				errs = append(errs, fmt.Errorf("queue argument %s must be one of classic, quorum, stream, got %v", name, value))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidQueueArgs, errors.Join(errs...))
+	}
+	return nil
+}
+
+// queueArgInt возвращает целочисленное значение аргумента (таблицы AMQP принимают любые целые типы Go)
+func queueArgInt(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// validateQueueArgs проверяет аргументы очереди на конфликты
+func validateQueueArgs(durable, autoDelete, exclusive bool, args map[string]interface{}) error {
+	var errs []error
+
+	if err := validateQueueArgTypes(args); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Неизвестные брокеру аргументы без префикса x- молча игнорируются, поэтому запрещаем их
+	for name := range args {
+		if !strings.HasPrefix(name, "x-") {
+			errs = append(errs, fmt.Errorf("queue argument %s must start with \"x-\"", name))
+		}
+	}
+
+	if queueType, ok := args[QueueArgQueueType]; ok && queueType == "quorum" {
+		if exclusive {
+			errs = append(errs, errors.New("quorum queues cannot be exclusive"))
+		}
+		if autoDelete {
+			errs = append(errs, errors.New("quorum queues cannot be auto-delete"))
+		}
+		if !durable {
+			errs = append(errs, errors.New("quorum queues must be durable"))
+		}
+		if _, ok := args[QueueArgMaxPriority]; ok {
+			errs = append(errs, errors.New("quorum queues do not support message priorities"))
+		}
+		if args[QueueArgOverflow] == string(OverflowRejectPublishDLX) {
+			errs = append(errs, errors.New("quorum queues do not support reject-publish-dlx overflow"))
+		}
+	}
+
+	if overflow, ok := args[QueueArgOverflow]; ok {
+		switch OverflowBehavior(fmt.Sprint(overflow)) {
+		case OverflowDropHead, OverflowRejectPublish:
+		case OverflowRejectPublishDLX:
+			if _, ok := args[QueueArgDeadLetterExchange]; !ok {
+				errs = append(errs, errors.New("reject-publish-dlx overflow requires a dead letter exchange"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("unknown overflow behavior %v", overflow))
+		}
+	}
+
+	if _, ok := args[QueueArgDeadLetterRoutingKey]; ok {
+		if _, ok := args[QueueArgDeadLetterExchange]; !ok {
+			errs = append(errs, errors.New("dead letter routing key requires a dead letter exchange"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,94 @@
+package rabbitmq
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vladzorgan/common/logging"
+)
+
+func TestQueueSpecValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		spec *QueueSpec
+		want string // подстрока ошибки; пустая - описание корректно
+	}{
+		{"defaults", NewQueueSpec(), ""},
+		{"full classic", NewQueueSpec().WithTTL(time.Minute).WithDLX("dlx", "dead").WithMaxPriority(10).WithMaxLength(100, OverflowRejectPublishDLX), ""},
+		{"quorum", NewQueueSpec().WithQuorum().WithMaxLength(10, OverflowRejectPublish), ""},
+		{"non-positive ttl", NewQueueSpec().WithTTL(0), "message TTL must be positive"},
+		{"priority out of range", NewQueueSpec().WithMaxPriority(256), "max priority must be between 1 and 255"},
+		{"non-positive max length", NewQueueSpec().WithMaxLength(0, ""), "max length must be positive"},
+		{"argument set twice", NewQueueSpec().WithTTL(time.Second).WithArg(QueueArgMessageTTL, 5), "set more than once"},
+		{"quorum exclusive", NewQueueSpec().WithQuorum().Exclusive(true), "cannot be exclusive"},
+		{"quorum auto-delete", NewQueueSpec().WithQuorum().AutoDelete(true), "cannot be auto-delete"},
+		{"quorum transient", NewQueueSpec().WithQuorum().Durable(false), "must be durable"},
+		{"quorum priority", NewQueueSpec().WithQuorum().WithMaxPriority(5), "do not support message priorities"},
+		{"quorum reject-publish-dlx", NewQueueSpec().WithQuorum().WithDLX("dlx", "").WithMaxLength(10, OverflowRejectPublishDLX), "do not support reject-publish-dlx"},
+		{"reject-publish-dlx without dlx", NewQueueSpec().WithMaxLength(10, OverflowRejectPublishDLX), "requires a dead letter exchange"},
+		{"unknown overflow", NewQueueSpec().WithMaxLength(10, "drop-tail"), "unknown overflow behavior"},
+		{"routing key without dlx", NewQueueSpec().WithArg(QueueArgDeadLetterRoutingKey, "dead"), "routing key requires a dead letter exchange"},
+		{"argument without x- prefix", NewQueueSpec().WithArg("message-ttl", 1000), "must start with"},
+		{"unknown x- argument", NewQueueSpec().WithArg("x-custom-extension", []string{"any"}), ""},
+		{"string ttl", NewQueueSpec().WithArg(QueueArgMessageTTL, "60000"), "must be a non-negative integer"},
+		{"float max length", NewQueueSpec().WithArg(QueueArgMaxLength, 10.5), "must be a non-negative integer"},
+		{"negative max length bytes", NewQueueSpec().WithArg(QueueArgMaxLengthBytes, -1), "must be a non-negative integer"},
+		{"zero expires", NewQueueSpec().WithArg(QueueArgExpires, 0), "must be a positive integer"},
+		{"raw priority out of range", NewQueueSpec().WithArg(QueueArgMaxPriority, int64(300)), "between 1 and 255"},
+		{"non-string dlx", NewQueueSpec().WithArg(QueueArgDeadLetterExchange, 1), "must be a string"},
+		{"unknown queue type", NewQueueSpec().WithArg(QueueArgQueueType, "lazy"), "must be one of classic, quorum, stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spec.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueueSpecConsumerOptions(t *testing.T) {
+	base := DefaultConsumerOptions()
+	base.PrefetchCount = 7
+
+	options, err := NewQueueSpec().WithTTL(time.Minute).WithQuorum().ConsumerOptions(base)
+	if err != nil {
+		t.Fatalf("ConsumerOptions() error = %v", err)
+	}
+	if options.PrefetchCount != 7 || !options.QueueDurable || options.QueueArgs[QueueArgMessageTTL] != int64(60000) || options.QueueArgs[QueueArgQueueType] != "quorum" {
+		t.Errorf("ConsumerOptions() = %+v", options)
+	}
+	if base.QueueArgs != nil {
+		t.Error("ConsumerOptions() modified base options")
+	}
+
+	if _, err := NewQueueSpec().WithQuorum().Exclusive(true).ConsumerOptions(nil); err == nil {
+		t.Error("ConsumerOptions() accepted invalid spec")
+	}
+}
+
+func TestNewConsumerRejectsMistypedQueueArgs(t *testing.T) {
+	options := DefaultConsumerOptions()
+	options.QueueArgs = map[string]interface{}{QueueArgMessageTTL: "60s"}
+
+	_, err := NewConsumer("", "events", "queue", "test", logging.NewLogger(), options)
+	if !errors.Is(err, ErrInvalidQueueArgs) {
+		t.Fatalf("NewConsumer() error = %v, want %v", err, ErrInvalidQueueArgs)
+	}
+
+	// Конфликты аргументов сохраняют прежнее поведение и только логируются
+	options.QueueArgs = map[string]interface{}{QueueArgDeadLetterRoutingKey: "dead"}
+	if _, err := NewConsumer("", "events", "queue", "test", logging.NewLogger(), options); err != nil {
+		t.Errorf("NewConsumer() error = %v", err)
+	}
+}
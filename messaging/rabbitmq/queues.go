@@ -0,0 +1,189 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/streadway/amqp"
+)
+
+// ErrDeclarationConflict возвращается, если обменник или очередь уже существуют с другими
+// параметрами (например, обменник другого типа). Переподключение такую ошибку не исправит:
+// нужно привести настройки в соответствие или удалить существующий объект.
+var ErrDeclarationConflict = errors.New("rabbitmq declaration conflicts with existing entity")
+
+// consumerQueue очередь потребителя с обработчиками по ключам маршрутизации
+type consumerQueue struct {
+	name        string
+	consumerTag string
+	handlers    map[string]HandlerFunc
+	// Получение сообщений запущено на текущем канале
+	consuming bool
+}
+
+// newQueue создает описание очереди; метка потребителя основной очереди не меняется для совместимости
+func (c *Consumer) newQueue(name string) *consumerQueue {
+	tag := c.consumerTag
+	if name != c.queueName {
+		tag = c.consumerTag + "-" + name
+	}
+
+	return &consumerQueue{
+		name:        name,
+		consumerTag: tag,
+		handlers:    make(map[string]HandlerFunc),
+	}
+}
+
+// exchangeKind проверяет тип обменника; пустой тип означает topic
+func exchangeKind(kind string) (string, error) {
+	switch kind {
+	case "":
+		return amqp.ExchangeTopic, nil
+	case amqp.ExchangeTopic, amqp.ExchangeDirect, amqp.ExchangeFanout, amqp.ExchangeHeaders:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("unsupported exchange type %q", kind)
+	}
+}
+
+// SubscribeQueue подписывает обработчик на маршрут дополнительной очереди. Очередь объявляется
+// с настройками потребителя (QueueDurable, QueueArgs и т.д.), привязывается к обменнику потребителя
+// и читается через то же соединение и канал; Concurrency и HandlerTimeout общие для всех очередей.
+func (c *Consumer) SubscribeQueue(queueName, routingKey string, handler HandlerFunc) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Сохраняем обработчик
+	queue, exists := c.queues[queueName]
+	if !exists {
+		queue = c.newQueue(queueName)
+		c.queues[queueName] = queue
+	}
+	queue.handlers[routingKey] = handler
+
+	// Если не подключены или останавливаемся, просто сохраняем обработчик
+	if !c.connected || c.channel == nil || c.draining {
+		return nil
+	}
+
+	// Новые очереди объявляем сразу, остальные объявлены при подключении. Ошибка объявления
+	// закрывает канал, поэтому очередь объявляется в отдельном канале, чтобы не остановить
+	// получение сообщений из уже подписанных очередей.
+	if !exists {
+		if err := c.declareQueueSeparately(queueName); err != nil {
+			return err
+		}
+	}
+
+	// Связываем очередь с обменником
+	if err := c.bind(queueName, routingKey); err != nil {
+		return err
+	}
+
+	// Если это первая подписка очереди, начинаем потреблять сообщения
+	if !queue.consuming {
+		return c.consume(queue)
+	}
+
+	return nil
+}
+
+// declare объявляет обменник и все очереди потребителя. Вызывается под c.mutex.
+func (c *Consumer) declare(channel *amqp.Channel) error {
+	err := channel.ExchangeDeclare(
+		c.exchangeName, // имя обменника
+		c.exchangeType, // тип обменника
+		true,           // долговечный (durable)
+		false,          // автоудаляемый (auto-delete)
+		false,          // внутренний (internal)
+		false,          // не ждать подтверждения (no-wait)
+		nil,            // аргументы
+	)
+	if err != nil {
+		return declarationError("exchange", c.exchangeName, c.exchangeType, err)
+	}
+
+	for name := range c.queues {
+		if err := c.declareQueue(channel, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// declareQueueSeparately объявляет очередь во временном канале соединения. Вызывается под c.mutex.
+func (c *Consumer) declareQueueSeparately(name string) error {
+	channel, err := c.connection.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to create channel to declare queue %s: %v", name, err)
+	}
+	defer channel.Close()
+
+	return c.declareQueue(channel, name)
+}
+
+// declareQueue объявляет очередь с настройками потребителя
+func (c *Consumer) declareQueue(channel *amqp.Channel, name string) error {
+	_, err := channel.QueueDeclare(
+		name,                      // имя очереди
+		c.options.QueueDurable,    // долговечная (durable)
+		c.options.QueueAutoDelete, // автоудаляемая (auto-delete)
+		c.options.QueueExclusive,  // эксклюзивная (exclusive)
+		c.options.QueueNoWait,     // не ждать подтверждения (no-wait)
+		c.options.QueueArgs,       // аргументы
+	)
+	if err != nil {
+		return declarationError("queue", name, "", err)
+	}
+	return nil
+}
+
+// bind привязывает очередь к обменнику по ключу маршрутизации. Вызывается под c.mutex.
+func (c *Consumer) bind(queueName, routingKey string) error {
+	if err := c.channel.QueueBind(
+		queueName,          // имя очереди
+		routingKey,         // ключ маршрутизации
+		c.exchangeName,     // имя обменника
+		false,              // не ждать подтверждения (no-wait)
+		c.options.BindArgs, // аргументы
+	); err != nil {
+		return fmt.Errorf("failed to bind queue %s to exchange: %v", queueName, err)
+	}
+	return nil
+}
+
+// consume запускает получение сообщений из очереди. Вызывается под c.mutex.
+func (c *Consumer) consume(queue *consumerQueue) error {
+	deliveries, err := c.channel.Consume(
+		queue.name,        // имя очереди
+		queue.consumerTag, // потребитель
+		false,             // автоматическое подтверждение
+		false,             // эксклюзивный (exclusive)
+		false,             // локальный (no-local)
+		false,             // не ждать подтверждения (no-wait)
+		nil,               // аргументы
+	)
+	if err != nil {
+		return fmt.Errorf("failed to consume from queue %s: %v", queue.name, err)
+	}
+
+	queue.consuming = true
+
+	// Запускаем обработчик сообщений
+	go c.handleDeliveries(queue.name, deliveries)
+	return nil
+}
+
+// declarationError формирует ошибку объявления; расхождение параметров с существующим
+// объектом (PRECONDITION_FAILED) оборачивается в ErrDeclarationConflict
+func declarationError(kind, name, declaredType string, err error) error {
+	var amqpErr *amqp.Error
+	if errors.As(err, &amqpErr) && amqpErr.Code == amqp.PreconditionFailed {
+		if declaredType != "" {
+			return fmt.Errorf("%w: %s %s declared as %s: %s", ErrDeclarationConflict, kind, name, declaredType, amqpErr.Reason)
+		}
+		return fmt.Errorf("%w: %s %s: %s", ErrDeclarationConflict, kind, name, amqpErr.Reason)
+	}
+	return fmt.Errorf("failed to declare %s %s: %v", kind, name, err)
+}
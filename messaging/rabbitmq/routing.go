@@ -0,0 +1,80 @@
+package rabbitmq
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/streadway/amqp"
+)
+
+// MatchRoutingKey проверяет, соответствует ли ключ маршрутизации шаблону topic обменника:
+// "*" заменяет ровно одно слово, "#" - ноль или более слов
+func MatchRoutingKey(pattern, key string) bool {
+	return matchWords(strings.Split(pattern, "."), strings.Split(key, "."))
+}
+
+func matchWords(pattern, key []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case "#":
+			for i := 0; i <= len(key); i++ {
+				if matchWords(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		case "*":
+			if len(key) == 0 {
+				return false
+			}
+		default:
+			if len(key) == 0 || pattern[0] != key[0] {
+				return false
+			}
+		}
+		pattern, key = pattern[1:], key[1:]
+	}
+	return len(key) == 0
+}
+
+// handlerFor возвращает обработчик очереди для ключа маршрутизации (см. consumerQueue.handlerFor).
+// Вызывается под c.mutex.
+func (c *Consumer) handlerFor(queueName, routingKey string) (HandlerFunc, bool) {
+	queue, ok := c.queues[queueName]
+	if !ok {
+		return nil, false
+	}
+	return queue.handlerFor(routingKey, c.exchangeType)
+}
+
+// handlerFor возвращает обработчик для ключа маршрутизации: подписку с точно таким ключом
+// или, для topic обменника, первую по алфавиту подписку с подходящим шаблоном.
+// Для fanout и headers обменников остальные сообщения получает подписка с пустым ключом.
+func (q *consumerQueue) handlerFor(routingKey, exchangeType string) (HandlerFunc, bool) {
+	if handler, ok := q.handlers[routingKey]; ok {
+		return handler, true
+	}
+
+	switch exchangeType {
+	case amqp.ExchangeFanout, amqp.ExchangeHeaders:
+		handler, ok := q.handlers[""]
+		return handler, ok
+	case amqp.ExchangeDirect:
+		return nil, false
+	}
+
+	patterns := make([]string, 0, len(q.handlers))
+	for pattern := range q.handlers {
+		if strings.ContainsAny(pattern, "*#") {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		if MatchRoutingKey(pattern, routingKey) {
+			return q.handlers[pattern], true
+		}
+	}
+	return nil, false
+}
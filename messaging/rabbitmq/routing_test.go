@@ -0,0 +1,27 @@
+package rabbitmq
+
+import "testing"
+
+func TestMatchRoutingKey(t *testing.T) {
+	cases := []struct {
+		pattern, key string
+		want         bool
+	}{
+		{"order.created", "order.created", true},
+		{"order.created", "order.paid", false},
+		{"order.*", "order.created", true},
+		{"order.*", "order.created.v2", false},
+		{"order.#", "order", true},
+		{"order.#", "order.created.v2", true},
+		{"#", "anything.at.all", true},
+		{"*.created", "user.created", true},
+		{"#.failed", "billing.payment.failed", true},
+		{"#.failed", "billing.payment.paid", false},
+	}
+
+	for _, tc := range cases {
+		if got := MatchRoutingKey(tc.pattern, tc.key); got != tc.want {
+			t.Errorf("MatchRoutingKey(%q, %q) = %v, want %v", tc.pattern, tc.key, got, tc.want)
+		}
+	}
+}
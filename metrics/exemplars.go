@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/vladzorgan/common/killswitch"
+	"github.com/vladzorgan/common/logging"
+)
+
+// Ключи меток exemplar
+const (
+	ExemplarTraceIDLabel   = "trace_id"
+	ExemplarRequestIDLabel = "request_id"
+)
+
+// maxExemplarValueLength ограничивает длину значения: суммарная длина меток exemplar не должна превышать 128 символов
+const maxExemplarValueLength = 64
+
+var (
+	exemplarsEnabled atomic.Bool
+
+	traceIDExtractor      func(ctx context.Context) string
+	traceIDExtractorMutex sync.RWMutex
+)
+
+// EnableExemplars включает прикрепление exemplar к гистограммам длительности запросов.
+// Exemplar отдаются только в формате OpenMetrics, поэтому обработчик /metrics
+// должен создаваться через Handler после вызова этой функции.
+// Отключается выключателем killswitch.FeatureExemplars.
+func EnableExemplars(enabled bool) {
+	exemplarsEnabled.Store(enabled)
+}
+
+// ExemplarsEnabled проверяет, включены ли exemplar
+func ExemplarsEnabled() bool {
+	return exemplarsEnabled.Load() && !killswitch.IsDisabled(killswitch.FeatureExemplars)
+}
+
+// SetTraceIDExtractor задает функцию получения trace id из контекста (например, из OpenTelemetry).
+// Если trace id не найден, в exemplar записывается request id.
+func SetTraceIDExtractor(fn func(ctx context.Context) string) {
+	traceIDExtractorMutex.Lock()
+	defer traceIDExtractorMutex.Unlock()
+
+	traceIDExtractor = fn
+}
+
+// ExemplarLabels возвращает метки exemplar для контекста запроса или nil, если идентификаторов нет
+func ExemplarLabels(ctx context.Context) prometheus.Labels {
+	traceIDExtractorMutex.RLock()
+	extractor := traceIDExtractor
+	traceIDExtractorMutex.RUnlock()
+
+	if extractor != nil {
+		if traceID := extractor(ctx); traceID != "" {
+			return prometheus.Labels{ExemplarTraceIDLabel: truncateExemplarValue(traceID)}
+		}
+	}
+
+	if requestID := logging.ExtractRequestID(ctx); requestID != "" {
+		return prometheus.Labels{ExemplarRequestIDLabel: truncateExemplarValue(requestID)}
+	}
+
+	return nil
+}
+
+// ObserveWithExemplar записывает значение в наблюдатель, прикрепляя exemplar с trace id
+// или request id, если exemplar включены
+func ObserveWithExemplar(ctx context.Context, observer prometheus.Observer, value float64) {
+	if ExemplarsEnabled() {
+		if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok {
+			if labels := ExemplarLabels(ctx); labels != nil {
+				exemplarObserver.ObserveWithExemplar(value, labels)
+				return
+			}
+		}
+	}
+
+	observer.Observe(value)
+}
+
+// Handler возвращает обработчик /metrics. При включенных exemplar поддерживается
+// согласование формата OpenMetrics, без которого exemplar не экспортируются.
+func Handler() http.Handler {
+	return promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+			EnableOpenMetrics: exemplarsEnabled.Load(),
+		}),
+	)
+}
+
+// truncateExemplarValue обрезает значение метки до допустимой длины
+func truncateExemplarValue(value string) string {
+	if len(value) > maxExemplarValueLength {
+		return value[:maxExemplarValueLength]
+	}
+	return value
+}
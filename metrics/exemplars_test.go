@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/vladzorgan/common/logging"
+)
+
+// gatherExemplars возвращает метки exemplar всех бакетов гистограмм с именем name
+func gatherExemplars(t *testing.T, gatherer prometheus.Gatherer, name string) []map[string]string {
+	t.Helper()
+
+	families, err := gatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	var exemplars []map[string]string
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, bucket := range metric.GetHistogram().GetBucket() {
+				if exemplar := bucket.GetExemplar(); exemplar != nil {
+					exemplars = append(exemplars, exemplarLabels(exemplar))
+				}
+			}
+		}
+	}
+	return exemplars
+}
+
+func exemplarLabels(exemplar *dto.Exemplar) map[string]string {
+	labels := make(map[string]string, len(exemplar.GetLabel()))
+	for _, pair := range exemplar.GetLabel() {
+		labels[pair.GetName()] = pair.GetValue()
+	}
+	return labels
+}
+
+// enableExemplarsForTest включает exemplar на время теста
+func enableExemplarsForTest(t *testing.T, enabled bool) {
+	t.Helper()
+
+	previous := exemplarsEnabled.Load()
+	EnableExemplars(enabled)
+	t.Cleanup(func() { EnableExemplars(previous) })
+}
+
+func TestObserveWithExemplar(t *testing.T) {
+	longID := strings.Repeat("a", 100)
+
+	tests := []struct {
+		name      string
+		enabled   bool
+		requestID string
+		traceID   string
+		want      map[string]string
+	}{
+		{name: "disabled", requestID: "req-1"},
+		{name: "request id", enabled: true, requestID: "req-1", want: map[string]string{ExemplarRequestIDLabel: "req-1"}},
+		{name: "trace id preferred", enabled: true, requestID: "req-1", traceID: "trace-1", want: map[string]string{ExemplarTraceIDLabel: "trace-1"}},
+		{name: "no identifiers", enabled: true},
+		{name: "truncated", enabled: true, requestID: longID, want: map[string]string{ExemplarRequestIDLabel: longID[:maxExemplarValueLength]}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enableExemplarsForTest(t, tt.enabled)
+			SetTraceIDExtractor(func(context.Context) string { return tt.traceID })
+			t.Cleanup(func() { SetTraceIDExtractor(nil) })
+
+			registry := prometheus.NewRegistry()
+			histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "latency_ms", Buckets: []float64{10, 100}})
+			registry.MustRegister(histogram)
+
+			ctx := context.Background()
+			if tt.requestID != "" {
+				ctx = logging.ContextWithRequestID(ctx, tt.requestID)
+			}
+			ObserveWithExemplar(ctx, histogram, 42)
+
+			exemplars := gatherExemplars(t, registry, "latency_ms")
+			if tt.want == nil {
+				if len(exemplars) != 0 {
+					t.Errorf("exemplars = %v, want none", exemplars)
+				}
+				return
+			}
+			if len(exemplars) != 1 || len(exemplars[0]) != len(tt.want) {
+				t.Fatalf("exemplars = %v, want %v", exemplars, tt.want)
+			}
+			for key, value := range tt.want {
+				if exemplars[0][key] != value {
+					t.Errorf("exemplar %s = %q, want %q", key, exemplars[0][key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestMetricsMiddlewareExemplars(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, enabled := range []bool{false, true} {
+		enableExemplarsForTest(t, enabled)
+		registry := prometheus.NewRegistry()
+		InitMetrics("exemplar_test", WithRegisterer(registry), WithRuntimeMetrics(false))
+
+		router := gin.New()
+		router.Use(func(c *gin.Context) { c.Set("RequestID", "req-42") })
+		router.Use(MetricsMiddleware())
+		router.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+		exemplars := gatherExemplars(t, registry, "exemplar_test_request_duration_ms")
+		switch {
+		case !enabled && len(exemplars) != 0:
+			t.Errorf("disabled: exemplars = %v, want none", exemplars)
+		case enabled && (len(exemplars) != 1 || exemplars[0][ExemplarRequestIDLabel] != "req-42"):
+			t.Errorf("enabled: exemplars = %v, want request_id req-42", exemplars)
+		}
+	}
+}
+
+func TestHandlerNegotiatesOpenMetrics(t *testing.T) {
+	histogram := Register(prometheus.DefaultRegisterer, prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "exemplar_handler_test_ms",
+		Buckets: []float64{10},
+	}))
+
+	for _, enabled := range []bool{false, true} {
+		enableExemplarsForTest(t, enabled)
+		ObserveWithExemplar(logging.ContextWithRequestID(context.Background(), "req-7"), histogram, 1)
+
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+		w := httptest.NewRecorder()
+		Handler().ServeHTTP(w, req)
+
+		body, _ := io.ReadAll(w.Body)
+		contentType := w.Header().Get("Content-Type")
+		hasExemplar := strings.Contains(string(body), `# {request_id="req-7"}`)
+
+		if enabled && (!strings.HasPrefix(contentType, "application/openmetrics-text") || !hasExemplar) {
+			t.Errorf("enabled: Content-Type = %s, exemplar exported = %v", contentType, hasExemplar)
+		}
+		if !enabled && (strings.HasPrefix(contentType, "application/openmetrics-text") || hasExemplar) {
+			t.Errorf("disabled: Content-Type = %s, exemplar exported = %v", contentType, hasExemplar)
+		}
+	}
+}
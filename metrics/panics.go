@@ -0,0 +1,21 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// PanicsRecovered возвращает счетчик паник, перехваченных middleware и интерцепторами восстановления
+// (метка method - gRPC метод или HTTP маршрут). Счетчик с тем же префиксом в реестре общий для
+// gRPC и HTTP, поэтому повторный вызов возвращает уже зарегистрированный.
+func PanicsRecovered(registerer prometheus.Registerer, servicePrefix string) *prometheus.CounterVec {
+	name := "panics_recovered_total"
+	if servicePrefix != "" {
+		name = servicePrefix + "_" + name
+	}
+
+	return Register(registerer, prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name,
+			Help: "Total number of recovered panics",
+		},
+		[]string{"method"},
+	))
+}
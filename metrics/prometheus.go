@@ -2,12 +2,15 @@
 package metrics
 
 import (
+	"context"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/vladzorgan/common/http/httpctx"
+	"github.com/vladzorgan/common/logging"
 )
 
 var (
@@ -30,62 +33,85 @@ var (
 	CustomMetrics map[string]interface{}
 )
 
-// InitMetrics инициализирует метрики Prometheus
-func InitMetrics(servicePrefix string) {
+// InitMetrics инициализирует метрики Prometheus. Метрики рантайма Go, процесса,
+// пула БД и gRPC клиентов регистрируются по умолчанию (см. WithRuntimeMetrics).
+// Повторный вызов с тем же реестром переиспользует уже зарегистрированные метрики.
+func InitMetrics(servicePrefix string, opts ...InitOption) {
+	options := &initOptions{
+		registerer:     prometheus.DefaultRegisterer,
+		runtimeMetrics: true,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	setRegisterer(options.registerer)
+
 	// Инициализируем карту пользовательских метрик
 	CustomMetrics = make(map[string]interface{})
 
 	// Счетчик общего числа запросов
-	RequestsTotal = promauto.NewCounterVec(
+	RequestsTotal = Register(options.registerer, prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: servicePrefix + "_requests_total",
 			Help: "Общее количество запросов к сервису",
 		},
 		[]string{"method", "path", "status"},
-	)
+	))
 
 	// Гистограмма времени обработки запросов
-	RequestDuration = promauto.NewHistogramVec(
+	RequestDuration = Register(options.registerer, prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    servicePrefix + "_request_duration_ms",
 			Help:    "Продолжительность запроса в миллисекундах",
 			Buckets: prometheus.ExponentialBuckets(1, 2, 15), // От 1мс до ~16с
 		},
 		[]string{"method", "path", "status"},
-	)
+	))
 
 	// Гистограмма размера ответов
-	ResponseSize = promauto.NewHistogramVec(
+	ResponseSize = Register(options.registerer, prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    servicePrefix + "_response_size_bytes",
 			Help:    "Размер ответа в байтах",
 			Buckets: prometheus.ExponentialBuckets(100, 10, 8), // От 100Б до ~100МБ
 		},
 		[]string{"method", "path"},
-	)
+	))
 
 	// Счетчик активных запросов
-	ActiveRequests = promauto.NewGauge(
+	ActiveRequests = Register(options.registerer, prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Name: servicePrefix + "_active_requests",
 			Help: "Количество активных запросов",
 		},
-	)
+	))
 
 	// Счетчик времени работы сервера
-	ServerUptime = promauto.NewCounter(
+	ServerUptime = Register(options.registerer, prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Name: servicePrefix + "_uptime_seconds",
 			Help: "Время работы сервера в секундах",
 		},
-	)
+	))
+
+	if options.runtimeMetrics {
+		if err := registerRuntimeMetrics(options.registerer); err != nil {
+			logging.NewLogger().Warn("Failed to register runtime metrics: %v", err)
+		}
+	}
 }
 
 // RecordRequest записывает метрики о запросе
 func RecordRequest(method, path string, status int, durationMs float64, sizeBytes int64) {
-	statusStr := string(rune(status))
+	RecordRequestContext(context.Background(), method, path, status, durationMs, sizeBytes)
+}
+
+// RecordRequestContext записывает метрики о запросе, прикрепляя к длительности exemplar
+// с идентификатором запроса из контекста (см. EnableExemplars)
+func RecordRequestContext(ctx context.Context, method, path string, status int, durationMs float64, sizeBytes int64) {
+	statusStr := strconv.Itoa(status)
 	RequestsTotal.WithLabelValues(method, path, statusStr).Inc()
-	RequestDuration.WithLabelValues(method, path, statusStr).Observe(durationMs)
+	ObserveWithExemplar(ctx, RequestDuration.WithLabelValues(method, path, statusStr), durationMs)
 	ResponseSize.WithLabelValues(method, path).Observe(float64(sizeBytes))
 }
 
@@ -110,60 +136,98 @@ func UpdateUptime(startTime time.Time) {
 	}()
 }
 
-// RegisterCounter регистрирует и возвращает новый счетчик
+// RegisterCounter регистрирует и возвращает новый счетчик в реестре из InitMetrics
+// (по умолчанию prometheus.DefaultRegisterer); повторная регистрация возвращает существующий
 func RegisterCounter(servicePrefix, name, help string, labelNames ...string) *prometheus.CounterVec {
-	counter := promauto.NewCounterVec(
+	counter := Register(currentRegisterer(), prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: servicePrefix + "_" + name,
 			Help: help,
 		},
 		labelNames,
-	)
+	))
 	CustomMetrics[name] = counter
 	return counter
 }
 
 // RegisterGauge регистрирует и возвращает новый gauge
 func RegisterGauge(servicePrefix, name, help string, labelNames ...string) *prometheus.GaugeVec {
-	gauge := promauto.NewGaugeVec(
+	gauge := Register(currentRegisterer(), prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: servicePrefix + "_" + name,
 			Help: help,
 		},
 		labelNames,
-	)
+	))
 	CustomMetrics[name] = gauge
 	return gauge
 }
 
 // RegisterHistogram регистрирует и возвращает новую гистограмму
 func RegisterHistogram(servicePrefix, name, help string, buckets []float64, labelNames ...string) *prometheus.HistogramVec {
-	histogram := promauto.NewHistogramVec(
+	histogram := Register(currentRegisterer(), prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    servicePrefix + "_" + name,
 			Help:    help,
 			Buckets: buckets,
 		},
 		labelNames,
-	)
+	))
 	CustomMetrics[name] = histogram
 	return histogram
 }
 
 // RegisterSummary регистрирует и возвращает новое summary
 func RegisterSummary(servicePrefix, name, help string, objectives map[float64]float64, labelNames ...string) *prometheus.SummaryVec {
-	summary := promauto.NewSummaryVec(
+	summary := Register(currentRegisterer(), prometheus.NewSummaryVec(
 		prometheus.SummaryOpts{
 			Name:       servicePrefix + "_" + name,
 			Help:       help,
 			Objectives: objectives,
 		},
 		labelNames,
-	)
+	))
 	CustomMetrics[name] = summary
 	return summary
 }
 
+// UnmatchedPath значение метки path для запросов, не совпавших ни с одним маршрутом
+const UnmatchedPath = "unmatched"
+
+var (
+	pathNormalizer      func(c *gin.Context) string
+	pathNormalizerMutex sync.RWMutex
+)
+
+// SetPathNormalizer задает функцию получения метки path для сервисов с динамическими маршрутами
+// (например, обрабатываемыми через NoRoute). Пустой результат означает метку по умолчанию:
+// шаблон маршрута (c.FullPath()) или UnmatchedPath. Значения должны быть из ограниченного набора.
+func SetPathNormalizer(fn func(c *gin.Context) string) {
+	pathNormalizerMutex.Lock()
+	defer pathNormalizerMutex.Unlock()
+
+	pathNormalizer = fn
+}
+
+// requestPath возвращает метку path запроса. Используется шаблон маршрута, а не URL,
+// чтобы идентификаторы в пути не порождали новые временные ряды.
+func requestPath(c *gin.Context) string {
+	pathNormalizerMutex.RLock()
+	normalizer := pathNormalizer
+	pathNormalizerMutex.RUnlock()
+
+	if normalizer != nil {
+		if path := normalizer(c); path != "" {
+			return path
+		}
+	}
+
+	if path := c.FullPath(); path != "" {
+		return path
+	}
+	return UnmatchedPath
+}
+
 // MetricsMiddleware возвращает middleware для сбора метрик
 func MetricsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -180,10 +244,17 @@ func MetricsMiddleware() gin.HandlerFunc {
 		// Вычисляем продолжительность запроса
 		duration := time.Since(startTime)
 
+		// Request ID из middleware RequestID используется как exemplar
+		ctx := c.Request.Context()
+		if requestID := httpctx.RequestID(c); requestID != "" && logging.ExtractRequestID(ctx) == "" {
+			ctx = logging.ContextWithRequestID(ctx, requestID)
+		}
+
 		// Обновляем метрики
-		RecordRequest(
+		RecordRequestContext(
+			ctx,
 			c.Request.Method,
-			c.Request.URL.Path,
+			requestPath(c),
 			c.Writer.Status(),
 			duration.Seconds()*1000, // миллисекунды
 			int64(c.Writer.Size()),
@@ -193,7 +264,7 @@ func MetricsMiddleware() gin.HandlerFunc {
 
 // PrometheusHandler возвращает обработчик для метрик Prometheus
 func PrometheusHandler() gin.HandlerFunc {
-	h := promhttp.Handler()
+	h := Handler()
 
 	return func(c *gin.Context) {
 		h.ServeHTTP(c.Writer, c.Request)
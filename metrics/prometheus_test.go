@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsMiddlewareUsesRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	InitMetrics("path_test", WithRegisterer(prometheus.NewRegistry()), WithRuntimeMetrics(false))
+
+	router := gin.New()
+	router.Use(MetricsMiddleware())
+	router.GET("/api/orders/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/files/*path", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/api/orders/1", "/api/orders/12345", "/files/a/b.txt", "/missing/1", "/missing/2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	tests := []struct {
+		path   string
+		status string
+		want   float64
+	}{
+		{"/api/orders/:id", "200", 2},
+		{"/files/*path", "200", 1},
+		{UnmatchedPath, "404", 2},
+	}
+	for _, tt := range tests {
+		if got := testutil.ToFloat64(RequestsTotal.WithLabelValues(http.MethodGet, tt.path, tt.status)); got != tt.want {
+			t.Errorf("requests_total{path=%q,status=%q} = %v, want %v", tt.path, tt.status, got, tt.want)
+		}
+	}
+	if series := testutil.CollectAndCount(RequestsTotal); series != len(tests) {
+		t.Errorf("requests_total series = %d, want %d", series, len(tests))
+	}
+}
+
+func TestSetPathNormalizer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	InitMetrics("normalizer_test", WithRegisterer(prometheus.NewRegistry()), WithRuntimeMetrics(false))
+
+	SetPathNormalizer(func(c *gin.Context) string {
+		if strings.HasPrefix(c.Request.URL.Path, "/pages/") {
+			return "/pages/:slug"
+		}
+		return ""
+	})
+	t.Cleanup(func() { SetPathNormalizer(nil) })
+
+	router := gin.New()
+	router.Use(MetricsMiddleware())
+	router.NoRoute(func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/pages/") {
+			c.Status(http.StatusOK)
+			return
+		}
+		c.Status(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/pages/about", "/pages/contacts", "/other"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := testutil.ToFloat64(RequestsTotal.WithLabelValues(http.MethodGet, "/pages/:slug", "200")); got != 2 {
+		t.Errorf("normalized path count = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(RequestsTotal.WithLabelValues(http.MethodGet, UnmatchedPath, "404")); got != 1 {
+		t.Errorf("unmatched path count = %v, want 1", got)
+	}
+}
+
+func TestInitMetricsTwiceReusesCollectors(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	InitMetrics("twice_test", WithRegisterer(registry), WithRuntimeMetrics(false))
+	first := RequestsTotal
+
+	InitMetrics("twice_test", WithRegisterer(registry), WithRuntimeMetrics(false))
+	if RequestsTotal != first {
+		t.Error("RequestsTotal was not reused")
+	}
+
+	counter := RegisterCounter("twice_test", "jobs_total", "Jobs", "type")
+	if again := RegisterCounter("twice_test", "jobs_total", "Jobs", "type"); again != counter {
+		t.Error("custom counter was not reused")
+	}
+	counter.WithLabelValues("import").Inc()
+	if got := testutil.CollectAndCount(registry, "twice_test_jobs_total"); got != 1 {
+		t.Errorf("custom counter series in registry = %d, want 1", got)
+	}
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	registererMutex sync.RWMutex
+	// registerer реестр пользовательских метрик (RegisterCounter и т.д.), задается в InitMetrics
+	registerer prometheus.Registerer = prometheus.DefaultRegisterer
+)
+
+// Register регистрирует коллектор в registerer (nil - prometheus.DefaultRegisterer).
+// Если такой же коллектор уже зарегистрирован, возвращается существующий, поэтому повторное
+// создание метрик (например, двух серверов в тестах) не приводит к панике.
+// Конфликт с другой метрикой того же имени по-прежнему вызывает панику.
+func Register[C prometheus.Collector](registerer prometheus.Registerer, collector C) C {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	if err := registerer.Register(collector); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			if existing, ok := alreadyRegistered.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+
+	return collector
+}
+
+// setRegisterer задает реестр пользовательских метрик
+func setRegisterer(r prometheus.Registerer) {
+	registererMutex.Lock()
+	defer registererMutex.Unlock()
+
+	registerer = r
+}
+
+// currentRegisterer возвращает реестр пользовательских метрик
+func currentRegisterer() prometheus.Registerer {
+	registererMutex.RLock()
+	defer registererMutex.RUnlock()
+
+	return registerer
+}
@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// InitOption настраивает InitMetrics
+type InitOption func(*initOptions)
+
+// initOptions содержит настройки InitMetrics
+type initOptions struct {
+	registerer     prometheus.Registerer
+	runtimeMetrics bool
+}
+
+// WithRegisterer задает Registerer, в котором регистрируются метрики.
+// По умолчанию используется prometheus.DefaultRegisterer, который отдается на /metrics.
+func WithRegisterer(registerer prometheus.Registerer) InitOption {
+	return func(o *initOptions) {
+		o.registerer = registerer
+	}
+}
+
+// WithRuntimeMetrics включает или отключает метрики рантайма Go, процесса,
+// пула соединений БД и gRPC клиентов. По умолчанию включены.
+func WithRuntimeMetrics(enabled bool) InitOption {
+	return func(o *initOptions) {
+		o.runtimeMetrics = enabled
+	}
+}
+
+// DBStatsProvider источник статистики пула соединений БД, например *sql.DB
+type DBStatsProvider interface {
+	Stats() sql.DBStats
+}
+
+// ConnectionCounter источник количества активных gRPC соединений, например grpc_clients.ClientRegistry
+type ConnectionCounter interface {
+	ConnectionCount() int
+}
+
+var (
+	runtimeSourcesMutex sync.RWMutex
+	dbStatsProvider     DBStatsProvider
+	connectionCounter   ConnectionCounter
+)
+
+// AttachDatabase подключает пул соединений БД к метрикам db_pool_*.
+// Может вызываться после InitMetrics, когда подключение к БД уже создано.
+func AttachDatabase(provider DBStatsProvider) {
+	runtimeSourcesMutex.Lock()
+	defer runtimeSourcesMutex.Unlock()
+
+	dbStatsProvider = provider
+}
+
+// AttachGRPCClients подключает реестр gRPC клиентов к метрике grpc_client_connections
+func AttachGRPCClients(counter ConnectionCounter) {
+	runtimeSourcesMutex.Lock()
+	defer runtimeSourcesMutex.Unlock()
+
+	connectionCounter = counter
+}
+
+// registerRuntimeMetrics регистрирует коллекторы Go и процесса (в том числе process_open_fds)
+// и коллектор пула БД и gRPC клиентов. Уже зарегистрированные коллекторы пропускаются.
+func registerRuntimeMetrics(registerer prometheus.Registerer) error {
+	collectorsToRegister := []prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		newRuntimeCollector(),
+	}
+
+	var errs []error
+	for _, collector := range collectorsToRegister {
+		if err := registerer.Register(collector); err != nil {
+			var alreadyRegistered prometheus.AlreadyRegisteredError
+			if !errors.As(err, &alreadyRegistered) {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// runtimeCollector собирает метрики подключенных через AttachDatabase и AttachGRPCClients источников
+type runtimeCollector struct {
+	dbMaxOpen        *prometheus.Desc
+	dbOpen           *prometheus.Desc
+	dbInUse          *prometheus.Desc
+	dbIdle           *prometheus.Desc
+	dbWaitCount      *prometheus.Desc
+	grpcClientsConns *prometheus.Desc
+}
+
+func newRuntimeCollector() *runtimeCollector {
+	return &runtimeCollector{
+		dbMaxOpen:        prometheus.NewDesc("db_pool_max_open_connections", "Максимальное количество соединений пула БД из конфигурации", nil, nil),
+		dbOpen:           prometheus.NewDesc("db_pool_open_connections", "Количество открытых соединений пула БД", nil, nil),
+		dbInUse:          prometheus.NewDesc("db_pool_in_use_connections", "Количество используемых соединений пула БД", nil, nil),
+		dbIdle:           prometheus.NewDesc("db_pool_idle_connections", "Количество простаивающих соединений пула БД", nil, nil),
+		dbWaitCount:      prometheus.NewDesc("db_pool_wait_count_total", "Количество ожиданий свободного соединения пула БД", nil, nil),
+		grpcClientsConns: prometheus.NewDesc("grpc_client_connections", "Количество активных gRPC клиентских соединений", nil, nil),
+	}
+}
+
+// Describe реализует prometheus.Collector
+func (c *runtimeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.dbMaxOpen
+	ch <- c.dbOpen
+	ch <- c.dbInUse
+	ch <- c.dbIdle
+	ch <- c.dbWaitCount
+	ch <- c.grpcClientsConns
+}
+
+// Collect реализует prometheus.Collector. Метрики неподключенных источников не отдаются.
+func (c *runtimeCollector) Collect(ch chan<- prometheus.Metric) {
+	runtimeSourcesMutex.RLock()
+	db, grpcClients := dbStatsProvider, connectionCounter
+	runtimeSourcesMutex.RUnlock()
+
+	if db != nil {
+		stats := db.Stats()
+		ch <- prometheus.MustNewConstMetric(c.dbMaxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+		ch <- prometheus.MustNewConstMetric(c.dbOpen, prometheus.GaugeValue, float64(stats.OpenConnections))
+		ch <- prometheus.MustNewConstMetric(c.dbInUse, prometheus.GaugeValue, float64(stats.InUse))
+		ch <- prometheus.MustNewConstMetric(c.dbIdle, prometheus.GaugeValue, float64(stats.Idle))
+		ch <- prometheus.MustNewConstMetric(c.dbWaitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	}
+
+	if grpcClients != nil {
+		ch <- prometheus.MustNewConstMetric(c.grpcClientsConns, prometheus.GaugeValue, float64(grpcClients.ConnectionCount()))
+	}
+}
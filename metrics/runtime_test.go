@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"database/sql"
+	"runtime"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fakeDB struct{}
+
+func (fakeDB) Stats() sql.DBStats {
+	return sql.DBStats{MaxOpenConnections: 100, OpenConnections: 12, InUse: 5, Idle: 7}
+}
+
+type fakeRegistry struct{}
+
+func (fakeRegistry) ConnectionCount() int { return 3 }
+
+func gatherValues(t *testing.T, registry *prometheus.Registry) map[string]float64 {
+	t.Helper()
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	values := make(map[string]float64, len(families))
+	for _, family := range families {
+		metric := family.GetMetric()[0]
+		switch {
+		case metric.GetGauge() != nil:
+			values[family.GetName()] = metric.GetGauge().GetValue()
+		case metric.GetCounter() != nil:
+			values[family.GetName()] = metric.GetCounter().GetValue()
+		default:
+			values[family.GetName()] = 0
+		}
+	}
+	return values
+}
+
+func TestInitMetricsRegistersRuntimeCollectors(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	InitMetrics("test", WithRegisterer(registry))
+
+	AttachDatabase(fakeDB{})
+	AttachGRPCClients(fakeRegistry{})
+	t.Cleanup(func() {
+		AttachDatabase(nil)
+		AttachGRPCClients(nil)
+	})
+
+	values := gatherValues(t, registry)
+
+	required := []string{"go_goroutines", "go_gc_duration_seconds", "go_memstats_heap_alloc_bytes", "test_active_requests"}
+	if runtime.GOOS == "linux" {
+		required = append(required, "process_open_fds", "process_resident_memory_bytes")
+	}
+	for _, name := range required {
+		if _, ok := values[name]; !ok {
+			t.Errorf("metric %s is not registered", name)
+		}
+	}
+
+	expected := map[string]float64{
+		"db_pool_max_open_connections": 100,
+		"db_pool_in_use_connections":   5,
+		"db_pool_idle_connections":     7,
+		"grpc_client_connections":      3,
+	}
+	for name, want := range expected {
+		if got, ok := values[name]; !ok || got != want {
+			t.Errorf("%s = %v (present %v), want %v", name, got, ok, want)
+		}
+	}
+}
+
+func TestRuntimeMetricsDuplicateRegistration(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	for i := 0; i < 2; i++ {
+		if err := registerRuntimeMetrics(registry); err != nil {
+			t.Fatalf("registerRuntimeMetrics() call %d error = %v", i+1, err)
+		}
+	}
+
+	// Коллекторы Go и процесса уже зарегистрированы в DefaultRegisterer
+	if err := registerRuntimeMetrics(prometheus.DefaultRegisterer); err != nil {
+		t.Errorf("registerRuntimeMetrics(DefaultRegisterer) error = %v", err)
+	}
+}
+
+func TestRuntimeMetricsWithoutSources(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	InitMetrics("bare", WithRegisterer(registry))
+
+	values := gatherValues(t, registry)
+	if _, ok := values["db_pool_max_open_connections"]; ok {
+		t.Error("db pool metrics exported without attached database")
+	}
+}
+
+func TestInitMetricsWithoutRuntimeMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	InitMetrics("plain", WithRegisterer(registry), WithRuntimeMetrics(false))
+
+	if _, ok := gatherValues(t, registry)["go_goroutines"]; ok {
+		t.Error("go collector registered with WithRuntimeMetrics(false)")
+	}
+}
@@ -0,0 +1,50 @@
+package profiling
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/http/httpctx"
+)
+
+// GinMiddleware возвращает middleware, выполняющий обработку запроса с метками pprof
+// (метод, шаблон маршрута, сущность, ID запроса). Должен подключаться после middleware.RequestID.
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Enabled() {
+			c.Next()
+			return
+		}
+
+		// Шаблон маршрута не раскрывает идентификаторы и ограничивает количество значений метки
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		labels := map[string]string{
+			LabelMethod:    c.Request.Method,
+			LabelPath:      route,
+			LabelEntity:    routeEntity(route),
+			LabelRequestID: requestLabel(c.Request.Context(), httpctx.RequestID(c)),
+		}
+
+		Do(c.Request.Context(), labels, func(ctx context.Context) {
+			c.Request = c.Request.WithContext(ctx)
+			c.Next()
+		})
+	}
+}
+
+// routeEntity возвращает последний сегмент маршрута без параметров: /api/v1/orders/:id -> orders
+func routeEntity(route string) string {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := segments[i]
+		if segment != "" && segment[0] != ':' && segment[0] != '*' {
+			return segment
+		}
+	}
+	return ""
+}
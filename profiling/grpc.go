@@ -0,0 +1,73 @@
+package profiling
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryServerInterceptor создает интерцептор, выполняющий обработку вызова с метками pprof.
+// Должен стоять после LoggingUnaryInterceptor, который помещает ID запроса в контекст.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		if !Enabled() {
+			return handler(ctx, req)
+		}
+
+		Do(ctx, grpcLabels(ctx, info.FullMethod), func(ctx context.Context) {
+			resp, err = handler(ctx, req)
+		})
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor создает потоковый интерцептор, выполняющий обработку с метками pprof
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		if !Enabled() {
+			return handler(srv, ss)
+		}
+
+		Do(ss.Context(), grpcLabels(ss.Context(), info.FullMethod), func(ctx context.Context) {
+			err = handler(srv, &labeledServerStream{ServerStream: ss, ctx: ctx})
+		})
+		return err
+	}
+}
+
+// grpcLabels возвращает метки вызова: полный метод и сервис (/location.LocationService/GetRegion -> LocationService)
+func grpcLabels(ctx context.Context, fullMethod string) map[string]string {
+	entity := strings.TrimPrefix(fullMethod, "/")
+	if i := strings.IndexByte(entity, '/'); i >= 0 {
+		entity = entity[:i]
+	}
+	if i := strings.LastIndexByte(entity, '.'); i >= 0 {
+		entity = entity[i+1:]
+	}
+
+	// Потоковые вызовы не помещают ID запроса в контекст, берем его из метаданных
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("x-request-id"); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+
+	return map[string]string{
+		LabelMethod:    fullMethod,
+		LabelEntity:    entity,
+		LabelRequestID: requestLabel(ctx, requestID),
+	}
+}
+
+// labeledServerStream подменяет контекст потока контекстом с метками
+type labeledServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *labeledServerStream) Context() context.Context {
+	return s.ctx
+}
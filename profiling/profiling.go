@@ -0,0 +1,101 @@
+// Package profiling добавляет метки pprof к обработке HTTP запросов, вызовов gRPC и сообщений RabbitMQ,
+// чтобы горячие участки в CPU профиле можно было отнести к эндпоинту или ключу маршрутизации.
+// Метки немного замедляют обработку, поэтому по умолчанию отключены (см. Enable и config.ProfilingLabels).
+package profiling
+
+import (
+	"context"
+	"runtime/pprof"
+	"strings"
+	"sync/atomic"
+
+	"github.com/vladzorgan/common/logging"
+)
+
+// Ключи меток pprof
+const (
+	LabelMethod     = "method"
+	LabelPath       = "path"
+	LabelRoutingKey = "routing_key"
+	LabelEntity     = "entity"
+	LabelRequestID  = "request_id"
+)
+
+// requestIDLength длина префикса ID запроса в метке: полный ID делает каждый сэмпл уникальным
+const requestIDLength = 8
+
+// enabled показывает, что метки включены
+var enabled atomic.Bool
+
+// Enable включает или выключает добавление меток pprof
+func Enable(value bool) {
+	enabled.Store(value)
+}
+
+// Enabled возвращает true, если метки pprof включены
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Do выполняет fn с метками pprof, добавленными к меткам из ctx.
+// Пустые значения пропускаются; при выключенных метках fn вызывается с исходным контекстом.
+func Do(ctx context.Context, labels map[string]string, fn func(ctx context.Context)) {
+	if !Enabled() {
+		fn(ctx)
+		return
+	}
+
+	pprof.Do(ctx, labelSet(labels), fn)
+}
+
+// WithOperationLabels добавляет метки для дорогого участка кода сервиса и возвращает функцию,
+// восстанавливающую прежние метки горутины:
+//
+//	ctx, done := profiling.WithOperationLabels(ctx, map[string]string{"operation": "price_recalc"})
+//	defer done()
+func WithOperationLabels(ctx context.Context, labels map[string]string) (context.Context, func()) {
+	if !Enabled() {
+		return ctx, func() {}
+	}
+
+	labeled := pprof.WithLabels(ctx, labelSet(labels))
+	pprof.SetGoroutineLabels(labeled)
+
+	return labeled, func() { pprof.SetGoroutineLabels(ctx) }
+}
+
+// labelSet преобразует карту меток в pprof.LabelSet, пропуская пустые значения
+func labelSet(labels map[string]string) pprof.LabelSet {
+	pairs := make([]string, 0, len(labels)*2)
+	for key, value := range labels {
+		if value != "" {
+			pairs = append(pairs, key, value)
+		}
+	}
+	return pprof.Labels(pairs...)
+}
+
+// requestLabel возвращает сокращенный ID запроса из контекста
+func requestLabel(ctx context.Context, requestID string) string {
+	if requestID == "" {
+		requestID = logging.ExtractRequestID(ctx)
+	}
+	if len(requestID) > requestIDLength {
+		requestID = requestID[:requestIDLength]
+	}
+	return requestID
+}
+
+// MessageLabels возвращает метки обработки сообщения: ключ маршрутизации и сущность (первое слово ключа)
+func MessageLabels(ctx context.Context, routingKey string) map[string]string {
+	entity := routingKey
+	if i := strings.IndexByte(routingKey, '.'); i >= 0 {
+		entity = routingKey[:i]
+	}
+
+	return map[string]string{
+		LabelRoutingKey: routingKey,
+		LabelEntity:     entity,
+		LabelRequestID:  requestLabel(ctx, ""),
+	}
+}
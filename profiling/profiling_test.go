@@ -0,0 +1,187 @@
+package profiling
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// busyLoop нагружает CPU в течение d
+func busyLoop(d time.Duration) int {
+	sum := 0
+	for deadline := time.Now().Add(d); time.Now().Before(deadline); {
+		for i := 0; i < 10000; i++ {
+			sum += i * i
+		}
+	}
+	return sum
+}
+
+// sampleLabels разбирает CPU профиль и возвращает метки строк каждого сэмпла
+func sampleLabels(t *testing.T, data []byte) []map[string]string {
+	t.Helper()
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("invalid profile: %v", err)
+	}
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("invalid profile: %v", err)
+	}
+
+	var table []string
+	var samples [][]byte
+	for b := raw; len(b) > 0; {
+		num, typ, n := protowire.ConsumeTag(b)
+		b = b[n:]
+		if typ != protowire.BytesType {
+			b = b[protowire.ConsumeFieldValue(num, typ, b):]
+			continue
+		}
+		value, n := protowire.ConsumeBytes(b)
+		b = b[n:]
+		switch num {
+		case 2: // Profile.sample
+			samples = append(samples, value)
+		case 6: // Profile.string_table
+			table = append(table, string(value))
+		}
+	}
+
+	var result []map[string]string
+	for _, sample := range samples {
+		labels := make(map[string]string)
+		for b := sample; len(b) > 0; {
+			num, typ, n := protowire.ConsumeTag(b)
+			b = b[n:]
+			if num != 3 || typ != protowire.BytesType { // Sample.label
+				b = b[protowire.ConsumeFieldValue(num, typ, b):]
+				continue
+			}
+			label, n := protowire.ConsumeBytes(b)
+			b = b[n:]
+
+			var key, str uint64
+			for l := label; len(l) > 0; {
+				num, typ, n := protowire.ConsumeTag(l)
+				l = l[n:]
+				if typ != protowire.VarintType {
+					l = l[protowire.ConsumeFieldValue(num, typ, l):]
+					continue
+				}
+				v, n := protowire.ConsumeVarint(l)
+				l = l[n:]
+				switch num {
+				case 1:
+					key = v
+				case 2:
+					str = v
+				}
+			}
+			labels[table[key]] = table[str]
+		}
+		result = append(result, labels)
+	}
+	return result
+}
+
+func TestCPUSamplesCarryLabels(t *testing.T) {
+	Enable(true)
+	defer Enable(false)
+
+	var profile bytes.Buffer
+	if err := pprof.StartCPUProfile(&profile); err != nil {
+		t.Skipf("CPU profiling unavailable: %v", err)
+	}
+
+	ctx := context.Background()
+	Do(ctx, MessageLabels(ctx, "order.created"), func(ctx context.Context) {
+		ctx, done := WithOperationLabels(ctx, map[string]string{"operation": "busy"})
+		defer done()
+		busyLoop(300 * time.Millisecond)
+	})
+	pprof.StopCPUProfile()
+
+	labeled := 0
+	for _, labels := range sampleLabels(t, profile.Bytes()) {
+		if labels["operation"] == "busy" {
+			labeled++
+			if labels[LabelRoutingKey] != "order.created" || labels[LabelEntity] != "order" {
+				t.Errorf("sample labels = %v, want routing key and entity of the message", labels)
+			}
+		}
+	}
+	if labeled == 0 {
+		t.Fatal("no CPU samples carry the operation label")
+	}
+}
+
+func TestWithOperationLabelsRestoresLabels(t *testing.T) {
+	Enable(true)
+	defer Enable(false)
+
+	Do(context.Background(), map[string]string{LabelEntity: "order"}, func(ctx context.Context) {
+		labeled, done := WithOperationLabels(ctx, map[string]string{"operation": "recalc"})
+		if value, _ := pprof.Label(labeled, "operation"); value != "recalc" {
+			t.Errorf("operation label = %q", value)
+		}
+		if value, _ := pprof.Label(labeled, LabelEntity); value != "order" {
+			t.Errorf("entity label = %q, parent labels must be kept", value)
+		}
+		done()
+	})
+}
+
+func TestGinMiddlewareLabels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var labels map[string]string
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("RequestID", "0123456789abcdef") })
+	router.Use(GinMiddleware())
+	router.GET("/api/v1/orders/:id", func(c *gin.Context) {
+		labels = map[string]string{}
+		pprof.ForLabels(c.Request.Context(), func(key, value string) bool {
+			labels[key] = value
+			return true
+		})
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/orders/42", nil))
+	if len(labels) != 0 {
+		t.Errorf("labels must be disabled by default, got %v", labels)
+	}
+
+	Enable(true)
+	defer Enable(false)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/orders/42", nil))
+	want := map[string]string{
+		LabelMethod:    http.MethodGet,
+		LabelPath:      "/api/v1/orders/:id",
+		LabelEntity:    "orders",
+		LabelRequestID: "01234567",
+	}
+	for key, value := range want {
+		if labels[key] != value {
+			t.Errorf("label %s = %q, want %q", key, labels[key], value)
+		}
+	}
+}
+
+func TestGRPCLabels(t *testing.T) {
+	labels := grpcLabels(context.Background(), "/location.LocationService/GetRegion")
+	if labels[LabelEntity] != "LocationService" || labels[LabelMethod] != "/location.LocationService/GetRegion" {
+		t.Errorf("grpcLabels() = %v", labels)
+	}
+}
@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Pipeline выполняет команды, добавленные fn, за один обмен с Redis.
+// Результаты читаются из команд, созданных внутри fn, после возврата Pipeline.
+// Отсутствие ключа (redis.Nil) в отдельных командах не считается ошибкой.
+// Конвейер не транзакционный: при ошибке часть команд может быть уже выполнена.
+func (c *Client) Pipeline(ctx context.Context, fn func(redis.Pipeliner) error) error {
+	if _, err := c.client.Pipelined(ctx, fn); err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to execute Redis pipeline: %v", err)
+	}
+
+	return nil
+}
+
+// MGetJSON получает JSON значения нескольких ключей за один обмен с Redis.
+// newValue создает указатель на значение, в которое десериализуется каждая запись.
+// Возвращаются только найденные ключи: отсутствующие ключи и записи, которые не удалось
+// десериализовать, в результат не попадают и считаются промахом кеша.
+// Ключи читаются конвейером GET, а не MGET, чтобы поддерживать ключи из разных слотов Cluster.
+func (c *Client) MGetJSON(ctx context.Context, keys []string, newValue func() interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	var cmds []*redis.StringCmd
+	err := c.retryRead(ctx, "mget", func() error {
+		cmds = make([]*redis.StringCmd, len(keys))
+		_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range keys {
+				cmds[i] = pipe.Get(ctx, key)
+			}
+			return nil
+		})
+		if err == redis.Nil {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get JSON values from Redis: %v", err)
+	}
+
+	for i, cmd := range cmds {
+		data, err := cmd.Bytes()
+		if err == redis.Nil {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get JSON values from Redis: %v", err)
+		}
+
+		value := newValue()
+		if err := json.Unmarshal(data, value); err != nil {
+			c.logger.Warn("Failed to unmarshal JSON for Redis key %s, treating as miss: %v", keys[i], err)
+			continue
+		}
+		result[keys[i]] = value
+	}
+
+	return result, nil
+}
+
+// MSetJSON устанавливает JSON значения нескольких ключей за один обмен с Redis.
+// Каждый ключ записывается командой SET с временем жизни expiration (0 - без ограничения),
+// поэтому ключ не существует без TTL даже кратковременно, в отличие от MSET с последующим EXPIRE.
+// Значения сериализуются до записи: при ошибке сериализации ничего не записывается.
+// Запись не атомарна: при ошибке Redis часть ключей может быть уже записана.
+func (c *Client) MSetJSON(ctx context.Context, values map[string]interface{}, expiration time.Duration) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	encoded := make(map[string][]byte, len(values))
+	for key, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON for key %s: %v", key, err)
+		}
+		encoded[key] = data
+	}
+
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, data := range encoded {
+			pipe.Set(ctx, key, data, expiration)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set JSON values in Redis: %v", err)
+	}
+
+	return nil
+}
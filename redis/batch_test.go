@@ -0,0 +1,112 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+type batchItem struct {
+	Name string `json:"name"`
+}
+
+func newTestClient(t *testing.T) (*Client, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client, err := NewClient(server.Addr(), "", 0, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, server
+}
+
+func TestMSetJSONAndMGetJSON(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	err := client.MSetJSON(ctx, map[string]interface{}{
+		"item:1": batchItem{Name: "first"},
+		"item:2": batchItem{Name: "second"},
+	}, time.Minute)
+	if err != nil {
+		t.Fatalf("MSetJSON() error = %v", err)
+	}
+	if ttl := server.TTL("item:1"); ttl != time.Minute {
+		t.Errorf("TTL(item:1) = %v, want 1m", ttl)
+	}
+
+	// Поврежденная запись и отсутствующий ключ считаются промахами
+	server.Set("item:3", "{not json")
+
+	values, err := client.MGetJSON(ctx, []string{"item:1", "item:2", "item:3", "item:4"}, func() interface{} { return &batchItem{} })
+	if err != nil {
+		t.Fatalf("MGetJSON() error = %v", err)
+	}
+	if len(values) != 2 {
+		t.Fatalf("MGetJSON() returned %d values, want 2: %v", len(values), values)
+	}
+	if item := values["item:2"].(*batchItem); item.Name != "second" {
+		t.Errorf("item:2 = %+v", item)
+	}
+	if _, ok := values["item:4"]; ok {
+		t.Error("missing key must not be returned")
+	}
+
+	if values, err := client.MGetJSON(ctx, nil, func() interface{} { return &batchItem{} }); err != nil || len(values) != 0 {
+		t.Errorf("MGetJSON(nil) = %v, %v", values, err)
+	}
+}
+
+func TestMSetJSONWithoutExpiration(t *testing.T) {
+	client, server := newTestClient(t)
+
+	if err := client.MSetJSON(context.Background(), map[string]interface{}{"item:1": batchItem{Name: "first"}}, 0); err != nil {
+		t.Fatalf("MSetJSON() error = %v", err)
+	}
+	if ttl := server.TTL("item:1"); ttl != 0 {
+		t.Errorf("TTL(item:1) = %v, want no expiration", ttl)
+	}
+}
+
+func TestMSetJSONMarshalErrorWritesNothing(t *testing.T) {
+	client, server := newTestClient(t)
+
+	err := client.MSetJSON(context.Background(), map[string]interface{}{
+		"item:1": batchItem{Name: "first"},
+		"item:2": make(chan int),
+	}, time.Minute)
+	if err == nil {
+		t.Fatal("MSetJSON() must fail for unsupported value")
+	}
+	if keys := server.Keys(); len(keys) != 0 {
+		t.Errorf("keys written after marshal error: %v", keys)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	var incr *redis.IntCmd
+	var missing *redis.StringCmd
+	err := client.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, "counter")
+		pipe.Expire(ctx, "counter", time.Minute)
+		missing = pipe.Get(ctx, "missing")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Pipeline() error = %v", err)
+	}
+	if incr.Val() != 1 || server.TTL("counter") != time.Minute {
+		t.Errorf("counter = %d, ttl = %v", incr.Val(), server.TTL("counter"))
+	}
+	if missing.Err() != redis.Nil {
+		t.Errorf("missing key error = %v, want redis.Nil", missing.Err())
+	}
+}
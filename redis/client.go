@@ -13,8 +13,10 @@ import (
 
 // Client представляет клиент Redis
 type Client struct {
-	client *redis.Client
-	logger logging.Logger
+	client   redis.UniversalClient
+	logger   logging.Logger
+	options  *ClientOptions
+	inFlight int64
 }
 
 // ClientOptions содержит опции для создания клиента Redis
@@ -29,21 +31,46 @@ type ClientOptions struct {
 	ReadTimeout time.Duration
 	// Время ожидания записи в Redis
 	WriteTimeout time.Duration
+	// Количество повторов идемпотентных команд чтения при сетевых ошибках.
+	// Команды записи не повторяются, чтобы не выполнить их дважды.
+	ReadRetries int
+	// Начальная задержка между повторами
+	RetryBackoff time.Duration
+	// Максимальная задержка между повторами (0 - без ограничения)
+	MaxRetryBackoff time.Duration
+	// Проверка, отключены ли повторы чтения (например, выключателем killswitch.FeatureRedisReadRetry);
+	// nil - повторы включены
+	ReadRetryDisabled func() bool
+	// Имя клиента (CLIENT SETNAME) для каждого соединения, обычно имя сервиса
+	ClientName string
 }
 
 // DefaultClientOptions возвращает опции по умолчанию
 func DefaultClientOptions() *ClientOptions {
 	return &ClientOptions{
-		PoolSize:     10,
-		MinIdleConns: 5,
-		PoolTimeout:  time.Second * 4,
-		ReadTimeout:  time.Second * 3,
-		WriteTimeout: time.Second * 3,
+		PoolSize:        10,
+		MinIdleConns:    5,
+		PoolTimeout:     time.Second * 4,
+		ReadTimeout:     time.Second * 3,
+		WriteTimeout:    time.Second * 3,
+		ReadRetries:     2,
+		RetryBackoff:    50 * time.Millisecond,
+		MaxRetryBackoff: 500 * time.Millisecond,
 	}
 }
 
-// NewClient создает новый клиент Redis
+// NewClient создает новый клиент Redis для одного узла.
+// Для Sentinel и Cluster используйте NewFailoverClient, NewClusterClient или NewUniversalClient.
 func NewClient(addr string, password string, db int, logger logging.Logger, options *ClientOptions) (*Client, error) {
+	return newClient(&redis.UniversalOptions{
+		Addrs:    []string{addr},
+		Password: password,
+		DB:       db,
+	}, modeSingle, logger, options)
+}
+
+// newClient создает клиент в заданном режиме, применяя опции пула и проверяя соединение
+func newClient(universal *redis.UniversalOptions, mode string, logger logging.Logger, options *ClientOptions) (*Client, error) {
 	if logger == nil {
 		logger = logging.NewLogger()
 	}
@@ -52,35 +79,48 @@ func NewClient(addr string, password string, db int, logger logging.Logger, opti
 		options = DefaultClientOptions()
 	}
 
+	c := &Client{
+		logger:  logger,
+		options: options,
+	}
+
+	universal.PoolSize = options.PoolSize
+	universal.MinIdleConns = options.MinIdleConns
+	universal.PoolTimeout = options.PoolTimeout
+	universal.ReadTimeout = options.ReadTimeout
+	universal.WriteTimeout = options.WriteTimeout
+	// Повторы выполняются только для команд чтения (см. ReadRetries)
+	universal.MaxRetries = -1
+	universal.OnConnect = onConnect(c, options.ClientName)
+
 	// Создаем клиент Redis
-	client := redis.NewClient(&redis.Options{
-		Addr:         addr,
-		Password:     password,
-		DB:           db,
-		PoolSize:     options.PoolSize,
-		MinIdleConns: options.MinIdleConns,
-		PoolTimeout:  options.PoolTimeout,
-		ReadTimeout:  options.ReadTimeout,
-		WriteTimeout: options.WriteTimeout,
-	})
+	var client redis.UniversalClient
+	switch mode {
+	case modeSentinel:
+		client = redis.NewFailoverClient(universal.Failover())
+	case modeCluster:
+		client = redis.NewClusterClient(universal.Cluster())
+	default:
+		client = redis.NewClient(universal.Simple())
+	}
+	client.AddHook(commandHook{inFlight: &c.inFlight})
+	c.client = client
 
 	// Проверяем соединение
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
 
 	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
 		return nil, fmt.Errorf("failed to connect to Redis: %v", err)
 	}
 
-	logger.Info("Successfully connected to Redis")
+	logger.Info("Successfully connected to Redis (%s)", mode)
 
-	return &Client{
-		client: client,
-		logger: logger,
-	}, nil
+	return c, nil
 }
 
-// Close закрывает соединение с Redis
+// Close немедленно закрывает соединение с Redis, прерывая выполняемые команды (см. Shutdown)
 func (c *Client) Close() error {
 	if err := c.client.Close(); err != nil {
 		return fmt.Errorf("failed to close Redis connection: %v", err)
@@ -90,8 +130,8 @@ func (c *Client) Close() error {
 	return nil
 }
 
-// Client возвращает оригинальный клиент Redis
-func (c *Client) Client() *redis.Client {
+// Client возвращает оригинальный клиент Redis (одиночный, Sentinel или Cluster)
+func (c *Client) Client() redis.UniversalClient {
 	return c.client
 }
 
@@ -106,7 +146,11 @@ func (c *Client) Ping(ctx context.Context) error {
 
 // Get получает значение по ключу
 func (c *Client) Get(ctx context.Context, key string) (string, error) {
-	result, err := c.client.Get(ctx, key).Result()
+	var result string
+	err := c.retryRead(ctx, "get", func() (err error) {
+		result, err = c.client.Get(ctx, key).Result()
+		return err
+	})
 	if err == redis.Nil {
 		return "", nil // Ключ не найден
 	} else if err != nil {
@@ -125,6 +169,28 @@ func (c *Client) Set(ctx context.Context, key string, value interface{}, expirat
 	return nil
 }
 
+// SetNX устанавливает значение, только если ключ не существует; возвращает true, если значение установлено
+func (c *Client) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	result, err := c.client.SetNX(ctx, key, value, expiration).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to set value if not exists in Redis: %v", err)
+	}
+
+	return result, nil
+}
+
+// GetSet устанавливает новое значение и возвращает предыдущее (пустая строка, если ключа не было)
+func (c *Client) GetSet(ctx context.Context, key string, value interface{}) (string, error) {
+	result, err := c.client.GetSet(ctx, key, value).Result()
+	if err == redis.Nil {
+		return "", nil // Ключ не найден
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get and set value in Redis: %v", err)
+	}
+
+	return result, nil
+}
+
 // Del удаляет ключ
 func (c *Client) Del(ctx context.Context, keys ...string) error {
 	if err := c.client.Del(ctx, keys...).Err(); err != nil {
@@ -136,7 +202,11 @@ func (c *Client) Del(ctx context.Context, keys ...string) error {
 
 // Exists проверяет существование ключа
 func (c *Client) Exists(ctx context.Context, keys ...string) (bool, error) {
-	result, err := c.client.Exists(ctx, keys...).Result()
+	var result int64
+	err := c.retryRead(ctx, "exists", func() (err error) {
+		result, err = c.client.Exists(ctx, keys...).Result()
+		return err
+	})
 	if err != nil {
 		return false, fmt.Errorf("failed to check key existence in Redis: %v", err)
 	}
@@ -155,7 +225,11 @@ func (c *Client) Expire(ctx context.Context, key string, expiration time.Duratio
 
 // TTL возвращает оставшееся время жизни ключа
 func (c *Client) TTL(ctx context.Context, key string) (time.Duration, error) {
-	result, err := c.client.TTL(ctx, key).Result()
+	var result time.Duration
+	err := c.retryRead(ctx, "ttl", func() (err error) {
+		result, err = c.client.TTL(ctx, key).Result()
+		return err
+	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to get TTL from Redis: %v", err)
 	}
@@ -173,6 +247,46 @@ func (c *Client) Incr(ctx context.Context, key string) (int64, error) {
 	return result, nil
 }
 
+// IncrBy увеличивает значение ключа на value
+func (c *Client) IncrBy(ctx context.Context, key string, value int64) (int64, error) {
+	result, err := c.client.IncrBy(ctx, key, value).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment value in Redis: %v", err)
+	}
+
+	return result, nil
+}
+
+// Decr уменьшает значение ключа на 1
+func (c *Client) Decr(ctx context.Context, key string) (int64, error) {
+	result, err := c.client.Decr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrement value in Redis: %v", err)
+	}
+
+	return result, nil
+}
+
+// incrWithTTLScript увеличивает счетчик и задает время жизни только при создании ключа
+var incrWithTTLScript = redis.NewScript(`
+local value = redis.call("INCR", KEYS[1])
+if value == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return value
+`)
+
+// IncrWithTTL атомарно увеличивает счетчик на 1 и при первом увеличении задает время жизни ключа.
+// Последующие увеличения не продлевают его, поэтому счетчик подходит для ограничения частоты в окне.
+func (c *Client) IncrWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	result, err := incrWithTTLScript.Run(ctx, c.client, []string{key}, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment value with TTL in Redis: %v", err)
+	}
+
+	return result, nil
+}
+
 // SetJSON устанавливает JSON значение по ключу
 func (c *Client) SetJSON(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	// Маршалим значение в JSON
@@ -192,7 +306,11 @@ func (c *Client) SetJSON(ctx context.Context, key string, value interface{}, exp
 // GetJSON получает JSON значение по ключу и десериализует его в указанный тип
 func (c *Client) GetJSON(ctx context.Context, key string, value interface{}) error {
 	// Получаем значение из Redis
-	data, err := c.client.Get(ctx, key).Bytes()
+	var data []byte
+	err := c.retryRead(ctx, "get", func() (err error) {
+		data, err = c.client.Get(ctx, key).Bytes()
+		return err
+	})
 	if err == redis.Nil {
 		return nil // Ключ не найден
 	} else if err != nil {
@@ -218,7 +336,11 @@ func (c *Client) HSet(ctx context.Context, key, field string, value interface{})
 
 // HGet получает поле хеша
 func (c *Client) HGet(ctx context.Context, key, field string) (string, error) {
-	result, err := c.client.HGet(ctx, key, field).Result()
+	var result string
+	err := c.retryRead(ctx, "hget", func() (err error) {
+		result, err = c.client.HGet(ctx, key, field).Result()
+		return err
+	})
 	if err == redis.Nil {
 		return "", nil // Поле не найдено
 	} else if err != nil {
@@ -230,7 +352,11 @@ func (c *Client) HGet(ctx context.Context, key, field string) (string, error) {
 
 // HGetAll получает все поля хеша
 func (c *Client) HGetAll(ctx context.Context, key string) (map[string]string, error) {
-	result, err := c.client.HGetAll(ctx, key).Result()
+	var result map[string]string
+	err := c.retryRead(ctx, "hgetall", func() (err error) {
+		result, err = c.client.HGetAll(ctx, key).Result()
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all hash fields from Redis: %v", err)
 	}
@@ -247,6 +373,43 @@ func (c *Client) HDel(ctx context.Context, key string, fields ...string) error {
 	return nil
 }
 
+// SAdd добавляет элементы в множество
+func (c *Client) SAdd(ctx context.Context, key string, members ...interface{}) error {
+	if err := c.client.SAdd(ctx, key, members...).Err(); err != nil {
+		return fmt.Errorf("failed to add to set in Redis: %v", err)
+	}
+
+	return nil
+}
+
+// SMembers возвращает все элементы множества (пустой список, если ключа нет)
+func (c *Client) SMembers(ctx context.Context, key string) ([]string, error) {
+	var result []string
+	err := c.retryRead(ctx, "smembers", func() (err error) {
+		result, err = c.client.SMembers(ctx, key).Result()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get set members from Redis: %v", err)
+	}
+
+	return result, nil
+}
+
+// SIsMember проверяет, входит ли элемент в множество
+func (c *Client) SIsMember(ctx context.Context, key string, member interface{}) (bool, error) {
+	var result bool
+	err := c.retryRead(ctx, "sismember", func() (err error) {
+		result, err = c.client.SIsMember(ctx, key, member).Result()
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check set membership in Redis: %v", err)
+	}
+
+	return result, nil
+}
+
 // Publish публикует сообщение в канал
 func (c *Client) Publish(ctx context.Context, channel string, message interface{}) error {
 	if err := c.client.Publish(ctx, channel, message).Err(); err != nil {
@@ -256,7 +419,8 @@ func (c *Client) Publish(ctx context.Context, channel string, message interface{
 	return nil
 }
 
-// Subscribe подписывается на канал
+// Subscribe подписывается на канал и возвращает низкоуровневую подписку.
+// Для обработки сообщений с переподпиской после потери соединения используйте Subscriber.
 func (c *Client) Subscribe(ctx context.Context, channel string) *redis.PubSub {
 	return c.client.Subscribe(ctx, channel)
 }
@@ -305,7 +469,11 @@ func (c *Client) RPop(ctx context.Context, key string) (string, error) {
 
 // LRange возвращает диапазон элементов списка
 func (c *Client) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
-	result, err := c.client.LRange(ctx, key, start, stop).Result()
+	var result []string
+	err := c.retryRead(ctx, "lrange", func() (err error) {
+		result, err = c.client.LRange(ctx, key, start, stop).Result()
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get range from list in Redis: %v", err)
 	}
@@ -0,0 +1,86 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCounters(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	if value, err := client.IncrBy(ctx, "counter", 5); err != nil || value != 5 {
+		t.Fatalf("IncrBy() = %d, %v", value, err)
+	}
+	if value, err := client.Decr(ctx, "counter"); err != nil || value != 4 {
+		t.Fatalf("Decr() = %d, %v", value, err)
+	}
+
+	// Время жизни задается только при первом увеличении
+	if value, err := client.IncrWithTTL(ctx, "rate:1", time.Minute); err != nil || value != 1 {
+		t.Fatalf("IncrWithTTL() = %d, %v", value, err)
+	}
+	server.FastForward(30 * time.Second)
+	if value, err := client.IncrWithTTL(ctx, "rate:1", time.Minute); err != nil || value != 2 {
+		t.Fatalf("IncrWithTTL() = %d, %v", value, err)
+	}
+	if ttl := server.TTL("rate:1"); ttl != 30*time.Second {
+		t.Errorf("TTL(rate:1) = %v, want 30s", ttl)
+	}
+
+	server.FastForward(31 * time.Second)
+	if value, err := client.IncrWithTTL(ctx, "rate:1", time.Minute); err != nil || value != 1 {
+		t.Errorf("IncrWithTTL() after expiration = %d, %v", value, err)
+	}
+
+	server.Set("text", "abc")
+	if _, err := client.Decr(ctx, "text"); err == nil {
+		t.Error("Decr() of non-integer value must fail")
+	}
+}
+
+func TestSetNXAndGetSet(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	if ok, err := client.SetNX(ctx, "flag", "on", time.Minute); err != nil || !ok {
+		t.Fatalf("SetNX() = %v, %v", ok, err)
+	}
+	if ok, err := client.SetNX(ctx, "flag", "off", time.Minute); err != nil || ok {
+		t.Fatalf("second SetNX() = %v, %v", ok, err)
+	}
+	if ttl := server.TTL("flag"); ttl != time.Minute {
+		t.Errorf("TTL(flag) = %v, want 1m", ttl)
+	}
+
+	if previous, err := client.GetSet(ctx, "flag", "off"); err != nil || previous != "on" {
+		t.Errorf("GetSet() = %q, %v", previous, err)
+	}
+	if previous, err := client.GetSet(ctx, "missing", "value"); err != nil || previous != "" {
+		t.Errorf("GetSet() of missing key = %q, %v", previous, err)
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	if err := client.SAdd(ctx, "features", "search", "export", "search"); err != nil {
+		t.Fatalf("SAdd() error = %v", err)
+	}
+
+	members, err := client.SMembers(ctx, "features")
+	if err != nil || len(members) != 2 {
+		t.Errorf("SMembers() = %v, %v", members, err)
+	}
+	if ok, err := client.SIsMember(ctx, "features", "export"); err != nil || !ok {
+		t.Errorf("SIsMember(export) = %v, %v", ok, err)
+	}
+	if ok, err := client.SIsMember(ctx, "features", "import"); err != nil || ok {
+		t.Errorf("SIsMember(import) = %v, %v", ok, err)
+	}
+	if members, err := client.SMembers(ctx, "missing"); err != nil || len(members) != 0 {
+		t.Errorf("SMembers(missing) = %v, %v", members, err)
+	}
+}
@@ -0,0 +1,160 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/vladzorgan/common/retry"
+)
+
+// ErrLockNotAcquired возвращается, если блокировку не удалось получить
+var ErrLockNotAcquired = errors.New("lock not acquired")
+
+// ErrLockNotHeld возвращается, если блокировка уже не принадлежит владельцу
+var ErrLockNotHeld = errors.New("lock not held")
+
+// releaseScript удаляет ключ, только если его значение совпадает с токеном
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// extendScript продлевает ключ, только если его значение совпадает с токеном
+var extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// LockOptions содержит опции получения блокировки при конкуренции
+type LockOptions struct {
+	// Максимальное время ожидания блокировки (0 - одна попытка)
+	WaitTimeout time.Duration
+	// Начальный интервал между попытками
+	RetryInterval time.Duration
+	// Максимальный интервал между попытками
+	MaxRetryInterval time.Duration
+}
+
+// DefaultLockOptions возвращает опции по умолчанию
+func DefaultLockOptions() *LockOptions {
+	return &LockOptions{
+		WaitTimeout:      0,
+		RetryInterval:    50 * time.Millisecond,
+		MaxRetryInterval: time.Second,
+	}
+}
+
+// Lock представляет распределенную блокировку
+type Lock struct {
+	client *Client
+	key    string
+	token  string
+}
+
+// AcquireLock получает блокировку без ожидания
+func (c *Client) AcquireLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	return c.AcquireLockWithOptions(ctx, key, ttl, nil)
+}
+
+// AcquireLockWithOptions получает блокировку, повторяя попытки с экспоненциальным backoff
+// до истечения WaitTimeout или отмены контекста
+func (c *Client) AcquireLockWithOptions(ctx context.Context, key string, ttl time.Duration, options *LockOptions) (*Lock, error) {
+	if options == nil {
+		options = DefaultLockOptions()
+	}
+
+	token := uuid.New().String()
+	deadline := time.Now().Add(options.WaitTimeout)
+	backoff := retry.NewBackoff(options.RetryInterval, options.MaxRetryInterval)
+
+	for {
+		ok, err := c.client.SetNX(ctx, key, token, ttl).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire lock in Redis: %v", err)
+		}
+
+		if ok {
+			return &Lock{
+				client: c,
+				key:    key,
+				token:  token,
+			}, nil
+		}
+
+		// Проверяем, остался ли бюджет на ожидание
+		delay := backoff.Next()
+		if options.WaitTimeout <= 0 || time.Now().Add(delay).After(deadline) {
+			return nil, ErrLockNotAcquired
+		}
+
+		if err := retry.Sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Key возвращает ключ блокировки
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Release освобождает блокировку, если она все еще принадлежит владельцу
+func (l *Lock) Release(ctx context.Context) error {
+	result, err := releaseScript.Run(ctx, l.client.client, []string{l.key}, l.token).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to release lock in Redis: %v", err)
+	}
+
+	if result == 0 {
+		return ErrLockNotHeld
+	}
+
+	return nil
+}
+
+// Extend продлевает время жизни блокировки, если она все еще принадлежит владельцу
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	result, err := extendScript.Run(ctx, l.client.client, []string{l.key}, l.token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to extend lock in Redis: %v", err)
+	}
+
+	if result == 0 {
+		return ErrLockNotHeld
+	}
+
+	return nil
+}
+
+// WithLock получает блокировку, выполняет функцию и освобождает блокировку, в том числе при панике
+func (c *Client) WithLock(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	return c.WithLockOptions(ctx, key, ttl, nil, fn)
+}
+
+// WithLockOptions аналогичен WithLock, но позволяет настроить ожидание блокировки
+func (c *Client) WithLockOptions(ctx context.Context, key string, ttl time.Duration, options *LockOptions, fn func(ctx context.Context) error) error {
+	lock, err := c.AcquireLockWithOptions(ctx, key, ttl, options)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		// Освобождаем блокировку независимо от отмены исходного контекста
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := lock.Release(releaseCtx); err != nil {
+			c.logger.Warn("Failed to release lock %s: %v", key, err)
+		}
+	}()
+
+	return fn(ctx)
+}
@@ -0,0 +1,128 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAcquireLockContention(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	lock, err := client.AcquireLock(ctx, "lock:orders", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+	if ttl := server.TTL("lock:orders"); ttl != time.Minute {
+		t.Errorf("TTL(lock:orders) = %v, want 1m", ttl)
+	}
+
+	if _, err := client.AcquireLock(ctx, "lock:orders", time.Minute); !errors.Is(err, ErrLockNotAcquired) {
+		t.Fatalf("second AcquireLock() error = %v, want %v", err, ErrLockNotAcquired)
+	}
+
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if server.Exists("lock:orders") {
+		t.Error("lock key remains after Release()")
+	}
+	if err := lock.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("repeated Release() error = %v, want %v", err, ErrLockNotHeld)
+	}
+}
+
+func TestLockReleaseAndExtendCheckToken(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	lock, err := client.AcquireLock(ctx, "lock:report", time.Second)
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+
+	if err := lock.Extend(ctx, time.Minute); err != nil {
+		t.Fatalf("Extend() error = %v", err)
+	}
+	if ttl := server.TTL("lock:report"); ttl != time.Minute {
+		t.Errorf("TTL after Extend() = %v, want 1m", ttl)
+	}
+
+	// Блокировка истекла и досталась другому владельцу: прежний владелец не может ее снять или продлить
+	server.FastForward(2 * time.Minute)
+	other, err := client.AcquireLock(ctx, "lock:report", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireLock() after expiration error = %v", err)
+	}
+	if err := lock.Extend(ctx, time.Minute); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("stale Extend() error = %v, want %v", err, ErrLockNotHeld)
+	}
+	if err := lock.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("stale Release() error = %v, want %v", err, ErrLockNotHeld)
+	}
+	if !server.Exists("lock:report") {
+		t.Fatal("stale Release() removed the new owner's lock")
+	}
+	if err := other.Release(ctx); err != nil {
+		t.Errorf("Release() by the new owner error = %v", err)
+	}
+}
+
+func TestAcquireLockWaitsForRelease(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	lock, err := client.AcquireLock(ctx, "lock:sync", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+	time.AfterFunc(30*time.Millisecond, func() { lock.Release(context.Background()) })
+
+	options := &LockOptions{WaitTimeout: time.Second, RetryInterval: 5 * time.Millisecond, MaxRetryInterval: 20 * time.Millisecond}
+	waited, err := client.AcquireLockWithOptions(ctx, "lock:sync", time.Minute, options)
+	if err != nil {
+		t.Fatalf("AcquireLockWithOptions() error = %v", err)
+	}
+	waited.Release(ctx)
+
+	// Без освобождения ожидание завершается по WaitTimeout
+	if _, err := client.AcquireLock(ctx, "lock:sync", time.Minute); err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+	options.WaitTimeout = 50 * time.Millisecond
+	if _, err := client.AcquireLockWithOptions(ctx, "lock:sync", time.Minute, options); !errors.Is(err, ErrLockNotAcquired) {
+		t.Errorf("AcquireLockWithOptions() error = %v, want %v", err, ErrLockNotAcquired)
+	}
+}
+
+func TestWithLockReleasesOnPanic(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("WithLock() swallowed the panic")
+			}
+		}()
+		client.WithLock(ctx, "lock:panic", time.Minute, func(context.Context) error {
+			panic("handler failed")
+		})
+	}()
+	if server.Exists("lock:panic") {
+		t.Error("lock key remains after panic")
+	}
+
+	errHandler := errors.New("handler error")
+	err := client.WithLock(ctx, "lock:panic", time.Minute, func(context.Context) error {
+		if !server.Exists("lock:panic") {
+			t.Error("fn runs without the lock")
+		}
+		return errHandler
+	})
+	if !errors.Is(err, errHandler) || server.Exists("lock:panic") {
+		t.Errorf("WithLock() error = %v, lock held = %v", err, server.Exists("lock:panic"))
+	}
+}
@@ -0,0 +1,151 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vladzorgan/common/retry"
+)
+
+var (
+	// commandRetriesTotal считает повторы команд чтения после сетевых ошибок
+	commandRetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "redis_command_retries_total",
+			Help: "Total number of Redis read commands retried after network errors",
+		},
+		[]string{"command"},
+	)
+
+	// commandFailuresTotal считает команды, завершившиеся ошибкой
+	commandFailuresTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "redis_command_failures_total",
+			Help: "Total number of failed Redis commands",
+		},
+		[]string{"command"},
+	)
+)
+
+// drainPollInterval - интервал проверки завершения выполняемых команд при остановке
+const drainPollInterval = 10 * time.Millisecond
+
+// commandHook отслеживает выполняемые команды и считает ошибки
+type commandHook struct {
+	inFlight *int64
+}
+
+// BeforeProcess отмечает начало команды
+func (h commandHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	atomic.AddInt64(h.inFlight, 1)
+	return ctx, nil
+}
+
+// AfterProcess отмечает завершение команды
+func (h commandHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	atomic.AddInt64(h.inFlight, -1)
+	if err := cmd.Err(); err != nil && err != redis.Nil {
+		commandFailuresTotal.WithLabelValues(cmd.Name()).Inc()
+	}
+	return nil
+}
+
+// BeforeProcessPipeline отмечает начало конвейера команд
+func (h commandHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	atomic.AddInt64(h.inFlight, 1)
+	return ctx, nil
+}
+
+// AfterProcessPipeline отмечает завершение конвейера команд
+func (h commandHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	atomic.AddInt64(h.inFlight, -1)
+	for _, cmd := range cmds {
+		if err := cmd.Err(); err != nil && err != redis.Nil {
+			commandFailuresTotal.WithLabelValues(cmd.Name()).Inc()
+		}
+	}
+	return nil
+}
+
+// onConnect возвращает обработчик новых соединений, задающий имя клиента для отладки на стороне Redis
+func onConnect(c *Client, clientName string) func(ctx context.Context, cn *redis.Conn) error {
+	return func(ctx context.Context, cn *redis.Conn) error {
+		if clientName == "" {
+			return nil
+		}
+
+		if err := cn.ClientSetName(ctx, clientName).Err(); err != nil {
+			// Имя клиента нужно только для отладки, соединение остается рабочим
+			c.logger.Warn("Failed to set Redis client name %s: %v", clientName, err)
+			return nil
+		}
+
+		c.logger.Debug("Redis connection established as %s", clientName)
+		return nil
+	}
+}
+
+// Shutdown дожидается завершения выполняемых команд (но не дольше ctx) и закрывает соединение
+func (c *Client) Shutdown(ctx context.Context) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for atomic.LoadInt64(&c.inFlight) > 0 {
+		select {
+		case <-ctx.Done():
+			c.logger.Warn("Closing Redis connection with %d commands in flight", atomic.LoadInt64(&c.inFlight))
+			return c.Close()
+		case <-ticker.C:
+		}
+	}
+
+	return c.Close()
+}
+
+// retryRead выполняет идемпотентную команду чтения, повторяя ее при сетевых ошибках
+func (c *Client) retryRead(ctx context.Context, command string, fn func() error) error {
+	backoff := retry.NewBackoff(c.options.RetryBackoff, c.options.MaxRetryBackoff)
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= c.options.ReadRetries || !isNetworkError(err) || c.readRetryDisabled() {
+			return err
+		}
+
+		commandRetriesTotal.WithLabelValues(command).Inc()
+		c.logger.Debug("Retrying Redis %s after network error: %v", command, err)
+
+		if backoff.Wait(ctx) != nil {
+			return err
+		}
+	}
+}
+
+// readRetryDisabled проверяет, отключены ли повторы команд чтения
+func (c *Client) readRetryDisabled() bool {
+	return c.options.ReadRetryDisabled != nil && c.options.ReadRetryDisabled()
+}
+
+// isNetworkError проверяет, вызвана ли ошибка проблемами соединения (разрыв, переключение мастера)
+func isNetworkError(err error) bool {
+	if err == nil || err == redis.Nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,260 @@
+package redis
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// faultyProxy проксирует соединения к miniredis и умеет разрывать их или задерживать команды
+type faultyProxy struct {
+	listener net.Listener
+	target   string
+
+	// drops - сколько следующих команд разорвать, не передавая серверу
+	drops atomic.Int32
+	// delay - задержка перед передачей каждой команды
+	delay atomic.Int64
+
+	mutex sync.Mutex
+	conns []net.Conn
+}
+
+func newFaultyProxy(t *testing.T, target string) *faultyProxy {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+
+	p := &faultyProxy{listener: listener, target: target}
+	t.Cleanup(p.close)
+	go p.serve()
+	return p
+}
+
+func (p *faultyProxy) Addr() string {
+	return p.listener.Addr().String()
+}
+
+func (p *faultyProxy) serve() {
+	for {
+		client, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		server, err := net.Dial("tcp", p.target)
+		if err != nil {
+			client.Close()
+			continue
+		}
+
+		p.mutex.Lock()
+		p.conns = append(p.conns, client, server)
+		p.mutex.Unlock()
+
+		go io.Copy(client, server)
+		go p.forward(client, server)
+	}
+}
+
+// forward передает команды серверу, разрывая соединение вместо отправки, если запрошено
+func (p *faultyProxy) forward(client, server net.Conn) {
+	defer client.Close()
+	defer server.Close()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := client.Read(buf)
+		if err != nil {
+			return
+		}
+		if p.drops.Add(-1) >= 0 {
+			return
+		}
+		p.drops.Store(0)
+
+		time.Sleep(time.Duration(p.delay.Load()))
+		if _, err := server.Write(buf[:n]); err != nil {
+			return
+		}
+	}
+}
+
+func (p *faultyProxy) close() {
+	p.listener.Close()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, conn := range p.conns {
+		conn.Close()
+	}
+}
+
+// newProxiedClient создает клиент, подключенный к miniredis через faultyProxy
+func newProxiedClient(t *testing.T, options *ClientOptions) (*Client, *miniredis.Miniredis, *faultyProxy) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	proxy := newFaultyProxy(t, server.Addr())
+
+	if options == nil {
+		options = DefaultClientOptions()
+	}
+	options.PoolSize = 1
+	options.MinIdleConns = 0
+	options.RetryBackoff = time.Millisecond
+
+	client, err := NewClient(proxy.Addr(), "", 0, nil, options)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, server, proxy
+}
+
+func TestReadRetriedAfterConnectionReset(t *testing.T) {
+	client, server, proxy := newProxiedClient(t, nil)
+	ctx := context.Background()
+	server.Set("key", "value")
+
+	retries := testutil.ToFloat64(commandRetriesTotal.WithLabelValues("get"))
+	failures := testutil.ToFloat64(commandFailuresTotal.WithLabelValues("get"))
+
+	// Соединение разрывается на первых двух попытках, третья проходит
+	proxy.drops.Store(2)
+	if value, err := client.Get(ctx, "key"); err != nil || value != "value" {
+		t.Fatalf("Get() = %q, %v; want value after retries", value, err)
+	}
+
+	if got := testutil.ToFloat64(commandRetriesTotal.WithLabelValues("get")) - retries; got != 2 {
+		t.Errorf("redis_command_retries_total{get} grew by %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(commandFailuresTotal.WithLabelValues("get")) - failures; got != 2 {
+		t.Errorf("redis_command_failures_total{get} grew by %v, want 2", got)
+	}
+
+	// Повторы исчерпаны: ошибка возвращается вызывающему коду
+	proxy.drops.Store(3)
+	if _, err := client.Get(ctx, "key"); err == nil {
+		t.Error("Get() must fail when every attempt is reset")
+	}
+}
+
+func TestWriteNotRetriedAfterConnectionReset(t *testing.T) {
+	client, server, proxy := newProxiedClient(t, nil)
+	ctx := context.Background()
+
+	proxy.drops.Store(1)
+	if err := client.Set(ctx, "key", "value", time.Minute); err == nil {
+		t.Fatal("Set() must fail without retry after a connection reset")
+	}
+	if server.Exists("key") {
+		t.Error("Set() reached the server")
+	}
+
+	// Следующая команда использует новое соединение
+	if err := client.Set(ctx, "key", "value", time.Minute); err != nil {
+		t.Errorf("Set() after reconnect error = %v", err)
+	}
+}
+
+func TestReadRetryDisabled(t *testing.T) {
+	options := DefaultClientOptions()
+	options.ReadRetryDisabled = func() bool { return true }
+	client, server, proxy := newProxiedClient(t, options)
+	server.Set("key", "value")
+
+	proxy.drops.Store(1)
+	if _, err := client.Get(context.Background(), "key"); err == nil {
+		t.Error("Get() must fail when read retries are disabled")
+	}
+}
+
+func TestReadNotRetriedOnServerError(t *testing.T) {
+	client, server, _ := newProxiedClient(t, nil)
+	server.HSet("hash", "field", "value")
+
+	retries := testutil.ToFloat64(commandRetriesTotal.WithLabelValues("get"))
+	if _, err := client.Get(context.Background(), "hash"); err == nil {
+		t.Fatal("Get() of a hash must fail with WRONGTYPE")
+	}
+	if got := testutil.ToFloat64(commandRetriesTotal.WithLabelValues("get")) - retries; got != 0 {
+		t.Errorf("redis_command_retries_total{get} grew by %v, want no retries for server errors", got)
+	}
+}
+
+func TestShutdownDrainsInFlightCommands(t *testing.T) {
+	client, server, proxy := newProxiedClient(t, nil)
+	server.Set("key", "value")
+	proxy.delay.Store(int64(50 * time.Millisecond))
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := client.Get(context.Background(), "key")
+		result <- err
+	}()
+	waitFor(t, "command in flight", func() bool { return atomic.LoadInt64(&client.inFlight) > 0 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if err := <-result; err != nil {
+		t.Errorf("in-flight Get() error = %v, want completed before close", err)
+	}
+}
+
+func TestShutdownClosesAfterDeadline(t *testing.T) {
+	client, server, proxy := newProxiedClient(t, nil)
+	server.Set("key", "value")
+	proxy.delay.Store(int64(time.Second))
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := client.Get(context.Background(), "key")
+		result <- err
+	}()
+	waitFor(t, "command in flight", func() bool { return atomic.LoadInt64(&client.inFlight) > 0 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	client.Shutdown(ctx)
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Errorf("Shutdown() took %v, want to stop waiting at the deadline", elapsed)
+	}
+	if err := <-result; err == nil {
+		t.Error("in-flight Get() must be aborted by Close")
+	}
+}
+
+func TestClientNameSetOnEveryConnection(t *testing.T) {
+	options := DefaultClientOptions()
+	options.ClientName = "orders"
+	client, server, proxy := newProxiedClient(t, options)
+	server.Set("key", "value")
+	ctx := context.Background()
+
+	if name, err := client.client.ClientGetName(ctx).Result(); err != nil || name != "orders" {
+		t.Fatalf("CLIENT GETNAME = %q, %v; want orders", name, err)
+	}
+
+	// После разрыва новое соединение получает то же имя
+	proxy.drops.Store(1)
+	if _, err := client.Get(ctx, "key"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if name, err := client.client.ClientGetName(ctx).Result(); err != nil || name != "orders" {
+		t.Errorf("CLIENT GETNAME after reconnect = %q, %v; want orders", name, err)
+	}
+}
@@ -0,0 +1,311 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/retry"
+)
+
+// ErrSubscriberClosed возвращается при подписке после Shutdown
+var ErrSubscriberClosed = errors.New("redis subscriber is closed")
+
+var (
+	// pubsubMessagesTotal считает обработанные сообщения Pub/Sub по результату обработки
+	pubsubMessagesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "redis_pubsub_messages_total",
+			Help: "Total number of Redis Pub/Sub messages handled by subscriber",
+		},
+		[]string{"pattern", "status"},
+	)
+
+	// pubsubResubscriptionsTotal считает повторные подписки после потери соединения
+	pubsubResubscriptionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "redis_pubsub_resubscriptions_total",
+			Help: "Total number of Redis Pub/Sub resubscriptions after connection loss",
+		},
+		[]string{"pattern"},
+	)
+)
+
+// MessageHandler обрабатывает сообщение Pub/Sub из канала channel
+type MessageHandler func(ctx context.Context, channel string, payload []byte) error
+
+// SubscriberOptions содержит опции подписчика
+type SubscriberOptions struct {
+	// Максимальное количество одновременно выполняемых обработчиков (по всем подпискам).
+	// Пока все обработчики заняты, чтение новых сообщений приостанавливается.
+	Concurrency int
+	// Начальная задержка перед повторной подпиской после потери соединения
+	ReconnectBackoff time.Duration
+	// Максимальная задержка перед повторной подпиской
+	MaxReconnectBackoff time.Duration
+	// Интервал проверки соединения (PING) при отсутствии сообщений; позволяет обнаружить
+	// оборванное соединение, о котором сервер не сообщил (0 - без проверки)
+	HealthCheckInterval time.Duration
+}
+
+// DefaultSubscriberOptions возвращает опции подписчика по умолчанию
+func DefaultSubscriberOptions() *SubscriberOptions {
+	return &SubscriberOptions{
+		Concurrency:         10,
+		ReconnectBackoff:    time.Second,
+		MaxReconnectBackoff: 30 * time.Second,
+		HealthCheckInterval: 30 * time.Second,
+	}
+}
+
+// Subscriber получает сообщения Redis Pub/Sub и передает их обработчикам: переподписывается
+// после потери соединения, ограничивает количество одновременных обработчиков и перехватывает панику.
+// Сообщения, опубликованные во время потери соединения, не доставляются (семантика Pub/Sub),
+// поэтому подписчик подходит для сигналов вроде инвалидации кешей, но не для надежной доставки.
+type Subscriber struct {
+	client  *Client
+	logger  logging.Logger
+	options *SubscriberOptions
+
+	// Контекст циклов чтения; отменяется при Shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+	// Контекст обработчиков; отменяется, если Shutdown не дождался их завершения
+	handlerCtx    context.Context
+	cancelHandler context.CancelFunc
+
+	slots    chan struct{}
+	handlers sync.WaitGroup
+	loops    sync.WaitGroup
+
+	mu     sync.Mutex
+	active map[*redis.PubSub]struct{}
+	closed bool
+}
+
+// NewSubscriber создает подписчика поверх клиента Redis
+func NewSubscriber(client *Client, options *SubscriberOptions) *Subscriber {
+	if options == nil {
+		options = DefaultSubscriberOptions()
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+	if options.ReconnectBackoff <= 0 {
+		options.ReconnectBackoff = DefaultSubscriberOptions().ReconnectBackoff
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handlerCtx, cancelHandler := context.WithCancel(context.Background())
+
+	return &Subscriber{
+		client:        client,
+		logger:        client.logger,
+		options:       options,
+		ctx:           ctx,
+		cancel:        cancel,
+		handlerCtx:    handlerCtx,
+		cancelHandler: cancelHandler,
+		slots:         make(chan struct{}, options.Concurrency),
+		active:        make(map[*redis.PubSub]struct{}),
+	}
+}
+
+// Subscribe подписывается на каналы, соответствующие шаблону (PSUBSCRIBE, например "cache:invalidate:*"),
+// и вызывает handler для каждого сообщения. Возвращает ошибку, если первая подписка не удалась;
+// дальнейшие разрывы соединения обрабатываются автоматически.
+func (s *Subscriber) Subscribe(channelPattern string, handler MessageHandler) error {
+	pubsub, err := s.subscribe(channelPattern, true)
+	if err != nil {
+		return err
+	}
+
+	go s.run(channelPattern, handler, pubsub)
+	return nil
+}
+
+// Shutdown прекращает получение сообщений и дожидается завершения обработчиков (но не дольше ctx)
+func (s *Subscriber) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.cancel()
+	for pubsub := range s.active {
+		pubsub.Close()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.loops.Wait()
+		s.handlers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancelHandler()
+		return nil
+	case <-ctx.Done():
+		// Прерываем обработчики, которые не успели завершиться
+		s.cancelHandler()
+		s.logger.Warn("Redis subscriber stopped with handlers still running")
+		return ctx.Err()
+	}
+}
+
+// subscribe подписывается на шаблон и дожидается подтверждения подписки.
+// newLoop регистрирует новый цикл чтения, которого дождется Shutdown.
+func (s *Subscriber) subscribe(pattern string, newLoop bool) (*redis.PubSub, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, ErrSubscriberClosed
+	}
+	if newLoop {
+		s.loops.Add(1)
+	}
+	pubsub := s.client.client.PSubscribe(s.ctx, pattern)
+	s.active[pubsub] = struct{}{}
+	s.mu.Unlock()
+
+	if _, err := pubsub.Receive(s.ctx); err != nil {
+		s.release(pubsub)
+		if newLoop {
+			s.loops.Done()
+		}
+		return nil, fmt.Errorf("failed to subscribe to %s: %v", pattern, err)
+	}
+
+	return pubsub, nil
+}
+
+// release закрывает подписку и удаляет ее из активных
+func (s *Subscriber) release(pubsub *redis.PubSub) {
+	s.mu.Lock()
+	delete(s.active, pubsub)
+	s.mu.Unlock()
+
+	pubsub.Close()
+}
+
+// run читает сообщения подписки и переподписывается после потери соединения до Shutdown
+func (s *Subscriber) run(pattern string, handler MessageHandler, pubsub *redis.PubSub) {
+	defer s.loops.Done()
+
+	for {
+		err := s.receive(pattern, handler, pubsub)
+		s.release(pubsub)
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		s.logger.Warn("Redis subscription %s lost: %v", pattern, err)
+
+		pubsub = s.resubscribe(pattern)
+		if pubsub == nil {
+			return
+		}
+		pubsubResubscriptionsTotal.WithLabelValues(pattern).Inc()
+		s.logger.Info("Redis subscription %s restored", pattern)
+	}
+}
+
+// resubscribe повторяет подписку с экспоненциальной задержкой. Возвращает nil после Shutdown.
+func (s *Subscriber) resubscribe(pattern string) *redis.PubSub {
+	backoff := retry.NewBackoff(s.options.ReconnectBackoff, s.options.MaxReconnectBackoff)
+
+	for {
+		if backoff.Wait(s.ctx) != nil {
+			return nil
+		}
+
+		pubsub, err := s.subscribe(pattern, false)
+		if err == nil {
+			return pubsub
+		}
+		if errors.Is(err, ErrSubscriberClosed) {
+			return nil
+		}
+		s.logger.Error("Failed to resubscribe to %s: %v", pattern, err)
+	}
+}
+
+// receive передает сообщения обработчикам до ошибки соединения
+func (s *Subscriber) receive(pattern string, handler MessageHandler, pubsub *redis.PubSub) error {
+	for {
+		received, err := pubsub.ReceiveTimeout(s.ctx, s.options.HealthCheckInterval)
+		if err != nil {
+			// Сообщений нет дольше интервала проверки: убеждаемся, что соединение живо
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() && s.ctx.Err() == nil {
+				if err := pubsub.Ping(s.ctx); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+
+		msg, ok := received.(*redis.Message)
+		if !ok {
+			// Подтверждения подписки и ответы на PING пропускаем
+			continue
+		}
+
+		// Ждем свободный слот обработчика
+		select {
+		case s.slots <- struct{}{}:
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		}
+
+		s.handlers.Add(1)
+		go s.handle(pattern, handler, msg)
+	}
+}
+
+// handle вызывает обработчик, перехватывая панику
+func (s *Subscriber) handle(pattern string, handler MessageHandler, msg *redis.Message) {
+	defer func() {
+		<-s.slots
+		s.handlers.Done()
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			pubsubMessagesTotal.WithLabelValues(pattern, "panic").Inc()
+			s.logger.Error("Panic in Redis subscriber handler for %s: %v\n%s", msg.Channel, r, debug.Stack())
+		}
+	}()
+
+	if err := handler(s.handlerCtx, msg.Channel, []byte(msg.Payload)); err != nil {
+		pubsubMessagesTotal.WithLabelValues(pattern, "error").Inc()
+		s.logger.Error("Redis subscriber handler for %s failed: %v", msg.Channel, err)
+		return
+	}
+
+	pubsubMessagesTotal.WithLabelValues(pattern, "ok").Inc()
+}
+
+// PublishJSON сериализует значение в JSON и публикует его в канал
+func (c *Client) PublishJSON(ctx context.Context, channel string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	return c.Publish(ctx, channel, data)
+}
@@ -0,0 +1,194 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestSubscriber(t *testing.T, client *Client, options *SubscriberOptions) *Subscriber {
+	t.Helper()
+
+	subscriber := NewSubscriber(client, options)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		subscriber.Shutdown(ctx)
+	})
+	return subscriber
+}
+
+// waitFor ждет выполнения условия не дольше секунды
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSubscriberDispatchesPatternMessages(t *testing.T) {
+	client, server := newTestClient(t)
+	subscriber := newTestSubscriber(t, client, nil)
+
+	var mu sync.Mutex
+	received := make(map[string]string)
+	err := subscriber.Subscribe("cache:invalidate:*", func(ctx context.Context, channel string, payload []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received[channel] = string(payload)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	if err := client.PublishJSON(context.Background(), "cache:invalidate:city", map[string]int{"id": 7}); err != nil {
+		t.Fatalf("PublishJSON() error = %v", err)
+	}
+	server.Publish("other:channel", "ignored")
+
+	waitFor(t, "message", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) > 0
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := received["cache:invalidate:city"]; got != `{"id":7}` {
+		t.Errorf("payload = %q, want JSON", got)
+	}
+	if _, ok := received["other:channel"]; ok {
+		t.Error("messages from other channels must not be dispatched")
+	}
+}
+
+func TestSubscriberRecoversFromPanics(t *testing.T) {
+	client, server := newTestClient(t)
+	subscriber := newTestSubscriber(t, client, nil)
+
+	var handled atomic.Int32
+	err := subscriber.Subscribe("events", func(ctx context.Context, channel string, payload []byte) error {
+		if handled.Add(1) == 1 {
+			panic("bad payload")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	server.Publish("events", "first")
+	server.Publish("events", "second")
+
+	waitFor(t, "both messages", func() bool { return handled.Load() == 2 })
+}
+
+func TestSubscriberBoundsConcurrency(t *testing.T) {
+	client, server := newTestClient(t)
+	subscriber := newTestSubscriber(t, client, &SubscriberOptions{Concurrency: 2})
+
+	var running, peak, done atomic.Int32
+	release := make(chan struct{})
+	err := subscriber.Subscribe("jobs", func(ctx context.Context, channel string, payload []byte) error {
+		current := running.Add(1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		done.Add(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		server.Publish("jobs", "job")
+	}
+
+	waitFor(t, "busy handlers", func() bool { return running.Load() == 2 })
+	close(release)
+	waitFor(t, "all handlers", func() bool { return done.Load() == 5 })
+
+	if got := peak.Load(); got != 2 {
+		t.Errorf("peak concurrency = %d, want 2", got)
+	}
+}
+
+func TestSubscriberResubscribesAfterConnectionLoss(t *testing.T) {
+	server := miniredis.RunT(t)
+	client, err := NewClient(server.Addr(), "", 0, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	subscriber := newTestSubscriber(t, client, &SubscriberOptions{
+		Concurrency:      1,
+		ReconnectBackoff: 10 * time.Millisecond,
+	})
+
+	var handled atomic.Int32
+	err = subscriber.Subscribe("events", func(ctx context.Context, channel string, payload []byte) error {
+		handled.Add(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	// Перезапуск сервера разрывает соединение и сбрасывает подписки
+	server.Restart()
+
+	waitFor(t, "resubscription", func() bool {
+		server.Publish("events", "after restart")
+		time.Sleep(10 * time.Millisecond)
+		return handled.Load() > 0
+	})
+}
+
+func TestSubscriberShutdown(t *testing.T) {
+	client, server := newTestClient(t)
+	subscriber := NewSubscriber(client, nil)
+
+	started := make(chan struct{})
+	var finished atomic.Bool
+	err := subscriber.Subscribe("events", func(ctx context.Context, channel string, payload []byte) error {
+		close(started)
+		time.Sleep(20 * time.Millisecond)
+		finished.Store(true)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	server.Publish("events", "payload")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := subscriber.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if !finished.Load() {
+		t.Error("Shutdown() must wait for running handlers")
+	}
+	if err := subscriber.Subscribe("events", nil); err != ErrSubscriberClosed {
+		t.Errorf("Subscribe() after Shutdown error = %v, want ErrSubscriberClosed", err)
+	}
+}
@@ -0,0 +1,155 @@
+package redis
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/vladzorgan/common/logging"
+)
+
+// Режимы подключения к Redis
+const (
+	modeSingle   = "single"
+	modeSentinel = "sentinel"
+	modeCluster  = "cluster"
+)
+
+// Схемы URL, определяющие режим подключения в NewUniversalClient
+const (
+	SchemeSentinel = "redis-sentinel"
+	SchemeCluster  = "redis-cluster"
+)
+
+// NewFailoverClient создает клиент Redis, получающий адрес мастера у Sentinel
+func NewFailoverClient(masterName string, sentinelAddrs []string, password string, db int, logger logging.Logger, options *ClientOptions) (*Client, error) {
+	if masterName == "" || len(sentinelAddrs) == 0 {
+		return nil, fmt.Errorf("redis sentinel requires master name and sentinel addresses")
+	}
+
+	return newClient(&redis.UniversalOptions{
+		MasterName: masterName,
+		Addrs:      sentinelAddrs,
+		Password:   password,
+		DB:         db,
+	}, modeSentinel, logger, options)
+}
+
+// NewClusterClient создает клиент Redis Cluster. Cluster не поддерживает выбор базы данных.
+func NewClusterClient(addrs []string, password string, logger logging.Logger, options *ClientOptions) (*Client, error) {
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("redis cluster requires at least one address")
+	}
+
+	return newClient(&redis.UniversalOptions{
+		Addrs:    addrs,
+		Password: password,
+	}, modeCluster, logger, options)
+}
+
+// NewUniversalClient создает клиент Redis по URL, режим определяется схемой:
+//
+//	localhost:6379, redis://:password@host:6379/0, rediss://...           - один узел
+//	redis-sentinel://:password@host1:26379,host2:26379/mymaster/0        - Sentinel
+//	redis-cluster://:password@host1:6379,host2:6379                      - Cluster
+//
+// Пароль и база данных из URL имеют приоритет над password и db.
+// Для Sentinel отдельный пароль задается параметром ?sentinel_password=.
+func NewUniversalClient(rawURL string, password string, db int, logger logging.Logger, options *ClientOptions) (*Client, error) {
+	universal, mode, err := parseUniversalURL(rawURL, password, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return newClient(universal, mode, logger, options)
+}
+
+// parseUniversalURL разбирает адрес Redis в опции и режим подключения
+func parseUniversalURL(rawURL string, password string, db int) (*redis.UniversalOptions, string, error) {
+	scheme := ""
+	if i := strings.Index(rawURL, "://"); i >= 0 {
+		scheme = rawURL[:i]
+	}
+
+	switch scheme {
+	case "":
+		return &redis.UniversalOptions{Addrs: []string{rawURL}, Password: password, DB: db}, modeSingle, nil
+	case "redis", "rediss":
+		parsed, err := redis.ParseURL(rawURL)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid Redis URL: %v", err)
+		}
+		universal := &redis.UniversalOptions{
+			Addrs:     []string{parsed.Addr},
+			Username:  parsed.Username,
+			Password:  parsed.Password,
+			DB:        parsed.DB,
+			TLSConfig: parsed.TLSConfig,
+		}
+		if universal.Password == "" {
+			universal.Password = password
+		}
+		if !hasPathDB(rawURL) {
+			universal.DB = db
+		}
+		return universal, modeSingle, nil
+	case SchemeSentinel, SchemeCluster:
+	default:
+		return nil, "", fmt.Errorf("unsupported Redis URL scheme %q", scheme)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid Redis URL: %v", err)
+	}
+
+	universal := &redis.UniversalOptions{Password: password, DB: db}
+	for _, addr := range strings.Split(u.Host, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			universal.Addrs = append(universal.Addrs, addr)
+		}
+	}
+	if len(universal.Addrs) == 0 {
+		return nil, "", fmt.Errorf("redis URL %s has no addresses", scheme)
+	}
+
+	if u.User != nil {
+		universal.Username = u.User.Username()
+		if value, ok := u.User.Password(); ok {
+			universal.Password = value
+		}
+	}
+
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+
+	if scheme == SchemeCluster {
+		if len(segments) > 0 {
+			return nil, "", fmt.Errorf("redis cluster URL must not contain a path")
+		}
+		if db != 0 {
+			return nil, "", fmt.Errorf("redis cluster does not support database selection")
+		}
+		return universal, modeCluster, nil
+	}
+
+	if len(segments) == 0 || len(segments) > 2 {
+		return nil, "", fmt.Errorf("redis sentinel URL must be %s://host:port,.../master[/db]", SchemeSentinel)
+	}
+	universal.MasterName = segments[0]
+	if len(segments) == 2 {
+		if universal.DB, err = strconv.Atoi(segments[1]); err != nil {
+			return nil, "", fmt.Errorf("invalid Redis database number %q", segments[1])
+		}
+	}
+	universal.SentinelPassword = u.Query().Get("sentinel_password")
+
+	return universal, modeSentinel, nil
+}
+
+// hasPathDB проверяет, задан ли в redis:// URL номер базы данных
+func hasPathDB(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && strings.Trim(u.Path, "/") != ""
+}
@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestParseUniversalURL(t *testing.T) {
+	cases := []struct {
+		url        string
+		mode       string
+		addrs      []string
+		master     string
+		password   string
+		sentinelPw string
+		db         int
+	}{
+		{"localhost:6379", modeSingle, []string{"localhost:6379"}, "", "env", "", 3},
+		{"redis://:secret@cache:6380/5", modeSingle, []string{"cache:6380"}, "", "secret", "", 5},
+		{"redis://cache:6380", modeSingle, []string{"cache:6380"}, "", "env", "", 3},
+		{"redis-sentinel://s1:26379,s2:26379/mymaster", modeSentinel, []string{"s1:26379", "s2:26379"}, "mymaster", "env", "", 3},
+		{"redis-sentinel://:secret@s1:26379/mymaster/2?sentinel_password=sp", modeSentinel, []string{"s1:26379"}, "mymaster", "secret", "sp", 2},
+		{"redis-cluster://n1:6379,n2:6379,n3:6379", modeCluster, []string{"n1:6379", "n2:6379", "n3:6379"}, "", "env", "", 0},
+	}
+
+	for _, tc := range cases {
+		db := 3
+		if tc.mode == modeCluster {
+			db = 0
+		}
+
+		options, mode, err := parseUniversalURL(tc.url, "env", db)
+		if err != nil {
+			t.Fatalf("parseUniversalURL(%q) error = %v", tc.url, err)
+		}
+		if mode != tc.mode || !reflect.DeepEqual(options.Addrs, tc.addrs) || options.MasterName != tc.master ||
+			options.Password != tc.password || options.SentinelPassword != tc.sentinelPw || options.DB != tc.db {
+			t.Errorf("parseUniversalURL(%q) = %s %+v", tc.url, mode, options)
+		}
+	}
+}
+
+func TestParseUniversalURLErrors(t *testing.T) {
+	for _, rawURL := range []string{
+		"memcached://host:11211",
+		"redis-sentinel://s1:26379",
+		"redis-sentinel://s1:26379/master/db",
+		"redis-cluster://n1:6379/0",
+		"redis-cluster:///",
+	} {
+		if _, _, err := parseUniversalURL(rawURL, "", 0); err == nil {
+			t.Errorf("parseUniversalURL(%q) expected error", rawURL)
+		}
+	}
+
+	if _, _, err := parseUniversalURL("redis-cluster://n1:6379", "", 1); err == nil {
+		t.Error("cluster must reject database selection")
+	}
+}
+
+func TestUniversalClientHelpers(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	client, err := NewUniversalClient("redis://"+server.Addr()+"/0", "", 0, nil, nil)
+	if err != nil {
+		t.Fatalf("NewUniversalClient() error = %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.SetJSON(ctx, "key", map[string]int{"a": 1}, time.Minute); err != nil {
+		t.Fatalf("SetJSON() error = %v", err)
+	}
+	var value map[string]int
+	if err := client.GetJSON(ctx, "key", &value); err != nil || value["a"] != 1 {
+		t.Fatalf("GetJSON() = %v, %v", value, err)
+	}
+
+	lock, err := client.AcquireLock(ctx, "lock", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+	if err := lock.Release(ctx); err != nil {
+		t.Errorf("Release() error = %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/database"
+	"github.com/vladzorgan/common/logging"
+	events "github.com/vladzorgan/common/messaging/rabbitmq"
+	"github.com/vladzorgan/common/metrics"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultArchiveBatchSize используется, если размер пакета не задан
+const defaultArchiveBatchSize = 1000
+
+// Archivable представляет модель, которая может быть помечена как прочитанная из архива
+type Archivable interface {
+	SetArchived(archived bool)
+}
+
+// ArchiveConfig определяет настройки архивации для репозитория
+type ArchiveConfig struct {
+	// Пауза между пакетами для ограничения нагрузки на базу данных
+	BatchDelay time.Duration
+	// Искать запись в архиве, если она не найдена в основной таблице
+	ReadThrough bool
+	// Издатель событий о ходе архивации (опционально)
+	Publisher events.EventPublisher
+	// Префикс метрик Prometheus
+	ServicePrefix string
+	// Логгер
+	Logger logging.Logger
+}
+
+// ArchiveProgressEvent представляет событие о ходе архивации
+type ArchiveProgressEvent struct {
+	Table    string `json:"table"`
+	Batch    int    `json:"batch"`
+	Archived int64  `json:"archived"`
+	Done     bool   `json:"done"`
+}
+
+// archiveMetrics содержит метрики архивации
+type archiveMetrics struct {
+	rowsTotal   *prometheus.CounterVec
+	lastRunRows *prometheus.GaugeVec
+}
+
+// WithArchive включает архивацию для репозитория
+func (r *BaseRepository[T]) WithArchive(config *ArchiveConfig) *BaseRepository[T] {
+	if config.Logger == nil {
+		config.Logger = logging.NewLogger()
+	}
+
+	r.archiveConfig = config
+	r.archiveMetrics = &archiveMetrics{
+		rowsTotal: metrics.Register(nil, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: config.ServicePrefix + "_archived_rows_total",
+				Help: "Количество строк, перенесенных в архив",
+			},
+			[]string{"table"},
+		)),
+		lastRunRows: metrics.Register(nil, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: config.ServicePrefix + "_archive_last_run_rows",
+				Help: "Количество строк, перенесенных в архив за последний запуск",
+			},
+			[]string{"table"},
+		)),
+	}
+
+	return r
+}
+
+// ArchiveWhere переносит записи, соответствующие фильтрам, в архивную таблицу "<table>_archive".
+// Каждый пакет копируется и удаляется в отдельной транзакции, поэтому прерванная архивация
+// продолжается со следующего запуска. Возвращает количество перенесенных записей.
+func (r *BaseRepository[T]) ArchiveWhere(ctx context.Context, filters map[string]interface{}, batchSize int) (int64, error) {
+	if r.archiveConfig == nil {
+		return 0, fmt.Errorf("archive is not configured for repository")
+	}
+
+	// Проверяем разрешения на запись
+	if err := r.checkWritePermission(ctx); err != nil {
+		return 0, err
+	}
+
+	if batchSize <= 0 {
+		batchSize = defaultArchiveBatchSize
+	}
+
+	var model T
+	table := model.GetTableName()
+
+	archiveTable, err := r.db.EnsureArchiveTable(ctx, table)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	batch := 0
+
+	for {
+		var moved int64
+		err := r.getDB(ctx).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			// Выбираем пакет записей, пропуская заблокированные другими транзакциями
+			ids := r.applyFilters(tx.Unscoped().Model(new(T)).Select("id"), filters).
+				Order("id ASC").
+				Limit(batchSize).
+				Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+
+			result := tx.Exec(
+				"WITH moved AS (DELETE FROM ? WHERE id IN (?) RETURNING *) INSERT INTO ? SELECT * FROM moved",
+				clause.Table{Name: table},
+				ids,
+				clause.Table{Name: archiveTable},
+			)
+			moved = result.RowsAffected
+			return result.Error
+		})
+		if err != nil {
+			return total, fmt.Errorf("failed to archive batch %d of %s: %v", batch+1, table, err)
+		}
+
+		if moved == 0 {
+			break
+		}
+
+		total += moved
+		batch++
+		r.archiveMetrics.rowsTotal.WithLabelValues(table).Add(float64(moved))
+		r.publishArchiveProgress(ctx, ArchiveProgressEvent{Table: table, Batch: batch, Archived: total})
+
+		if moved < int64(batchSize) {
+			break
+		}
+
+		// Ограничиваем скорость архивации
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(r.archiveConfig.BatchDelay):
+		}
+	}
+
+	r.archiveMetrics.lastRunRows.WithLabelValues(table).Set(float64(total))
+	r.publishArchiveProgress(ctx, ArchiveProgressEvent{Table: table, Batch: batch, Archived: total, Done: true})
+	r.archiveConfig.Logger.Info("Archived %d rows from %s in %d batches", total, table, batch)
+
+	return total, nil
+}
+
+// ScheduleArchive периодически запускает архивацию до отмены контекста.
+// Фильтры вычисляются перед каждым запуском, что позволяет задавать относительные границы.
+func (r *BaseRepository[T]) ScheduleArchive(ctx context.Context, interval time.Duration, filters func() map[string]interface{}, batchSize int) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := r.ArchiveWhere(ctx, filters(), batchSize); err != nil {
+					r.archiveConfig.Logger.Error("Scheduled archive failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// GetByIDWithArchive получает запись по ID, при необходимости из архива.
+// Второе значение показывает, что запись найдена в архиве.
+func (r *BaseRepository[T]) GetByIDWithArchive(ctx context.Context, id uint) (*T, bool, error) {
+	return r.getByID(ctx, id, r.archiveConfig != nil)
+}
+
+// getArchivedByID получает запись по ID из архивной таблицы
+func (r *BaseRepository[T]) getArchivedByID(ctx context.Context, id uint) (*T, bool, error) {
+	var model T
+	archived := new(T)
+
+	query := r.getDB(ctx).WithContext(ctx).Table(database.ArchiveTableName(model.GetTableName()))
+	// Применяем фильтр по владению
+	query = r.applyOwnershipFilter(ctx, query)
+
+	if err := query.First(archived, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	// Проверяем права владения
+	if err := r.checkOwnership(ctx, archived); err != nil {
+		return nil, false, err
+	}
+
+	if archivable, ok := any(archived).(Archivable); ok {
+		archivable.SetArchived(true)
+	}
+
+	return archived, true, nil
+}
+
+// publishArchiveProgress публикует событие о ходе архивации
+func (r *BaseRepository[T]) publishArchiveProgress(ctx context.Context, event ArchiveProgressEvent) {
+	if r.archiveConfig.Publisher == nil {
+		return
+	}
+
+	if err := r.archiveConfig.Publisher.PublishEvent(ctx, event.Table+".archive.progress", event); err != nil {
+		r.archiveConfig.Logger.Warn("Failed to publish archive progress for %s: %v", event.Table, err)
+	}
+}
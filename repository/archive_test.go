@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vladzorgan/common/auth"
+	events "github.com/vladzorgan/common/messaging/rabbitmq"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type archiveOrder struct {
+	ID        uint
+	Name      string
+	CreatedAt time.Time
+	Archived  bool `gorm:"-"`
+}
+
+func (o archiveOrder) GetID() uint             { return o.ID }
+func (archiveOrder) GetTableName() string      { return "archive_orders" }
+func (archiveOrder) TableName() string         { return "archive_orders" }
+func (o *archiveOrder) SetArchived(value bool) { o.Archived = value }
+
+// archiveStore тестовый драйвер: запись 5 есть только в архивной таблице
+type archiveStore struct{}
+
+func (archiveStore) Connect(context.Context) (driver.Conn, error) { return archiveConn{}, nil }
+func (archiveStore) Driver() driver.Driver                        { return nil }
+
+type archiveConn struct{}
+
+func (archiveConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (archiveConn) Close() error                        { return nil }
+func (archiveConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (archiveConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	columns := []string{"id", "name", "created_at"}
+	switch {
+	case strings.Contains(query, `FROM "archive_orders_archive"`):
+		if id, _ := args[0].Value.(int64); id == 5 {
+			return &tagRows{columns: columns, values: [][]driver.Value{{int64(5), "old", time.Now()}}}, nil
+		}
+		return &tagRows{columns: columns}, nil
+	case strings.Contains(query, `FROM "archive_orders"`):
+		return &tagRows{columns: columns}, nil
+	default:
+		return nil, errors.New("unexpected query: " + query)
+	}
+}
+
+func TestGetByIDWithArchiveReadThrough(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(archiveStore{})}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	repo := NewBaseRepository[archiveOrder](nil)
+	repo.tx = db
+	ctx := context.Background()
+
+	// Без настроенной архивации запись из архива не читается
+	if order, archived, err := repo.GetByIDWithArchive(ctx, 5); err != nil || order != nil || archived {
+		t.Fatalf("GetByIDWithArchive() without archive = %v, %v, %v", order, archived, err)
+	}
+
+	repo.WithArchive(&ArchiveConfig{ServicePrefix: "archive_read_through_test", ReadThrough: true})
+
+	order, archived, err := repo.GetByIDWithArchive(ctx, 5)
+	if err != nil {
+		t.Fatalf("GetByIDWithArchive() error = %v", err)
+	}
+	if order == nil || !archived || !order.Archived || order.Name != "old" {
+		t.Errorf("GetByIDWithArchive() = %+v, archived = %v", order, archived)
+	}
+
+	// GetByID читает архив при ReadThrough
+	if order, err := repo.GetByID(ctx, 5); err != nil || order == nil || !order.Archived {
+		t.Errorf("GetByID() = %+v, %v; want archived order", order, err)
+	}
+	if order, archived, err := repo.GetByIDWithArchive(ctx, 6); err != nil || order != nil || archived {
+		t.Errorf("GetByIDWithArchive() of missing row = %v, %v, %v", order, archived, err)
+	}
+}
+
+// recordingArchivePublisher запоминает события о ходе архивации
+type recordingArchivePublisher struct {
+	mutex  sync.Mutex
+	events []ArchiveProgressEvent
+}
+
+func (p *recordingArchivePublisher) PublishEvent(_ context.Context, _ string, payload interface{}) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.events = append(p.events, payload.(ArchiveProgressEvent))
+	return nil
+}
+
+func (p *recordingArchivePublisher) PublishEventWithConfig(ctx context.Context, routingKey string, payload interface{}, _ *events.PublishConfig) error {
+	return p.PublishEvent(ctx, routingKey, payload)
+}
+
+func (p *recordingArchivePublisher) Close() {}
+
+func TestArchiveWherePostgres(t *testing.T) {
+	db := newPostgresDatabase(t)
+	gormDB := db.GetDB()
+	t.Cleanup(func() {
+		gormDB.Exec("DROP TABLE IF EXISTS archive_orders_archive, archive_orders")
+	})
+
+	gormDB.Exec("DROP TABLE IF EXISTS archive_orders_archive, archive_orders")
+	if err := gormDB.AutoMigrate(&archiveOrder{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+
+	cutoff := time.Now().AddDate(-2, 0, 0)
+	var orders []archiveOrder
+	for i := 0; i < 25; i++ {
+		orders = append(orders, archiveOrder{Name: "old", CreatedAt: cutoff.AddDate(0, 0, -1-i)})
+	}
+	for i := 0; i < 5; i++ {
+		orders = append(orders, archiveOrder{Name: "recent", CreatedAt: time.Now()})
+	}
+	if err := gormDB.Create(&orders).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	publisher := &recordingArchivePublisher{}
+	repo := NewBaseRepository[archiveOrder](db).WithArchive(&ArchiveConfig{
+		ServicePrefix: "archive_it",
+		ReadThrough:   true,
+		Publisher:     publisher,
+	})
+	admin := auth.WithUser(context.Background(), &auth.User{ID: 1, Role: auth.UserRole_Admin, IsActive: true})
+
+	moved, err := repo.ArchiveWhere(admin, map[string]interface{}{"created_before": cutoff}, 10)
+	if err != nil {
+		t.Fatalf("ArchiveWhere() error = %v", err)
+	}
+	if moved != 25 {
+		t.Errorf("ArchiveWhere() = %d, want 25", moved)
+	}
+
+	var hot, archived int64
+	gormDB.Table("archive_orders").Count(&hot)
+	gormDB.Table("archive_orders_archive").Count(&archived)
+	if hot != 5 || archived != 25 {
+		t.Errorf("rows: hot = %d, archive = %d; want 5, 25", hot, archived)
+	}
+
+	// Три пакета (10, 10, 5) и итоговое событие
+	if len(publisher.events) != 4 || !publisher.events[3].Done || publisher.events[3].Archived != 25 {
+		t.Errorf("progress events = %+v", publisher.events)
+	}
+
+	// Повторный запуск ничего не переносит
+	if moved, err := repo.ArchiveWhere(admin, map[string]interface{}{"created_before": cutoff}, 10); err != nil || moved != 0 {
+		t.Errorf("second ArchiveWhere() = %d, %v", moved, err)
+	}
+
+	order, fromArchive, err := repo.GetByIDWithArchive(admin, orders[0].ID)
+	if err != nil || order == nil || !fromArchive || !order.Archived {
+		t.Errorf("GetByIDWithArchive() = %+v, %v, %v", order, fromArchive, err)
+	}
+}
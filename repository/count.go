@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TotalUnknown возвращается GetAll, Search и GetAllByField вместо общего количества записей
+// с опцией WithoutTotal
+const TotalUnknown int64 = -1
+
+// countMode способ получения общего количества записей для списков
+type countMode int
+
+const (
+	// countExact точный COUNT(*) по условиям запроса (по умолчанию)
+	countExact countMode = iota
+	// countNone без запроса количества, total = TotalUnknown
+	countNone
+	// countEstimated оценка по статистике планировщика pg_class.reltuples
+	countEstimated
+)
+
+// WithoutTotal отключает запрос COUNT(*) в GetAll, Search и GetAllByField: вместо общего количества
+// записей возвращается TotalUnknown. Подходит для вызовов, которым не нужны total и pages.
+func WithoutTotal() QueryOption {
+	return func(o *queryOptions) {
+		o.count = countNone
+	}
+}
+
+// WithEstimatedTotal заменяет точный COUNT(*) в GetAll, Search и GetAllByField оценкой количества
+// строк таблицы из pg_class.reltuples (обновляется VACUUM и ANALYZE). Оценка применяется только
+// к запросам без фильтров, условий Scope и фильтров по владению и арендатору и к моделям без мягкого
+// удаления (reltuples учитывает мягко удаленные записи); в остальных случаях, а также для таблиц
+// без собранной статистики выполняется точный подсчет.
+func WithEstimatedTotal() QueryOption {
+	return func(o *queryOptions) {
+		o.count = countEstimated
+	}
+}
+
+// countTotal возвращает общее количество записей запроса queryCount в режиме из опций вызова
+func (r *repositoryCore[T]) countTotal(queryCount *gorm.DB, opts []QueryOption) (int64, error) {
+	options := &queryOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	switch options.count {
+	case countNone:
+		return TotalUnknown, nil
+	case countEstimated:
+		if len(options.scopes) == 0 {
+			if estimate, ok := r.estimateTotal(queryCount); ok {
+				return estimate, nil
+			}
+		}
+	}
+
+	var total int64
+	if err := queryCount.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// estimateTotal оценивает количество строк таблицы по pg_class.reltuples. Возвращает false, если
+// запрос ограничен условиями, соединениями или условиями модели (мягкое удаление), а также если
+// статистика по таблице еще не собрана.
+func (r *repositoryCore[T]) estimateTotal(queryCount *gorm.DB) (int64, bool) {
+	stmt := queryCount.Statement
+	if len(stmt.Joins) > 0 {
+		return 0, false
+	}
+	for _, name := range []string{clause.Where{}.Name(), clause.GroupBy{}.Name(), clause.From{}.Name()} {
+		if _, ok := stmt.Clauses[name]; ok {
+			return 0, false
+		}
+	}
+
+	parsed := &gorm.Statement{DB: queryCount}
+	if err := parsed.Parse(new(T)); err != nil {
+		return 0, false
+	}
+	// Условия модели (например, deleted_at IS NULL для gorm.DeletedAt) добавляются при выполнении
+	// запроса, а reltuples учитывает все строки, поэтому для таких моделей нужен точный подсчет
+	if len(parsed.Schema.QueryClauses) > 0 {
+		return 0, false
+	}
+
+	table := stmt.Table
+	if table == "" {
+		table = parsed.Table
+	}
+
+	// Ошибка оценки не критична: выполняется точный подсчет
+	var estimate *int64
+	if err := queryCount.Session(&gorm.Session{NewDB: true}).
+		Raw("SELECT reltuples::bigint FROM pg_class WHERE oid = to_regclass(?)", table).
+		Scan(&estimate).Error; err != nil || estimate == nil {
+		return 0, false
+	}
+
+	// До первого ANALYZE reltuples равен -1 (в старых версиях PostgreSQL - 0)
+	if *estimate <= 0 {
+		return 0, false
+	}
+	return *estimate, true
+}
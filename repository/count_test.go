@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// countStore тестовый драйвер списков: записывает запросы и имитирует их длительность.
+// На большой таблице COUNT(*) выполняется заметно дольше выборки страницы.
+type countStore struct {
+	mutex   sync.Mutex
+	queries []string
+
+	total     int64
+	reltuples int64
+
+	countDelay    time.Duration
+	pageDelay     time.Duration
+	estimateDelay time.Duration
+}
+
+func (s *countStore) Connect(context.Context) (driver.Conn, error) { return &countConn{store: s}, nil }
+func (s *countStore) Driver() driver.Driver                        { return nil }
+
+// executed возвращает запросы, начинающиеся с prefix
+func (s *countStore) executed(prefix string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	n := 0
+	for _, query := range s.queries {
+		if strings.HasPrefix(query, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+type countConn struct {
+	store *countStore
+}
+
+func (c *countConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *countConn) Close() error                        { return nil }
+func (c *countConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *countConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.store.mutex.Lock()
+	c.store.queries = append(c.store.queries, query)
+	c.store.mutex.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "SELECT count(*)"):
+		time.Sleep(c.store.countDelay)
+		return &tagRows{columns: []string{"count"}, values: [][]driver.Value{{c.store.total}}}, nil
+	case strings.HasPrefix(query, "SELECT reltuples"):
+		time.Sleep(c.store.estimateDelay)
+		return &tagRows{columns: []string{"reltuples"}, values: [][]driver.Value{{c.store.reltuples}}}, nil
+	case strings.HasPrefix(query, "SELECT *"):
+		time.Sleep(c.store.pageDelay)
+		return &tagRows{values: [][]driver.Value{{int64(1), "go"}}}, nil
+	default:
+		return nil, errors.New("unexpected query: " + query)
+	}
+}
+
+func newCountRepository[T BaseModel](tb testing.TB, store *countStore) *BaseRepository[T] {
+	tb.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(store)}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		tb.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	repo := NewBaseRepository[T](nil)
+	repo.tx = db
+	return repo
+}
+
+func TestWithoutTotalSkipsCount(t *testing.T) {
+	store := &countStore{total: 42}
+	repo := newCountRepository[labelEntity](t, store)
+	ctx := context.Background()
+
+	calls := map[string]func() ([]labelEntity, int64, error){
+		"GetAll": func() ([]labelEntity, int64, error) {
+			return repo.GetAll(ctx, 0, 10, nil, nil, WithoutTotal())
+		},
+		"Search": func() ([]labelEntity, int64, error) {
+			return repo.Search(ctx, "go", 0, 10, nil, nil, WithoutTotal())
+		},
+		"GetAllByField": func() ([]labelEntity, int64, error) {
+			return repo.GetAllByField(ctx, "name", "go", 0, 10, WithoutTotal())
+		},
+	}
+
+	for name, call := range calls {
+		entities, total, err := call()
+		if err != nil {
+			t.Fatalf("%s() error = %v", name, err)
+		}
+		if total != TotalUnknown || len(entities) != 1 {
+			t.Errorf("%s() = %d entities, total %d; want 1, %d", name, len(entities), total, TotalUnknown)
+		}
+	}
+	if n := store.executed("SELECT count(*)"); n != 0 {
+		t.Errorf("count queries = %d, want 0", n)
+	}
+
+	// Без опции общее количество считается точно
+	if _, total, err := repo.GetAll(ctx, 0, 10, nil, nil); err != nil || total != 42 {
+		t.Errorf("GetAll() total = %d, error = %v; want 42", total, err)
+	}
+}
+
+func TestWithEstimatedTotal(t *testing.T) {
+	tests := []struct {
+		name      string
+		reltuples int64
+		filters   map[string]interface{}
+		opts      []QueryOption
+		want      int64
+		counts    int
+	}{
+		{name: "unrestricted", reltuples: 1000, want: 1000},
+		{name: "no statistics", reltuples: -1, want: 42, counts: 1},
+		{name: "filtered", reltuples: 1000, filters: map[string]interface{}{"name": "go"}, want: 42, counts: 1},
+		{name: "scoped", reltuples: 1000, opts: []QueryOption{Scope(func(db *gorm.DB) *gorm.DB { return db })}, want: 42, counts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &countStore{total: 42, reltuples: tt.reltuples}
+			repo := newCountRepository[labelEntity](t, store)
+
+			_, total, err := repo.GetAll(context.Background(), 0, 10, tt.filters, nil, append(tt.opts, WithEstimatedTotal())...)
+			if err != nil {
+				t.Fatalf("GetAll() error = %v", err)
+			}
+			if total != tt.want {
+				t.Errorf("total = %d, want %d", total, tt.want)
+			}
+			if n := store.executed("SELECT count(*)"); n != tt.counts {
+				t.Errorf("count queries = %d, want %d", n, tt.counts)
+			}
+		})
+	}
+}
+
+// softLabelEntity метка с мягким удалением
+type softLabelEntity struct {
+	ID        uint
+	Name      string
+	DeletedAt gorm.DeletedAt
+}
+
+func (t softLabelEntity) GetID() uint        { return t.ID }
+func (softLabelEntity) GetTableName() string { return "labels" }
+func (softLabelEntity) TableName() string    { return "labels" }
+
+func TestWithEstimatedTotalCountsSoftDeleteExactly(t *testing.T) {
+	store := &countStore{total: 42, reltuples: 1000}
+	repo := newCountRepository[softLabelEntity](t, store)
+
+	// reltuples учитывает мягко удаленные строки, поэтому выполняется точный подсчет
+	_, total, err := repo.GetAll(context.Background(), 0, 10, nil, nil, WithEstimatedTotal())
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if total != 42 {
+		t.Errorf("total = %d, want 42", total)
+	}
+	if n := store.executed("SELECT reltuples"); n != 0 {
+		t.Errorf("estimate queries = %d, want 0", n)
+	}
+	if n := store.executed("SELECT count(*)"); n != 1 {
+		t.Errorf("count queries = %d, want 1", n)
+	}
+}
+
+// BenchmarkGetAll сравнивает GetAll с точным подсчетом, без подсчета и с оценкой по статистике
+// на тестовом драйвере, имитирующем таблицу, где COUNT(*) в 10 раз медленнее выборки страницы
+// (на реальной базе см. BenchmarkGetAllPostgres)
+func BenchmarkGetAll(b *testing.B) {
+	modes := []struct {
+		name string
+		opts []QueryOption
+	}{
+		{name: "exact"},
+		{name: "without_total", opts: []QueryOption{WithoutTotal()}},
+		{name: "estimated", opts: []QueryOption{WithEstimatedTotal()}},
+	}
+
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			store := &countStore{
+				total:         1_000_000,
+				reltuples:     1_000_000,
+				countDelay:    2 * time.Millisecond,
+				pageDelay:     200 * time.Microsecond,
+				estimateDelay: 20 * time.Microsecond,
+			}
+			repo := newCountRepository[labelEntity](b, store)
+			ctx := context.Background()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := repo.GetAll(ctx, 0, 20, nil, nil, mode.opts...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkGetAllPostgres сравнивает режимы подсчета GetAll на таблице Postgres из COMMON_IT_DATABASE_URL
+// со 100 000 строк; без переменной окружения пропускается
+func BenchmarkGetAllPostgres(b *testing.B) {
+	db := newPostgresDatabase(b)
+	gormDB := db.GetDB()
+
+	gormDB.Exec("DROP TABLE IF EXISTS labels")
+	if err := gormDB.Exec("CREATE TABLE labels (id bigserial PRIMARY KEY, name text)").Error; err != nil {
+		b.Fatalf("create table: %v", err)
+	}
+	b.Cleanup(func() { gormDB.Exec("DROP TABLE labels") })
+	if err := gormDB.Exec("INSERT INTO labels (name) SELECT 'label-' || n FROM generate_series(1, 100000) AS n").Error; err != nil {
+		b.Fatalf("insert: %v", err)
+	}
+	// Статистика для WithEstimatedTotal
+	if err := gormDB.Exec("ANALYZE labels").Error; err != nil {
+		b.Fatalf("analyze: %v", err)
+	}
+
+	repo := NewBaseRepository[labelEntity](db)
+	ctx := context.Background()
+	modes := []struct {
+		name string
+		opts []QueryOption
+	}{
+		{name: "exact"},
+		{name: "without_total", opts: []QueryOption{WithoutTotal()}},
+		{name: "estimated", opts: []QueryOption{WithEstimatedTotal()}},
+	}
+
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, _, err := repo.GetAll(ctx, 0, 20, nil, nil, mode.opts...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
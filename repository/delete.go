@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrEmptyDeleteFilter возвращается DeleteByFilter без фильтров и условий Scope, если не передана опция AllowDeleteAll
+var ErrEmptyDeleteFilter = errors.New("delete by filter requires at least one filter; use AllowDeleteAll to delete all records")
+
+// AllowDeleteAll разрешает DeleteByFilter без фильтров, то есть удаление всех доступных записей таблицы
+func AllowDeleteAll() QueryOption {
+	return func(o *queryOptions) {
+		o.allowDeleteAll = true
+	}
+}
+
+// CheckDeleteFilter проверяет, что удаление по фильтрам ограничено хотя бы одним фильтром или условием Scope.
+// Пустые значения фильтров не учитываются, так как не попадают в запрос.
+func CheckDeleteFilter(filters map[string]interface{}, opts ...QueryOption) error {
+	options := &queryOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.allowDeleteAll || len(options.scopes) > 0 {
+		return nil
+	}
+
+	for _, value := range filters {
+		if value != nil && value != "" {
+			return nil
+		}
+	}
+
+	return ErrEmptyDeleteFilter
+}
+
+// DeleteMany удаляет записи с указанными ID одним запросом (soft delete, если модель его поддерживает).
+// Чужие записи для обычного пользователя не удаляются. Возвращает количество удаленных записей.
+func (r *BaseRepository[T]) DeleteMany(ctx context.Context, ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// Проверяем разрешения на запись (для удаления)
+	if err := r.checkWritePermission(ctx); err != nil {
+		return 0, err
+	}
+
+	query := r.applyOwnershipFilter(ctx, r.getDB(ctx).WithContext(ctx))
+	result := query.Where("id IN ?", ids).Delete(new(T))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// DeleteByFilter удаляет записи, соответствующие фильтрам и условиям Scope, одним запросом.
+// Фильтры применяются так же, как в GetAll, вместе с фильтром по владению.
+// Без фильтров возвращает ErrEmptyDeleteFilter, если не передана опция AllowDeleteAll.
+// ID удаленных записей не возвращаются: если они нужны для аудита, событий или инвалидации кеша,
+// найдите записи и удалите их через DeleteMany в одной транзакции (так делает service.DeleteByFilter).
+func (r *repositoryCore[T]) DeleteByFilter(ctx context.Context, filters map[string]interface{}, opts ...QueryOption) (int64, error) {
+	if err := CheckDeleteFilter(filters, opts...); err != nil {
+		return 0, err
+	}
+
+	// Проверяем разрешения на запись (для удаления)
+	if err := r.checkWritePermission(ctx); err != nil {
+		return 0, err
+	}
+
+	query := r.getDB(ctx).WithContext(ctx).Model(new(T))
+	query = r.applyOwnershipFilter(ctx, query)
+	query = r.applyScopes(r.applyFilters(query, filters), opts)
+
+	// Пустой фильтр уже разрешен опцией AllowDeleteAll; без этого GORM вернет ErrMissingWhereClause
+	result := query.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(new(T))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
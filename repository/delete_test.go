@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vladzorgan/common/auth"
+	"gorm.io/gorm"
+)
+
+// captureDelete запоминает SQL запросов удаления репозитория
+func captureDelete(repo *BaseRepository[orderEntity]) *string {
+	// Транзакция по умолчанию требует подключения, которого у DryRun соединения нет
+	repo.tx.SkipDefaultTransaction = true
+
+	var sql string
+	repo.tx.Callback().Delete().After("gorm:delete").Register("test:capture_delete", func(tx *gorm.DB) {
+		sql = tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)
+	})
+	return &sql
+}
+
+func TestDeleteManyAppliesOwnership(t *testing.T) {
+	user := auth.WithUser(context.Background(), &auth.User{ID: 7, Role: auth.UserRole_User, IsActive: true})
+
+	repo, _ := newOwnedRepository(t)
+	sql := captureDelete(repo)
+
+	if _, err := repo.DeleteMany(user, []uint{1, 2, 3}); err != nil {
+		t.Fatalf("DeleteMany() error = %v", err)
+	}
+	if want := `DELETE FROM "orders" WHERE user_id = 7 AND id IN (1,2,3)`; *sql != want {
+		t.Errorf("DeleteMany() SQL = %s, want %s", *sql, want)
+	}
+}
+
+func TestDeleteByFilterRequiresFilterOrAllowDeleteAll(t *testing.T) {
+	admin := auth.WithUser(context.Background(), &auth.User{ID: 1, Role: auth.UserRole_Admin, IsActive: true})
+
+	repo, _ := newOwnedRepository(t)
+	sql := captureDelete(repo)
+
+	if _, err := repo.DeleteByFilter(admin, map[string]interface{}{"status": ""}); !errors.Is(err, ErrEmptyDeleteFilter) {
+		t.Fatalf("DeleteByFilter() with empty filter error = %v, want ErrEmptyDeleteFilter", err)
+	}
+	if *sql != "" {
+		t.Fatalf("query must not run without filters, got %s", *sql)
+	}
+
+	if _, err := repo.DeleteByFilter(admin, map[string]interface{}{"status": "cancelled"}); err != nil {
+		t.Fatalf("DeleteByFilter() error = %v", err)
+	}
+	if want := `DELETE FROM "orders" WHERE status = 'cancelled'`; *sql != want {
+		t.Errorf("DeleteByFilter() SQL = %s, want %s", *sql, want)
+	}
+
+	if _, err := repo.DeleteByFilter(admin, nil, AllowDeleteAll()); err != nil {
+		t.Fatalf("DeleteByFilter() with AllowDeleteAll error = %v", err)
+	}
+	if want := `DELETE FROM "orders"`; *sql != want {
+		t.Errorf("DeleteByFilter() SQL = %s, want %s", *sql, want)
+	}
+}
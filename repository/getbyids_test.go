@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vladzorgan/common/auth"
+)
+
+func TestGetByIDsBuildsSingleQuery(t *testing.T) {
+	admin := auth.WithUser(context.Background(), &auth.User{ID: 1, Role: auth.UserRole_Admin, IsActive: true})
+	user := auth.WithUser(context.Background(), &auth.User{ID: 7, Role: auth.UserRole_User, IsActive: true})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{name: "admin", ctx: admin, want: `SELECT * FROM "orders" WHERE id IN (3,1,2) ORDER BY id`},
+		{name: "user", ctx: user, want: `SELECT * FROM "orders" WHERE user_id = 7 AND id IN (3,1,2) ORDER BY id`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, sql := newOwnedRepository(t)
+			if _, err := repo.GetByIDs(tt.ctx, []uint{3, 1, 2}); err != nil {
+				t.Fatalf("GetByIDs() error = %v", err)
+			}
+			if *sql != tt.want {
+				t.Errorf("SQL = %s, want %s", *sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetByIDsEmpty(t *testing.T) {
+	user := auth.WithUser(context.Background(), &auth.User{ID: 7, Role: auth.UserRole_User, IsActive: true})
+	repo, sql := newOwnedRepository(t)
+
+	for _, ids := range [][]uint{nil, {}} {
+		entities, err := repo.GetByIDs(user, ids)
+		if err != nil || entities == nil || len(entities) != 0 {
+			t.Errorf("GetByIDs(%v) = %v, %v; want empty non-nil slice", ids, entities, err)
+		}
+	}
+	if *sql != "" {
+		t.Errorf("GetByIDs() with no IDs ran %s, want no query", *sql)
+	}
+
+	// Проверка разрешений выполняется и для пустого списка
+	if _, err := repo.GetByIDs(context.Background(), nil); err == nil {
+		t.Error("GetByIDs() without user must fail")
+	}
+}
+
+func TestGetByIDsOrdersByIDPostgres(t *testing.T) {
+	db := newPostgresDatabase(t)
+	gormDB := db.GetDB()
+
+	if err := gormDB.Exec("CREATE TABLE labels (id bigint PRIMARY KEY, name text)").Error; err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	t.Cleanup(func() { gormDB.Exec("DROP TABLE labels") })
+	// Записи вставляются не по порядку ID
+	if err := gormDB.Exec("INSERT INTO labels (id, name) VALUES (5, 'e'), (2, 'b'), (4, 'd'), (1, 'a'), (3, 'c')").Error; err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	repo := NewBaseRepository[labelEntity](db)
+	entities, err := repo.GetByIDs(context.Background(), []uint{4, 9, 1, 2, 4})
+	if err != nil {
+		t.Fatalf("GetByIDs() error = %v", err)
+	}
+
+	// Результат упорядочен по ID, отсутствующие и повторяющиеся ID не дают лишних записей
+	if len(entities) != 3 || entities[0].ID != 1 || entities[1].ID != 2 || entities[2].ID != 4 {
+		t.Errorf("entities = %+v, want IDs 1, 2, 4", entities)
+	}
+}
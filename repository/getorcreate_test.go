@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type labelEntity struct {
+	ID   uint
+	Name string
+}
+
+func (t labelEntity) GetID() uint        { return t.ID }
+func (labelEntity) GetTableName() string { return "labels" }
+func (labelEntity) TableName() string    { return "labels" }
+
+// uniqueViolation ошибка нарушения уникальности; диалект postgres распознает ее по коду
+type uniqueViolation struct {
+	Code string
+}
+
+func (e *uniqueViolation) Error() string { return "duplicate key value violates unique constraint" }
+
+// tagStore хранилище тегов для тестового драйвера с уникальным индексом по name.
+// Первые readers запросов SELECT ждут друг друга, чтобы все они не нашли запись.
+type tagStore struct {
+	mutex   sync.Mutex
+	tags    map[string]int64
+	inserts int
+
+	readers int
+	waiting int
+	release chan struct{}
+}
+
+func newTagStore(readers int) *tagStore {
+	return &tagStore{tags: make(map[string]int64), readers: readers, release: make(chan struct{})}
+}
+
+func (s *tagStore) Connect(context.Context) (driver.Conn, error) { return &tagConn{store: s}, nil }
+func (s *tagStore) Driver() driver.Driver                        { return nil }
+
+// wait блокирует первые readers чтений, пока все они не начнутся
+func (s *tagStore) wait(ctx context.Context) error {
+	s.mutex.Lock()
+	if s.waiting >= s.readers {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.waiting++
+	if s.waiting == s.readers {
+		close(s.release)
+	}
+	s.mutex.Unlock()
+
+	select {
+	case <-s.release:
+		return nil
+	case <-time.After(time.Second):
+		return errors.New("readers did not meet at barrier")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type tagConn struct {
+	store *tagStore
+}
+
+func (c *tagConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *tagConn) Close() error                        { return nil }
+func (c *tagConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c *tagConn) Commit() error                       { return nil }
+func (c *tagConn) Rollback() error                     { return nil }
+
+func (c *tagConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) { return c, nil }
+
+func (c *tagConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	// SAVEPOINT и ROLLBACK TO SAVEPOINT не меняют состояние хранилища
+	return driver.RowsAffected(0), nil
+}
+
+func (c *tagConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	name, _ := args[0].Value.(string)
+
+	switch {
+	case strings.HasPrefix(query, "SELECT"):
+		if err := c.store.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		c.store.mutex.Lock()
+		defer c.store.mutex.Unlock()
+		rows := &tagRows{}
+		if id, ok := c.store.tags[name]; ok {
+			rows.values = [][]driver.Value{{id, name}}
+		}
+		return rows, nil
+	case strings.HasPrefix(query, "INSERT"):
+		c.store.mutex.Lock()
+		defer c.store.mutex.Unlock()
+		if _, ok := c.store.tags[name]; ok {
+			return nil, &uniqueViolation{Code: "23505"}
+		}
+		c.store.inserts++
+		id := int64(len(c.store.tags) + 1)
+		c.store.tags[name] = id
+		return &tagRows{columns: []string{"id"}, values: [][]driver.Value{{id}}}, nil
+	default:
+		return nil, errors.New("unexpected query: " + query)
+	}
+}
+
+type tagRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *tagRows) Columns() []string {
+	if r.columns == nil {
+		return []string{"id", "name"}
+	}
+	return r.columns
+}
+
+func (r *tagRows) Close() error { return nil }
+
+func (r *tagRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// newTagRepository создает репозиторий тегов поверх тестового драйвера
+func newTagRepository(t *testing.T, store *tagStore) *BaseRepository[labelEntity] {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(store)}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		TranslateError:         true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	repo := NewBaseRepository[labelEntity](nil)
+	repo.tx = db
+	return repo
+}
+
+func TestGetOrCreateReturnsExisting(t *testing.T) {
+	store := newTagStore(0)
+	store.tags["go"] = 7
+	repo := newTagRepository(t, store)
+
+	tag, created, err := repo.GetOrCreate(context.Background(), "name", "go", func() *labelEntity {
+		t.Fatal("create called for existing record")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GetOrCreate() error = %v", err)
+	}
+	if created || tag.ID != 7 {
+		t.Fatalf("GetOrCreate() = %+v, %v; want existing record 7", tag, created)
+	}
+}
+
+func TestGetOrCreateConcurrent(t *testing.T) {
+	store := newTagStore(2)
+	repo := newTagRepository(t, store)
+
+	type result struct {
+		tag     *labelEntity
+		created bool
+		err     error
+	}
+	results := make([]result, 2)
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tag, created, err := repo.GetOrCreate(context.Background(), "name", "go", func() *labelEntity {
+				return &labelEntity{Name: "go"}
+			})
+			results[i] = result{tag: tag, created: created, err: err}
+		}(i)
+	}
+	wg.Wait()
+
+	createdCount := 0
+	for _, r := range results {
+		if r.err != nil {
+			t.Fatalf("GetOrCreate() error = %v", r.err)
+		}
+		if r.tag == nil || r.tag.ID != 1 || r.tag.Name != "go" {
+			t.Fatalf("GetOrCreate() tag = %+v, want record 1", r.tag)
+		}
+		if r.created {
+			createdCount++
+		}
+	}
+	if createdCount != 1 {
+		t.Fatalf("created = %d, want exactly one", createdCount)
+	}
+	if store.inserts != 1 {
+		t.Fatalf("inserts = %d, want 1", store.inserts)
+	}
+}
+
+func TestGetOrCreateOtherUniqueViolation(t *testing.T) {
+	store := newTagStore(0)
+	store.tags["go"] = 1
+	repo := newTagRepository(t, store)
+
+	// Запись с другим значением поля не найдена и при повторном чтении: ошибка возвращается
+	_, created, err := repo.GetOrCreate(context.Background(), "name", "golang", func() *labelEntity {
+		return &labelEntity{Name: "go"}
+	})
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Fatalf("GetOrCreate() error = %v, want %v", err, gorm.ErrDuplicatedKey)
+	}
+	if created {
+		t.Fatal("GetOrCreate() created = true, want false")
+	}
+}
@@ -0,0 +1,80 @@
+package repository
+
+import "gorm.io/gorm"
+
+// QueryOption настраивает отдельный запрос репозитория
+type QueryOption func(*queryOptions)
+
+// queryOptions содержит настройки запроса
+type queryOptions struct {
+	preloads       []string
+	scopes         []func(*gorm.DB) *gorm.DB
+	allowDeleteAll bool
+	count          countMode
+}
+
+// WithPreload загружает указанные связи вместе с сущностью.
+// Поддерживаются вложенные связи через точку, например "Items.Product".
+func WithPreload(associations ...string) QueryOption {
+	return func(o *queryOptions) {
+		o.preloads = append(o.preloads, associations...)
+	}
+}
+
+// Scope добавляет к запросам GetAll, Search, GetAllByField и Count произвольные условия
+// (Where, Joins, Group) поверх фильтров, сортировки и фильтра по владению, не заменяя их.
+// При соединении таблиц столбцы в условиях указываются с именем таблицы.
+func Scope(fn func(*gorm.DB) *gorm.DB) QueryOption {
+	return func(o *queryOptions) {
+		o.scopes = append(o.scopes, fn)
+	}
+}
+
+// WithDefaultPreloads задает связи, загружаемые во всех запросах чтения репозитория
+func (r *BaseRepository[T]) WithDefaultPreloads(associations ...string) *BaseRepository[T] {
+	r.preloads = append([]string(nil), associations...)
+	return r
+}
+
+// applyPreloads добавляет в запрос загрузку связей по умолчанию и из опций вызова
+func (r *repositoryCore[T]) applyPreloads(query *gorm.DB, opts []QueryOption) *gorm.DB {
+	// Без опций и связей по умолчанию запрос не изменяется
+	if len(opts) == 0 && len(r.preloads) == 0 {
+		return query
+	}
+
+	options := &queryOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	seen := make(map[string]bool, len(r.preloads)+len(options.preloads))
+	for _, associations := range [][]string{r.preloads, options.preloads} {
+		for _, association := range associations {
+			if association == "" || seen[association] {
+				continue
+			}
+			seen[association] = true
+			query = query.Preload(association)
+		}
+	}
+
+	return query
+}
+
+// applyScopes применяет к запросу условия Scope из опций вызова
+func (r *repositoryCore[T]) applyScopes(query *gorm.DB, opts []QueryOption) *gorm.DB {
+	if len(opts) == 0 {
+		return query
+	}
+
+	options := &queryOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if len(options.scopes) == 0 {
+		return query
+	}
+
+	return query.Scopes(options.scopes...)
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vladzorgan/common/auth"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type orderEntity struct {
+	ID     uint
+	UserID uint
+}
+
+func (orderEntity) GetID() uint          { return 0 }
+func (orderEntity) GetTableName() string { return "orders" }
+func (orderEntity) TableName() string    { return "orders" }
+
+// newOwnedRepository создает репозиторий с авторизацией поверх DryRun соединения,
+// запоминающего последний построенный SQL
+func newOwnedRepository(t *testing.T) (*BaseRepository[orderEntity], *string) {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	var sql string
+	db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		sql = tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)
+	})
+
+	repo := NewBaseRepositoryWithAuth[orderEntity](nil, &AuthConfig{
+		ResourceType: auth.ResourceTypeOrder,
+		OwnerField:   "user_id",
+		Enabled:      true,
+		ReadAuth:     true,
+	})
+	repo.tx = db
+	return repo, &sql
+}
+
+func TestCountAndExistsRespectOwnership(t *testing.T) {
+	admin := auth.WithUser(context.Background(), &auth.User{ID: 1, Role: auth.UserRole_Admin, IsActive: true})
+	user := auth.WithUser(context.Background(), &auth.User{ID: 7, Role: auth.UserRole_User, IsActive: true})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		call func(ctx context.Context, repo *BaseRepository[orderEntity]) error
+		want string
+	}{
+		{
+			name: "count as admin",
+			ctx:  admin,
+			call: func(ctx context.Context, repo *BaseRepository[orderEntity]) error {
+				_, err := repo.Count(ctx, map[string]interface{}{"status": "paid"})
+				return err
+			},
+			want: `SELECT count(*) FROM "orders" WHERE status = 'paid'`,
+		},
+		{
+			name: "count as user",
+			ctx:  user,
+			call: func(ctx context.Context, repo *BaseRepository[orderEntity]) error {
+				_, err := repo.Count(ctx, map[string]interface{}{"status": "paid"})
+				return err
+			},
+			want: `SELECT count(*) FROM "orders" WHERE user_id = 7 AND status = 'paid'`,
+		},
+		{
+			name: "exists as user",
+			ctx:  user,
+			call: func(ctx context.Context, repo *BaseRepository[orderEntity]) error {
+				_, err := repo.Exists(ctx, 3)
+				return err
+			},
+			want: `SELECT count(*) FROM "orders" WHERE user_id = 7 AND id = 3`,
+		},
+		{
+			name: "exists as admin",
+			ctx:  admin,
+			call: func(ctx context.Context, repo *BaseRepository[orderEntity]) error {
+				_, err := repo.Exists(ctx, 3)
+				return err
+			},
+			want: `SELECT count(*) FROM "orders" WHERE id = 3`,
+		},
+		{
+			name: "exists by field unscoped as user",
+			ctx:  user,
+			call: func(ctx context.Context, repo *BaseRepository[orderEntity]) error {
+				_, err := repo.ExistsByFieldUnscoped(ctx, "status", "new")
+				return err
+			},
+			want: `SELECT count(*) FROM "orders" WHERE status = 'new'`,
+		},
+		{
+			name: "count by field as user",
+			ctx:  user,
+			call: func(ctx context.Context, repo *BaseRepository[orderEntity]) error {
+				_, err := repo.CountByField(ctx, "status", "new")
+				return err
+			},
+			want: `SELECT count(*) FROM "orders" WHERE user_id = 7 AND status = 'new'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, sql := newOwnedRepository(t)
+			if err := tt.call(tt.ctx, repo); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *sql != tt.want {
+				t.Errorf("SQL = %s, want %s", *sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountAndExistsRequireAuthentication(t *testing.T) {
+	repo, sql := newOwnedRepository(t)
+	ctx := context.Background()
+
+	if _, err := repo.Count(ctx, nil); err == nil {
+		t.Error("Count() without user must fail")
+	}
+	if _, err := repo.Exists(ctx, 1); err == nil {
+		t.Error("Exists() without user must fail")
+	}
+	if _, err := repo.CountByField(ctx, "status", "new"); err == nil {
+		t.Error("CountByField() without user must fail")
+	}
+	if *sql != "" {
+		t.Errorf("query must not run without permission, got %s", *sql)
+	}
+
+	// Без ReadAuth неаутентифицированный запрос не видит записей владельцев
+	repo.authConfig.ReadAuth = false
+	if _, err := repo.Count(ctx, nil); err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if want := `SELECT count(*) FROM "orders" WHERE 1 = 0`; *sql != want {
+		t.Errorf("SQL = %s, want %s", *sql, want)
+	}
+}
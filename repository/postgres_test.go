@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"os"
+	"testing"
+
+	"github.com/vladzorgan/common/database"
+	"gorm.io/gorm/logger"
+)
+
+// postgresURLEnv задает Postgres для интеграционных тестов и бенчмарков репозитория;
+// без переменной они пропускаются
+const postgresURLEnv = "COMMON_IT_DATABASE_URL"
+
+// newPostgresDatabase подключается к Postgres из COMMON_IT_DATABASE_URL
+func newPostgresDatabase(tb testing.TB) *database.Database {
+	tb.Helper()
+
+	url := os.Getenv(postgresURLEnv)
+	if url == "" {
+		tb.Skipf("%s is not set", postgresURLEnv)
+	}
+
+	options := database.DefaultDatabaseOptions()
+	options.LogLevel = logger.Silent
+	db, err := database.NewDatabase(url, nil, options)
+	if err != nil {
+		tb.Fatalf("NewDatabase() error = %v", err)
+	}
+	tb.Cleanup(func() { db.Close() })
+	return db
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type preloadProduct struct {
+	ID   uint
+	Name string
+}
+
+func (preloadProduct) TableName() string { return "products" }
+
+type preloadItem struct {
+	ID        uint
+	OrderID   uint
+	ProductID uint
+	Product   preloadProduct
+}
+
+func (preloadItem) TableName() string { return "order_items" }
+
+type preloadOrder struct {
+	ID     uint
+	Number string
+	Items  []preloadItem `gorm:"foreignKey:OrderID"`
+}
+
+func (o preloadOrder) GetID() uint        { return o.ID }
+func (preloadOrder) GetTableName() string { return "orders" }
+func (preloadOrder) TableName() string    { return "orders" }
+
+// preloadStore тестовый драйвер заказов с позициями и товарами: записывает выполненные запросы
+type preloadStore struct {
+	mutex   sync.Mutex
+	queries []string
+}
+
+func (s *preloadStore) Connect(context.Context) (driver.Conn, error) {
+	return &preloadConn{store: s}, nil
+}
+func (s *preloadStore) Driver() driver.Driver { return nil }
+
+// tables возвращает таблицы выполненных запросов в порядке выполнения
+func (s *preloadStore) tables() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tables := make([]string, 0, len(s.queries))
+	for _, query := range s.queries {
+		for _, table := range []string{"orders", "order_items", "products"} {
+			if strings.Contains(query, `FROM "`+table+`"`) {
+				tables = append(tables, table)
+			}
+		}
+	}
+	return tables
+}
+
+type preloadConn struct {
+	store *preloadStore
+}
+
+func (c *preloadConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *preloadConn) Close() error                        { return nil }
+func (c *preloadConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *preloadConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.store.mutex.Lock()
+	c.store.queries = append(c.store.queries, query)
+	c.store.mutex.Unlock()
+
+	switch {
+	case strings.Contains(query, `FROM "orders"`):
+		return &tagRows{columns: []string{"id", "number"}, values: [][]driver.Value{{int64(1), "A-1"}}}, nil
+	case strings.Contains(query, `FROM "order_items"`):
+		return &tagRows{
+			columns: []string{"id", "order_id", "product_id"},
+			values:  [][]driver.Value{{int64(10), int64(1), int64(100)}, {int64(11), int64(1), int64(101)}},
+		}, nil
+	case strings.Contains(query, `FROM "products"`):
+		return &tagRows{values: [][]driver.Value{{int64(100), "pen"}, {int64(101), "ink"}}}, nil
+	default:
+		return nil, errors.New("unexpected query: " + query)
+	}
+}
+
+func newPreloadRepository(tb testing.TB, store *preloadStore) *BaseRepository[preloadOrder] {
+	tb.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(store)}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		tb.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	repo := NewBaseRepository[preloadOrder](nil)
+	repo.tx = db
+	return repo
+}
+
+func TestWithPreload(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults []string
+		opts     []QueryOption
+		tables   string
+		products bool
+	}{
+		{name: "without preloads", tables: "orders"},
+		{name: "items", opts: []QueryOption{WithPreload("Items")}, tables: "orders,order_items"},
+		{name: "nested", opts: []QueryOption{WithPreload("Items.Product")}, tables: "orders,order_items,products", products: true},
+		{name: "defaults", defaults: []string{"Items.Product"}, tables: "orders,order_items,products", products: true},
+		{
+			name:     "defaults merged with call",
+			defaults: []string{"Items"},
+			opts:     []QueryOption{WithPreload("Items", "Items.Product")},
+			tables:   "orders,order_items,products",
+			products: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &preloadStore{}
+			repo := newPreloadRepository(t, store)
+			if tt.defaults != nil {
+				repo.WithDefaultPreloads(tt.defaults...)
+			}
+
+			order, err := repo.GetByID(context.Background(), 1, tt.opts...)
+			if err != nil || order == nil {
+				t.Fatalf("GetByID() = %v, error = %v", order, err)
+			}
+			if got := strings.Join(store.tables(), ","); got != tt.tables {
+				t.Errorf("queried tables = %s, want %s", got, tt.tables)
+			}
+			if tt.tables != "orders" && len(order.Items) != 2 {
+				t.Fatalf("items = %d, want 2", len(order.Items))
+			}
+			if tt.products && (order.Items[0].Product.Name != "pen" || order.Items[1].Product.Name != "ink") {
+				t.Errorf("products = %+v, want pen, ink", order.Items)
+			}
+		})
+	}
+}
+
+// BenchmarkPreloadOptions сравнивает чтение без связей через репозиторий с прямым запросом gorm,
+// чтобы поддержка опций не добавляла накладных расходов вызовам без preload
+func BenchmarkPreloadOptions(b *testing.B) {
+	ctx := context.Background()
+
+	b.Run("gorm_first", func(b *testing.B) {
+		repo := newPreloadRepository(b, &preloadStore{})
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			var order preloadOrder
+			if err := repo.tx.WithContext(ctx).First(&order, 1).Error; err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	modes := []struct {
+		name string
+		opts []QueryOption
+	}{
+		{name: "get_by_id"},
+		{name: "get_by_id_empty_preload", opts: []QueryOption{WithPreload()}},
+		{name: "get_by_id_nested_preload", opts: []QueryOption{WithPreload("Items.Product")}},
+	}
+
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			repo := newPreloadRepository(b, &preloadStore{})
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.GetByID(ctx, 1, mode.opts...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"github.com/vladzorgan/common/auth"
 	"github.com/vladzorgan/common/database"
+	"github.com/vladzorgan/common/killswitch"
 	"gorm.io/gorm"
 )
 
@@ -13,10 +15,15 @@ type BaseModel interface {
 	GetTableName() string
 }
 
+// Owned представляет сущность с владельцем независимо от типа ее ID
+type Owned interface {
+	GetOwnerID() uint // Возвращает ID владельца сущности
+}
+
 // OwnableModel представляет модель с поддержкой владения
 type OwnableModel interface {
 	BaseModel
-	GetOwnerID() uint // Возвращает ID владельца сущности
+	Owned
 }
 
 // AuthConfig определяет настройки авторизации для репозитория
@@ -44,82 +51,133 @@ type BulkUpdateItem struct {
 type Repository[T BaseModel] interface {
 	// CRUD операции
 	Create(ctx context.Context, entity *T) error
-	GetByID(ctx context.Context, id uint) (*T, error)
+	GetByID(ctx context.Context, id uint, opts ...QueryOption) (*T, error)
+	GetByIDs(ctx context.Context, ids []uint, opts ...QueryOption) ([]T, error)
 	Update(ctx context.Context, id uint, updates map[string]interface{}) (*T, error)
 	Delete(ctx context.Context, id uint) (*T, error)
 	
 	// Массовые операции
 	BulkCreate(ctx context.Context, entities []*T) error
 	BulkUpdate(ctx context.Context, updates []BulkUpdateItem) error
+	Upsert(ctx context.Context, entity *T, conflictColumns []string, updateColumns []string) (bool, error)
+	BulkUpsert(ctx context.Context, entities []*T, conflictColumns []string, updateColumns []string) (*UpsertResult, error)
+	DeleteMany(ctx context.Context, ids []uint) (int64, error)
+	DeleteByFilter(ctx context.Context, filters map[string]interface{}, opts ...QueryOption) (int64, error)
 	
 	// Операции с коллекциями
-	GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *SortOptions) ([]T, int64, error)
-	Search(ctx context.Context, keyword string, skip, limit int, filters map[string]interface{}, sort *SortOptions) ([]T, int64, error)
-	GetByField(ctx context.Context, field string, value interface{}) (*T, error)
-	GetAllByField(ctx context.Context, field string, value interface{}, skip, limit int) ([]T, int64, error)
+	GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *SortOptions, opts ...QueryOption) ([]T, int64, error)
+	Search(ctx context.Context, keyword string, skip, limit int, filters map[string]interface{}, sort *SortOptions, opts ...QueryOption) ([]T, int64, error)
+	GetByField(ctx context.Context, field string, value interface{}, opts ...QueryOption) (*T, error)
+	GetOrCreate(ctx context.Context, field string, value interface{}, create func() *T) (*T, bool, error)
+	GetAllByField(ctx context.Context, field string, value interface{}, skip, limit int, opts ...QueryOption) ([]T, int64, error)
+	Stream(ctx context.Context, filters map[string]interface{}, sort *SortOptions, fn func(entity *T) error) error
+	StreamBatched(ctx context.Context, filters map[string]interface{}, sort *SortOptions, batchSize int) (<-chan T, <-chan error)
 	
 	// Дополнительные операции
-	Count(ctx context.Context, filters map[string]interface{}) (int64, error)
+	Count(ctx context.Context, filters map[string]interface{}, opts ...QueryOption) (int64, error)
+	CountByField(ctx context.Context, field string, value interface{}) (int64, error)
+	ExistsByField(ctx context.Context, field string, value interface{}) (bool, error)
+	ExistsByFieldUnscoped(ctx context.Context, field string, value interface{}) (bool, error)
 	Exists(ctx context.Context, id uint) (bool, error)
 	
 	// Работа с транзакциями
 	WithTx(tx *gorm.DB) Repository[T]
 }
 
+// repositoryCore содержит операции, не зависящие от типа ID; общая часть
+// BaseRepository и BaseUUIDRepository
+type repositoryCore[T any] struct {
+	db             *database.Database
+	tx             *gorm.DB
+	authConfig     *AuthConfig
+	tenantConfig   *TenantConfig
+	preloads       []string
+	searchFields   []string
+	searchMode     SearchMode
+	fullTextSearch *fullTextSearch
+}
+
 // BaseRepository представляет базовую реализацию репозитория
 type BaseRepository[T BaseModel] struct {
-	db         *database.Database
-	tx         *gorm.DB
-	authConfig *AuthConfig
+	repositoryCore[T]
+	archiveConfig  *ArchiveConfig
+	archiveMetrics *archiveMetrics
 }
 
 // NewBaseRepository создает новый экземпляр BaseRepository
 func NewBaseRepository[T BaseModel](db *database.Database) *BaseRepository[T] {
 	return &BaseRepository[T]{
-		db: db,
+		repositoryCore: repositoryCore[T]{db: db},
 	}
 }
 
 // NewBaseRepositoryWithAuth создает новый экземпляр BaseRepository с авторизацией
 func NewBaseRepositoryWithAuth[T BaseModel](db *database.Database, authConfig *AuthConfig) *BaseRepository[T] {
 	return &BaseRepository[T]{
-		db:         db,
-		authConfig: authConfig,
+		repositoryCore: repositoryCore[T]{db: db, authConfig: authConfig},
 	}
 }
 
-// getDB возвращает подключение к базе данных (обычное или транзакция)
-func (r *BaseRepository[T]) getDB() *gorm.DB {
+// getDB возвращает подключение к базе данных: транзакцию из контекста (database.WithTransaction,
+// database.RunInTransaction), затем транзакцию, заданную через WithTx, иначе primary
+func (r *repositoryCore[T]) getDB(ctx context.Context) *gorm.DB {
+	if tx, ok := database.TransactionFromContext(ctx); ok {
+		return tx
+	}
 	if r.tx != nil {
 		return r.tx
 	}
 	return r.db.GetDB()
 }
 
+// getReadDB возвращает соединение для чтения: транзакцию (см. getDB), если она есть, иначе реплику
+// (см. database.Database.Reader; database.ForcePrimary направляет чтение на primary)
+func (r *repositoryCore[T]) getReadDB(ctx context.Context) *gorm.DB {
+	if tx, ok := database.TransactionFromContext(ctx); ok {
+		return tx
+	}
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db.Reader(ctx)
+}
+
+// withTx возвращает копию настроек репозитория с транзакцией
+func (r *repositoryCore[T]) withTx(tx *gorm.DB) repositoryCore[T] {
+	core := *r
+	core.tx = tx
+	return core
+}
+
 // WithTx создает новый репозиторий с транзакцией
 func (r *BaseRepository[T]) WithTx(tx *gorm.DB) Repository[T] {
 	return &BaseRepository[T]{
-		db:         r.db,
-		tx:         tx,
-		authConfig: r.authConfig,
+		repositoryCore: r.withTx(tx),
+		archiveConfig:  r.archiveConfig,
+		archiveMetrics: r.archiveMetrics,
 	}
 }
 
 // Create создает новую запись в базе данных
-func (r *BaseRepository[T]) Create(ctx context.Context, entity *T) error {
+func (r *repositoryCore[T]) Create(ctx context.Context, entity *T) error {
 	// Проверяем разрешения на запись
 	if err := r.checkWritePermission(ctx); err != nil {
 		return err
 	}
 
-	if err := r.getDB().WithContext(ctx).Create(entity).Error; err != nil {
+	// Заполняем арендатора из контекста
+	if err := r.assignTenant(ctx, entity); err != nil {
+		return err
+	}
+
+	if err := r.getDB(ctx).WithContext(ctx).Create(entity).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
 // BulkCreate создает множество записей в базе данных
-func (r *BaseRepository[T]) BulkCreate(ctx context.Context, entities []*T) error {
+func (r *repositoryCore[T]) BulkCreate(ctx context.Context, entities []*T) error {
 	if len(entities) == 0 {
 		return nil
 	}
@@ -129,6 +187,11 @@ func (r *BaseRepository[T]) BulkCreate(ctx context.Context, entities []*T) error
 		return err
 	}
 
+	// Заполняем арендатора из контекста
+	if err := r.assignTenant(ctx, entities...); err != nil {
+		return err
+	}
+
 	// Используем пакетную вставку для лучшей производительности
 	batchSize := 100
 	for i := 0; i < len(entities); i += batchSize {
@@ -138,7 +201,7 @@ func (r *BaseRepository[T]) BulkCreate(ctx context.Context, entities []*T) error
 		}
 		
 		batch := entities[i:end]
-		if err := r.getDB().WithContext(ctx).Create(&batch).Error; err != nil {
+		if err := r.getDB(ctx).WithContext(ctx).Create(&batch).Error; err != nil {
 			return err
 		}
 	}
@@ -158,12 +221,16 @@ func (r *BaseRepository[T]) BulkUpdate(ctx context.Context, updates []BulkUpdate
 	}
 
 	// Выполняем обновления в транзакции для обеспечения консистентности
-	return r.getDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.getDB(ctx).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		for _, update := range updates {
 			if len(update.Updates) == 0 {
 				continue
 			}
 
+			if err := r.checkTenantUpdates(ctx, update.Updates); err != nil {
+				return err
+			}
+
 			var entity T
 			
 			// Применяем фильтр по владению
@@ -191,32 +258,82 @@ func (r *BaseRepository[T]) BulkUpdate(ctx context.Context, updates []BulkUpdate
 	})
 }
 
-// GetByID получает запись по ID
-func (r *BaseRepository[T]) GetByID(ctx context.Context, id uint) (*T, error) {
+// GetByID получает запись по ID.
+// Если для репозитория включено чтение из архива, отсутствующая запись ищется в архивной таблице
+// (отключается выключателем killswitch.FeatureArchiveReadThrough).
+func (r *BaseRepository[T]) GetByID(ctx context.Context, id uint, opts ...QueryOption) (*T, error) {
+	readThrough := r.archiveConfig != nil && r.archiveConfig.ReadThrough &&
+		!killswitch.IsDisabled(killswitch.FeatureArchiveReadThrough)
+	entity, _, err := r.getByID(ctx, id, readThrough, opts...)
+	return entity, err
+}
+
+// getByID получает запись по ID, при необходимости обращаясь к архиву.
+// Второе значение показывает, что запись найдена в архиве.
+func (r *BaseRepository[T]) getByID(ctx context.Context, id uint, readThrough bool, opts ...QueryOption) (*T, bool, error) {
 	// Проверяем разрешения на чтение
 	if err := r.checkReadPermission(ctx); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	var entity T
 	
-	query := r.getDB().WithContext(ctx)
+	query := r.getReadDB(ctx).WithContext(ctx)
 	// Применяем фильтр по владению если настроен
 	query = r.applyOwnershipFilter(ctx, query)
+	// Загружаем связанные сущности
+	query = r.applyPreloads(query, opts)
 	
 	if err := query.First(&entity, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, nil
+			if readThrough {
+				return r.getArchivedByID(ctx, id)
+			}
+			return nil, false, nil
 		}
-		return nil, err
+		return nil, false, err
 	}
 	
 	// Дополнительная проверка владения для конкретной записи
 	if err := r.checkOwnership(ctx, &entity); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	
-	return &entity, nil
+	return &entity, false, nil
+}
+
+// GetByIDs получает записи по списку ID одним запросом, упорядоченные по ID.
+// Отсутствующие и недоступные пользователю записи в результат не попадают.
+func (r *BaseRepository[T]) GetByIDs(ctx context.Context, ids []uint, opts ...QueryOption) ([]T, error) {
+	// Проверяем разрешения на чтение
+	if err := r.checkReadPermission(ctx); err != nil {
+		return nil, err
+	}
+
+	entities := make([]T, 0, len(ids))
+	if len(ids) == 0 {
+		return entities, nil
+	}
+
+	query := r.getReadDB(ctx).WithContext(ctx)
+	// Применяем фильтр по владению если настроен
+	query = r.applyOwnershipFilter(ctx, query)
+	// Загружаем связанные сущности
+	query = r.applyPreloads(query, opts)
+
+	if err := query.Where("id IN ?", ids).Order("id").Find(&entities).Error; err != nil {
+		return nil, err
+	}
+
+	// Исключаем записи, владение которыми не подтверждено
+	owned := entities[:0]
+	for i := range entities {
+		if err := r.checkOwnership(ctx, &entities[i]); err == nil {
+			owned = append(owned, entities[i])
+		}
+	}
+
+	return owned, nil
 }
 
 // Update обновляет запись по ID
@@ -226,9 +343,14 @@ func (r *BaseRepository[T]) Update(ctx context.Context, id uint, updates map[str
 		return nil, err
 	}
 
+	// Перенос записи к другому арендатору доступен только администраторам
+	if err := r.checkTenantUpdates(ctx, updates); err != nil {
+		return nil, err
+	}
+
 	var entity T
 	
-	query := r.getDB().WithContext(ctx)
+	query := r.getDB(ctx).WithContext(ctx)
 	// Применяем фильтр по владению
 	query = r.applyOwnershipFilter(ctx, query)
 	
@@ -246,12 +368,12 @@ func (r *BaseRepository[T]) Update(ctx context.Context, id uint, updates map[str
 	}
 	
 	// Обновляем запись
-	if err := r.getDB().WithContext(ctx).Model(&entity).Updates(updates).Error; err != nil {
+	if err := r.getDB(ctx).WithContext(ctx).Model(&entity).Updates(updates).Error; err != nil {
 		return nil, err
 	}
 	
 	// Получаем обновленную запись
-	if err := r.getDB().WithContext(ctx).First(&entity, id).Error; err != nil {
+	if err := r.getDB(ctx).WithContext(ctx).First(&entity, id).Error; err != nil {
 		return nil, err
 	}
 	
@@ -267,7 +389,7 @@ func (r *BaseRepository[T]) Delete(ctx context.Context, id uint) (*T, error) {
 
 	var entity T
 	
-	query := r.getDB().WithContext(ctx)
+	query := r.getDB(ctx).WithContext(ctx)
 	// Применяем фильтр по владению
 	query = r.applyOwnershipFilter(ctx, query)
 	
@@ -285,7 +407,7 @@ func (r *BaseRepository[T]) Delete(ctx context.Context, id uint) (*T, error) {
 	}
 	
 	// Удаляем запись
-	if err := r.getDB().WithContext(ctx).Delete(&entity).Error; err != nil {
+	if err := r.getDB(ctx).WithContext(ctx).Delete(&entity).Error; err != nil {
 		return nil, err
 	}
 	
@@ -293,13 +415,12 @@ func (r *BaseRepository[T]) Delete(ctx context.Context, id uint) (*T, error) {
 }
 
 // GetAll получает все записи с пагинацией, фильтрацией и сортировкой
-func (r *BaseRepository[T]) GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *SortOptions) ([]T, int64, error) {
+func (r *repositoryCore[T]) GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *SortOptions, opts ...QueryOption) ([]T, int64, error) {
 	var entities []T
-	var total int64
 	
 	// Создаем базовый запрос
-	query := r.getDB().WithContext(ctx).Model(new(T))
-	queryCount := r.getDB().WithContext(ctx).Model(new(T))
+	query := r.getReadDB(ctx).WithContext(ctx).Model(new(T))
+	queryCount := r.getReadDB(ctx).WithContext(ctx).Model(new(T))
 	
 	// Проверяем разрешения на чтение
 	if err := r.checkReadPermission(ctx); err != nil {
@@ -310,15 +431,19 @@ func (r *BaseRepository[T]) GetAll(ctx context.Context, skip, limit int, filters
 	query = r.applyOwnershipFilter(ctx, query)
 	queryCount = r.applyOwnershipFilter(ctx, queryCount)
 
-	// Применяем фильтры
-	query = r.applyFilters(query, filters)
-	queryCount = r.applyFilters(queryCount, filters)
+	// Применяем фильтры и условия Scope
+	query = r.applyScopes(r.applyFilters(query, filters), opts)
+	queryCount = r.applyScopes(r.applyFilters(queryCount, filters), opts)
 	
 	// Применяем сортировку
 	query = r.applySorting(query, sort)
 	
+	// Загружаем связанные сущности
+	query = r.applyPreloads(query, opts)
+	
 	// Получаем общее количество записей
-	if err := queryCount.Count(&total).Error; err != nil {
+	total, err := r.countTotal(queryCount, opts)
+	if err != nil {
 		return nil, 0, err
 	}
 	
@@ -334,17 +459,20 @@ func (r *BaseRepository[T]) GetAll(ctx context.Context, skip, limit int, filters
 }
 
 // Search выполняет поиск записей по ключевому слову с сортировкой
-func (r *BaseRepository[T]) Search(ctx context.Context, keyword string, skip, limit int, filters map[string]interface{}, sort *SortOptions) ([]T, int64, error) {
+func (r *repositoryCore[T]) Search(ctx context.Context, keyword string, skip, limit int, filters map[string]interface{}, sort *SortOptions, opts ...QueryOption) ([]T, int64, error) {
 	var entities []T
-	var total int64
 	
-	searchQuery := "%" + keyword + "%"
+	// Пустой запрос полнотекстового поиска возвращает все записи
+	if r.fullTextSearch != nil {
+		keyword = sanitizeFullTextKeyword(keyword)
+		if keyword == "" {
+			return r.GetAll(ctx, skip, limit, filters, sort, opts...)
+		}
+	}
 	
 	// Создаем базовый запрос с поиском
-	query := r.getDB().WithContext(ctx).Model(new(T)).
-		Where("name ILIKE ?", searchQuery)
-	queryCount := r.getDB().WithContext(ctx).Model(new(T)).
-		Where("name ILIKE ?", searchQuery)
+	query := r.applySearch(r.getReadDB(ctx).WithContext(ctx).Model(new(T)), keyword)
+	queryCount := r.applySearch(r.getReadDB(ctx).WithContext(ctx).Model(new(T)), keyword)
 	
 	// Проверяем разрешения на чтение
 	if err := r.checkReadPermission(ctx); err != nil {
@@ -355,15 +483,19 @@ func (r *BaseRepository[T]) Search(ctx context.Context, keyword string, skip, li
 	query = r.applyOwnershipFilter(ctx, query)
 	queryCount = r.applyOwnershipFilter(ctx, queryCount)
 
-	// Применяем дополнительные фильтры
-	query = r.applyFilters(query, filters)
-	queryCount = r.applyFilters(queryCount, filters)
+	// Применяем дополнительные фильтры и условия Scope
+	query = r.applyScopes(r.applyFilters(query, filters), opts)
+	queryCount = r.applyScopes(r.applyFilters(queryCount, filters), opts)
 	
 	// Применяем сортировку
-	query = r.applySorting(query, sort)
+	query = r.applySearchSorting(query, keyword, sort)
+	
+	// Загружаем связанные сущности
+	query = r.applyPreloads(query, opts)
 	
 	// Получаем общее количество найденных записей
-	if err := queryCount.Count(&total).Error; err != nil {
+	total, err := r.countTotal(queryCount, opts)
+	if err != nil {
 		return nil, 0, err
 	}
 	
@@ -378,12 +510,54 @@ func (r *BaseRepository[T]) Search(ctx context.Context, keyword string, skip, li
 	return entities, total, nil
 }
 
-// Count подсчитывает количество записей с фильтрами
-func (r *BaseRepository[T]) Count(ctx context.Context, filters map[string]interface{}) (int64, error) {
+// Stream последовательно передает в fn все записи, соответствующие фильтрам, не загружая их в память целиком.
+// Обход прекращается при первой ошибке fn или отмене контекста.
+func (r *repositoryCore[T]) Stream(ctx context.Context, filters map[string]interface{}, sort *SortOptions, fn func(entity *T) error) error {
+	// Проверяем разрешения на чтение
+	if err := r.checkReadPermission(ctx); err != nil {
+		return err
+	}
+
+	query := r.getReadDB(ctx).WithContext(ctx).Model(new(T))
+	query = r.applyOwnershipFilter(ctx, query)
+	query = r.applyFilters(query, filters)
+	query = r.applySorting(query, sort)
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	db := r.getReadDB(ctx).WithContext(ctx)
+	for rows.Next() {
+		var entity T
+		if err := db.ScanRows(rows, &entity); err != nil {
+			return err
+		}
+
+		if err := fn(&entity); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Count подсчитывает количество записей с фильтрами и условиями Scope.
+// Учитывает права на чтение и фильтр по владению так же, как GetAll.
+func (r *repositoryCore[T]) Count(ctx context.Context, filters map[string]interface{}, opts ...QueryOption) (int64, error) {
 	var count int64
 	
-	query := r.getDB().WithContext(ctx).Model(new(T))
+	// Проверяем разрешения на чтение
+	if err := r.checkReadPermission(ctx); err != nil {
+		return 0, err
+	}
+	
+	query := r.getReadDB(ctx).WithContext(ctx).Model(new(T))
+	query = r.applyOwnershipFilter(ctx, query)
 	query = r.applyFilters(query, filters)
+	query = r.applyScopes(query, opts)
 	
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
@@ -392,25 +566,107 @@ func (r *BaseRepository[T]) Count(ctx context.Context, filters map[string]interf
 	return count, nil
 }
 
-// Exists проверяет существование записи по ID
+// CountByField подсчитывает количество записей с указанным значением поля
+func (r *repositoryCore[T]) CountByField(ctx context.Context, field string, value interface{}) (int64, error) {
+	var count int64
+	
+	// Проверяем разрешения на чтение
+	if err := r.checkReadPermission(ctx); err != nil {
+		return 0, err
+	}
+	
+	query := r.getReadDB(ctx).WithContext(ctx).Model(new(T))
+	query = r.applyOwnershipFilter(ctx, query)
+	
+	if err := query.Where(field+" = ?", value).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	
+	return count, nil
+}
+
+// ExistsByField проверяет существование записи с указанным значением поля
+// (с теми же фильтрами по владению, что и CountByField)
+func (r *repositoryCore[T]) ExistsByField(ctx context.Context, field string, value interface{}) (bool, error) {
+	count, err := r.CountByField(ctx, field, value)
+	if err != nil {
+		return false, err
+	}
+	
+	return count > 0, nil
+}
+
+// ExistsByFieldUnscoped проверяет существование записи с указанным значением поля среди всех записей
+// таблицы: без фильтров по владению и арендатору и с удаленными (soft delete) записями.
+// Используется для проверки уникальности значения перед вставкой, которую проверяет уникальный индекс.
+func (r *repositoryCore[T]) ExistsByFieldUnscoped(ctx context.Context, field string, value interface{}) (bool, error) {
+	var count int64
+
+	// Проверяем разрешения на чтение
+	if err := r.checkReadPermission(ctx); err != nil {
+		return false, err
+	}
+
+	query := r.getReadDB(ctx).WithContext(ctx).Unscoped().Model(new(T))
+	if err := query.Where(field+" = ?", value).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Exists проверяет существование записи по ID.
+// Чужие записи для обычного пользователя считаются несуществующими.
 func (r *BaseRepository[T]) Exists(ctx context.Context, id uint) (bool, error) {
 	var count int64
 	
-	if err := r.getDB().WithContext(ctx).
-		Model(new(T)).
-		Where("id = ?", id).
-		Count(&count).Error; err != nil {
+	// Проверяем разрешения на чтение
+	if err := r.checkReadPermission(ctx); err != nil {
+		return false, err
+	}
+	
+	query := r.getReadDB(ctx).WithContext(ctx).Model(new(T))
+	query = r.applyOwnershipFilter(ctx, query)
+	
+	if err := query.Where("id = ?", id).Count(&count).Error; err != nil {
 		return false, err
 	}
 	
 	return count > 0, nil
 }
 
+// Raw выполняет произвольный SQL запрос и возвращает строки как сущности.
+// Запрос выполняется в транзакции репозитория (WithTx), если она задана. Права на чтение
+// проверяются, но фильтры по владению и арендатору к SQL не применяются: условия нужно
+// добавить в запрос самостоятельно или использовать GetAll с опцией Scope.
+func (r *repositoryCore[T]) Raw(ctx context.Context, query string, args ...interface{}) ([]T, error) {
+	var entities []T
+	if err := r.RawScan(ctx, &entities, query, args...); err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+// RawScan выполняет произвольный SQL запрос и сканирует результат в dest
+// (структуру, срез структур или map), например, для отчетов с агрегатами.
+// Ограничения те же, что у Raw.
+func (r *repositoryCore[T]) RawScan(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	// Проверяем разрешения на чтение
+	if err := r.checkReadPermission(ctx); err != nil {
+		return err
+	}
+
+	return r.getDB(ctx).WithContext(ctx).Raw(query, args...).Scan(dest).Error
+}
+
 // GetByField получает запись по указанному полю
-func (r *BaseRepository[T]) GetByField(ctx context.Context, field string, value interface{}) (*T, error) {
+func (r *repositoryCore[T]) GetByField(ctx context.Context, field string, value interface{}, opts ...QueryOption) (*T, error) {
 	var entity T
 	
-	if err := r.getDB().WithContext(ctx).Where(field+" = ?", value).First(&entity).Error; err != nil {
+	query := r.applyTenantFilter(ctx, r.getReadDB(ctx).WithContext(ctx))
+	query = r.applyPreloads(query, opts)
+	if err := query.Where(field+" = ?", value).First(&entity).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
@@ -420,17 +676,55 @@ func (r *BaseRepository[T]) GetByField(ctx context.Context, field string, value
 	return &entity, nil
 }
 
+// GetOrCreate возвращает запись с указанным значением поля или создает ее из create.
+// Второе значение равно true, если запись создана. Поле должно быть защищено уникальным индексом:
+// если запись создана параллельно между чтением и вставкой, ошибка уникальности обрабатывается
+// повторным чтением. Вставка выполняется в savepoint, поэтому ошибка не прерывает транзакцию репозитория.
+func (r *repositoryCore[T]) GetOrCreate(ctx context.Context, field string, value interface{}, create func() *T) (*T, bool, error) {
+	// Читаем с primary, чтобы отставание реплики не приводило к попытке повторного создания
+	ctx = database.ForcePrimary(ctx)
+	
+	existing, err := r.GetByField(ctx, field, value)
+	if err != nil || existing != nil {
+		return existing, false, err
+	}
+	
+	entity := create()
+	err = r.getDB(ctx).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return r.Create(database.WithTransaction(ctx, tx), entity)
+	})
+	if err == nil {
+		return entity, true, nil
+	}
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, false, err
+	}
+	
+	// Запись создана параллельно; если ее нет, уникальность нарушена по другому полю
+	existing, fetchErr := r.GetByField(ctx, field, value)
+	if fetchErr != nil {
+		return nil, false, fetchErr
+	}
+	if existing == nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
 // GetAllByField получает все записи по указанному полю с пагинацией
-func (r *BaseRepository[T]) GetAllByField(ctx context.Context, field string, value interface{}, skip, limit int) ([]T, int64, error) {
+func (r *repositoryCore[T]) GetAllByField(ctx context.Context, field string, value interface{}, skip, limit int, opts ...QueryOption) ([]T, int64, error) {
 	var entities []T
-	var total int64
 	
 	// Создаем базовый запрос
-	query := r.getDB().WithContext(ctx).Model(new(T)).Where(field+" = ?", value)
-	queryCount := r.getDB().WithContext(ctx).Model(new(T)).Where(field+" = ?", value)
+	query := r.applyScopes(r.applyTenantFilter(ctx, r.getReadDB(ctx).WithContext(ctx).Model(new(T))).Where(field+" = ?", value), opts)
+	queryCount := r.applyScopes(r.applyTenantFilter(ctx, r.getReadDB(ctx).WithContext(ctx).Model(new(T))).Where(field+" = ?", value), opts)
+	
+	// Загружаем связанные сущности
+	query = r.applyPreloads(query, opts)
 	
 	// Получаем общее количество записей
-	if err := queryCount.Count(&total).Error; err != nil {
+	total, err := r.countTotal(queryCount, opts)
+	if err != nil {
 		return nil, 0, err
 	}
 	
@@ -446,7 +740,7 @@ func (r *BaseRepository[T]) GetAllByField(ctx context.Context, field string, val
 }
 
 // applyFilters применяет фильтры к запросу
-func (r *BaseRepository[T]) applyFilters(query *gorm.DB, filters map[string]interface{}) *gorm.DB {
+func (r *repositoryCore[T]) applyFilters(query *gorm.DB, filters map[string]interface{}) *gorm.DB {
 	for key, value := range filters {
 		if value != nil && value != "" {
 			switch key {
@@ -476,12 +770,21 @@ func (r *BaseRepository[T]) applyFilters(query *gorm.DB, filters map[string]inte
 }
 
 // applySorting применяет сортировку к запросу
-func (r *BaseRepository[T]) applySorting(query *gorm.DB, sort *SortOptions) *gorm.DB {
+func (r *repositoryCore[T]) applySorting(query *gorm.DB, sort *SortOptions) *gorm.DB {
+	field, desc := sortColumn(sort)
+	if desc {
+		return query.Order(field + " DESC")
+	}
+	return query.Order(field + " ASC")
+}
+
+// sortColumn возвращает разрешенное поле сортировки и ее направление.
+// Недопустимые поля заменяются сортировкой по умолчанию - по ID в порядке возрастания.
+func sortColumn(sort *SortOptions) (string, bool) {
 	if sort == nil || sort.Field == "" {
-		// Сортировка по умолчанию - по ID в порядке возрастания
-		return query.Order("id ASC")
+		return "id", false
 	}
-	
+
 	// Определяем допустимые поля для сортировки
 	allowedFields := map[string]bool{
 		"id":         true,
@@ -489,24 +792,17 @@ func (r *BaseRepository[T]) applySorting(query *gorm.DB, sort *SortOptions) *gor
 		"created_at": true,
 		"updated_at": true,
 	}
-	
+
 	// Проверяем, что поле разрешено для сортировки
 	if !allowedFields[sort.Field] {
-		// Если поле не разрешено, используем сортировку по умолчанию
-		return query.Order("id ASC")
+		return "id", false
 	}
-	
-	// Определяем порядок сортировки
-	order := "ASC"
-	if sort.Order == "desc" || sort.Order == "DESC" {
-		order = "DESC"
-	}
-	
-	return query.Order(sort.Field + " " + order)
+
+	return sort.Field, sort.Order == "desc" || sort.Order == "DESC"
 }
 
 // checkReadPermission проверяет разрешения на чтение
-func (r *BaseRepository[T]) checkReadPermission(ctx context.Context) error {
+func (r *repositoryCore[T]) checkReadPermission(ctx context.Context) error {
 	if r.authConfig == nil || !r.authConfig.Enabled || !r.authConfig.ReadAuth {
 		return nil
 	}
@@ -521,7 +817,7 @@ func (r *BaseRepository[T]) checkReadPermission(ctx context.Context) error {
 }
 
 // checkWritePermission проверяет разрешения на запись
-func (r *BaseRepository[T]) checkWritePermission(ctx context.Context) error {
+func (r *repositoryCore[T]) checkWritePermission(ctx context.Context) error {
 	if r.authConfig == nil || !r.authConfig.Enabled || !r.authConfig.WriteAuth {
 		return nil
 	}
@@ -536,13 +832,13 @@ func (r *BaseRepository[T]) checkWritePermission(ctx context.Context) error {
 }
 
 // checkOwnership проверяет права владения для конкретной сущности
-func (r *BaseRepository[T]) checkOwnership(ctx context.Context, entity *T) error {
+func (r *repositoryCore[T]) checkOwnership(ctx context.Context, entity *T) error {
 	if r.authConfig == nil || !r.authConfig.Enabled || r.authConfig.OwnerField == "" {
 		return nil
 	}
 
-	// Проверяем, реализует ли сущность интерфейс OwnableModel
-	if ownableEntity, ok := any(*entity).(OwnableModel); ok {
+	// Проверяем, реализует ли сущность интерфейс Owned
+	if ownableEntity, ok := any(*entity).(Owned); ok {
 		ownerID := ownableEntity.GetOwnerID()
 		return auth.CheckOwnership(ctx, ownerID)
 	}
@@ -550,8 +846,10 @@ func (r *BaseRepository[T]) checkOwnership(ctx context.Context, entity *T) error
 	return nil
 }
 
-// applyOwnershipFilter применяет фильтр по владению для обычных пользователей
-func (r *BaseRepository[T]) applyOwnershipFilter(ctx context.Context, query *gorm.DB) *gorm.DB {
+// applyOwnershipFilter применяет фильтр по арендатору (см. WithTenant) и фильтр по владению для обычных пользователей
+func (r *repositoryCore[T]) applyOwnershipFilter(ctx context.Context, query *gorm.DB) *gorm.DB {
+	query = r.applyTenantFilter(ctx, query)
+
 	if r.authConfig == nil || !r.authConfig.Enabled || r.authConfig.OwnerField == "" {
 		return query
 	}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/vladzorgan/common/auth"
+	"gorm.io/gorm"
+)
+
+func paidScope(db *gorm.DB) *gorm.DB {
+	return db.Joins("JOIN payments ON payments.order_id = orders.id").Where("payments.status = ?", "paid")
+}
+
+func TestScopeComposesWithFiltersAndOwnership(t *testing.T) {
+	user := auth.WithUser(context.Background(), &auth.User{ID: 7, Role: auth.UserRole_User, IsActive: true})
+
+	repo, sql := newOwnedRepository(t)
+	if _, err := repo.Count(user, map[string]interface{}{"status": "new"}, Scope(paidScope)); err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	want := `SELECT count(*) FROM "orders" JOIN payments ON payments.order_id = orders.id WHERE user_id = 7 AND status = 'new' AND payments.status = 'paid'`
+	if *sql != want {
+		t.Errorf("Count() SQL = %s, want %s", *sql, want)
+	}
+
+	if _, _, err := repo.GetAll(user, 0, 10, map[string]interface{}{"status": "new"}, nil, Scope(paidScope)); err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	for _, part := range []string{"JOIN payments", "user_id = 7", "status = 'new'", "payments.status = 'paid'", "LIMIT 10"} {
+		if !strings.Contains(*sql, part) {
+			t.Errorf("GetAll() SQL = %s, missing %s", *sql, part)
+		}
+	}
+}
+
+func TestRawRunsInRepositoryTransaction(t *testing.T) {
+	repo, _ := newOwnedRepository(t)
+
+	var sql string
+	repo.tx.Callback().Row().After("gorm:row").Register("test:capture_row", func(tx *gorm.DB) {
+		sql = tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)
+	})
+
+	admin := auth.WithUser(context.Background(), &auth.User{ID: 1, Role: auth.UserRole_Admin, IsActive: true})
+	_, err := repo.Raw(admin, "SELECT orders.* FROM orders JOIN payments ON payments.order_id = orders.id WHERE payments.amount > ?", 100)
+	// DryRun соединение строит SQL, но не возвращает строк
+	if err != nil && !errors.Is(err, gorm.ErrDryRunModeUnsupported) {
+		t.Fatalf("Raw() error = %v", err)
+	}
+	if !strings.Contains(sql, "payments.amount > 100") {
+		t.Errorf("Raw() SQL = %q", sql)
+	}
+
+	var totals []struct {
+		UserID uint
+		Total  int64
+	}
+	sql = ""
+	if err := repo.RawScan(context.Background(), &totals, "SELECT user_id, count(*) AS total FROM orders GROUP BY user_id"); err == nil || sql != "" {
+		t.Errorf("RawScan() without user must fail before running SQL, err = %v, SQL = %q", err, sql)
+	}
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/vladzorgan/common/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SearchMode определяет способ сопоставления ключевого слова в Search
+type SearchMode int
+
+const (
+	// SearchContains ищет ключевое слово в любой части значения (ILIKE '%keyword%')
+	SearchContains SearchMode = iota
+	// SearchPrefix ищет значения, начинающиеся с ключевого слова (ILIKE 'keyword%').
+	// Такой запрос может использовать trigram и btree (text_pattern_ops) индексы.
+	SearchPrefix
+)
+
+// defaultSearchFields поля поиска, если они не заданы через WithSearchFields
+var defaultSearchFields = []string{"name"}
+
+// maxFullTextKeywordLength ограничивает длину ключевого слова полнотекстового поиска
+const maxFullTextKeywordLength = 256
+
+// fullTextSearch содержит настройки полнотекстового поиска
+type fullTextSearch struct {
+	// vector выражение to_tsvector, совпадающее с выражением индекса
+	vector string
+	// query выражение plainto_tsquery с плейсхолдером для ключевого слова
+	query string
+}
+
+// likeEscaper экранирует спецсимволы шаблона LIKE в ключевом слове
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// WithSearchFields задает колонки, по которым Search ищет ключевое слово (через OR).
+// Без настройки поиск выполняется по колонке name.
+func (r *BaseRepository[T]) WithSearchFields(fields ...string) *BaseRepository[T] {
+	r.searchFields = append([]string(nil), fields...)
+	return r
+}
+
+// WithSearchMode задает способ сопоставления ключевого слова в Search
+func (r *BaseRepository[T]) WithSearchMode(mode SearchMode) *BaseRepository[T] {
+	r.searchMode = mode
+	return r
+}
+
+// WithFullTextSearch переключает Search на полнотекстовый поиск Postgres по колонке:
+// to_tsvector(config, column) @@ plainto_tsquery(config, keyword). Без явной сортировки
+// результаты упорядочиваются по ts_rank. Индекс создается database.EnsureFullTextIndex.
+func (r *BaseRepository[T]) WithFullTextSearch(column, config string) *BaseRepository[T] {
+	r.fullTextSearch = newFullTextSearch(column, config)
+	return r
+}
+
+// newFullTextSearch создает условие полнотекстового поиска по колонке
+func newFullTextSearch(column, config string) *fullTextSearch {
+	if config == "" {
+		config = database.DefaultFullTextConfig
+	}
+
+	return &fullTextSearch{
+		vector: database.FullTextVector(config, column),
+		query:  database.FullTextQuery(config),
+	}
+}
+
+// sanitizeFullTextKeyword удаляет управляющие символы, схлопывает пробелы и ограничивает длину
+func sanitizeFullTextKeyword(keyword string) string {
+	keyword = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, keyword)
+	keyword = strings.Join(strings.Fields(keyword), " ")
+
+	if runes := []rune(keyword); len(runes) > maxFullTextKeywordLength {
+		keyword = strings.TrimSpace(string(runes[:maxFullTextKeywordLength]))
+	}
+	return keyword
+}
+
+// searchPattern возвращает шаблон ILIKE для ключевого слова с экранированными % и _
+func searchPattern(keyword string, mode SearchMode) string {
+	escaped := likeEscaper.Replace(keyword)
+	if mode == SearchPrefix {
+		return escaped + "%"
+	}
+	return "%" + escaped + "%"
+}
+
+// applySearch добавляет в запрос условие поиска ключевого слова по настроенным колонкам
+// или условие полнотекстового поиска
+func (r *repositoryCore[T]) applySearch(query *gorm.DB, keyword string) *gorm.DB {
+	if r.fullTextSearch != nil {
+		return query.Where(r.fullTextSearch.vector+" @@ "+r.fullTextSearch.query, keyword)
+	}
+
+	fields := r.searchFields
+	if len(fields) == 0 {
+		fields = defaultSearchFields
+	}
+
+	pattern := searchPattern(keyword, r.searchMode)
+	conditions := make([]string, 0, len(fields))
+	args := make([]interface{}, 0, len(fields))
+	for _, field := range fields {
+		conditions = append(conditions, query.Statement.Quote(field)+" ILIKE ?")
+		args = append(args, pattern)
+	}
+
+	return query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// applySearchSorting применяет сортировку результатов поиска. При полнотекстовом поиске
+// без явной сортировки результаты упорядочиваются по релевантности.
+func (r *repositoryCore[T]) applySearchSorting(query *gorm.DB, keyword string, sort *SortOptions) *gorm.DB {
+	if r.fullTextSearch == nil || (sort != nil && sort.Field != "") {
+		return r.applySorting(query, sort)
+	}
+
+	return query.Clauses(clause.OrderBy{Expression: clause.Expr{
+		SQL:  "ts_rank(" + r.fullTextSearch.vector + ", " + r.fullTextSearch.query + ") DESC, id ASC",
+		Vars: []interface{}{keyword},
+	}})
+}
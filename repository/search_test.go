@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type searchEntity struct {
+	ID    uint
+	Email string
+	Phone string
+}
+
+func (searchEntity) GetID() uint          { return 0 }
+func (searchEntity) GetTableName() string { return "customers" }
+func (searchEntity) TableName() string    { return "customers" }
+
+func TestSearchPattern(t *testing.T) {
+	tests := []struct {
+		keyword string
+		mode    SearchMode
+		want    string
+	}{
+		{"ivan", SearchContains, "%ivan%"},
+		{"ivan", SearchPrefix, "ivan%"},
+		{"100%", SearchContains, `%100\%%`},
+		{"first_name", SearchPrefix, `first\_name%`},
+		{`C:\temp`, SearchContains, `%C:\\temp%`},
+	}
+
+	for _, tt := range tests {
+		if got := searchPattern(tt.keyword, tt.mode); got != tt.want {
+			t.Errorf("searchPattern(%q, %d) = %q, want %q", tt.keyword, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestApplySearch(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		repo *BaseRepository[searchEntity]
+		want string
+	}{
+		{
+			name: "default name column",
+			repo: &BaseRepository[searchEntity]{},
+			want: `SELECT * FROM "customers" WHERE ("name" ILIKE '%ivan%')`,
+		},
+		{
+			name: "configured fields with prefix mode",
+			repo: (&BaseRepository[searchEntity]{}).WithSearchFields("email", "phone").WithSearchMode(SearchPrefix),
+			want: `SELECT * FROM "customers" WHERE ("email" ILIKE 'ivan%' OR "phone" ILIKE 'ivan%')`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var entities []searchEntity
+				return tt.repo.applySearch(tx.Model(&searchEntity{}), "ivan").Find(&entities)
+			})
+			if got != tt.want {
+				t.Errorf("SQL = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFullTextSearchSQL(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	repo := (&BaseRepository[searchEntity]{}).WithFullTextSearch("email", "russian")
+
+	tests := []struct {
+		name string
+		sort *SortOptions
+		want string
+	}{
+		{
+			name: "ranked by default",
+			want: `SELECT * FROM "customers" WHERE to_tsvector('russian', "email") @@ plainto_tsquery('russian', 'ivan petrov') ` +
+				`ORDER BY ts_rank(to_tsvector('russian', "email"), plainto_tsquery('russian', 'ivan petrov')) DESC, id ASC`,
+		},
+		{
+			name: "explicit sort",
+			sort: &SortOptions{Field: "created_at", Order: "desc"},
+			want: `SELECT * FROM "customers" WHERE to_tsvector('russian', "email") @@ plainto_tsquery('russian', 'ivan petrov') ORDER BY created_at DESC`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var entities []searchEntity
+				query := repo.applySearch(tx.Model(&searchEntity{}), "ivan petrov")
+				return repo.applySearchSorting(query, "ivan petrov", tt.sort).Find(&entities)
+			})
+			if got != tt.want {
+				t.Errorf("SQL = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeFullTextKeyword(t *testing.T) {
+	tests := []struct {
+		keyword string
+		want    string
+	}{
+		{"  ivan   petrov ", "ivan petrov"},
+		{"ivan\x00\tpetrov\n", "ivan petrov"},
+		{" \t\n", ""},
+		{strings.Repeat("я", 300), strings.Repeat("я", maxFullTextKeywordLength)},
+	}
+
+	for _, tt := range tests {
+		if got := sanitizeFullTextKeyword(tt.keyword); got != tt.want {
+			t.Errorf("sanitizeFullTextKeyword(%q) = %q, want %q", tt.keyword, got, tt.want)
+		}
+	}
+}
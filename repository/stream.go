@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// DefaultStreamBatchSize размер пакета StreamBatched по умолчанию
+const DefaultStreamBatchSize = 500
+
+// StreamBatched передает записи, соответствующие фильтрам, через канал, читая их из базы пакетами
+// по batchSize записей (0 - DefaultStreamBatchSize). Пакеты выбираются по ключу (сортировка + id)
+// без OFFSET и COUNT, поэтому каждый запрос короткий и соединение не удерживается на весь экспорт.
+//
+// Канал записей буферизован на один пакет; если потребитель не успевает, чтение из базы
+// приостанавливается. После закрытия канала записей из канала ошибок можно прочитать не более
+// одной ошибки (в том числе ctx.Err() при отмене), затем он закрывается.
+// Записи с NULL в поле сортировки передаются после остальных (NULLS LAST при любом направлении).
+func (r *repositoryCore[T]) StreamBatched(ctx context.Context, filters map[string]interface{}, sort *SortOptions, batchSize int) (<-chan T, <-chan error) {
+	if batchSize <= 0 {
+		batchSize = DefaultStreamBatchSize
+	}
+
+	entities := make(chan T, batchSize)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(entities)
+
+		if err := r.streamBatches(ctx, filters, sort, batchSize, func(batch []T) error {
+			for i := range batch {
+				select {
+				case entities <- batch[i]:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		}); err != nil {
+			errs <- err
+		}
+	}()
+
+	return entities, errs
+}
+
+// streamBatches последовательно выбирает пакеты записей по ключу и передает их в fn.
+// При сортировке не по id сначала обходятся записи с заданным полем сортировки, затем,
+// если колонка допускает NULL, записи с NULL по id: сравнение кортежей с NULL не работает.
+func (r *repositoryCore[T]) streamBatches(ctx context.Context, filters map[string]interface{}, sort *SortOptions, batchSize int, fn func(batch []T) error) error {
+	// Проверяем разрешения на чтение
+	if err := r.checkReadPermission(ctx); err != nil {
+		return err
+	}
+
+	field, desc := sortColumn(sort)
+	keyFields, err := r.keysetFields(ctx, field)
+	if err != nil {
+		return err
+	}
+
+	if field == "id" {
+		return r.streamPhase(ctx, filters, keysetPhase{fields: keyFields, desc: desc}, batchSize, fn)
+	}
+
+	phase := keysetPhase{condition: field + " IS NOT NULL", columns: []string{field}, fields: keyFields, desc: desc}
+	if err := r.streamPhase(ctx, filters, phase, batchSize, fn); err != nil {
+		return err
+	}
+	if keyFields[0].NotNull {
+		return nil
+	}
+
+	phase = keysetPhase{condition: field + " IS NULL", fields: keyFields[1:], desc: desc}
+	return r.streamPhase(ctx, filters, phase, batchSize, fn)
+}
+
+// keysetPhase описывает обход части записей по ключу: условие части, колонки ключа перед id
+// и поля модели, из которых берутся значения ключа последней записи пакета
+type keysetPhase struct {
+	condition string
+	columns   []string
+	fields    []*schema.Field
+	desc      bool
+}
+
+// streamPhase выбирает пакеты записей одной части по ключу (columns + id) и передает их в fn
+func (r *repositoryCore[T]) streamPhase(ctx context.Context, filters map[string]interface{}, phase keysetPhase, batchSize int, fn func(batch []T) error) error {
+	order, compare := "ASC", ">"
+	if phase.desc {
+		order, compare = "DESC", "<"
+	}
+
+	columns := append(append([]string(nil), phase.columns...), "id")
+	keyCondition := columns[0] + " " + compare + " ?"
+	if len(columns) > 1 {
+		keyCondition = fmt.Sprintf("(%s) %s (?%s)", strings.Join(columns, ", "), compare, strings.Repeat(", ?", len(columns)-1))
+	}
+
+	var lastKey []interface{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		query := r.getReadDB(ctx).WithContext(ctx)
+		query = r.applyOwnershipFilter(ctx, query)
+		query = r.applyFilters(query, filters)
+		if phase.condition != "" {
+			query = query.Where(phase.condition)
+		}
+		if lastKey != nil {
+			query = query.Where(keyCondition, lastKey...)
+		}
+		for _, column := range columns {
+			query = query.Order(column + " " + order)
+		}
+
+		batch := make([]T, 0, batchSize)
+		if err := query.Limit(batchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+
+		last := reflect.ValueOf(&batch[len(batch)-1]).Elem()
+		lastKey = make([]interface{}, len(phase.fields))
+		for i, keyField := range phase.fields {
+			lastKey[i], _ = keyField.ValueOf(ctx, last)
+		}
+	}
+}
+
+// keysetFields возвращает поля модели, образующие ключ итерации: поле сортировки и id
+func (r *repositoryCore[T]) keysetFields(ctx context.Context, field string) ([]*schema.Field, error) {
+	stmt := &gorm.Statement{DB: r.getDB(ctx)}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+
+	columns := []string{"id"}
+	if field != "id" {
+		columns = []string{field, "id"}
+	}
+
+	fields := make([]*schema.Field, 0, len(columns))
+	for _, column := range columns {
+		schemaField := stmt.Schema.LookUpField(column)
+		if schemaField == nil {
+			return nil, fmt.Errorf("model %s has no column %s for keyset iteration", stmt.Schema.Name, column)
+		}
+		fields = append(fields, schemaField)
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vladzorgan/common/auth"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// pagedRepository возвращает на запросы пакетов заранее заданные страницы и запоминает SQL
+func pagedRepository(t *testing.T, pages [][]orderEntity, failPage int) (*BaseRepository[orderEntity], *[]string) {
+	t.Helper()
+
+	repo, _ := newOwnedRepository(t)
+	var queries []string
+	repo.tx.Callback().Query().After("gorm:query").Register("test:pages", func(tx *gorm.DB) {
+		queries = append(queries, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+		page := len(queries) - 1
+		if page == failPage {
+			tx.AddError(errors.New("connection reset"))
+			return
+		}
+		if page < len(pages) {
+			*tx.Statement.Dest.(*[]orderEntity) = append([]orderEntity(nil), pages[page]...)
+		}
+	})
+	return repo, &queries
+}
+
+func adminContext() context.Context {
+	return auth.WithUser(context.Background(), &auth.User{ID: 1, Role: auth.UserRole_Admin, IsActive: true})
+}
+
+func TestStreamBatchedUsesKeysetPagination(t *testing.T) {
+	pages := [][]orderEntity{{{ID: 1}, {ID: 2}}, {{ID: 3}, {ID: 4}}, {{ID: 5}}}
+	repo, queries := pagedRepository(t, pages, -1)
+
+	entities, errs := repo.StreamBatched(adminContext(), nil, nil, 2)
+	var ids []uint
+	for entity := range entities {
+		ids = append(ids, entity.ID)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+
+	if len(ids) != 5 || ids[4] != 5 {
+		t.Errorf("ids = %v", ids)
+	}
+	if len(*queries) != 3 {
+		t.Fatalf("queries = %v", *queries)
+	}
+	if want := `SELECT * FROM "orders" WHERE id > 2 ORDER BY id ASC LIMIT 2`; (*queries)[1] != want {
+		t.Errorf("second query = %s, want %s", (*queries)[1], want)
+	}
+	for _, query := range *queries {
+		if strings.Contains(query, "COUNT") || strings.Contains(query, "OFFSET") {
+			t.Errorf("unexpected query %s", query)
+		}
+	}
+}
+
+type tagEntity struct {
+	ID   uint
+	Name string
+}
+
+func (tagEntity) GetID() uint          { return 0 }
+func (tagEntity) GetTableName() string { return "tags" }
+func (tagEntity) TableName() string    { return "tags" }
+
+func TestStreamBatchedSortKey(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	// Записи с NULL в name выбираются после остальных отдельным обходом по id
+	pages := [][]tagEntity{{{ID: 9, Name: "go"}, {ID: 4, Name: "db"}}, {}, {{ID: 7}, {ID: 3}}, {{ID: 1}}}
+	var queries []string
+	db.Callback().Query().After("gorm:query").Register("test:pages", func(tx *gorm.DB) {
+		queries = append(queries, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+		*tx.Statement.Dest.(*[]tagEntity) = pages[len(queries)-1]
+	})
+
+	repo := NewBaseRepository[tagEntity](nil)
+	repo.tx = db
+
+	entities, errs := repo.StreamBatched(context.Background(), nil, &SortOptions{Field: "name", Order: "desc"}, 2)
+	var ids []uint
+	for entity := range entities {
+		ids = append(ids, entity.ID)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+
+	if want := []uint{9, 4, 7, 3, 1}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+	want := []string{
+		`SELECT * FROM "tags" WHERE name IS NOT NULL ORDER BY name DESC,id DESC LIMIT 2`,
+		`SELECT * FROM "tags" WHERE name IS NOT NULL AND (name, id) < ('db', 4) ORDER BY name DESC,id DESC LIMIT 2`,
+		`SELECT * FROM "tags" WHERE name IS NULL ORDER BY id DESC LIMIT 2`,
+		`SELECT * FROM "tags" WHERE name IS NULL AND id < 3 ORDER BY id DESC LIMIT 2`,
+	}
+	if !reflect.DeepEqual(queries, want) {
+		t.Errorf("queries = %v, want %v", queries, want)
+	}
+}
+
+func TestStreamBatchedMidStreamError(t *testing.T) {
+	pages := [][]orderEntity{{{ID: 1}, {ID: 2}}, {{ID: 3}, {ID: 4}}}
+	repo, _ := pagedRepository(t, pages, 1)
+
+	entities, errs := repo.StreamBatched(adminContext(), nil, nil, 2)
+	count := 0
+	for range entities {
+		count++
+	}
+	if err := <-errs; err == nil || !strings.Contains(err.Error(), "connection reset") {
+		t.Errorf("error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("received %d entities before error, want 2", count)
+	}
+}
+
+func TestStreamBatchedCancellation(t *testing.T) {
+	pages := [][]orderEntity{{{ID: 1}, {ID: 2}}, {{ID: 3}, {ID: 4}}, {{ID: 5}, {ID: 6}}}
+	repo, queries := pagedRepository(t, pages, -1)
+
+	ctx, cancel := context.WithCancel(adminContext())
+	entities, errs := repo.StreamBatched(ctx, nil, nil, 2)
+
+	<-entities
+	cancel()
+	for range entities {
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if len(*queries) == len(pages) {
+		t.Errorf("iteration was not stopped: %d queries", len(*queries))
+	}
+}
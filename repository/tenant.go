@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/vladzorgan/common/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// TenantConfig определяет изоляцию записей по арендатору (auth.WithTenant)
+type TenantConfig struct {
+	// Столбец арендатора в базе данных (например, "tenant_id")
+	Field string
+	// Требовать арендатора в контексте: без него запросы и создание записей возвращают ошибку PermissionDenied.
+	// Администраторы без арендатора в контексте видят записи всех арендаторов.
+	Required bool
+}
+
+// WithTenant включает изоляцию по арендатору: ко всем запросам чтения, обновления и удаления
+// добавляется условие по столбцу арендатора, а при создании столбец заполняется из контекста
+func (r *BaseRepository[T]) WithTenant(config *TenantConfig) *BaseRepository[T] {
+	r.tenantConfig = config
+	return r
+}
+
+// WithTenant включает изоляцию по арендатору (см. BaseRepository.WithTenant)
+func (r *BaseUUIDRepository[T]) WithTenant(config *TenantConfig) *BaseUUIDRepository[T] {
+	r.tenantConfig = config
+	return r
+}
+
+// tenantEnabled проверяет, настроена ли изоляция по арендатору
+func (r *repositoryCore[T]) tenantEnabled() bool {
+	return r.tenantConfig != nil && r.tenantConfig.Field != ""
+}
+
+// contextTenant возвращает арендатора из контекста. ok равен false, если условие по арендатору
+// не применяется: арендатор не задан и он не обязателен или пользователь - администратор.
+func (r *repositoryCore[T]) contextTenant(ctx context.Context) (tenantID uint, ok bool, err error) {
+	if tenantID, err := auth.GetTenantFromContext(ctx); err == nil {
+		return tenantID, true, nil
+	}
+
+	// Без арендатора администраторы работают с записями всех арендаторов
+	if user, err := auth.GetUserFromContext(ctx); err == nil && user.IsAdmin() {
+		return 0, false, nil
+	}
+
+	if r.tenantConfig.Required {
+		_, err := auth.RequireTenant(ctx)
+		return 0, false, err
+	}
+	return 0, false, nil
+}
+
+// applyTenantFilter добавляет к запросу условие по арендатору из контекста.
+// Если арендатор обязателен и не задан, запрос завершится ошибкой при выполнении.
+func (r *repositoryCore[T]) applyTenantFilter(ctx context.Context, query *gorm.DB) *gorm.DB {
+	if !r.tenantEnabled() {
+		return query
+	}
+
+	tenantID, ok, err := r.contextTenant(ctx)
+	if err != nil {
+		query.AddError(err)
+		return query
+	}
+	if !ok {
+		return query
+	}
+
+	return query.Where(r.tenantConfig.Field+" = ?", tenantID)
+}
+
+// assignTenant заполняет столбец арендатора сущностей значением из контекста
+func (r *repositoryCore[T]) assignTenant(ctx context.Context, entities ...*T) error {
+	if !r.tenantEnabled() || len(entities) == 0 {
+		return nil
+	}
+
+	tenantID, ok, err := r.contextTenant(ctx)
+	if err != nil || !ok {
+		return err
+	}
+
+	field, err := r.tenantField(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, entity := range entities {
+		if err := field.Set(ctx, reflect.ValueOf(entity).Elem(), tenantID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTenant проверяет, что сущность принадлежит арендатору из контекста
+func (r *repositoryCore[T]) checkTenant(ctx context.Context, entity *T) error {
+	if !r.tenantEnabled() {
+		return nil
+	}
+
+	tenantID, ok, err := r.contextTenant(ctx)
+	if err != nil || !ok {
+		return err
+	}
+
+	field, err := r.tenantField(ctx)
+	if err != nil {
+		return err
+	}
+
+	value, _ := field.ValueOf(ctx, reflect.ValueOf(entity).Elem())
+	if fmt.Sprint(value) != fmt.Sprint(tenantID) {
+		return status.Errorf(codes.PermissionDenied, "Доступ запрещен: запись другого арендатора")
+	}
+	return nil
+}
+
+// checkTenantUpdates запрещает перенос записей к другому арендатору через обновление столбца арендатора
+func (r *repositoryCore[T]) checkTenantUpdates(ctx context.Context, updates map[string]interface{}) error {
+	if !r.tenantEnabled() {
+		return nil
+	}
+
+	if _, exists := updates[r.tenantConfig.Field]; !exists {
+		return nil
+	}
+
+	if user, err := auth.GetUserFromContext(ctx); err == nil && user.IsAdmin() {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "Изменение арендатора записи запрещено")
+}
+
+// tenantField возвращает поле модели, соответствующее столбцу арендатора
+func (r *repositoryCore[T]) tenantField(ctx context.Context) (*schema.Field, error) {
+	stmt := &gorm.Statement{DB: r.getDB(ctx)}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+
+	field := stmt.Schema.LookUpField(r.tenantConfig.Field)
+	if field == nil {
+		return nil, fmt.Errorf("tenant column %s not found in %s", r.tenantConfig.Field, stmt.Schema.Table)
+	}
+	return field, nil
+}
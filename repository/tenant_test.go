@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vladzorgan/common/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type invoiceEntity struct {
+	ID       uint
+	TenantID uint
+	Number   string
+}
+
+func (invoiceEntity) GetID() uint          { return 0 }
+func (invoiceEntity) GetTableName() string { return "invoices" }
+func (invoiceEntity) TableName() string    { return "invoices" }
+
+// newTenantRepository создает репозиторий с изоляцией по арендатору поверх DryRun соединения
+func newTenantRepository(t *testing.T, required bool) (*BaseRepository[invoiceEntity], *string) {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	var sql string
+	db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		sql = tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)
+	})
+
+	repo := NewBaseRepository[invoiceEntity](nil).WithTenant(&TenantConfig{Field: "tenant_id", Required: required})
+	repo.tx = db
+	return repo, &sql
+}
+
+func TestTenantFilterApplied(t *testing.T) {
+	tenant := auth.WithTenant(context.Background(), 42)
+
+	tests := []struct {
+		name string
+		call func(ctx context.Context, repo *BaseRepository[invoiceEntity]) error
+		want string
+	}{
+		{
+			name: "count",
+			call: func(ctx context.Context, repo *BaseRepository[invoiceEntity]) error {
+				_, err := repo.Count(ctx, map[string]interface{}{"number": "A-1"})
+				return err
+			},
+			want: `SELECT count(*) FROM "invoices" WHERE tenant_id = 42 AND number = 'A-1'`,
+		},
+		{
+			name: "exists",
+			call: func(ctx context.Context, repo *BaseRepository[invoiceEntity]) error {
+				_, err := repo.Exists(ctx, 3)
+				return err
+			},
+			want: `SELECT count(*) FROM "invoices" WHERE tenant_id = 42 AND id = 3`,
+		},
+		{
+			name: "get by field",
+			call: func(ctx context.Context, repo *BaseRepository[invoiceEntity]) error {
+				_, err := repo.GetByField(ctx, "number", "A-1")
+				return err
+			},
+			want: `SELECT * FROM "invoices" WHERE tenant_id = 42 AND number = 'A-1' ORDER BY "invoices"."id" LIMIT 1`,
+		},
+		{
+			name: "get all",
+			call: func(ctx context.Context, repo *BaseRepository[invoiceEntity]) error {
+				_, _, err := repo.GetAll(ctx, 0, 10, nil, nil)
+				return err
+			},
+			want: `SELECT * FROM "invoices" WHERE tenant_id = 42 ORDER BY id ASC LIMIT 10`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, sql := newTenantRepository(t, true)
+			if err := tt.call(tenant, repo); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *sql != tt.want {
+				t.Errorf("SQL = %s, want %s", *sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenantRequired(t *testing.T) {
+	repo, sql := newTenantRepository(t, true)
+	ctx := context.Background()
+
+	if _, err := repo.Count(ctx, nil); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Count() error = %v, want PermissionDenied", err)
+	}
+	if err := repo.Create(ctx, &invoiceEntity{Number: "A-1"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Create() error = %v, want PermissionDenied", err)
+	}
+	if *sql != "" {
+		t.Errorf("query must not run without tenant, got %s", *sql)
+	}
+
+	// Администратор без арендатора видит записи всех арендаторов
+	admin := auth.WithUser(ctx, &auth.User{ID: 1, Role: auth.UserRole_Admin, IsActive: true})
+	if _, err := repo.Count(admin, nil); err != nil {
+		t.Fatalf("Count() as admin error = %v", err)
+	}
+	if want := `SELECT count(*) FROM "invoices"`; *sql != want {
+		t.Errorf("SQL = %s, want %s", *sql, want)
+	}
+}
+
+func TestTenantOptional(t *testing.T) {
+	repo, sql := newTenantRepository(t, false)
+
+	if _, err := repo.Count(context.Background(), nil); err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if want := `SELECT count(*) FROM "invoices"`; *sql != want {
+		t.Errorf("SQL = %s, want %s", *sql, want)
+	}
+}
+
+func TestTenantAssignedOnCreate(t *testing.T) {
+	repo, _ := newTenantRepository(t, true)
+	ctx := auth.WithTenant(context.Background(), 42)
+
+	invoice := &invoiceEntity{Number: "A-1", TenantID: 7}
+	if err := repo.Create(ctx, invoice); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if invoice.TenantID != 42 {
+		t.Errorf("TenantID = %d, want 42", invoice.TenantID)
+	}
+
+	invoices := []*invoiceEntity{{Number: "A-2"}, {Number: "A-3"}}
+	if err := repo.assignTenant(ctx, invoices...); err != nil {
+		t.Fatalf("assignTenant() error = %v", err)
+	}
+	for _, inv := range invoices {
+		if inv.TenantID != 42 {
+			t.Errorf("TenantID = %d, want 42", inv.TenantID)
+		}
+	}
+}
+
+func TestTenantUpdateRequiresAdmin(t *testing.T) {
+	repo, _ := newTenantRepository(t, true)
+	ctx := auth.WithTenant(context.Background(), 42)
+
+	if _, err := repo.Update(ctx, 1, map[string]interface{}{"tenant_id": 7}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Update() error = %v, want PermissionDenied", err)
+	}
+	if err := repo.checkTenantUpdates(auth.WithUser(ctx, &auth.User{ID: 1, Role: auth.UserRole_Admin}), map[string]interface{}{"tenant_id": 7}); err != nil {
+		t.Errorf("checkTenantUpdates() as admin error = %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// upsertBatchSize размер пакета при массовой вставке с обновлением
+const upsertBatchSize = 100
+
+// UpsertResult содержит ID записей, вставленных и обновленных при Upsert
+type UpsertResult struct {
+	Inserted []uint
+	Updated  []uint
+}
+
+// Upsert вставляет запись или обновляет существующую при конфликте по conflictColumns.
+// Обновляются столбцы updateColumns (все столбцы, если список пуст).
+// Возвращает true, если запись была вставлена.
+func (r *BaseRepository[T]) Upsert(ctx context.Context, entity *T, conflictColumns []string, updateColumns []string) (bool, error) {
+	result, err := r.BulkUpsert(ctx, []*T{entity}, conflictColumns, updateColumns)
+	if err != nil {
+		return false, err
+	}
+	return len(result.Inserted) > 0, nil
+}
+
+// BulkUpsert вставляет записи или обновляет существующие при конфликте по conflictColumns.
+// Существующие записи определяются в той же транзакции до вставки, поэтому результат
+// разделяет вставленные и обновленные записи. Обновление чужих записей запрещено проверкой владения.
+func (r *BaseRepository[T]) BulkUpsert(ctx context.Context, entities []*T, conflictColumns []string, updateColumns []string) (*UpsertResult, error) {
+	result := &UpsertResult{}
+	if len(entities) == 0 {
+		return result, nil
+	}
+
+	if len(conflictColumns) == 0 {
+		return nil, errors.New("upsert requires at least one conflict column")
+	}
+
+	// Проверяем разрешения на запись
+	if err := r.checkWritePermission(ctx); err != nil {
+		return nil, err
+	}
+
+	// Заполняем арендатора из контекста
+	if err := r.assignTenant(ctx, entities...); err != nil {
+		return nil, err
+	}
+
+	err := r.getDB(ctx).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(new(T)); err != nil {
+			return err
+		}
+
+		fields := make([]*schema.Field, 0, len(conflictColumns))
+		for _, column := range conflictColumns {
+			field := stmt.Schema.LookUpField(column)
+			if field == nil {
+				return fmt.Errorf("unknown conflict column %s", column)
+			}
+			fields = append(fields, field)
+		}
+
+		// Находим уже существующие записи по ключу конфликта
+		keys := make([]string, len(entities))
+		tuples := make([][]interface{}, len(entities))
+		for i, entity := range entities {
+			keys[i], tuples[i] = conflictKey(ctx, fields, reflect.ValueOf(entity).Elem())
+		}
+
+		existing, err := r.findByConflictKeys(ctx, tx, fields, conflictColumns, tuples)
+		if err != nil {
+			return err
+		}
+
+		onConflict := clause.OnConflict{Columns: make([]clause.Column, 0, len(conflictColumns))}
+		for _, column := range conflictColumns {
+			onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+		}
+		if len(updateColumns) > 0 {
+			onConflict.DoUpdates = clause.AssignmentColumns(updateColumns)
+		} else {
+			onConflict.UpdateAll = true
+		}
+
+		if err := tx.Clauses(onConflict).CreateInBatches(entities, upsertBatchSize).Error; err != nil {
+			return err
+		}
+
+		for i, entity := range entities {
+			if existing[keys[i]] {
+				result.Updated = append(result.Updated, (*entity).GetID())
+			} else {
+				result.Inserted = append(result.Inserted, (*entity).GetID())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// findByConflictKeys возвращает множество ключей конфликта уже существующих записей,
+// проверяя права владения и арендатора для каждой из них
+func (r *BaseRepository[T]) findByConflictKeys(ctx context.Context, tx *gorm.DB, fields []*schema.Field, columns []string, tuples [][]interface{}) (map[string]bool, error) {
+	query := tx.Model(new(T))
+	if len(columns) == 1 {
+		values := make([]interface{}, len(tuples))
+		for i, tuple := range tuples {
+			values[i] = tuple[0]
+		}
+		query = query.Where(columns[0]+" IN ?", values)
+	} else {
+		query = query.Where("("+strings.Join(columns, ", ")+") IN ?", tuples)
+	}
+
+	var rows []T
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(rows))
+	for i := range rows {
+		// Обновление записи другого владельца или арендатора запрещено
+		if err := r.checkOwnership(ctx, &rows[i]); err != nil {
+			return nil, err
+		}
+		if err := r.checkTenant(ctx, &rows[i]); err != nil {
+			return nil, err
+		}
+
+		key, _ := conflictKey(ctx, fields, reflect.ValueOf(&rows[i]).Elem())
+		existing[key] = true
+	}
+
+	return existing, nil
+}
+
+// conflictKey возвращает строковый ключ и значения столбцов конфликта записи
+func conflictKey(ctx context.Context, fields []*schema.Field, value reflect.Value) (string, []interface{}) {
+	values := make([]interface{}, len(fields))
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		values[i], _ = field.ValueOf(ctx, value)
+
+		// Значения указателей сравниваются по содержимому
+		part := values[i]
+		if rv := reflect.ValueOf(part); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			part = rv.Elem().Interface()
+		}
+		parts[i] = fmt.Sprint(part)
+	}
+	return strings.Join(parts, "\x00"), values
+}
@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vladzorgan/common/database"
+	"gorm.io/gorm"
+)
+
+// UUIDModel представляет модель со строковым первичным ключом (например, UUID).
+// Модели с числовым ID продолжают использовать BaseModel и BaseRepository.
+type UUIDModel interface {
+	GetID() string
+	GetTableName() string
+}
+
+// UUIDRepository определяет интерфейс репозитория для моделей со строковым ID.
+// Операции над коллекциями совпадают с Repository; архивация, upsert и BulkUpdate не поддерживаются.
+type UUIDRepository[T UUIDModel] interface {
+	// CRUD операции
+	Create(ctx context.Context, entity *T) error
+	GetByID(ctx context.Context, id string, opts ...QueryOption) (*T, error)
+	GetByIDs(ctx context.Context, ids []string, opts ...QueryOption) ([]T, error)
+	Update(ctx context.Context, id string, updates map[string]interface{}) (*T, error)
+	Delete(ctx context.Context, id string) (*T, error)
+
+	// Массовые операции
+	BulkCreate(ctx context.Context, entities []*T) error
+
+	// Операции с коллекциями
+	GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *SortOptions, opts ...QueryOption) ([]T, int64, error)
+	Search(ctx context.Context, keyword string, skip, limit int, filters map[string]interface{}, sort *SortOptions, opts ...QueryOption) ([]T, int64, error)
+	GetByField(ctx context.Context, field string, value interface{}, opts ...QueryOption) (*T, error)
+	GetOrCreate(ctx context.Context, field string, value interface{}, create func() *T) (*T, bool, error)
+	GetAllByField(ctx context.Context, field string, value interface{}, skip, limit int, opts ...QueryOption) ([]T, int64, error)
+	Stream(ctx context.Context, filters map[string]interface{}, sort *SortOptions, fn func(entity *T) error) error
+	StreamBatched(ctx context.Context, filters map[string]interface{}, sort *SortOptions, batchSize int) (<-chan T, <-chan error)
+
+	// Дополнительные операции
+	Count(ctx context.Context, filters map[string]interface{}, opts ...QueryOption) (int64, error)
+	CountByField(ctx context.Context, field string, value interface{}) (int64, error)
+	ExistsByField(ctx context.Context, field string, value interface{}) (bool, error)
+	ExistsByFieldUnscoped(ctx context.Context, field string, value interface{}) (bool, error)
+	Exists(ctx context.Context, id string) (bool, error)
+
+	// Работа с транзакциями
+	WithTx(tx *gorm.DB) UUIDRepository[T]
+}
+
+// BaseUUIDRepository представляет базовую реализацию репозитория для моделей со строковым ID.
+// Авторизация, фильтр по владению (Owned), поиск и опции запросов работают так же, как в BaseRepository.
+type BaseUUIDRepository[T UUIDModel] struct {
+	repositoryCore[T]
+}
+
+// NewBaseUUIDRepository создает новый экземпляр BaseUUIDRepository
+func NewBaseUUIDRepository[T UUIDModel](db *database.Database) *BaseUUIDRepository[T] {
+	return &BaseUUIDRepository[T]{
+		repositoryCore: repositoryCore[T]{db: db},
+	}
+}
+
+// NewBaseUUIDRepositoryWithAuth создает новый экземпляр BaseUUIDRepository с авторизацией
+func NewBaseUUIDRepositoryWithAuth[T UUIDModel](db *database.Database, authConfig *AuthConfig) *BaseUUIDRepository[T] {
+	return &BaseUUIDRepository[T]{
+		repositoryCore: repositoryCore[T]{db: db, authConfig: authConfig},
+	}
+}
+
+// WithTx создает новый репозиторий с транзакцией
+func (r *BaseUUIDRepository[T]) WithTx(tx *gorm.DB) UUIDRepository[T] {
+	return &BaseUUIDRepository[T]{repositoryCore: r.withTx(tx)}
+}
+
+// WithDefaultPreloads задает связи, загружаемые во всех запросах чтения репозитория
+func (r *BaseUUIDRepository[T]) WithDefaultPreloads(associations ...string) *BaseUUIDRepository[T] {
+	r.preloads = append([]string(nil), associations...)
+	return r
+}
+
+// WithSearchFields задает колонки, по которым Search ищет ключевое слово (см. BaseRepository.WithSearchFields)
+func (r *BaseUUIDRepository[T]) WithSearchFields(fields ...string) *BaseUUIDRepository[T] {
+	r.searchFields = append([]string(nil), fields...)
+	return r
+}
+
+// WithSearchMode задает способ сопоставления ключевого слова в Search
+func (r *BaseUUIDRepository[T]) WithSearchMode(mode SearchMode) *BaseUUIDRepository[T] {
+	r.searchMode = mode
+	return r
+}
+
+// WithFullTextSearch переключает Search на полнотекстовый поиск Postgres (см. BaseRepository.WithFullTextSearch)
+func (r *BaseUUIDRepository[T]) WithFullTextSearch(column, config string) *BaseUUIDRepository[T] {
+	r.fullTextSearch = newFullTextSearch(column, config)
+	return r
+}
+
+// GetByID получает запись по ID
+func (r *BaseUUIDRepository[T]) GetByID(ctx context.Context, id string, opts ...QueryOption) (*T, error) {
+	// Проверяем разрешения на чтение
+	if err := r.checkReadPermission(ctx); err != nil {
+		return nil, err
+	}
+
+	query := r.getReadDB(ctx).WithContext(ctx)
+	query = r.applyOwnershipFilter(ctx, query)
+	query = r.applyPreloads(query, opts)
+
+	return r.first(ctx, query, id)
+}
+
+// GetByIDs получает записи по списку ID одним запросом, упорядоченные по ID.
+// Отсутствующие и недоступные пользователю записи в результат не попадают.
+func (r *BaseUUIDRepository[T]) GetByIDs(ctx context.Context, ids []string, opts ...QueryOption) ([]T, error) {
+	// Проверяем разрешения на чтение
+	if err := r.checkReadPermission(ctx); err != nil {
+		return nil, err
+	}
+
+	entities := make([]T, 0, len(ids))
+	if len(ids) == 0 {
+		return entities, nil
+	}
+
+	query := r.getReadDB(ctx).WithContext(ctx)
+	query = r.applyOwnershipFilter(ctx, query)
+	query = r.applyPreloads(query, opts)
+
+	if err := query.Where("id IN ?", ids).Order("id").Find(&entities).Error; err != nil {
+		return nil, err
+	}
+
+	// Исключаем записи, владение которыми не подтверждено
+	owned := entities[:0]
+	for i := range entities {
+		if err := r.checkOwnership(ctx, &entities[i]); err == nil {
+			owned = append(owned, entities[i])
+		}
+	}
+
+	return owned, nil
+}
+
+// Update обновляет запись по ID
+func (r *BaseUUIDRepository[T]) Update(ctx context.Context, id string, updates map[string]interface{}) (*T, error) {
+	// Проверяем разрешения на запись
+	if err := r.checkWritePermission(ctx); err != nil {
+		return nil, err
+	}
+
+	// Перенос записи к другому арендатору доступен только администраторам
+	if err := r.checkTenantUpdates(ctx, updates); err != nil {
+		return nil, err
+	}
+
+	entity, err := r.first(ctx, r.applyOwnershipFilter(ctx, r.getDB(ctx).WithContext(ctx)), id)
+	if err != nil || entity == nil {
+		return nil, err
+	}
+
+	if err := r.getDB(ctx).WithContext(ctx).Model(entity).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	// Получаем обновленную запись
+	if err := r.getDB(ctx).WithContext(ctx).Where("id = ?", id).First(entity).Error; err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
+
+// Delete удаляет запись по ID (soft delete, если модель его поддерживает)
+func (r *BaseUUIDRepository[T]) Delete(ctx context.Context, id string) (*T, error) {
+	// Проверяем разрешения на запись (для удаления)
+	if err := r.checkWritePermission(ctx); err != nil {
+		return nil, err
+	}
+
+	entity, err := r.first(ctx, r.applyOwnershipFilter(ctx, r.getDB(ctx).WithContext(ctx)), id)
+	if err != nil || entity == nil {
+		return nil, err
+	}
+
+	if err := r.getDB(ctx).WithContext(ctx).Delete(entity).Error; err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
+
+// Exists проверяет существование записи по ID.
+// Чужие записи для обычного пользователя считаются несуществующими.
+func (r *BaseUUIDRepository[T]) Exists(ctx context.Context, id string) (bool, error) {
+	var count int64
+
+	// Проверяем разрешения на чтение
+	if err := r.checkReadPermission(ctx); err != nil {
+		return false, err
+	}
+
+	query := r.getReadDB(ctx).WithContext(ctx).Model(new(T))
+	query = r.applyOwnershipFilter(ctx, query)
+
+	if err := query.Where("id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// first получает запись по строковому ID и проверяет владение; для отсутствующей записи возвращает nil.
+// Строковый ID передается условием "id = ?": gorm трактует строку в First как SQL.
+func (r *BaseUUIDRepository[T]) first(ctx context.Context, query *gorm.DB, id string) (*T, error) {
+	var entity T
+
+	if err := query.Where("id = ?", id).First(&entity).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// Дополнительная проверка владения для конкретной записи
+	if err := r.checkOwnership(ctx, &entity); err != nil {
+		return nil, err
+	}
+
+	return &entity, nil
+}
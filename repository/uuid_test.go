@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vladzorgan/common/auth"
+)
+
+type deviceEntity struct {
+	ID     string
+	UserID uint
+}
+
+func (d deviceEntity) GetID() string      { return d.ID }
+func (deviceEntity) GetTableName() string { return "devices" }
+func (deviceEntity) TableName() string    { return "devices" }
+func (d deviceEntity) GetOwnerID() uint   { return d.UserID }
+
+func newOwnedUUIDRepository(t *testing.T) (*BaseUUIDRepository[deviceEntity], *string) {
+	t.Helper()
+
+	owned, sql := newOwnedRepository(t)
+	repo := NewBaseUUIDRepositoryWithAuth[deviceEntity](nil, owned.authConfig)
+	repo.tx = owned.tx
+	return repo, sql
+}
+
+func TestUUIDRepositoryQueriesByStringID(t *testing.T) {
+	admin := auth.WithUser(context.Background(), &auth.User{ID: 1, Role: auth.UserRole_Admin, IsActive: true})
+	user := auth.WithUser(context.Background(), &auth.User{ID: 7, Role: auth.UserRole_User, IsActive: true})
+	id := "8f14e45f-ceea-4e67-a1d2-7a4b2d6f0c11"
+
+	tests := []struct {
+		name string
+		call func(repo *BaseUUIDRepository[deviceEntity]) error
+		want string
+	}{
+		{
+			// Строковый ID передается параметром, а не как SQL условие
+			name: "get by id",
+			call: func(repo *BaseUUIDRepository[deviceEntity]) error {
+				_, err := repo.GetByID(admin, id)
+				return err
+			},
+			want: `SELECT * FROM "devices" WHERE id = '` + id + `' ORDER BY "devices"."id" LIMIT 1`,
+		},
+		{
+			name: "get by ids",
+			call: func(repo *BaseUUIDRepository[deviceEntity]) error {
+				_, err := repo.GetByIDs(user, []string{id, "b"})
+				return err
+			},
+			want: `SELECT * FROM "devices" WHERE user_id = 7 AND id IN ('` + id + `','b') ORDER BY id`,
+		},
+		{
+			name: "exists",
+			call: func(repo *BaseUUIDRepository[deviceEntity]) error {
+				_, err := repo.Exists(user, id)
+				return err
+			},
+			want: `SELECT count(*) FROM "devices" WHERE user_id = 7 AND id = '` + id + `'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, sql := newOwnedUUIDRepository(t)
+			if err := tt.call(repo); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *sql != tt.want {
+				t.Errorf("SQL = %s, want %s", *sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestUUIDRepositoryChecksOwnership(t *testing.T) {
+	repo, _ := newOwnedUUIDRepository(t)
+	user := auth.WithUser(context.Background(), &auth.User{ID: 7, Role: auth.UserRole_User, IsActive: true})
+
+	if err := repo.checkOwnership(user, &deviceEntity{ID: "a", UserID: 7}); err != nil {
+		t.Errorf("own entity: %v", err)
+	}
+	if err := repo.checkOwnership(user, &deviceEntity{ID: "a", UserID: 8}); err == nil {
+		t.Error("foreign entity must be rejected")
+	}
+}
@@ -0,0 +1,93 @@
+// Package retry предоставляет экспоненциальную задержку между повторными попытками
+package retry
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// DefaultJitter - доля случайного разброса задержки по умолчанию
+const DefaultJitter = 0.5
+
+// Backoff вычисляет задержки между попытками: каждая следующая вдвое больше предыдущей,
+// но не больше Max. Случайный разброс не дает клиентам повторять запросы одновременно.
+// Backoff не безопасен для конкурентного использования: каждому циклу повторов нужен свой экземпляр.
+type Backoff struct {
+	// Начальная задержка
+	Initial time.Duration
+	// Максимальная задержка (0 - без ограничения)
+	Max time.Duration
+	// Доля случайного разброса: задержка выбирается из [d*(1-Jitter), d] (0 - без разброса)
+	Jitter float64
+
+	current time.Duration
+}
+
+// NewBackoff создает Backoff с разбросом DefaultJitter
+func NewBackoff(initial, max time.Duration) *Backoff {
+	return &Backoff{
+		Initial: initial,
+		Max:     max,
+		Jitter:  DefaultJitter,
+	}
+}
+
+// Next возвращает задержку перед следующей попыткой
+func (b *Backoff) Next() time.Duration {
+	switch {
+	case b.current <= 0:
+		b.current = b.Initial
+	case b.current > maxDuration/2:
+		b.current = maxDuration
+	default:
+		b.current *= 2
+	}
+	if b.Max > 0 && b.current > b.Max {
+		b.current = b.Max
+	}
+
+	return jitter(b.current, b.Jitter)
+}
+
+// Reset возвращает задержку к начальной
+func (b *Backoff) Reset() {
+	b.current = 0
+}
+
+// Wait ждет следующую задержку. Возвращает ctx.Err(), если контекст отменен раньше.
+func (b *Backoff) Wait(ctx context.Context) error {
+	return Sleep(ctx, b.Next())
+}
+
+// Sleep ждет d или отмены контекста
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// maxDuration - наибольшая задержка, при которой удвоение не переполняется
+const maxDuration = time.Duration(1<<63 - 1)
+
+// jitter уменьшает задержку на случайную долю не больше fraction
+func jitter(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || d <= 1 {
+		return d
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+
+	spread := time.Duration(float64(d) * fraction)
+	if spread <= 0 {
+		return d
+	}
+	return d - spread + rand.N(spread+1)
+}
@@ -0,0 +1,67 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoffDoublesUpToMax(t *testing.T) {
+	b := &Backoff{Initial: 10 * time.Millisecond, Max: 50 * time.Millisecond}
+
+	want := []time.Duration{10, 20, 40, 50, 50}
+	for i, w := range want {
+		if got := b.Next(); got != w*time.Millisecond {
+			t.Errorf("Next() #%d = %v, want %v", i, got, w*time.Millisecond)
+		}
+	}
+
+	b.Reset()
+	if got := b.Next(); got != 10*time.Millisecond {
+		t.Errorf("Next() after Reset = %v, want 10ms", got)
+	}
+}
+
+func TestBackoffWithoutMaxDoesNotOverflow(t *testing.T) {
+	b := &Backoff{Initial: time.Hour}
+	for i := 0; i < 100; i++ {
+		if got := b.Next(); got <= 0 {
+			t.Fatalf("Next() #%d = %v, want positive", i, got)
+		}
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	b := NewBackoff(100*time.Millisecond, 100*time.Millisecond)
+
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 200; i++ {
+		got := b.Next()
+		if got < 50*time.Millisecond || got > 100*time.Millisecond {
+			t.Fatalf("Next() = %v, want within [50ms, 100ms]", got)
+		}
+		seen[got] = true
+	}
+	if len(seen) < 2 {
+		t.Error("Next() returned the same delay every time, want jitter")
+	}
+}
+
+func TestBackoffWaitStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	b := &Backoff{Initial: time.Hour}
+	started := time.Now()
+	if err := b.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Wait() took %v after cancellation", elapsed)
+	}
+
+	if err := Sleep(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("Sleep() error = %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/http/httpctx"
 	"github.com/vladzorgan/common/logging"
 )
 
@@ -63,7 +64,7 @@ func APIKeyMiddleware(config *APIKeyConfig, logger logging.Logger) gin.HandlerFu
 		// Получаем API-ключ из заголовка
 		apiKey := c.GetHeader(config.Header)
 		if apiKey == "" {
-			logger.WithRequestID(c.GetString("RequestID")).
+			logger.WithRequestID(httpctx.RequestID(c)).
 				WithField("path", path).
 				WithField("method", method).
 				Warn("API key is missing")
@@ -77,7 +78,7 @@ func APIKeyMiddleware(config *APIKeyConfig, logger logging.Logger) gin.HandlerFu
 
 		// Проверяем API-ключ
 		if apiKey != config.Key {
-			logger.WithRequestID(c.GetString("RequestID")).
+			logger.WithRequestID(httpctx.RequestID(c)).
 				WithField("path", path).
 				WithField("method", method).
 				Warn("Invalid API key")
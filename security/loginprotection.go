@@ -0,0 +1,293 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/http/httpctx"
+	"github.com/vladzorgan/common/killswitch"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/metrics"
+	"github.com/vladzorgan/common/redis"
+)
+
+// loginResultKey ключ контекста Gin, в котором обработчик отмечает результат входа
+const loginResultKey = "security.login_result"
+
+// maxLoginBodyBytes ограничивает размер тела, читаемого для определения имени пользователя
+const maxLoginBodyBytes = 64 << 10
+
+// loginReserveScript атомарно резервирует попытку входа до вызова обработчика.
+// Попытка сразу учитывается в счетчике неудач, поэтому параллельные запросы не могут
+// превысить limit до того, как первые из них завершатся.
+// KEYS[1] - счетчик, KEYS[2] - блокировка; ARGV - окно счетчика (мс), limit.
+// Возвращает 0, если попытка разрешена, иначе время в мс, через которое стоит повторить.
+var loginReserveScript = goredis.NewScript(`
+local lockedFor = redis.call("PTTL", KEYS[2])
+if lockedFor > 0 then
+	return lockedFor
+end
+
+local attempts = redis.call("INCR", KEYS[1])
+if attempts == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+
+if attempts > tonumber(ARGV[2]) then
+	redis.call("DECR", KEYS[1])
+	local retryAfter = redis.call("PTTL", KEYS[1])
+	if retryAfter <= 0 then
+		retryAfter = ARGV[1]
+	end
+	return retryAfter
+end
+return 0
+`)
+
+// loginFailureScript блокирует пару после limit неудач; сама неудача уже учтена при резервировании.
+// KEYS[1] - счетчик, KEYS[2] - блокировка; ARGV - limit, длительность блокировки (мс).
+// Возвращает 1, если пара заблокирована этой попыткой.
+var loginFailureScript = goredis.NewScript(`
+local failures = tonumber(redis.call("GET", KEYS[1]) or "0")
+if failures >= tonumber(ARGV[1]) then
+	redis.call("SET", KEYS[2], 1, "PX", ARGV[2])
+	redis.call("DEL", KEYS[1])
+	return 1
+end
+return 0
+`)
+
+// loginReleaseScript возвращает зарезервированную попытку, результат которой обработчик не отметил.
+// KEYS[1] - счетчик.
+var loginReleaseScript = goredis.NewScript(`
+local attempts = tonumber(redis.call("GET", KEYS[1]) or "0")
+if attempts > 0 then
+	redis.call("DECR", KEYS[1])
+end
+return 0
+`)
+
+// LoginProtectionOptions содержит настройки защиты входа от подбора паролей
+type LoginProtectionOptions struct {
+	// Количество неудачных попыток до блокировки
+	MaxFailures int
+	// Окно, в котором считаются неудачные попытки
+	FailureWindow time.Duration
+	// Длительность блокировки пары пользователь+IP
+	LockoutDuration time.Duration
+	// Функция получения имени пользователя из запроса (по умолчанию поле username тела запроса)
+	Username func(c *gin.Context) string
+	// Префикс ключей в Redis
+	KeyPrefix string
+	// Префикс метрик Prometheus
+	ServicePrefix string
+	// Registerer для метрик (по умолчанию prometheus.DefaultRegisterer)
+	Registerer prometheus.Registerer
+	// Логгер
+	Logger logging.Logger
+}
+
+// DefaultLoginProtectionOptions возвращает опции по умолчанию
+func DefaultLoginProtectionOptions() *LoginProtectionOptions {
+	return &LoginProtectionOptions{
+		MaxFailures:     5,
+		FailureWindow:   15 * time.Minute,
+		LockoutDuration: 15 * time.Minute,
+		Username:        UsernameFromBody("username"),
+		KeyPrefix:       "login",
+	}
+}
+
+// LoginProtector блокирует пару пользователь+IP после серии неудачных попыток входа.
+// Счетчики хранятся в Redis и общие для всех реплик.
+type LoginProtector struct {
+	redis    *redis.Client
+	options  *LoginProtectionOptions
+	lockouts prometheus.Counter
+}
+
+// NewLoginProtector создает защиту входа
+func NewLoginProtector(redisClient *redis.Client, options *LoginProtectionOptions) *LoginProtector {
+	if options == nil {
+		options = DefaultLoginProtectionOptions()
+	}
+	if options.Username == nil {
+		options.Username = UsernameFromBody("username")
+	}
+	if options.KeyPrefix == "" {
+		options.KeyPrefix = "login"
+	}
+	if options.Registerer == nil {
+		options.Registerer = prometheus.DefaultRegisterer
+	}
+	if options.Logger == nil {
+		options.Logger = logging.NewLogger()
+	}
+
+	name := "login_lockouts_total"
+	if options.ServicePrefix != "" {
+		name = options.ServicePrefix + "_" + name
+	}
+	lockouts := metrics.Register(options.Registerer, prometheus.NewCounter(prometheus.CounterOpts{
+		Name: name,
+		Help: "Количество блокировок входа после серии неудачных попыток",
+	}))
+
+	return &LoginProtector{
+		redis:    redisClient,
+		options:  options,
+		lockouts: lockouts,
+	}
+}
+
+// LoginProtection возвращает middleware защиты входа (см. LoginProtector.Middleware)
+func LoginProtection(redisClient *redis.Client, options *LoginProtectionOptions) gin.HandlerFunc {
+	return NewLoginProtector(redisClient, options).Middleware()
+}
+
+// LoginFailed отмечает в обработчике входа неудачную попытку
+func LoginFailed(c *gin.Context) {
+	c.Set(loginResultKey, false)
+}
+
+// LoginSucceeded отмечает в обработчике входа успешный вход; счетчик неудач сбрасывается
+func LoginSucceeded(c *gin.Context) {
+	c.Set(loginResultKey, true)
+}
+
+// Middleware возвращает middleware для обработчиков входа. Перед обработчиком попытка атомарно
+// резервируется в Redis: заблокированные пары и пары, исчерпавшие лимит параллельными попытками,
+// получают 429 с заголовком Retry-After. Результат попытки обработчик отмечает через LoginFailed
+// и LoginSucceeded; неотмеченная попытка возвращается в лимит.
+// При недоступности Redis запросы пропускаются (fail open).
+// Отключается выключателем killswitch.FeatureLoginProtection.
+func (p *LoginProtector) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if killswitch.IsDisabled(killswitch.FeatureLoginProtection) {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		username := p.options.Username(c)
+		failuresKey, lockKey := p.keys(username, c.ClientIP())
+		keys := []string{failuresKey, lockKey}
+		logger := p.options.Logger.WithRequestID(httpctx.RequestID(c))
+
+		retryAfterMs, err := loginReserveScript.Run(ctx, p.redis.Client(), keys,
+			p.options.FailureWindow.Milliseconds(), p.options.MaxFailures,
+		).Int64()
+		if err != nil {
+			logger.Warn("Login protection unavailable, allowing request: %v", err)
+			c.Next()
+			return
+		}
+		if retryAfterMs > 0 {
+			retryAfter := int(math.Ceil(float64(retryAfterMs) / 1000))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":   "Too Many Requests",
+				"message": "Too many failed login attempts",
+			})
+			return
+		}
+
+		c.Next()
+
+		succeeded, ok := c.Get(loginResultKey)
+		if !ok {
+			if err := loginReleaseScript.Run(ctx, p.redis.Client(), keys[:1]).Err(); err != nil {
+				logger.Warn("Failed to release login attempt: %v", err)
+			}
+			return
+		}
+
+		if succeeded.(bool) {
+			if err := p.redis.Del(ctx, failuresKey); err != nil {
+				logger.Warn("Failed to reset login failures: %v", err)
+			}
+			return
+		}
+
+		locked, err := loginFailureScript.Run(ctx, p.redis.Client(), keys,
+			p.options.MaxFailures, p.options.LockoutDuration.Milliseconds(),
+		).Int()
+		if err != nil {
+			logger.Warn("Failed to record login failure: %v", err)
+			return
+		}
+
+		if locked == 1 {
+			p.lockouts.Inc()
+			logger.Warn("Login locked out for %s after %d failed attempts from %s", username, p.options.MaxFailures, c.ClientIP())
+		}
+	}
+}
+
+// ClearLockout снимает блокировку и сбрасывает счетчик неудач пары пользователь+IP
+func (p *LoginProtector) ClearLockout(ctx context.Context, username, ip string) error {
+	failuresKey, lockKey := p.keys(username, ip)
+	if err := p.redis.Del(ctx, failuresKey, lockKey); err != nil {
+		return fmt.Errorf("failed to clear login lockout: %w", err)
+	}
+
+	return nil
+}
+
+// keys возвращает ключи счетчика неудач и блокировки. Имя пользователя хешируется,
+// чтобы произвольный ввод не попадал в ключи Redis.
+func (p *LoginProtector) keys(username, ip string) (string, string) {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(username))))
+	pair := hex.EncodeToString(sum[:16]) + ":" + ip
+
+	return p.options.KeyPrefix + ":failures:" + pair, p.options.KeyPrefix + ":lock:" + pair
+}
+
+// UsernameFromBody возвращает функцию чтения имени пользователя из поля field JSON или
+// form-urlencoded тела запроса. Тело восстанавливается для обработчика.
+func UsernameFromBody(field string) func(c *gin.Context) string {
+	return func(c *gin.Context) string {
+		if c.Request.Body == nil {
+			return ""
+		}
+
+		data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxLoginBodyBytes))
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), c.Request.Body))
+		if err != nil {
+			return ""
+		}
+
+		if strings.HasPrefix(c.ContentType(), gin.MIMEPOSTForm) {
+			values, err := url.ParseQuery(string(data))
+			if err != nil {
+				return ""
+			}
+			return values.Get(field)
+		}
+
+		var body map[string]interface{}
+		if err := json.Unmarshal(data, &body); err != nil {
+			return ""
+		}
+		username, _ := body[field].(string)
+		return username
+	}
+}
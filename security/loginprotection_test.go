@@ -0,0 +1,233 @@
+package security
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/vladzorgan/common/redis"
+)
+
+func newLoginRouter(t *testing.T) (*gin.Engine, *LoginProtector, *miniredis.Miniredis) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	server := miniredis.RunT(t)
+	client, err := redis.NewClient(server.Addr(), "", 0, nil, nil)
+	if err != nil {
+		t.Fatalf("redis.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	options := DefaultLoginProtectionOptions()
+	options.MaxFailures = 3
+	options.LockoutDuration = time.Minute
+	options.Registerer = prometheus.NewRegistry()
+	protector := NewLoginProtector(client, options)
+
+	router := gin.New()
+	router.POST("/login", protector.Middleware(), func(c *gin.Context) {
+		var input struct {
+			Username string `json:"username" form:"username"`
+			Password string `json:"password" form:"password"`
+		}
+		if err := c.ShouldBind(&input); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		if input.Password != "secret" {
+			LoginFailed(c)
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		LoginSucceeded(c)
+		c.Status(http.StatusOK)
+	})
+	return router, protector, server
+}
+
+func login(router *gin.Engine, ip, username, password string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/login",
+		strings.NewReader(`{"username":"`+username+`","password":"`+password+`"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.RemoteAddr = ip + ":1234"
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestLoginProtectionLocksOutAfterFailures(t *testing.T) {
+	router, protector, server := newLoginRouter(t)
+
+	for i := 0; i < 3; i++ {
+		if code := login(router, "10.0.0.1", "ivan", "wrong").Code; code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: status = %d, want 401", i+1, code)
+		}
+	}
+
+	// Пара заблокирована даже для верного пароля
+	recorder := login(router, "10.0.0.1", "Ivan", "secret")
+	if recorder.Code != http.StatusTooManyRequests || recorder.Header().Get("Retry-After") != "60" {
+		t.Errorf("locked: status = %d, Retry-After = %q", recorder.Code, recorder.Header().Get("Retry-After"))
+	}
+	if got := testutil.ToFloat64(protector.lockouts); got != 1 {
+		t.Errorf("lockouts = %v, want 1", got)
+	}
+
+	// Другой IP и другой пользователь не затронуты
+	if code := login(router, "10.0.0.2", "ivan", "secret").Code; code != http.StatusOK {
+		t.Errorf("other IP: status = %d, want 200", code)
+	}
+	if code := login(router, "10.0.0.1", "petr", "secret").Code; code != http.StatusOK {
+		t.Errorf("other user: status = %d, want 200", code)
+	}
+
+	// Блокировка истекает
+	server.FastForward(time.Minute)
+	if code := login(router, "10.0.0.1", "ivan", "secret").Code; code != http.StatusOK {
+		t.Errorf("after lockout: status = %d, want 200", code)
+	}
+}
+
+func TestLoginProtectionResetsOnSuccess(t *testing.T) {
+	router, _, _ := newLoginRouter(t)
+
+	login(router, "10.0.0.1", "ivan", "wrong")
+	login(router, "10.0.0.1", "ivan", "wrong")
+	login(router, "10.0.0.1", "ivan", "secret")
+	login(router, "10.0.0.1", "ivan", "wrong")
+	login(router, "10.0.0.1", "ivan", "wrong")
+
+	if code := login(router, "10.0.0.1", "ivan", "secret").Code; code != http.StatusOK {
+		t.Errorf("status = %d, want 200: counter must reset after success", code)
+	}
+}
+
+func TestLoginProtectionClearLockout(t *testing.T) {
+	router, protector, _ := newLoginRouter(t)
+
+	for i := 0; i < 3; i++ {
+		login(router, "10.0.0.1", "ivan", "wrong")
+	}
+	if err := protector.ClearLockout(context.Background(), "ivan", "10.0.0.1"); err != nil {
+		t.Fatalf("ClearLockout() error = %v", err)
+	}
+	if code := login(router, "10.0.0.1", "ivan", "secret").Code; code != http.StatusOK {
+		t.Errorf("status = %d, want 200 after ClearLockout", code)
+	}
+}
+
+func TestUsernameFromBodyForm(t *testing.T) {
+	router, _, _ := newLoginRouter(t)
+
+	for i := 0; i < 3; i++ {
+		request := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader("username=ivan&password=wrong"))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.RemoteAddr = "10.0.0.1:1234"
+		router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	if code := login(router, "10.0.0.1", "ivan", "secret").Code; code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want 429 for form login failures", code)
+	}
+}
+
+func TestLoginProtectionReservesAttemptsBeforeHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := miniredis.RunT(t)
+	client, err := redis.NewClient(server.Addr(), "", 0, nil, nil)
+	if err != nil {
+		t.Fatalf("redis.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	options := DefaultLoginProtectionOptions()
+	options.MaxFailures = 3
+	options.Registerer = prometheus.NewRegistry()
+	protector := NewLoginProtector(client, options)
+
+	// Обработчик держит запросы, пока все параллельные попытки не пройдут middleware
+	var handled atomic.Int32
+	release := make(chan struct{})
+	router := gin.New()
+	router.POST("/login", protector.Middleware(), func(c *gin.Context) {
+		handled.Add(1)
+		<-release
+		LoginFailed(c)
+		c.Status(http.StatusUnauthorized)
+	})
+
+	const attempts = 10
+	codes := make(chan int, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- login(router, "10.0.0.1", "ivan", "wrong").Code
+		}()
+	}
+
+	// Лишние попытки отклоняются, не дожидаясь завершения зарезервированных
+	rejected := 0
+	for rejected < attempts-options.MaxFailures {
+		select {
+		case code := <-codes:
+			if code != http.StatusTooManyRequests {
+				t.Fatalf("status = %d before handlers finished, want 429", code)
+			}
+			rejected++
+		case <-time.After(5 * time.Second):
+			t.Fatalf("rejected = %d, want %d", rejected, attempts-options.MaxFailures)
+		}
+	}
+	close(release)
+	wg.Wait()
+	close(codes)
+
+	for code := range codes {
+		if code != http.StatusUnauthorized {
+			t.Errorf("reserved attempt status = %d, want 401", code)
+		}
+	}
+	if got := handled.Load(); got != int32(options.MaxFailures) {
+		t.Errorf("handler calls = %d, want %d", got, options.MaxFailures)
+	}
+	if got := testutil.ToFloat64(protector.lockouts); got != 1 {
+		t.Errorf("lockouts = %v, want 1", got)
+	}
+	if code := login(router, "10.0.0.1", "ivan", "secret").Code; code != http.StatusTooManyRequests {
+		t.Errorf("after concurrent failures: status = %d, want 429", code)
+	}
+}
+
+func TestLoginProtectionReleasesUnmarkedAttempts(t *testing.T) {
+	router, _, _ := newLoginRouter(t)
+
+	// Некорректный запрос не отмечает результат и не расходует лимит
+	for i := 0; i < 5; i++ {
+		request := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"ivan","password":`))
+		request.Header.Set("Content-Type", "application/json")
+		request.RemoteAddr = "10.0.0.1:1234"
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("malformed attempt %d: status = %d, want 400", i+1, recorder.Code)
+		}
+	}
+
+	if code := login(router, "10.0.0.1", "ivan", "secret").Code; code != http.StatusOK {
+		t.Errorf("status = %d, want 200: unmarked attempts must not count", code)
+	}
+}
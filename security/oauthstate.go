@@ -0,0 +1,56 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthStateCookie имя cookie, в которой хранится state OAuth авторизации
+const OAuthStateCookie = "oauth_state"
+
+// ErrStateMismatch state из callback не совпадает с выданным
+var ErrStateMismatch = errors.New("oauth state mismatch")
+
+// GenerateState генерирует случайный state токен для защиты OAuth flow от CSRF
+func GenerateState() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate state: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// IssueState генерирует state, сохраняет его в зашифрованной cookie на StateTTL
+// и возвращает значение для передачи провайдеру в параметре state
+func (s *SecureCookie) IssueState(c *gin.Context) (string, error) {
+	state, err := GenerateState()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.set(c, OAuthStateCookie, state, s.options.StateTTL); err != nil {
+		return "", err
+	}
+	return state, nil
+}
+
+// VerifyState сравнивает state из callback с сохраненным в cookie. Cookie удаляется
+// при любом результате, поэтому state нельзя использовать повторно.
+func (s *SecureCookie) VerifyState(c *gin.Context, state string) error {
+	var expected string
+	err := s.Get(c, OAuthStateCookie, &expected)
+	s.Clear(c, OAuthStateCookie)
+	if err != nil {
+		return err
+	}
+
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(expected)) != 1 {
+		return ErrStateMismatch
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/config"
+)
+
+var (
+	// ErrCookieNotFound cookie отсутствует в запросе
+	ErrCookieNotFound = errors.New("cookie not found")
+	// ErrCookieInvalid cookie повреждена, подделана или зашифрована для другого имени
+	ErrCookieInvalid = errors.New("invalid cookie")
+	// ErrCookieExpired срок действия cookie истек
+	ErrCookieExpired = errors.New("cookie expired")
+	// ErrCookieUnknownKey cookie зашифрована ключом, которого нет в списке ключей
+	ErrCookieUnknownKey = errors.New("cookie encrypted with unknown key")
+)
+
+// CookieKey ключ шифрования cookie. ID сохраняется в cookie и позволяет
+// расшифровывать значения, выпущенные до ротации ключа.
+type CookieKey struct {
+	ID     string
+	Secret []byte
+}
+
+// CookieKeysFromEnv читает ключи из переменной окружения или файла в формате
+// "id1:base64key,id2:base64key". Первый ключ используется для шифрования,
+// остальные только для расшифровки.
+func CookieKeysFromEnv(envKey, fileEnvKey string) ([]CookieKey, error) {
+	raw := config.GetSecretFromEnvOrFile(envKey, fileEnvKey, "")
+	if raw == "" {
+		return nil, fmt.Errorf("cookie keys are not configured: %s", envKey)
+	}
+
+	var keys []CookieKey
+	for _, part := range strings.Split(raw, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid cookie key %q: expected id:base64key", part)
+		}
+
+		secret, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookie key %s: %v", id, err)
+		}
+		keys = append(keys, CookieKey{ID: id, Secret: secret})
+	}
+
+	return keys, nil
+}
+
+// SecureCookieOptions содержит атрибуты и время жизни cookie
+type SecureCookieOptions struct {
+	Path     string
+	Domain   string
+	MaxAge   time.Duration
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+	// StateTTL время жизни cookie с OAuth state
+	StateTTL time.Duration
+}
+
+// DefaultSecureCookieOptions возвращает опции по умолчанию
+func DefaultSecureCookieOptions() *SecureCookieOptions {
+	return &SecureCookieOptions{
+		Path:     "/",
+		MaxAge:   24 * time.Hour,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		StateTTL: 10 * time.Minute,
+	}
+}
+
+// SecureCookie шифрует и аутентифицирует значения cookie с помощью AES-GCM
+type SecureCookie struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+	options *SecureCookieOptions
+	now     func() time.Time
+}
+
+// cookiePayload содержимое cookie до шифрования
+type cookiePayload struct {
+	Value     json.RawMessage `json:"v"`
+	ExpiresAt int64           `json:"exp"`
+}
+
+// NewSecureCookie создает SecureCookie. Первый ключ используется для шифрования,
+// все ключи для расшифровки. Размер ключа 16, 24 или 32 байта.
+func NewSecureCookie(keys []CookieKey, options *SecureCookieOptions) (*SecureCookie, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one cookie key is required")
+	}
+	if options == nil {
+		options = DefaultSecureCookieOptions()
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(keys))
+	for _, key := range keys {
+		if _, exists := aeads[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate cookie key id %s", key.ID)
+		}
+
+		block, err := aes.NewCipher(key.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookie key %s: %v", key.ID, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookie key %s: %v", key.ID, err)
+		}
+		aeads[key.ID] = aead
+	}
+
+	return &SecureCookie{
+		primary: keys[0].ID,
+		aeads:   aeads,
+		options: options,
+		now:     time.Now,
+	}, nil
+}
+
+// Encode сериализует value в JSON и шифрует его. Имя cookie участвует в аутентификации,
+// поэтому значение нельзя подставить в cookie с другим именем.
+func (s *SecureCookie) Encode(name string, value interface{}) (string, error) {
+	return s.encode(name, value, s.options.MaxAge)
+}
+
+func (s *SecureCookie) encode(name string, value interface{}, ttl time.Duration) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cookie %s: %v", name, err)
+	}
+
+	plaintext, err := json.Marshal(cookiePayload{Value: data, ExpiresAt: s.now().Add(ttl).Unix()})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cookie %s: %v", name, err)
+	}
+
+	aead := s.aeads[s.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %v", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(name))
+
+	return base64.RawURLEncoding.EncodeToString([]byte(s.primary)) + "." +
+		base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decode расшифровывает значение cookie и десериализует его в dst
+func (s *SecureCookie) Decode(name, encoded string, dst interface{}) error {
+	encodedID, encodedData, ok := strings.Cut(encoded, ".")
+	if !ok {
+		return ErrCookieInvalid
+	}
+
+	id, err := base64.RawURLEncoding.DecodeString(encodedID)
+	if err != nil {
+		return ErrCookieInvalid
+	}
+	aead, ok := s.aeads[string(id)]
+	if !ok {
+		return ErrCookieUnknownKey
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(encodedData)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return ErrCookieInvalid
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return ErrCookieInvalid
+	}
+
+	var payload cookiePayload
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return ErrCookieInvalid
+	}
+	if s.now().Unix() >= payload.ExpiresAt {
+		return ErrCookieExpired
+	}
+
+	if err := json.Unmarshal(payload.Value, dst); err != nil {
+		return fmt.Errorf("failed to unmarshal cookie %s: %v", name, err)
+	}
+	return nil
+}
+
+// Set шифрует value и устанавливает cookie в ответ
+func (s *SecureCookie) Set(c *gin.Context, name string, value interface{}) error {
+	return s.set(c, name, value, s.options.MaxAge)
+}
+
+func (s *SecureCookie) set(c *gin.Context, name string, value interface{}, ttl time.Duration) error {
+	encoded, err := s.encode(name, value, ttl)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(c.Writer, s.cookie(name, encoded, int(ttl.Seconds())))
+	return nil
+}
+
+// Get читает и расшифровывает cookie из запроса в dst
+func (s *SecureCookie) Get(c *gin.Context, name string, dst interface{}) error {
+	cookie, err := c.Request.Cookie(name)
+	if err != nil {
+		return ErrCookieNotFound
+	}
+	return s.Decode(name, cookie.Value, dst)
+}
+
+// Clear удаляет cookie в браузере
+func (s *SecureCookie) Clear(c *gin.Context, name string) {
+	http.SetCookie(c.Writer, s.cookie(name, "", -1))
+}
+
+func (s *SecureCookie) cookie(name, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     s.options.Path,
+		Domain:   s.options.Domain,
+		MaxAge:   maxAge,
+		Secure:   s.options.Secure,
+		HttpOnly: s.options.HttpOnly,
+		SameSite: s.options.SameSite,
+	}
+}
+
+// Session типизированное содержимое cookie, доступное обработчикам через SessionFromContext
+type Session[T any] struct {
+	// Value значение cookie; нулевое, если cookie отсутствует или недействительна
+	Value T
+	// Exists признак того, что cookie была передана и успешно расшифрована
+	Exists bool
+
+	name   string
+	cookie *SecureCookie
+}
+
+// Save шифрует текущее значение и устанавливает cookie. Вызывается до записи тела ответа.
+func (s *Session[T]) Save(c *gin.Context) error {
+	return s.cookie.Set(c, s.name, s.Value)
+}
+
+// Clear удаляет cookie и сбрасывает значение
+func (s *Session[T]) Clear(c *gin.Context) {
+	var zero T
+	s.Value = zero
+	s.Exists = false
+	s.cookie.Clear(c, s.name)
+}
+
+// sessionContextKey ключ сессии в контексте gin
+func sessionContextKey(name string) string {
+	return "securecookie." + name
+}
+
+// SessionMiddleware расшифровывает cookie name в Session[T] и сохраняет ее в контексте.
+// Недействительная или просроченная cookie не прерывает запрос: обработчик получает пустую сессию.
+func SessionMiddleware[T any](cookie *SecureCookie, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session := &Session[T]{name: name, cookie: cookie}
+		if err := cookie.Get(c, name, &session.Value); err == nil {
+			session.Exists = true
+		} else {
+			var zero T
+			session.Value = zero
+		}
+
+		c.Set(sessionContextKey(name), session)
+		c.Next()
+	}
+}
+
+// SessionFromContext возвращает сессию, установленную SessionMiddleware
+func SessionFromContext[T any](c *gin.Context, name string) (*Session[T], bool) {
+	value, exists := c.Get(sessionContextKey(name))
+	if !exists {
+		return nil, false
+	}
+	session, ok := value.(*Session[T])
+	return session, ok
+}
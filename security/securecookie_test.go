@@ -0,0 +1,248 @@
+package security
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testPayload struct {
+	UserID uint   `json:"user_id"`
+	Return string `json:"return"`
+}
+
+func testKey(id string, b byte) CookieKey {
+	return CookieKey{ID: id, Secret: []byte(strings.Repeat(string(b), 32))}
+}
+
+func newTestCookie(t *testing.T, keys ...CookieKey) *SecureCookie {
+	t.Helper()
+	sc, err := NewSecureCookie(keys, nil)
+	if err != nil {
+		t.Fatalf("NewSecureCookie() error = %v", err)
+	}
+	return sc
+}
+
+func TestSecureCookieRoundTrip(t *testing.T) {
+	sc := newTestCookie(t, testKey("k1", 'a'))
+
+	encoded, err := sc.Encode("session", testPayload{UserID: 42, Return: "/orders"})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	var got testPayload
+	if err := sc.Decode("session", encoded, &got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got.UserID != 42 || got.Return != "/orders" {
+		t.Errorf("Decode() = %+v", got)
+	}
+}
+
+func TestSecureCookieTampering(t *testing.T) {
+	sc := newTestCookie(t, testKey("k1", 'a'))
+
+	encoded, err := sc.Encode("session", testPayload{UserID: 42})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	id, data, _ := strings.Cut(encoded, ".")
+	sealed, _ := base64.RawURLEncoding.DecodeString(data)
+	sealed[len(sealed)-1] ^= 0x01
+	flipped := id + "." + base64.RawURLEncoding.EncodeToString(sealed)
+
+	tests := []struct {
+		name    string
+		cookie  string
+		value   string
+		wantErr error
+	}{
+		{"flipped bit", "session", flipped, ErrCookieInvalid},
+		{"other cookie name", "admin", encoded, ErrCookieInvalid},
+		{"garbage", "session", "not-a-cookie", ErrCookieInvalid},
+		{"truncated", "session", encoded[:len(encoded)-10], ErrCookieInvalid},
+		{"forged key id", "session", base64.RawURLEncoding.EncodeToString([]byte("k9")) + "." + data, ErrCookieUnknownKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testPayload
+			if err := sc.Decode(tt.cookie, tt.value, &got); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSecureCookieExpiry(t *testing.T) {
+	sc := newTestCookie(t, testKey("k1", 'a'))
+	now := time.Now()
+	sc.now = func() time.Time { return now }
+
+	encoded, err := sc.Encode("session", testPayload{UserID: 1})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	sc.now = func() time.Time { return now.Add(25 * time.Hour) }
+
+	var got testPayload
+	if err := sc.Decode("session", encoded, &got); !errors.Is(err, ErrCookieExpired) {
+		t.Errorf("Decode() error = %v, want ErrCookieExpired", err)
+	}
+}
+
+func TestSecureCookieKeyRotation(t *testing.T) {
+	oldKey, newKey := testKey("2024-01", 'a'), testKey("2024-06", 'b')
+
+	encoded, err := newTestCookie(t, oldKey).Encode("session", testPayload{UserID: 7})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	// После ротации старые cookie расшифровываются, новые шифруются новым ключом
+	rotated := newTestCookie(t, newKey, oldKey)
+	var got testPayload
+	if err := rotated.Decode("session", encoded, &got); err != nil || got.UserID != 7 {
+		t.Fatalf("Decode() = %+v, %v", got, err)
+	}
+
+	reissued, err := rotated.Encode("session", got)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if err := newTestCookie(t, newKey).Decode("session", reissued, &got); err != nil {
+		t.Errorf("Decode() with new key error = %v", err)
+	}
+
+	// После удаления старого ключа выпущенные им cookie недействительны
+	if err := newTestCookie(t, newKey).Decode("session", encoded, &got); !errors.Is(err, ErrCookieUnknownKey) {
+		t.Errorf("Decode() error = %v, want ErrCookieUnknownKey", err)
+	}
+}
+
+func TestCookieKeysFromEnv(t *testing.T) {
+	secret := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("x", 32)))
+	t.Setenv("TEST_COOKIE_KEYS", "new:"+secret+", old:"+secret)
+
+	keys, err := CookieKeysFromEnv("TEST_COOKIE_KEYS", "")
+	if err != nil {
+		t.Fatalf("CookieKeysFromEnv() error = %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != "new" || keys[1].ID != "old" {
+		t.Errorf("keys = %+v", keys)
+	}
+
+	t.Setenv("TEST_COOKIE_KEYS", "no-separator")
+	if _, err := CookieKeysFromEnv("TEST_COOKIE_KEYS", ""); err == nil {
+		t.Error("CookieKeysFromEnv() expected error for malformed value")
+	}
+}
+
+func TestSessionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sc := newTestCookie(t, testKey("k1", 'a'))
+
+	router := gin.New()
+	router.Use(SessionMiddleware[testPayload](sc, "session"))
+	router.POST("/login", func(c *gin.Context) {
+		session, _ := SessionFromContext[testPayload](c, "session")
+		session.Value.UserID = 42
+		if err := session.Save(c); err != nil {
+			t.Fatal(err)
+		}
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/me", func(c *gin.Context) {
+		session, ok := SessionFromContext[testPayload](c, "session")
+		if !ok || !session.Exists {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		c.JSON(http.StatusOK, session.Value)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/login", nil))
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].HttpOnly || !cookies[0].Secure || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Fatalf("cookies = %+v", cookies)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"user_id":42`) {
+		t.Errorf("response = %d %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "forged"})
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("forged cookie status = %d, want 401", rec.Code)
+	}
+}
+
+func TestOAuthState(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sc := newTestCookie(t, testKey("k1", 'a'))
+
+	var issued string
+	router := gin.New()
+	router.GET("/login", func(c *gin.Context) {
+		state, err := sc.IssueState(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		issued = state
+		c.Redirect(http.StatusFound, "https://provider.example.com/authorize?state="+state)
+	})
+	router.GET("/callback", func(c *gin.Context) {
+		if err := sc.VerifyState(c, c.Query("state")); err != nil {
+			c.String(http.StatusForbidden, err.Error())
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login", nil))
+	stateCookie := rec.Result().Cookies()[0]
+	if stateCookie.MaxAge != int((10 * time.Minute).Seconds()) {
+		t.Errorf("state cookie MaxAge = %d", stateCookie.MaxAge)
+	}
+
+	callback := func(state string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/callback?state="+state, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := callback(issued, stateCookie); rec.Code != http.StatusOK {
+		t.Errorf("valid state status = %d: %s", rec.Code, rec.Body.String())
+	} else if cleared := rec.Result().Cookies(); len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+		t.Errorf("state cookie is not cleared: %+v", cleared)
+	}
+	if rec := callback("attacker-state", stateCookie); rec.Code != http.StatusForbidden {
+		t.Errorf("mismatched state status = %d, want 403", rec.Code)
+	}
+	if rec := callback(issued, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("missing cookie status = %d, want 403", rec.Code)
+	}
+}
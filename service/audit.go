@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/vladzorgan/common/auth"
+	"github.com/vladzorgan/common/database"
+	apperrors "github.com/vladzorgan/common/errors"
+	"github.com/vladzorgan/common/logging"
+)
+
+// Действия, записываемые в журнал аудита
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditSystemActor автор изменений, выполненных без пользователя в контексте (фоновые задачи, потребители событий)
+const AuditSystemActor = "system"
+
+// AuditLogger записывает изменения сущностей. Вызывается в транзакции операции:
+// ошибка записи отменяет изменение. before равен nil при создании, after - при удалении;
+// actor равен nil, если пользователя нет в контексте.
+type AuditLogger interface {
+	RecordChange(ctx context.Context, entityName string, entityID uint, action string, before, after map[string]interface{}, actor *auth.User) error
+}
+
+// WithAuditLogger включает журнал аудита для Create, CreateOrUpdate, BulkCreate, Update, BulkUpdate и Delete.
+// Снимки сущности строятся по ее JSON представлению, поля с тегом json:"-" в журнал не попадают.
+// Update и BulkUpdate дополнительно читают состояние сущностей до обновления.
+func (s *BaseService[T, R]) WithAuditLogger(logger AuditLogger) *BaseService[T, R] {
+	s.audit = logger
+	return s
+}
+
+// recordAudit записывает изменение сущности в журнал аудита, если он включен
+func (s *BaseService[T, R]) recordAudit(ctx context.Context, action string, id uint, before, after *T) error {
+	if s.audit == nil {
+		return nil
+	}
+
+	beforeSnapshot, err := entitySnapshot(before)
+	if err != nil {
+		return apperrors.Wrap(apperrors.ErrInternal, s.entity.Singular, id, err, "не удалось записать изменение в журнал аудита")
+	}
+	afterSnapshot, err := entitySnapshot(after)
+	if err != nil {
+		return apperrors.Wrap(apperrors.ErrInternal, s.entity.Singular, id, err, "не удалось записать изменение в журнал аудита")
+	}
+
+	// Отсутствие пользователя означает системное изменение
+	actor, _ := auth.GetUserFromContext(ctx)
+
+	if err := s.audit.RecordChange(ctx, s.entity.Singular, id, action, beforeSnapshot, afterSnapshot, actor); err != nil {
+		return apperrors.Wrap(apperrors.ErrInternal, s.entity.Singular, id, err, "не удалось записать изменение в журнал аудита")
+	}
+
+	return nil
+}
+
+// entitySnapshot возвращает поля сущности в виде JSON объекта или nil для отсутствующей сущности
+func entitySnapshot[T any](entity *T) (map[string]interface{}, error) {
+	if entity == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal entity snapshot: %w", err)
+	}
+
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal entity snapshot: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// AuditSnapshot снимок полей сущности, хранимый в JSONB
+type AuditSnapshot map[string]interface{}
+
+// Value сериализует снимок в JSON (nil - NULL)
+func (s AuditSnapshot) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan читает снимок из JSON
+func (s *AuditSnapshot) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported audit snapshot type %T", value)
+	}
+
+	return json.Unmarshal(data, s)
+}
+
+// AuditLog запись журнала аудита
+type AuditLog struct {
+	ID         uint          `gorm:"primaryKey" json:"id"`
+	EntityName string        `gorm:"size:100;not null;index:idx_audit_logs_entity" json:"entity_name"`
+	EntityID   uint          `gorm:"not null;index:idx_audit_logs_entity" json:"entity_id"`
+	Action     string        `gorm:"size:20;not null" json:"action"`
+	Before     AuditSnapshot `gorm:"type:jsonb" json:"before"`
+	After      AuditSnapshot `gorm:"type:jsonb" json:"after"`
+	// ID пользователя; пустой для системных изменений
+	ActorID *uint `gorm:"index" json:"actor_id"`
+	// Имя пользователя или AuditSystemActor
+	Actor     string    `gorm:"size:255;not null" json:"actor"`
+	RequestID string    `gorm:"size:64" json:"request_id,omitempty"`
+	CreatedAt time.Time `gorm:"not null;index" json:"created_at"`
+}
+
+// TableName возвращает имя таблицы журнала аудита
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// GormAuditLogger записывает журнал аудита в таблицу audit_logs.
+// Если в контексте есть транзакция, запись выполняется в ней.
+type GormAuditLogger struct {
+	db *database.Database
+}
+
+// NewGormAuditLogger создает журнал аудита в базе данных
+func NewGormAuditLogger(db *database.Database) *GormAuditLogger {
+	return &GormAuditLogger{db: db}
+}
+
+// Migrate создает таблицу audit_logs
+func (l *GormAuditLogger) Migrate() error {
+	return l.db.AutoMigrate(&AuditLog{})
+}
+
+// RecordChange записывает изменение сущности
+func (l *GormAuditLogger) RecordChange(ctx context.Context, entityName string, entityID uint, action string, before, after map[string]interface{}, actor *auth.User) error {
+	record := &AuditLog{
+		EntityName: entityName,
+		EntityID:   entityID,
+		Action:     action,
+		Before:     before,
+		After:      after,
+		Actor:      AuditSystemActor,
+		RequestID:  logging.ExtractRequestID(ctx),
+	}
+	if actor != nil {
+		id := actor.ID
+		record.ActorID = &id
+		record.Actor = actor.Username
+		if record.Actor == "" {
+			record.Actor = "user:" + strconv.FormatUint(uint64(actor.ID), 10)
+		}
+	}
+
+	db, ok := database.TransactionFromContext(ctx)
+	if !ok {
+		db = l.db.GetDB()
+	}
+
+	if err := db.WithContext(ctx).Create(record).Error; err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/vladzorgan/common/auth"
+	"github.com/vladzorgan/common/database"
+	apperrors "github.com/vladzorgan/common/errors"
+	"github.com/vladzorgan/common/repository"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type auditEntity struct {
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	Secret string `json:"-"`
+}
+
+func (e auditEntity) GetID() uint          { return e.ID }
+func (e auditEntity) GetName() string      { return e.Name }
+func (e auditEntity) GetTableName() string { return "audit_entities" }
+
+type auditTransformer struct{}
+
+func (auditTransformer) Transform(entity *auditEntity) *auditEntity { return entity }
+func (auditTransformer) TransformSlice(entities []auditEntity) []auditEntity {
+	return entities
+}
+
+// memoryRepository хранит сущности в памяти; неиспользуемые методы не реализованы
+type memoryRepository struct {
+	repository.Repository[auditEntity]
+	items map[uint]auditEntity
+}
+
+func (r *memoryRepository) GetByID(ctx context.Context, id uint, opts ...repository.QueryOption) (*auditEntity, error) {
+	if entity, ok := r.items[id]; ok {
+		return &entity, nil
+	}
+	return nil, nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, id uint, updates map[string]interface{}) (*auditEntity, error) {
+	entity, ok := r.items[id]
+	if !ok {
+		return nil, nil
+	}
+	entity.Name = updates["name"].(string)
+	r.items[id] = entity
+	return &entity, nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id uint) (*auditEntity, error) {
+	entity, ok := r.items[id]
+	if !ok {
+		return nil, nil
+	}
+	delete(r.items, id)
+	return &entity, nil
+}
+
+func (r *memoryRepository) GetByIDs(ctx context.Context, ids []uint, opts ...repository.QueryOption) ([]auditEntity, error) {
+	entities := make([]auditEntity, 0, len(ids))
+	for _, id := range ids {
+		if entity, ok := r.items[id]; ok {
+			entities = append(entities, entity)
+		}
+	}
+	return entities, nil
+}
+
+func (r *memoryRepository) DeleteMany(ctx context.Context, ids []uint) (int64, error) {
+	var deleted int64
+	for _, id := range ids {
+		if _, ok := r.items[id]; ok {
+			delete(r.items, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+type auditUpdate struct{ name string }
+
+func (u auditUpdate) ToUpdateMap() map[string]interface{} {
+	return map[string]interface{}{"name": u.name}
+}
+func (u auditUpdate) Validate() error { return nil }
+
+type auditRecord struct {
+	entityName string
+	entityID   uint
+	action     string
+	before     map[string]interface{}
+	after      map[string]interface{}
+	actor      *auth.User
+}
+
+type recordingAuditLogger struct {
+	records []auditRecord
+	err     error
+}
+
+func (l *recordingAuditLogger) RecordChange(ctx context.Context, entityName string, entityID uint, action string, before, after map[string]interface{}, actor *auth.User) error {
+	l.records = append(l.records, auditRecord{entityName, entityID, action, before, after, actor})
+	return l.err
+}
+
+func newAuditService(logger AuditLogger) (*BaseService[auditEntity, auditEntity], *memoryRepository) {
+	repo := &memoryRepository{items: map[uint]auditEntity{1: {ID: 1, Name: "old", Secret: "s3cr3t"}}}
+	s := NewBaseService[auditEntity, auditEntity](repo, auditTransformer{}, nil, "audit_entity").WithAuditLogger(logger)
+	return s, repo
+}
+
+func TestAuditUpdateRecordsSnapshotsAndActor(t *testing.T) {
+	logger := &recordingAuditLogger{}
+	s, _ := newAuditService(logger)
+
+	ctx := auth.WithUser(context.Background(), &auth.User{ID: 7, Username: "ivan"})
+	if _, err := s.Update(ctx, 1, auditUpdate{name: "new"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if len(logger.records) != 1 {
+		t.Fatalf("records = %d, want 1", len(logger.records))
+	}
+	record := logger.records[0]
+	if record.entityName != "audit_entity" || record.entityID != 1 || record.action != AuditActionUpdate {
+		t.Errorf("record = %+v", record)
+	}
+	if record.before["name"] != "old" || record.after["name"] != "new" {
+		t.Errorf("before = %v, after = %v", record.before, record.after)
+	}
+	if _, ok := record.before["Secret"]; ok {
+		t.Error("fields excluded from JSON must not be recorded")
+	}
+	if record.actor == nil || record.actor.ID != 7 {
+		t.Errorf("actor = %+v, want user 7", record.actor)
+	}
+}
+
+func TestAuditDeleteWithoutUser(t *testing.T) {
+	logger := &recordingAuditLogger{}
+	s, _ := newAuditService(logger)
+
+	if _, err := s.Delete(context.Background(), 1); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	record := logger.records[0]
+	if record.action != AuditActionDelete || record.before["name"] != "old" || record.after != nil || record.actor != nil {
+		t.Errorf("record = %+v", record)
+	}
+}
+
+func TestAuditFailureAbortsOperation(t *testing.T) {
+	logger := &recordingAuditLogger{err: errors.New("audit table unavailable")}
+	s, _ := newAuditService(logger)
+
+	_, err := s.Update(context.Background(), 1, auditUpdate{name: "new"})
+	if !apperrors.IsInternal(err) {
+		t.Errorf("Update() error = %v, want internal error", err)
+	}
+}
+
+func TestGormAuditLoggerWritesInContextTransaction(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	var sql string
+	db.Callback().Create().After("gorm:create").Register("test:capture", func(tx *gorm.DB) {
+		sql = tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)
+	})
+
+	// Соединение не задано: запись должна выполняться в транзакции из контекста
+	logger := NewGormAuditLogger(nil)
+	ctx := database.WithTransaction(context.Background(), db)
+
+	err = logger.RecordChange(ctx, "order", 5, AuditActionUpdate,
+		map[string]interface{}{"status": "new"}, map[string]interface{}{"status": "paid"}, nil)
+	if err != nil {
+		t.Fatalf("RecordChange() error = %v", err)
+	}
+
+	for _, want := range []string{`INSERT INTO "audit_logs"`, `'{"status":"new"}'`, `'{"status":"paid"}'`, `'system'`} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("SQL %s does not contain %s", sql, want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+
+	"github.com/vladzorgan/common/repository"
+)
+
+// DeleteMany удаляет сущности с указанными ID одним запросом в транзакции сервиса.
+// Политика удаления (WithDeletePolicy) применяется к каждой найденной сущности; хуки Delete не вызываются.
+// После фиксации публикуется событие bulk_deleted с ID удаленных сущностей и вызываются обработчики AfterBulkDelete.
+// Возвращает количество удаленных записей.
+func (s *BaseService[T, R]) DeleteMany(ctx context.Context, ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, validationError(s.entity.Singular, stderrors.New("список ID для удаления пуст"))
+	}
+
+	var deleted int64
+	var deletedEntities []*T
+	err := s.runWrite(ctx, func(ctx context.Context, repo repository.Repository[T], pending *[]pendingEvent) error {
+		// Сущности до удаления нужны для проверок, журнала аудита и события
+		entities, err := repo.GetByIDs(ctx, ids)
+		if err != nil {
+			return s.wrapRepoError(err, nil, fmt.Sprintf("ошибка при получении списка %s", s.entity.DisplayNameRu))
+		}
+
+		deleted, deletedEntities, err = s.deleteEntities(ctx, entities, pending, func(foundIDs []uint) (int64, error) {
+			return repo.DeleteMany(ctx, foundIDs)
+		})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("Удалено %d %s", deleted, s.entity.DisplayNameRu)
+	s.runAfterBulkDeleteHooks(ctx, deletedEntities)
+	return deleted, nil
+}
+
+// DeleteByFilter удаляет сущности, соответствующие фильтрам и условиям Scope, одним запросом в транзакции сервиса.
+// Удаляются найденные по фильтрам записи по их ID, поэтому записи, добавленные параллельно,
+// не удаляются без проверок, журнала аудита и события.
+// Без фильтров возвращает ошибку валидации, если не передана опция repository.AllowDeleteAll.
+// Политика удаления, событие bulk_deleted, журнал аудита и обработчики AfterBulkDelete - как в DeleteMany.
+func (s *BaseService[T, R]) DeleteByFilter(ctx context.Context, filters map[string]interface{}, opts ...repository.QueryOption) (int64, error) {
+	if err := repository.CheckDeleteFilter(filters, opts...); err != nil {
+		return 0, validationError(s.entity.Singular, err)
+	}
+
+	var deleted int64
+	var deletedEntities []*T
+	err := s.runWrite(ctx, func(ctx context.Context, repo repository.Repository[T], pending *[]pendingEvent) error {
+		// Отрицательный лимит снимает ограничение количества записей
+		entities, _, err := repo.GetAll(ctx, 0, -1, filters, nil, opts...)
+		if err != nil {
+			return s.wrapRepoError(err, nil, fmt.Sprintf("ошибка при получении списка %s", s.entity.DisplayNameRu))
+		}
+
+		deleted, deletedEntities, err = s.deleteEntities(ctx, entities, pending, func(foundIDs []uint) (int64, error) {
+			return repo.DeleteMany(ctx, foundIDs)
+		})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("Удалено %d %s", deleted, s.entity.DisplayNameRu)
+	s.runAfterBulkDeleteHooks(ctx, deletedEntities)
+	return deleted, nil
+}
+
+// deleteEntities проверяет зависимости и выполняет каскадные действия для entities, удаляет их через
+// remove и добавляет в журнал аудита и событие bulk_deleted. Возвращает количество удаленных записей
+// и удаленные сущности. Вызывается внутри runWrite.
+func (s *BaseService[T, R]) deleteEntities(ctx context.Context, entities []T, pending *[]pendingEvent, remove func(ids []uint) (int64, error)) (int64, []*T, error) {
+	if len(entities) == 0 {
+		return 0, nil, nil
+	}
+
+	ids := make([]uint, 0, len(entities))
+	deletedEntities := make([]*T, 0, len(entities))
+	for i := range entities {
+		ids = append(ids, entities[i].GetID())
+		deletedEntities = append(deletedEntities, &entities[i])
+	}
+
+	// Проверяем зависимости и удаляем дочерние сущности
+	if err := s.CheckDelete(ctx, ids...); err != nil {
+		return 0, nil, err
+	}
+	for _, id := range ids {
+		if err := s.runCascades(ctx, id); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	deleted, err := remove(ids)
+	if err != nil {
+		return 0, nil, s.wrapRepoError(err, nil, fmt.Sprintf("не удалось удалить %s", s.entity.DisplayNameRu))
+	}
+
+	for _, entity := range deletedEntities {
+		if err := s.recordAudit(ctx, AuditActionDelete, (*entity).GetID(), entity, nil); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	// Публикуем событие о массовом удалении после фиксации
+	if event, ok := s.bulkEvent("bulk_deleted", deletedEntities); ok {
+		*pending = append(*pending, event)
+	}
+	return deleted, deletedEntities, nil
+}
+
+// runAfterBulkDeleteHooks вызывает обработчики AfterBulkDelete, если что-то было удалено
+func (s *BaseService[T, R]) runAfterBulkDeleteHooks(ctx context.Context, entities []*T) {
+	if len(entities) == 0 {
+		return
+	}
+	s.runAfterHooks(ctx, "AfterBulkDelete", len(s.hooks.afterBulkDelete), func(ctx context.Context, i int) error {
+		return s.hooks.afterBulkDelete[i](ctx, entities)
+	})
+}
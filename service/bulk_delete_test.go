@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	apperrors "github.com/vladzorgan/common/errors"
+	"github.com/vladzorgan/common/repository"
+)
+
+func TestDeleteManyPublishesBulkDeleted(t *testing.T) {
+	publisher := &recordingPublisher{}
+	repo := &memoryRepository{items: map[uint]auditEntity{1: {ID: 1, Name: "first"}, 2: {ID: 2, Name: "second"}}}
+	s := NewBaseService[auditEntity, auditEntity](repo, auditTransformer{}, publisher, "audit_entity")
+
+	deleted, err := s.DeleteMany(context.Background(), []uint{1, 2, 3})
+	if err != nil {
+		t.Fatalf("DeleteMany() error = %v", err)
+	}
+	if deleted != 2 || len(repo.items) != 0 {
+		t.Errorf("deleted = %d, remaining = %v", deleted, repo.items)
+	}
+
+	if len(publisher.keys) != 1 || publisher.keys[0] != s.routingKey("bulk_deleted") {
+		t.Fatalf("published = %v, want [%s]", publisher.keys, s.routingKey("bulk_deleted"))
+	}
+	event := publisher.payloads[0].(BulkEvent)
+	if len(event.IDs) != 2 || event.IDs[0] != 1 || event.IDs[1] != 2 || event.EventType != "bulk_deleted" {
+		t.Errorf("event = %+v", event)
+	}
+}
+
+func TestBulkDeleteValidatesInput(t *testing.T) {
+	repo := &memoryRepository{items: map[uint]auditEntity{1: {ID: 1, Name: "first"}}}
+	s := NewBaseService[auditEntity, auditEntity](repo, auditTransformer{}, nil, "audit_entity")
+
+	if _, err := s.DeleteMany(context.Background(), nil); !apperrors.IsValidation(err) {
+		t.Errorf("DeleteMany(nil) error = %v, want validation error", err)
+	}
+	if _, err := s.DeleteByFilter(context.Background(), map[string]interface{}{"name": ""}); !apperrors.IsValidation(err) {
+		t.Errorf("DeleteByFilter() with empty filter error = %v, want validation error", err)
+	}
+	if len(repo.items) != 1 {
+		t.Errorf("items = %v, want nothing deleted", repo.items)
+	}
+}
+
+// phantomRepository добавляет запись с тем же именем сразу после выборки, как параллельная вставка
+type phantomRepository struct {
+	*bulkRepository
+}
+
+func (r *phantomRepository) GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) ([]auditEntity, int64, error) {
+	entities, total, err := r.bulkRepository.GetAll(ctx, skip, limit, filters, sort, opts...)
+	r.items[100] = auditEntity{ID: 100, Name: "x"}
+	return entities, total, err
+}
+
+func TestDeleteByFilterDeletesFoundIDs(t *testing.T) {
+	repo := &phantomRepository{&bulkRepository{memoryRepository: &memoryRepository{items: map[uint]auditEntity{1: {ID: 1, Name: "x"}}}}}
+	s := NewBaseService[auditEntity, auditEntity](repo, auditTransformer{}, nil, "audit_entity")
+
+	deleted, err := s.DeleteByFilter(context.Background(), map[string]interface{}{"name": "x"})
+	if err != nil {
+		t.Fatalf("DeleteByFilter() error = %v", err)
+	}
+	if _, ok := repo.items[100]; deleted != 1 || !ok {
+		t.Errorf("deleted = %d, remaining = %v, want only the found entity deleted", deleted, repo.items)
+	}
+}
@@ -0,0 +1,534 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/auth"
+	"github.com/vladzorgan/common/database"
+	"github.com/vladzorgan/common/killswitch"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/messaging"
+	"github.com/vladzorgan/common/metrics"
+	"github.com/vladzorgan/common/redis"
+	"github.com/vladzorgan/common/repository"
+)
+
+// Результаты обращений к кешу сервиса для метрики service_cache_requests_total
+const (
+	cacheResultHit   = "hit"
+	cacheResultMiss  = "miss"
+	cacheResultError = "error"
+)
+
+// cacheSetScript сохраняет значение, только если версия сущности не изменилась с момента чтения,
+// чтобы ответ, загруженный до изменения, не попал в кеш после инвалидации
+var cacheSetScript = goredis.NewScript(`
+local version = redis.call("GET", KEYS[1])
+if not version then
+	version = "0"
+end
+if version ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[2], ARGV[2], "PX", ARGV[3])
+return 1
+`)
+
+// CachedServiceOptions содержит опции кеширующего сервиса
+type CachedServiceOptions struct {
+	// Время жизни записей GetByID и GetByField
+	TTL time.Duration
+	// Кешировать ли GetAll и Search
+	CacheLists bool
+	// Время жизни записей GetAll и Search
+	ListTTL time.Duration
+	// Префикс ключей в Redis (по умолчанию "service_cache:<сущность>")
+	KeyPrefix string
+	// Сущность; если не задана, берется из сервиса (BaseService.Entity()) или из имени типа T
+	Entity EntityDescriptor
+	// Префикс метрик Prometheus
+	ServicePrefix string
+	// Registerer для метрик (по умолчанию prometheus.DefaultRegisterer)
+	Registerer prometheus.Registerer
+	// Логгер
+	Logger logging.Logger
+}
+
+// DefaultCachedServiceOptions возвращает опции по умолчанию
+func DefaultCachedServiceOptions() *CachedServiceOptions {
+	return &CachedServiceOptions{
+		TTL:     5 * time.Minute,
+		ListTTL: time.Minute,
+	}
+}
+
+// CachedService кеширует в Redis преобразованные ответы сервиса: GetByID и GetByField,
+// а при CacheLists также GetAll и Search. Вызовы с QueryOption выполняются без кеша.
+// Тип R должен сериализоваться в JSON без потерь.
+//
+// Записи по ID удаляются при изменении сущности через этот сервис, остальные записи
+// инвалидируются увеличением версии сущности. Изменения, сделанные другими экземплярами
+// или сервисами, применяются через BindConsumer; TTL ограничивает устаревание, если событие потеряно.
+//
+// Ключи запросов с пользователем или арендатором в контексте включают их ID: ответы репозиториев
+// с фильтрами по владельцу и арендатору у разных пользователей различаются.
+// Кеш отключается выключателем killswitch.FeatureResponseCache.
+type CachedService[T BaseEntity, R any] struct {
+	Service[T, R]
+
+	client   *redis.Client
+	options  *CachedServiceOptions
+	entity   EntityDescriptor
+	logger   logging.Logger
+	requests *prometheus.CounterVec
+}
+
+// NewCachedService создает кеширующий декоратор сервиса svc
+func NewCachedService[T BaseEntity, R any](svc Service[T, R], client *redis.Client, options *CachedServiceOptions) *CachedService[T, R] {
+	if options == nil {
+		options = DefaultCachedServiceOptions()
+	}
+
+	var entity EntityDescriptor
+	if options.Entity.Singular != "" || options.Entity.RoutingSegment != "" {
+		entity = options.Entity.withDefaults()
+	} else if described, ok := svc.(interface{ Entity() EntityDescriptor }); ok {
+		entity = described.Entity()
+	} else {
+		entity = DescribeEntity(reflect.TypeOf((*T)(nil)).Elem().Name())
+	}
+
+	keyPrefix := options.KeyPrefix
+	if keyPrefix == "" {
+		keyPrefix = "service_cache:" + entity.Singular
+	}
+
+	logger := options.Logger
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	options = &CachedServiceOptions{
+		TTL:           options.TTL,
+		CacheLists:    options.CacheLists,
+		ListTTL:       options.ListTTL,
+		KeyPrefix:     keyPrefix,
+		Entity:        entity,
+		ServicePrefix: options.ServicePrefix,
+		Registerer:    options.Registerer,
+		Logger:        logger,
+	}
+
+	name := "service_cache_requests_total"
+	if options.ServicePrefix != "" {
+		name = options.ServicePrefix + "_" + name
+	}
+
+	s := &CachedService[T, R]{
+		Service: svc,
+		client:  client,
+		options: options,
+		entity:  entity,
+		logger:  logger,
+		requests: metrics.Register(options.Registerer, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: name,
+				Help: "Обращения к кешу ответов сервиса по результату (hit, miss, error)",
+			},
+			[]string{"entity", "operation", "result"},
+		)),
+	}
+
+	// ID сущностей, удаленных по фильтрам, известны только сервису
+	if base, ok := svc.(interface {
+		OnAfterBulkDelete(hook AfterBulkDeleteHook[T]) *BaseService[T, R]
+	}); ok {
+		base.OnAfterBulkDelete(func(ctx context.Context, entities []*T) error {
+			ids := make([]uint, 0, len(entities))
+			for _, entity := range entities {
+				ids = append(ids, (*entity).GetID())
+			}
+			s.Invalidate(ctx, ids...)
+			return nil
+		})
+	}
+
+	return s
+}
+
+// GetByID возвращает сущность по ID из кеша или из сервиса
+func (s *CachedService[T, R]) GetByID(ctx context.Context, id uint, opts ...repository.QueryOption) (*R, error) {
+	if len(opts) > 0 {
+		return s.Service.GetByID(ctx, id, opts...)
+	}
+
+	// Записи анонимных запросов удаляются по ID, остальные - увеличением версии
+	var key cacheKey
+	var ok bool
+	if cacheScope(ctx) == "" {
+		key, ok = s.idKey(ctx, id)
+	} else {
+		key, ok = s.versionedKey(ctx, "id", id)
+	}
+	if !ok {
+		return s.Service.GetByID(ctx, id)
+	}
+
+	return cached(s, ctx, "get_by_id", key, s.options.TTL, func() (*R, error) {
+		return s.Service.GetByID(ctx, id)
+	})
+}
+
+// GetByField возвращает сущность по значению поля из кеша или из сервиса
+func (s *CachedService[T, R]) GetByField(ctx context.Context, field string, value interface{}, opts ...repository.QueryOption) (*R, error) {
+	if len(opts) > 0 {
+		return s.Service.GetByField(ctx, field, value, opts...)
+	}
+
+	key, ok := s.versionedKey(ctx, "field", field, value)
+	if !ok {
+		return s.Service.GetByField(ctx, field, value)
+	}
+
+	return cached(s, ctx, "get_by_field", key, s.options.TTL, func() (*R, error) {
+		return s.Service.GetByField(ctx, field, value)
+	})
+}
+
+// GetAll возвращает страницу сущностей; при CacheLists результат кешируется по параметрам запроса
+func (s *CachedService[T, R]) GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) (*PaginationResponse[R], error) {
+	if !s.options.CacheLists || len(opts) > 0 {
+		return s.Service.GetAll(ctx, skip, limit, filters, sort, opts...)
+	}
+
+	key, ok := s.versionedKey(ctx, "list", skip, limit, filters, sort)
+	if !ok {
+		return s.Service.GetAll(ctx, skip, limit, filters, sort)
+	}
+
+	return cached(s, ctx, "get_all", key, s.options.ListTTL, func() (*PaginationResponse[R], error) {
+		return s.Service.GetAll(ctx, skip, limit, filters, sort)
+	})
+}
+
+// Search ищет сущности; при CacheLists результат кешируется по параметрам запроса
+func (s *CachedService[T, R]) Search(ctx context.Context, keyword string, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) (*PaginationResponse[R], error) {
+	if !s.options.CacheLists || len(opts) > 0 {
+		return s.Service.Search(ctx, keyword, skip, limit, filters, sort, opts...)
+	}
+
+	key, ok := s.versionedKey(ctx, "search", keyword, skip, limit, filters, sort)
+	if !ok {
+		return s.Service.Search(ctx, keyword, skip, limit, filters, sort)
+	}
+
+	return cached(s, ctx, "search", key, s.options.ListTTL, func() (*PaginationResponse[R], error) {
+		return s.Service.Search(ctx, keyword, skip, limit, filters, sort)
+	})
+}
+
+// Create создает сущность и инвалидирует кеши списков
+func (s *CachedService[T, R]) Create(ctx context.Context, input CreateInput[T]) (*R, error) {
+	response, err := s.Service.Create(ctx, input)
+	if err == nil {
+		s.Invalidate(ctx)
+	}
+	return response, err
+}
+
+// Update обновляет сущность и инвалидирует ее кеш
+func (s *CachedService[T, R]) Update(ctx context.Context, id uint, input UpdateInput[T]) (*R, error) {
+	response, err := s.Service.Update(ctx, id, input)
+	if err == nil {
+		s.Invalidate(ctx, id)
+	}
+	return response, err
+}
+
+// Delete удаляет сущность и инвалидирует ее кеш
+func (s *CachedService[T, R]) Delete(ctx context.Context, id uint) (*R, error) {
+	response, err := s.Service.Delete(ctx, id)
+	if err == nil {
+		s.Invalidate(ctx, id)
+	}
+	return response, err
+}
+
+// CreateOrUpdate создает или обновляет сущность и инвалидирует кеш.
+// Запись по ID удаляется, только если R реализует GetID() uint; иначе она обновится по событию или TTL.
+func (s *CachedService[T, R]) CreateOrUpdate(ctx context.Context, input CreateInput[T], conflictColumns []string) (*R, error) {
+	response, err := s.Service.CreateOrUpdate(ctx, input, conflictColumns)
+	if err == nil {
+		s.Invalidate(ctx, responseIDs([]*R{response})...)
+	}
+	return response, err
+}
+
+// GetOrCreate возвращает или создает сущность; кеши списков инвалидируются, только если сущность создана
+func (s *CachedService[T, R]) GetOrCreate(ctx context.Context, field string, value interface{}, input CreateInput[T]) (*R, bool, error) {
+	response, created, err := s.Service.GetOrCreate(ctx, field, value, input)
+	if err == nil && created {
+		s.Invalidate(ctx)
+	}
+	return response, created, err
+}
+
+// BulkCreate создает сущности и инвалидирует кеши списков
+func (s *CachedService[T, R]) BulkCreate(ctx context.Context, inputs []CreateInput[T]) ([]R, error) {
+	responses, err := s.Service.BulkCreate(ctx, inputs)
+	if err == nil {
+		s.Invalidate(ctx)
+	}
+	return responses, err
+}
+
+// BulkUpdate обновляет сущности и инвалидирует их кеш
+func (s *CachedService[T, R]) BulkUpdate(ctx context.Context, updates []BulkUpdateInput[T]) ([]R, error) {
+	responses, err := s.Service.BulkUpdate(ctx, updates)
+	if err == nil {
+		ids := make([]uint, 0, len(updates))
+		for _, update := range updates {
+			ids = append(ids, update.GetID())
+		}
+		s.Invalidate(ctx, ids...)
+	}
+	return responses, err
+}
+
+// DeleteMany удаляет сущности и инвалидирует их кеш
+func (s *CachedService[T, R]) DeleteMany(ctx context.Context, ids []uint) (int64, error) {
+	deleted, err := s.Service.DeleteMany(ctx, ids)
+	if err == nil {
+		s.Invalidate(ctx, ids...)
+	}
+	return deleted, err
+}
+
+// DeleteByFilter удаляет сущности по фильтрам и инвалидирует кеши списков.
+// Записи по ID удаляет обработчик AfterBulkDelete, если svc - *BaseService;
+// иначе - событие bulk_deleted (см. BindConsumer) или истечение TTL.
+func (s *CachedService[T, R]) DeleteByFilter(ctx context.Context, filters map[string]interface{}, opts ...repository.QueryOption) (int64, error) {
+	deleted, err := s.Service.DeleteByFilter(ctx, filters, opts...)
+	if err == nil {
+		s.Invalidate(ctx)
+	}
+	return deleted, err
+}
+
+// Invalidate удаляет записи сущностей с указанными ID и инвалидирует записи GetByField, GetAll и Search.
+// В транзакции RunInTransaction инвалидация выполняется после ее фиксации.
+// Ошибки Redis логируются: изменение данных уже выполнено, а устаревание ограничено TTL.
+func (s *CachedService[T, R]) Invalidate(ctx context.Context, ids ...uint) {
+	afterCommit(ctx, func(ctx context.Context) {
+		s.invalidate(ctx, ids)
+	})
+}
+
+// invalidate удаляет записи сущностей с указанными ID и увеличивает версию сущности
+func (s *CachedService[T, R]) invalidate(ctx context.Context, ids []uint) {
+	if len(ids) > 0 {
+		keys := make([]string, 0, len(ids))
+		for _, id := range ids {
+			keys = append(keys, s.idEntryKey(id))
+		}
+		if err := s.client.Del(ctx, keys...); err != nil {
+			s.logger.WithContext(ctx).Warn("Failed to invalidate %s cache: %v", s.entity.Singular, err)
+		}
+	}
+
+	if _, err := s.client.Incr(ctx, s.versionKey()); err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to invalidate %s cache: %v", s.entity.Singular, err)
+	}
+}
+
+// BindConsumer подписывает инвалидацию кеша на события created, updated, deleted, bulk_created,
+// bulk_updated и bulk_deleted сущности, которые публикует BaseService, чтобы изменения, сделанные
+// другими экземплярами или сервисами, сразу применялись к кешу
+func (s *CachedService[T, R]) BindConsumer(consumer messaging.Consumer) error {
+	for _, eventType := range []string{"created", "updated", "deleted"} {
+		if err := consumer.Subscribe(s.routingKey(eventType), s.handleEntityEvent); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %v", s.routingKey(eventType), err)
+		}
+	}
+
+	for _, eventType := range []string{"bulk_created", "bulk_updated", "bulk_deleted"} {
+		if err := consumer.Subscribe(s.routingKey(eventType), s.handleBulkEvent); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %v", s.routingKey(eventType), err)
+		}
+	}
+
+	return nil
+}
+
+// handleEntityEvent инвалидирует кеш по событию об операции с сущностью
+func (s *CachedService[T, R]) handleEntityEvent(ctx context.Context, key string, payload []byte) error {
+	var event EntityEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		// Повторная доставка не исправит формат сообщения
+		s.logger.WithContext(ctx).Warn("Failed to decode %s event: %v", key, err)
+		return nil
+	}
+
+	if event.ID == 0 {
+		s.Invalidate(ctx)
+	} else {
+		s.Invalidate(ctx, event.ID)
+	}
+	return nil
+}
+
+// handleBulkEvent инвалидирует кеш по событию массовой операции
+func (s *CachedService[T, R]) handleBulkEvent(ctx context.Context, key string, payload []byte) error {
+	var event BulkEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to decode %s event: %v", key, err)
+		return nil
+	}
+
+	s.Invalidate(ctx, event.IDs...)
+	return nil
+}
+
+// routingKey формирует ключ маршрутизации события сущности
+func (s *CachedService[T, R]) routingKey(eventType string) string {
+	return fmt.Sprintf("%s.%s", s.entity.RoutingSegment, eventType)
+}
+
+// cacheKey - ключ записи кеша и версия сущности, прочитанная до загрузки значения
+type cacheKey struct {
+	key     string
+	version string
+}
+
+// cacheScope возвращает область запроса - пользователя и арендатора из контекста,
+// или пустую строку для анонимного запроса без арендатора
+func cacheScope(ctx context.Context) string {
+	var userID uint
+	if user, err := auth.GetUserFromContext(ctx); err == nil {
+		userID = user.ID
+	}
+	tenantID, _ := auth.GetTenantFromContext(ctx)
+
+	if userID == 0 && tenantID == 0 {
+		return ""
+	}
+	return fmt.Sprintf("u%d:t%d", userID, tenantID)
+}
+
+// idEntryKey возвращает ключ записи сущности по ID для анонимных запросов
+func (s *CachedService[T, R]) idEntryKey(id uint) string {
+	return s.options.KeyPrefix + ":id:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// idKey возвращает ключ записи сущности по ID вместе с текущей версией сущности
+func (s *CachedService[T, R]) idKey(ctx context.Context, id uint) (cacheKey, bool) {
+	version, ok := s.version(ctx)
+	if !ok {
+		return cacheKey{}, false
+	}
+	return cacheKey{key: s.idEntryKey(id), version: version}, true
+}
+
+// versionKey возвращает ключ версии сущности, которая увеличивается при каждом изменении
+func (s *CachedService[T, R]) versionKey() string {
+	return s.options.KeyPrefix + ":version"
+}
+
+// version возвращает текущую версию сущности. Если кеш отключен, запрос выполняется в транзакции
+// (ее незафиксированные данные не должны попасть в кеш) или версию получить не удалось,
+// возвращает false и кеш не используется.
+func (s *CachedService[T, R]) version(ctx context.Context) (string, bool) {
+	if killswitch.IsDisabled(killswitch.FeatureResponseCache) {
+		return "", false
+	}
+	if _, ok := database.TransactionFromContext(ctx); ok || inDeferredTransaction(ctx) {
+		return "", false
+	}
+
+	version, err := s.client.Get(ctx, s.versionKey())
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to read %s cache version: %v", s.entity.Singular, err)
+		return "", false
+	}
+	if version == "" {
+		version = "0"
+	}
+	return version, true
+}
+
+// versionedKey возвращает ключ записи для текущей версии сущности, области запроса и хеша параметров
+func (s *CachedService[T, R]) versionedKey(ctx context.Context, kind string, params ...interface{}) (cacheKey, bool) {
+	version, ok := s.version(ctx)
+	if !ok {
+		return cacheKey{}, false
+	}
+
+	// Ключи map сериализуются в JSON упорядоченными, поэтому одинаковые фильтры дают один хеш
+	data, err := json.Marshal(append([]interface{}{cacheScope(ctx)}, params...))
+	if err != nil {
+		return cacheKey{}, false
+	}
+	hash := sha256.Sum256(data)
+
+	key := fmt.Sprintf("%s:v%s:%s:%s", s.options.KeyPrefix, version, kind, hex.EncodeToString(hash[:16]))
+	return cacheKey{key: key, version: version}, true
+}
+
+// cached возвращает значение из кеша по ключу или вызывает load и сохраняет результат,
+// если версия сущности с момента чтения не изменилась.
+// Ошибки Redis не прерывают запрос: значение загружается из сервиса.
+func cached[T BaseEntity, R any, V any](s *CachedService[T, R], ctx context.Context, operation string, key cacheKey, ttl time.Duration, load func() (*V, error)) (*V, error) {
+	data, err := s.client.Get(ctx, key.key)
+	switch {
+	case err != nil:
+		s.requests.WithLabelValues(s.entity.Singular, operation, cacheResultError).Inc()
+		s.logger.WithContext(ctx).Warn("Failed to read %s cache: %v", s.entity.Singular, err)
+	case data != "":
+		var value V
+		if err := json.Unmarshal([]byte(data), &value); err == nil {
+			s.requests.WithLabelValues(s.entity.Singular, operation, cacheResultHit).Inc()
+			return &value, nil
+		}
+		s.requests.WithLabelValues(s.entity.Singular, operation, cacheResultError).Inc()
+	default:
+		s.requests.WithLabelValues(s.entity.Singular, operation, cacheResultMiss).Inc()
+	}
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to encode %s cache: %v", s.entity.Singular, err)
+		return value, nil
+	}
+	err = cacheSetScript.Run(ctx, s.client.Client(), []string{s.versionKey(), key.key},
+		key.version, encoded, ttl.Milliseconds()).Err()
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to write %s cache: %v", s.entity.Singular, err)
+	}
+
+	return value, nil
+}
+
+// responseIDs возвращает ID ответов, если тип ответа реализует GetID() uint
+func responseIDs[R any](responses []*R) []uint {
+	ids := make([]uint, 0, len(responses))
+	for _, response := range responses {
+		if identified, ok := any(response).(interface{ GetID() uint }); ok && response != nil {
+			ids = append(ids, identified.GetID())
+		}
+	}
+	return ids
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/vladzorgan/common/auth"
+	"github.com/vladzorgan/common/redis"
+	"github.com/vladzorgan/common/repository"
+)
+
+// countingService считает обращения к GetByID и GetAll; неиспользуемые методы не реализованы
+type countingService struct {
+	Service[auditEntity, auditEntity]
+	items   map[uint]auditEntity
+	getByID int
+	getAll  int
+}
+
+func (s *countingService) GetByID(ctx context.Context, id uint, opts ...repository.QueryOption) (*auditEntity, error) {
+	s.getByID++
+	entity := s.items[id]
+	return &entity, nil
+}
+
+func (s *countingService) GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) (*PaginationResponse[auditEntity], error) {
+	s.getAll++
+	items := make([]auditEntity, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, item)
+	}
+	return &PaginationResponse[auditEntity]{Items: items}, nil
+}
+
+func (s *countingService) Update(ctx context.Context, id uint, input UpdateInput[auditEntity]) (*auditEntity, error) {
+	entity := s.items[id]
+	entity.Name = input.ToUpdateMap()["name"].(string)
+	s.items[id] = entity
+	return &entity, nil
+}
+
+func newTestCachedService(t *testing.T) (*CachedService[auditEntity, auditEntity], *countingService, *prometheus.Registry) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client, err := redis.NewClient(server.Addr(), "", 0, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	inner := &countingService{items: map[uint]auditEntity{1: {ID: 1, Name: "first"}}}
+	registry := prometheus.NewRegistry()
+	options := DefaultCachedServiceOptions()
+	options.CacheLists = true
+	options.Entity = DescribeEntity("audit_entity")
+	options.Registerer = registry
+
+	return NewCachedService[auditEntity, auditEntity](inner, client, options), inner, registry
+}
+
+func TestCachedServiceGetByIDInvalidatedOnUpdate(t *testing.T) {
+	svc, inner, _ := newTestCachedService(t)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if got, err := svc.GetByID(ctx, 1); err != nil || got.Name != "first" {
+			t.Fatalf("GetByID() = %+v, %v", got, err)
+		}
+	}
+	if inner.getByID != 1 {
+		t.Fatalf("inner GetByID calls = %d, want 1", inner.getByID)
+	}
+
+	if _, err := svc.Update(ctx, 1, auditUpdate{name: "renamed"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	got, err := svc.GetByID(ctx, 1)
+	if err != nil || got.Name != "renamed" {
+		t.Fatalf("GetByID() after update = %+v, %v", got, err)
+	}
+	if inner.getByID != 2 {
+		t.Errorf("inner GetByID calls = %d, want 2", inner.getByID)
+	}
+}
+
+func TestCachedServiceListsInvalidatedByEvent(t *testing.T) {
+	svc, inner, registry := newTestCachedService(t)
+	ctx := context.Background()
+	filters := map[string]interface{}{"name": "first"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.GetAll(ctx, 0, 10, filters, nil); err != nil {
+			t.Fatalf("GetAll() error = %v", err)
+		}
+	}
+	if inner.getAll != 1 {
+		t.Fatalf("inner GetAll calls = %d, want 1", inner.getAll)
+	}
+
+	payload, _ := json.Marshal(EntityEvent{ID: 2, EventType: "created"})
+	if err := svc.handleEntityEvent(ctx, "audit_entity.created", payload); err != nil {
+		t.Fatalf("handleEntityEvent() error = %v", err)
+	}
+
+	if _, err := svc.GetAll(ctx, 0, 10, filters, nil); err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if inner.getAll != 2 {
+		t.Errorf("inner GetAll calls = %d, want 2", inner.getAll)
+	}
+
+	if got := testutil.ToFloat64(svc.requests.WithLabelValues("audit_entity", "get_all", cacheResultHit)); got != 1 {
+		t.Errorf("hits = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(svc.requests.WithLabelValues("audit_entity", "get_all", cacheResultMiss)); got != 2 {
+		t.Errorf("misses = %v, want 2", got)
+	}
+	if count, err := testutil.GatherAndCount(registry, "service_cache_requests_total"); err != nil || count == 0 {
+		t.Errorf("GatherAndCount() = %d, %v", count, err)
+	}
+}
+
+func TestCachedServiceKeysIncludeUserAndTenant(t *testing.T) {
+	svc, inner, _ := newTestCachedService(t)
+	owner := auth.WithUser(context.Background(), &auth.User{ID: 1, Role: auth.UserRole_User, IsActive: true})
+	other := auth.WithUser(context.Background(), &auth.User{ID: 2, Role: auth.UserRole_User, IsActive: true})
+
+	for _, ctx := range []context.Context{owner, owner, other, auth.WithTenant(other, 7)} {
+		if _, err := svc.GetByID(ctx, 1); err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if _, err := svc.GetAll(ctx, 0, 10, nil, nil); err != nil {
+			t.Fatalf("GetAll() error = %v", err)
+		}
+	}
+
+	// Повторный запрос владельца обслуживается из кеша, запросы другого пользователя и арендатора - нет
+	if inner.getByID != 3 || inner.getAll != 3 {
+		t.Errorf("inner calls = GetByID %d, GetAll %d, want 3 and 3", inner.getByID, inner.getAll)
+	}
+}
+
+func TestCachedServiceSkipsStaleWrite(t *testing.T) {
+	svc, _, _ := newTestCachedService(t)
+	ctx := context.Background()
+
+	key, ok := svc.idKey(ctx, 1)
+	if !ok {
+		t.Fatal("idKey() not available")
+	}
+
+	// Сущность изменена, пока загружался старый ответ
+	_, err := cached(svc, ctx, "get_by_id", key, time.Minute, func() (*auditEntity, error) {
+		svc.Invalidate(ctx, 1)
+		return &auditEntity{ID: 1, Name: "stale"}, nil
+	})
+	if err != nil {
+		t.Fatalf("cached() error = %v", err)
+	}
+
+	if data, err := svc.client.Get(ctx, key.key); err != nil || data != "" {
+		t.Errorf("cache entry = %q, %v, want none", data, err)
+	}
+}
+
+func TestCachedServiceMetricUsesServicePrefix(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	options := DefaultCachedServiceOptions()
+	options.Entity = DescribeEntity("audit_entity")
+	options.ServicePrefix = "orders"
+	options.Registerer = registry
+
+	svc := NewCachedService[auditEntity, auditEntity](&countingService{}, nil, options)
+	svc.requests.WithLabelValues("audit_entity", "get_by_id", cacheResultMiss).Inc()
+
+	if count, err := testutil.GatherAndCount(registry, "orders_service_cache_requests_total"); err != nil || count != 1 {
+		t.Errorf("GatherAndCount() = %d, %v", count, err)
+	}
+}
+
+func TestCachedServiceDeleteByFilterInvalidatesIDs(t *testing.T) {
+	server := miniredis.RunT(t)
+	client, err := redis.NewClient(server.Addr(), "", 0, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	repo := &bulkRepository{memoryRepository: &memoryRepository{items: map[uint]auditEntity{1: {ID: 1, Name: "x"}}}}
+	base := NewBaseService[auditEntity, auditEntity](repo, auditTransformer{}, nil, "audit_entity")
+	options := DefaultCachedServiceOptions()
+	options.Registerer = prometheus.NewRegistry()
+	svc := NewCachedService[auditEntity, auditEntity](base, client, options)
+	ctx := context.Background()
+
+	if _, err := svc.GetByID(ctx, 1); err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if !server.Exists(svc.idEntryKey(1)) {
+		t.Fatal("GetByID() result was not cached")
+	}
+
+	if _, err := svc.DeleteByFilter(ctx, map[string]interface{}{"name": "x"}); err != nil {
+		t.Fatalf("DeleteByFilter() error = %v", err)
+	}
+	if server.Exists(svc.idEntryKey(1)) {
+		t.Error("cache entry of the deleted entity was not removed")
+	}
+}
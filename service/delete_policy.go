@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	apperrors "github.com/vladzorgan/common/errors"
+)
+
+// DependencyCheck возвращает причины, препятствующие удалению сущности (например, "has 12 cities")
+type DependencyCheck func(ctx context.Context, id uint) (blockers []string, err error)
+
+// CascadeAction удаляет или отвязывает зависимые сущности перед удалением родителя.
+// Выполняется в транзакции удаления: сервисы дочерних сущностей, созданные через
+// NewBaseServiceWithTx с тем же *database.Database, присоединяются к ней через контекст.
+type CascadeAction func(ctx context.Context, id uint) error
+
+// DeletePolicy описывает проверки зависимостей и каскадные действия при удалении
+type DeletePolicy struct {
+	// Проверки, блокирующие удаление при наличии зависимых сущностей
+	Checks []DependencyCheck
+	// Каскадные действия, выполняемые после проверок и до удаления
+	Cascades []CascadeAction
+}
+
+// WithDeletePolicy устанавливает политику удаления сервиса
+func (s *BaseService[T, R]) WithDeletePolicy(policy *DeletePolicy) *BaseService[T, R] {
+	s.deletePolicy = policy
+	return s
+}
+
+// CheckDelete проверяет, можно ли удалить сущности с указанными ID.
+// Причины блокировки собираются по всем ID и возвращаются вместе ошибкой apperrors.Blocked.
+func (s *BaseService[T, R]) CheckDelete(ctx context.Context, ids ...uint) error {
+	if s.deletePolicy == nil || len(s.deletePolicy.Checks) == 0 {
+		return nil
+	}
+
+	blockers := make(map[uint][]string)
+	for _, id := range ids {
+		for _, check := range s.deletePolicy.Checks {
+			reasons, err := check(ctx, id)
+			if err != nil {
+				return apperrors.Wrap(apperrors.ErrInternal, s.entity.Singular, id, err,
+					fmt.Sprintf("ошибка при проверке зависимостей %s", s.entity.DisplayNameRu))
+			}
+			blockers[id] = append(blockers[id], reasons...)
+		}
+
+		if len(blockers[id]) == 0 {
+			delete(blockers, id)
+		}
+	}
+
+	if len(blockers) > 0 {
+		return apperrors.Blocked(s.entity.Singular, blockers)
+	}
+
+	return nil
+}
+
+// runCascades выполняет каскадные действия политики удаления
+func (s *BaseService[T, R]) runCascades(ctx context.Context, id uint) error {
+	if s.deletePolicy == nil {
+		return nil
+	}
+
+	for _, cascade := range s.deletePolicy.Cascades {
+		if err := cascade(ctx, id); err != nil {
+			return apperrors.Wrap(apperrors.ErrInternal, s.entity.Singular, id, err,
+				fmt.Sprintf("ошибка каскадного удаления зависимостей %s", s.entity.DisplayNameRu))
+		}
+	}
+
+	return nil
+}
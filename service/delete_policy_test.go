@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	apperrors "github.com/vladzorgan/common/errors"
+)
+
+// policyRepository дополняет memoryRepository проверкой существования
+type policyRepository struct {
+	*memoryRepository
+}
+
+func (r policyRepository) Exists(ctx context.Context, id uint) (bool, error) {
+	_, ok := r.items[id]
+	return ok, nil
+}
+
+// snapshotTxRunner имитирует транзакцию над репозиториями в памяти: при ошибке восстанавливает их содержимое
+type snapshotTxRunner struct {
+	repos []*memoryRepository
+}
+
+func (r *snapshotTxRunner) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	snapshots := make([]map[uint]auditEntity, len(r.repos))
+	for i, repo := range r.repos {
+		snapshots[i] = make(map[uint]auditEntity, len(repo.items))
+		for id, entity := range repo.items {
+			snapshots[i][id] = entity
+		}
+	}
+
+	if err := fn(ctx); err != nil {
+		for i, repo := range r.repos {
+			repo.items = snapshots[i]
+		}
+		return err
+	}
+	return nil
+}
+
+// regionFixture содержит сервис регионов с политикой удаления и репозиторий городов
+type regionFixture struct {
+	regions *BaseService[auditEntity, auditEntity]
+	cities  *BaseService[auditEntity, auditEntity]
+
+	regionRepo *memoryRepository
+	cityRepo   *memoryRepository
+	// cityRegion связывает город с регионом
+	cityRegion map[uint]uint
+}
+
+func newRegionFixture() *regionFixture {
+	f := &regionFixture{
+		regionRepo: &memoryRepository{items: map[uint]auditEntity{1: {ID: 1, Name: "north"}, 2: {ID: 2, Name: "south"}, 3: {ID: 3, Name: "east"}}},
+		cityRepo:   &memoryRepository{items: map[uint]auditEntity{10: {ID: 10, Name: "a"}, 11: {ID: 11, Name: "b"}, 20: {ID: 20, Name: "c"}}},
+		cityRegion: map[uint]uint{10: 1, 11: 1, 20: 2},
+	}
+
+	runner := &snapshotTxRunner{repos: []*memoryRepository{f.regionRepo, f.cityRepo}}
+	f.regions = NewBaseServiceWithTx[auditEntity, auditEntity](policyRepository{f.regionRepo}, auditTransformer{}, nil, "region", runner)
+	f.cities = NewBaseServiceWithTx[auditEntity, auditEntity](policyRepository{f.cityRepo}, auditTransformer{}, nil, "city", runner)
+	return f
+}
+
+// citiesOf возвращает ID оставшихся городов региона
+func (f *regionFixture) citiesOf(regionID uint) []uint {
+	var ids []uint
+	for cityID := range f.cityRepo.items {
+		if f.cityRegion[cityID] == regionID {
+			ids = append(ids, cityID)
+		}
+	}
+	return ids
+}
+
+// hasCities блокирует удаление региона с городами
+func (f *regionFixture) hasCities(ctx context.Context, id uint) ([]string, error) {
+	if n := len(f.citiesOf(id)); n > 0 {
+		return []string{fmt.Sprintf("has %d cities", n)}, nil
+	}
+	return nil, nil
+}
+
+// deleteCities удаляет города региона через их сервис
+func (f *regionFixture) deleteCities(ctx context.Context, id uint) error {
+	ids := f.citiesOf(id)
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := f.cities.DeleteMany(ctx, ids)
+	return err
+}
+
+func TestDeletePolicyBlocks(t *testing.T) {
+	f := newRegionFixture()
+	cascades := 0
+	f.regions.WithDeletePolicy(&DeletePolicy{
+		Checks:   []DependencyCheck{f.hasCities},
+		Cascades: []CascadeAction{func(context.Context, uint) error { cascades++; return nil }},
+	})
+
+	_, err := f.regions.Delete(context.Background(), 1)
+	if !apperrors.IsConflict(err) {
+		t.Fatalf("Delete() error = %v, want conflict", err)
+	}
+
+	var typedErr *apperrors.Error
+	if !stderrors.As(err, &typedErr) || !reflect.DeepEqual(typedErr.Blockers, map[uint][]string{1: {"has 2 cities"}}) {
+		t.Errorf("blockers = %v, want 1: [has 2 cities]", typedErr.Blockers)
+	}
+	if _, ok := f.regionRepo.items[1]; !ok || cascades != 0 {
+		t.Errorf("region deleted = %v, cascades = %d; want region kept and no cascades", !ok, cascades)
+	}
+
+	// Регион без городов удаляется
+	if _, err := f.regions.Delete(context.Background(), 3); err != nil {
+		t.Errorf("Delete(3) error = %v", err)
+	}
+	// Отсутствующая сущность - NotFound до проверок
+	if _, err := f.regions.Delete(context.Background(), 3); !apperrors.IsNotFound(err) {
+		t.Errorf("Delete(missing) error = %v, want not found", err)
+	}
+}
+
+func TestDeletePolicyCascade(t *testing.T) {
+	f := newRegionFixture()
+	f.regions.WithDeletePolicy(&DeletePolicy{Cascades: []CascadeAction{f.deleteCities}})
+
+	if _, err := f.regions.Delete(context.Background(), 1); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok := f.regionRepo.items[1]; ok {
+		t.Error("region 1 was not deleted")
+	}
+	if cities := f.citiesOf(1); len(cities) != 0 {
+		t.Errorf("cities of region 1 = %v, want none", cities)
+	}
+	if cities := f.citiesOf(2); len(cities) != 1 {
+		t.Errorf("cities of region 2 = %v, want untouched", cities)
+	}
+}
+
+func TestDeletePolicyCascadeFailureRollsBack(t *testing.T) {
+	f := newRegionFixture()
+	f.regions.WithDeletePolicy(&DeletePolicy{Cascades: []CascadeAction{
+		f.deleteCities,
+		func(context.Context, uint) error { return stderrors.New("detach failed") },
+	}})
+
+	_, err := f.regions.Delete(context.Background(), 1)
+	if !apperrors.IsInternal(err) {
+		t.Fatalf("Delete() error = %v, want internal", err)
+	}
+
+	// Удаленные каскадом города восстановлены вместе с регионом
+	if _, ok := f.regionRepo.items[1]; !ok {
+		t.Error("region 1 must be kept")
+	}
+	if cities := f.citiesOf(1); len(cities) != 2 {
+		t.Errorf("cities of region 1 = %v, want both restored", cities)
+	}
+}
+
+func TestDeleteManyAggregatesBlockers(t *testing.T) {
+	f := newRegionFixture()
+	f.regions.WithDeletePolicy(&DeletePolicy{Checks: []DependencyCheck{
+		f.hasCities,
+		func(ctx context.Context, id uint) ([]string, error) {
+			if id == 1 {
+				return []string{"has 3 warehouses"}, nil
+			}
+			return nil, nil
+		},
+	}})
+
+	_, err := f.regions.DeleteMany(context.Background(), []uint{1, 2, 3, 99})
+	if !apperrors.IsConflict(err) {
+		t.Fatalf("DeleteMany() error = %v, want conflict", err)
+	}
+
+	var typedErr *apperrors.Error
+	stderrors.As(err, &typedErr)
+	want := map[uint][]string{1: {"has 2 cities", "has 3 warehouses"}, 2: {"has 1 cities"}}
+	if !reflect.DeepEqual(typedErr.Blockers, want) {
+		t.Errorf("blockers = %v, want %v", typedErr.Blockers, want)
+	}
+	// Удаление атомарно: незаблокированный регион 3 тоже остается
+	if len(f.regionRepo.items) != 3 {
+		t.Errorf("regions = %v, want all kept", f.regionRepo.items)
+	}
+}
+
+func TestDeleteManyMixedCascadeAndBlock(t *testing.T) {
+	f := newRegionFixture()
+	// Города удаляются каскадом, а регион 2 заблокирован внешней ссылкой
+	f.regions.WithDeletePolicy(&DeletePolicy{
+		Checks: []DependencyCheck{func(ctx context.Context, id uint) ([]string, error) {
+			if id == 2 {
+				return []string{"referenced by 5 orders"}, nil
+			}
+			return nil, nil
+		}},
+		Cascades: []CascadeAction{f.deleteCities},
+	})
+
+	if _, err := f.regions.DeleteMany(context.Background(), []uint{1, 2}); !apperrors.IsConflict(err) {
+		t.Fatalf("DeleteMany() error = %v, want conflict", err)
+	}
+	if len(f.cityRepo.items) != 3 {
+		t.Errorf("cities = %v, want cascades skipped for a blocked batch", f.cityRepo.items)
+	}
+
+	deleted, err := f.regions.DeleteMany(context.Background(), []uint{1, 3})
+	if err != nil || deleted != 2 {
+		t.Fatalf("DeleteMany() = %d, %v; want 2", deleted, err)
+	}
+	if len(f.citiesOf(1)) != 0 || len(f.citiesOf(2)) != 1 {
+		t.Errorf("cities = %v, want only region 1 cities removed", f.cityRepo.items)
+	}
+}
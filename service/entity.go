@@ -0,0 +1,154 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// routingSegmentPattern соглашение об именовании сегмента ключа маршрутизации: snake_case в нижнем регистре
+var routingSegmentPattern = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// EntityDescriptor описывает имена сущности для сообщений об ошибках, логов и событий
+type EntityDescriptor struct {
+	// Имя сущности в единственном числе (например, "device_brand"); используется в ошибках
+	Singular string
+	// Имя сущности во множественном числе (например, "device_brands")
+	Plural string
+	// Отображаемое имя на русском (например, "бренд устройства"); используется в сообщениях и логах
+	DisplayNameRu string
+	// Отображаемое имя на английском (например, "device brand"); используется в каталоге событий
+	DisplayNameEn string
+	// Сегмент ключа маршрутизации событий (например, "device_brand" для "device_brand.created")
+	RoutingSegment string
+}
+
+// EntityName ограничивает способы задания сущности в конструкторах сервиса
+type EntityName interface {
+	~string | EntityDescriptor
+}
+
+// DescribeEntity выводит описание сущности из одного имени, сохраняя его без изменений.
+// Singular и RoutingSegment совпадают с исходным именем, поэтому ключи маршрутизации
+// и EntityType событий остаются прежними ("DeviceBrand" -> "DeviceBrand.created").
+func DescribeEntity(name string) EntityDescriptor {
+	return EntityDescriptor{
+		Singular:       name,
+		Plural:         pluralize(name),
+		DisplayNameRu:  name,
+		DisplayNameEn:  strings.ReplaceAll(toSnakeCase(name), "_", " "),
+		RoutingSegment: name,
+	}
+}
+
+// DescribeEntityNormalized выводит описание сущности с именем, приведенным к snake_case
+// ("DeviceBrand" -> "device_brand", "device_brand.created").
+// Меняет ключи маршрутизации существующих сущностей, поэтому включается явно:
+// результат передается в конструктор сервиса вместо строки.
+func DescribeEntityNormalized(name string) EntityDescriptor {
+	singular := toSnakeCase(name)
+
+	return EntityDescriptor{
+		Singular:       singular,
+		Plural:         pluralize(singular),
+		DisplayNameRu:  name,
+		DisplayNameEn:  strings.ReplaceAll(singular, "_", " "),
+		RoutingSegment: singular,
+	}
+}
+
+// Validate проверяет описание сущности на соответствие соглашениям об именовании
+func (d EntityDescriptor) Validate() error {
+	var problems []string
+
+	if d.Singular == "" {
+		problems = append(problems, "singular name is empty")
+	}
+	if !routingSegmentPattern.MatchString(d.RoutingSegment) {
+		problems = append(problems, fmt.Sprintf("routing segment %q must be lower snake_case", d.RoutingSegment))
+	}
+	if d.Plural != "" && d.Plural != d.Singular && d.RoutingSegment == d.Plural {
+		problems = append(problems, fmt.Sprintf("routing segment %q must use the singular form", d.RoutingSegment))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid entity descriptor %q: %s", d.Singular, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// withDefaults заполняет незаданные поля описания, выводя их из заданных.
+// Явное описание - выбор новых соглашений, поэтому производные имена нормализуются.
+func (d EntityDescriptor) withDefaults() EntityDescriptor {
+	base := d.Singular
+	if base == "" {
+		base = d.RoutingSegment
+	}
+	derived := DescribeEntityNormalized(base)
+
+	if d.Singular == "" {
+		d.Singular = derived.Singular
+	}
+	if d.Plural == "" {
+		d.Plural = derived.Plural
+	}
+	if d.DisplayNameRu == "" {
+		d.DisplayNameRu = derived.DisplayNameRu
+	}
+	if d.DisplayNameEn == "" {
+		d.DisplayNameEn = derived.DisplayNameEn
+	}
+	if d.RoutingSegment == "" {
+		d.RoutingSegment = derived.RoutingSegment
+	}
+
+	return d
+}
+
+// toEntityDescriptor приводит имя или описание сущности к полному описанию
+func toEntityDescriptor[E EntityName](entity E) EntityDescriptor {
+	if descriptor, ok := any(entity).(EntityDescriptor); ok {
+		return descriptor.withDefaults()
+	}
+	return DescribeEntity(reflect.ValueOf(entity).String())
+}
+
+// toSnakeCase приводит имя к snake_case в нижнем регистре ("DeviceBrand", "device-brand" -> "device_brand")
+func toSnakeCase(name string) string {
+	var builder strings.Builder
+	runes := []rune(strings.TrimSpace(name))
+
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == ' ' || r == '.':
+			builder.WriteRune('_')
+		case unicode.IsUpper(r):
+			if i > 0 && runes[i-1] != '_' && !unicode.IsUpper(runes[i-1]) {
+				builder.WriteRune('_')
+			}
+			builder.WriteRune(unicode.ToLower(r))
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
+// pluralize образует множественное число последнего слова по правилам английского языка
+func pluralize(singular string) string {
+	switch {
+	case singular == "":
+		return ""
+	case strings.HasSuffix(singular, "y") && len(singular) > 1 && !strings.ContainsRune("aeiou", rune(singular[len(singular)-2])):
+		return singular[:len(singular)-1] + "ies"
+	case strings.HasSuffix(singular, "s"), strings.HasSuffix(singular, "x"), strings.HasSuffix(singular, "z"),
+		strings.HasSuffix(singular, "ch"), strings.HasSuffix(singular, "sh"):
+		return singular + "es"
+	default:
+		return singular + "s"
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDescribeEntity(t *testing.T) {
+	tests := []struct {
+		name string
+		want EntityDescriptor
+	}{
+		{"device_brand", EntityDescriptor{"device_brand", "device_brands", "device_brand", "device brand", "device_brand"}},
+		{"DeviceBrand", EntityDescriptor{"DeviceBrand", "DeviceBrands", "DeviceBrand", "device brand", "DeviceBrand"}},
+		{"city", EntityDescriptor{"city", "cities", "city", "city", "city"}},
+		{"address", EntityDescriptor{"address", "addresses", "address", "address", "address"}},
+	}
+
+	for _, tt := range tests {
+		if got := DescribeEntity(tt.name); got != tt.want {
+			t.Errorf("DescribeEntity(%q) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDescribeEntityNormalized(t *testing.T) {
+	tests := []struct {
+		name string
+		want EntityDescriptor
+	}{
+		{"device_brand", EntityDescriptor{"device_brand", "device_brands", "device_brand", "device brand", "device_brand"}},
+		{"DeviceBrand", EntityDescriptor{"device_brand", "device_brands", "DeviceBrand", "device brand", "device_brand"}},
+		{"device-model", EntityDescriptor{"device_model", "device_models", "device-model", "device model", "device_model"}},
+		{"city", EntityDescriptor{"city", "cities", "city", "city", "city"}},
+	}
+
+	for _, tt := range tests {
+		if got := DescribeEntityNormalized(tt.name); got != tt.want {
+			t.Errorf("DescribeEntityNormalized(%q) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestLegacyRoutingKeys фиксирует ключи маршрутизации и EntityType, под которые уже созданы привязки очередей
+func TestLegacyRoutingKeys(t *testing.T) {
+	tests := []struct {
+		entity     string
+		wantKey    string
+		wantEntity string
+	}{
+		{"device_brand", "device_brand.deleted", "device_brand"},
+		{"DeviceBrand", "DeviceBrand.deleted", "DeviceBrand"},
+		{"device-model", "device-model.deleted", "device-model"},
+		{"city", "city.deleted", "city"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entity, func(t *testing.T) {
+			publisher := &recordingPublisher{}
+			repo := &memoryRepository{items: map[uint]auditEntity{1: {ID: 1, Name: "old"}}}
+			s := NewBaseService[auditEntity, auditEntity](repo, auditTransformer{}, publisher, tt.entity)
+
+			if _, err := s.Delete(context.Background(), 1); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if len(publisher.keys) != 1 || publisher.keys[0] != tt.wantKey {
+				t.Fatalf("published = %v, want [%s]", publisher.keys, tt.wantKey)
+			}
+			if event, ok := publisher.payloads[0].(EntityEvent); !ok || event.EntityType != tt.wantEntity {
+				t.Errorf("payload = %+v, want EntityType %s", publisher.payloads[0], tt.wantEntity)
+			}
+		})
+	}
+
+	// Нормализованные имена включаются явно через описание
+	publisher := &recordingPublisher{}
+	repo := &memoryRepository{items: map[uint]auditEntity{1: {ID: 1, Name: "old"}}}
+	s := NewBaseService[auditEntity, auditEntity](repo, auditTransformer{}, publisher, DescribeEntityNormalized("DeviceBrand"))
+	if _, err := s.Delete(context.Background(), 1); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if len(publisher.keys) != 1 || publisher.keys[0] != "device_brand.deleted" {
+		t.Errorf("published = %v, want [device_brand.deleted]", publisher.keys)
+	}
+}
+
+func TestEntityDescriptorDefaults(t *testing.T) {
+	got := toEntityDescriptor(EntityDescriptor{Singular: "device_brand", DisplayNameRu: "бренд устройства"})
+
+	if got.DisplayNameRu != "бренд устройства" || got.RoutingSegment != "device_brand" || got.Plural != "device_brands" {
+		t.Errorf("descriptor defaults = %+v", got)
+	}
+}
+
+func TestEntityDescriptorValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		descriptor EntityDescriptor
+		wantErr    bool
+	}{
+		{"derived", DescribeEntityNormalized("DeviceBrand"), false},
+		{"legacy camel case", DescribeEntity("DeviceBrand"), true},
+		{"camel case segment", EntityDescriptor{Singular: "device_brand", RoutingSegment: "deviceBrand"}, true},
+		{"plural segment", EntityDescriptor{Singular: "city", Plural: "cities", RoutingSegment: "cities"}, true},
+		{"cyrillic segment", DescribeEntity("устройство"), true},
+		{"empty singular", EntityDescriptor{RoutingSegment: "city"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.descriptor.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
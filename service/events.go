@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vladzorgan/common/eventcatalog"
+)
+
+// EntityEvent представляет полезную нагрузку событий created, updated и deleted
+type EntityEvent struct {
+	ID            uint     `json:"id" doc:"ID сущности" example:"1"`
+	Name          string   `json:"name" doc:"Название сущности"`
+	EventType     string   `json:"event_type" doc:"Тип события" example:"created"`
+	EntityType    string   `json:"entity_type" doc:"Тип сущности"`
+	UpdatedFields []string `json:"updated_fields,omitempty" doc:"Измененные поля (только для updated)"`
+}
+
+// BulkEvent представляет полезную нагрузку событий массовых операций
+type BulkEvent struct {
+	IDs        []uint   `json:"ids" doc:"ID сущностей"`
+	Names      []string `json:"names" doc:"Названия сущностей в порядке ids"`
+	Count      int      `json:"count" doc:"Количество сущностей"`
+	EventType  string   `json:"event_type" doc:"Тип события" example:"bulk_created"`
+	EntityType string   `json:"entity_type" doc:"Тип сущности"`
+}
+
+// UUIDEntityEvent полезная нагрузка событий created, updated и deleted сервиса со строковыми ID (BaseUUIDService).
+// Совпадает с EntityEvent, но поле id содержит строку.
+type UUIDEntityEvent struct {
+	ID            string   `json:"id" doc:"ID сущности" example:"8f14e45f-ceea-4e67-a1d2-7a4b2d6f0c11"`
+	Name          string   `json:"name" doc:"Название сущности"`
+	EventType     string   `json:"event_type" doc:"Тип события" example:"created"`
+	EntityType    string   `json:"entity_type" doc:"Тип сущности"`
+	UpdatedFields []string `json:"updated_fields,omitempty" doc:"Измененные поля (только для updated)"`
+}
+
+// UUIDBulkEvent полезная нагрузка событий массовых операций сервиса со строковыми ID
+type UUIDBulkEvent struct {
+	IDs        []string `json:"ids" doc:"ID сущностей"`
+	Names      []string `json:"names" doc:"Названия сущностей в порядке ids"`
+	Count      int      `json:"count" doc:"Количество сущностей"`
+	EventType  string   `json:"event_type" doc:"Тип события" example:"bulk_created"`
+	EntityType string   `json:"entity_type" doc:"Тип сущности"`
+}
+
+// RegisterEvents регистрирует в каталоге стандартные события сервиса, включая bulk_deleted
+func (s *BaseService[T, R]) RegisterEvents(catalog *eventcatalog.Catalog) error {
+	bulkExample := func(eventType string) interface{} {
+		return BulkEvent{
+			IDs:        []uint{1, 2},
+			Names:      []string{"first", "second"},
+			Count:      2,
+			EventType:  eventType,
+			EntityType: s.entity.Singular,
+		}
+	}
+
+	err := s.registerEvents(catalog, EntityEvent{}, BulkEvent{},
+		func(eventType string) interface{} {
+			return EntityEvent{ID: 1, Name: "example", EventType: eventType, EntityType: s.entity.Singular}
+		},
+		bulkExample,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Массовое удаление есть только у сервиса с числовыми ID
+	return catalog.Register(eventcatalog.EventDescriptor{
+		RoutingKey:  s.routingKey("bulk_deleted"),
+		Description: fmt.Sprintf("Several %s were deleted", strings.ReplaceAll(s.entity.Plural, "_", " ")),
+		Payload:     BulkEvent{},
+		Example:     bulkExample("bulk_deleted"),
+	})
+}
+
+// registerEvents регистрирует стандартные события с заданными типами полезной нагрузки и примерами
+func (s *serviceCore[T, R]) registerEvents(
+	catalog *eventcatalog.Catalog,
+	entityPayload, bulkPayload interface{},
+	entityExample, bulkExample func(eventType string) interface{},
+) error {
+	entityEvents := []struct {
+		eventType   string
+		description string
+	}{
+		{"created", fmt.Sprintf("A %s was created", s.entity.DisplayNameEn)},
+		{"updated", fmt.Sprintf("A %s was updated", s.entity.DisplayNameEn)},
+		{"deleted", fmt.Sprintf("A %s was deleted", s.entity.DisplayNameEn)},
+	}
+
+	bulkEvents := []struct {
+		eventType   string
+		description string
+	}{
+		{"bulk_created", fmt.Sprintf("Several %s were created", strings.ReplaceAll(s.entity.Plural, "_", " "))},
+		{"bulk_updated", fmt.Sprintf("Several %s were updated", strings.ReplaceAll(s.entity.Plural, "_", " "))},
+	}
+
+	descriptors := make([]eventcatalog.EventDescriptor, 0, len(entityEvents)+len(bulkEvents))
+	for _, event := range entityEvents {
+		descriptors = append(descriptors, eventcatalog.EventDescriptor{
+			RoutingKey:  s.routingKey(event.eventType),
+			Description: event.description,
+			Payload:     entityPayload,
+			Example:     entityExample(event.eventType),
+		})
+	}
+	for _, event := range bulkEvents {
+		descriptors = append(descriptors, eventcatalog.EventDescriptor{
+			RoutingKey:  s.routingKey(event.eventType),
+			Description: event.description,
+			Payload:     bulkPayload,
+			Example:     bulkExample(event.eventType),
+		})
+	}
+
+	return catalog.Register(descriptors...)
+}
+
+// routingKey формирует ключ маршрутизации события сущности из RoutingSegment описания
+func (s *serviceCore[T, R]) routingKey(eventType string) string {
+	return fmt.Sprintf("%s.%s", s.entity.RoutingSegment, eventType)
+}
+
+// entityEvent формирует событие об операции с сущностью
+func (s *BaseService[T, R]) entityEvent(eventType string, entity *T, updatedFields []string) pendingEvent {
+	return pendingEvent{
+		name: s.routingKey(eventType),
+		data: EntityEvent{
+			ID:            (*entity).GetID(),
+			Name:          (*entity).GetName(),
+			EventType:     eventType,
+			EntityType:    s.entity.Singular,
+			UpdatedFields: updatedFields,
+		},
+	}
+}
+
+// bulkEvent формирует событие массовой операции; для пустого списка события нет
+func (s *BaseService[T, R]) bulkEvent(eventType string, entities []*T) (pendingEvent, bool) {
+	if len(entities) == 0 {
+		return pendingEvent{}, false
+	}
+
+	entityIDs := make([]uint, 0, len(entities))
+	entityNames := make([]string, 0, len(entities))
+
+	for _, entity := range entities {
+		entityIDs = append(entityIDs, (*entity).GetID())
+		entityNames = append(entityNames, (*entity).GetName())
+	}
+
+	return pendingEvent{
+		name: s.routingKey(eventType),
+		data: BulkEvent{
+			IDs:        entityIDs,
+			Names:      entityNames,
+			Count:      len(entities),
+			EventType:  eventType,
+			EntityType: s.entity.Singular,
+		},
+	}, true
+}
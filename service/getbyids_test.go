@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	apperrors "github.com/vladzorgan/common/errors"
+	"github.com/vladzorgan/common/repository"
+)
+
+// labelResponse ответ с преобразованным именем
+type labelResponse struct {
+	ID    uint
+	Label string
+}
+
+type labelTransformer struct{}
+
+func (labelTransformer) Transform(entity *auditEntity) *labelResponse {
+	return &labelResponse{ID: entity.ID, Label: strings.ToUpper(entity.Name)}
+}
+
+func (t labelTransformer) TransformSlice(entities []auditEntity) []labelResponse {
+	responses := make([]labelResponse, len(entities))
+	for i := range entities {
+		responses[i] = *t.Transform(&entities[i])
+	}
+	return responses
+}
+
+// failingGetByIDsRepository возвращает ошибку при пакетном чтении
+type failingGetByIDsRepository struct {
+	*memoryRepository
+}
+
+func (failingGetByIDsRepository) GetByIDs(context.Context, []uint, ...repository.QueryOption) ([]auditEntity, error) {
+	return nil, stderrors.New("connection refused")
+}
+
+func TestServiceGetByIDs(t *testing.T) {
+	repo := &memoryRepository{items: map[uint]auditEntity{
+		1: {ID: 1, Name: "north"},
+		2: {ID: 2, Name: "south"},
+		3: {ID: 3, Name: "east"},
+	}}
+	svc := NewBaseService[auditEntity, labelResponse](repo, labelTransformer{}, nil, "region")
+
+	// Порядок ID в запросе не важен, отсутствующие ID не попадают в результат
+	responses, err := svc.GetByIDs(context.Background(), []uint{3, 99, 1})
+	if err != nil {
+		t.Fatalf("GetByIDs() error = %v", err)
+	}
+	if len(responses) != 2 || responses[1].Label != "NORTH" || responses[3].Label != "EAST" {
+		t.Errorf("GetByIDs() = %v, want transformed regions 1 and 3", responses)
+	}
+	if _, ok := responses[99]; ok {
+		t.Error("missing ID 99 must be absent")
+	}
+
+	for _, ids := range [][]uint{nil, {}} {
+		responses, err := svc.GetByIDs(context.Background(), ids)
+		if err != nil || responses == nil || len(responses) != 0 {
+			t.Errorf("GetByIDs(%v) = %v, %v; want empty non-nil map", ids, responses, err)
+		}
+	}
+}
+
+func TestServiceGetByIDsWrapsRepositoryError(t *testing.T) {
+	repo := failingGetByIDsRepository{&memoryRepository{}}
+	svc := NewBaseService[auditEntity, labelResponse](repo, labelTransformer{}, nil, "region")
+
+	if _, err := svc.GetByIDs(context.Background(), []uint{1}); !apperrors.IsInternal(err) {
+		t.Errorf("GetByIDs() error = %v, want internal", err)
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/vladzorgan/common/repository"
+)
+
+// modelRepository хранит модели устройств в памяти с уникальным индексом по name.
+// Первые readers чтений по полю дожидаются друг друга, чтобы параллельные вызовы не нашли запись.
+type modelRepository struct {
+	repository.Repository[auditEntity]
+
+	mu      sync.Mutex
+	items   map[string]auditEntity
+	inserts int
+	readers sync.WaitGroup
+	reads   int
+	barrier int
+}
+
+func newModelRepository(concurrent int) *modelRepository {
+	repo := &modelRepository{items: make(map[string]auditEntity), barrier: concurrent}
+	repo.readers.Add(concurrent)
+	return repo
+}
+
+func (r *modelRepository) GetByField(ctx context.Context, field string, value interface{}, opts ...repository.QueryOption) (*auditEntity, error) {
+	r.mu.Lock()
+	entity, exists := r.items[value.(string)]
+	r.reads++
+	wait := r.reads <= r.barrier
+	r.mu.Unlock()
+
+	if wait {
+		r.readers.Done()
+		r.readers.Wait()
+	}
+	if !exists {
+		return nil, nil
+	}
+	return &entity, nil
+}
+
+// GetOrCreate повторяет поведение репозитория: при занятом значении возвращается существующая запись
+func (r *modelRepository) GetOrCreate(ctx context.Context, field string, value interface{}, create func() *auditEntity) (*auditEntity, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entity, exists := r.items[value.(string)]; exists {
+		return &entity, false, nil
+	}
+	entity := create()
+	r.inserts++
+	entity.ID = uint(len(r.items) + 1)
+	r.items[value.(string)] = *entity
+	return entity, true, nil
+}
+
+type modelInput struct{ name string }
+
+func (i modelInput) ToEntity() *auditEntity { return &auditEntity{Name: i.name} }
+func (i modelInput) Validate() error        { return nil }
+
+// syncPublisher записывает события из нескольких горутин
+type syncPublisher struct {
+	mu sync.Mutex
+	recordingPublisher
+}
+
+func (p *syncPublisher) PublishEvent(ctx context.Context, key string, payload interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.recordingPublisher.PublishEvent(ctx, key, payload)
+}
+
+func TestGetOrCreateReturnsExisting(t *testing.T) {
+	repo := newModelRepository(0)
+	repo.items["iPhone 15"] = auditEntity{ID: 3, Name: "iPhone 15"}
+	publisher := &syncPublisher{}
+	s := NewBaseService[auditEntity, auditEntity](repo, auditTransformer{}, publisher, "device_model")
+
+	model, created, err := s.GetOrCreate(context.Background(), "name", "iPhone 15", modelInput{name: "iPhone 15"})
+	if err != nil {
+		t.Fatalf("GetOrCreate() error = %v", err)
+	}
+	if created || model.ID != 3 {
+		t.Errorf("GetOrCreate() = %+v, %v; want existing model 3", model, created)
+	}
+	if repo.inserts != 0 || len(publisher.keys) != 0 {
+		t.Errorf("inserts = %d, published = %v; want none", repo.inserts, publisher.keys)
+	}
+}
+
+func TestGetOrCreateConcurrentPublishesOnce(t *testing.T) {
+	const concurrent = 2
+	repo := newModelRepository(concurrent)
+	publisher := &syncPublisher{}
+	s := NewBaseService[auditEntity, auditEntity](repo, auditTransformer{}, publisher, "device_model")
+
+	var hooks int
+	var hooksMu sync.Mutex
+	s.OnAfterCreate(func(ctx context.Context, entity *auditEntity, response *auditEntity) error {
+		hooksMu.Lock()
+		defer hooksMu.Unlock()
+		hooks++
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	createdCh := make(chan bool, concurrent)
+	for i := 0; i < concurrent; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			model, created, err := s.GetOrCreate(context.Background(), "name", "iPhone 15", modelInput{name: "iPhone 15"})
+			if err != nil {
+				t.Errorf("GetOrCreate() error = %v", err)
+				return
+			}
+			if model.ID != 1 {
+				t.Errorf("GetOrCreate() ID = %d, want 1", model.ID)
+			}
+			createdCh <- created
+		}()
+	}
+	wg.Wait()
+	close(createdCh)
+
+	createdCount := 0
+	for created := range createdCh {
+		if created {
+			createdCount++
+		}
+	}
+	if createdCount != 1 {
+		t.Errorf("created = %d, want exactly one", createdCount)
+	}
+	if repo.inserts != 1 {
+		t.Errorf("inserts = %d, want 1", repo.inserts)
+	}
+	if len(publisher.keys) != 1 || publisher.keys[0] != s.routingKey("created") {
+		t.Errorf("published = %v, want [%s]", publisher.keys, s.routingKey("created"))
+	}
+	if hooks != 1 {
+		t.Errorf("AfterCreate hooks = %d, want 1", hooks)
+	}
+}
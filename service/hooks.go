@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+
+	apperrors "github.com/vladzorgan/common/errors"
+)
+
+// BeforeCreateHook вызывается в транзакции перед созданием сущности; ошибка отменяет создание
+type BeforeCreateHook[T BaseEntity] func(ctx context.Context, entity *T) error
+
+// AfterCreateHook вызывается после фиксации создания сущности
+type AfterCreateHook[T BaseEntity, R any] func(ctx context.Context, entity *T, response *R) error
+
+// BeforeUpdateHook вызывается в транзакции перед обновлением; может изменить updates, ошибка отменяет обновление
+type BeforeUpdateHook func(ctx context.Context, id uint, updates map[string]interface{}) error
+
+// AfterUpdateHook вызывается после фиксации обновления сущности
+type AfterUpdateHook[T BaseEntity, R any] func(ctx context.Context, entity *T, response *R) error
+
+// BeforeDeleteHook вызывается в транзакции перед удалением сущности; ошибка отменяет удаление
+type BeforeDeleteHook func(ctx context.Context, id uint) error
+
+// AfterDeleteHook вызывается после фиксации удаления сущности
+type AfterDeleteHook[T BaseEntity, R any] func(ctx context.Context, entity *T, response *R) error
+
+// BeforeBulkCreateHook вызывается в транзакции перед массовым созданием; ошибка отменяет создание
+type BeforeBulkCreateHook[T BaseEntity] func(ctx context.Context, entities []*T) error
+
+// AfterBulkCreateHook вызывается после фиксации массового создания
+type AfterBulkCreateHook[T BaseEntity, R any] func(ctx context.Context, entities []*T, responses []R) error
+
+// AfterBulkUpdateHook вызывается после фиксации массового обновления
+type AfterBulkUpdateHook[T BaseEntity, R any] func(ctx context.Context, entities []*T, responses []R) error
+
+// AfterBulkDeleteHook вызывается после фиксации массового удаления (DeleteMany, DeleteByFilter)
+// с сущностями в состоянии до удаления
+type AfterBulkDeleteHook[T BaseEntity] func(ctx context.Context, entities []*T) error
+
+// hooks содержит зарегистрированные обработчики операций сервиса
+type hooks[T BaseEntity, R any] struct {
+	beforeCreate     []BeforeCreateHook[T]
+	afterCreate      []AfterCreateHook[T, R]
+	beforeUpdate     []BeforeUpdateHook
+	afterUpdate      []AfterUpdateHook[T, R]
+	beforeDelete     []BeforeDeleteHook
+	afterDelete      []AfterDeleteHook[T, R]
+	beforeBulkCreate []BeforeBulkCreateHook[T]
+	afterBulkCreate  []AfterBulkCreateHook[T, R]
+	afterBulkUpdate  []AfterBulkUpdateHook[T, R]
+	afterBulkDelete  []AfterBulkDeleteHook[T]
+}
+
+// OnBeforeCreate регистрирует обработчик, вызываемый перед созданием сущности (в том числе в CreateOrUpdate)
+func (s *BaseService[T, R]) OnBeforeCreate(hook BeforeCreateHook[T]) *BaseService[T, R] {
+	s.hooks.beforeCreate = append(s.hooks.beforeCreate, hook)
+	return s
+}
+
+// OnAfterCreate регистрирует обработчик, вызываемый после создания сущности
+func (s *BaseService[T, R]) OnAfterCreate(hook AfterCreateHook[T, R]) *BaseService[T, R] {
+	s.hooks.afterCreate = append(s.hooks.afterCreate, hook)
+	return s
+}
+
+// OnBeforeUpdate регистрирует обработчик, вызываемый перед обновлением сущности
+func (s *BaseService[T, R]) OnBeforeUpdate(hook BeforeUpdateHook) *BaseService[T, R] {
+	s.hooks.beforeUpdate = append(s.hooks.beforeUpdate, hook)
+	return s
+}
+
+// OnAfterUpdate регистрирует обработчик, вызываемый после обновления сущности
+// (в том числе при обновлении существующей записи в CreateOrUpdate)
+func (s *BaseService[T, R]) OnAfterUpdate(hook AfterUpdateHook[T, R]) *BaseService[T, R] {
+	s.hooks.afterUpdate = append(s.hooks.afterUpdate, hook)
+	return s
+}
+
+// OnBeforeDelete регистрирует обработчик, вызываемый перед удалением сущности
+func (s *BaseService[T, R]) OnBeforeDelete(hook BeforeDeleteHook) *BaseService[T, R] {
+	s.hooks.beforeDelete = append(s.hooks.beforeDelete, hook)
+	return s
+}
+
+// OnAfterDelete регистрирует обработчик, вызываемый после удаления сущности
+func (s *BaseService[T, R]) OnAfterDelete(hook AfterDeleteHook[T, R]) *BaseService[T, R] {
+	s.hooks.afterDelete = append(s.hooks.afterDelete, hook)
+	return s
+}
+
+// OnBeforeBulkCreate регистрирует обработчик, вызываемый перед массовым созданием сущностей
+func (s *BaseService[T, R]) OnBeforeBulkCreate(hook BeforeBulkCreateHook[T]) *BaseService[T, R] {
+	s.hooks.beforeBulkCreate = append(s.hooks.beforeBulkCreate, hook)
+	return s
+}
+
+// OnAfterBulkCreate регистрирует обработчик, вызываемый после массового создания сущностей
+func (s *BaseService[T, R]) OnAfterBulkCreate(hook AfterBulkCreateHook[T, R]) *BaseService[T, R] {
+	s.hooks.afterBulkCreate = append(s.hooks.afterBulkCreate, hook)
+	return s
+}
+
+// OnAfterBulkUpdate регистрирует обработчик, вызываемый после массового обновления сущностей
+func (s *BaseService[T, R]) OnAfterBulkUpdate(hook AfterBulkUpdateHook[T, R]) *BaseService[T, R] {
+	s.hooks.afterBulkUpdate = append(s.hooks.afterBulkUpdate, hook)
+	return s
+}
+
+// OnAfterBulkDelete регистрирует обработчик, вызываемый после массового удаления сущностей
+func (s *BaseService[T, R]) OnAfterBulkDelete(hook AfterBulkDeleteHook[T]) *BaseService[T, R] {
+	s.hooks.afterBulkDelete = append(s.hooks.afterBulkDelete, hook)
+	return s
+}
+
+// runBeforeHooks вызывает обработчики в порядке регистрации до первой ошибки.
+// Ошибка или паника обработчика отменяет операцию.
+func (s *BaseService[T, R]) runBeforeHooks(stage string, id interface{}, count int, call func(i int) error) error {
+	for i := 0; i < count; i++ {
+		if err := callHook(func() error { return call(i) }); err != nil {
+			// Типизированные ошибки обработчика передаем вызывающему коду как есть
+			var appErr *apperrors.Error
+			if stderrors.As(err, &appErr) {
+				return err
+			}
+			return apperrors.Wrap(apperrors.ErrInternal, s.entity.Singular, id, err,
+				fmt.Sprintf("обработчик %s отменил операцию с %s", stage, s.entity.DisplayNameRu))
+		}
+	}
+	return nil
+}
+
+// runBeforeCreateHooks вызывает обработчики BeforeCreate для сущности
+func (s *BaseService[T, R]) runBeforeCreateHooks(ctx context.Context, entity *T) error {
+	return s.runBeforeHooks("BeforeCreate", nil, len(s.hooks.beforeCreate), func(i int) error {
+		return s.hooks.beforeCreate[i](ctx, entity)
+	})
+}
+
+// runAfterHooks вызывает обработчики в порядке регистрации после фиксации операции,
+// а в транзакции RunInTransaction - после ее фиксации (при откате не вызывает).
+// Операция уже выполнена, поэтому ошибки и паники обработчиков логируются, а остальные обработчики продолжают выполняться.
+func (s *BaseService[T, R]) runAfterHooks(ctx context.Context, stage string, count int, call func(ctx context.Context, i int) error) {
+	if count == 0 {
+		return
+	}
+
+	afterCommit(ctx, func(ctx context.Context) {
+		for i := 0; i < count; i++ {
+			if err := callHook(func() error { return call(ctx, i) }); err != nil {
+				log.Printf("Ошибка обработчика %s для %s: %v", stage, s.entity.DisplayNameRu, err)
+			}
+		}
+	})
+}
+
+// callHook вызывает обработчик, преобразуя панику в ошибку
+func callHook(hook func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic in hook: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return hook()
+}
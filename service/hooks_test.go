@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apperrors "github.com/vladzorgan/common/errors"
+)
+
+type hookEntity struct{ ID uint }
+
+func (e hookEntity) GetID() uint          { return e.ID }
+func (e hookEntity) GetName() string      { return "hook" }
+func (e hookEntity) GetTableName() string { return "hook_entities" }
+
+func newHookService() *BaseService[hookEntity, hookEntity] {
+	return NewBaseService[hookEntity, hookEntity](nil, nil, nil, "hook_entity")
+}
+
+func TestBeforeHooksRunInOrderUntilError(t *testing.T) {
+	s := newHookService()
+
+	var calls []int
+	s.OnBeforeCreate(func(ctx context.Context, entity *hookEntity) error {
+		calls = append(calls, 1)
+		return nil
+	}).OnBeforeCreate(func(ctx context.Context, entity *hookEntity) error {
+		calls = append(calls, 2)
+		return errors.New("slug taken")
+	}).OnBeforeCreate(func(ctx context.Context, entity *hookEntity) error {
+		calls = append(calls, 3)
+		return nil
+	})
+
+	err := s.runBeforeCreateHooks(context.Background(), &hookEntity{})
+	if !apperrors.IsInternal(err) {
+		t.Fatalf("expected internal error, got %v", err)
+	}
+	if len(calls) != 2 || calls[0] != 1 || calls[1] != 2 {
+		t.Errorf("calls = %v, want [1 2]", calls)
+	}
+}
+
+func TestBeforeHooksKeepTypedErrors(t *testing.T) {
+	s := newHookService().OnBeforeDelete(func(ctx context.Context, id uint) error {
+		return apperrors.Conflict("hook_entity", id, errors.New("in use"))
+	})
+
+	err := s.runBeforeHooks("BeforeDelete", uint(1), len(s.hooks.beforeDelete), func(i int) error {
+		return s.hooks.beforeDelete[i](context.Background(), 1)
+	})
+	if !apperrors.IsConflict(err) {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+}
+
+func TestBeforeHookPanicAbortsOperation(t *testing.T) {
+	s := newHookService().OnBeforeCreate(func(ctx context.Context, entity *hookEntity) error {
+		panic("boom")
+	})
+
+	if err := s.runBeforeCreateHooks(context.Background(), &hookEntity{}); err == nil {
+		t.Fatal("expected panic to be converted to error")
+	}
+}
+
+func TestAfterHooksContinueAfterFailure(t *testing.T) {
+	s := newHookService()
+
+	var calls []int
+	s.OnAfterCreate(func(ctx context.Context, entity *hookEntity, response *hookEntity) error {
+		calls = append(calls, 1)
+		panic("boom")
+	}).OnAfterCreate(func(ctx context.Context, entity *hookEntity, response *hookEntity) error {
+		calls = append(calls, 2)
+		return errors.New("cache unavailable")
+	}).OnAfterCreate(func(ctx context.Context, entity *hookEntity, response *hookEntity) error {
+		calls = append(calls, 3)
+		return nil
+	})
+
+	s.runAfterHooks(context.Background(), "AfterCreate", len(s.hooks.afterCreate), func(ctx context.Context, i int) error {
+		return s.hooks.afterCreate[i](ctx, &hookEntity{}, &hookEntity{})
+	})
+
+	if len(calls) != 3 {
+		t.Errorf("calls = %v, want [1 2 3]", calls)
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+
+	"github.com/vladzorgan/common/cache"
+)
+
+// WithInvalidationBus рассылает через bus инвалидацию записей сущности после
+// создания, обновления и удаления, в том числе массовых операций и CreateOrUpdate.
+// Кеши сущности регистрируются в bus под именем Entity().Singular.
+func (s *BaseService[T, R]) WithInvalidationBus(bus *cache.Bus) *BaseService[T, R] {
+	invalidate := func(ctx context.Context, entity *T, _ *R) error {
+		return bus.InvalidateIDs(ctx, s.entity.Singular, (*entity).GetID())
+	}
+
+	s.OnAfterCreate(invalidate)
+	s.OnAfterUpdate(invalidate)
+	s.OnAfterDelete(invalidate)
+	// Пустой список ID инвалидировал бы все записи сущности
+	invalidateMany := func(ctx context.Context, entities []*T) error {
+		if len(entities) == 0 {
+			return nil
+		}
+		ids := make([]uint, 0, len(entities))
+		for _, entity := range entities {
+			ids = append(ids, (*entity).GetID())
+		}
+		return bus.InvalidateIDs(ctx, s.entity.Singular, ids...)
+	}
+
+	s.OnAfterBulkCreate(func(ctx context.Context, entities []*T, _ []R) error {
+		return invalidateMany(ctx, entities)
+	})
+	s.OnAfterBulkUpdate(func(ctx context.Context, entities []*T, _ []R) error {
+		return invalidateMany(ctx, entities)
+	})
+	s.OnAfterBulkDelete(invalidateMany)
+
+	return s
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/vladzorgan/common/cache"
+	"github.com/vladzorgan/common/repository"
+)
+
+// recordingTransport запоминает разосланные сообщения об инвалидации
+type recordingTransport struct {
+	invalidations []cache.Invalidation
+}
+
+func (t *recordingTransport) Publish(_ context.Context, invalidation cache.Invalidation) error {
+	t.invalidations = append(t.invalidations, invalidation)
+	return nil
+}
+
+// bulkRepository дополняет memoryRepository массовыми операциями и upsert по имени
+type bulkRepository struct {
+	*memoryRepository
+	nextID uint
+}
+
+func (r *bulkRepository) BulkCreate(ctx context.Context, entities []*auditEntity) error {
+	for _, entity := range entities {
+		r.nextID++
+		entity.ID = r.nextID
+		r.items[entity.ID] = *entity
+	}
+	return nil
+}
+
+func (r *bulkRepository) BulkUpdate(ctx context.Context, updates []repository.BulkUpdateItem) error {
+	for _, update := range updates {
+		if _, err := r.Update(ctx, update.ID, update.Updates); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *bulkRepository) Upsert(ctx context.Context, entity *auditEntity, _ []string, _ []string) (bool, error) {
+	for id, existing := range r.items {
+		if existing.Name == entity.Name {
+			entity.ID = id
+			return false, nil
+		}
+	}
+	return true, r.BulkCreate(ctx, []*auditEntity{entity})
+}
+
+func (r *bulkRepository) GetAll(ctx context.Context, _, _ int, filters map[string]interface{}, _ *repository.SortOptions, _ ...repository.QueryOption) ([]auditEntity, int64, error) {
+	var entities []auditEntity
+	for _, entity := range r.items {
+		if entity.Name == filters["name"] {
+			entities = append(entities, entity)
+		}
+	}
+	return entities, int64(len(entities)), nil
+}
+
+func (r *bulkRepository) DeleteByFilter(ctx context.Context, filters map[string]interface{}, _ ...repository.QueryOption) (int64, error) {
+	entities, _, _ := r.GetAll(ctx, 0, -1, filters, nil)
+	for _, entity := range entities {
+		delete(r.items, entity.ID)
+	}
+	return int64(len(entities)), nil
+}
+
+type bulkUpdate struct {
+	id   uint
+	name string
+}
+
+func (u bulkUpdate) GetID() uint { return u.id }
+func (u bulkUpdate) ToUpdateMap() map[string]interface{} {
+	return map[string]interface{}{"name": u.name}
+}
+func (u bulkUpdate) Validate() error { return nil }
+
+func TestInvalidationBusCoversBulkOperations(t *testing.T) {
+	repo := &bulkRepository{memoryRepository: &memoryRepository{items: map[uint]auditEntity{}}}
+	transport := &recordingTransport{}
+	s := NewBaseService[auditEntity, auditEntity](repo, auditTransformer{}, nil, "audit_entity").
+		WithInvalidationBus(cache.NewBus(transport, nil))
+	ctx := context.Background()
+
+	// invalidated возвращает отсортированные ID последнего сообщения и сбрасывает журнал
+	invalidated := func(operation string) []uint {
+		t.Helper()
+		if len(transport.invalidations) != 1 {
+			t.Fatalf("%s: invalidations = %+v, want exactly one", operation, transport.invalidations)
+		}
+		invalidation := transport.invalidations[0]
+		transport.invalidations = nil
+		if invalidation.Entity != "audit_entity" {
+			t.Errorf("%s: entity = %s, want audit_entity", operation, invalidation.Entity)
+		}
+		ids := append([]uint(nil), invalidation.IDs...)
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		return ids
+	}
+
+	if _, err := s.BulkCreate(ctx, []CreateInput[auditEntity]{modelInput{"a"}, modelInput{"b"}, modelInput{"c"}}); err != nil {
+		t.Fatalf("BulkCreate() error = %v", err)
+	}
+	if got := invalidated("BulkCreate"); !reflect.DeepEqual(got, []uint{1, 2, 3}) {
+		t.Errorf("BulkCreate invalidated %v, want [1 2 3]", got)
+	}
+
+	if _, err := s.BulkUpdate(ctx, []BulkUpdateInput[auditEntity]{bulkUpdate{1, "x"}, bulkUpdate{2, "x"}}); err != nil {
+		t.Fatalf("BulkUpdate() error = %v", err)
+	}
+	if got := invalidated("BulkUpdate"); !reflect.DeepEqual(got, []uint{1, 2}) {
+		t.Errorf("BulkUpdate invalidated %v, want [1 2]", got)
+	}
+
+	// Upsert существующей записи - обновление, новой - создание
+	if _, err := s.CreateOrUpdate(ctx, modelInput{"c"}, []string{"name"}); err != nil {
+		t.Fatalf("CreateOrUpdate() error = %v", err)
+	}
+	if got := invalidated("CreateOrUpdate"); !reflect.DeepEqual(got, []uint{3}) {
+		t.Errorf("CreateOrUpdate invalidated %v, want [3]", got)
+	}
+	if _, err := s.CreateOrUpdate(ctx, modelInput{"d"}, []string{"name"}); err != nil {
+		t.Fatalf("CreateOrUpdate() error = %v", err)
+	}
+	if got := invalidated("CreateOrUpdate"); !reflect.DeepEqual(got, []uint{4}) {
+		t.Errorf("CreateOrUpdate invalidated %v, want [4]", got)
+	}
+
+	if _, err := s.DeleteByFilter(ctx, map[string]interface{}{"name": "x"}); err != nil {
+		t.Fatalf("DeleteByFilter() error = %v", err)
+	}
+	if got := invalidated("DeleteByFilter"); !reflect.DeepEqual(got, []uint{1, 2}) {
+		t.Errorf("DeleteByFilter invalidated %v, want [1 2]", got)
+	}
+
+	if _, err := s.DeleteMany(ctx, []uint{3, 4}); err != nil {
+		t.Fatalf("DeleteMany() error = %v", err)
+	}
+	if got := invalidated("DeleteMany"); !reflect.DeepEqual(got, []uint{3, 4}) {
+		t.Errorf("DeleteMany invalidated %v, want [3 4]", got)
+	}
+
+	// Пустой результат не рассылает инвалидацию всей сущности
+	if _, err := s.DeleteByFilter(ctx, map[string]interface{}{"name": "missing"}); err != nil {
+		t.Fatalf("DeleteByFilter() error = %v", err)
+	}
+	if len(transport.invalidations) != 0 {
+		t.Errorf("invalidations = %+v, want none for an empty delete", transport.invalidations)
+	}
+}
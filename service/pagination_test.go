@@ -0,0 +1,44 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/vladzorgan/common/repository"
+)
+
+func TestCalculatePagination(t *testing.T) {
+	s := &serviceCore[auditEntity, auditEntity]{}
+
+	if got := s.calculatePagination(45, 20, 20); got != (Pagination{Total: 45, Page: 2, Size: 20, Pages: 3}) {
+		t.Errorf("calculatePagination() = %+v", got)
+	}
+
+	got := s.calculatePagination(repository.TotalUnknown, 20, 20)
+	if got != (Pagination{Total: -1, Page: 2, Size: 20}) || got.HasTotal() {
+		t.Errorf("calculatePagination(TotalUnknown) = %+v", got)
+	}
+}
+
+func TestPaginationJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		pagination Pagination
+		want       string
+	}{
+		{"with total", Pagination{Total: 0, Page: 1, Size: 20}, `{"total":0,"page":1,"size":20,"pages":0}`},
+		{"without total", Pagination{Total: -1, Page: 2, Size: 20}, `{"page":2,"size":20}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(PaginationResponse[string]{Items: []string{}, Pagination: tt.pagination})
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if want := `{"items":[],"pagination":` + tt.want + `}`; string(data) != want {
+				t.Errorf("json = %s, want %s", data, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MergePatch представляет документ JSON Merge Patch (RFC 7396) верхнего уровня.
+// Сохраняет порядок полей и отличает отсутствующее поле от поля со значением null.
+type MergePatch struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+// DecodeMergePatch разбирает тело PATCH запроса. Документ должен быть JSON объектом
+// без повторяющихся ключей.
+func DecodeMergePatch(data []byte) (*MergePatch, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("некорректный JSON: %v", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, errors.New("merge patch должен быть JSON объектом")
+	}
+
+	patch := &MergePatch{values: make(map[string]json.RawMessage)}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("некорректный JSON: %v", err)
+		}
+		key := token.(string)
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, fmt.Errorf("некорректное значение поля %s: %v", key, err)
+		}
+		if _, exists := patch.values[key]; exists {
+			return nil, fmt.Errorf("поле %s указано несколько раз", key)
+		}
+
+		patch.keys = append(patch.keys, key)
+		patch.values[key] = value
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("некорректный JSON: %v", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("лишние данные после JSON объекта")
+	}
+
+	return patch, nil
+}
+
+// Keys возвращает поля документа в порядке их следования
+func (p *MergePatch) Keys() []string {
+	return p.keys
+}
+
+// Has проверяет, присутствует ли поле в документе
+func (p *MergePatch) Has(key string) bool {
+	_, ok := p.values[key]
+	return ok
+}
+
+// IsNull проверяет, что поле присутствует и равно null (требование очистить значение)
+func (p *MergePatch) IsNull(key string) bool {
+	value, ok := p.values[key]
+	return ok && string(value) == "null"
+}
+
+// Raw возвращает JSON значение поля
+func (p *MergePatch) Raw(key string) (json.RawMessage, bool) {
+	value, ok := p.values[key]
+	return value, ok
+}
+
+// PatchField описывает поле сущности, которое можно изменять через merge patch
+type PatchField struct {
+	// Column колонка в базе данных; по умолчанию совпадает с именем поля
+	Column string
+	// Nullable разрешает очищать поле значением null
+	Nullable bool
+	// JSON колонка типа jsonb: вложенные объекты объединяются по RFC 7396 функцией
+	// jsonb_merge_patch (см. database.EnsureMergePatchFunction), остальные значения заменяют текущее
+	JSON bool
+}
+
+// PatchSchema список полей сущности, разрешенных для изменения через merge patch.
+// Поля, не входящие в схему (идентификатор, даты, вычисляемые поля), отклоняются.
+type PatchSchema map[string]PatchField
+
+// PatchInput реализует UpdateInput для документа merge patch
+type PatchInput[T BaseEntity] struct {
+	updates map[string]interface{}
+	err     error
+}
+
+// NewPatchInput создает UpdateInput, преобразующий merge patch в карту обновлений по схеме
+func NewPatchInput[T BaseEntity](patch *MergePatch, schema PatchSchema) *PatchInput[T] {
+	input := &PatchInput[T]{}
+	input.updates, input.err = patch.ToUpdateMap(schema)
+	return input
+}
+
+// Validate проверяет поля документа по схеме
+func (i *PatchInput[T]) Validate() error {
+	return i.err
+}
+
+// ToUpdateMap возвращает карту обновлений для BaseService.Update
+func (i *PatchInput[T]) ToUpdateMap() map[string]interface{} {
+	return i.updates
+}
+
+// ToUpdateMap преобразует документ в карту обновлений, совместимую с UpdateInput:
+// отсутствующие поля пропускаются, null превращается в NULL, вложенные объекты
+// JSON колонок объединяются с текущим значением, массивы и скаляры заменяют его.
+func (p *MergePatch) ToUpdateMap(schema PatchSchema) (map[string]interface{}, error) {
+	var rejected []string
+	var problems []string
+	updates := make(map[string]interface{}, len(p.keys))
+
+	for _, key := range p.keys {
+		field, ok := schema[key]
+		if !ok {
+			rejected = append(rejected, key)
+			continue
+		}
+
+		column := field.Column
+		if column == "" {
+			column = key
+		}
+
+		value, err := patchValue(field, column, p.values[key])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		updates[column] = value
+	}
+
+	if len(rejected) > 0 {
+		sort.Strings(rejected)
+		problems = append([]string{"поля нельзя изменять: " + strings.Join(rejected, ", ")}, problems...)
+	}
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+
+	return updates, nil
+}
+
+// patchValue возвращает значение колонки для поля merge patch
+func patchValue(field PatchField, column string, raw json.RawMessage) (interface{}, error) {
+	trimmed := bytes.TrimSpace(raw)
+
+	if string(trimmed) == "null" {
+		if !field.Nullable {
+			return nil, errors.New("поле не может быть пустым")
+		}
+		return gorm.Expr("NULL"), nil
+	}
+
+	if field.JSON {
+		if trimmed[0] == '{' {
+			return gorm.Expr("jsonb_merge_patch(?, ?::jsonb)", clause.Column{Name: column}, string(trimmed)), nil
+		}
+		return gorm.Expr("?::jsonb", string(trimmed)), nil
+	}
+
+	switch trimmed[0] {
+	case '{', '[':
+		return nil, errors.New("ожидается скалярное значение")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	if number, ok := value.(json.Number); ok {
+		if n, err := number.Int64(); err == nil {
+			return n, nil
+		}
+		return number.Float64()
+	}
+	return value, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm/clause"
+)
+
+var customerPatchSchema = PatchSchema{
+	"name":     {},
+	"phone":    {Nullable: true},
+	"email":    {Column: "email_address", Nullable: true},
+	"age":      {},
+	"settings": {JSON: true, Nullable: true},
+	"tags":     {JSON: true},
+}
+
+func mustDecodePatch(t *testing.T, body string) *MergePatch {
+	t.Helper()
+	patch, err := DecodeMergePatch([]byte(body))
+	if err != nil {
+		t.Fatalf("DecodeMergePatch(%s) error = %v", body, err)
+	}
+	return patch
+}
+
+func TestDecodeMergePatchDistinguishesAbsentAndNull(t *testing.T) {
+	patch := mustDecodePatch(t, `{"phone": null, "name": "Ivan", "age": 30}`)
+
+	if got := strings.Join(patch.Keys(), ","); got != "phone,name,age" {
+		t.Errorf("Keys() = %s, want phone,name,age", got)
+	}
+	if !patch.IsNull("phone") || patch.IsNull("name") {
+		t.Error("IsNull() does not distinguish null from value")
+	}
+	if patch.Has("email") || patch.IsNull("email") {
+		t.Error("absent field reported as present")
+	}
+}
+
+func TestDecodeMergePatchRejectsInvalidDocuments(t *testing.T) {
+	for _, body := range []string{
+		`[{"name": "Ivan"}]`,
+		`"Ivan"`,
+		`{"name": "Ivan", "name": "Petr"}`,
+		`{"name": "Ivan"} {}`,
+		`{"name": `,
+	} {
+		if _, err := DecodeMergePatch([]byte(body)); err == nil {
+			t.Errorf("DecodeMergePatch(%s) expected error", body)
+		}
+	}
+}
+
+func TestMergePatchNullClearsField(t *testing.T) {
+	updates, err := mustDecodePatch(t, `{"phone": null, "email": null}`).ToUpdateMap(customerPatchSchema)
+	if err != nil {
+		t.Fatalf("ToUpdateMap() error = %v", err)
+	}
+
+	for _, column := range []string{"phone", "email_address"} {
+		expr, ok := updates[column].(clause.Expr)
+		if !ok || expr.SQL != "NULL" {
+			t.Errorf("updates[%s] = %#v, want NULL expression", column, updates[column])
+		}
+	}
+	if len(updates) != 2 {
+		t.Errorf("updates = %v, absent fields must be omitted", updates)
+	}
+}
+
+func TestMergePatchScalars(t *testing.T) {
+	updates, err := mustDecodePatch(t, `{"name": "Ivan", "age": 30}`).ToUpdateMap(customerPatchSchema)
+	if err != nil {
+		t.Fatalf("ToUpdateMap() error = %v", err)
+	}
+
+	if updates["name"] != "Ivan" || updates["age"] != int64(30) {
+		t.Errorf("updates = %#v", updates)
+	}
+}
+
+func TestMergePatchNestedObjectsAndArrays(t *testing.T) {
+	updates, err := mustDecodePatch(t, `{"settings": {"notify": {"sms": null, "email": true}}, "tags": ["vip"]}`).
+		ToUpdateMap(customerPatchSchema)
+	if err != nil {
+		t.Fatalf("ToUpdateMap() error = %v", err)
+	}
+
+	// Вложенный объект объединяется с текущим значением колонки
+	settings, ok := updates["settings"].(clause.Expr)
+	if !ok || settings.SQL != "jsonb_merge_patch(?, ?::jsonb)" {
+		t.Fatalf("updates[settings] = %#v, want jsonb_merge_patch expression", updates["settings"])
+	}
+	if column, ok := settings.Vars[0].(clause.Column); !ok || column.Name != "settings" {
+		t.Errorf("merge target = %#v, want settings column", settings.Vars[0])
+	}
+	if settings.Vars[1] != `{"notify": {"sms": null, "email": true}}` {
+		t.Errorf("merge patch = %v", settings.Vars[1])
+	}
+
+	// Массив по RFC 7396 заменяет текущее значение целиком
+	tags, ok := updates["tags"].(clause.Expr)
+	if !ok || tags.SQL != "?::jsonb" || tags.Vars[0] != `["vip"]` {
+		t.Errorf("updates[tags] = %#v, want replacement", updates["tags"])
+	}
+}
+
+func TestMergePatchRejectsReadOnlyAndInvalidFields(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"read-only fields", `{"id": 5, "created_at": "2024-01-01", "name": "Ivan"}`, "поля нельзя изменять: created_at, id"},
+		{"null for required field", `{"name": null}`, "name: поле не может быть пустым"},
+		{"object for scalar field", `{"name": {"first": "Ivan"}}`, "name: ожидается скалярное значение"},
+		{"array for scalar field", `{"age": [1, 2]}`, "age: ожидается скалярное значение"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := NewPatchInput[hookEntity](mustDecodePatch(t, tt.body), customerPatchSchema)
+
+			err := input.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
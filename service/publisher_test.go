@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+type recordingPublisher struct {
+	keys     []string
+	payloads []interface{}
+}
+
+func (p *recordingPublisher) PublishEvent(ctx context.Context, key string, payload interface{}) error {
+	p.keys = append(p.keys, key)
+	p.payloads = append(p.payloads, payload)
+	return nil
+}
+
+func TestBaseServiceAcceptsBrokerAgnosticPublisher(t *testing.T) {
+	publisher := &recordingPublisher{}
+	repo := &memoryRepository{items: map[uint]auditEntity{1: {ID: 1, Name: "old"}}}
+	s := NewBaseService[auditEntity, auditEntity](repo, auditTransformer{}, publisher, "audit_entity")
+
+	if _, err := s.Delete(context.Background(), 1); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if len(publisher.keys) != 1 || publisher.keys[0] != s.routingKey("deleted") {
+		t.Errorf("published = %v, want [%s]", publisher.keys, s.routingKey("deleted"))
+	}
+}
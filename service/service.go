@@ -2,12 +2,20 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"log"
 	"time"
 
+	"github.com/vladzorgan/common/database"
+	apperrors "github.com/vladzorgan/common/errors"
+	"github.com/vladzorgan/common/messaging"
 	"github.com/vladzorgan/common/repository"
 	events "github.com/vladzorgan/common/messaging/rabbitmq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
 )
 
 // BaseEntity представляет базовую сущность с общими полями
@@ -22,7 +30,8 @@ type PaginationResponse[T any] struct {
 	Pagination Pagination `json:"pagination"`
 }
 
-// Pagination представляет информацию о пагинации
+// Pagination представляет информацию о пагинации.
+// При запросе с опцией repository.WithoutTotal Total равен -1, а total и pages не выводятся в JSON.
 type Pagination struct {
 	Total int `json:"total"`
 	Page  int `json:"page"`
@@ -30,37 +39,63 @@ type Pagination struct {
 	Pages int `json:"pages"`
 }
 
+// HasTotal сообщает, известно ли общее количество записей
+func (p Pagination) HasTotal() bool {
+	return p.Total >= 0
+}
+
+// MarshalJSON пропускает total и pages, если общее количество записей не запрашивалось
+func (p Pagination) MarshalJSON() ([]byte, error) {
+	if p.HasTotal() {
+		type pagination Pagination
+		return json.Marshal(pagination(p))
+	}
+
+	return json.Marshal(struct {
+		Page int `json:"page"`
+		Size int `json:"size"`
+	}{Page: p.Page, Size: p.Size})
+}
+
 // Service определяет универсальный интерфейс сервиса
 type Service[T BaseEntity, R any] interface {
 	// CRUD операции
 	Create(ctx context.Context, input CreateInput[T]) (*R, error)
-	GetByID(ctx context.Context, id uint) (*R, error)
+	GetByID(ctx context.Context, id uint, opts ...repository.QueryOption) (*R, error)
+	GetByIDs(ctx context.Context, ids []uint, opts ...repository.QueryOption) (map[uint]R, error)
 	Update(ctx context.Context, id uint, input UpdateInput[T]) (*R, error)
 	Delete(ctx context.Context, id uint) (*R, error)
+	CreateOrUpdate(ctx context.Context, input CreateInput[T], conflictColumns []string) (*R, error)
+	GetOrCreate(ctx context.Context, field string, value interface{}, input CreateInput[T]) (*R, bool, error)
 	
 	// Массовые операции
 	BulkCreate(ctx context.Context, inputs []CreateInput[T]) ([]R, error)
 	BulkUpdate(ctx context.Context, updates []BulkUpdateInput[T]) ([]R, error)
+	DeleteMany(ctx context.Context, ids []uint) (int64, error)
+	DeleteByFilter(ctx context.Context, filters map[string]interface{}, opts ...repository.QueryOption) (int64, error)
 	
 	// Операции с коллекциями
-	GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions) (*PaginationResponse[R], error)
-	Search(ctx context.Context, keyword string, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions) (*PaginationResponse[R], error)
-	GetByField(ctx context.Context, field string, value interface{}) (*R, error)
-	GetAllByField(ctx context.Context, field string, value interface{}, skip, limit int) (*PaginationResponse[R], error)
+	GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) (*PaginationResponse[R], error)
+	Search(ctx context.Context, keyword string, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) (*PaginationResponse[R], error)
+	GetByField(ctx context.Context, field string, value interface{}, opts ...repository.QueryOption) (*R, error)
+	GetBySlug(ctx context.Context, slug string) (*R, error)
+	GetAllByField(ctx context.Context, field string, value interface{}, skip, limit int, opts ...repository.QueryOption) (*PaginationResponse[R], error)
+	Stream(ctx context.Context, filters map[string]interface{}, sort *repository.SortOptions, fn func(response *R) error) error
+	StreamTransformed(ctx context.Context, filters map[string]interface{}, sort *repository.SortOptions, batchSize int) (<-chan R, <-chan error)
 	
 	// Дополнительные операции
-	Count(ctx context.Context, filters map[string]interface{}) (int64, error)
+	Count(ctx context.Context, filters map[string]interface{}, opts ...repository.QueryOption) (int64, error)
 	Exists(ctx context.Context, id uint) (bool, error)
 }
 
 // CreateInput представляет входные данные для создания
-type CreateInput[T BaseEntity] interface {
+type CreateInput[T any] interface {
 	ToEntity() *T
 	Validate() error
 }
 
 // UpdateInput представляет входные данные для обновления
-type UpdateInput[T BaseEntity] interface {
+type UpdateInput[T any] interface {
 	ToUpdateMap() map[string]interface{}
 	Validate() error
 }
@@ -73,59 +108,292 @@ type BulkUpdateInput[T BaseEntity] interface {
 }
 
 // EntityTransformer определяет интерфейс для преобразования сущностей
-type EntityTransformer[T BaseEntity, R any] interface {
+type EntityTransformer[T any, R any] interface {
 	Transform(entity *T) *R
 	TransformSlice(entities []T) []R
 }
 
+// entityReader операции чтения коллекций, общие для Repository и UUIDRepository
+type entityReader[T any] interface {
+	GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) ([]T, int64, error)
+	Search(ctx context.Context, keyword string, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) ([]T, int64, error)
+	GetByField(ctx context.Context, field string, value interface{}, opts ...repository.QueryOption) (*T, error)
+	GetAllByField(ctx context.Context, field string, value interface{}, skip, limit int, opts ...repository.QueryOption) ([]T, int64, error)
+	Stream(ctx context.Context, filters map[string]interface{}, sort *repository.SortOptions, fn func(entity *T) error) error
+	StreamBatched(ctx context.Context, filters map[string]interface{}, sort *repository.SortOptions, batchSize int) (<-chan T, <-chan error)
+	Count(ctx context.Context, filters map[string]interface{}, opts ...repository.QueryOption) (int64, error)
+}
+
+// serviceCore содержит операции, не зависящие от типа ID; общая часть BaseService и BaseUUIDService
+type serviceCore[T any, R any] struct {
+	reader      entityReader[T]
+	transformer EntityTransformer[T, R]
+	publisher   messaging.Publisher
+	txRunner    database.TxRunner
+	entity      EntityDescriptor
+}
+
 // BaseService представляет базовую реализацию сервиса
 type BaseService[T BaseEntity, R any] struct {
-	repo        repository.Repository[T]
-	transformer EntityTransformer[T, R]
-	publisher   *events.Publisher
-	entityName  string
+	serviceCore[T, R]
+	repo repository.Repository[T]
+
+	deletePolicy *DeletePolicy
+	hooks        hooks[T, R]
+	audit        AuditLogger
+	slugField    string
+}
+
+// pendingEvent представляет событие, ожидающее фиксации транзакции
+type pendingEvent struct {
+	name string
+	data interface{}
+}
+
+// NewBaseService создает новый экземпляр BaseService.
+// Сущность задается строкой (имя используется без изменений, см. DescribeEntity) или EntityDescriptor,
+// например DescribeEntityNormalized("DeviceBrand") для ключей вида "device_brand.created".
+// Издателем может быть любая реализация messaging.Publisher, например *rabbitmq.Publisher.
+func NewBaseService[T BaseEntity, R any, E EntityName](
+	repo repository.Repository[T],
+	transformer EntityTransformer[T, R],
+	publisher messaging.Publisher,
+	entity E,
+) *BaseService[T, R] {
+	return NewBaseServiceWithTx(repo, transformer, publisher, entity, nil)
 }
 
-// NewBaseService создает новый экземпляр BaseService
-func NewBaseService[T BaseEntity, R any](
+// NewBaseServiceWithTx создает экземпляр BaseService, выполняющий операции записи
+// в транзакции txRunner (например, *database.Database).
+// События публикуются только после фиксации транзакции.
+func NewBaseServiceWithTx[T BaseEntity, R any, E EntityName](
 	repo repository.Repository[T],
 	transformer EntityTransformer[T, R],
-	publisher *events.Publisher,
-	entityName string,
+	publisher messaging.Publisher,
+	entity E,
+	txRunner database.TxRunner,
 ) *BaseService[T, R] {
 	return &BaseService[T, R]{
+		serviceCore: newServiceCore[T, R](repo, transformer, publisher, entity, txRunner),
 		repo:        repo,
+	}
+}
+
+// newServiceCore создает общую часть сервиса и регистрирует имя сущности для сообщений об ошибках
+func newServiceCore[T any, R any, E EntityName](
+	reader entityReader[T],
+	transformer EntityTransformer[T, R],
+	publisher messaging.Publisher,
+	entity E,
+	txRunner database.TxRunner,
+) serviceCore[T, R] {
+	// Типизированный nil не должен считаться настроенным издателем
+	if p, ok := publisher.(*events.Publisher); ok && p == nil {
+		publisher = nil
+	}
+
+	if db, ok := txRunner.(*database.Database); ok && db == nil {
+		txRunner = nil
+	}
+
+	descriptor := toEntityDescriptor(entity)
+	apperrors.RegisterEntityName(descriptor.Singular, descriptor.DisplayNameRu)
+
+	return serviceCore[T, R]{
+		reader:      reader,
 		transformer: transformer,
 		publisher:   publisher,
-		entityName:  entityName,
+		txRunner:    txRunner,
+		entity:      descriptor,
 	}
 }
 
-// Create создает новую сущность
+// Entity возвращает описание сущности сервиса
+func (s *serviceCore[T, R]) Entity() EntityDescriptor {
+	return s.entity
+}
+
+// Create создает новую сущность. Для сущностей Sluggable без slug генерируется уникальный slug
+// (см. Sluggable); если его заняла параллельно созданная запись, создание повторяется с новым slug.
 func (s *BaseService[T, R]) Create(ctx context.Context, input CreateInput[T]) (*R, error) {
 	// Валидация входных данных
-	if err := input.Validate(); err != nil {
-		return nil, fmt.Errorf("ошибка валидации: %v", err)
+	if err := validateInput(ctx, input); err != nil {
+		return nil, validationError(s.entity.Singular, err)
 	}
 	
 	// Создаем сущность
 	entity := input.ToEntity()
-	if err := s.repo.Create(ctx, entity); err != nil {
-		return nil, fmt.Errorf("не удалось создать %s: %v", s.entityName, err)
+	var err error
+	for attempt := 1; ; attempt++ {
+		var generated bool
+		err = s.runWrite(ctx, func(ctx context.Context, repo repository.Repository[T], pending *[]pendingEvent) error {
+			if err := s.runBeforeCreateHooks(ctx, entity); err != nil {
+				return err
+			}
+			
+			var err error
+			if generated, err = s.assignSlug(ctx, repo, entity); err != nil {
+				return err
+			}
+			
+			if err := repo.Create(ctx, entity); err != nil {
+				return s.wrapRepoError(err, nil, fmt.Sprintf("не удалось создать %s", s.entity.DisplayNameRu))
+			}
+			
+			if err := s.recordAudit(ctx, AuditActionCreate, (*entity).GetID(), nil, entity); err != nil {
+				return err
+			}
+			
+			// Публикуем событие о создании после фиксации
+			*pending = append(*pending, s.entityEvent("created", entity, nil))
+			return nil
+		})
+		if !retrySlug(entity, generated, attempt, err) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
 	}
 	
-	log.Printf("Создан новый %s: %s (ID: %d)", s.entityName, (*entity).GetName(), (*entity).GetID())
+	log.Printf("Создан новый %s: %s (ID: %d)", s.entity.DisplayNameRu, (*entity).GetName(), (*entity).GetID())
 	
-	// Публикуем событие о создании
-	if s.publisher != nil {
-		s.publishEvent(ctx, "created", entity, nil)
+	// Преобразуем в ответ
+	response := s.transformer.Transform(entity)
+	s.runAfterHooks(ctx, "AfterCreate", len(s.hooks.afterCreate), func(ctx context.Context, i int) error {
+		return s.hooks.afterCreate[i](ctx, entity, response)
+	})
+	return response, nil
+}
+
+// CreateOrUpdate создает сущность или обновляет существующую с тем же значением conflictColumns.
+// Публикуется событие created или updated в зависимости от того, существовала ли запись.
+func (s *BaseService[T, R]) CreateOrUpdate(ctx context.Context, input CreateInput[T], conflictColumns []string) (*R, error) {
+	// Валидация входных данных
+	if err := validateInput(ctx, input); err != nil {
+		return nil, validationError(s.entity.Singular, err)
+	}
+	
+	entity := input.ToEntity()
+	var inserted bool
+	err := s.runWrite(ctx, func(ctx context.Context, repo repository.Repository[T], pending *[]pendingEvent) error {
+		if err := s.runBeforeCreateHooks(ctx, entity); err != nil {
+			return err
+		}
+		
+		var err error
+		inserted, err = repo.Upsert(ctx, entity, conflictColumns, nil)
+		if err != nil {
+			return s.wrapRepoError(err, nil, fmt.Sprintf("не удалось сохранить %s", s.entity.DisplayNameRu))
+		}
+		
+		// Состояние до обновления существующей записи при upsert не читается
+		action := AuditActionCreate
+		if !inserted {
+			action = AuditActionUpdate
+		}
+		if err := s.recordAudit(ctx, action, (*entity).GetID(), nil, entity); err != nil {
+			return err
+		}
+		
+		// Публикуем событие о создании или обновлении после фиксации
+		if inserted {
+			*pending = append(*pending, s.entityEvent("created", entity, nil))
+		} else {
+			*pending = append(*pending, s.entityEvent("updated", entity, nil))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	if inserted {
+		log.Printf("Создан новый %s: %s (ID: %d)", s.entity.DisplayNameRu, (*entity).GetName(), (*entity).GetID())
+	} else {
+		log.Printf("Обновлен %s: %s (ID: %d)", s.entity.DisplayNameRu, (*entity).GetName(), (*entity).GetID())
 	}
 	
-	// Преобразуем в ответ
 	response := s.transformer.Transform(entity)
+	if inserted {
+		s.runAfterHooks(ctx, "AfterCreate", len(s.hooks.afterCreate), func(ctx context.Context, i int) error {
+			return s.hooks.afterCreate[i](ctx, entity, response)
+		})
+	} else {
+		s.runAfterHooks(ctx, "AfterUpdate", len(s.hooks.afterUpdate), func(ctx context.Context, i int) error {
+			return s.hooks.afterUpdate[i](ctx, entity, response)
+		})
+	}
 	return response, nil
 }
 
+// GetOrCreate возвращает сущность с указанным значением уникального поля или создает ее из input.
+// Второе значение равно true, если сущность создана; только в этом случае вызываются обработчики
+// создания и публикуется событие created. Параллельное создание той же записи обрабатывается
+// репозиторием (см. repository.Repository.GetOrCreate).
+func (s *BaseService[T, R]) GetOrCreate(ctx context.Context, field string, value interface{}, input CreateInput[T]) (*R, bool, error) {
+	// Валидация входных данных
+	if err := validateInput(ctx, input); err != nil {
+		return nil, false, validationError(s.entity.Singular, err)
+	}
+	
+	entity := input.ToEntity()
+	var result *T
+	var created bool
+	var err error
+	for attempt := 1; ; attempt++ {
+		var generated bool
+		err = s.runWrite(ctx, func(ctx context.Context, repo repository.Repository[T], pending *[]pendingEvent) error {
+			existing, err := repo.GetByField(ctx, field, value)
+			if err != nil {
+				return s.wrapRepoError(err, nil, fmt.Sprintf("ошибка при получении %s", s.entity.DisplayNameRu))
+			}
+			if existing != nil {
+				result, created = existing, false
+				return nil
+			}
+			
+			if err := s.runBeforeCreateHooks(ctx, entity); err != nil {
+				return err
+			}
+			if generated, err = s.assignSlug(ctx, repo, entity); err != nil {
+				return err
+			}
+			
+			result, created, err = repo.GetOrCreate(ctx, field, value, func() *T { return entity })
+			if err != nil {
+				return s.wrapRepoError(err, nil, fmt.Sprintf("не удалось создать %s", s.entity.DisplayNameRu))
+			}
+			if !created {
+				return nil
+			}
+			
+			if err := s.recordAudit(ctx, AuditActionCreate, (*result).GetID(), nil, result); err != nil {
+				return err
+			}
+			
+			// Публикуем событие о создании после фиксации
+			*pending = append(*pending, s.entityEvent("created", result, nil))
+			return nil
+		})
+		if !retrySlug(entity, generated, attempt, err) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	
+	response := s.transformer.Transform(result)
+	if created {
+		log.Printf("Создан новый %s: %s (ID: %d)", s.entity.DisplayNameRu, (*result).GetName(), (*result).GetID())
+		s.runAfterHooks(ctx, "AfterCreate", len(s.hooks.afterCreate), func(ctx context.Context, i int) error {
+			return s.hooks.afterCreate[i](ctx, result, response)
+		})
+	}
+	return response, created, nil
+}
+
 // BulkCreate создает множество новых сущностей
 func (s *BaseService[T, R]) BulkCreate(ctx context.Context, inputs []CreateInput[T]) ([]R, error) {
 	if len(inputs) == 0 {
@@ -135,23 +403,42 @@ func (s *BaseService[T, R]) BulkCreate(ctx context.Context, inputs []CreateInput
 	// Валидация всех входных данных
 	entities := make([]*T, 0, len(inputs))
 	for i, input := range inputs {
-		if err := input.Validate(); err != nil {
-			return nil, fmt.Errorf("ошибка валидации элемента %d: %v", i, err)
+		if err := validateInput(ctx, input); err != nil {
+			return nil, itemValidationError(s.entity.Singular, i, err)
 		}
 		entities = append(entities, input.ToEntity())
 	}
 	
 	// Массовое создание в репозитории
-	if err := s.repo.BulkCreate(ctx, entities); err != nil {
-		return nil, fmt.Errorf("не удалось создать %s: %v", s.entityName, err)
+	err := s.runWrite(ctx, func(ctx context.Context, repo repository.Repository[T], pending *[]pendingEvent) error {
+		err := s.runBeforeHooks("BeforeBulkCreate", nil, len(s.hooks.beforeBulkCreate), func(i int) error {
+			return s.hooks.beforeBulkCreate[i](ctx, entities)
+		})
+		if err != nil {
+			return err
+		}
+		
+		if err := repo.BulkCreate(ctx, entities); err != nil {
+			return s.wrapRepoError(err, nil, fmt.Sprintf("не удалось создать %s", s.entity.DisplayNameRu))
+		}
+		
+		for _, entity := range entities {
+			if err := s.recordAudit(ctx, AuditActionCreate, (*entity).GetID(), nil, entity); err != nil {
+				return err
+			}
+		}
+		
+		// Публикуем событие о массовом создании после фиксации
+		if event, ok := s.bulkEvent("bulk_created", entities); ok {
+			*pending = append(*pending, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	
-	log.Printf("Создано %d новых %s", len(entities), s.entityName)
-	
-	// Публикуем событие о массовом создании
-	if s.publisher != nil {
-		s.publishBulkEvent(ctx, "bulk_created", entities)
-	}
+	log.Printf("Создано %d новых %s", len(entities), s.entity.DisplayNameRu)
 	
 	// Преобразуем сущности в ответы
 	responses := make([]R, 0, len(entities))
@@ -160,6 +447,9 @@ func (s *BaseService[T, R]) BulkCreate(ctx context.Context, inputs []CreateInput
 		responses = append(responses, *response)
 	}
 	
+	s.runAfterHooks(ctx, "AfterBulkCreate", len(s.hooks.afterBulkCreate), func(ctx context.Context, i int) error {
+		return s.hooks.afterBulkCreate[i](ctx, entities, responses)
+	})
 	return responses, nil
 }
 
@@ -174,8 +464,8 @@ func (s *BaseService[T, R]) BulkUpdate(ctx context.Context, inputs []BulkUpdateI
 	updatedIDs := make([]uint, 0, len(inputs))
 	
 	for i, input := range inputs {
-		if err := input.Validate(); err != nil {
-			return nil, fmt.Errorf("ошибка валидации элемента %d: %v", i, err)
+		if err := validateInput(ctx, input); err != nil {
+			return nil, itemValidationError(s.entity.Singular, i, err)
 		}
 		
 		updateMap := input.ToUpdateMap()
@@ -194,144 +484,241 @@ func (s *BaseService[T, R]) BulkUpdate(ctx context.Context, inputs []BulkUpdateI
 		return []R{}, nil
 	}
 	
-	// Массовое обновление в репозитории
-	if err := s.repo.BulkUpdate(ctx, updates); err != nil {
-		return nil, fmt.Errorf("не удалось обновить %s: %v", s.entityName, err)
-	}
-	
-	log.Printf("Обновлено %d %s", len(updates), s.entityName)
-	
-	// Получаем обновленные сущности для возврата
-	responses := make([]R, 0, len(updatedIDs))
-	for _, id := range updatedIDs {
-		entity, err := s.repo.GetByID(ctx, id)
-		if err != nil {
-			log.Printf("Ошибка при получении обновленной сущности %s с ID %d: %v", s.entityName, id, err)
-			continue
+	entities := make([]*T, 0, len(updatedIDs))
+	err := s.runWrite(ctx, func(ctx context.Context, repo repository.Repository[T], pending *[]pendingEvent) error {
+		// Состояние до обновления для журнала аудита
+		var before map[uint]*T
+		if s.audit != nil {
+			snapshots, err := repo.GetByIDs(ctx, updatedIDs)
+			if err != nil {
+				return s.wrapRepoError(err, nil, fmt.Sprintf("ошибка при получении списка %s", s.entity.DisplayNameRu))
+			}
+			before = make(map[uint]*T, len(snapshots))
+			for i := range snapshots {
+				before[snapshots[i].GetID()] = &snapshots[i]
+			}
 		}
-		if entity != nil {
-			response := s.transformer.Transform(entity)
-			responses = append(responses, *response)
+		
+		// Массовое обновление в репозитории
+		if err := repo.BulkUpdate(ctx, updates); err != nil {
+			return s.wrapRepoError(err, nil, fmt.Sprintf("не удалось обновить %s", s.entity.DisplayNameRu))
 		}
-	}
-	
-	// Публикуем событие о массовом обновлении
-	if s.publisher != nil {
-		entities := make([]*T, 0, len(responses))
+		
+		// Получаем обновленные сущности один раз для ответа и события
 		for _, id := range updatedIDs {
-			if entity, err := s.repo.GetByID(ctx, id); err == nil && entity != nil {
+			entity, err := repo.GetByID(ctx, id)
+			if err != nil {
+				log.Printf("Ошибка при получении обновленной сущности %s с ID %d: %v", s.entity.DisplayNameRu, id, err)
+				continue
+			}
+			if entity != nil {
 				entities = append(entities, entity)
 			}
 		}
-		s.publishBulkEvent(ctx, "bulk_updated", entities)
+		
+		for _, entity := range entities {
+			id := (*entity).GetID()
+			if err := s.recordAudit(ctx, AuditActionUpdate, id, before[id], entity); err != nil {
+				return err
+			}
+		}
+		
+		// Публикуем событие о массовом обновлении после фиксации
+		if event, ok := s.bulkEvent("bulk_updated", entities); ok {
+			*pending = append(*pending, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	
+	log.Printf("Обновлено %d %s", len(updates), s.entity.DisplayNameRu)
+	
+	responses := make([]R, 0, len(entities))
+	for _, entity := range entities {
+		response := s.transformer.Transform(entity)
+		responses = append(responses, *response)
+	}
+	
+	s.runAfterHooks(ctx, "AfterBulkUpdate", len(s.hooks.afterBulkUpdate), func(ctx context.Context, i int) error {
+		return s.hooks.afterBulkUpdate[i](ctx, entities, responses)
+	})
 	return responses, nil
 }
 
 // GetByID получает сущность по ID
-func (s *BaseService[T, R]) GetByID(ctx context.Context, id uint) (*R, error) {
-	entity, err := s.repo.GetByID(ctx, id)
+func (s *BaseService[T, R]) GetByID(ctx context.Context, id uint, opts ...repository.QueryOption) (*R, error) {
+	entity, err := s.repo.GetByID(ctx, id, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при получении %s: %v", s.entityName, err)
+		return nil, s.wrapRepoError(err, id, fmt.Sprintf("ошибка при получении %s", s.entity.DisplayNameRu))
 	}
 	
 	if entity == nil {
-		return nil, fmt.Errorf("%s с ID %d не найден", s.entityName, id)
+		return nil, apperrors.NotFound(s.entity.Singular, id)
 	}
 	
 	response := s.transformer.Transform(entity)
 	return response, nil
 }
 
-// Update обновляет сущность
-func (s *BaseService[T, R]) Update(ctx context.Context, id uint, input UpdateInput[T]) (*R, error) {
-	// Проверяем существование сущности
-	exists, err := s.repo.Exists(ctx, id)
+// GetByIDs получает сущности по списку ID одним запросом.
+// Результат индексирован по ID; отсутствующие ID в нем просто не представлены.
+func (s *BaseService[T, R]) GetByIDs(ctx context.Context, ids []uint, opts ...repository.QueryOption) (map[uint]R, error) {
+	entities, err := s.repo.GetByIDs(ctx, ids, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при проверке существования %s: %v", s.entityName, err)
+		return nil, s.wrapRepoError(err, nil, fmt.Sprintf("ошибка при получении списка %s", s.entity.DisplayNameRu))
 	}
-	
-	if !exists {
-		return nil, fmt.Errorf("%s с ID %d не найден", s.entityName, id)
-	}
-	
-	// Валидация входных данных
-	if err := input.Validate(); err != nil {
-		return nil, fmt.Errorf("ошибка валидации: %v", err)
-	}
-	
-	// Получаем данные для обновления
-	updates := input.ToUpdateMap()
-	if len(updates) == 0 {
-		return nil, fmt.Errorf("нет данных для обновления")
-	}
-	
-	// Обновляем сущность
-	updatedEntity, err := s.repo.Update(ctx, id, updates)
-	if err != nil {
-		return nil, fmt.Errorf("не удалось обновить %s: %v", s.entityName, err)
-	}
-	
-	if updatedEntity == nil {
-		return nil, fmt.Errorf("%s с ID %d не найден", s.entityName, id)
+
+	responses := make(map[uint]R, len(entities))
+	for i := range entities {
+		responses[entities[i].GetID()] = *s.transformer.Transform(&entities[i])
 	}
-	
-	log.Printf("Обновлен %s: %s (ID: %d)", s.entityName, (*updatedEntity).GetName(), (*updatedEntity).GetID())
-	
-	// Публикуем событие об обновлении
-	if s.publisher != nil {
+
+	return responses, nil
+}
+
+// Update обновляет сущность
+func (s *BaseService[T, R]) Update(ctx context.Context, id uint, input UpdateInput[T]) (*R, error) {
+	var updatedEntity *T
+	
+	err := s.runWrite(ctx, func(ctx context.Context, repo repository.Repository[T], pending *[]pendingEvent) error {
+		// Проверяем существование сущности; для журнала аудита читаем ее состояние до обновления
+		var before *T
+		var exists bool
+		var err error
+		if s.audit != nil {
+			before, err = repo.GetByID(ctx, id)
+			exists = before != nil
+		} else {
+			exists, err = repo.Exists(ctx, id)
+		}
+		if err != nil {
+			return s.wrapRepoError(err, id, fmt.Sprintf("ошибка при проверке существования %s", s.entity.DisplayNameRu))
+		}
+		
+		if !exists {
+			return apperrors.NotFound(s.entity.Singular, id)
+		}
+		
+		// Валидация входных данных
+		if err := validateInput(ctx, input); err != nil {
+			return validationError(s.entity.Singular, err)
+		}
+		
+		// Получаем данные для обновления
+		updates := input.ToUpdateMap()
+		if len(updates) == 0 {
+			return apperrors.New(apperrors.ErrValidation, s.entity.Singular, id, "нет данных для обновления")
+		}
+		
+		err = s.runBeforeHooks("BeforeUpdate", id, len(s.hooks.beforeUpdate), func(i int) error {
+			return s.hooks.beforeUpdate[i](ctx, id, updates)
+		})
+		if err != nil {
+			return err
+		}
+		
+		// Обновляем сущность
+		updatedEntity, err = repo.Update(ctx, id, updates)
+		if err != nil {
+			return s.wrapRepoError(err, id, fmt.Sprintf("не удалось обновить %s", s.entity.DisplayNameRu))
+		}
+		
+		if updatedEntity == nil {
+			return apperrors.NotFound(s.entity.Singular, id)
+		}
+		
+		if err := s.recordAudit(ctx, AuditActionUpdate, id, before, updatedEntity); err != nil {
+			return err
+		}
+		
+		// Публикуем событие об обновлении после фиксации
 		updatedFields := make([]string, 0, len(updates))
 		for key := range updates {
 			updatedFields = append(updatedFields, key)
 		}
-		s.publishEvent(ctx, "updated", updatedEntity, updatedFields)
+		*pending = append(*pending, s.entityEvent("updated", updatedEntity, updatedFields))
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	
+	log.Printf("Обновлен %s: %s (ID: %d)", s.entity.DisplayNameRu, (*updatedEntity).GetName(), (*updatedEntity).GetID())
+	
 	response := s.transformer.Transform(updatedEntity)
+	s.runAfterHooks(ctx, "AfterUpdate", len(s.hooks.afterUpdate), func(ctx context.Context, i int) error {
+		return s.hooks.afterUpdate[i](ctx, updatedEntity, response)
+	})
 	return response, nil
 }
 
-// Delete удаляет сущность
+// Delete удаляет сущность. Если задана политика удаления (WithDeletePolicy),
+// сначала выполняются проверки зависимостей и каскадные действия.
 func (s *BaseService[T, R]) Delete(ctx context.Context, id uint) (*R, error) {
-	// Получаем сущность перед удалением
-	entity, err := s.repo.GetByID(ctx, id)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка при получении %s: %v", s.entityName, err)
-	}
-	
-	if entity == nil {
-		return nil, fmt.Errorf("%s с ID %d не найден", s.entityName, id)
-	}
-	
-	// Сохраняем данные для ответа
-	response := s.transformer.Transform(entity)
-	
-	// Удаляем сущность
-	deletedEntity, err := s.repo.Delete(ctx, id)
+	var deletedEntity *T
+	
+	err := s.runWrite(ctx, func(ctx context.Context, repo repository.Repository[T], pending *[]pendingEvent) error {
+		// Проверяем зависимости и удаляем дочерние сущности
+		if s.deletePolicy != nil {
+			exists, err := repo.Exists(ctx, id)
+			if err != nil {
+				return s.wrapRepoError(err, id, fmt.Sprintf("ошибка при проверке существования %s", s.entity.DisplayNameRu))
+			}
+			if !exists {
+				return apperrors.NotFound(s.entity.Singular, id)
+			}
+		}
+		if err := s.CheckDelete(ctx, id); err != nil {
+			return err
+		}
+		err := s.runBeforeHooks("BeforeDelete", id, len(s.hooks.beforeDelete), func(i int) error {
+			return s.hooks.beforeDelete[i](ctx, id)
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.runCascades(ctx, id); err != nil {
+			return err
+		}
+		
+		// Репозиторий возвращает сущность в состоянии до удаления
+		deletedEntity, err = repo.Delete(ctx, id)
+		if err != nil {
+			return s.wrapRepoError(err, id, fmt.Sprintf("не удалось удалить %s", s.entity.DisplayNameRu))
+		}
+		
+		if deletedEntity == nil {
+			return apperrors.NotFound(s.entity.Singular, id)
+		}
+		
+		if err := s.recordAudit(ctx, AuditActionDelete, id, deletedEntity, nil); err != nil {
+			return err
+		}
+		
+		// Публикуем событие об удалении после фиксации
+		*pending = append(*pending, s.entityEvent("deleted", deletedEntity, nil))
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("не удалось удалить %s: %v", s.entityName, err)
-	}
-	
-	if deletedEntity == nil {
-		return nil, fmt.Errorf("%s с ID %d не найден", s.entityName, id)
+		return nil, err
 	}
 	
-	log.Printf("Удален %s: %s (ID: %d)", s.entityName, (*deletedEntity).GetName(), (*deletedEntity).GetID())
-	
-	// Публикуем событие об удалении
-	if s.publisher != nil {
-		s.publishEvent(ctx, "deleted", deletedEntity, nil)
-	}
+	log.Printf("Удален %s: %s (ID: %d)", s.entity.DisplayNameRu, (*deletedEntity).GetName(), (*deletedEntity).GetID())
 	
+	response := s.transformer.Transform(deletedEntity)
+	s.runAfterHooks(ctx, "AfterDelete", len(s.hooks.afterDelete), func(ctx context.Context, i int) error {
+		return s.hooks.afterDelete[i](ctx, deletedEntity, response)
+	})
 	return response, nil
 }
 
 // GetAll получает все сущности с пагинацией, фильтрацией и сортировкой
-func (s *BaseService[T, R]) GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions) (*PaginationResponse[R], error) {
-	entities, total, err := s.repo.GetAll(ctx, skip, limit, filters, sort)
+func (s *serviceCore[T, R]) GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) (*PaginationResponse[R], error) {
+	entities, total, err := s.reader.GetAll(ctx, skip, limit, filters, sort, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при получении списка %s: %v", s.entityName, err)
+		return nil, s.wrapRepoError(err, nil, fmt.Sprintf("ошибка при получении списка %s", s.entity.DisplayNameRu))
 	}
 	
 	// Преобразуем сущности в ответы
@@ -347,20 +734,26 @@ func (s *BaseService[T, R]) GetAll(ctx context.Context, skip, limit int, filters
 }
 
 // Search выполняет поиск сущностей с сортировкой
-func (s *BaseService[T, R]) Search(ctx context.Context, keyword string, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions) (*PaginationResponse[R], error) {
+func (s *serviceCore[T, R]) Search(ctx context.Context, keyword string, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) (*PaginationResponse[R], error) {
 	// Запуск таймера для измерения производительности
 	startTime := time.Now()
 	
-	entities, total, err := s.repo.Search(ctx, keyword, skip, limit, filters, sort)
+	entities, total, err := s.reader.Search(ctx, keyword, skip, limit, filters, sort, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при поиске %s: %v", s.entityName, err)
+		return nil, s.wrapRepoError(err, nil, fmt.Sprintf("ошибка при поиске %s", s.entity.DisplayNameRu))
 	}
 	
 	// Логируем поисковый запрос
 	processingTime := int(time.Since(startTime).Milliseconds())
 	
-	log.Printf("Поиск %s по запросу '%s': найдено %d результатов за %d мс", 
-		s.entityName, keyword, len(entities), processingTime)
+	// С опцией WithoutTotal общее количество неизвестно, логируется только размер страницы
+	if total == repository.TotalUnknown {
+		log.Printf("Поиск %s по запросу '%s': получено %d результатов за %d мс", 
+			s.entity.DisplayNameRu, keyword, len(entities), processingTime)
+	} else {
+		log.Printf("Поиск %s по запросу '%s': получено %d результатов из %d за %d мс", 
+			s.entity.DisplayNameRu, keyword, len(entities), total, processingTime)
+	}
 	
 	// Преобразуем сущности в ответы
 	responses := s.transformer.TransformSlice(entities)
@@ -374,11 +767,11 @@ func (s *BaseService[T, R]) Search(ctx context.Context, keyword string, skip, li
 	}, nil
 }
 
-// Count подсчитывает количество сущностей
-func (s *BaseService[T, R]) Count(ctx context.Context, filters map[string]interface{}) (int64, error) {
-	count, err := s.repo.Count(ctx, filters)
+// Count подсчитывает количество сущностей; опция repository.Scope добавляет произвольные условия
+func (s *serviceCore[T, R]) Count(ctx context.Context, filters map[string]interface{}, opts ...repository.QueryOption) (int64, error) {
+	count, err := s.reader.Count(ctx, filters, opts...)
 	if err != nil {
-		return 0, fmt.Errorf("ошибка при подсчете %s: %v", s.entityName, err)
+		return 0, s.wrapRepoError(err, nil, fmt.Sprintf("ошибка при подсчете %s", s.entity.DisplayNameRu))
 	}
 	
 	return count, nil
@@ -388,21 +781,21 @@ func (s *BaseService[T, R]) Count(ctx context.Context, filters map[string]interf
 func (s *BaseService[T, R]) Exists(ctx context.Context, id uint) (bool, error) {
 	exists, err := s.repo.Exists(ctx, id)
 	if err != nil {
-		return false, fmt.Errorf("ошибка при проверке существования %s: %v", s.entityName, err)
+		return false, s.wrapRepoError(err, id, fmt.Sprintf("ошибка при проверке существования %s", s.entity.DisplayNameRu))
 	}
 	
 	return exists, nil
 }
 
 // GetByField получает сущность по указанному полю
-func (s *BaseService[T, R]) GetByField(ctx context.Context, field string, value interface{}) (*R, error) {
-	entity, err := s.repo.GetByField(ctx, field, value)
+func (s *serviceCore[T, R]) GetByField(ctx context.Context, field string, value interface{}, opts ...repository.QueryOption) (*R, error) {
+	entity, err := s.reader.GetByField(ctx, field, value, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при получении %s по полю %s: %v", s.entityName, field, err)
+		return nil, s.wrapRepoError(err, nil, fmt.Sprintf("ошибка при получении %s по полю %s", s.entity.DisplayNameRu, field))
 	}
 	
 	if entity == nil {
-		return nil, fmt.Errorf("%s с %s = %v не найден", s.entityName, field, value)
+		return nil, apperrors.New(apperrors.ErrNotFound, s.entity.Singular, value, fmt.Sprintf("%s с %s = %v не найден", s.entity.DisplayNameRu, field, value))
 	}
 	
 	response := s.transformer.Transform(entity)
@@ -410,10 +803,10 @@ func (s *BaseService[T, R]) GetByField(ctx context.Context, field string, value
 }
 
 // GetAllByField получает все сущности по указанному полю с пагинацией
-func (s *BaseService[T, R]) GetAllByField(ctx context.Context, field string, value interface{}, skip, limit int) (*PaginationResponse[R], error) {
-	entities, total, err := s.repo.GetAllByField(ctx, field, value, skip, limit)
+func (s *serviceCore[T, R]) GetAllByField(ctx context.Context, field string, value interface{}, skip, limit int, opts ...repository.QueryOption) (*PaginationResponse[R], error) {
+	entities, total, err := s.reader.GetAllByField(ctx, field, value, skip, limit, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при получении списка %s по полю %s: %v", s.entityName, field, err)
+		return nil, s.wrapRepoError(err, nil, fmt.Sprintf("ошибка при получении списка %s по полю %s", s.entity.DisplayNameRu, field))
 	}
 	
 	// Преобразуем сущности в ответы
@@ -428,11 +821,99 @@ func (s *BaseService[T, R]) GetAllByField(ctx context.Context, field string, val
 	}, nil
 }
 
+// Stream последовательно передает в fn все сущности, соответствующие фильтрам, в виде ответов.
+// Ошибка fn возвращается без изменений.
+func (s *serviceCore[T, R]) Stream(ctx context.Context, filters map[string]interface{}, sort *repository.SortOptions, fn func(response *R) error) error {
+	var callbackErr error
+
+	err := s.reader.Stream(ctx, filters, sort, func(entity *T) error {
+		if err := fn(s.transformer.Transform(entity)); err != nil {
+			callbackErr = err
+			return err
+		}
+		return nil
+	})
+
+	if callbackErr != nil {
+		return callbackErr
+	}
+
+	if err != nil {
+		return s.wrapRepoError(err, nil, fmt.Sprintf("ошибка при потоковом получении %s", s.entity.DisplayNameRu))
+	}
+
+	return nil
+}
+
+// StreamTransformed передает через канал все сущности, соответствующие фильтрам, в виде ответов.
+// Сущности читаются пакетами по batchSize (см. repository.StreamBatched), ответы формируются
+// через TransformSlice для каждого накопленного пакета. После закрытия канала ответов
+// из канала ошибок можно прочитать не более одной ошибки; отмена ctx останавливает чтение из базы.
+func (s *serviceCore[T, R]) StreamTransformed(ctx context.Context, filters map[string]interface{}, sort *repository.SortOptions, batchSize int) (<-chan R, <-chan error) {
+	if batchSize <= 0 {
+		batchSize = repository.DefaultStreamBatchSize
+	}
+
+	entities, repoErrs := s.reader.StreamBatched(ctx, filters, sort, batchSize)
+	responses := make(chan R, batchSize)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(responses)
+
+		batch := make([]T, 0, batchSize)
+		flush := func() bool {
+			for _, response := range s.transformer.TransformSlice(batch) {
+				select {
+				case responses <- response:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			batch = batch[:0]
+			return true
+		}
+
+		for entity := range entities {
+			batch = append(batch, entity)
+			// Пакет передается, когда набран целиком или репозиторий пока не выдал следующие записи
+			if len(batch) == batchSize || len(entities) == 0 {
+				if !flush() {
+					break
+				}
+			}
+		}
+
+		if ctx.Err() == nil && len(batch) > 0 {
+			flush()
+		}
+
+		// Дочитываем канал, чтобы горутина репозитория завершилась
+		for range entities {
+		}
+
+		err := <-repoErrs
+		if err == nil {
+			err = ctx.Err()
+		}
+		switch {
+		case err == nil:
+		case stderrors.Is(err, context.Canceled), stderrors.Is(err, context.DeadlineExceeded):
+			errs <- err
+		default:
+			errs <- s.wrapRepoError(err, nil, fmt.Sprintf("ошибка при потоковом получении %s", s.entity.DisplayNameRu))
+		}
+	}()
+
+	return responses, errs
+}
+
 // calculatePagination вычисляет информацию о пагинации
-func (s *BaseService[T, R]) calculatePagination(total int64, skip, limit int) Pagination {
-	// Вычисляем количество страниц
+func (s *serviceCore[T, R]) calculatePagination(total int64, skip, limit int) Pagination {
+	// Вычисляем количество страниц; без общего количества (repository.TotalUnknown) страниц нет
 	pages := (int(total) + limit - 1) / limit
-	if limit <= 0 {
+	if limit <= 0 || total == repository.TotalUnknown {
 		pages = 0
 	}
 	
@@ -450,49 +931,86 @@ func (s *BaseService[T, R]) calculatePagination(total int64, skip, limit int) Pa
 	}
 }
 
-// publishEvent публикует событие в очередь сообщений
-func (s *BaseService[T, R]) publishEvent(ctx context.Context, eventType string, entity *T, updatedFields []string) {
-	eventData := map[string]interface{}{
-		"id":          (*entity).GetID(),
-		"name":        (*entity).GetName(),
-		"event_type":  eventType,
-		"entity_type": s.entityName,
-	}
-	
-	if updatedFields != nil {
-		eventData["updated_fields"] = updatedFields
+// wrapRepoError преобразует ошибку репозитория в типизированную ошибку
+func (s *serviceCore[T, R]) wrapRepoError(err error, id interface{}, message string) error {
+	// Нарушение уникальности и внешних ключей
+	if stderrors.Is(err, gorm.ErrDuplicatedKey) || stderrors.Is(err, gorm.ErrForeignKeyViolated) {
+		return apperrors.Wrap(apperrors.ErrConflict, s.entity.Singular, id, err, message)
 	}
-	
-	eventName := fmt.Sprintf("%s.%s", s.entityName, eventType)
-	if err := s.publisher.PublishEvent(ctx, eventName, eventData); err != nil {
-		log.Printf("Ошибка при публикации события %s: %v", eventName, err)
+
+	// Ошибки авторизации из репозитория приходят в виде gRPC статусов
+	switch status.Code(err) {
+	case codes.PermissionDenied:
+		return apperrors.Wrap(apperrors.ErrPermissionDenied, s.entity.Singular, id, err, message)
+	case codes.Unauthenticated:
+		// Сохраняем исходный статус, чтобы клиент получил Unauthenticated
+		return fmt.Errorf("%s: %w", message, err)
 	}
+
+	return apperrors.Wrap(apperrors.ErrInternal, s.entity.Singular, id, err, message)
 }
 
-// publishBulkEvent публикует событие массовой операции в очередь сообщений
-func (s *BaseService[T, R]) publishBulkEvent(ctx context.Context, eventType string, entities []*T) {
-	if len(entities) == 0 {
-		return
-	}
+// runWrite выполняет fn в транзакции (если настроен txRunner) с репозиторием, привязанным к ней.
+// Накопленные события публикуются только после успешной фиксации. Если транзакция
+// открыта вызывающим кодом, события публикуются после выполнения fn, до фиксации внешней транзакции
+// (кроме транзакций RunInTransaction, которые откладывают публикацию до своей фиксации).
+// Чтения внутри fn (проверка существования, повторное чтение обновленной записи) выполняются
+// на primary (database.ForcePrimary), чтобы не получить устаревшие данные реплики.
+func (s *BaseService[T, R]) runWrite(ctx context.Context, fn func(ctx context.Context, repo repository.Repository[T], pending *[]pendingEvent) error) error {
+	ctx = database.ForcePrimary(ctx)
+	return s.runInTransaction(ctx, func(ctx context.Context, tx *gorm.DB, pending *[]pendingEvent) error {
+		repo := s.repo
+		if tx != nil {
+			repo = s.repo.WithTx(tx)
+		}
+		return fn(ctx, repo, pending)
+	})
+}
+
+// runInTransaction выполняет fn в транзакции txRunner и публикует накопленные события (см. runWrite).
+// tx равен nil, если транзакции в контексте нет.
+func (s *serviceCore[T, R]) runInTransaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB, pending *[]pendingEvent) error) error {
+	var pending []pendingEvent
 	
-	entityIDs := make([]uint, 0, len(entities))
-	entityNames := make([]string, 0, len(entities))
+	run := func(ctx context.Context) error {
+		pending = pending[:0]
+		
+		tx, _ := database.TransactionFromContext(ctx)
+		return fn(ctx, tx, &pending)
+	}
 	
-	for _, entity := range entities {
-		entityIDs = append(entityIDs, (*entity).GetID())
-		entityNames = append(entityNames, (*entity).GetName())
+	var err error
+	if s.txRunner != nil {
+		err = s.txRunner.RunInTransaction(ctx, run)
+	} else {
+		err = run(ctx)
+	}
+	if err != nil {
+		return err
 	}
 	
-	eventData := map[string]interface{}{
-		"ids":         entityIDs,
-		"names":       entityNames,
-		"count":       len(entities),
-		"event_type":  eventType,
-		"entity_type": s.entityName,
+	s.flushEvents(ctx, pending)
+	return nil
+}
+
+// flushEvents публикует накопленные события в очередь сообщений
+func (s *serviceCore[T, R]) flushEvents(ctx context.Context, pending []pendingEvent) {
+	if s.publisher == nil || len(pending) == 0 {
+		return
 	}
 	
-	eventName := fmt.Sprintf("%s.%s", s.entityName, eventType)
-	if err := s.publisher.PublishEvent(ctx, eventName, eventData); err != nil {
-		log.Printf("Ошибка при публикации массового события %s: %v", eventName, err)
+	// В транзакции RunInTransaction события публикуются после ее фиксации
+	pending = append([]pendingEvent(nil), pending...)
+	afterCommit(ctx, func(ctx context.Context) {
+		s.publishEvents(ctx, pending)
+	})
+}
+
+// publishEvents публикует события в очередь сообщений
+func (s *serviceCore[T, R]) publishEvents(ctx context.Context, pending []pendingEvent) {
+	for _, event := range pending {
+		if err := s.publisher.PublishEvent(ctx, event.name, event.data); err != nil {
+			log.Printf("Ошибка при публикации события %s: %v", event.name, err)
+		}
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/vladzorgan/common/repository"
+	"gorm.io/gorm"
+)
+
+// DefaultSlugField столбец slug по умолчанию
+const DefaultSlugField = "slug"
+
+// maxSlugAttempts ограничивает количество попыток создания сущности, если сгенерированный
+// slug занят параллельно созданной записью
+const maxSlugAttempts = 5
+
+// Sluggable реализуют сущности с URL slug. Если при создании slug пуст, BaseService
+// генерирует его из GetName() и добавляет суффикс -2, -3, ..., пока slug не станет уникальным.
+// SetSlug должен быть определен на указателе, чтобы изменять сущность.
+type Sluggable interface {
+	GetSlug() string
+	SetSlug(slug string)
+}
+
+// transliteration соответствие кириллических букв латинским
+var transliteration = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+}
+
+// Slugify преобразует строку в slug: кириллица транслитерируется, буквы приводятся к нижнему
+// регистру, остальные символы заменяются дефисами (например, "Санкт-Петербург" -> "sankt-peterburg")
+func Slugify(value string) string {
+	var builder strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(value) {
+		if latin, ok := transliteration[r]; ok {
+			builder.WriteString(latin)
+			dash = false
+			continue
+		}
+
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			builder.WriteRune(r)
+			dash = false
+			continue
+		}
+
+		// Остальные символы заменяем одним дефисом
+		if !dash && builder.Len() > 0 {
+			builder.WriteByte('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(builder.String(), "-")
+}
+
+// WithSlugField задает столбец slug для сущностей, реализующих Sluggable (по умолчанию "slug")
+func (s *BaseService[T, R]) WithSlugField(field string) *BaseService[T, R] {
+	s.slugField = field
+	return s
+}
+
+// GetBySlug получает сущность по slug
+func (s *BaseService[T, R]) GetBySlug(ctx context.Context, slug string) (*R, error) {
+	return s.GetByField(ctx, s.slugColumn(), slug)
+}
+
+// slugColumn возвращает столбец slug
+func (s *BaseService[T, R]) slugColumn() string {
+	if s.slugField == "" {
+		return DefaultSlugField
+	}
+	return s.slugField
+}
+
+// assignSlug генерирует уникальный slug для сущности без slug.
+// Возвращает true, если slug был сгенерирован.
+func (s *BaseService[T, R]) assignSlug(ctx context.Context, repo repository.Repository[T], entity *T) (bool, error) {
+	sluggable, ok := any(entity).(Sluggable)
+	if !ok || sluggable.GetSlug() != "" {
+		return false, nil
+	}
+
+	base := Slugify((*entity).GetName())
+	if base == "" {
+		base = Slugify(s.entity.Singular)
+	}
+
+	// Добавляем суффикс, пока slug занят. Уникальный индекс учитывает чужие и удаленные записи,
+	// поэтому проверка выполняется без фильтров по владельцу, арендатору и soft delete.
+	slug := base
+	for suffix := 2; ; suffix++ {
+		exists, err := repo.ExistsByFieldUnscoped(ctx, s.slugColumn(), slug)
+		if err != nil {
+			return false, s.wrapRepoError(err, nil, fmt.Sprintf("ошибка при проверке slug %s", s.entity.DisplayNameRu))
+		}
+		if !exists {
+			break
+		}
+		slug = fmt.Sprintf("%s-%d", base, suffix)
+	}
+
+	sluggable.SetSlug(slug)
+	return true, nil
+}
+
+// retrySlug проверяет, нужно ли повторить создание сущности со сгенерированным slug:
+// между проверкой уникальности и вставкой slug могла занять параллельно созданная запись.
+// Сгенерированный slug сбрасывается, чтобы следующая попытка подобрала новый.
+func retrySlug[T any](entity *T, generated bool, attempt int, err error) bool {
+	if !generated || attempt >= maxSlugAttempts || !stderrors.Is(err, gorm.ErrDuplicatedKey) {
+		return false
+	}
+
+	any(entity).(Sluggable).SetSlug("")
+	return true
+}
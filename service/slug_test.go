@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/vladzorgan/common/repository"
+	"gorm.io/gorm"
+)
+
+type slugEntity struct {
+	ID   uint
+	Name string
+	Slug string
+}
+
+func (e slugEntity) GetID() uint          { return e.ID }
+func (e slugEntity) GetName() string      { return e.Name }
+func (e slugEntity) GetTableName() string { return "slug_entities" }
+func (e slugEntity) GetSlug() string      { return e.Slug }
+func (e *slugEntity) SetSlug(slug string) { e.Slug = slug }
+
+type slugInput struct{ name string }
+
+func (i slugInput) ToEntity() *slugEntity { return &slugEntity{Name: i.name} }
+func (i slugInput) Validate() error       { return nil }
+
+type slugTransformer struct{}
+
+func (slugTransformer) Transform(entity *slugEntity) *slugEntity { return entity }
+func (slugTransformer) TransformSlice(entities []slugEntity) []slugEntity {
+	return entities
+}
+
+// slugRepository хранит сущности в памяти и, как уникальный индекс, отклоняет повторный slug.
+// Первые checkers проверок slug дожидаются друг друга, чтобы параллельные Create увидели slug свободным.
+type slugRepository struct {
+	repository.Repository[slugEntity]
+
+	mu       sync.Mutex
+	items    map[string]slugEntity
+	creates  int
+	checks   int
+	checkers sync.WaitGroup
+	barrier  int
+}
+
+func newSlugRepository(concurrent int) *slugRepository {
+	repo := &slugRepository{items: make(map[string]slugEntity), barrier: concurrent}
+	repo.checkers.Add(concurrent)
+	return repo
+}
+
+func (r *slugRepository) ExistsByFieldUnscoped(ctx context.Context, field string, value interface{}) (bool, error) {
+	r.mu.Lock()
+	_, exists := r.items[value.(string)]
+	r.checks++
+	wait := r.checks <= r.barrier
+	r.mu.Unlock()
+
+	if wait {
+		r.checkers.Done()
+		r.checkers.Wait()
+	}
+	return exists, nil
+}
+
+func (r *slugRepository) Create(ctx context.Context, entity *slugEntity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.creates++
+	if _, exists := r.items[entity.Slug]; exists {
+		return fmt.Errorf("insert: %w", gorm.ErrDuplicatedKey)
+	}
+	entity.ID = uint(len(r.items) + 1)
+	r.items[entity.Slug] = *entity
+	return nil
+}
+
+func (r *slugRepository) GetByField(ctx context.Context, field string, value interface{}, opts ...repository.QueryOption) (*slugEntity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entity, ok := r.items[value.(string)]; ok && field == DefaultSlugField {
+		return &entity, nil
+	}
+	return nil, nil
+}
+
+func newSlugService(repo *slugRepository) *BaseService[slugEntity, slugEntity] {
+	return NewBaseService[slugEntity, slugEntity](repo, slugTransformer{}, nil, "city")
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{"Москва", "moskva"},
+		{"Санкт-Петербург", "sankt-peterburg"},
+		{"  Ростов-на-Дону!! ", "rostov-na-donu"},
+		{"Сервисный центр №1", "servisnyy-tsentr-1"},
+		{"Щёлково", "shchelkovo"},
+		{"iPhone 15 Pro", "iphone-15-pro"},
+		{"Café", "caf"},
+		{"!!!", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Slugify(tt.value); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCreateGeneratesUniqueSlug(t *testing.T) {
+	repo := newSlugRepository(0)
+	s := newSlugService(repo)
+	ctx := context.Background()
+
+	for i, want := range []string{"moskva", "moskva-2", "moskva-3"} {
+		city, err := s.Create(ctx, slugInput{name: "Москва"})
+		if err != nil {
+			t.Fatalf("Create() #%d error = %v", i, err)
+		}
+		if city.Slug != want {
+			t.Errorf("Create() #%d slug = %q, want %q", i, city.Slug, want)
+		}
+	}
+
+	city, err := s.GetBySlug(ctx, "moskva-2")
+	if err != nil || city == nil || city.ID != 2 {
+		t.Errorf("GetBySlug() = %+v, %v", city, err)
+	}
+}
+
+func TestCreateKeepsExplicitSlug(t *testing.T) {
+	repo := newSlugRepository(0)
+	repo.items["msk"] = slugEntity{ID: 1, Name: "Москва", Slug: "msk"}
+
+	_, err := newSlugService(repo).Create(context.Background(), createSlugInput{slugEntity{Name: "Москва", Slug: "msk"}})
+	if err == nil {
+		t.Fatal("explicit duplicate slug must not be replaced")
+	}
+	if repo.creates != 1 {
+		t.Errorf("creates = %d, want 1 (no retry for explicit slug)", repo.creates)
+	}
+}
+
+func TestConcurrentCreatesRetryOnSlugConflict(t *testing.T) {
+	const concurrent = 4
+	repo := newSlugRepository(concurrent)
+	s := newSlugService(repo)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, concurrent)
+	for i := 0; i < concurrent; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Create(context.Background(), slugInput{name: "Москва"})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	for _, slug := range []string{"moskva", "moskva-2", "moskva-3", "moskva-4"} {
+		if _, ok := repo.items[slug]; !ok {
+			t.Errorf("slug %s not created, got %v", slug, repo.items)
+		}
+	}
+	// Все параллельные Create выбрали один slug, поэтому хотя бы часть из них повторялась
+	if repo.creates <= concurrent {
+		t.Errorf("creates = %d, want retries after unique violations", repo.creates)
+	}
+}
+
+type createSlugInput struct{ entity slugEntity }
+
+func (i createSlugInput) ToEntity() *slugEntity {
+	entity := i.entity
+	return &entity
+}
+func (i createSlugInput) Validate() error { return nil }
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apperrors "github.com/vladzorgan/common/errors"
+	"github.com/vladzorgan/common/repository"
+)
+
+// streamRepository выдает заданные сущности и ошибку через StreamBatched
+type streamRepository struct {
+	repository.Repository[hookEntity]
+	entities []hookEntity
+	err      error
+}
+
+func (r *streamRepository) StreamBatched(ctx context.Context, filters map[string]interface{}, sort *repository.SortOptions, batchSize int) (<-chan hookEntity, <-chan error) {
+	entities := make(chan hookEntity, len(r.entities))
+	errs := make(chan error, 1)
+	for _, entity := range r.entities {
+		entities <- entity
+	}
+	if r.err != nil {
+		errs <- r.err
+	}
+	close(entities)
+	close(errs)
+	return entities, errs
+}
+
+// batchTransformer запоминает размеры пакетов TransformSlice
+type batchTransformer struct {
+	batches []int
+}
+
+func (t *batchTransformer) Transform(entity *hookEntity) *uint { return &entity.ID }
+
+func (t *batchTransformer) TransformSlice(entities []hookEntity) []uint {
+	t.batches = append(t.batches, len(entities))
+	ids := make([]uint, 0, len(entities))
+	for _, entity := range entities {
+		ids = append(ids, entity.ID)
+	}
+	return ids
+}
+
+func TestStreamTransformedAppliesTransformerPerBatch(t *testing.T) {
+	repo := &streamRepository{entities: []hookEntity{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}}}
+	transformer := &batchTransformer{}
+	s := NewBaseService[hookEntity, uint](repo, transformer, nil, "hook_entity")
+
+	responses, errs := s.StreamTransformed(context.Background(), nil, nil, 2)
+	var ids []uint
+	for id := range responses {
+		ids = append(ids, id)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+
+	if len(ids) != 5 || ids[0] != 1 || ids[4] != 5 {
+		t.Errorf("ids = %v", ids)
+	}
+	if len(transformer.batches) != 3 || transformer.batches[0] != 2 || transformer.batches[2] != 1 {
+		t.Errorf("batches = %v, want [2 2 1]", transformer.batches)
+	}
+}
+
+func TestStreamTransformedMidStreamError(t *testing.T) {
+	repo := &streamRepository{entities: []hookEntity{{ID: 1}, {ID: 2}}, err: errors.New("connection reset")}
+	s := NewBaseService[hookEntity, uint](repo, &batchTransformer{}, nil, "hook_entity")
+
+	responses, errs := s.StreamTransformed(context.Background(), nil, nil, 10)
+	count := 0
+	for range responses {
+		count++
+	}
+	err := <-errs
+	if !apperrors.IsInternal(err) {
+		t.Errorf("error = %v, want internal", err)
+	}
+	if count != 2 {
+		t.Errorf("received %d responses before error, want 2", count)
+	}
+}
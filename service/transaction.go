@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vladzorgan/common/database"
+	"github.com/vladzorgan/common/repository"
+	"gorm.io/gorm"
+)
+
+// deferredEventsKey ключ контекста с действиями (публикация событий, обработчики After*,
+// инвалидация кеша), которые выполняются после фиксации транзакции RunInTransaction
+type deferredEventsKey struct{}
+
+// deferredEvents действия сервисов, накопленные в транзакции RunInTransaction
+type deferredEvents struct {
+	mutex sync.Mutex
+	run   []func(ctx context.Context)
+}
+
+// add откладывает действие до фиксации транзакции
+func (d *deferredEvents) add(fn func(ctx context.Context)) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.run = append(d.run, fn)
+}
+
+// flush выполняет отложенные действия в порядке выполнения операций с контекстом вне транзакции
+func (d *deferredEvents) flush(ctx context.Context) {
+	d.mutex.Lock()
+	run := d.run
+	d.run = nil
+	d.mutex.Unlock()
+
+	for _, fn := range run {
+		fn(ctx)
+	}
+}
+
+// afterCommit выполняет fn сразу, а в транзакции RunInTransaction - после ее фиксации
+func afterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if deferred, ok := ctx.Value(deferredEventsKey{}).(*deferredEvents); ok {
+		deferred.add(fn)
+		return
+	}
+	fn(ctx)
+}
+
+// inDeferredTransaction проверяет, выполняется ли вызов в транзакции RunInTransaction
+func inDeferredTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(deferredEventsKey{}).(*deferredEvents)
+	return ok
+}
+
+// RunInTransaction выполняет fn в транзакции db (например, *database.Database), передавая ее через
+// контекст. Операции BaseService и репозиториев с контекстом fn выполняются в этой транзакции,
+// а события сервисов, обработчики After* и инвалидация CachedService выполняются только после
+// ее фиксации; при ошибке fn все изменения откатываются, а отложенные действия отбрасываются.
+// Вложенный вызов выполняется во внешней транзакции.
+func RunInTransaction(ctx context.Context, db database.TxRunner, fn func(ctx context.Context) error) error {
+	if inDeferredTransaction(ctx) {
+		return db.RunInTransaction(ctx, fn)
+	}
+
+	events := &deferredEvents{}
+	if err := db.RunInTransaction(context.WithValue(ctx, deferredEventsKey{}, events), fn); err != nil {
+		return err
+	}
+
+	events.flush(ctx)
+	return nil
+}
+
+// UnitOfWork предоставляет репозитории, привязанные к одной транзакции (см. RunUnitOfWork)
+type UnitOfWork struct {
+	tx *gorm.DB
+}
+
+// RunUnitOfWork выполняет fn в транзакции db (см. RunInTransaction). Репозитории, полученные
+// через Repo и UUIDRepo, и сервисы, вызванные с контекстом fn, работают в этой транзакции:
+//
+//	err := service.RunUnitOfWork(ctx, db, func(ctx context.Context, uow *service.UnitOfWork) error {
+//		if err := service.Repo(uow, ordersRepo).Create(ctx, order); err != nil {
+//			return err
+//		}
+//		_, err := paymentsService.Create(ctx, paymentInput)
+//		return err
+//	})
+func RunUnitOfWork(ctx context.Context, db database.TxRunner, fn func(ctx context.Context, uow *UnitOfWork) error) error {
+	return RunInTransaction(ctx, db, func(ctx context.Context) error {
+		tx, _ := database.TransactionFromContext(ctx)
+		return fn(ctx, &UnitOfWork{tx: tx})
+	})
+}
+
+// Tx возвращает транзакцию единицы работы (nil, если TxRunner не передает транзакцию через контекст)
+func (u *UnitOfWork) Tx() *gorm.DB {
+	return u.tx
+}
+
+// Repo возвращает вариант репозитория, работающий в транзакции единицы работы.
+// Функция, а не метод UnitOfWork, так как методы в Go не могут иметь параметров типа.
+func Repo[T repository.BaseModel](u *UnitOfWork, repo repository.Repository[T]) repository.Repository[T] {
+	if u.tx == nil {
+		return repo
+	}
+	return repo.WithTx(u.tx)
+}
+
+// UUIDRepo возвращает вариант репозитория со строковым ID, работающий в транзакции единицы работы
+func UUIDRepo[T repository.UUIDModel](u *UnitOfWork, repo repository.UUIDRepository[T]) repository.UUIDRepository[T] {
+	if u.tx == nil {
+		return repo
+	}
+	return repo.WithTx(u.tx)
+}
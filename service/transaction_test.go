@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/vladzorgan/common/database"
+	"github.com/vladzorgan/common/repository"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type orderEntity struct {
+	ID   uint
+	Name string
+}
+
+func (e orderEntity) GetID() uint          { return e.ID }
+func (e orderEntity) GetName() string      { return e.Name }
+func (e orderEntity) GetTableName() string { return "orders" }
+func (orderEntity) TableName() string      { return "orders" }
+
+type paymentEntity struct {
+	ID   uint
+	Name string
+}
+
+func (e paymentEntity) GetID() uint          { return e.ID }
+func (e paymentEntity) GetName() string      { return e.Name }
+func (e paymentEntity) GetTableName() string { return "payments" }
+func (paymentEntity) TableName() string      { return "payments" }
+
+type orderInput struct{ name string }
+
+func (i orderInput) ToEntity() *orderEntity { return &orderEntity{Name: i.name} }
+func (i orderInput) Validate() error        { return nil }
+
+type paymentInput struct{ name string }
+
+func (i paymentInput) ToEntity() *paymentEntity { return &paymentEntity{Name: i.name} }
+func (i paymentInput) Validate() error          { return nil }
+
+type identityTransformer[T any] struct{}
+
+func (identityTransformer[T]) Transform(entity *T) *T          { return entity }
+func (identityTransformer[T]) TransformSlice(entities []T) []T { return entities }
+
+// errRejected ошибка вставки строки с именем "reject"
+var errRejected = errors.New("insert rejected")
+
+// insertPattern разбирает INSERT, формируемый gorm для сущностей с полем Name
+var insertPattern = regexp.MustCompile(`^INSERT INTO "(\w+)"`)
+
+// txStore хранилище тестового драйвера: вставки видны только после фиксации транзакции
+type txStore struct {
+	mutex     sync.Mutex
+	rows      map[string][]string
+	commits   int
+	rollbacks int
+}
+
+func (s *txStore) Connect(context.Context) (driver.Conn, error) { return &txConn{store: s}, nil }
+func (s *txStore) Driver() driver.Driver                        { return nil }
+
+func (s *txStore) count(table string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.rows[table])
+}
+
+type txConn struct {
+	store  *txStore
+	staged map[string][]string
+}
+
+func (c *txConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *txConn) Close() error                        { return nil }
+func (c *txConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *txConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.staged = make(map[string][]string)
+	return c, nil
+}
+
+func (c *txConn) Commit() error {
+	c.store.mutex.Lock()
+	defer c.store.mutex.Unlock()
+
+	for table, rows := range c.staged {
+		c.store.rows[table] = append(c.store.rows[table], rows...)
+	}
+	c.staged = nil
+	c.store.commits++
+	return nil
+}
+
+func (c *txConn) Rollback() error {
+	c.store.mutex.Lock()
+	defer c.store.mutex.Unlock()
+
+	c.staged = nil
+	c.store.rollbacks++
+	return nil
+}
+
+func (c *txConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (c *txConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	match := insertPattern.FindStringSubmatch(query)
+	if match == nil || c.staged == nil {
+		return nil, fmt.Errorf("unexpected query outside transaction: %s", query)
+	}
+
+	name, _ := args[0].Value.(string)
+	if name == "reject" {
+		return nil, errRejected
+	}
+
+	table := match[1]
+	c.staged[table] = append(c.staged[table], name)
+
+	c.store.mutex.Lock()
+	id := int64(len(c.store.rows[table]) + len(c.staged[table]))
+	c.store.mutex.Unlock()
+	return &idRows{id: id}, nil
+}
+
+// idRows результат RETURNING "id"
+type idRows struct {
+	id   int64
+	read bool
+}
+
+func (r *idRows) Columns() []string { return []string{"id"} }
+func (r *idRows) Close() error      { return nil }
+
+func (r *idRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	dest[0], r.read = r.id, true
+	return nil
+}
+
+// gormTxRunner выполняет функцию в транзакции gorm, передавая ее через контекст (как database.Database)
+type gormTxRunner struct {
+	db *gorm.DB
+}
+
+func (r gormTxRunner) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := database.TransactionFromContext(ctx); ok {
+		return fn(ctx)
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(database.WithTransaction(ctx, tx))
+	})
+}
+
+// newTxFixture создает сервисы заказов и платежей поверх одного транзакционного тестового драйвера
+func newTxFixture(t *testing.T) (*txStore, gormTxRunner, *BaseService[orderEntity, orderEntity], *BaseService[paymentEntity, paymentEntity], *recordingPublisher) {
+	t.Helper()
+
+	store := &txStore{rows: make(map[string][]string)}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(store)}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	publisher := &recordingPublisher{}
+	orders := NewBaseService[orderEntity, orderEntity](repository.NewBaseRepository[orderEntity](nil), identityTransformer[orderEntity]{}, publisher, "order")
+	payments := NewBaseService[paymentEntity, paymentEntity](repository.NewBaseRepository[paymentEntity](nil), identityTransformer[paymentEntity]{}, publisher, "payment")
+	return store, gormTxRunner{db: db}, orders, payments, publisher
+}
+
+func TestRunInTransactionCommitsServicesAndPublishesAfterCommit(t *testing.T) {
+	store, db, orders, payments, publisher := newTxFixture(t)
+
+	err := RunInTransaction(context.Background(), db, func(ctx context.Context) error {
+		if _, err := orders.Create(ctx, orderInput{name: "order-1"}); err != nil {
+			return err
+		}
+		if len(publisher.keys) != 0 {
+			t.Errorf("published before commit: %v", publisher.keys)
+		}
+		_, err := payments.Create(ctx, paymentInput{name: "payment-1"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("RunInTransaction() error = %v", err)
+	}
+
+	if store.count("orders") != 1 || store.count("payments") != 1 || store.commits != 1 {
+		t.Errorf("orders = %d, payments = %d, commits = %d; want 1, 1, 1", store.count("orders"), store.count("payments"), store.commits)
+	}
+	want := []string{orders.routingKey("created"), payments.routingKey("created")}
+	if len(publisher.keys) != 2 || publisher.keys[0] != want[0] || publisher.keys[1] != want[1] {
+		t.Errorf("published = %v, want %v", publisher.keys, want)
+	}
+}
+
+func TestRunInTransactionRollsBackFirstServiceOnSecondError(t *testing.T) {
+	store, db, orders, payments, publisher := newTxFixture(t)
+
+	err := RunInTransaction(context.Background(), db, func(ctx context.Context) error {
+		if _, err := orders.Create(ctx, orderInput{name: "order-1"}); err != nil {
+			return err
+		}
+		_, err := payments.Create(ctx, paymentInput{name: "reject"})
+		return err
+	})
+	if !errors.Is(err, errRejected) {
+		t.Fatalf("RunInTransaction() error = %v, want %v", err, errRejected)
+	}
+
+	if store.count("orders") != 0 || store.count("payments") != 0 {
+		t.Errorf("orders = %d, payments = %d; want the order rolled back", store.count("orders"), store.count("payments"))
+	}
+	if store.rollbacks != 1 || store.commits != 0 {
+		t.Errorf("rollbacks = %d, commits = %d; want 1, 0", store.rollbacks, store.commits)
+	}
+	if len(publisher.keys) != 0 {
+		t.Errorf("published = %v, want no events after rollback", publisher.keys)
+	}
+}
+
+func TestUnitOfWorkRollsBackRepositoriesOnError(t *testing.T) {
+	store, db, _, _, _ := newTxFixture(t)
+	ordersRepo := repository.NewBaseRepository[orderEntity](nil)
+	paymentsRepo := repository.NewBaseRepository[paymentEntity](nil)
+
+	err := RunUnitOfWork(context.Background(), db, func(ctx context.Context, uow *UnitOfWork) error {
+		if uow.Tx() == nil {
+			t.Fatal("UnitOfWork.Tx() = nil")
+		}
+		if err := Repo(uow, ordersRepo).Create(ctx, &orderEntity{Name: "order-1"}); err != nil {
+			return err
+		}
+		return Repo(uow, paymentsRepo).Create(ctx, &paymentEntity{Name: "reject"})
+	})
+	if !errors.Is(err, errRejected) {
+		t.Fatalf("RunUnitOfWork() error = %v, want %v", err, errRejected)
+	}
+	if store.count("orders") != 0 || store.rollbacks != 1 {
+		t.Errorf("orders = %d, rollbacks = %d; want 0, 1", store.count("orders"), store.rollbacks)
+	}
+
+	// Без ошибок обе записи фиксируются
+	err = RunUnitOfWork(context.Background(), db, func(ctx context.Context, uow *UnitOfWork) error {
+		if err := Repo(uow, ordersRepo).Create(ctx, &orderEntity{Name: "order-2"}); err != nil {
+			return err
+		}
+		return Repo(uow, paymentsRepo).Create(ctx, &paymentEntity{Name: "payment-2"})
+	})
+	if err != nil {
+		t.Fatalf("RunUnitOfWork() error = %v", err)
+	}
+	if store.count("orders") != 1 || store.count("payments") != 1 {
+		t.Errorf("orders = %d, payments = %d; want 1, 1", store.count("orders"), store.count("payments"))
+	}
+}
+
+// logPublisher записывает публикуемые события в общий журнал действий
+type logPublisher struct {
+	log *[]string
+}
+
+func (p logPublisher) PublishEvent(ctx context.Context, key string, payload interface{}) error {
+	*p.log = append(*p.log, "event:"+key)
+	return nil
+}
+
+func TestRunInTransactionDefersAfterHooksUntilCommit(t *testing.T) {
+	store, db, orders, payments, _ := newTxFixture(t)
+
+	var actions []string
+	orders.publisher, payments.publisher = logPublisher{log: &actions}, logPublisher{log: &actions}
+	orders.OnAfterCreate(func(ctx context.Context, entity *orderEntity, response *orderEntity) error {
+		actions = append(actions, fmt.Sprintf("hook:order commits=%d", store.commits))
+		return nil
+	})
+	payments.OnAfterCreate(func(ctx context.Context, entity *paymentEntity, response *paymentEntity) error {
+		actions = append(actions, fmt.Sprintf("hook:payment commits=%d", store.commits))
+		return nil
+	})
+
+	err := RunInTransaction(context.Background(), db, func(ctx context.Context) error {
+		if _, err := orders.Create(ctx, orderInput{name: "order-1"}); err != nil {
+			return err
+		}
+		_, err := payments.Create(ctx, paymentInput{name: "payment-1"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("RunInTransaction() error = %v", err)
+	}
+
+	want := []string{
+		"event:" + orders.routingKey("created"),
+		"hook:order commits=1",
+		"event:" + payments.routingKey("created"),
+		"hook:payment commits=1",
+	}
+	if fmt.Sprint(actions) != fmt.Sprint(want) {
+		t.Errorf("actions = %v, want %v", actions, want)
+	}
+}
+
+func TestRunInTransactionDropsAfterHooksOnRollback(t *testing.T) {
+	_, db, orders, payments, _ := newTxFixture(t)
+
+	var hooks int
+	orders.OnAfterCreate(func(ctx context.Context, entity *orderEntity, response *orderEntity) error {
+		hooks++
+		return nil
+	})
+
+	err := RunInTransaction(context.Background(), db, func(ctx context.Context) error {
+		if _, err := orders.Create(ctx, orderInput{name: "order-1"}); err != nil {
+			return err
+		}
+		_, err := payments.Create(ctx, paymentInput{name: "reject"})
+		return err
+	})
+	if !errors.Is(err, errRejected) {
+		t.Fatalf("RunInTransaction() error = %v, want %v", err, errRejected)
+	}
+	if hooks != 0 {
+		t.Errorf("after hooks ran %d times, want none after rollback", hooks)
+	}
+}
+
+func TestRunInTransactionDefersCacheInvalidation(t *testing.T) {
+	_, db, _, _, _ := newTxFixture(t)
+	svc, inner, _ := newTestCachedService(t)
+	ctx := context.Background()
+
+	if _, err := svc.GetByID(ctx, 1); err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+
+	// Внутри транзакции кеш не читается и не заполняется, при откате инвалидация отбрасывается
+	err := RunInTransaction(ctx, db, func(ctx context.Context) error {
+		svc.Invalidate(ctx, 1)
+		if _, err := svc.GetByID(ctx, 1); err != nil {
+			return err
+		}
+		return errRejected
+	})
+	if !errors.Is(err, errRejected) {
+		t.Fatalf("RunInTransaction() error = %v, want %v", err, errRejected)
+	}
+	if _, err := svc.GetByID(ctx, 1); err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if inner.getByID != 2 {
+		t.Fatalf("inner GetByID calls = %d, want 2 (entry kept after rollback)", inner.getByID)
+	}
+
+	// После фиксации запись инвалидирована
+	err = RunInTransaction(ctx, db, func(ctx context.Context) error {
+		svc.Invalidate(ctx, 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunInTransaction() error = %v", err)
+	}
+	if _, err := svc.GetByID(ctx, 1); err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if inner.getByID != 3 {
+		t.Errorf("inner GetByID calls = %d, want 3 (entry invalidated after commit)", inner.getByID)
+	}
+}
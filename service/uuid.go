@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/vladzorgan/common/database"
+	apperrors "github.com/vladzorgan/common/errors"
+	"github.com/vladzorgan/common/eventcatalog"
+	"github.com/vladzorgan/common/messaging"
+	"github.com/vladzorgan/common/repository"
+	"gorm.io/gorm"
+)
+
+// UUIDEntity представляет сущность со строковым ID (например, UUID)
+type UUIDEntity interface {
+	repository.UUIDModel
+	GetName() string
+}
+
+// UUIDService определяет интерфейс сервиса для сущностей со строковым ID
+type UUIDService[T UUIDEntity, R any] interface {
+	// CRUD операции
+	Create(ctx context.Context, input CreateInput[T]) (*R, error)
+	GetByID(ctx context.Context, id string, opts ...repository.QueryOption) (*R, error)
+	GetByIDs(ctx context.Context, ids []string, opts ...repository.QueryOption) (map[string]R, error)
+	Update(ctx context.Context, id string, input UpdateInput[T]) (*R, error)
+	Delete(ctx context.Context, id string) (*R, error)
+
+	// Массовые операции
+	BulkCreate(ctx context.Context, inputs []CreateInput[T]) ([]R, error)
+
+	// Операции с коллекциями
+	GetAll(ctx context.Context, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) (*PaginationResponse[R], error)
+	Search(ctx context.Context, keyword string, skip, limit int, filters map[string]interface{}, sort *repository.SortOptions, opts ...repository.QueryOption) (*PaginationResponse[R], error)
+	GetByField(ctx context.Context, field string, value interface{}, opts ...repository.QueryOption) (*R, error)
+	GetAllByField(ctx context.Context, field string, value interface{}, skip, limit int, opts ...repository.QueryOption) (*PaginationResponse[R], error)
+	Stream(ctx context.Context, filters map[string]interface{}, sort *repository.SortOptions, fn func(response *R) error) error
+	StreamTransformed(ctx context.Context, filters map[string]interface{}, sort *repository.SortOptions, batchSize int) (<-chan R, <-chan error)
+
+	// Дополнительные операции
+	Count(ctx context.Context, filters map[string]interface{}, opts ...repository.QueryOption) (int64, error)
+	Exists(ctx context.Context, id string) (bool, error)
+}
+
+// BaseUUIDService представляет базовую реализацию сервиса для сущностей со строковым ID.
+// Транзакции, публикация событий после фиксации и операции с коллекциями работают так же,
+// как в BaseService; события используют UUIDEntityEvent и UUIDBulkEvent.
+// Обработчики операций, журнал аудита и политика удаления пока доступны только в BaseService.
+type BaseUUIDService[T UUIDEntity, R any] struct {
+	serviceCore[T, R]
+	repo repository.UUIDRepository[T]
+}
+
+// NewBaseUUIDService создает новый экземпляр BaseUUIDService (см. NewBaseService)
+func NewBaseUUIDService[T UUIDEntity, R any, E EntityName](
+	repo repository.UUIDRepository[T],
+	transformer EntityTransformer[T, R],
+	publisher messaging.Publisher,
+	entity E,
+) *BaseUUIDService[T, R] {
+	return NewBaseUUIDServiceWithTx(repo, transformer, publisher, entity, nil)
+}
+
+// NewBaseUUIDServiceWithTx создает экземпляр BaseUUIDService, выполняющий операции записи
+// в транзакции txRunner (см. NewBaseServiceWithTx)
+func NewBaseUUIDServiceWithTx[T UUIDEntity, R any, E EntityName](
+	repo repository.UUIDRepository[T],
+	transformer EntityTransformer[T, R],
+	publisher messaging.Publisher,
+	entity E,
+	txRunner database.TxRunner,
+) *BaseUUIDService[T, R] {
+	return &BaseUUIDService[T, R]{
+		serviceCore: newServiceCore[T, R](repo, transformer, publisher, entity, txRunner),
+		repo:        repo,
+	}
+}
+
+// Create создает новую сущность
+func (s *BaseUUIDService[T, R]) Create(ctx context.Context, input CreateInput[T]) (*R, error) {
+	// Валидация входных данных
+	if err := validateInput(ctx, input); err != nil {
+		return nil, validationError(s.entity.Singular, err)
+	}
+
+	entity := input.ToEntity()
+	err := s.runWrite(ctx, func(ctx context.Context, repo repository.UUIDRepository[T], pending *[]pendingEvent) error {
+		if err := repo.Create(ctx, entity); err != nil {
+			return s.wrapRepoError(err, nil, fmt.Sprintf("не удалось создать %s", s.entity.DisplayNameRu))
+		}
+
+		// Публикуем событие о создании после фиксации
+		*pending = append(*pending, s.entityEvent("created", entity, nil))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Создан новый %s: %s (ID: %s)", s.entity.DisplayNameRu, (*entity).GetName(), (*entity).GetID())
+
+	return s.transformer.Transform(entity), nil
+}
+
+// BulkCreate создает множество новых сущностей
+func (s *BaseUUIDService[T, R]) BulkCreate(ctx context.Context, inputs []CreateInput[T]) ([]R, error) {
+	if len(inputs) == 0 {
+		return []R{}, nil
+	}
+
+	// Валидация всех входных данных
+	entities := make([]*T, 0, len(inputs))
+	for i, input := range inputs {
+		if err := validateInput(ctx, input); err != nil {
+			return nil, itemValidationError(s.entity.Singular, i, err)
+		}
+		entities = append(entities, input.ToEntity())
+	}
+
+	err := s.runWrite(ctx, func(ctx context.Context, repo repository.UUIDRepository[T], pending *[]pendingEvent) error {
+		if err := repo.BulkCreate(ctx, entities); err != nil {
+			return s.wrapRepoError(err, nil, fmt.Sprintf("не удалось создать %s", s.entity.DisplayNameRu))
+		}
+
+		// Публикуем событие о массовом создании после фиксации
+		if event, ok := s.bulkEvent("bulk_created", entities); ok {
+			*pending = append(*pending, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Создано %d новых %s", len(entities), s.entity.DisplayNameRu)
+
+	responses := make([]R, 0, len(entities))
+	for _, entity := range entities {
+		responses = append(responses, *s.transformer.Transform(entity))
+	}
+
+	return responses, nil
+}
+
+// GetByID получает сущность по ID
+func (s *BaseUUIDService[T, R]) GetByID(ctx context.Context, id string, opts ...repository.QueryOption) (*R, error) {
+	entity, err := s.repo.GetByID(ctx, id, opts...)
+	if err != nil {
+		return nil, s.wrapRepoError(err, id, fmt.Sprintf("ошибка при получении %s", s.entity.DisplayNameRu))
+	}
+
+	if entity == nil {
+		return nil, apperrors.NotFound(s.entity.Singular, id)
+	}
+
+	return s.transformer.Transform(entity), nil
+}
+
+// GetByIDs получает сущности по списку ID одним запросом.
+// Результат индексирован по ID; отсутствующие ID в нем просто не представлены.
+func (s *BaseUUIDService[T, R]) GetByIDs(ctx context.Context, ids []string, opts ...repository.QueryOption) (map[string]R, error) {
+	entities, err := s.repo.GetByIDs(ctx, ids, opts...)
+	if err != nil {
+		return nil, s.wrapRepoError(err, nil, fmt.Sprintf("ошибка при получении списка %s", s.entity.DisplayNameRu))
+	}
+
+	responses := make(map[string]R, len(entities))
+	for i := range entities {
+		responses[entities[i].GetID()] = *s.transformer.Transform(&entities[i])
+	}
+
+	return responses, nil
+}
+
+// Update обновляет сущность
+func (s *BaseUUIDService[T, R]) Update(ctx context.Context, id string, input UpdateInput[T]) (*R, error) {
+	var updatedEntity *T
+
+	err := s.runWrite(ctx, func(ctx context.Context, repo repository.UUIDRepository[T], pending *[]pendingEvent) error {
+		exists, err := repo.Exists(ctx, id)
+		if err != nil {
+			return s.wrapRepoError(err, id, fmt.Sprintf("ошибка при проверке существования %s", s.entity.DisplayNameRu))
+		}
+		if !exists {
+			return apperrors.NotFound(s.entity.Singular, id)
+		}
+
+		// Валидация входных данных
+		if err := validateInput(ctx, input); err != nil {
+			return validationError(s.entity.Singular, err)
+		}
+
+		updates := input.ToUpdateMap()
+		if len(updates) == 0 {
+			return apperrors.New(apperrors.ErrValidation, s.entity.Singular, id, "нет данных для обновления")
+		}
+
+		updatedEntity, err = repo.Update(ctx, id, updates)
+		if err != nil {
+			return s.wrapRepoError(err, id, fmt.Sprintf("не удалось обновить %s", s.entity.DisplayNameRu))
+		}
+		if updatedEntity == nil {
+			return apperrors.NotFound(s.entity.Singular, id)
+		}
+
+		// Публикуем событие об обновлении после фиксации
+		updatedFields := make([]string, 0, len(updates))
+		for key := range updates {
+			updatedFields = append(updatedFields, key)
+		}
+		*pending = append(*pending, s.entityEvent("updated", updatedEntity, updatedFields))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Обновлен %s: %s (ID: %s)", s.entity.DisplayNameRu, (*updatedEntity).GetName(), id)
+
+	return s.transformer.Transform(updatedEntity), nil
+}
+
+// Delete удаляет сущность
+func (s *BaseUUIDService[T, R]) Delete(ctx context.Context, id string) (*R, error) {
+	var deletedEntity *T
+
+	err := s.runWrite(ctx, func(ctx context.Context, repo repository.UUIDRepository[T], pending *[]pendingEvent) error {
+		// Репозиторий возвращает сущность в состоянии до удаления
+		var err error
+		deletedEntity, err = repo.Delete(ctx, id)
+		if err != nil {
+			return s.wrapRepoError(err, id, fmt.Sprintf("не удалось удалить %s", s.entity.DisplayNameRu))
+		}
+		if deletedEntity == nil {
+			return apperrors.NotFound(s.entity.Singular, id)
+		}
+
+		// Публикуем событие об удалении после фиксации
+		*pending = append(*pending, s.entityEvent("deleted", deletedEntity, nil))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Удален %s: %s (ID: %s)", s.entity.DisplayNameRu, (*deletedEntity).GetName(), id)
+
+	return s.transformer.Transform(deletedEntity), nil
+}
+
+// Exists проверяет существование сущности
+func (s *BaseUUIDService[T, R]) Exists(ctx context.Context, id string) (bool, error) {
+	exists, err := s.repo.Exists(ctx, id)
+	if err != nil {
+		return false, s.wrapRepoError(err, id, fmt.Sprintf("ошибка при проверке существования %s", s.entity.DisplayNameRu))
+	}
+
+	return exists, nil
+}
+
+// RegisterEvents регистрирует в каталоге стандартные события сервиса
+func (s *BaseUUIDService[T, R]) RegisterEvents(catalog *eventcatalog.Catalog) error {
+	return s.registerEvents(catalog, UUIDEntityEvent{}, UUIDBulkEvent{},
+		func(eventType string) interface{} {
+			return UUIDEntityEvent{
+				ID:         "8f14e45f-ceea-4e67-a1d2-7a4b2d6f0c11",
+				Name:       "example",
+				EventType:  eventType,
+				EntityType: s.entity.Singular,
+			}
+		},
+		func(eventType string) interface{} {
+			return UUIDBulkEvent{
+				IDs:        []string{"8f14e45f-ceea-4e67-a1d2-7a4b2d6f0c11", "c9f0f895-fb98-4b91-8f5e-1d2a3b4c5d6e"},
+				Names:      []string{"first", "second"},
+				Count:      2,
+				EventType:  eventType,
+				EntityType: s.entity.Singular,
+			}
+		},
+	)
+}
+
+// runWrite выполняет fn в транзакции с репозиторием, привязанным к ней; чтения внутри fn
+// выполняются на primary (см. BaseService.runWrite)
+func (s *BaseUUIDService[T, R]) runWrite(ctx context.Context, fn func(ctx context.Context, repo repository.UUIDRepository[T], pending *[]pendingEvent) error) error {
+	ctx = database.ForcePrimary(ctx)
+	return s.runInTransaction(ctx, func(ctx context.Context, tx *gorm.DB, pending *[]pendingEvent) error {
+		repo := s.repo
+		if tx != nil {
+			repo = s.repo.WithTx(tx)
+		}
+		return fn(ctx, repo, pending)
+	})
+}
+
+// entityEvent формирует событие об операции с сущностью
+func (s *BaseUUIDService[T, R]) entityEvent(eventType string, entity *T, updatedFields []string) pendingEvent {
+	return pendingEvent{
+		name: s.routingKey(eventType),
+		data: UUIDEntityEvent{
+			ID:            (*entity).GetID(),
+			Name:          (*entity).GetName(),
+			EventType:     eventType,
+			EntityType:    s.entity.Singular,
+			UpdatedFields: updatedFields,
+		},
+	}
+}
+
+// bulkEvent формирует событие массовой операции; для пустого списка события нет
+func (s *BaseUUIDService[T, R]) bulkEvent(eventType string, entities []*T) (pendingEvent, bool) {
+	if len(entities) == 0 {
+		return pendingEvent{}, false
+	}
+
+	entityIDs := make([]string, 0, len(entities))
+	entityNames := make([]string, 0, len(entities))
+	for _, entity := range entities {
+		entityIDs = append(entityIDs, (*entity).GetID())
+		entityNames = append(entityNames, (*entity).GetName())
+	}
+
+	return pendingEvent{
+		name: s.routingKey(eventType),
+		data: UUIDBulkEvent{
+			IDs:        entityIDs,
+			Names:      entityNames,
+			Count:      len(entities),
+			EventType:  eventType,
+			EntityType: s.entity.Singular,
+		},
+	}, true
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vladzorgan/common/database"
+	apperrors "github.com/vladzorgan/common/errors"
+	"github.com/vladzorgan/common/repository"
+)
+
+type uuidEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (e uuidEntity) GetID() string        { return e.ID }
+func (e uuidEntity) GetName() string      { return e.Name }
+func (e uuidEntity) GetTableName() string { return "uuid_entities" }
+
+type uuidTransformer struct{}
+
+func (uuidTransformer) Transform(entity *uuidEntity) *uuidEntity { return entity }
+func (uuidTransformer) TransformSlice(entities []uuidEntity) []uuidEntity {
+	return entities
+}
+
+// memoryUUIDRepository хранит сущности в памяти; неиспользуемые методы не реализованы
+type memoryUUIDRepository struct {
+	repository.UUIDRepository[uuidEntity]
+	items map[string]uuidEntity
+	// Признак чтения с primary для каждого вызова Exists
+	primaryReads []bool
+}
+
+func (r *memoryUUIDRepository) Create(ctx context.Context, entity *uuidEntity) error {
+	r.items[entity.ID] = *entity
+	return nil
+}
+
+func (r *memoryUUIDRepository) GetByID(ctx context.Context, id string, opts ...repository.QueryOption) (*uuidEntity, error) {
+	if entity, ok := r.items[id]; ok {
+		return &entity, nil
+	}
+	return nil, nil
+}
+
+func (r *memoryUUIDRepository) Exists(ctx context.Context, id string) (bool, error) {
+	r.primaryReads = append(r.primaryReads, database.HasWriteIntent(ctx))
+	_, ok := r.items[id]
+	return ok, nil
+}
+
+func (r *memoryUUIDRepository) Update(ctx context.Context, id string, updates map[string]interface{}) (*uuidEntity, error) {
+	entity := r.items[id]
+	entity.Name = updates["name"].(string)
+	r.items[id] = entity
+	return &entity, nil
+}
+
+func (r *memoryUUIDRepository) Delete(ctx context.Context, id string) (*uuidEntity, error) {
+	entity, ok := r.items[id]
+	if !ok {
+		return nil, nil
+	}
+	delete(r.items, id)
+	return &entity, nil
+}
+
+type uuidInput struct{ entity uuidEntity }
+
+func (i uuidInput) ToEntity() *uuidEntity { return &i.entity }
+func (i uuidInput) Validate() error       { return nil }
+
+type uuidUpdate struct{ name string }
+
+func (u uuidUpdate) ToUpdateMap() map[string]interface{} {
+	return map[string]interface{}{"name": u.name}
+}
+func (u uuidUpdate) Validate() error { return nil }
+
+func TestBaseUUIDService(t *testing.T) {
+	ctx := context.Background()
+	id := "8f14e45f-ceea-4e67-a1d2-7a4b2d6f0c11"
+	publisher := &recordingPublisher{}
+	repo := &memoryUUIDRepository{items: map[string]uuidEntity{}}
+	s := NewBaseUUIDService[uuidEntity, uuidEntity](repo, uuidTransformer{}, publisher, "device")
+
+	if _, err := s.Create(ctx, uuidInput{entity: uuidEntity{ID: id, Name: "phone"}}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if got, err := s.GetByID(ctx, id); err != nil || got.Name != "phone" {
+		t.Fatalf("GetByID() = %v, %v", got, err)
+	}
+	if _, err := s.Delete(ctx, id); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.GetByID(ctx, id); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("GetByID() after delete error = %v, want not found", err)
+	}
+
+	if len(publisher.payloads) != 2 || publisher.keys[1] != "device.deleted" {
+		t.Fatalf("published = %v", publisher.keys)
+	}
+	event, ok := publisher.payloads[0].(UUIDEntityEvent)
+	if !ok || event.ID != id || event.EventType != "created" || event.EntityType != "device" {
+		t.Errorf("created event = %#v", publisher.payloads[0])
+	}
+}
+
+func TestBaseUUIDServiceWritesReadFromPrimary(t *testing.T) {
+	id := "8f14e45f-ceea-4e67-a1d2-7a4b2d6f0c11"
+	repo := &memoryUUIDRepository{items: map[string]uuidEntity{id: {ID: id, Name: "phone"}}}
+	s := NewBaseUUIDService[uuidEntity, uuidEntity](repo, uuidTransformer{}, nil, "device")
+
+	if _, err := s.Exists(context.Background(), id); err != nil {
+		t.Fatalf("Exists() error = %v", err)
+	}
+	if _, err := s.Update(context.Background(), id, uuidUpdate{name: "tablet"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	// Обычное чтение может идти на реплику, проверка существования при обновлении - только на primary
+	if len(repo.primaryReads) != 2 || repo.primaryReads[0] || !repo.primaryReads[1] {
+		t.Errorf("primary reads = %v, want [false true]", repo.primaryReads)
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	apperrors "github.com/vladzorgan/common/errors"
+	"github.com/vladzorgan/common/validation"
+)
+
+// ContextValidator реализуется входными данными, проверка которых использует контекст запроса,
+// например, правила exists через validation.StructCtx. Сервис вызывает ValidateCtx вместо Validate.
+type ContextValidator interface {
+	ValidateCtx(ctx context.Context) error
+}
+
+// validateInput проверяет входные данные через ValidateCtx, если он реализован, иначе через Validate
+func validateInput(ctx context.Context, input interface{ Validate() error }) error {
+	if validator, ok := input.(ContextValidator); ok {
+		return validator.ValidateCtx(ctx)
+	}
+	return input.Validate()
+}
+
+// validationError оборачивает ошибку Validate() в ErrValidation. Ошибки validation.ValidationErrors
+// сохраняются по полям в Error.Fields, сбои проверок (ErrInternal) возвращаются как есть.
+func validationError(entity string, err error) error {
+	if stderrors.Is(err, apperrors.ErrInternal) {
+		return err
+	}
+
+	typedErr := apperrors.Validation(entity, err)
+	typedErr.Fields = validationFields(err, "")
+	return typedErr
+}
+
+// itemValidationError оборачивает ошибку валидации элемента массовой операции;
+// имена полей дополняются индексом элемента (например, "[2].name")
+func itemValidationError(entity string, index int, err error) error {
+	if stderrors.Is(err, apperrors.ErrInternal) {
+		return err
+	}
+
+	typedErr := apperrors.Wrap(apperrors.ErrValidation, entity, nil, err, fmt.Sprintf("ошибка валидации элемента %d", index))
+	typedErr.Fields = validationFields(err, fmt.Sprintf("[%d].", index))
+	return typedErr
+}
+
+// validationFields возвращает сообщения ошибок по полям с префиксом или nil
+func validationFields(err error, prefix string) map[string]string {
+	var fieldErrs validation.ValidationErrors
+	if !stderrors.As(err, &fieldErrs) || len(fieldErrs) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]string, len(fieldErrs))
+	for field, message := range fieldErrs {
+		fields[prefix+field] = message
+	}
+	return fields
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apperrors "github.com/vladzorgan/common/errors"
+	"github.com/vladzorgan/common/validation"
+)
+
+func TestValidationErrorFields(t *testing.T) {
+	var typedErr *apperrors.Error
+
+	err := validationError("city", validation.ValidationErrors{"name": "name обязательное поле"})
+	if !errors.As(err, &typedErr) || !errors.Is(err, apperrors.ErrValidation) || typedErr.Fields["name"] == "" {
+		t.Errorf("error = %#v", err)
+	}
+
+	err = itemValidationError("city", 2, validation.ValidationErrors{"name": "name обязательное поле"})
+	if !errors.As(err, &typedErr) || typedErr.Fields["[2].name"] == "" {
+		t.Errorf("item error = %#v", err)
+	}
+
+	// Ошибки собственных реализаций Validate() остаются ошибками валидации без полей
+	err = validationError("city", errors.New("invalid"))
+	if !errors.As(err, &typedErr) || !errors.Is(err, apperrors.ErrValidation) || typedErr.Fields != nil {
+		t.Errorf("plain error = %#v", err)
+	}
+
+	internal := apperrors.Wrap(apperrors.ErrInternal, "", nil, errors.New("db down"), "ошибка проверки данных")
+	if err := validationError("city", internal); errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("internal error wrapped as validation: %v", err)
+	}
+}
+
+// ctxInput проверяет ссылку через правило exists, которому нужен контекст запроса
+type ctxInput struct {
+	CityID uint `json:"city_id" validate:"exists=validation_test_city"`
+}
+
+func (i ctxInput) ToEntity() *auditEntity { return &auditEntity{Name: "order"} }
+func (i ctxInput) Validate() error        { return validation.Struct(i) }
+func (i ctxInput) ValidateCtx(ctx context.Context) error {
+	return validation.StructCtx(ctx, i)
+}
+
+type tenantKey struct{}
+
+func TestServiceUsesValidateCtx(t *testing.T) {
+	// Проверка видит только города арендатора из контекста запроса
+	validation.RegisterExistsCheck("validation_test_city", func(ctx context.Context, id uint) (bool, error) {
+		return ctx.Value(tenantKey{}) == "moscow" && id == 1, nil
+	})
+
+	repo := &bulkRepository{memoryRepository: &memoryRepository{items: map[uint]auditEntity{}}}
+	s := NewBaseService[auditEntity, auditEntity](repo, auditTransformer{}, nil, "order")
+	ctx := context.WithValue(context.Background(), tenantKey{}, "moscow")
+
+	if _, err := s.BulkCreate(ctx, []CreateInput[auditEntity]{ctxInput{CityID: 1}}); err != nil {
+		t.Fatalf("BulkCreate() error = %v", err)
+	}
+
+	_, err := s.BulkCreate(context.Background(), []CreateInput[auditEntity]{ctxInput{CityID: 1}})
+	var typedErr *apperrors.Error
+	if !errors.As(err, &typedErr) || typedErr.Fields["[0].city_id"] == "" {
+		t.Errorf("BulkCreate() without tenant error = %v, want field error for city_id", err)
+	}
+}
@@ -0,0 +1,171 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// maxResponseBytes ограничивает размер читаемого ответа Bot API
+const maxResponseBytes = 64 << 10
+
+// APIError ошибка, возвращенная Telegram Bot API
+type APIError struct {
+	// HTTP статус ответа
+	StatusCode int
+	// Код ошибки Bot API (error_code)
+	ErrorCode int
+	// Описание ошибки (description)
+	Description string
+	// Время, через которое можно повторить запрос (parameters.retry_after для ответа 429)
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("telegram API returned status code: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("telegram API returned status code: %d: %s", e.StatusCode, e.Description)
+}
+
+// apiResponse ответ Bot API
+type apiResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+	Parameters  *struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// apiRequest тело запроса к методу Bot API
+type apiRequest struct {
+	body        []byte
+	contentType string
+	err         error
+}
+
+// jsonRequest формирует запрос с JSON телом
+func jsonRequest(payload interface{}) apiRequest {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return apiRequest{err: fmt.Errorf("failed to marshal telegram request: %w", err)}
+	}
+	return apiRequest{body: body, contentType: "application/json"}
+}
+
+// multipartRequest формирует запрос multipart/form-data с полями fields и файлом field
+func multipartRequest(fields map[string]string, field string, file InputFile) apiRequest {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for name, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := writer.WriteField(name, value); err != nil {
+			return apiRequest{err: fmt.Errorf("failed to build telegram request: %w", err)}
+		}
+	}
+
+	part, err := writer.CreateFormFile(field, file.Name)
+	if err == nil {
+		_, err = part.Write(file.Data)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return apiRequest{err: fmt.Errorf("failed to build telegram request: %w", err)}
+	}
+
+	return apiRequest{body: body.Bytes(), contentType: writer.FormDataContentType()}
+}
+
+// call вызывает метод Bot API. Ответ 429 повторяется после retry_after, если ожидание
+// не превышает maxRetryAfter и не исчерпаны повторы. Ответы 5xx и ошибки соединения не повторяются:
+// сообщение могло быть доставлено, и повтор отправил бы его дважды.
+func (c *TelegramClient) call(ctx context.Context, method string, request apiRequest) error {
+	if request.err != nil {
+		return request.err
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := c.send(ctx, method, request)
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.RetryAfter <= 0 {
+			return err
+		}
+		if attempt >= c.options.maxRetries || apiErr.RetryAfter > c.options.maxRetryAfter {
+			return err
+		}
+
+		c.options.logger.WithContext(ctx).Warn("Telegram %s rate limited, retrying in %s", method, apiErr.RetryAfter)
+		if err := c.options.sleep(ctx, apiErr.RetryAfter); err != nil {
+			return err
+		}
+	}
+}
+
+// send выполняет одну попытку вызова метода Bot API
+func (c *TelegramClient) send(ctx context.Context, method string, request apiRequest) error {
+	req, err := c.httpClient.NewRequest(ctx, http.MethodPost, "/bot"+c.botToken+"/"+method, bytes.NewReader(request.body))
+	if err != nil {
+		return fmt.Errorf("failed to create telegram request: %w", redactURL(err))
+	}
+	req.Header.Set("Content-Type", request.contentType)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telegram message: %w", redactURL(err))
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+
+	var result apiResponse
+	decodeErr := json.Unmarshal(data, &result)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 && (decodeErr != nil || result.OK) {
+		return nil
+	}
+
+	apiErr := &APIError{
+		StatusCode:  resp.StatusCode,
+		ErrorCode:   result.ErrorCode,
+		Description: result.Description,
+	}
+	if result.Parameters != nil && result.Parameters.RetryAfter > 0 {
+		apiErr.RetryAfter = time.Duration(result.Parameters.RetryAfter) * time.Second
+	}
+	return apiErr
+}
+
+// redactURL убирает из ошибки адрес запроса: путь содержит токен бота
+func redactURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s: %w", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
+
+// sleepContext ожидает d или отмены контекста
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
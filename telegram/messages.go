@@ -0,0 +1,231 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MaxMessageLength наибольшая длина текста сообщения в Telegram (в кодовых единицах UTF-16)
+const MaxMessageLength = 4096
+
+// InlineButton кнопка inline-клавиатуры: ссылка (URL) или кнопка с данными обратного вызова
+type InlineButton struct {
+	Text         string `json:"text"`
+	URL          string `json:"url,omitempty"`
+	CallbackData string `json:"callback_data,omitempty"`
+}
+
+// InlineKeyboardMarkup inline-клавиатура сообщения; каждый элемент InlineKeyboard - ряд кнопок
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineButton `json:"inline_keyboard"`
+}
+
+// InputFile файл для отправки. Если задан FileID (file_id ранее загруженного файла или URL),
+// Telegram получает файл сам; иначе содержимое Data загружается с именем Name.
+type InputFile struct {
+	FileID string
+	Name   string
+	Data   []byte
+}
+
+// SendPhoto отправляет фотографию с подписью в HTML (пустой chatID - чат клиента)
+func (c *TelegramClient) SendPhoto(ctx context.Context, chatID string, photo InputFile, caption string) error {
+	return c.sendFile(ctx, "sendPhoto", "photo", chatID, photo, caption)
+}
+
+// SendDocument отправляет документ с подписью в HTML (пустой chatID - чат клиента)
+func (c *TelegramClient) SendDocument(ctx context.Context, chatID string, document InputFile, caption string) error {
+	return c.sendFile(ctx, "sendDocument", "document", chatID, document, caption)
+}
+
+// sendFile отправляет файл методом method в поле field
+func (c *TelegramClient) sendFile(ctx context.Context, method, field, chatID string, file InputFile, caption string) error {
+	chatID, err := c.resolveChat(chatID)
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]string{
+		"chat_id":    chatID,
+		"caption":    caption,
+		"parse_mode": "HTML",
+	}
+	if caption == "" {
+		delete(fields, "parse_mode")
+	}
+
+	if file.Data == nil {
+		payload := map[string]string{field: file.FileID}
+		for name, value := range fields {
+			if value != "" {
+				payload[name] = value
+			}
+		}
+		return c.call(ctx, method, jsonRequest(payload))
+	}
+	return c.call(ctx, method, multipartRequest(fields, field, file))
+}
+
+// htmlToken неделимая часть HTML текста: тег, сущность (&amp;) или один символ
+type htmlToken struct {
+	text  string
+	width int
+	// Имя тега для открывающих и закрывающих тегов
+	tag     string
+	closing bool
+}
+
+// splitMessage разбивает текст с HTML разметкой на части не длиннее limit кодовых единиц UTF-16,
+// по возможности по переводу строки или пробелу. Теги и сущности не разрываются; теги, открытые
+// на границе частей, закрываются в конце части и открываются заново в начале следующей.
+func splitMessage(text string, limit int) []string {
+	var parts []string
+	tokens := tokenizeHTML(text)
+	// Теги, открытые к началу текущей части
+	var open []htmlToken
+
+	for {
+		prefix, size := "", 0
+		for _, tag := range open {
+			prefix += tag.text
+			size += tag.width
+		}
+
+		// Находим наибольший префикс, помещающийся в limit вместе с закрывающими тегами
+		stack := append([]htmlToken(nil), open...)
+		end, lineBreak, space := len(tokens), -1, -1
+		var lineBreakStack, spaceStack, endStack []htmlToken
+		for i, token := range tokens {
+			next := applyTag(stack, token)
+			if size+token.width+closingWidth(next) > limit && i > 0 {
+				end = i
+				break
+			}
+			size += token.width
+			switch token.text {
+			case "\n":
+				lineBreak, lineBreakStack = i, stack
+			case " ":
+				space, spaceStack = i, stack
+			}
+			stack = next
+		}
+		endStack = stack
+
+		if end == len(tokens) {
+			if len(tokens) > 0 || len(parts) == 0 {
+				parts = append(parts, prefix+joinTokens(tokens))
+			}
+			return parts
+		}
+
+		// Разрыв по переводу строки или пробелу, если он не слишком близко к началу части
+		cut, cutStack := end, endStack
+		if lineBreak > end/2 {
+			cut, cutStack = lineBreak, lineBreakStack
+		} else if space > end/2 {
+			cut, cutStack = space, spaceStack
+		}
+
+		part := strings.TrimRight(prefix+joinTokens(tokens[:cut]), " \n")
+		for i := len(cutStack) - 1; i >= 0; i-- {
+			part += "</" + cutStack[i].tag + ">"
+		}
+		parts = append(parts, part)
+
+		tokens = tokens[cut:]
+		if cut < end {
+			// Пропускаем разделитель
+			tokens = tokens[1:]
+		}
+		open = cutStack
+	}
+}
+
+// tokenizeHTML разбивает текст на теги, сущности и отдельные символы
+func tokenizeHTML(text string) []htmlToken {
+	var tokens []htmlToken
+	for len(text) > 0 {
+		size := 0
+		switch text[0] {
+		case '<':
+			if end := strings.IndexByte(text, '>'); end > 1 {
+				size = end + 1
+			}
+		case '&':
+			if end := strings.IndexByte(text, ';'); end > 1 && end <= 10 {
+				size = end + 1
+			}
+		}
+		if size == 0 {
+			_, size = utf8.DecodeRuneInString(text)
+		}
+
+		token := htmlToken{text: text[:size], width: utf16Len(text[:size])}
+		if size > 1 && text[0] == '<' {
+			token.tag, token.closing = tagName(text[1 : size-1])
+		}
+		tokens = append(tokens, token)
+		text = text[size:]
+	}
+	return tokens
+}
+
+// tagName возвращает имя тега по его содержимому между < и >
+func tagName(content string) (string, bool) {
+	closing := strings.HasPrefix(content, "/")
+	content = strings.TrimPrefix(content, "/")
+	if end := strings.IndexAny(content, " \t\n"); end >= 0 {
+		content = content[:end]
+	}
+	return strings.ToLower(content), closing
+}
+
+// applyTag возвращает стек открытых тегов после token; исходный стек не изменяется
+func applyTag(stack []htmlToken, token htmlToken) []htmlToken {
+	if token.tag == "" {
+		return stack
+	}
+	if !token.closing {
+		return append(append([]htmlToken(nil), stack...), token)
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].tag == token.tag {
+			return append(append([]htmlToken(nil), stack[:i]...), stack[i+1:]...)
+		}
+	}
+	return stack
+}
+
+// closingWidth возвращает длину закрывающих тегов для стека
+func closingWidth(stack []htmlToken) int {
+	width := 0
+	for _, tag := range stack {
+		width += len(tag.tag) + 3
+	}
+	return width
+}
+
+// joinTokens склеивает текст токенов
+func joinTokens(tokens []htmlToken) string {
+	var builder strings.Builder
+	for _, token := range tokens {
+		builder.WriteString(token.text)
+	}
+	return builder.String()
+}
+
+// utf16Len возвращает длину строки в кодовых единицах UTF-16
+func utf16Len(text string) int {
+	width := 0
+	for _, r := range text {
+		if n := utf16.RuneLen(r); n > 0 {
+			width += n
+		} else {
+			width++
+		}
+	}
+	return width
+}
@@ -2,79 +2,195 @@
 package telegram
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/vladzorgan/common/httpclient"
+	"github.com/vladzorgan/common/logging"
 )
 
+// apiURL адрес Telegram Bot API
+const apiURL = "https://api.telegram.org"
+
+// ErrNotConfigured возвращается, если не заданы токен бота или чат
+var ErrNotConfigured = errors.New("telegram bot token or chat ID not configured")
+
 // TelegramMessage представляет сообщение для отправки в Telegram
 type TelegramMessage struct {
-	ChatID    string `json:"chat_id"`
-	Text      string `json:"text"`
-	ParseMode string `json:"parse_mode,omitempty"`
+	ChatID      string                `json:"chat_id"`
+	Text        string                `json:"text"`
+	ParseMode   string                `json:"parse_mode,omitempty"`
+	ReplyMarkup *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+}
+
+// Option настраивает TelegramClient
+type Option func(*options)
+
+// options содержит настройки TelegramClient
+type options struct {
+	apiURL        string
+	httpClient    *http.Client
+	maxRetries    int
+	maxRetryAfter time.Duration
+	logger        logging.Logger
+	// sleep ожидает перед повтором; подменяется в тестах
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// WithAPIURL задает адрес Bot API (например, локального сервера Bot API)
+func WithAPIURL(url string) Option {
+	return func(o *options) {
+		o.apiURL = url
+	}
+}
+
+// WithHTTPClient задает http.Client (например, с telegramtest.Transport в тестах)
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithRateLimitRetry задает количество повторов после ответа 429 и наибольшее время ожидания
+// retry_after, которое клиент готов выдержать (по умолчанию 3 повтора и 30 секунд)
+func WithRateLimitRetry(maxRetries int, maxRetryAfter time.Duration) Option {
+	return func(o *options) {
+		o.maxRetries = maxRetries
+		o.maxRetryAfter = maxRetryAfter
+	}
+}
+
+// WithLogger задает логгер повторных попыток
+func WithLogger(logger logging.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
 }
 
 // TelegramClient клиент для работы с Telegram Bot API
 type TelegramClient struct {
-	botToken string
-	chatID   string
-	httpClient *http.Client
+	botToken   string
+	chatID     string
+	options    *options
+	httpClient *httpclient.Client
 }
 
 // NewTelegramClient создает новый клиент для работы с Telegram
-func NewTelegramClient(botToken, chatID string) *TelegramClient {
+func NewTelegramClient(botToken, chatID string, opts ...Option) *TelegramClient {
+	o := &options{
+		apiURL:        apiURL,
+		maxRetries:    3,
+		maxRetryAfter: 30 * time.Second,
+		sleep:         sleepContext,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.logger == nil {
+		o.logger = logging.NewLogger()
+	}
+
+	// Повторы httpclient отключены: sendMessage неидемпотентен, а ответ 429 повторяет call
+	clientOpts := []httpclient.Option{
+		httpclient.WithTimeout(10 * time.Second),
+		httpclient.WithLogger(o.logger),
+		httpclient.WithRetry(0, 0, 0),
+	}
+	if o.httpClient != nil {
+		clientOpts = append(clientOpts, httpclient.WithHTTPClient(o.httpClient))
+	}
+
 	return &TelegramClient{
-		botToken: botToken,
-		chatID:   chatID,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		botToken:   botToken,
+		chatID:     chatID,
+		options:    o,
+		httpClient: httpclient.New(o.apiURL, clientOpts...),
 	}
 }
 
 // SendMessage отправляет сообщение в Telegram
 func (c *TelegramClient) SendMessage(text string) error {
-	if c.botToken == "" || c.chatID == "" {
-		return fmt.Errorf("telegram bot token or chat ID not configured")
-	}
+	return c.SendMessageToCtx(context.Background(), c.chatID, text)
+}
 
-	message := TelegramMessage{
-		ChatID:    c.chatID,
-		Text:      text,
-		ParseMode: "HTML",
-	}
+// SendMessageCtx отправляет сообщение в чат клиента с учетом отмены контекста
+func (c *TelegramClient) SendMessageCtx(ctx context.Context, text string) error {
+	return c.SendMessageToCtx(ctx, c.chatID, text)
+}
 
-	jsonData, err := json.Marshal(message)
-	if err != nil {
-		return fmt.Errorf("failed to marshal telegram message: %v", err)
-	}
+// SendMessageTo отправляет сообщение в указанный чат; пустой chatID заменяется чатом клиента
+func (c *TelegramClient) SendMessageTo(chatID, text string) error {
+	return c.SendMessageToCtx(context.Background(), chatID, text)
+}
+
+// SendMessageToChat отправляет сообщение в чат с числовым идентификатором
+func (c *TelegramClient) SendMessageToChat(chatID int64, text string) error {
+	return c.SendMessageToCtx(context.Background(), strconv.FormatInt(chatID, 10), text)
+}
 
-	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", c.botToken)
-	
-	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBuffer(jsonData))
+// SendMessageToCtx отправляет сообщение в указанный чат (пустой chatID - чат клиента).
+// Сообщения длиннее MaxMessageLength отправляются несколькими частями.
+func (c *TelegramClient) SendMessageToCtx(ctx context.Context, chatID, text string) error {
+	return c.sendText(ctx, chatID, text, nil)
+}
+
+// SendMessageWithButtons отправляет сообщение с inline-клавиатурой (пустой chatID - чат клиента).
+// Если сообщение разбивается на части, клавиатура прикрепляется к последней.
+func (c *TelegramClient) SendMessageWithButtons(ctx context.Context, chatID, text string, buttons [][]InlineButton) error {
+	return c.sendText(ctx, chatID, text, &InlineKeyboardMarkup{InlineKeyboard: buttons})
+}
+
+// sendText отправляет текст частями не длиннее MaxMessageLength
+func (c *TelegramClient) sendText(ctx context.Context, chatID, text string, markup *InlineKeyboardMarkup) error {
+	chatID, err := c.resolveChat(chatID)
 	if err != nil {
-		return fmt.Errorf("failed to send telegram message: %v", err)
+		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram API returned status code: %d", resp.StatusCode)
+	parts := splitMessage(text, MaxMessageLength)
+	for i, part := range parts {
+		message := TelegramMessage{
+			ChatID:    chatID,
+			Text:      part,
+			ParseMode: "HTML",
+		}
+		if i == len(parts)-1 {
+			message.ReplyMarkup = markup
+		}
+
+		if err := c.call(ctx, "sendMessage", jsonRequest(message)); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// resolveChat возвращает чат получателя; пустой chatID заменяется чатом клиента
+func (c *TelegramClient) resolveChat(chatID string) (string, error) {
+	if chatID == "" {
+		chatID = c.chatID
+	}
+
+	if c.botToken == "" || chatID == "" {
+		return "", ErrNotConfigured
+	}
+	return chatID, nil
+}
+
 // SendBusinessRegistrationNotification отправляет уведомление о новой заявке на регистрацию бизнеса
 func (c *TelegramClient) SendBusinessRegistrationNotification(serviceName, contactName, contactPhone, city string) error {
 	message := fmt.Sprintf(
 		"🆕 <b>Новая заявка на регистрацию сервисного центра</b>\n\n"+
-		"📱 <b>Название:</b> %s\n"+
-		"👤 <b>Контактное лицо:</b> %s\n"+
-		"📞 <b>Телефон:</b> %s\n"+
-		"🏙 <b>Город:</b> %s\n\n"+
-		"⏰ <i>%s</i>",
+			"📱 <b>Название:</b> %s\n"+
+			"👤 <b>Контактное лицо:</b> %s\n"+
+			"📞 <b>Телефон:</b> %s\n"+
+			"🏙 <b>Город:</b> %s\n\n"+
+			"⏰ <i>%s</i>",
 		serviceName,
 		contactName,
 		contactPhone,
@@ -83,4 +199,4 @@ func (c *TelegramClient) SendBusinessRegistrationNotification(serviceName, conta
 	)
 
 	return c.SendMessage(message)
-}
\ No newline at end of file
+}
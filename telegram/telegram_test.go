@@ -0,0 +1,250 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vladzorgan/common/telegram/telegramtest"
+)
+
+// newTestClient создает клиент поверх имитации Bot API; ожидания retry_after записываются вместо сна
+func newTestClient(t *testing.T, opts ...Option) (*TelegramClient, *telegramtest.Transport, *[]time.Duration) {
+	t.Helper()
+
+	transport := telegramtest.NewTransport()
+	client := NewTelegramClient("token", "100", append([]Option{WithHTTPClient(transport.Client())}, opts...)...)
+
+	var sleeps []time.Duration
+	client.options.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return ctx.Err()
+	}
+	return client, transport, &sleeps
+}
+
+func TestSendMessage(t *testing.T) {
+	client, transport, _ := newTestClient(t)
+
+	if err := client.SendMessage("<b>hello</b>"); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	requests := transport.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(requests))
+	}
+	request := requests[0]
+	if request.Token != "token" || request.Method != "sendMessage" {
+		t.Errorf("request = %s/%s, want token/sendMessage", request.Token, request.Method)
+	}
+	if request.Params["chat_id"] != "100" || request.Params["text"] != "<b>hello</b>" || request.Params["parse_mode"] != "HTML" {
+		t.Errorf("params = %v", request.Params)
+	}
+}
+
+func TestSendMessageToChat(t *testing.T) {
+	client, transport, _ := newTestClient(t)
+
+	if err := client.SendMessageToChat(-1001234567890, "hi"); err != nil {
+		t.Fatalf("SendMessageToChat() error = %v", err)
+	}
+	if chat := transport.Requests()[0].Params["chat_id"]; chat != "-1001234567890" {
+		t.Errorf("chat_id = %s, want -1001234567890", chat)
+	}
+}
+
+func TestSendMessageNotConfigured(t *testing.T) {
+	client := NewTelegramClient("", "100")
+	if err := client.SendMessage("hi"); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("SendMessage() error = %v, want %v", err, ErrNotConfigured)
+	}
+}
+
+func TestSendMessageRetriesAfterRateLimit(t *testing.T) {
+	client, transport, sleeps := newTestClient(t)
+	transport.Enqueue(telegramtest.TooManyRequests(3), telegramtest.TooManyRequests(1))
+
+	if err := client.SendMessageCtx(context.Background(), "burst"); err != nil {
+		t.Fatalf("SendMessageCtx() error = %v", err)
+	}
+	if len(transport.Requests()) != 3 {
+		t.Errorf("requests = %d, want 3", len(transport.Requests()))
+	}
+	if want := []time.Duration{3 * time.Second, time.Second}; len(*sleeps) != 2 || (*sleeps)[0] != want[0] || (*sleeps)[1] != want[1] {
+		t.Errorf("waited %v, want %v", *sleeps, want)
+	}
+}
+
+func TestSendMessageRateLimitBounded(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []telegramtest.Response
+		requests  int
+	}{
+		{
+			name:      "retries exhausted",
+			responses: []telegramtest.Response{telegramtest.TooManyRequests(1), telegramtest.TooManyRequests(1), telegramtest.TooManyRequests(1)},
+			requests:  2,
+		},
+		{
+			name:      "retry_after too long",
+			responses: []telegramtest.Response{telegramtest.TooManyRequests(120)},
+			requests:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, transport, _ := newTestClient(t, WithRateLimitRetry(1, time.Minute))
+			transport.Enqueue(tt.responses...)
+
+			err := client.SendMessage("burst")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || apiErr.RetryAfter == 0 {
+				t.Fatalf("SendMessage() error = %v, want rate limit APIError", err)
+			}
+			if len(transport.Requests()) != tt.requests {
+				t.Errorf("requests = %d, want %d", len(transport.Requests()), tt.requests)
+			}
+		})
+	}
+}
+
+func TestSendMessageAPIError(t *testing.T) {
+	client, transport, _ := newTestClient(t)
+	transport.Enqueue(telegramtest.Error(http.StatusBadRequest, "Bad Request: chat not found"))
+
+	err := client.SendMessage("hi")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("SendMessage() error = %v, want *APIError", err)
+	}
+	if apiErr.ErrorCode != http.StatusBadRequest || apiErr.Description != "Bad Request: chat not found" {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+	if len(transport.Requests()) != 1 {
+		t.Errorf("requests = %d, want 1 (no retry)", len(transport.Requests()))
+	}
+}
+
+func TestSendMessageDoesNotRetryServerErrors(t *testing.T) {
+	client, transport, _ := newTestClient(t)
+	transport.Enqueue(telegramtest.Error(http.StatusBadGateway, "Bad Gateway"))
+
+	// Сообщение могло быть доставлено, поэтому повтор отправил бы его дважды
+	var apiErr *APIError
+	if err := client.SendMessage("hi"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("SendMessage() error = %v, want Bad Gateway APIError", err)
+	}
+	if len(transport.Requests()) != 1 {
+		t.Errorf("requests = %d, want 1 (no retry)", len(transport.Requests()))
+	}
+}
+
+func TestSendMessageContextCanceled(t *testing.T) {
+	client, transport, _ := newTestClient(t)
+	transport.Enqueue(telegramtest.TooManyRequests(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.SendMessageCtx(ctx, "hi"); err == nil {
+		t.Fatal("SendMessageCtx() error = nil, want context error")
+	}
+}
+
+func TestSendMessageSplitsLongText(t *testing.T) {
+	client, transport, _ := newTestClient(t)
+
+	line := strings.Repeat("я", 99) + "\n"
+	text := strings.Repeat(line, 50) // 5000 символов
+	buttons := [][]InlineButton{{{Text: "Открыть", URL: "https://example.com"}}}
+	if err := client.SendMessageWithButtons(context.Background(), "", text, buttons); err != nil {
+		t.Fatalf("SendMessageWithButtons() error = %v", err)
+	}
+
+	requests := transport.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(requests))
+	}
+	first, second := requests[0].Params["text"], requests[1].Params["text"]
+	if n := len([]rune(first)); n > MaxMessageLength || strings.HasSuffix(first, "\n") {
+		t.Errorf("first part length = %d, ends with newline = %v", n, strings.HasSuffix(first, "\n"))
+	}
+	if first+"\n"+second != text {
+		t.Error("parts do not reassemble into the original text")
+	}
+
+	if _, ok := requests[0].Params["reply_markup"]; ok {
+		t.Error("keyboard attached to the first part")
+	}
+	if markup := requests[1].Params["reply_markup"]; markup != `{"inline_keyboard":[[{"text":"Открыть","url":"https://example.com"}]]}` {
+		t.Errorf("reply_markup = %s", markup)
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{"short", "hello", 10, []string{"hello"}},
+		{"empty", "", 10, []string{""}},
+		{"by space", "hello brave new world", 12, []string{"hello brave", "new world"}},
+		{"hard cut", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		// Эмодзи вне BMP занимают две кодовые единицы UTF-16
+		{"surrogates", "😀😀😀", 4, []string{"😀😀", "😀"}},
+		// Теги закрываются в конце части и открываются в начале следующей
+		{"tags", "<b>hello world</b>", 14, []string{"<b>hello</b>", "<b>world</b>"}},
+		{"nested tags", `<a href="u">one <b>two</b></a>`, 26, []string{`<a href="u">one</a>`, `<a href="u"><b>two</b></a>`}},
+		// Сущности не разрываются
+		{"entities", "ab&amp;cd", 4, []string{"ab", "&amp;", "cd"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitMessage(tt.text, tt.limit)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("splitMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendPhotoUpload(t *testing.T) {
+	client, transport, _ := newTestClient(t)
+
+	photo := InputFile{Name: "report.png", Data: []byte("png-data")}
+	if err := client.SendPhoto(context.Background(), "200", photo, "Отчет"); err != nil {
+		t.Fatalf("SendPhoto() error = %v", err)
+	}
+
+	request := transport.Requests()[0]
+	if request.Method != "sendPhoto" || request.Params["chat_id"] != "200" || request.Params["caption"] != "Отчет" {
+		t.Errorf("request = %s %v", request.Method, request.Params)
+	}
+	if string(request.Files["photo"]) != "png-data" {
+		t.Errorf("photo = %q, want png-data", request.Files["photo"])
+	}
+}
+
+func TestSendDocumentByFileID(t *testing.T) {
+	client, transport, _ := newTestClient(t)
+
+	if err := client.SendDocument(context.Background(), "", InputFile{FileID: "https://example.com/a.pdf"}, ""); err != nil {
+		t.Fatalf("SendDocument() error = %v", err)
+	}
+
+	request := transport.Requests()[0]
+	if request.Method != "sendDocument" || request.Params["document"] != "https://example.com/a.pdf" || request.Params["chat_id"] != "100" {
+		t.Errorf("request = %s %v", request.Method, request.Params)
+	}
+	if _, ok := request.Params["caption"]; ok {
+		t.Error("empty caption sent")
+	}
+}
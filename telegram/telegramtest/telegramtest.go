@@ -0,0 +1,174 @@
+// Package telegramtest предоставляет имитацию Telegram Bot API для тестов: Transport перехватывает
+// запросы telegram.TelegramClient без обращения к api.telegram.org
+package telegramtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Request запрос к Bot API, полученный Transport
+type Request struct {
+	// Токен бота из пути запроса
+	Token string
+	// Метод Bot API (например, "sendMessage")
+	Method string
+	// Параметры запроса; значения, не являющиеся строками (например, reply_markup), хранятся в виде JSON
+	Params map[string]string
+	// Загруженные файлы по имени поля
+	Files map[string][]byte
+}
+
+// Response ответ Bot API, возвращаемый Transport
+type Response struct {
+	StatusCode int
+	Body       string
+}
+
+// OK возвращает успешный ответ
+func OK() Response {
+	return Response{StatusCode: http.StatusOK, Body: `{"ok":true,"result":{}}`}
+}
+
+// Error возвращает ответ с ошибкой Bot API
+func Error(statusCode int, description string) Response {
+	body, _ := json.Marshal(map[string]interface{}{
+		"ok":          false,
+		"error_code":  statusCode,
+		"description": description,
+	})
+	return Response{StatusCode: statusCode, Body: string(body)}
+}
+
+// TooManyRequests возвращает ответ 429 с parameters.retry_after в секундах
+func TooManyRequests(retryAfter int) Response {
+	return Response{
+		StatusCode: http.StatusTooManyRequests,
+		Body: fmt.Sprintf(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after %d","parameters":{"retry_after":%d}}`,
+			retryAfter, retryAfter),
+	}
+}
+
+// Transport реализует http.RoundTripper, записывая запросы к Bot API и отвечая из очереди ответов.
+// Когда очередь пуста, запрос считается успешным.
+type Transport struct {
+	mu        sync.Mutex
+	requests  []Request
+	responses []Response
+}
+
+// NewTransport создает имитацию Bot API
+func NewTransport() *Transport {
+	return &Transport{}
+}
+
+// Client возвращает http.Client, использующий Transport (см. telegram.WithHTTPClient)
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// Enqueue добавляет ответы на следующие запросы
+func (t *Transport) Enqueue(responses ...Response) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.responses = append(t.responses, responses...)
+}
+
+// Requests возвращает полученные запросы
+func (t *Transport) Requests() []Request {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]Request(nil), t.requests...)
+}
+
+// RoundTrip записывает запрос и возвращает следующий ответ из очереди
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, err := parseRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	t.requests = append(t.requests, request)
+	response := OK()
+	if len(t.responses) > 0 {
+		response = t.responses[0]
+		t.responses = t.responses[1:]
+	}
+	t.mu.Unlock()
+
+	return &http.Response{
+		StatusCode: response.StatusCode,
+		Status:     http.StatusText(response.StatusCode),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(response.Body)),
+		Request:    req,
+	}, nil
+}
+
+// parseRequest разбирает путь /bot<token>/<method> и тело запроса
+func parseRequest(req *http.Request) (Request, error) {
+	request := Request{Params: make(map[string]string), Files: make(map[string][]byte)}
+
+	path := strings.TrimPrefix(req.URL.Path, "/bot")
+	if token, method, ok := strings.Cut(path, "/"); ok {
+		request.Token, request.Method = token, method
+	}
+
+	if req.Body == nil {
+		return request, nil
+	}
+	defer req.Body.Close()
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return request, err
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return request, fmt.Errorf("telegramtest: invalid JSON body: %w", err)
+		}
+		for name, raw := range fields {
+			var value string
+			if json.Unmarshal(raw, &value) != nil {
+				value = string(raw)
+			}
+			request.Params[name] = value
+		}
+	case "multipart/form-data":
+		form, err := multipart.NewReader(bytes.NewReader(body), params["boundary"]).ReadForm(32 << 20)
+		if err != nil {
+			return request, fmt.Errorf("telegramtest: invalid multipart body: %w", err)
+		}
+		for name, values := range form.Value {
+			request.Params[name] = values[0]
+		}
+		for name, files := range form.File {
+			file, err := files[0].Open()
+			if err != nil {
+				return request, err
+			}
+			data, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				return request, err
+			}
+			request.Files[name] = data
+		}
+	}
+
+	return request, nil
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headersCarrier адаптирует заголовки сообщения AMQP к propagation.TextMapCarrier
+type headersCarrier map[string]interface{}
+
+func (c headersCarrier) Get(key string) string {
+	if value, ok := c[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+func (c headersCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c headersCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// InjectHeaders добавляет контекст трейса в заголовки сообщения.
+// Возвращает заголовки без изменений, если трассировка отключена.
+func InjectHeaders(ctx context.Context, headers map[string]interface{}) map[string]interface{} {
+	if !Enabled() {
+		return headers
+	}
+
+	if headers == nil {
+		headers = make(map[string]interface{})
+	}
+	otel.GetTextMapPropagator().Inject(ctx, headersCarrier(headers))
+
+	return headers
+}
+
+// StartConsumerSpan продолжает трейс издателя из заголовков сообщения и начинает спан обработки
+func StartConsumerSpan(ctx context.Context, headers map[string]interface{}, routingKey, queue string) (context.Context, trace.Span) {
+	if !Enabled() {
+		// Пустой спан, чтобы End не завершил спан из родительского контекста
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headersCarrier(headers))
+
+	return StartSpan(ctx, routingKey+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.destination", queue),
+			attribute.String("messaging.rabbitmq.routing_key", routingKey),
+		),
+	)
+}
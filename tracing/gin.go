@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vladzorgan/common/http/httpctx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GinMiddleware возвращает middleware, создающий серверный спан на каждый HTTP запрос.
+// Должен подключаться после middleware.RequestID, чтобы ID запроса попал в атрибуты спана.
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Enabled() {
+			c.Next()
+			return
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		// Шаблон маршрута не раскрывает идентификаторы и не плодит имена спанов
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		ctx, span := Tracer().Start(ctx, fmt.Sprintf("%s %s", c.Request.Method, route),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("http.target", c.Request.URL.Path),
+				attribute.String("net.peer.ip", c.ClientIP()),
+			),
+		)
+		defer span.End()
+
+		if requestID := httpctx.RequestID(c); requestID != "" {
+			span.SetAttributes(RequestIDKey.String(requestID))
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.status_code", status))
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+		if status >= 500 {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormSpanKey ключ спана в экземпляре запроса GORM
+const gormSpanKey = "common:tracing_span"
+
+// GormPlugin создает спан на каждый запрос GORM
+type GormPlugin struct{}
+
+// NewGormPlugin создает плагин трассировки GORM
+func NewGormPlugin() *GormPlugin {
+	return &GormPlugin{}
+}
+
+// Name возвращает имя плагина
+func (p *GormPlugin) Name() string {
+	return "common:tracing"
+}
+
+// Initialize регистрирует callback'и до и после каждой операции GORM
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("common:tracing_before_create", p.before("create")),
+		callbacks.Create().After("gorm:create").Register("common:tracing_after_create", p.after),
+		callbacks.Query().Before("gorm:query").Register("common:tracing_before_query", p.before("query")),
+		callbacks.Query().After("gorm:query").Register("common:tracing_after_query", p.after),
+		callbacks.Update().Before("gorm:update").Register("common:tracing_before_update", p.before("update")),
+		callbacks.Update().After("gorm:update").Register("common:tracing_after_update", p.after),
+		callbacks.Delete().Before("gorm:delete").Register("common:tracing_before_delete", p.before("delete")),
+		callbacks.Delete().After("gorm:delete").Register("common:tracing_after_delete", p.after),
+		callbacks.Row().Before("gorm:row").Register("common:tracing_before_row", p.before("row")),
+		callbacks.Row().After("gorm:row").Register("common:tracing_after_row", p.after),
+		callbacks.Raw().Before("gorm:raw").Register("common:tracing_before_raw", p.before("raw")),
+		callbacks.Raw().After("gorm:raw").Register("common:tracing_after_raw", p.after),
+	)
+}
+
+// before начинает спан операции
+func (p *GormPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if !Enabled() || db.Statement.Context == nil {
+			return
+		}
+
+		name := "gorm." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+
+		ctx, span := StartSpan(db.Statement.Context, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "postgresql"),
+				attribute.String("db.operation", operation),
+				attribute.String("db.sql.table", db.Statement.Table),
+			),
+		)
+
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+// after завершает спан операции, записывая текст запроса и ошибку
+func (p *GormPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.statement", db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+
+	// Отсутствие записи - ожидаемый результат, а не ошибка запроса
+	if db.Error != nil && db.Error != gorm.ErrRecordNotFound {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
@@ -0,0 +1,141 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// metadataCarrier адаптирует метаданные gRPC к propagation.TextMapCarrier
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// UnaryServerInterceptor создает интерцептор, продолжающий трейс вызывающей стороны.
+// Должен стоять после LoggingUnaryInterceptor, который помещает ID запроса в контекст.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !Enabled() {
+			return handler(ctx, req)
+		}
+
+		ctx, span := startServerSpan(ctx, info.FullMethod)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		setGRPCStatus(span, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor создает потоковый интерцептор, продолжающий трейс вызывающей стороны
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !Enabled() {
+			return handler(srv, ss)
+		}
+
+		ctx, span := startServerSpan(ss.Context(), info.FullMethod)
+		defer span.End()
+
+		err := handler(srv, &tracedServerStream{ServerStream: ss, ctx: ctx})
+		setGRPCStatus(span, err)
+		return err
+	}
+}
+
+// UnaryClientInterceptor создает клиентский интерцептор, передающий контекст трейса в метаданных
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !Enabled() {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		ctx, span := StartSpan(ctx, method,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("rpc.system", "grpc"),
+				attribute.String("rpc.method", method),
+				attribute.String("net.peer.name", cc.Target()),
+			),
+		)
+		defer span.End()
+
+		md, ok := metadata.FromOutgoingContext(ctx)
+		if ok {
+			md = md.Copy()
+		} else {
+			md = metadata.MD{}
+		}
+		otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+		ctx = metadata.NewOutgoingContext(ctx, md)
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		setGRPCStatus(span, err)
+		return err
+	}
+}
+
+// startServerSpan извлекает контекст трейса из входящих метаданных и начинает серверный спан
+func startServerSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	ctx, span := StartSpan(ctx, method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.method", method),
+		),
+	)
+
+	// Потоковые вызовы не помещают ID запроса в контекст, берем его из метаданных
+	if requestIDs := md.Get("x-request-id"); len(requestIDs) > 0 {
+		span.SetAttributes(RequestIDKey.String(requestIDs[0]))
+	}
+
+	return ctx, span
+}
+
+// setGRPCStatus записывает в спан код ответа gRPC
+func setGRPCStatus(span trace.Span, err error) {
+	st, _ := status.FromError(err)
+	span.SetAttributes(attribute.String("rpc.grpc.status_code", st.Code().String()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, st.Message())
+	}
+}
+
+// tracedServerStream подменяет контекст потока контекстом со спаном
+type tracedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedServerStream) Context() context.Context {
+	return s.ctx
+}
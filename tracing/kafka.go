@@ -0,0 +1,29 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartKafkaConsumerSpan продолжает трейс издателя из заголовков сообщения Kafka
+// (контекст трейса записывается в них InjectHeaders) и начинает спан обработки
+func StartKafkaConsumerSpan(ctx context.Context, headers map[string]interface{}, topic, group string) (context.Context, trace.Span) {
+	if !Enabled() {
+		// Пустой спан, чтобы End не завершил спан из родительского контекста
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headersCarrier(headers))
+
+	return StartSpan(ctx, topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination", topic),
+			attribute.String("messaging.kafka.consumer_group", group),
+		),
+	)
+}
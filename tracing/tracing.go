@@ -0,0 +1,151 @@
+// Package tracing предоставляет интеграцию с OpenTelemetry: настройку экспорта трейсов по OTLP,
+// middleware для HTTP, интерцепторы gRPC, плагин GORM и передачу контекста трассировки через RabbitMQ.
+// Если переменная OTEL_EXPORTER_OTLP_ENDPOINT не задана, все компоненты работают как no-op.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/vladzorgan/common/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName имя библиотеки инструментирования
+const instrumentationName = "github.com/vladzorgan/common/tracing"
+
+// RequestIDKey атрибут спана с ID запроса
+const RequestIDKey = attribute.Key("request.id")
+
+// enabled показывает, что экспорт трейсов настроен
+var enabled atomic.Bool
+
+// Config содержит настройки трассировки
+type Config struct {
+	// Адрес OTLP коллектора (OTEL_EXPORTER_OTLP_ENDPOINT); пустой - трассировка отключена
+	Endpoint string
+	// Имя сервиса (OTEL_SERVICE_NAME)
+	ServiceName string
+	// Версия сервиса
+	ServiceVersion string
+	// Окружение (deployment.environment)
+	Environment string
+	// Доля сэмплируемых трейсов от 0 до 1 (OTEL_TRACES_SAMPLER_ARG)
+	SampleRatio float64
+}
+
+// ConfigFromEnv формирует конфигурацию из переменных окружения.
+// serviceName и version используются, если OTEL_SERVICE_NAME не задан.
+func ConfigFromEnv(serviceName, version, env string) *Config {
+	config := &Config{
+		Endpoint:       os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		ServiceName:    serviceName,
+		ServiceVersion: version,
+		Environment:    env,
+		SampleRatio:    1,
+	}
+
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		config.ServiceName = name
+	}
+
+	if ratio, err := strconv.ParseFloat(os.Getenv("OTEL_TRACES_SAMPLER_ARG"), 64); err == nil && ratio >= 0 && ratio <= 1 {
+		config.SampleRatio = ratio
+	}
+
+	return config
+}
+
+// Init настраивает глобальный провайдер трейсов и распространение контекста.
+// Возвращает функцию, отправляющую оставшиеся спаны и останавливающую экспорт.
+// Без Endpoint трассировка не включается, а возвращаемая функция ничего не делает.
+func Init(ctx context.Context, config *Config, logger logging.Logger) (func(context.Context) error, error) {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	noop := func(context.Context) error { return nil }
+
+	if config == nil || config.Endpoint == "" {
+		logger.Info("OTEL_EXPORTER_OTLP_ENDPOINT not set, tracing disabled")
+		return noop, nil
+	}
+
+	// Адрес коллектора, заголовки и TLS экспортер берет из переменных окружения OTEL_EXPORTER_OTLP_*
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return noop, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", config.ServiceName),
+		attribute.String("service.version", config.ServiceVersion),
+		attribute.String("deployment.environment", config.Environment),
+	))
+	if err != nil {
+		return noop, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	enabled.Store(true)
+
+	logger.Info("Tracing enabled, exporting spans to %s", config.Endpoint)
+
+	return func(ctx context.Context) error {
+		enabled.Store(false)
+		return provider.Shutdown(ctx)
+	}, nil
+}
+
+// Enabled показывает, включена ли трассировка
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Tracer возвращает трейсер библиотеки
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// StartSpan начинает спан с ID запроса из контекста в качестве атрибута
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	ctx, span := Tracer().Start(ctx, name, opts...)
+	SetRequestID(ctx, span)
+	return ctx, span
+}
+
+// SetRequestID добавляет к спану ID запроса из контекста
+func SetRequestID(ctx context.Context, span trace.Span) {
+	if requestID := logging.ExtractRequestID(ctx); requestID != "" {
+		span.SetAttributes(RequestIDKey.String(requestID))
+	}
+}
+
+// RecordError отмечает спан как завершившийся ошибкой
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,174 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// enableTestTracing включает трассировку с записью спанов в память до конца теста
+func enableTestTracing(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	enabled.Store(true)
+
+	t.Cleanup(func() {
+		enabled.Store(false)
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+		provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+// testClientConn создает соединение без подключения для клиентских интерцепторов
+func testClientConn(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	conn, err := grpc.Dial("passthrough:///tracing-test", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.Dial() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestDisabledTracingIsNoop(t *testing.T) {
+	shutdown, err := Init(context.Background(), &Config{}, nil)
+	if err != nil || Enabled() {
+		t.Fatalf("Init() without endpoint: enabled = %v, error = %v", Enabled(), err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+
+	if headers := InjectHeaders(context.Background(), nil); headers != nil {
+		t.Errorf("InjectHeaders() = %v, want nil", headers)
+	}
+
+	parent := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1},
+	}))
+	ctx, span := StartConsumerSpan(parent, nil, "todo.created", "todo.events")
+	if ctx != parent || span.SpanContext().IsValid() {
+		t.Errorf("StartConsumerSpan() changed the context or returned the parent span")
+	}
+
+	var outgoing metadata.MD
+	err = UnaryClientInterceptor()(context.Background(), "/test.Service/Get", nil, nil, testClientConn(t),
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			outgoing, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		})
+	if err != nil || len(outgoing) != 0 {
+		t.Errorf("outgoing metadata = %v, %v; want none", outgoing, err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(GinMiddleware())
+	router.GET("/", func(c *gin.Context) {
+		if trace.SpanFromContext(c.Request.Context()).SpanContext().IsValid() {
+			t.Error("span started while tracing is disabled")
+		}
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestMessageHeadersPropagateTraceContext(t *testing.T) {
+	tests := []struct {
+		name  string
+		start func(ctx context.Context, headers map[string]interface{}) (context.Context, trace.Span)
+	}{
+		{"amqp", func(ctx context.Context, headers map[string]interface{}) (context.Context, trace.Span) {
+			return StartConsumerSpan(ctx, headers, "todo.created", "todo.events")
+		}},
+		{"kafka", func(ctx context.Context, headers map[string]interface{}) (context.Context, trace.Span) {
+			return StartKafkaConsumerSpan(ctx, headers, "todo.created", "todo-service")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := enableTestTracing(t)
+
+			ctx, publishSpan := StartSpan(context.Background(), "todo.created publish")
+			headers := InjectHeaders(ctx, map[string]interface{}{"x-request-id": "req-1"})
+			publishSpan.End()
+
+			if _, ok := headers["traceparent"].(string); !ok || headers["x-request-id"] != "req-1" {
+				t.Fatalf("headers = %v, want traceparent added to existing headers", headers)
+			}
+
+			_, span := tt.start(context.Background(), headers)
+			span.End()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 2 {
+				t.Fatalf("spans = %d, want 2", len(spans))
+			}
+			publish, consume := spans[0], spans[1]
+			if consume.SpanKind != trace.SpanKindConsumer || consume.Parent.SpanID() != publish.SpanContext.SpanID() ||
+				consume.SpanContext.TraceID() != publish.SpanContext.TraceID() {
+				t.Errorf("consumer span = %+v, want a child of the publisher span", consume.Parent)
+			}
+		})
+	}
+}
+
+func TestGRPCMetadataPropagatesTraceContext(t *testing.T) {
+	exporter := enableTestTracing(t)
+
+	// Клиентский интерцептор передает контекст трейса в метаданных
+	var outgoing metadata.MD
+	err := UnaryClientInterceptor()(context.Background(), "/test.Service/Get", nil, nil, testClientConn(t),
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			outgoing, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		})
+	if err != nil || len(outgoing.Get("traceparent")) != 1 {
+		t.Fatalf("outgoing metadata = %v, %v; want traceparent", outgoing, err)
+	}
+
+	// Серверный интерцептор продолжает тот же трейс
+	var handlerSpan trace.SpanContext
+	incoming := metadata.NewIncomingContext(context.Background(), outgoing)
+	_, err = UnaryServerInterceptor()(incoming, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			handlerSpan = trace.SpanFromContext(ctx).SpanContext()
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatalf("server interceptor error = %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	client, server := spans[0], spans[1]
+	if client.SpanKind != trace.SpanKindClient || server.SpanKind != trace.SpanKindServer {
+		t.Errorf("span kinds = %s, %s; want client, server", client.SpanKind, server.SpanKind)
+	}
+	if server.Parent.SpanID() != client.SpanContext.SpanID() || handlerSpan.TraceID() != client.SpanContext.TraceID() {
+		t.Errorf("server span parent = %s, handler trace = %s; want child of client span %s",
+			server.Parent.SpanID(), handlerSpan.TraceID(), client.SpanContext.SpanID())
+	}
+}
@@ -0,0 +1,273 @@
+// Package validation предоставляет валидацию структур по тегам validate с переводом
+// сообщений об ошибках на русский и английский языки
+package validation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ru"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	ruTranslations "github.com/go-playground/validator/v10/translations/ru"
+	apperrors "github.com/vladzorgan/common/errors"
+)
+
+// Locale язык сообщений об ошибках
+type Locale string
+
+const (
+	// LocaleRu русский язык (по умолчанию)
+	LocaleRu Locale = "ru"
+	// LocaleEn английский язык
+	LocaleEn Locale = "en"
+)
+
+// ValidationErrors содержит сообщения об ошибках валидации по полям (имена полей берутся из тега json)
+type ValidationErrors map[string]string
+
+// Error возвращает сообщения об ошибках, упорядоченные по имени поля
+func (e ValidationErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, field+": "+e[field])
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// ExistsFunc проверяет существование сущности с указанным ID
+type ExistsFunc func(ctx context.Context, id uint) (bool, error)
+
+var (
+	phoneRegexp = regexp.MustCompile(`^\+?\d{10,15}$`)
+	slugRegexp  = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	phoneChars  = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "")
+
+	existsMu     sync.RWMutex
+	existsChecks = make(map[string]ExistsFunc)
+
+	initOnce    sync.Once
+	validate    *validator.Validate
+	translators map[Locale]ut.Translator
+)
+
+// RegisterExistsCheck регистрирует проверку для тега exists=<name>, например, `validate:"exists=city"`.
+// Нулевой ID считается допустимым; обязательность поля задается тегом required.
+func RegisterExistsCheck(name string, fn ExistsFunc) {
+	existsMu.Lock()
+	defer existsMu.Unlock()
+	existsChecks[name] = fn
+}
+
+// Struct проверяет структуру по тегам validate. Ошибки валидации возвращаются как ValidationErrors
+// с сообщениями на русском языке, сбои проверок exists (например, недоступность базы) - как ErrInternal.
+func Struct(v interface{}) error {
+	return StructCtx(context.Background(), v)
+}
+
+// StructCtx проверяет структуру с контекстом, который передается в проверки exists
+func StructCtx(ctx context.Context, v interface{}) error {
+	return StructLocale(ctx, v, LocaleRu)
+}
+
+// StructLocale проверяет структуру и возвращает сообщения об ошибках на указанном языке
+func StructLocale(ctx context.Context, v interface{}, locale Locale) error {
+	initValidator()
+
+	state := &checkState{}
+	err := validate.StructCtx(context.WithValue(ctx, checkStateKey{}, state), v)
+	if state.err != nil {
+		return apperrors.Wrap(apperrors.ErrInternal, "", nil, state.err, "ошибка проверки данных")
+	}
+	if err == nil {
+		return nil
+	}
+
+	fieldErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	return Translate(fieldErrs, locale)
+}
+
+// Translate преобразует ошибки go-playground/validator в ValidationErrors на указанном языке
+func Translate(errs validator.ValidationErrors, locale Locale) ValidationErrors {
+	initValidator()
+
+	trans, ok := translators[locale]
+	if !ok {
+		trans = translators[LocaleRu]
+	}
+
+	result := make(ValidationErrors, len(errs))
+	for _, fieldErr := range errs {
+		result[fieldPath(fieldErr)] = fieldErr.Translate(trans)
+	}
+
+	return result
+}
+
+// Validator возвращает настроенный экземпляр validator.Validate, например, для регистрации
+// дополнительных правил или использования в gin (binding.Validator)
+func Validator() *validator.Validate {
+	initValidator()
+	return validate
+}
+
+// checkStateKey ключ контекста для состояния проверки
+type checkStateKey struct{}
+
+// checkState сохраняет первую ошибку проверки exists, которая не является ошибкой валидации
+type checkState struct {
+	mu  sync.Mutex
+	err error
+}
+
+// initValidator создает валидатор и переводы при первом использовании
+func initValidator() {
+	initOnce.Do(func() {
+		validate = validator.New()
+		validate.RegisterTagNameFunc(jsonFieldName)
+
+		validate.RegisterValidation("phone", validatePhone)
+		validate.RegisterValidation("slug", validateSlug)
+		validate.RegisterValidationCtx("exists", validateExists)
+
+		uni := ut.New(ru.New(), ru.New(), en.New())
+		ruTrans, _ := uni.GetTranslator(string(LocaleRu))
+		enTrans, _ := uni.GetTranslator(string(LocaleEn))
+		translators = map[Locale]ut.Translator{LocaleRu: ruTrans, LocaleEn: enTrans}
+
+		if err := ruTranslations.RegisterDefaultTranslations(validate, ruTrans); err != nil {
+			panic(fmt.Sprintf("failed to register ru translations: %v", err))
+		}
+		if err := enTranslations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+			panic(fmt.Sprintf("failed to register en translations: %v", err))
+		}
+
+		registerTranslation(ruTrans, "phone", "{0} должен быть номером телефона")
+		registerTranslation(ruTrans, "slug", "{0} может содержать только строчные латинские буквы, цифры и дефисы")
+		registerTranslation(ruTrans, "exists", "{0} ссылается на несуществующую запись")
+		registerTranslation(enTrans, "phone", "{0} must be a valid phone number")
+		registerTranslation(enTrans, "slug", "{0} must contain only lowercase letters, digits and hyphens")
+		registerTranslation(enTrans, "exists", "{0} refers to a non-existent record")
+	})
+}
+
+// registerTranslation регистрирует перевод собственного правила
+func registerTranslation(trans ut.Translator, tag, text string) {
+	err := validate.RegisterTranslation(tag, trans,
+		func(trans ut.Translator) error {
+			return trans.Add(tag, text, true)
+		},
+		func(trans ut.Translator, fieldErr validator.FieldError) string {
+			message, _ := trans.T(tag, fieldErr.Field())
+			return message
+		},
+	)
+	if err != nil {
+		panic(fmt.Sprintf("failed to register %s translation: %v", tag, err))
+	}
+}
+
+// jsonFieldName возвращает имя поля из тега json
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// fieldPath возвращает путь к полю без имени корневой структуры (например, "items[0].name")
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fieldErr.Field()
+}
+
+// validatePhone проверяет номер телефона: 10-15 цифр с необязательным "+",
+// пробелы, дефисы и скобки игнорируются
+func validatePhone(fl validator.FieldLevel) bool {
+	return phoneRegexp.MatchString(phoneChars.Replace(fl.Field().String()))
+}
+
+// validateSlug проверяет slug: строчные латинские буквы и цифры, разделенные одиночными дефисами
+func validateSlug(fl validator.FieldLevel) bool {
+	return slugRegexp.MatchString(fl.Field().String())
+}
+
+// validateExists проверяет существование сущности через зарегистрированную проверку
+func validateExists(ctx context.Context, fl validator.FieldLevel) bool {
+	name := fl.Param()
+
+	existsMu.RLock()
+	fn, ok := existsChecks[name]
+	existsMu.RUnlock()
+
+	state, _ := ctx.Value(checkStateKey{}).(*checkState)
+	if !ok {
+		state.fail(fmt.Errorf("validation: exists check %q is not registered", name))
+		return true
+	}
+
+	field := fl.Field()
+	var id uint
+	switch field.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		id = uint(field.Uint())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if field.Int() < 0 {
+			return false
+		}
+		id = uint(field.Int())
+	default:
+		state.fail(fmt.Errorf("validation: exists check %q does not support %s", name, field.Kind()))
+		return true
+	}
+
+	if id == 0 {
+		return true
+	}
+
+	exists, err := fn(ctx, id)
+	if err != nil {
+		// Ошибка проверки (например, недоступность базы) не должна превращаться в ошибку валидации
+		state.fail(fmt.Errorf("validation: exists check %q failed: %w", name, err))
+		return true
+	}
+
+	return exists
+}
+
+// fail сохраняет первую ошибку проверки
+func (s *checkState) fail(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
@@ -0,0 +1,64 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apperrors "github.com/vladzorgan/common/errors"
+)
+
+type cityInput struct {
+	Name     string `json:"name" validate:"required"`
+	Slug     string `json:"slug" validate:"omitempty,slug"`
+	Phone    string `json:"phone" validate:"omitempty,phone"`
+	RegionID uint   `json:"region_id" validate:"exists=test_region"`
+}
+
+func TestStructReturnsFieldErrors(t *testing.T) {
+	RegisterExistsCheck("test_region", func(ctx context.Context, id uint) (bool, error) {
+		return id == 1, nil
+	})
+
+	if err := Struct(&cityInput{Name: "Москва", Slug: "moscow-city", Phone: "+7 (999) 123-45-67", RegionID: 1}); err != nil {
+		t.Fatalf("valid input: %v", err)
+	}
+
+	err := Struct(&cityInput{Slug: "Moscow_City", Phone: "12-34", RegionID: 2})
+	var fieldErrs ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		t.Fatalf("error = %v, want ValidationErrors", err)
+	}
+	for _, field := range []string{"name", "slug", "phone", "region_id"} {
+		if fieldErrs[field] == "" {
+			t.Errorf("no error for %s in %v", field, fieldErrs)
+		}
+	}
+	if fieldErrs["name"] != "name обязательное поле" {
+		t.Errorf("name message = %q", fieldErrs["name"])
+	}
+}
+
+func TestStructLocale(t *testing.T) {
+	err := StructLocale(context.Background(), &cityInput{}, LocaleEn)
+	var fieldErrs ValidationErrors
+	if !errors.As(err, &fieldErrs) || fieldErrs["name"] != "name is a required field" {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestExistsCheckFailureIsNotValidationError(t *testing.T) {
+	RegisterExistsCheck("test_broken", func(ctx context.Context, id uint) (bool, error) {
+		return false, errors.New("connection refused")
+	})
+
+	input := struct {
+		RegionID uint `json:"region_id" validate:"exists=test_broken"`
+	}{RegionID: 5}
+
+	err := Struct(&input)
+	var fieldErrs ValidationErrors
+	if errors.As(err, &fieldErrs) || !errors.Is(err, apperrors.ErrInternal) {
+		t.Errorf("error = %v, want ErrInternal", err)
+	}
+}
@@ -0,0 +1,28 @@
+package warmup
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vladzorgan/common/cache"
+)
+
+// ReferenceLoadFunc загружает справочные данные целиком (ключ кеша - значение)
+type ReferenceLoadFunc[V any] func(ctx context.Context) (map[string]V, error)
+
+// ReferenceCacheWarmer возвращает функцию прогрева, которая заполняет кеш справочных данных
+// в памяти процесса (например, регионов или каталога устройств) результатом load
+func ReferenceCacheWarmer[V any](c *cache.LocalCache[V], load ReferenceLoadFunc[V]) Func {
+	return func(ctx context.Context) error {
+		values, err := load(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load reference data: %v", err)
+		}
+
+		for key, value := range values {
+			c.Set(key, value)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,39 @@
+package warmup
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vladzorgan/common/redis"
+)
+
+// LoadFunc загружает в кеш значение для указанного ключа
+type LoadFunc func(ctx context.Context, key string) error
+
+// HotKeysWarmer возвращает функцию прогрева, которая загружает topN самых популярных ключей.
+// Популярность ключей берется из sorted set в Redis, где score - счетчик обращений.
+func HotKeysWarmer(client *redis.Client, zsetKey string, topN int64, load LoadFunc) Func {
+	return func(ctx context.Context) error {
+		if topN <= 0 {
+			return nil
+		}
+
+		// Получаем самые популярные ключи по убыванию счетчика обращений
+		keys, err := client.Client().ZRevRange(ctx, zsetKey, 0, topN-1).Result()
+		if err != nil {
+			return fmt.Errorf("failed to get hot keys from Redis: %v", err)
+		}
+
+		for _, key := range keys {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if err := load(ctx, key); err != nil {
+				return fmt.Errorf("failed to warm up key %s: %v", key, err)
+			}
+		}
+
+		return nil
+	}
+}
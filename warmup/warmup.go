@@ -0,0 +1,228 @@
+// Package warmup предоставляет механизм прогрева кешей и справочных данных при старте сервиса
+package warmup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vladzorgan/common/health"
+	"github.com/vladzorgan/common/logging"
+	"github.com/vladzorgan/common/metrics"
+)
+
+// ErrDuplicateTask возвращается Register при повторной регистрации задачи с тем же именем
+var ErrDuplicateTask = errors.New("warmup task already registered")
+
+// Func представляет функцию прогрева
+type Func func(ctx context.Context) error
+
+// Task представляет зарегистрированную задачу прогрева
+type Task struct {
+	Name     string
+	Fn       Func
+	Required bool // Ошибка обязательной задачи оставляет сервис неготовым
+}
+
+// Result представляет результат выполнения задачи прогрева
+type Result struct {
+	Name     string
+	Required bool
+	Duration time.Duration
+	Err      error
+}
+
+// Warmer выполняет зарегистрированные задачи прогрева и сообщает о готовности сервиса.
+// Warmer реализует health.Component: до завершения прогрева и при ошибке
+// обязательных задач компонент возвращает StatusDown.
+type Warmer struct {
+	tasks          []Task
+	logger         logging.Logger
+	mutex          sync.RWMutex
+	completed      bool
+	failedRequired []string
+	duration       *prometheus.HistogramVec
+}
+
+// NewWarmer создает новый Warmer
+func NewWarmer(servicePrefix string, logger logging.Logger) *Warmer {
+	if logger == nil {
+		logger = logging.NewLogger()
+	}
+
+	duration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    servicePrefix + "_warmup_duration_ms",
+			Help:    "Продолжительность прогрева в миллисекундах",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 16), // От 1мс до ~32с
+		},
+		[]string{"name", "status"},
+	)
+
+	// Регистрируем метрики; повторное создание Warmer использует уже зарегистрированную гистограмму
+	duration = metrics.Register(nil, duration)
+
+	return &Warmer{
+		tasks:    make([]Task, 0),
+		logger:   logger,
+		duration: duration,
+	}
+}
+
+// Register регистрирует задачу прогрева. Имена задач уникальны: Run различает результаты
+// по имени, поэтому повторная регистрация возвращает ErrDuplicateTask.
+func (w *Warmer) Register(name string, fn Func, required bool) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for _, task := range w.tasks {
+		if task.Name == name {
+			return fmt.Errorf("%w: %s", ErrDuplicateTask, name)
+		}
+	}
+
+	w.tasks = append(w.tasks, Task{
+		Name:     name,
+		Fn:       fn,
+		Required: required,
+	})
+	return nil
+}
+
+// Run параллельно выполняет все задачи прогрева в пределах общего бюджета времени.
+// Задачи, не успевшие завершиться, считаются завершившимися с ошибкой таймаута.
+func (w *Warmer) Run(ctx context.Context, budget time.Duration) []Result {
+	w.mutex.RLock()
+	tasks := make([]Task, len(w.tasks))
+	copy(tasks, w.tasks)
+	w.mutex.RUnlock()
+
+	startTime := time.Now()
+	w.logger.Info("Starting warmup of %d tasks with budget %v", len(tasks), budget)
+
+	ctx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+
+	// Запускаем задачи параллельно
+	resultsChan := make(chan Result, len(tasks))
+	for _, task := range tasks {
+		go func(task Task) {
+			taskStart := time.Now()
+			err := w.runTask(ctx, task)
+			resultsChan <- Result{
+				Name:     task.Name,
+				Required: task.Required,
+				Duration: time.Since(taskStart),
+				Err:      err,
+			}
+		}(task)
+	}
+
+	// Собираем результаты до завершения всех задач или истечения бюджета
+	finished := make(map[string]Result, len(tasks))
+wait:
+	for len(finished) < len(tasks) {
+		select {
+		case result := <-resultsChan:
+			finished[result.Name] = result
+		case <-ctx.Done():
+			break wait
+		}
+	}
+
+	results := make([]Result, 0, len(tasks))
+	failedRequired := make([]string, 0)
+	failedCount := 0
+
+	for _, task := range tasks {
+		result, ok := finished[task.Name]
+		if !ok {
+			result = Result{
+				Name:     task.Name,
+				Required: task.Required,
+				Duration: time.Since(startTime),
+				Err:      fmt.Errorf("warmup budget exceeded: %v", ctx.Err()),
+			}
+		}
+
+		status := "success"
+		if result.Err != nil {
+			status = "error"
+			failedCount++
+		}
+		w.duration.WithLabelValues(result.Name, status).Observe(float64(result.Duration.Milliseconds()))
+
+		switch {
+		case result.Err == nil:
+			w.logger.Info("Warmup task %s completed in %v", result.Name, result.Duration)
+		case result.Required:
+			failedRequired = append(failedRequired, result.Name)
+			w.logger.Error("Required warmup task %s failed after %v: %v", result.Name, result.Duration, result.Err)
+		default:
+			w.logger.Warn("Optional warmup task %s failed after %v: %v", result.Name, result.Duration, result.Err)
+		}
+
+		results = append(results, result)
+	}
+
+	w.mutex.Lock()
+	w.completed = true
+	w.failedRequired = failedRequired
+	w.mutex.Unlock()
+
+	w.logger.WithFields(map[string]interface{}{
+		"total":           len(tasks),
+		"failed":          failedCount,
+		"failed_required": len(failedRequired),
+		"duration_ms":     time.Since(startTime).Milliseconds(),
+	}).Info("Warmup finished")
+
+	return results
+}
+
+// runTask выполняет задачу с защитой от паники
+func (w *Warmer) runTask(ctx context.Context, task Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic in warmup task: %v", r)
+		}
+	}()
+
+	return task.Fn(ctx)
+}
+
+// Ready возвращает true, если прогрев завершен и все обязательные задачи выполнены успешно
+func (w *Warmer) Ready() bool {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.completed && len(w.failedRequired) == 0
+}
+
+// Name возвращает имя компонента
+func (w *Warmer) Name() string {
+	return "warmup"
+}
+
+// Check проверяет состояние прогрева
+func (w *Warmer) Check(ctx context.Context) (health.Status, error) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	if !w.completed {
+		return health.StatusDown, fmt.Errorf("warmup in progress")
+	}
+
+	if len(w.failedRequired) > 0 {
+		return health.StatusDown, fmt.Errorf("required warmup tasks failed: %v", w.failedRequired)
+	}
+
+	return health.StatusUp, nil
+}
+
+// IsCritical возвращает true, если компонент критичен для работы сервиса
+func (w *Warmer) IsCritical() bool {
+	return true
+}
@@ -0,0 +1,74 @@
+package warmup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vladzorgan/common/cache"
+)
+
+func TestRegisterRejectsDuplicateNames(t *testing.T) {
+	warmer := NewWarmer("warmup_duplicate", nil)
+	noop := func(context.Context) error { return nil }
+
+	if err := warmer.Register("regions", noop, true); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := warmer.Register("regions", noop, false); !errors.Is(err, ErrDuplicateTask) {
+		t.Fatalf("Register() duplicate error = %v, want %v", err, ErrDuplicateTask)
+	}
+
+	done := make(chan []Result, 1)
+	go func() { done <- warmer.Run(context.Background(), time.Second) }()
+
+	select {
+	case results := <-done:
+		if len(results) != 1 || results[0].Err != nil || !warmer.Ready() {
+			t.Errorf("Run() = %+v, ready = %v", results, warmer.Ready())
+		}
+	case <-time.After(time.Second / 2):
+		t.Fatal("Run() did not finish before the budget")
+	}
+}
+
+func TestReferenceCacheWarmer(t *testing.T) {
+	regions := cache.NewLocalCache[string](time.Minute)
+	warmer := NewWarmer("warmup_reference", nil)
+
+	err := warmer.Register("regions", ReferenceCacheWarmer(regions, func(context.Context) (map[string]string, error) {
+		return map[string]string{"77": "Москва", "78": "Санкт-Петербург"}, nil
+	}), true)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	failing := ReferenceCacheWarmer(cache.NewLocalCache[string](0), func(context.Context) (map[string]string, error) {
+		return nil, errors.New("location service unavailable")
+	})
+	if err := warmer.Register("cities", failing, false); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	results := warmer.Run(context.Background(), time.Second)
+	if len(results) != 2 || results[0].Err != nil || results[1].Err == nil {
+		t.Fatalf("Run() = %+v", results)
+	}
+	if name, ok := regions.Get("77"); !ok || name != "Москва" || regions.Len() != 2 {
+		t.Errorf("regions cache = %q, %v, len %d", name, ok, regions.Len())
+	}
+	// Ошибка необязательной задачи не влияет на готовность
+	if !warmer.Ready() {
+		t.Error("Ready() = false, want true")
+	}
+}
+
+func TestNewWarmerReusesRegisteredMetrics(t *testing.T) {
+	first := NewWarmer("warmup_repeated", nil)
+	// Повторное создание с тем же префиксом (например, в тестах сервиса) не паникует
+	second := NewWarmer("warmup_repeated", nil)
+
+	if first.duration != second.duration {
+		t.Error("second Warmer must reuse the registered histogram")
+	}
+}